@@ -1,14 +1,16 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"log/slog"
 	"os"
 	"strings"
+	"time"
 
-	"solr-mcp-go/internal/client"
-	"solr-mcp-go/internal/server"
+	"github.com/Sashimimochi/solr-mcp-go/internal/client"
+	"github.com/Sashimimochi/solr-mcp-go/internal/server"
 )
 
 var (
@@ -70,21 +72,22 @@ func main() {
 
 	out := flag.CommandLine.Output()
 	flag.Usage = func() {
-		fmt.Fprintf(out, "Usage: %s <client|server> [-proto <http|https>] [-port <port>] [-host <host>]\n\n", os.Args[0])
+		fmt.Fprintf(out, "Usage: %s <client|server|doctor> [-proto <http|https>] [-port <port>] [-host <host>]\n\n", os.Args[0])
 		fmt.Fprintf(out, "This program demonstrates MCP over HTTP using the streamable transport.\n")
-		fmt.Fprintf(out, "It can run as either a server or client.\n\n")
+		fmt.Fprintf(out, "It can run as either a server or client, or check the server configuration with doctor.\n\n")
 		fmt.Fprintf(out, "Options:\n")
 		flag.PrintDefaults()
 		fmt.Fprintf(out, "\nExamples:\n")
 		fmt.Fprintf(out, " Run as server: %s server\n", os.Args[0])
 		fmt.Fprintf(out, " Run as client: %s client\n", os.Args[0])
+		fmt.Fprintf(out, " Check configuration: %s doctor\n", os.Args[0])
 		fmt.Fprintf(out, " Custom host/port: %s -port 9000 -host 0.0.0.0 server\n", os.Args[0])
 		os.Exit(1)
 	}
 	flag.Parse()
 
 	if flag.NArg() != 1 {
-		fmt.Fprintf(out, "Error: Must specify 'client' or 'server' as first argument\n")
+		fmt.Fprintf(out, "Error: Must specify 'client', 'server' or 'doctor' as first argument\n")
 		flag.Usage()
 	}
 	mode := flag.Arg(0)
@@ -96,8 +99,19 @@ func main() {
 	case "client":
 		url := fmt.Sprintf("%s://%s:%d", *proto, *host, *port)
 		client.Run(url)
+	case "doctor":
+		// Keep the report readable unless a log level was requested explicitly
+		if levelStr == "" {
+			logLevel.Set(slog.LevelError)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		failures := server.WriteReport(os.Stdout, server.Doctor(ctx))
+		cancel()
+		if failures > 0 {
+			os.Exit(1)
+		}
 	default:
-		fmt.Fprintf(os.Stderr, "Error: Invalid mode '%s'. Must be 'client' or 'server'\n\n", mode)
+		fmt.Fprintf(os.Stderr, "Error: Invalid mode '%s'. Must be 'client', 'server' or 'doctor'\n\n", mode)
 		flag.Usage()
 	}
 }
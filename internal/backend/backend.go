@@ -0,0 +1,48 @@
+// Package backend defines the operations the MCP tools need from a search engine, so that the
+// Solr HTTP implementation can be replaced by a mock in tests or by an adapter for another engine.
+package backend
+
+import (
+	"context"
+	"net/url"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/config"
+	"github.com/Sashimimochi/solr-mcp-go/internal/types"
+)
+
+// Querier runs searches.
+type Querier interface {
+	// Query runs a search described by Solr /select parameters (q, fq, fl, sort, start, rows, ...)
+	// and returns a response in the Solr JSON response format.
+	Query(ctx context.Context, collection string, params url.Values) (map[string]any, error)
+	// Count returns the number of documents matching query.
+	Count(ctx context.Context, collection, query string) (int64, error)
+}
+
+// Updater modifies documents. Every call is committed before it returns.
+type Updater interface {
+	Add(ctx context.Context, collection string, docs []map[string]any) error
+	DeleteByQuery(ctx context.Context, collection, query string) error
+	DeleteByIDs(ctx context.Context, collection string, ids []string) error
+}
+
+// SchemaReader describes collections.
+type SchemaReader interface {
+	Schema(ctx context.Context, collection string) (*types.FieldCatalog, error)
+}
+
+// Admin manages the cluster and its collections.
+type Admin interface {
+	// ClusterStatus returns the cluster state, limited to collection when it is not empty.
+	ClusterStatus(ctx context.Context, collection string) (*config.ClusterStatusResponse, error)
+	ListCollections(ctx context.Context) ([]string, error)
+	DeleteCollection(ctx context.Context, name string) error
+}
+
+// SearchBackend is everything the tools need from a search engine.
+type SearchBackend interface {
+	Querier
+	Updater
+	SchemaReader
+	Admin
+}
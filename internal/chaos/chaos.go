@@ -0,0 +1,171 @@
+// Package chaos injects failures into Solr requests for testing the retry and fallback behavior of agents.
+// It is a developer tool and must not be enabled against clusters serving real users.
+package chaos
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/config"
+)
+
+// Fault is a kind of injected failure.
+type Fault string
+
+const (
+	// Timeout holds the request until Config.Timeout or its deadline passes and fails it with a timeout error.
+	Timeout Fault = "timeout"
+	// Unavailable answers with HTTP 503, as an overloaded or restarting node does.
+	Unavailable Fault = "503"
+	// Malformed sends the request and cuts the response body in half, so it is not valid JSON.
+	Malformed Fault = "malformed"
+)
+
+// Faults are all fault kinds.
+var Faults = []Fault{Timeout, Unavailable, Malformed}
+
+// DefaultTimeout is how long a request with an injected timeout is held by default.
+const DefaultTimeout = 10 * time.Second
+
+// Config configures failure injection.
+type Config struct {
+	// Rate is the share of Solr requests that fail, from 0 to 1.
+	Rate float64
+	// Faults are the fault kinds chosen from at random (default: all).
+	Faults []Fault
+	// Timeout is how long a request with an injected timeout is held (default: DefaultTimeout).
+	Timeout time.Duration
+	// AllRequests fails requests of any path, e.g. of a client dedicated to an OpenSearch backend, not only those
+	// of the Solr APIs.
+	AllRequests bool
+}
+
+// ParseFaults parses a comma-separated list of fault kinds. An empty list selects all of them.
+func ParseFaults(s string) ([]Fault, error) {
+	var faults []Fault
+	for _, f := range strings.Split(s, ",") {
+		f = strings.TrimSpace(f)
+		if f == "" {
+			continue
+		}
+		if !slices.Contains(Faults, Fault(f)) {
+			return nil, fmt.Errorf("unknown fault %q (expected timeout, 503 or malformed)", f)
+		}
+		faults = append(faults, Fault(f))
+	}
+	if len(faults) == 0 {
+		return Faults, nil
+	}
+	return faults, nil
+}
+
+// Transport is an http.RoundTripper failing a random share of Solr requests. Requests to other services, such as
+// object stores, are passed through.
+type Transport struct {
+	Base     http.RoundTripper
+	Config   Config
+	injected atomic.Int64
+}
+
+// Wrap returns a copy of client whose Solr requests fail as configured by c.
+func Wrap(client *http.Client, c Config) *http.Client {
+	if len(c.Faults) == 0 {
+		c.Faults = Faults
+	}
+	if c.Timeout <= 0 {
+		c.Timeout = DefaultTimeout
+	}
+	base := client.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	wrapped := *client
+	wrapped.Transport = &Transport{Base: base, Config: c}
+	return &wrapped
+}
+
+// Injected returns the number of failures injected so far.
+func (t *Transport) Injected() int64 {
+	return t.injected.Load()
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if (!t.Config.AllRequests && !isSolrRequest(req)) || rand.Float64() >= t.Config.Rate {
+		return t.Base.RoundTrip(req)
+	}
+	fault := t.Config.Faults[rand.IntN(len(t.Config.Faults))]
+	t.injected.Add(1)
+	slog.Warn("Injecting fault", "fault", fault, "method", req.Method, "url", config.RedactURL(req.URL.String()))
+	switch fault {
+	case Timeout:
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		timer := time.NewTimer(t.Config.Timeout)
+		defer timer.Stop()
+		select {
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		case <-timer.C:
+			return nil, timeoutError{t.Config.Timeout}
+		}
+	case Unavailable:
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		body := `{"responseHeader":{"status":503},"error":{"msg":"chaos: injected 503 Service Unavailable","code":503}}`
+		return &http.Response{
+			Status:        "503 Service Unavailable",
+			StatusCode:    http.StatusServiceUnavailable,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        http.Header{"Content-Type": {"application/json"}, "X-Chaos-Fault": {string(fault)}},
+			Body:          io.NopCloser(strings.NewReader(body)),
+			ContentLength: int64(len(body)),
+			Request:       req,
+		}, nil
+	default:
+		resp, err := t.Base.RoundTrip(req)
+		if err != nil {
+			return nil, err
+		}
+		data, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		data = data[:len(data)/2]
+		resp.Body = io.NopCloser(bytes.NewReader(data))
+		resp.ContentLength = int64(len(data))
+		resp.Header.Del("Content-Length")
+		resp.Header.Set("X-Chaos-Fault", string(fault))
+		return resp, nil
+	}
+}
+
+// isSolrRequest reports whether req goes to the V1 or V2 API of Solr.
+func isSolrRequest(req *http.Request) bool {
+	return strings.HasPrefix(req.URL.Path, "/solr/") || strings.HasPrefix(req.URL.Path, "/api/")
+}
+
+// timeoutError is the error of an injected timeout. It is a net.Error, like the timeouts of real connections.
+type timeoutError struct {
+	after time.Duration
+}
+
+func (e timeoutError) Error() string {
+	return fmt.Sprintf("chaos: injected timeout after %s", e.after)
+}
+
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
@@ -0,0 +1,88 @@
+package chaos
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestTransport tests injecting each fault kind into Solr requests.
+func TestTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"responseHeader":{"status":0},"response":{"numFound":1,"docs":[{"id":"1"}]}}`)
+	}))
+	defer server.Close()
+	get := func(client *http.Client, path string) (*http.Response, []byte, error) {
+		resp, err := client.Get(server.URL + path)
+		if err != nil {
+			return nil, nil, err
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		return resp, body, err
+	}
+
+	// Goal: Without failures, requests pass through.
+	client := Wrap(server.Client(), Config{Rate: 0})
+	resp, body, err := get(client, "/solr/products/select")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.True(t, json.Valid(body))
+
+	// Goal: 503 responses look like those of Solr.
+	client = Wrap(server.Client(), Config{Rate: 1, Faults: []Fault{Unavailable}})
+	resp, body, err = get(client, "/solr/products/select")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.Contains(t, string(body), "injected 503")
+	assert.Equal(t, int64(1), client.Transport.(*Transport).Injected())
+
+	// Goal: Malformed responses keep the status but are not valid JSON.
+	client = Wrap(server.Client(), Config{Rate: 1, Faults: []Fault{Malformed}})
+	resp, body, err = get(client, "/api/collections")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.False(t, json.Valid(body))
+	assert.Equal(t, "malformed", resp.Header.Get("X-Chaos-Fault"))
+
+	// Goal: Timeouts are net.Error timeouts, and end early with the request deadline.
+	client = Wrap(server.Client(), Config{Rate: 1, Faults: []Fault{Timeout}, Timeout: 10 * time.Millisecond})
+	_, _, err = get(client, "/solr/products/select")
+	var netErr net.Error
+	require.ErrorAs(t, err, &netErr)
+	assert.True(t, netErr.Timeout())
+	client = Wrap(server.Client(), Config{Rate: 1, Faults: []Fault{Timeout}, Timeout: time.Hour})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/solr/products/select", nil)
+	_, err = client.Do(req)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+
+	// Goal: Requests to other services are never failed.
+	client = Wrap(server.Client(), Config{Rate: 1})
+	resp, _, err = get(client, "/bucket/exports/a.jsonl")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+// TestParseFaults tests parsing fault lists.
+func TestParseFaults(t *testing.T) {
+	faults, err := ParseFaults("")
+	require.NoError(t, err)
+	assert.Equal(t, Faults, faults)
+	faults, err = ParseFaults(" 503, malformed ")
+	require.NoError(t, err)
+	assert.Equal(t, []Fault{Unavailable, Malformed}, faults)
+	_, err = ParseFaults("timeout,500")
+	assert.ErrorContains(t, err, `unknown fault "500"`)
+}
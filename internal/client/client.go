@@ -4,7 +4,7 @@ import (
 	"context"
 	"log/slog"
 
-	"solr-mcp-go/internal/config"
+	"github.com/Sashimimochi/solr-mcp-go/internal/config"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
@@ -0,0 +1,85 @@
+// Package coalesce shares one execution of identical concurrent calls between their callers, like the same query
+// issued at once by several agents watching the same dashboard question.
+package coalesce
+
+import (
+	"context"
+	"sync"
+)
+
+// Group runs calls of the same key once while they overlap. The zero value is ready to use.
+type Group[T any] struct {
+	mu      sync.Mutex
+	flights map[string]*flight[T]
+}
+
+type flight[T any] struct {
+	done    chan struct{}
+	val     T
+	err     error
+	callers int // callers that joined the flight
+	waiting int // callers still waiting for it
+	shared  bool
+	cancel  context.CancelFunc
+}
+
+// Do runs fn for key unless a call of key is already running, and returns its result. shared reports whether the
+// result was returned to other callers too, who must then not modify it.
+//
+// fn runs with the values of the context of the caller that started it, but without its deadline and cancellation:
+// it is only canceled when every caller has given up, so one caller leaving does not fail the others. A caller whose
+// ctx is done returns ctx.Err() right away.
+func (g *Group[T]) Do(ctx context.Context, key string, fn func(context.Context) (T, error)) (v T, shared bool, err error) {
+	g.mu.Lock()
+	if g.flights == nil {
+		g.flights = map[string]*flight[T]{}
+	}
+	f, ok := g.flights[key]
+	if !ok {
+		fctx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		f = &flight[T]{done: make(chan struct{}), cancel: cancel}
+		g.flights[key] = f
+		go g.run(fctx, key, f, fn)
+	}
+	f.callers++
+	f.waiting++
+	g.mu.Unlock()
+
+	select {
+	case <-f.done:
+		return f.val, f.shared, f.err
+	case <-ctx.Done():
+		g.mu.Lock()
+		if f.waiting--; f.waiting == 0 {
+			f.cancel()
+			g.forget(key, f)
+		}
+		g.mu.Unlock()
+		var zero T
+		return zero, false, ctx.Err()
+	}
+}
+
+func (g *Group[T]) run(ctx context.Context, key string, f *flight[T], fn func(context.Context) (T, error)) {
+	val, err := fn(ctx)
+	f.cancel()
+	g.mu.Lock()
+	f.val, f.err, f.shared = val, err, f.callers > 1
+	g.forget(key, f)
+	g.mu.Unlock()
+	close(f.done)
+}
+
+// forget removes the flight of key, unless an abandoned flight was already replaced by a new one.
+func (g *Group[T]) forget(key string, f *flight[T]) {
+	if g.flights[key] == f {
+		delete(g.flights, key)
+	}
+}
+
+// Running returns the number of keys with a call running.
+func (g *Group[T]) Running() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return len(g.flights)
+}
@@ -0,0 +1,108 @@
+package coalesce
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestDo tests sharing one call between concurrent callers of a key.
+func TestDo(t *testing.T) {
+	var g Group[string]
+	var calls atomic.Int32
+	release := make(chan struct{})
+	fn := func(context.Context) (string, error) {
+		calls.Add(1)
+		<-release
+		return "result", nil
+	}
+
+	// Goal: Overlapping callers of a key share one call and its result.
+	var wg sync.WaitGroup
+	results := make([]string, 3)
+	shared := make([]bool, 3)
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, s, err := g.Do(context.Background(), "q", fn)
+			assert.NoError(t, err)
+			results[i], shared[i] = v, s
+		}()
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	assert.Equal(t, int32(1), calls.Load())
+	assert.Equal(t, []string{"result", "result", "result"}, results)
+	assert.Equal(t, []bool{true, true, true}, shared)
+	assert.Equal(t, 0, g.Running())
+
+	// Goal: Calls that do not overlap, or have other keys, run on their own.
+	v, s, err := g.Do(context.Background(), "q", fn)
+	require.NoError(t, err)
+	assert.Equal(t, "result", v)
+	assert.False(t, s)
+	_, _, err = g.Do(context.Background(), "other", func(context.Context) (string, error) { return "", errors.New("failed") })
+	assert.EqualError(t, err, "failed")
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+// TestDoCancel tests that a call is only canceled when all of its callers gave up.
+func TestDoCancel(t *testing.T) {
+	var g Group[int]
+	started := make(chan struct{})
+	canceled := make(chan struct{})
+	release := make(chan struct{})
+	fn := func(ctx context.Context) (int, error) {
+		close(started)
+		select {
+		case <-ctx.Done():
+			close(canceled)
+			return 0, ctx.Err()
+		case <-release:
+			return 42, nil
+		}
+	}
+
+	// Goal: A caller leaving returns at once, and the others still get the result.
+	first, cancelFirst := context.WithCancel(context.Background())
+	errs := make(chan error, 1)
+	go func() {
+		_, _, err := g.Do(first, "q", fn)
+		errs <- err
+	}()
+	<-started
+	done := make(chan int, 1)
+	go func() {
+		v, _, _ := g.Do(context.Background(), "q", fn)
+		done <- v
+	}()
+	time.Sleep(20 * time.Millisecond)
+	cancelFirst()
+	assert.ErrorIs(t, <-errs, context.Canceled)
+	close(release)
+	assert.Equal(t, 42, <-done)
+
+	// Goal: The call is canceled when its last caller leaves, and the key is free for new calls.
+	started, canceled, release = make(chan struct{}), make(chan struct{}), make(chan struct{})
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-started
+		cancel()
+	}()
+	_, _, err := g.Do(ctx, "q", fn)
+	assert.ErrorIs(t, err, context.Canceled)
+	select {
+	case <-canceled:
+	case <-time.After(time.Second):
+		t.Fatal("the abandoned call was not canceled")
+	}
+	assert.Equal(t, 0, g.Running())
+}
@@ -1,9 +1,11 @@
 package config
 
 import (
+	"context"
 	"log/slog"
 	"net/http"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
@@ -19,6 +21,20 @@ func GetEnv(key, defaultVal string) string {
 	return defaultVal
 }
 
+// GetEnvInt returns the integer value of an environment variable, or defaultVal if it is unset or invalid.
+func GetEnvInt(key string, defaultVal int) int {
+	v := os.Getenv(key)
+	if v == "" {
+		return defaultVal
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(v))
+	if err != nil {
+		slog.Warn("Invalid integer environment variable, using default", "key", key, "value", v, "default", defaultVal)
+		return defaultVal
+	}
+	return n
+}
+
 // ClusterStatusResponse represents the response from CLUSTERSTATUS API
 type ClusterStatusResponse struct {
 	ResponseHeader solr.ResponseHeader `json:"responseHeader"`
@@ -57,13 +73,29 @@ type ReplicaInfo struct {
 
 func NewSolrClient() (*solr.JSONClient, string, string, string, *http.Client) {
 	baseURL := strings.TrimRight(GetEnv("SOLR_MCP_SOLR_URL", "http://localhost:8983"), "/")
-	user := GetEnv("SOLR_BASIC_USER", "")
-	pass := GetEnv("SOLR_BASIC_PASS", "")
-	rs := solr.NewDefaultRequestSender().WithHTTPClient(&http.Client{Timeout: 30 * time.Second})
+	user, err := GetSecret(context.Background(), "SOLR_BASIC_USER")
+	if err != nil {
+		slog.Error("Failed to load Solr credentials", "error", err)
+	}
+	pass, err := GetSecret(context.Background(), "SOLR_BASIC_PASS")
+	if err != nil {
+		slog.Error("Failed to load Solr credentials", "error", err)
+	}
+	httpClient, err := NewHTTPClient(GetEnv("SOLR_MCP_PROXY_URL", ""))
+	if err != nil {
+		slog.Error("Ignoring SOLR_MCP_PROXY_URL", "error", err)
+		httpClient = &http.Client{Timeout: 30 * time.Second}
+	}
+	client := NewJSONClient(baseURL, user, pass, httpClient)
+	slog.Info("Using Solr URL", "url", RedactURL(baseURL))
+	return client, baseURL, user, pass, httpClient
+}
+
+// NewJSONClient creates a solr-go client for baseURL, authenticating with user and pass if user is set.
+func NewJSONClient(baseURL, user, pass string, httpClient *http.Client) *solr.JSONClient {
+	rs := solr.NewDefaultRequestSender().WithHTTPClient(httpClient)
 	if user != "" {
 		rs = rs.WithBasicAuth(user, pass)
 	}
-	client := solr.NewJSONClient(baseURL).WithRequestSender(rs)
-	slog.Info("Using Solr URL", "url", baseURL)
-	return client, baseURL, user, pass, &http.Client{Timeout: 30 * time.Second}
+	return solr.NewJSONClient(baseURL).WithRequestSender(rs)
 }
@@ -41,6 +41,32 @@ func TestGetEnv(t *testing.T) {
 	})
 }
 
+// TestGetEnvInt tests the GetEnvInt function.
+func TestGetEnvInt(t *testing.T) {
+	// Case 1: Environment variable is a valid integer
+	t.Run("Valid integer", func(t *testing.T) {
+		t.Setenv("TEST_ENV_INT", "42")
+		if got := GetEnvInt("TEST_ENV_INT", 7); got != 42 {
+			t.Errorf("Expected 42, Actual %d", got)
+		}
+	})
+
+	// Case 2: Environment variable is not set
+	t.Run("Not set", func(t *testing.T) {
+		if got := GetEnvInt("NON_EXISTENT_INT", 7); got != 7 {
+			t.Errorf("Expected 7, Actual %d", got)
+		}
+	})
+
+	// Case 3: Environment variable is not an integer
+	t.Run("Invalid integer", func(t *testing.T) {
+		t.Setenv("TEST_ENV_INT", "ten")
+		if got := GetEnvInt("TEST_ENV_INT", 7); got != 7 {
+			t.Errorf("Expected 7, Actual %d", got)
+		}
+	})
+}
+
 // TestNewSolrClient tests the NewSolrClient function.
 func TestNewSolrClient(t *testing.T) {
 	// Clear environment variables that may already be set
@@ -0,0 +1,905 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FileConfig is the optional JSON configuration file referenced by SOLR_MCP_CONFIG_FILE.
+// It holds settings that do not fit into plain environment variables.
+type FileConfig struct {
+	SavedQueries []SavedQuery     `json:"savedQueries,omitempty"`
+	Exporter     ExporterConfig   `json:"exporter,omitempty"`
+	Datasource   DatasourceConfig `json:"datasource,omitempty"`
+	Retention    RetentionConfig  `json:"retention,omitempty"`
+	// QueryLimits are resource limits added to solr.query requests. Can be changed at runtime.
+	QueryLimits QueryLimitsConfig `json:"queryLimits,omitempty"`
+	// PostProcessing configures the processors applied to tool results. Can be changed at runtime.
+	PostProcessing PostProcessingConfig `json:"postProcessing,omitempty"`
+	// DisabledTools lists tool names that are not registered. Can be changed at runtime.
+	DisabledTools []string `json:"disabledTools,omitempty"`
+	// ToolArgsLimits overrides SOLR_MCP_MAX_TOOL_ARGS_BYTES per tool name, e.g. a larger limit for solr.update
+	// and a smaller one for solr.query; 0 disables the check for the tool. Can be changed at runtime.
+	ToolArgsLimits map[string]int `json:"toolArgsLimits,omitempty"`
+	// Drift configures scheduled schema and config snapshots. Can be changed at runtime.
+	Drift DriftConfig `json:"drift,omitempty"`
+	// Notifier receives alerts of background jobs. Can be changed at runtime.
+	Notifier NotifierConfig `json:"notifier,omitempty"`
+	// Maintenance configures the index hygiene job run in a nightly window. Can be changed at runtime.
+	Maintenance MaintenanceConfig `json:"maintenance,omitempty"`
+	// Transformers reshape the documents returned by solr.query, per collection. Can be changed at runtime.
+	Transformers map[string][]TransformerConfig `json:"transformers,omitempty"`
+	// Experiments route a share of solr.query executions to alternate query profiles. Can be changed at runtime.
+	Experiments []ExperimentConfig `json:"experiments,omitempty"`
+	// Feedback configures click feedback. Can be changed at runtime.
+	Feedback FeedbackConfig `json:"feedback,omitempty"`
+	// Jobs sets the default throttling of export, reindex and import jobs. Can be changed at runtime.
+	Jobs JobsConfig `json:"jobs,omitempty"`
+	// ResponseBudget narrows the fields of solr.query results that are too large. Can be changed at runtime.
+	ResponseBudget ResponseBudgetConfig `json:"responseBudget,omitempty"`
+	// QuerySafety handles expensive wildcard, regex and fuzzy terms of solr.query. Can be changed at runtime.
+	QuerySafety QuerySafetyConfig `json:"querySafety,omitempty"`
+	// ObjectStores are named object storage destinations of exports and large results. Can be changed at runtime.
+	ObjectStores map[string]ObjectStoreConfig `json:"objectStores,omitempty"`
+	// SolrExporter is a solr-exporter endpoint scraped into a metric history. When it is set at startup, its url,
+	// retention and metrics can be changed at runtime.
+	SolrExporter SolrExporterConfig `json:"solrExporter,omitempty"`
+	// SlowQuery attaches node metrics and possible causes to slow solr.query results. Can be changed at runtime.
+	SlowQuery SlowQueryConfig `json:"slowQuery,omitempty"`
+	// Policy authorizes tool calls with CEL rules and an OPA server. Can be changed at runtime.
+	Policy PolicyConfig `json:"policy,omitempty"`
+	// MemoryBudget bounds the Solr responses held by running tool calls. Bytes are only tracked when maxBytes is
+	// set at startup; the limits can be changed at runtime.
+	MemoryBudget MemoryBudgetConfig `json:"memoryBudget,omitempty"`
+}
+
+// DefaultMemoryBudgetWait is how long a tool call waits for the memory budget unless memoryBudget.maxWait is set.
+const DefaultMemoryBudgetWait = 5 * time.Second
+
+// MemoryBudgetConfig bounds the tool calls in flight. A call that would start while the running calls hold MaxBytes
+// of Solr responses, or while MaxConcurrent calls are running, waits up to MaxWait for one of them to finish and
+// is rejected as busy after that. Zero values do not limit.
+type MemoryBudgetConfig struct {
+	MaxBytes      int64  `json:"maxBytes,omitempty"`
+	MaxConcurrent int    `json:"maxConcurrent,omitempty"`
+	MaxWait       string `json:"maxWait,omitempty"` // Go duration string (default: 5s); "0s" rejects without waiting
+}
+
+// Enabled reports whether tool calls are limited at all.
+func (mb MemoryBudgetConfig) Enabled() bool {
+	return mb.MaxBytes > 0 || mb.MaxConcurrent > 0
+}
+
+// MaxWaitDuration returns how long a tool call waits for the budget.
+func (mb MemoryBudgetConfig) MaxWaitDuration() time.Duration {
+	d, err := time.ParseDuration(mb.MaxWait)
+	if err != nil || d < 0 {
+		return DefaultMemoryBudgetWait
+	}
+	return d
+}
+
+// Policy effects.
+const (
+	PolicyAllow = "allow"
+	PolicyDeny  = "deny"
+)
+
+// PolicyConfig authorizes each tool call. Rules are CEL expressions over the context of the call, the first
+// matching one decides. Calls that are allowed are then checked with OPA, if configured.
+type PolicyConfig struct {
+	UserHeader string       `json:"userHeader,omitempty"` // HTTP header naming the caller, set by an authenticating proxy (default: X-Forwarded-User)
+	Rules      []PolicyRule `json:"rules,omitempty"`
+	Default    string       `json:"default,omitempty"`   // effect when no rule matches: "allow" (default) or "deny"
+	OPA        OPAConfig    `json:"opa,omitempty"`       // Open Policy Agent consulted after the rules
+	AuditFile  string       `json:"auditFile,omitempty"` // JSON Lines file receiving a record per decision
+}
+
+// PolicyRule applies Effect to the tool calls for which the CEL expression When is true.
+type PolicyRule struct {
+	Name   string `json:"name"`
+	When   string `json:"when"`
+	Effect string `json:"effect"`           // "allow" or "deny"
+	Reason string `json:"reason,omitempty"` // explanation returned to denied callers
+}
+
+// OPAConfig queries an Open Policy Agent decision, e.g. http://opa:8181/v1/data/solr/mcp/allow. The decision is
+// a boolean, or an object with allow and reason.
+type OPAConfig struct {
+	URL      string `json:"url,omitempty"`
+	Timeout  string `json:"timeout,omitempty"`  // Go duration string (default: 2s)
+	FailOpen bool   `json:"failOpen,omitempty"` // allow calls when OPA cannot be reached instead of denying them
+}
+
+// DefaultOPATimeout is how long an OPA decision may take unless opa.timeout is set.
+const DefaultOPATimeout = 2 * time.Second
+
+// Enabled reports whether tool calls are authorized at all.
+func (pc PolicyConfig) Enabled() bool {
+	return len(pc.Rules) > 0 || pc.OPA.URL != "" || pc.Default == PolicyDeny
+}
+
+// UserHeaderName returns the header naming the caller.
+func (pc PolicyConfig) UserHeaderName() string {
+	if pc.UserHeader == "" {
+		return "X-Forwarded-User"
+	}
+	return pc.UserHeader
+}
+
+// TimeoutDuration returns the timeout of OPA decisions.
+func (oc OPAConfig) TimeoutDuration() time.Duration {
+	d, err := time.ParseDuration(oc.Timeout)
+	if err != nil || d <= 0 {
+		return DefaultOPATimeout
+	}
+	return d
+}
+
+// validate checks the structure of the policy. CEL expressions are compiled by the policy engine.
+func (pc PolicyConfig) validate() error {
+	if pc.Default != "" && pc.Default != PolicyAllow && pc.Default != PolicyDeny {
+		return fmt.Errorf("policy.default: must be allow or deny")
+	}
+	seen := map[string]bool{}
+	for i, r := range pc.Rules {
+		switch {
+		case strings.TrimSpace(r.Name) == "":
+			return fmt.Errorf("policy.rules[%d]: name is required", i)
+		case seen[r.Name]:
+			return fmt.Errorf("policy.rules[%d]: duplicate name %q", i, r.Name)
+		case strings.TrimSpace(r.When) == "":
+			return fmt.Errorf("policy.rules[%d]: when is required", i)
+		case r.Effect != PolicyAllow && r.Effect != PolicyDeny:
+			return fmt.Errorf("policy.rules[%d]: effect must be allow or deny", i)
+		}
+		seen[r.Name] = true
+	}
+	if u := pc.OPA.URL; u != "" && !strings.HasPrefix(u, "http://") && !strings.HasPrefix(u, "https://") {
+		return fmt.Errorf("policy.opa.url: must be an http or https URL")
+	}
+	if t := pc.OPA.Timeout; t != "" {
+		if d, err := time.ParseDuration(t); err != nil || d <= 0 {
+			return fmt.Errorf("policy.opa.timeout: invalid duration %q", t)
+		}
+	}
+	return nil
+}
+
+// SlowQueryConfig sets the QTime above which solr.query explains slowness with node metrics.
+type SlowQueryConfig struct {
+	Threshold string `json:"threshold,omitempty"` // Go duration string, e.g. "1s"; unset disables the analysis
+}
+
+// ThresholdDuration returns the QTime threshold, or 0 when the analysis is disabled.
+func (sq SlowQueryConfig) ThresholdDuration() time.Duration {
+	d, err := time.ParseDuration(sq.Threshold)
+	if err != nil || d < 0 {
+		return 0
+	}
+	return d
+}
+
+// Defaults of the solr-exporter scraping.
+const (
+	DefaultScrapeInterval   = time.Minute
+	DefaultMetricsRetention = 6 * time.Hour
+	DefaultMaxSeries        = 5000
+)
+
+// SolrExporterConfig is the Prometheus endpoint of a solr-exporter (or any Prometheus text endpoint). Its samples
+// are kept in memory for Retention, so solr.metrics.history can answer questions about trends.
+type SolrExporterConfig struct {
+	URL       string   `json:"url,omitempty"`       // e.g. http://solr-exporter:9854/metrics
+	Interval  string   `json:"interval,omitempty"`  // Go duration string (default: 1m)
+	Retention string   `json:"retention,omitempty"` // Go duration string (default: 6h)
+	Metrics   []string `json:"metrics,omitempty"`   // regular expressions of metric names to keep (default: all)
+	MaxSeries int      `json:"maxSeries,omitempty"` // series kept at most (default: 5000); further series are dropped
+}
+
+// IntervalDuration returns the scrape interval.
+func (sc SolrExporterConfig) IntervalDuration() time.Duration {
+	if d, err := time.ParseDuration(sc.Interval); err == nil && d > 0 {
+		return d
+	}
+	return DefaultScrapeInterval
+}
+
+// RetentionDuration returns how long samples are kept.
+func (sc SolrExporterConfig) RetentionDuration() time.Duration {
+	if d, err := time.ParseDuration(sc.Retention); err == nil && d > 0 {
+		return d
+	}
+	return DefaultMetricsRetention
+}
+
+// SeriesLimit returns MaxSeries, or its default.
+func (sc SolrExporterConfig) SeriesLimit() int {
+	if sc.MaxSeries > 0 {
+		return sc.MaxSeries
+	}
+	return DefaultMaxSeries
+}
+
+func (sc SolrExporterConfig) validate() error {
+	if sc.URL != "" && !strings.HasPrefix(sc.URL, "http://") && !strings.HasPrefix(sc.URL, "https://") {
+		return fmt.Errorf("solrExporter.url: must be an http or https URL")
+	}
+	for name, v := range map[string]string{"interval": sc.Interval, "retention": sc.Retention} {
+		if v == "" {
+			continue
+		}
+		if d, err := time.ParseDuration(v); err != nil || d <= 0 {
+			return fmt.Errorf("solrExporter.%s: invalid duration %q", name, v)
+		}
+	}
+	for i, m := range sc.Metrics {
+		if _, err := regexp.Compile(m); err != nil {
+			return fmt.Errorf("solrExporter.metrics[%d]: %v", i, err)
+		}
+	}
+	if sc.MaxSeries < 0 {
+		return fmt.Errorf("solrExporter.maxSeries: must not be negative")
+	}
+	return nil
+}
+
+// Object store types.
+const (
+	ObjectStoreS3    = "s3"    // Amazon S3 or an S3-compatible store such as MinIO
+	ObjectStoreGCS   = "gcs"   // Google Cloud Storage through its XML API with HMAC keys
+	ObjectStoreAzure = "azure" // Azure Blob Storage with a SAS token
+)
+
+// ObjectStoreConfig is an object storage destination. Credentials are not part of the config file: they are read
+// from the secret environment variables CredentialsEnv + "_ACCESS_KEY_ID", "_SECRET_ACCESS_KEY" and "_SESSION_TOKEN"
+// (S3 and GCS) or "_SAS_TOKEN" (Azure), which also accept _FILE and secret references.
+type ObjectStoreConfig struct {
+	Type     string `json:"type"`
+	Bucket   string `json:"bucket"`             // bucket, or container for Azure
+	Prefix   string `json:"prefix,omitempty"`   // prepended to object keys, e.g. "solr-mcp/"
+	Region   string `json:"region,omitempty"`   // S3 region (default for GCS: auto)
+	Endpoint string `json:"endpoint,omitempty"` // base URL replacing the public endpoint, e.g. of MinIO or Azurite
+	Account  string `json:"account,omitempty"`  // Azure storage account
+	// CredentialsEnv is the prefix of the credential variables (default: AWS, GCS or AZURE_STORAGE by type).
+	CredentialsEnv string `json:"credentialsEnv,omitempty"`
+}
+
+// CredentialsPrefix returns CredentialsEnv, or the default prefix of the store type.
+func (oc ObjectStoreConfig) CredentialsPrefix() string {
+	if oc.CredentialsEnv != "" {
+		return oc.CredentialsEnv
+	}
+	switch oc.Type {
+	case ObjectStoreGCS:
+		return "GCS"
+	case ObjectStoreAzure:
+		return "AZURE_STORAGE"
+	}
+	return "AWS"
+}
+
+func (oc ObjectStoreConfig) validate() error {
+	switch oc.Type {
+	case ObjectStoreS3:
+		if oc.Region == "" && oc.Endpoint == "" {
+			return fmt.Errorf("region or endpoint is required for s3")
+		}
+	case ObjectStoreGCS:
+	case ObjectStoreAzure:
+		if oc.Account == "" && oc.Endpoint == "" {
+			return fmt.Errorf("account or endpoint is required for azure")
+		}
+	default:
+		return fmt.Errorf("unknown type %q (use %s, %s or %s)", oc.Type, ObjectStoreS3, ObjectStoreGCS, ObjectStoreAzure)
+	}
+	if strings.TrimSpace(oc.Bucket) == "" {
+		return fmt.Errorf("bucket is required")
+	}
+	if oc.Endpoint != "" && !strings.HasPrefix(oc.Endpoint, "http://") && !strings.HasPrefix(oc.Endpoint, "https://") {
+		return fmt.Errorf("endpoint must be an http or https URL")
+	}
+	return nil
+}
+
+// Query safety modes.
+const (
+	SafetyConfirm = "confirm" // the query only runs with a confirmation token
+	SafetyRewrite = "rewrite" // expensive terms are replaced by bounded ones
+	SafetyOff     = "off"     // queries run unchecked (default)
+)
+
+// DefaultMaxExpansion is the number of distinct values of a field up to which expensive terms on it are allowed.
+const DefaultMaxExpansion = 10000
+
+// QuerySafetyConfig decides what happens to solr.query requests with terms that scan the term dictionary of a field:
+// leading wildcards, regular expressions starting with .* and fuzzy terms with an edit distance of 2 on short terms.
+// Terms on fields with at most MaxExpansion distinct values run unchanged.
+type QuerySafetyConfig struct {
+	Mode         string `json:"mode,omitempty"`
+	MaxExpansion int64  `json:"maxExpansion,omitempty"` // default: DefaultMaxExpansion
+}
+
+// SafetyMode returns Mode, or SafetyOff when it is not set.
+func (qc QuerySafetyConfig) SafetyMode() string {
+	if qc.Mode == "" {
+		return SafetyOff
+	}
+	return qc.Mode
+}
+
+// ExpansionLimit returns MaxExpansion, or DefaultMaxExpansion when it is not set.
+func (qc QuerySafetyConfig) ExpansionLimit() int64 {
+	if qc.MaxExpansion == 0 {
+		return DefaultMaxExpansion
+	}
+	return qc.MaxExpansion
+}
+
+// ResponseBudgetConfig is a size limit of solr.query results. A result larger than MaxBytes after post-processing
+// is fetched again with only the uniqueKey and the display fields of its collection.
+type ResponseBudgetConfig struct {
+	MaxBytes      int                 `json:"maxBytes,omitempty"`
+	DisplayFields map[string][]string `json:"displayFields,omitempty"` // collection -> fields kept, most important first
+}
+
+// JobsConfig is the default throttling of background jobs, used when a job does not set its own. Zero values do not limit.
+type JobsConfig struct {
+	MaxRequestsPerSecond float64 `json:"maxRequestsPerSecond,omitempty"`
+	MaxBytesPerSecond    int64   `json:"maxBytesPerSecond,omitempty"`
+}
+
+// FeedbackConfig controls what happens to the clicks reported with solr.feedback.click.
+type FeedbackConfig struct {
+	// SignalsCollection, if set, receives a document per click, e.g. for boosting by popularity.
+	SignalsCollection string `json:"signalsCollection,omitempty"`
+}
+
+// ExperimentConfig is an A/B test of solr.query: a Fraction of the executions on Collections run as variant B,
+// with Params set on the request. The others run unchanged as variant A.
+type ExperimentConfig struct {
+	Name        string            `json:"name"`
+	Collections []string          `json:"collections,omitempty"` // default: all collections
+	Fraction    float64           `json:"fraction"`
+	Params      map[string]string `json:"params"` // an empty value removes the parameter, e.g. rq to turn re-ranking off
+	Description string            `json:"description,omitempty"`
+}
+
+// SavedQuery is a named Solr query defined by the operator.
+type SavedQuery struct {
+	Name        string            `json:"name"`
+	Description string            `json:"description,omitempty"`
+	Collection  string            `json:"collection"`
+	Query       string            `json:"query,omitempty"`
+	FilterQuery []string          `json:"fq,omitempty"`
+	Aggregate   string            `json:"aggregate,omitempty"` // JSON Facet function (e.g. "sum(bytes)"); numFound is used when empty
+	Labels      map[string]string `json:"labels,omitempty"`
+	TimeField   string            `json:"timeField,omitempty"` // date field used to bucket time series
+}
+
+// ExporterConfig controls the Prometheus exporter mode.
+type ExporterConfig struct {
+	Enabled  bool   `json:"enabled"`
+	Interval string `json:"interval,omitempty"` // Go duration string (default: 1m)
+}
+
+// DatasourceConfig controls the Grafana JSON datasource endpoint.
+type DatasourceConfig struct {
+	Enabled bool `json:"enabled"`
+}
+
+// RetentionConfig controls index lifecycle management.
+type RetentionConfig struct {
+	Interval  string            `json:"interval,omitempty"`  // Go duration string; policies run automatically when set
+	AuditFile string            `json:"auditFile,omitempty"` // JSON Lines file receiving an audit record per action
+	Policies  []RetentionPolicy `json:"policies,omitempty"`
+}
+
+// PostProcessingConfig selects the processors applied to tool results, in order.
+// Built-in processors are "redact", "truncate", "summarize" and "format".
+type PostProcessingConfig struct {
+	Pipeline        []string            `json:"pipeline,omitempty"`        // applied to every tool
+	Tools           map[string][]string `json:"tools,omitempty"`           // per-tool pipelines replacing Pipeline
+	RedactFields    []string            `json:"redactFields,omitempty"`    // keys whose values "redact" replaces
+	MaxStringLength int                 `json:"maxStringLength,omitempty"` // "truncate" limit in characters (default: 2000)
+	MaxArrayLength  int                 `json:"maxArrayLength,omitempty"`  // "truncate" limit in items (default: 100)
+}
+
+// DriftConfig controls schema and config drift detection. Snapshots are stored in the data directory.
+type DriftConfig struct {
+	Interval    string   `json:"interval,omitempty"`    // Go duration string; snapshots are taken automatically when set
+	Collections []string `json:"collections,omitempty"` // collections to snapshot (default: the default collection)
+	Keep        int      `json:"keep,omitempty"`        // snapshots kept per collection (default: 100)
+}
+
+// NotifierConfig sends alerts as JSON to a webhook (e.g. a Slack or Teams incoming webhook adapter).
+type NotifierConfig struct {
+	WebhookURL string            `json:"webhookUrl,omitempty"`
+	Headers    map[string]string `json:"headers,omitempty"` // e.g. an Authorization header
+}
+
+// MaintenanceConfig controls the maintenance job, which runs once per occurrence of its window: it merges away
+// deleted documents, runs the retention policies and backs up collections, between a health check before and after.
+type MaintenanceConfig struct {
+	Window               string                  `json:"window,omitempty"`               // "HH:MM-HH:MM", may span midnight; the job is scheduled when set
+	Timezone             string                  `json:"timezone,omitempty"`             // IANA time zone of the window (default: UTC)
+	Collections          []string                `json:"collections,omitempty"`          // collections to maintain (default: all)
+	DeletedDocsThreshold float64                 `json:"deletedDocsThreshold,omitempty"` // share of deleted documents that triggers a merge (default: 0.2)
+	Merge                string                  `json:"merge,omitempty"`                // "expungeDeletes" (default) or "optimize"
+	MaxSegments          int                     `json:"maxSegments,omitempty"`          // segments left by "optimize" (default: 1)
+	Retention            bool                    `json:"retention,omitempty"`            // run the retention policies
+	Backup               MaintenanceBackupConfig `json:"backup,omitempty"`
+}
+
+// MaintenanceBackupConfig backs up the maintained collections with the Collections API BACKUP action.
+type MaintenanceBackupConfig struct {
+	Location   string `json:"location,omitempty"`   // backup location on the Solr nodes or in the repository; backups run when set
+	Repository string `json:"repository,omitempty"` // backup repository configured in solr.xml (default: the local file system)
+	Keep       int    `json:"keep,omitempty"`       // backup points kept per collection (default: Solr's own)
+}
+
+// Maintenance defaults.
+const (
+	DefaultDeletedDocsThreshold = 0.2
+	MergeExpungeDeletes         = "expungeDeletes"
+	MergeOptimize               = "optimize"
+)
+
+// Threshold returns the share of deleted documents that triggers a merge.
+func (mc MaintenanceConfig) Threshold() float64 {
+	if mc.DeletedDocsThreshold <= 0 {
+		return DefaultDeletedDocsThreshold
+	}
+	return mc.DeletedDocsThreshold
+}
+
+// MergeMode returns how collections over the threshold are merged.
+func (mc MaintenanceConfig) MergeMode() string {
+	if mc.Merge == "" {
+		return MergeExpungeDeletes
+	}
+	return mc.Merge
+}
+
+// Location returns the time zone of the window.
+func (mc MaintenanceConfig) Location() (*time.Location, error) {
+	if mc.Timezone == "" {
+		return time.UTC, nil
+	}
+	return time.LoadLocation(mc.Timezone)
+}
+
+// ParseWindow parses a daily window "HH:MM-HH:MM" into its start and end as offsets from midnight. The end is
+// before the start for windows that span midnight.
+func ParseWindow(s string) (start, end time.Duration, err error) {
+	from, to, ok := strings.Cut(s, "-")
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid window %q, expected HH:MM-HH:MM", s)
+	}
+	clock := func(v string) (time.Duration, error) {
+		t, err := time.Parse("15:04", strings.TrimSpace(v))
+		if err != nil {
+			return 0, fmt.Errorf("invalid window %q, expected HH:MM-HH:MM", s)
+		}
+		return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+	}
+	if start, err = clock(from); err != nil {
+		return 0, 0, err
+	}
+	if end, err = clock(to); err != nil {
+		return 0, 0, err
+	}
+	if start == end {
+		return 0, 0, fmt.Errorf("invalid window %q, start and end are the same", s)
+	}
+	return start, end, nil
+}
+
+// TransformerConfig is one step reshaping the documents of a collection. Built-in types are
+// "rename", "convert", "localize" and "concat"; other types name transformers registered by embedders.
+type TransformerConfig struct {
+	Type      string         `json:"type"`
+	Field     string         `json:"field,omitempty"`     // source field of rename, convert and localize
+	Fields    []string       `json:"fields,omitempty"`    // source fields of concat
+	To        string         `json:"to,omitempty"`        // target field (default: the source field for convert and localize)
+	Factor    float64        `json:"factor,omitempty"`    // convert: value * factor + offset (default factor: 1)
+	Offset    float64        `json:"offset,omitempty"`    // convert
+	Decimals  *int           `json:"decimals,omitempty"`  // convert: round to this many decimals
+	Unit      string         `json:"unit,omitempty"`      // convert: appended to the value, e.g. "MB"
+	Timezone  string         `json:"timezone,omitempty"`  // localize: IANA time zone, e.g. "Asia/Tokyo"
+	Layout    string         `json:"layout,omitempty"`    // localize: Go time layout (default: RFC 3339)
+	Separator string         `json:"separator,omitempty"` // concat (default: " ")
+	Options   map[string]any `json:"options,omitempty"`   // passed to registered transformers
+}
+
+// QueryLimitsConfig sets default resource limits of queries. Zero values are not sent.
+// cpuAllowed, memAllowed and multiThreaded need Solr 9.6 or later and are left out for older versions.
+type QueryLimitsConfig struct {
+	TimeAllowed    int     `json:"timeAllowed,omitempty"`    // milliseconds of search time
+	CPUAllowed     int     `json:"cpuAllowed,omitempty"`     // milliseconds of CPU time
+	MemAllowed     float64 `json:"memAllowed,omitempty"`     // megabytes of memory allocated by the request thread
+	MultiThreaded  bool    `json:"multiThreaded,omitempty"`  // search segments in parallel
+	PartialResults *bool   `json:"partialResults,omitempty"` // return partial results when a limit is hit (Solr default: true)
+	MaxRows        int     `json:"maxRows,omitempty"`        // larger rows are lowered to this, also for other backends
+}
+
+// RetentionPolicy removes data older than MaxAge, either documents of Collection by DateField,
+// or whole collections named CollectionPrefix followed by a date in DateLayout
+// (e.g. daily collections, or "logs__TRA__" for a time-routed alias).
+type RetentionPolicy struct {
+	Name             string `json:"name"`
+	Collection       string `json:"collection,omitempty"`
+	DateField        string `json:"dateField,omitempty"`
+	CollectionPrefix string `json:"collectionPrefix,omitempty"`
+	DateLayout       string `json:"dateLayout,omitempty"` // Go time layout of the collection name suffix (default: 2006-01-02)
+	MaxAge           string `json:"maxAge"`               // e.g. "30d" or "12h"
+}
+
+// Layout returns the date layout of collection name suffixes.
+func (p RetentionPolicy) Layout() string {
+	if p.DateLayout == "" {
+		return "2006-01-02"
+	}
+	return p.DateLayout
+}
+
+// ParseAge parses a Go duration string that may also use a "d" (days) suffix, e.g. "30d".
+func ParseAge(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("invalid age %q", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid age %q", s)
+	}
+	return d, nil
+}
+
+var metricNameRe = regexp.MustCompile(`^[a-zA-Z_:][a-zA-Z0-9_:]*$`)
+
+// IntervalDuration returns the evaluation interval of the exporter.
+func (e ExporterConfig) IntervalDuration() time.Duration {
+	if d, err := time.ParseDuration(e.Interval); err == nil && d > 0 {
+		return d
+	}
+	return time.Minute
+}
+
+// LoadFileConfig reads the config file at path.
+// An empty path returns an empty configuration.
+func LoadFileConfig(path string) (*FileConfig, error) {
+	fc := &FileConfig{}
+	if strings.TrimSpace(path) == "" {
+		return fc, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read config file: %v", err)
+	}
+	if err := json.Unmarshal(data, fc); err != nil {
+		return nil, fmt.Errorf("decode config file: %v", err)
+	}
+	if err := fc.Validate(); err != nil {
+		return nil, err
+	}
+	return fc, nil
+}
+
+// Validate checks the configuration for obvious mistakes.
+func (fc *FileConfig) Validate() error {
+	seen := map[string]bool{}
+	for i, q := range fc.SavedQueries {
+		if !metricNameRe.MatchString(q.Name) {
+			return fmt.Errorf("savedQueries[%d]: invalid name %q (must match %s)", i, q.Name, metricNameRe.String())
+		}
+		if seen[q.Name] {
+			return fmt.Errorf("savedQueries[%d]: duplicate name %q", i, q.Name)
+		}
+		seen[q.Name] = true
+		if strings.TrimSpace(q.Collection) == "" {
+			return fmt.Errorf("savedQueries[%d]: collection is required", i)
+		}
+		for k := range q.Labels {
+			if !metricNameRe.MatchString(k) || strings.Contains(k, ":") {
+				return fmt.Errorf("savedQueries[%d]: invalid label name %q", i, k)
+			}
+		}
+	}
+	if fc.Exporter.Interval != "" {
+		if _, err := time.ParseDuration(fc.Exporter.Interval); err != nil {
+			return fmt.Errorf("exporter.interval: %v", err)
+		}
+	}
+	if fc.QueryLimits.TimeAllowed < 0 || fc.QueryLimits.CPUAllowed < 0 || fc.QueryLimits.MemAllowed < 0 || fc.QueryLimits.MaxRows < 0 {
+		return fmt.Errorf("queryLimits: limits must not be negative")
+	}
+	if err := fc.PostProcessing.validate(); err != nil {
+		return err
+	}
+	if err := fc.Drift.validate(); err != nil {
+		return err
+	}
+	for collection, steps := range fc.Transformers {
+		for i, tc := range steps {
+			if err := tc.validate(); err != nil {
+				return fmt.Errorf("transformers[%s][%d]: %v", collection, i, err)
+			}
+		}
+	}
+	seen = map[string]bool{}
+	for i, ec := range fc.Experiments {
+		if strings.TrimSpace(ec.Name) == "" {
+			return fmt.Errorf("experiments[%d]: name is required", i)
+		}
+		if seen[ec.Name] {
+			return fmt.Errorf("experiments[%d]: duplicate name %q", i, ec.Name)
+		}
+		seen[ec.Name] = true
+		if ec.Fraction <= 0 || ec.Fraction > 1 {
+			return fmt.Errorf("experiments[%d]: fraction must be greater than 0 and at most 1", i)
+		}
+		if len(ec.Params) == 0 {
+			return fmt.Errorf("experiments[%d]: params is required", i)
+		}
+	}
+	for name, limit := range fc.ToolArgsLimits {
+		if limit < 0 {
+			return fmt.Errorf("toolArgsLimits[%s]: must not be negative", name)
+		}
+	}
+	if fc.ResponseBudget.MaxBytes < 0 {
+		return fmt.Errorf("responseBudget.maxBytes: must not be negative")
+	}
+	if fc.Jobs.MaxRequestsPerSecond < 0 || fc.Jobs.MaxBytesPerSecond < 0 {
+		return fmt.Errorf("jobs: limits must not be negative")
+	}
+	if fc.MemoryBudget.MaxBytes < 0 || fc.MemoryBudget.MaxConcurrent < 0 {
+		return fmt.Errorf("memoryBudget: limits must not be negative")
+	}
+	if w := fc.MemoryBudget.MaxWait; w != "" {
+		if d, err := time.ParseDuration(w); err != nil || d < 0 {
+			return fmt.Errorf("memoryBudget.maxWait: invalid duration %q", w)
+		}
+	}
+	switch fc.QuerySafety.Mode {
+	case "", SafetyConfirm, SafetyRewrite, SafetyOff:
+	default:
+		return fmt.Errorf("querySafety.mode: unknown mode %q (use %s, %s or %s)", fc.QuerySafety.Mode, SafetyConfirm, SafetyRewrite, SafetyOff)
+	}
+	if fc.QuerySafety.MaxExpansion < 0 {
+		return fmt.Errorf("querySafety.maxExpansion: must not be negative")
+	}
+	if t := fc.SlowQuery.Threshold; t != "" {
+		if d, err := time.ParseDuration(t); err != nil || d < 0 {
+			return fmt.Errorf("slowQuery.threshold: invalid duration %q", t)
+		}
+	}
+	if err := fc.SolrExporter.validate(); err != nil {
+		return err
+	}
+	for name, oc := range fc.ObjectStores {
+		if err := oc.validate(); err != nil {
+			return fmt.Errorf("objectStores[%s]: %v", name, err)
+		}
+	}
+	if err := fc.Policy.validate(); err != nil {
+		return err
+	}
+	if u := fc.Notifier.WebhookURL; u != "" && !strings.HasPrefix(u, "http://") && !strings.HasPrefix(u, "https://") {
+		return fmt.Errorf("notifier.webhookUrl: must be an http or https URL")
+	}
+	if err := fc.Maintenance.validate(); err != nil {
+		return err
+	}
+	return fc.Retention.validate()
+}
+
+func (pc PostProcessingConfig) validate() error {
+	for i, name := range pc.Pipeline {
+		if strings.TrimSpace(name) == "" {
+			return fmt.Errorf("postProcessing.pipeline[%d]: processor name is required", i)
+		}
+	}
+	for tool, names := range pc.Tools {
+		for i, name := range names {
+			if strings.TrimSpace(name) == "" {
+				return fmt.Errorf("postProcessing.tools[%s][%d]: processor name is required", tool, i)
+			}
+		}
+	}
+	if pc.MaxStringLength < 0 || pc.MaxArrayLength < 0 {
+		return fmt.Errorf("postProcessing: limits must not be negative")
+	}
+	return nil
+}
+
+// validate checks the settings of the built-in transformer types. Other types are checked when they are applied.
+func (tc TransformerConfig) validate() error {
+	switch tc.Type {
+	case "":
+		return fmt.Errorf("type is required")
+	case "rename":
+		if tc.Field == "" || tc.To == "" {
+			return fmt.Errorf("rename requires field and to")
+		}
+	case "convert":
+		if tc.Field == "" {
+			return fmt.Errorf("convert requires field")
+		}
+		if tc.Decimals != nil && *tc.Decimals < 0 {
+			return fmt.Errorf("decimals must not be negative")
+		}
+	case "localize":
+		if tc.Field == "" || tc.Timezone == "" {
+			return fmt.Errorf("localize requires field and timezone")
+		}
+		if _, err := time.LoadLocation(tc.Timezone); err != nil {
+			return fmt.Errorf("timezone: %v", err)
+		}
+	case "concat":
+		if len(tc.Fields) == 0 || tc.To == "" {
+			return fmt.Errorf("concat requires fields and to")
+		}
+	}
+	return nil
+}
+
+func (dc DriftConfig) validate() error {
+	if dc.Interval != "" {
+		if d, err := time.ParseDuration(dc.Interval); err != nil || d <= 0 {
+			return fmt.Errorf("drift.interval: invalid duration %q", dc.Interval)
+		}
+	}
+	if dc.Keep < 0 {
+		return fmt.Errorf("drift.keep: must not be negative")
+	}
+	return nil
+}
+
+func (mc MaintenanceConfig) validate() error {
+	if mc.Window != "" {
+		if _, _, err := ParseWindow(mc.Window); err != nil {
+			return fmt.Errorf("maintenance.window: %v", err)
+		}
+	}
+	if _, err := mc.Location(); err != nil {
+		return fmt.Errorf("maintenance.timezone: %v", err)
+	}
+	if mc.DeletedDocsThreshold < 0 || mc.DeletedDocsThreshold >= 1 {
+		return fmt.Errorf("maintenance.deletedDocsThreshold: must be between 0 and 1")
+	}
+	if m := mc.Merge; m != "" && m != MergeExpungeDeletes && m != MergeOptimize {
+		return fmt.Errorf("maintenance.merge: must be %q or %q, got %q", MergeExpungeDeletes, MergeOptimize, m)
+	}
+	if mc.MaxSegments < 0 || mc.Backup.Keep < 0 {
+		return fmt.Errorf("maintenance: maxSegments and backup.keep must not be negative")
+	}
+	return nil
+}
+
+func (rc RetentionConfig) validate() error {
+	if rc.Interval != "" {
+		if d, err := time.ParseDuration(rc.Interval); err != nil || d <= 0 {
+			return fmt.Errorf("retention.interval: invalid duration %q", rc.Interval)
+		}
+	}
+	seen := map[string]bool{}
+	for i, p := range rc.Policies {
+		if strings.TrimSpace(p.Name) == "" {
+			return fmt.Errorf("retention.policies[%d]: name is required", i)
+		}
+		if seen[p.Name] {
+			return fmt.Errorf("retention.policies[%d]: duplicate name %q", i, p.Name)
+		}
+		seen[p.Name] = true
+		switch {
+		case p.Collection != "" && p.CollectionPrefix != "":
+			return fmt.Errorf("retention.policies[%d]: collection and collectionPrefix are mutually exclusive", i)
+		case p.Collection != "" && p.DateField == "":
+			return fmt.Errorf("retention.policies[%d]: dateField is required with collection", i)
+		case p.Collection == "" && p.CollectionPrefix == "":
+			return fmt.Errorf("retention.policies[%d]: collection or collectionPrefix is required", i)
+		}
+		if _, err := ParseAge(p.MaxAge); err != nil {
+			return fmt.Errorf("retention.policies[%d]: maxAge: %v", i, err)
+		}
+	}
+	return nil
+}
+
+// RetentionPolicy looks up a retention policy by name.
+func (fc *FileConfig) RetentionPolicy(name string) (RetentionPolicy, bool) {
+	if fc == nil {
+		return RetentionPolicy{}, false
+	}
+	for _, p := range fc.Retention.Policies {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return RetentionPolicy{}, false
+}
+
+// SavedQuery looks up a saved query by name.
+func (fc *FileConfig) SavedQuery(name string) (SavedQuery, bool) {
+	if fc == nil {
+		return SavedQuery{}, false
+	}
+	for _, q := range fc.SavedQueries {
+		if q.Name == name {
+			return q, true
+		}
+	}
+	return SavedQuery{}, false
+}
+
+// ToolArgsLimit returns the argument size limit of the tool in ToolArgsLimits, or def when it has none.
+func (fc *FileConfig) ToolArgsLimit(name string, def int) (int, bool) {
+	if fc == nil {
+		return def, false
+	}
+	if limit, ok := fc.ToolArgsLimits[name]; ok {
+		return limit, true
+	}
+	return def, false
+}
+
+// ToolDisabled reports whether the tool is listed in DisabledTools.
+func (fc *FileConfig) ToolDisabled(name string) bool {
+	if fc == nil {
+		return false
+	}
+	for _, n := range fc.DisabledTools {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// WatchFileConfig polls the config file at path and calls onChange with the new configuration
+// whenever its modification time or size changes. Invalid configurations are logged and ignored.
+// It blocks until ctx is done.
+func WatchFileConfig(ctx context.Context, path string, interval time.Duration, onChange func(*FileConfig)) {
+	stat := func() (time.Time, int64) {
+		fi, err := os.Stat(path)
+		if err != nil {
+			return time.Time{}, -1
+		}
+		return fi.ModTime(), fi.Size()
+	}
+	lastMod, lastSize := stat()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			mod, size := stat()
+			if mod.Equal(lastMod) && size == lastSize {
+				continue
+			}
+			lastMod, lastSize = mod, size
+			fc, err := LoadFileConfig(path)
+			if err != nil {
+				slog.Error("Failed to reload config file, keeping the current configuration", "path", path, "error", err)
+				continue
+			}
+			slog.Info("Config file reloaded", "path", path)
+			onChange(fc)
+		}
+	}
+}
@@ -0,0 +1,263 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// writeConfigFile writes content to a temporary config file and returns its path.
+func writeConfigFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+	return path
+}
+
+// TestLoadFileConfig tests the LoadFileConfig function.
+func TestLoadFileConfig(t *testing.T) {
+	// Goal: An empty path yields an empty configuration without error.
+	t.Run("Empty path", func(t *testing.T) {
+		fc, err := LoadFileConfig("")
+		assert.NoError(t, err)
+		assert.Empty(t, fc.SavedQueries)
+		assert.False(t, fc.Exporter.Enabled)
+	})
+
+	// Goal: Saved queries and exporter settings are decoded.
+	t.Run("Valid config", func(t *testing.T) {
+		path := writeConfigFile(t, `{
+			"savedQueries": [
+				{"name": "solr_errors_last_5m", "collection": "logs", "query": "level:ERROR",
+				 "fq": ["timestamp:[NOW-5MINUTES TO NOW]"], "labels": {"service": "api"}}
+			],
+			"exporter": {"enabled": true, "interval": "30s"}
+		}`)
+
+		fc, err := LoadFileConfig(path)
+		assert.NoError(t, err)
+		assert.Len(t, fc.SavedQueries, 1)
+		assert.Equal(t, "api", fc.SavedQueries[0].Labels["service"])
+		assert.True(t, fc.Exporter.Enabled)
+		assert.Equal(t, 30*time.Second, fc.Exporter.IntervalDuration())
+	})
+
+	// Goal: A missing file is reported as an error.
+	t.Run("Missing file", func(t *testing.T) {
+		_, err := LoadFileConfig(filepath.Join(t.TempDir(), "missing.json"))
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "read config file")
+	})
+
+	// Goal: Malformed JSON is reported as an error.
+	t.Run("Invalid JSON", func(t *testing.T) {
+		_, err := LoadFileConfig(writeConfigFile(t, `{"savedQueries": [`))
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "decode config file")
+	})
+}
+
+// TestFileConfigValidate tests the Validate method.
+func TestFileConfigValidate(t *testing.T) {
+	testCases := []struct {
+		name    string
+		fc      FileConfig
+		wantErr string
+	}{
+		{
+			name:    "invalid metric name",
+			fc:      FileConfig{SavedQueries: []SavedQuery{{Name: "errors-5m", Collection: "logs"}}},
+			wantErr: "invalid name",
+		},
+		{
+			name: "duplicate name",
+			fc: FileConfig{SavedQueries: []SavedQuery{
+				{Name: "errors", Collection: "logs"},
+				{Name: "errors", Collection: "logs"},
+			}},
+			wantErr: "duplicate name",
+		},
+		{
+			name:    "missing collection",
+			fc:      FileConfig{SavedQueries: []SavedQuery{{Name: "errors"}}},
+			wantErr: "collection is required",
+		},
+		{
+			name: "invalid label name",
+			fc: FileConfig{SavedQueries: []SavedQuery{
+				{Name: "errors", Collection: "logs", Labels: map[string]string{"my-label": "x"}},
+			}},
+			wantErr: "invalid label name",
+		},
+		{
+			name:    "invalid interval",
+			fc:      FileConfig{Exporter: ExporterConfig{Interval: "soon"}},
+			wantErr: "exporter.interval",
+		},
+		{
+			name:    "retention policy without date field",
+			fc:      FileConfig{Retention: RetentionConfig{Policies: []RetentionPolicy{{Name: "logs", Collection: "logs", MaxAge: "30d"}}}},
+			wantErr: "dateField is required",
+		},
+		{
+			name:    "retention policy without target",
+			fc:      FileConfig{Retention: RetentionConfig{Policies: []RetentionPolicy{{Name: "logs", MaxAge: "30d"}}}},
+			wantErr: "collection or collectionPrefix is required",
+		},
+		{
+			name:    "retention policy with invalid max age",
+			fc:      FileConfig{Retention: RetentionConfig{Policies: []RetentionPolicy{{Name: "logs", CollectionPrefix: "logs_", MaxAge: "a month"}}}},
+			wantErr: "maxAge",
+		},
+		{
+			name:    "post-processing pipeline with empty processor name",
+			fc:      FileConfig{PostProcessing: PostProcessingConfig{Tools: map[string][]string{"solr.query": {"redact", " "}}}},
+			wantErr: "postProcessing.tools[solr.query][1]",
+		},
+		{
+			name:    "negative query limit",
+			fc:      FileConfig{QueryLimits: QueryLimitsConfig{CPUAllowed: -1}},
+			wantErr: "queryLimits",
+		},
+		{
+			name:    "invalid maintenance window",
+			fc:      FileConfig{Maintenance: MaintenanceConfig{Window: "02:00"}},
+			wantErr: "maintenance.window",
+		},
+		{
+			name:    "unknown maintenance merge",
+			fc:      FileConfig{Maintenance: MaintenanceConfig{Window: "22:30-01:00", Merge: "forceMerge"}},
+			wantErr: "maintenance.merge",
+		},
+		{
+			name:    "invalid drift interval",
+			fc:      FileConfig{Drift: DriftConfig{Interval: "nightly"}},
+			wantErr: "drift.interval",
+		},
+		{
+			name:    "webhook without scheme",
+			fc:      FileConfig{Notifier: NotifierConfig{WebhookURL: "hooks.example.com/alerts"}},
+			wantErr: "notifier.webhookUrl",
+		},
+		{
+			name: "transformer with unknown time zone",
+			fc: FileConfig{Transformers: map[string][]TransformerConfig{
+				"logs": {{Type: "localize", Field: "timestamp", Timezone: "Mars/Olympus"}},
+			}},
+			wantErr: "transformers[logs][0]: timezone",
+		},
+		{
+			name:    "experiment with out of range fraction",
+			fc:      FileConfig{Experiments: []ExperimentConfig{{Name: "boost", Fraction: 1.5, Params: map[string]string{"bq": "inStock:true"}}}},
+			wantErr: "experiments[0]: fraction",
+		},
+		{
+			name:    "unknown query safety mode",
+			fc:      FileConfig{QuerySafety: QuerySafetyConfig{Mode: "block"}},
+			wantErr: "querySafety.mode",
+		},
+		{
+			name:    "object store without bucket",
+			fc:      FileConfig{ObjectStores: map[string]ObjectStoreConfig{"backups": {Type: ObjectStoreGCS}}},
+			wantErr: "objectStores[backups]: bucket is required",
+		},
+		{
+			name:    "invalid solr-exporter metric pattern",
+			fc:      FileConfig{SolrExporter: SolrExporterConfig{URL: "http://exporter:9854/metrics", Metrics: []string{"solr_(cache"}}},
+			wantErr: "solrExporter.metrics[0]",
+		},
+		{
+			name:    "invalid slow query threshold",
+			fc:      FileConfig{SlowQuery: SlowQueryConfig{Threshold: "2 seconds"}},
+			wantErr: "slowQuery.threshold",
+		},
+		{
+			name:    "invalid policy rule effect",
+			fc:      FileConfig{Policy: PolicyConfig{Rules: []PolicyRule{{Name: "hr", When: "collection == 'hr'", Effect: "block"}}}},
+			wantErr: "policy.rules[0]: effect must be allow or deny",
+		},
+		{
+			name:    "invalid memory budget wait",
+			fc:      FileConfig{MemoryBudget: MemoryBudgetConfig{MaxBytes: 1 << 30, MaxWait: "-1s"}},
+			wantErr: "memoryBudget.maxWait",
+		},
+		{
+			name:    "negative tool argument limit",
+			fc:      FileConfig{ToolArgsLimits: map[string]int{"solr.update": -1}},
+			wantErr: "toolArgsLimits[solr.update]",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.fc.Validate()
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tc.wantErr)
+		})
+	}
+}
+
+// TestExporterConfigIntervalDuration tests the default exporter interval.
+func TestExporterConfigIntervalDuration(t *testing.T) {
+	assert.Equal(t, time.Minute, ExporterConfig{}.IntervalDuration())
+	assert.Equal(t, time.Minute, ExporterConfig{Interval: "-5s"}.IntervalDuration())
+}
+
+// TestParseAge tests parsing of ages with a day suffix.
+func TestParseAge(t *testing.T) {
+	d, err := ParseAge("30d")
+	assert.NoError(t, err)
+	assert.Equal(t, 30*24*time.Hour, d)
+
+	d, err = ParseAge("12h")
+	assert.NoError(t, err)
+	assert.Equal(t, 12*time.Hour, d)
+
+	for _, invalid := range []string{"", "0d", "xd", "-1h"} {
+		_, err = ParseAge(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+// TestToolDisabled tests the ToolDisabled method.
+func TestToolDisabled(t *testing.T) {
+	fc := &FileConfig{DisabledTools: []string{"solr.query"}}
+	assert.True(t, fc.ToolDisabled("solr.query"))
+	assert.False(t, fc.ToolDisabled("solr.ping"))
+
+	var nilConfig *FileConfig
+	assert.False(t, nilConfig.ToolDisabled("solr.query"))
+}
+
+// TestWatchFileConfig tests that changes to the config file are picked up and invalid changes are ignored.
+func TestWatchFileConfig(t *testing.T) {
+	path := writeConfigFile(t, `{"disabledTools": []}`)
+	changes := make(chan *FileConfig, 10)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go WatchFileConfig(ctx, path, 10*time.Millisecond, func(fc *FileConfig) { changes <- fc })
+
+	// Goal: An invalid file is not reported.
+	time.Sleep(30 * time.Millisecond)
+	os.WriteFile(path, []byte(`{"disabledTools": [`), 0o600)
+	select {
+	case <-changes:
+		t.Fatal("invalid config should not be reported")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	// Goal: A valid change is reported.
+	os.WriteFile(path, []byte(`{"disabledTools": ["solr.query"]}`), 0o600)
+	select {
+	case fc := <-changes:
+		assert.Equal(t, []string{"solr.query"}, fc.DisabledTools)
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected a config change")
+	}
+}
@@ -0,0 +1,75 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// NewHTTPClient creates the HTTP client for outbound Solr requests. Requests go through proxyURL when it is set,
+// except for hosts matched by NO_PROXY; otherwise HTTP_PROXY, HTTPS_PROXY and NO_PROXY apply as usual.
+func NewHTTPClient(proxyURL string) (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if strings.TrimSpace(proxyURL) != "" {
+		u, err := url.Parse(proxyURL)
+		if err != nil || u.Host == "" {
+			return nil, fmt.Errorf("invalid proxy URL %q", proxyURL)
+		}
+		transport.Proxy = fixedProxy(u, GetEnv("NO_PROXY", os.Getenv("no_proxy")))
+	}
+	return &http.Client{Timeout: 30 * time.Second, Transport: transport}, nil
+}
+
+// fixedProxy returns a proxy function sending all requests to proxy except those for hosts matched by noProxy.
+func fixedProxy(proxy *url.URL, noProxy string) func(*http.Request) (*url.URL, error) {
+	return func(req *http.Request) (*url.URL, error) {
+		if bypassProxy(req.URL.Host, noProxy) {
+			return nil, nil
+		}
+		return proxy, nil
+	}
+}
+
+// bypassProxy reports whether host is a loopback address or matched by the comma-separated NO_PROXY list.
+// Entries are host names, which also match their subdomains, IP addresses, CIDR ranges, host:port pairs and "*".
+func bypassProxy(hostport, noProxy string) bool {
+	host, port, err := net.SplitHostPort(hostport)
+	if err != nil {
+		host = hostport
+	}
+	host = strings.ToLower(strings.Trim(host, "[]"))
+	ip := net.ParseIP(host)
+	if host == "localhost" || (ip != nil && ip.IsLoopback()) {
+		return true
+	}
+	for _, entry := range strings.Split(noProxy, ",") {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		switch {
+		case entry == "":
+			continue
+		case entry == "*":
+			return true
+		}
+		if _, cidr, err := net.ParseCIDR(entry); err == nil {
+			if ip != nil && cidr.Contains(ip) {
+				return true
+			}
+			continue
+		}
+		if h, p, err := net.SplitHostPort(entry); err == nil {
+			if p != port {
+				continue
+			}
+			entry = h
+		}
+		entry = strings.TrimPrefix(strings.TrimPrefix(entry, "*"), ".")
+		if host == entry || strings.HasSuffix(host, "."+entry) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,55 @@
+package config
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestNewHTTPClient tests that an explicit proxy URL is used except for NO_PROXY hosts.
+func TestNewHTTPClient(t *testing.T) {
+	t.Setenv("NO_PROXY", "solr.internal,10.0.0.0/8")
+
+	// Goal: Without a proxy URL the environment proxy settings apply.
+	client, err := NewHTTPClient("")
+	require.NoError(t, err)
+	assert.NotNil(t, client.Transport.(*http.Transport).Proxy)
+
+	// Goal: Requests go through the proxy unless NO_PROXY matches.
+	client, err = NewHTTPClient("http://proxy.corp:3128")
+	require.NoError(t, err)
+	proxy := client.Transport.(*http.Transport).Proxy
+	for host, want := range map[string]string{
+		"solr.example.com:8983": "http://proxy.corp:3128",
+		"solr.internal:8983":    "",
+		"node1.solr.internal":   "",
+		"10.1.2.3:8983":         "",
+		"localhost:8983":        "",
+	} {
+		got, err := proxy(&http.Request{URL: &url.URL{Scheme: "http", Host: host}})
+		require.NoError(t, err)
+		if want == "" {
+			assert.Nil(t, got, host)
+		} else {
+			assert.Equal(t, want, got.String(), host)
+		}
+	}
+
+	// Goal: Invalid proxy URLs are rejected.
+	_, err = NewHTTPClient("proxy.corp")
+	assert.Error(t, err)
+}
+
+// TestBypassProxy tests the NO_PROXY matching rules.
+func TestBypassProxy(t *testing.T) {
+	assert.True(t, bypassProxy("any:80", "*"))
+	assert.True(t, bypassProxy("a.example.com", ".example.com"))
+	assert.True(t, bypassProxy("solr:8983", "solr:8983"))
+	assert.False(t, bypassProxy("solr:8984", "solr:8983"))
+	assert.False(t, bypassProxy("notexample.com", "example.com"))
+	assert.True(t, bypassProxy("[::1]:8983", ""))
+	assert.False(t, bypassProxy("192.168.1.1", "10.0.0.0/8"))
+}
@@ -0,0 +1,279 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Secret reference prefixes accepted in the value of a secret environment variable.
+const (
+	vaultPrefix = "vault:"  // vault:<path>#<key>, read from VAULT_ADDR with VAULT_TOKEN
+	awsSMPrefix = "aws-sm:" // aws-sm:<secret id>[#<key>], read from AWS Secrets Manager
+)
+
+// secretHTTPClient is used for Vault and AWS Secrets Manager requests.
+var secretHTTPClient = &http.Client{Timeout: 10 * time.Second}
+
+// GetSecret returns the value of a secret environment variable. The value is read, in order of precedence, from:
+//   - the file named by key + "_FILE" (Docker and Kubernetes secrets), without a trailing newline;
+//   - HashiCorp Vault, when the variable holds a "vault:<path>#<key>" reference;
+//   - AWS Secrets Manager, when the variable holds an "aws-sm:<secret id>[#<key>]" reference;
+//   - the variable itself.
+//
+// Secrets are only kept in memory; they are never written back to the environment.
+func GetSecret(ctx context.Context, key string) (string, error) {
+	if path := os.Getenv(key + "_FILE"); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("%s_FILE: %v", key, err)
+		}
+		return strings.TrimRight(string(data), "\r\n"), nil
+	}
+	v := os.Getenv(key)
+	switch {
+	case strings.HasPrefix(v, vaultPrefix):
+		s, err := vaultSecret(ctx, strings.TrimPrefix(v, vaultPrefix))
+		if err != nil {
+			return "", fmt.Errorf("%s: %v", key, err)
+		}
+		return s, nil
+	case strings.HasPrefix(v, awsSMPrefix):
+		s, err := awsSecret(ctx, strings.TrimPrefix(v, awsSMPrefix))
+		if err != nil {
+			return "", fmt.Errorf("%s: %v", key, err)
+		}
+		return s, nil
+	}
+	return v, nil
+}
+
+// vaultSecret reads a field of a KV secret (version 1 or 2) from Vault. ref is "<path>#<key>",
+// e.g. "secret/data/solr#password" for the KV v2 engine mounted at secret/.
+func vaultSecret(ctx context.Context, ref string) (string, error) {
+	path, field, ok := strings.Cut(ref, "#")
+	if !ok || path == "" || field == "" {
+		return "", fmt.Errorf("invalid Vault reference %q (want vault:<path>#<key>)", vaultPrefix+ref)
+	}
+	addr := strings.TrimRight(os.Getenv("VAULT_ADDR"), "/")
+	if addr == "" {
+		return "", fmt.Errorf("VAULT_ADDR is not set")
+	}
+	token, err := GetSecret(ctx, "VAULT_TOKEN")
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, addr+"/v1/"+strings.TrimLeft(path, "/"), nil)
+	if err != nil {
+		return "", fmt.Errorf("create Vault request: %v", err)
+	}
+	req.Header.Set("X-Vault-Token", token)
+	if ns := os.Getenv("VAULT_NAMESPACE"); ns != "" {
+		req.Header.Set("X-Vault-Namespace", ns)
+	}
+	var resp struct {
+		Data map[string]any `json:"data"`
+	}
+	if err := doSecretRequest(req, &resp); err != nil {
+		return "", fmt.Errorf("Vault %s: %v", path, err)
+	}
+	data := resp.Data
+	if nested, ok := data["data"].(map[string]any); ok {
+		// KV version 2 wraps the secret in data.data
+		data = nested
+	}
+	v, ok := data[field].(string)
+	if !ok {
+		return "", fmt.Errorf("Vault %s has no string field %q", path, field)
+	}
+	return v, nil
+}
+
+// awsSecret reads a secret from AWS Secrets Manager with the credentials of AWS_ACCESS_KEY_ID,
+// AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN in AWS_REGION. ref is "<secret id>[#<key>]";
+// with a key, the secret string is decoded as a JSON object and the key's value is returned.
+func awsSecret(ctx context.Context, ref string) (string, error) {
+	id, field, _ := strings.Cut(ref, "#")
+	if id == "" {
+		return "", fmt.Errorf("invalid AWS Secrets Manager reference %q (want aws-sm:<secret id>[#<key>])", awsSMPrefix+ref)
+	}
+	region := GetEnv("AWS_REGION", os.Getenv("AWS_DEFAULT_REGION"))
+	accessKey, secretKey := os.Getenv("AWS_ACCESS_KEY_ID"), os.Getenv("AWS_SECRET_ACCESS_KEY")
+	if region == "" || accessKey == "" || secretKey == "" {
+		return "", fmt.Errorf("AWS_REGION, AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY are required for AWS Secrets Manager")
+	}
+	endpoint := GetEnv("AWS_ENDPOINT_URL_SECRETS_MANAGER", "https://secretsmanager."+region+".amazonaws.com")
+
+	body, _ := json.Marshal(map[string]string{"SecretId": id})
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("create AWS request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "secretsmanager.GetSecretValue")
+	if token := os.Getenv("AWS_SESSION_TOKEN"); token != "" {
+		req.Header.Set("X-Amz-Security-Token", token)
+	}
+	signAWSv4(req, body, accessKey, secretKey, region, "secretsmanager", time.Now())
+
+	var resp struct {
+		SecretString string `json:"SecretString"`
+	}
+	if err := doSecretRequest(req, &resp); err != nil {
+		return "", fmt.Errorf("AWS Secrets Manager %s: %v", id, err)
+	}
+	if field == "" {
+		return resp.SecretString, nil
+	}
+	var fields map[string]any
+	if err := json.Unmarshal([]byte(resp.SecretString), &fields); err != nil {
+		return "", fmt.Errorf("AWS Secrets Manager %s is not a JSON object", id)
+	}
+	v, ok := fields[field].(string)
+	if !ok {
+		return "", fmt.Errorf("AWS Secrets Manager %s has no string field %q", id, field)
+	}
+	return v, nil
+}
+
+// signAWSv4 adds the AWS Signature Version 4 headers to req, signing all headers set so far.
+func signAWSv4(req *http.Request, body []byte, accessKey, secretKey, region, service string, now time.Time) {
+	payloadHash := sha256.Sum256(body)
+	SignAWSv4(req, hex.EncodeToString(payloadHash[:]), accessKey, secretKey, region, service, now)
+}
+
+// SignAWSv4 adds the AWS Signature Version 4 headers to req for a payload with the hex SHA-256 payloadHash,
+// signing all headers set so far. S3 also expects payloadHash in X-Amz-Content-Sha256.
+func SignAWSv4(req *http.Request, payloadHash, accessKey, secretKey, region, service string, now time.Time) {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+	req.Header.Set("X-Amz-Date", amzDate)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for k, vs := range req.Header {
+		values := make([]string, len(vs))
+		for i, v := range vs {
+			// values are trimmed and runs of spaces collapsed to one
+			values[i] = strings.Join(strings.Fields(v), " ")
+		}
+		headers[strings.ToLower(k)] = strings.Join(values, ",")
+	}
+	names := make([]string, 0, len(headers))
+	for k := range headers {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, k := range names {
+		canonicalHeaders.WriteString(k + ":" + headers[k] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	// The request is sent with the canonical path and query, so AWS decodes exactly what was signed
+	uri := canonicalURI(req.URL.Path)
+	req.URL.RawPath = uri
+	req.URL.RawQuery = canonicalQuery(req.URL.Query())
+	canonicalRequest := strings.Join([]string{
+		req.Method, uri, req.URL.RawQuery, canonicalHeaders.String(), signedHeaders, payloadHash,
+	}, "\n")
+
+	scope := date + "/" + region + "/" + service + "/aws4_request"
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	key := hmacSHA256([]byte("AWS4"+secretKey), date)
+	for _, part := range []string{region, service, "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		accessKey, scope, signedHeaders, signature))
+}
+
+// canonicalURI encodes the path of a SigV4 canonical request: each segment escaped like the query, the slashes kept.
+// The path is not normalized, as S3 requires, so S3 object keys such as "a$b" or "a+b" are signed as S3 reads them.
+func canonicalURI(path string) string {
+	if path == "" {
+		return "/"
+	}
+	segments := strings.Split(path, "/")
+	for i, s := range segments {
+		segments[i] = sigV4Escape(s)
+	}
+	return strings.Join(segments, "/")
+}
+
+// canonicalQuery encodes the query of a SigV4 canonical request: keys and values escaped as RFC 3986 requires,
+// with spaces as %20 rather than the + of url.Values.Encode, sorted by key and then by value.
+func canonicalQuery(q url.Values) string {
+	pairs := make([]string, 0, len(q))
+	for k, vs := range q {
+		for _, v := range vs {
+			pairs = append(pairs, sigV4Escape(k)+"="+sigV4Escape(v))
+		}
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, "&")
+}
+
+// sigV4Escape percent-encodes every byte of s except the unreserved characters A-Z, a-z, 0-9, '-', '.', '_' and '~'.
+func sigV4Escape(s string) string {
+	const hexDigits = "0123456789ABCDEF"
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' || c == '-' || c == '.' || c == '_' || c == '~' {
+			b.WriteByte(c)
+			continue
+		}
+		b.WriteByte('%')
+		b.WriteByte(hexDigits[c>>4])
+		b.WriteByte(hexDigits[c&15])
+	}
+	return b.String()
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+// doSecretRequest sends req and decodes the JSON response into out. Response bodies are not included
+// in errors, as they may contain secrets.
+func doSecretRequest(req *http.Request, out any) error {
+	res, err := secretHTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("HTTP request error: %v", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		_, _ = io.Copy(io.Discard, res.Body)
+		return fmt.Errorf("HTTP status %d", res.StatusCode)
+	}
+	if err := json.NewDecoder(res.Body).Decode(out); err != nil {
+		return fmt.Errorf("JSON decode error: %v", err)
+	}
+	return nil
+}
+
+// RedactURL removes the password of a URL with user info, for logs and tool output.
+func RedactURL(s string) string {
+	u, err := url.Parse(s)
+	if err != nil {
+		return s
+	}
+	return u.Redacted()
+}
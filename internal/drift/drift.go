@@ -0,0 +1,317 @@
+// Package drift detects changes of a collection's schema and config overlay made outside change
+// control, by storing snapshots and comparing them over time.
+package drift
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/config"
+)
+
+// defaultKeep is the number of snapshots kept per collection.
+const defaultKeep = 100
+
+// Change kinds.
+const (
+	KindAdded   = "added"
+	KindRemoved = "removed"
+	KindChanged = "changed"
+)
+
+// Snapshot is the schema and config overlay of a collection at one point in time.
+type Snapshot struct {
+	Collection    string         `json:"collection"`
+	TakenAt       time.Time      `json:"takenAt"`
+	Hash          string         `json:"hash"`
+	Schema        map[string]any `json:"schema"`
+	ConfigOverlay map[string]any `json:"configOverlay"`
+}
+
+// Change is a single difference between two snapshots. Category is "analyzer", "fieldType",
+// "field", "schema", "handler" or "config".
+type Change struct {
+	Path     string `json:"path"`
+	Kind     string `json:"kind"`
+	Category string `json:"category"`
+	Before   any    `json:"before,omitempty"`
+	After    any    `json:"after,omitempty"`
+}
+
+// Report is the result of comparing the current state of a collection with its latest snapshot.
+type Report struct {
+	Collection string     `json:"collection"`
+	CheckedAt  time.Time  `json:"checkedAt"`
+	First      bool       `json:"first,omitempty"`    // no earlier snapshot existed; the baseline was stored
+	Previous   *time.Time `json:"previous,omitempty"` // when the compared snapshot was taken
+	Changed    bool       `json:"changed"`
+	Changes    []Change   `json:"changes,omitempty"`
+}
+
+// Fetcher reads the current schema and config overlay of a collection.
+type Fetcher func(ctx context.Context, collection string) (schema, overlay map[string]any, err error)
+
+// Manager takes snapshots, stores those that differ from the latest one and notifies about drift.
+type Manager struct {
+	mu                sync.RWMutex
+	cfg               config.DriftConfig
+	defaultCollection string
+	store             *Store
+	fetch             Fetcher
+	notify            func(ctx context.Context, r Report)
+	now               func() time.Time
+}
+
+// NewManager creates a Manager. notify is called for every check that found changes.
+func NewManager(cfg config.DriftConfig, defaultCollection string, store *Store, fetch Fetcher, notify func(context.Context, Report)) *Manager {
+	return &Manager{cfg: cfg, defaultCollection: defaultCollection, store: store, fetch: fetch, notify: notify, now: time.Now}
+}
+
+// SetConfig replaces the configuration, e.g. after a config reload.
+func (m *Manager) SetConfig(cfg config.DriftConfig) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.cfg = cfg
+}
+
+// Collections returns the collections checked on schedule.
+func (m *Manager) Collections() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if len(m.cfg.Collections) > 0 {
+		return m.cfg.Collections
+	}
+	if m.defaultCollection != "" {
+		return []string{m.defaultCollection}
+	}
+	return nil
+}
+
+// Check snapshots collection and compares it with the latest stored snapshot. The snapshot is
+// stored when it is the first one or differs from the latest.
+func (m *Manager) Check(ctx context.Context, collection string) (Report, error) {
+	schema, overlay, err := m.fetch(ctx, collection)
+	if err != nil {
+		return Report{}, fmt.Errorf("snapshot %s: %v", collection, err)
+	}
+	snap := Snapshot{Collection: collection, TakenAt: m.now().UTC(), Schema: schema, ConfigOverlay: overlay}
+	snap.Hash = hash(snap)
+	report := Report{Collection: collection, CheckedAt: snap.TakenAt}
+
+	history, err := m.store.Load(collection)
+	if err != nil {
+		return report, err
+	}
+	m.mu.RLock()
+	keep := m.cfg.Keep
+	m.mu.RUnlock()
+	if keep <= 0 {
+		keep = defaultKeep
+	}
+	if len(history) == 0 {
+		report.First = true
+		return report, m.store.Append(snap, keep)
+	}
+	last := history[len(history)-1]
+	report.Previous = &last.TakenAt
+	if last.Hash == snap.Hash {
+		return report, nil
+	}
+	report.Changed = true
+	report.Changes = Diff(last, snap)
+	if err := m.store.Append(snap, keep); err != nil {
+		return report, err
+	}
+	if m.notify != nil {
+		m.notify(ctx, report)
+	}
+	return report, nil
+}
+
+// RunScheduled checks the configured collections on the configured interval until ctx is done.
+// It returns immediately when no interval is configured.
+func (m *Manager) RunScheduled(ctx context.Context) {
+	m.mu.RLock()
+	interval, err := time.ParseDuration(m.cfg.Interval)
+	m.mu.RUnlock()
+	if err != nil || interval <= 0 {
+		return
+	}
+	check := func() {
+		for _, c := range m.Collections() {
+			if _, err := m.Check(ctx, c); err != nil {
+				slog.Error("Scheduled drift check failed", "collection", c, "error", err)
+			}
+		}
+	}
+	check()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			check()
+		}
+	}
+}
+
+// TimelineEntry is a stored snapshot and its changes compared with the snapshot before it.
+type TimelineEntry struct {
+	TakenAt time.Time `json:"takenAt"`
+	Hash    string    `json:"hash"`
+	Changes []Change  `json:"changes,omitempty"`
+}
+
+// Timeline lists the stored snapshots of a collection taken between From and To.
+type Timeline struct {
+	Collection string          `json:"collection"`
+	Snapshots  []TimelineEntry `json:"snapshots"`
+	// Net is the difference between the first and the last listed snapshot.
+	Net []Change `json:"net"`
+}
+
+// Timeline returns the stored snapshots of collection taken in [from, to]. Zero times are open bounds.
+func (m *Manager) Timeline(collection string, from, to time.Time) (Timeline, error) {
+	history, err := m.store.Load(collection)
+	if err != nil {
+		return Timeline{}, err
+	}
+	tl := Timeline{Collection: collection, Snapshots: []TimelineEntry{}, Net: []Change{}}
+	var first, last *Snapshot
+	for i := range history {
+		s := &history[i]
+		if (!from.IsZero() && s.TakenAt.Before(from)) || (!to.IsZero() && s.TakenAt.After(to)) {
+			continue
+		}
+		entry := TimelineEntry{TakenAt: s.TakenAt, Hash: s.Hash}
+		if i > 0 {
+			entry.Changes = Diff(history[i-1], *s)
+		}
+		tl.Snapshots = append(tl.Snapshots, entry)
+		if first == nil {
+			first = s
+		}
+		last = s
+	}
+	if first != nil && first != last {
+		tl.Net = Diff(*first, *last)
+	}
+	return tl, nil
+}
+
+// hash identifies the content of a snapshot. json.Marshal sorts map keys, so equal content has equal hashes.
+func hash(s Snapshot) string {
+	b, _ := json.Marshal([]any{s.Schema, s.ConfigOverlay})
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+// ignoredKeys change on every edit without describing the edit itself.
+var ignoredKeys = map[string]bool{"znodeVersion": true}
+
+// Diff lists the differences between two snapshots. Arrays of named objects (fields, field types,
+// dynamic fields) are compared by name, so reordering is not a change.
+func Diff(before, after Snapshot) []Change {
+	var out []Change
+	diffValue("schema", before.Schema, after.Schema, &out)
+	diffValue("configOverlay", before.ConfigOverlay, after.ConfigOverlay, &out)
+	for i := range out {
+		out[i].Category = category(out[i].Path)
+	}
+	return out
+}
+
+func diffValue(path string, a, b any, out *[]Change) {
+	if am, ok := a.(map[string]any); ok {
+		if bm, ok := b.(map[string]any); ok {
+			for _, k := range unionKeys(am, bm) {
+				if ignoredKeys[k] {
+					continue
+				}
+				av, aok := am[k]
+				bv, bok := bm[k]
+				p := path + "." + k
+				switch {
+				case !aok:
+					*out = append(*out, Change{Path: p, Kind: KindAdded, After: bv})
+				case !bok:
+					*out = append(*out, Change{Path: p, Kind: KindRemoved, Before: av})
+				default:
+					diffValue(p, av, bv, out)
+				}
+			}
+			return
+		}
+	}
+	if an, ok := byName(a); ok {
+		if bn, ok := byName(b); ok {
+			diffValue(path, an, bn, out)
+			return
+		}
+	}
+	if !reflect.DeepEqual(a, b) {
+		*out = append(*out, Change{Path: path, Kind: KindChanged, Before: a, After: b})
+	}
+}
+
+// byName turns an array of objects with unique "name" keys into a map, or returns false.
+func byName(v any) (map[string]any, bool) {
+	list, ok := v.([]any)
+	if !ok || len(list) == 0 {
+		return nil, false
+	}
+	out := make(map[string]any, len(list))
+	for _, item := range list {
+		obj, ok := item.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		name, ok := obj["name"].(string)
+		if !ok || out[name] != nil {
+			return nil, false
+		}
+		out[name] = obj
+	}
+	return out, true
+}
+
+func unionKeys(a, b map[string]any) []string {
+	keys := make([]string, 0, len(a)+len(b))
+	for k := range a {
+		keys = append(keys, k)
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func category(path string) string {
+	switch {
+	case strings.HasPrefix(path, "schema.fieldTypes.") && strings.Contains(path, "nalyzer"):
+		return "analyzer"
+	case strings.HasPrefix(path, "schema.fieldTypes"):
+		return "fieldType"
+	case strings.HasPrefix(path, "schema.fields"), strings.HasPrefix(path, "schema.dynamicFields"), strings.HasPrefix(path, "schema.copyFields"):
+		return "field"
+	case strings.HasPrefix(path, "schema"):
+		return "schema"
+	case strings.HasPrefix(path, "configOverlay.requestHandler"):
+		return "handler"
+	}
+	return "config"
+}
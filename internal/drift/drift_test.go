@@ -0,0 +1,147 @@
+package drift
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSolr serves a schema whose text field type analyzer can be changed between checks.
+type fakeSolr struct {
+	tokenizer string
+	handlers  map[string]any
+}
+
+func (f *fakeSolr) fetch(_ context.Context, _ string) (map[string]any, map[string]any, error) {
+	schema := map[string]any{
+		"uniqueKey": "id",
+		"fields": []any{
+			map[string]any{"name": "id", "type": "string"},
+			map[string]any{"name": "title", "type": "text"},
+		},
+		"fieldTypes": []any{
+			map[string]any{"name": "text", "class": "solr.TextField", "analyzer": map[string]any{
+				"tokenizer": map[string]any{"class": f.tokenizer},
+			}},
+		},
+	}
+	overlay := map[string]any{"znodeVersion": float64(len(f.handlers))}
+	if f.handlers != nil {
+		overlay["requestHandler"] = f.handlers
+	}
+	return schema, overlay, nil
+}
+
+func newTestManager(t *testing.T, solr *fakeSolr, keep int, notified *[]Report) *Manager {
+	t.Helper()
+	m := NewManager(config.DriftConfig{Keep: keep}, "products", NewStore(t.TempDir()), solr.fetch,
+		func(_ context.Context, r Report) { *notified = append(*notified, r) })
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	m.now = func() time.Time {
+		now = now.Add(time.Hour)
+		return now
+	}
+	return m
+}
+
+// TestCheck tests storing snapshots and reporting drift.
+func TestCheck(t *testing.T) {
+	solr := &fakeSolr{tokenizer: "solr.StandardTokenizerFactory"}
+	var notified []Report
+	m := newTestManager(t, solr, 0, &notified)
+	ctx := context.Background()
+
+	// Goal: The first check stores a baseline without reporting changes.
+	report, err := m.Check(ctx, "products")
+	require.NoError(t, err)
+	assert.True(t, report.First)
+	assert.False(t, report.Changed)
+
+	// Goal: An unchanged collection is not stored again and nobody is notified.
+	report, err = m.Check(ctx, "products")
+	require.NoError(t, err)
+	assert.False(t, report.Changed)
+	assert.NotNil(t, report.Previous)
+	assert.Empty(t, notified)
+
+	// Goal: A changed analyzer and a new request handler are reported by category and notified.
+	solr.tokenizer = "solr.WhitespaceTokenizerFactory"
+	solr.handlers = map[string]any{"/export2": map[string]any{"class": "solr.SearchHandler"}}
+	report, err = m.Check(ctx, "products")
+	require.NoError(t, err)
+	assert.True(t, report.Changed)
+	require.Len(t, report.Changes, 2)
+	assert.Equal(t, Change{
+		Path: "schema.fieldTypes.text.analyzer.tokenizer.class", Kind: KindChanged, Category: "analyzer",
+		Before: "solr.StandardTokenizerFactory", After: "solr.WhitespaceTokenizerFactory",
+	}, report.Changes[0])
+	assert.Equal(t, "configOverlay.requestHandler", report.Changes[1].Path)
+	assert.Equal(t, KindAdded, report.Changes[1].Kind)
+	assert.Equal(t, "handler", report.Changes[1].Category)
+	require.Len(t, notified, 1)
+
+	history, err := m.store.Load("products")
+	require.NoError(t, err)
+	assert.Len(t, history, 2)
+}
+
+// TestDiff tests that named arrays are compared by name.
+func TestDiff(t *testing.T) {
+	before := Snapshot{Schema: map[string]any{"fields": []any{
+		map[string]any{"name": "id", "type": "string"},
+		map[string]any{"name": "title", "type": "text"},
+	}}}
+	after := Snapshot{Schema: map[string]any{"fields": []any{
+		map[string]any{"name": "title", "type": "text", "stored": false},
+		map[string]any{"name": "id", "type": "string"},
+		map[string]any{"name": "price", "type": "pfloat"},
+	}}}
+
+	// Goal: Reordering is not a change; added fields and attributes are.
+	changes := Diff(before, after)
+	require.Len(t, changes, 2)
+	assert.Equal(t, "schema.fields.price", changes[0].Path)
+	assert.Equal(t, KindAdded, changes[0].Kind)
+	assert.Equal(t, "schema.fields.title.stored", changes[1].Path)
+	assert.Equal(t, "field", changes[1].Category)
+}
+
+// TestTimeline tests listing stored snapshots and the retention of snapshots.
+func TestTimeline(t *testing.T) {
+	solr := &fakeSolr{tokenizer: "a"}
+	var notified []Report
+	m := newTestManager(t, solr, 3, &notified)
+	ctx := context.Background()
+	for _, tok := range []string{"a", "b", "c", "d"} {
+		solr.tokenizer = tok
+		_, err := m.Check(ctx, "products")
+		require.NoError(t, err)
+	}
+
+	// Goal: Only the configured number of snapshots is kept.
+	tl, err := m.Timeline("products", time.Time{}, time.Time{})
+	require.NoError(t, err)
+	require.Len(t, tl.Snapshots, 3)
+	assert.Empty(t, tl.Snapshots[0].Changes)
+	require.Len(t, tl.Snapshots[1].Changes, 1)
+	assert.Equal(t, "c", tl.Snapshots[1].Changes[0].After)
+	require.Len(t, tl.Net, 1)
+	assert.Equal(t, "b", tl.Net[0].Before)
+	assert.Equal(t, "d", tl.Net[0].After)
+
+	// Goal: The time range limits the listed snapshots.
+	tl, err = m.Timeline("products", tl.Snapshots[1].TakenAt, time.Time{})
+	require.NoError(t, err)
+	assert.Len(t, tl.Snapshots, 2)
+}
+
+// TestStoreWithoutDir tests the error without a data directory.
+func TestStoreWithoutDir(t *testing.T) {
+	_, err := NewStore("").Load("products")
+	assert.ErrorContains(t, err, "SOLR_MCP_DATA_DIR")
+}
@@ -0,0 +1,103 @@
+package drift
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+)
+
+// Store keeps the snapshots of each collection as a JSON Lines file in a directory, oldest first.
+type Store struct {
+	dir string
+}
+
+// NewStore creates a Store in dir. An empty dir is an error on use.
+func NewStore(dir string) *Store {
+	return &Store{dir: dir}
+}
+
+func (s *Store) path(collection string) (string, error) {
+	if s == nil || s.dir == "" {
+		return "", errors.New("no data directory for drift snapshots (SOLR_MCP_DATA_DIR is not set)")
+	}
+	return filepath.Join(s.dir, url.PathEscape(collection)+".jsonl"), nil
+}
+
+// Load returns the stored snapshots of collection, oldest first. A collection without snapshots has none.
+func (s *Store) Load(collection string) ([]Snapshot, error) {
+	path, err := s.path(collection)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read drift snapshots: %v", err)
+	}
+	defer f.Close()
+
+	var out []Snapshot
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		var snap Snapshot
+		if err := json.Unmarshal(scanner.Bytes(), &snap); err != nil {
+			return nil, fmt.Errorf("decode drift snapshot in %s: %v", path, err)
+		}
+		out = append(out, snap)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read drift snapshots: %v", err)
+	}
+	return out, nil
+}
+
+// Append stores snap and drops the oldest snapshots beyond keep. The file is replaced atomically.
+func (s *Store) Append(snap Snapshot, keep int) error {
+	path, err := s.path(snap.Collection)
+	if err != nil {
+		return err
+	}
+	history, err := s.Load(snap.Collection)
+	if err != nil {
+		return err
+	}
+	history = append(history, snap)
+	if len(history) > keep {
+		history = history[len(history)-keep:]
+	}
+
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return fmt.Errorf("create drift directory: %v", err)
+	}
+	tmp, err := os.CreateTemp(s.dir, ".snapshots-*")
+	if err != nil {
+		return fmt.Errorf("write drift snapshots: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+	w := bufio.NewWriter(tmp)
+	enc := json.NewEncoder(w)
+	for _, h := range history {
+		if err := enc.Encode(h); err != nil {
+			tmp.Close()
+			return fmt.Errorf("write drift snapshots: %v", err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write drift snapshots: %v", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("write drift snapshots: %v", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("write drift snapshots: %v", err)
+	}
+	return nil
+}
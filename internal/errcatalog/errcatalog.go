@@ -0,0 +1,344 @@
+// Package errcatalog is the catalog of tool errors: a stable code for each kind of failure, with a message and a
+// remediation hint in every supported language and a link to the documentation. Clients branch on the code instead
+// of matching error text.
+package errcatalog
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Code identifies a kind of tool failure. Codes are stable; messages and hints may change.
+type Code string
+
+const (
+	InvalidArgument         Code = "invalid_argument"
+	CollectionNotFound      Code = "collection_not_found"
+	NotFound                Code = "not_found"
+	SolrUnauthenticated     Code = "solr_unauthenticated"
+	SolrPermissionDenied    Code = "solr_permission_denied"
+	PolicyDenied            Code = "policy_denied"
+	ReadOnly                Code = "read_only"
+	StandbyWrite            Code = "standby_write"
+	UnsupportedSolr         Code = "unsupported_solr"
+	CapabilityNotConfigured Code = "capability_not_configured"
+	ConfirmationInvalid     Code = "confirmation_invalid"
+	ServerBusy              Code = "server_busy"
+	SolrUnavailable         Code = "solr_unavailable"
+	Timeout                 Code = "timeout"
+	Canceled                Code = "canceled"
+	SolrError               Code = "solr_error"
+	Internal                Code = "internal"
+)
+
+// Lang is a language of messages and hints.
+type Lang string
+
+const (
+	English  Lang = "en"
+	Japanese Lang = "ja"
+)
+
+// Langs are the supported languages.
+var Langs = []Lang{English, Japanese}
+
+// ParseLang parses a language such as "ja", "ja-JP" or "en_US". The empty string is English.
+func ParseLang(s string) (Lang, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "" {
+		return English, nil
+	}
+	base, _, _ := strings.Cut(strings.ReplaceAll(s, "_", "-"), "-")
+	for _, l := range Langs {
+		if Lang(base) == l {
+			return l, nil
+		}
+	}
+	return "", fmt.Errorf("unsupported language %q (supported: en, ja)", s)
+}
+
+// DocsURL is the base of the documentation links.
+const DocsURL = "https://github.com/Sashimimochi/solr-mcp-go"
+
+// Entry is a code of the catalog with its message and hint per language and its documentation anchor.
+type Entry struct {
+	Code    Code
+	Message map[Lang]string
+	Hint    map[Lang]string
+	Anchor  string
+}
+
+// Docs returns the documentation link of the entry.
+func (e Entry) Docs() string {
+	if e.Anchor == "" {
+		return DocsURL + "#readme"
+	}
+	return DocsURL + "#" + e.Anchor
+}
+
+var catalog = map[Code]Entry{
+	InvalidArgument: {
+		Message: map[Lang]string{English: "Invalid arguments", Japanese: "引数が正しくありません"},
+		Hint: map[Lang]string{
+			English:  "Fix the argument named in the error (input.<name>) and call the tool again. The tool description lists the accepted values.",
+			Japanese: "エラーに示された引数 (input.<名前>) を修正して、もう一度呼び出してください。受け付ける値はツールの説明にあります。",
+		},
+		Anchor: "available-tools",
+	},
+	CollectionNotFound: {
+		Message: map[Lang]string{English: "Collection not found", Japanese: "コレクションが見つかりません"},
+		Hint: map[Lang]string{
+			English:  "Use one of the available collections named in the error.",
+			Japanese: "エラーに示された利用可能なコレクションのいずれかを指定してください。",
+		},
+		Anchor: "unknown-collections",
+	},
+	NotFound: {
+		Message: map[Lang]string{English: "Not found", Japanese: "見つかりません"},
+		Hint: map[Lang]string{
+			English:  "Check the names in the arguments, such as the collection, field, model or job ID.",
+			Japanese: "コレクション、フィールド、モデル、ジョブ ID などの引数の名前を確認してください。",
+		},
+		Anchor: "available-tools",
+	},
+	SolrUnauthenticated: {
+		Message: map[Lang]string{English: "Solr rejected the credentials", Japanese: "Solr が認証情報を拒否しました"},
+		Hint: map[Lang]string{
+			English:  "Ask the operator of the server to check SOLR_BASIC_USER and SOLR_BASIC_PASS.",
+			Japanese: "SOLR_BASIC_USER と SOLR_BASIC_PASS の確認をサーバーの管理者に依頼してください。",
+		},
+		Anchor: "setup",
+	},
+	SolrPermissionDenied: {
+		Message: map[Lang]string{English: "Solr denied the request", Japanese: "Solr がリクエストを拒否しました"},
+		Hint: map[Lang]string{
+			English:  "Call solr.permissions to see which permission the Solr user lacks, and ask a Solr administrator to grant it.",
+			Japanese: "solr.permissions で Solr ユーザーに不足している権限を確認し、Solr の管理者に付与を依頼してください。",
+		},
+		Anchor: "solrpermissions",
+	},
+	PolicyDenied: {
+		Message: map[Lang]string{English: "Denied by the authorization policy", Japanese: "認可ポリシーにより拒否されました"},
+		Hint: map[Lang]string{
+			English:  "You may not make this call. Change the arguments as the reason suggests, or ask the operator of the server.",
+			Japanese: "この呼び出しは許可されていません。理由に従って引数を変えるか、サーバーの管理者に問い合わせてください。",
+		},
+		Anchor: "authorization-policies",
+	},
+	ReadOnly: {
+		Message: map[Lang]string{English: "The server is in read-only mode", Japanese: "サーバーは読み取り専用モードです"},
+		Hint: map[Lang]string{
+			English:  "An operator disabled the write tools. Retry later, or ask the operator of the server.",
+			Japanese: "管理者が書き込み系のツールを無効にしています。時間をおいて再試行するか、サーバーの管理者に問い合わせてください。",
+		},
+		Anchor: "admin-api",
+	},
+	StandbyWrite: {
+		Message: map[Lang]string{English: "Writes are disabled on the standby", Japanese: "スタンバイでは書き込みが無効です"},
+		Hint: map[Lang]string{
+			English:  "The primary Solr is unavailable. Retry once it is back.",
+			Japanese: "プライマリの Solr が利用できません。復旧してから再試行してください。",
+		},
+		Anchor: "standby-failover",
+	},
+	UnsupportedSolr: {
+		Message: map[Lang]string{English: "Not supported by this Solr", Japanese: "この Solr では利用できません"},
+		Hint: map[Lang]string{
+			English:  "Use another tool. The tool needs a newer Solr or SolrCloud mode.",
+			Japanese: "別のツールを使ってください。このツールには新しい Solr か SolrCloud モードが必要です。",
+		},
+		Anchor: "solr-version-compatibility",
+	},
+	CapabilityNotConfigured: {
+		Message: map[Lang]string{English: "Capability not configured", Japanese: "機能が設定されていません"},
+		Hint: map[Lang]string{
+			English:  "The server is not set up for this tool. solr.info lists the setup hints of all capabilities for the operator.",
+			Japanese: "このツールを使うための設定がされていません。設定方法は solr.info で確認できます。",
+		},
+		Anchor: "solrinfo",
+	},
+	ConfirmationInvalid: {
+		Message: map[Lang]string{English: "Invalid confirmation token", Japanese: "確認トークンが無効です"},
+		Hint: map[Lang]string{
+			English:  "Call the tool again without confirm to get a new preview and token, then confirm with exactly the same arguments.",
+			Japanese: "confirm を付けずにもう一度呼び出して新しいプレビューとトークンを取得し、同じ引数のまま確定してください。",
+		},
+		Anchor: "destructive-tools",
+	},
+	ServerBusy: {
+		Message: map[Lang]string{English: "The server is busy", Japanese: "サーバーが混雑しています"},
+		Hint: map[Lang]string{
+			English:  "Too many large calls are running. Retry after the time in the detail, or request fewer rows or fields.",
+			Japanese: "大きな呼び出しが多数実行中です。詳細に示された時間をおいて再試行するか、rows やフィールドを減らしてください。",
+		},
+		Anchor: "memory-budget",
+	},
+	SolrUnavailable: {
+		Message: map[Lang]string{English: "Solr is unavailable", Japanese: "Solr に接続できません"},
+		Hint: map[Lang]string{
+			English:  "Retry in a moment. If it persists, the operator should check SOLR_MCP_SOLR_URL, the network and the Solr nodes.",
+			Japanese: "しばらくしてから再試行してください。続く場合は SOLR_MCP_SOLR_URL、ネットワーク、Solr ノードの確認が必要です。",
+		},
+		Anchor: "standby-failover",
+	},
+	Timeout: {
+		Message: map[Lang]string{English: "Solr did not answer in time", Japanese: "Solr の応答がタイムアウトしました"},
+		Hint: map[Lang]string{
+			English:  "Make the request cheaper, e.g. with fewer rows, narrower filters or fewer facets, or retry later.",
+			Japanese: "rows を減らす、フィルタを絞る、ファセットを減らすなどしてリクエストを軽くするか、時間をおいて再試行してください。",
+		},
+		Anchor: "slow-request-warnings",
+	},
+	Canceled: {
+		Message: map[Lang]string{English: "The call was canceled", Japanese: "呼び出しはキャンセルされました"},
+		Hint: map[Lang]string{
+			English:  "Call the tool again if the result is still needed.",
+			Japanese: "結果が必要であれば、もう一度呼び出してください。",
+		},
+	},
+	SolrError: {
+		Message: map[Lang]string{English: "Solr returned an error", Japanese: "Solr がエラーを返しました"},
+		Hint: map[Lang]string{
+			English:  "Read the Solr message in the detail. It usually names the offending parameter, field or syntax.",
+			Japanese: "詳細にある Solr のメッセージを確認してください。多くの場合、問題のパラメータ、フィールド、構文が示されています。",
+		},
+		Anchor: "available-tools",
+	},
+	Internal: {
+		Message: map[Lang]string{English: "The tool failed", Japanese: "ツールの実行に失敗しました"},
+		Hint: map[Lang]string{
+			English:  "Read the detail. If the failure persists, report it with the server logs.",
+			Japanese: "詳細を確認してください。失敗が続く場合は、サーバーのログを添えて報告してください。",
+		},
+	},
+}
+
+// Entries returns the catalog sorted by code.
+func Entries() []Entry {
+	entries := make([]Entry, 0, len(catalog))
+	for code, e := range catalog {
+		e.Code = code
+		entries = append(entries, e)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Code < entries[j].Code })
+	return entries
+}
+
+// Error is an error with its catalog code.
+type Error struct {
+	Code Code
+	Err  error
+}
+
+func (e *Error) Error() string { return e.Err.Error() }
+
+func (e *Error) Unwrap() error { return e.Err }
+
+// New returns an error with code and a message formatted like fmt.Errorf.
+func New(code Code, format string, args ...any) error {
+	return &Error{Code: code, Err: fmt.Errorf(format, args...)}
+}
+
+// Wrap attaches code to err, or returns nil if err is nil.
+func Wrap(code Code, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &Error{Code: code, Err: err}
+}
+
+// Coder is implemented by structured errors of other packages that know their code.
+type Coder interface {
+	ErrorCode() Code
+}
+
+var httpStatus = regexp.MustCompile(`HTTP status (\d{3})`)
+
+// CodeOf returns the code of err: the code attached to it, or else the code derived from the conventions of the
+// error messages in this repository, e.g. "input.rows: ..." for invalid arguments and "HTTP status 403" for
+// requests Solr refused.
+func CodeOf(err error) Code {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Code
+	}
+	var c Coder
+	if errors.As(err, &c) {
+		return c.ErrorCode()
+	}
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "context canceled"):
+		return Canceled
+	case strings.Contains(msg, "context deadline exceeded"), strings.Contains(msg, "Client.Timeout exceeded"),
+		strings.Contains(msg, "i/o timeout"):
+		return Timeout
+	case strings.HasPrefix(msg, "input."):
+		return InvalidArgument
+	}
+	if m := httpStatus.FindStringSubmatch(msg); m != nil {
+		switch m[1] {
+		case "401":
+			return SolrUnauthenticated
+		case "403":
+			return SolrPermissionDenied
+		case "404":
+			return NotFound
+		case "502", "503", "504":
+			return SolrUnavailable
+		}
+		return SolrError
+	}
+	if strings.Contains(msg, "HTTP request error") {
+		return SolrUnavailable
+	}
+	return Internal
+}
+
+// Described is an error as returned to clients.
+type Described struct {
+	Code    Code   `json:"code"`
+	Message string `json:"message"`
+	Detail  string `json:"detail,omitempty"`
+	Hint    string `json:"hint"`
+	Docs    string `json:"docs"`
+}
+
+// Describe returns err with its code, and the message and hint of the code in lang.
+func Describe(err error, lang Lang) Described {
+	d := Lookup(CodeOf(err), lang)
+	d.Detail = err.Error()
+	return d
+}
+
+// Lookup returns the message and hint of code in lang, falling back to English.
+func Lookup(code Code, lang Lang) Described {
+	e, ok := catalog[code]
+	if !ok {
+		e = catalog[Internal]
+	}
+	return Described{
+		Code:    code,
+		Message: localized(e.Message, lang),
+		Hint:    localized(e.Hint, lang),
+		Docs:    e.Docs(),
+	}
+}
+
+func localized(texts map[Lang]string, lang Lang) string {
+	if s, ok := texts[lang]; ok {
+		return s
+	}
+	return texts[English]
+}
+
+var hintLabel = map[Lang]string{English: "Hint", Japanese: "対処"}
+
+// Text renders d for the text content of a tool result: the message and detail, the hint, then the code and the
+// documentation link.
+func (d Described) Text(lang Lang) string {
+	return fmt.Sprintf("%s: %s\n%s: %s\ncode: %s (%s)", d.Message, d.Detail, localized(hintLabel, lang), d.Hint, d.Code, d.Docs)
+}
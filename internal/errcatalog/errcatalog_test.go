@@ -0,0 +1,79 @@
+package errcatalog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type coded struct{}
+
+func (coded) Error() string { return "capability not configured" }
+
+func (coded) ErrorCode() Code { return CapabilityNotConfigured }
+
+// TestCodeOf tests deriving the codes of errors.
+func TestCodeOf(t *testing.T) {
+	// Goal: Codes attached to errors win, also through wrapping.
+	err := New(ReadOnly, "solr.update is disabled: the server is in read-only mode")
+	assert.Equal(t, ReadOnly, CodeOf(fmt.Errorf("call: %w", err)))
+	assert.Equal(t, CapabilityNotConfigured, CodeOf(coded{}))
+	assert.NoError(t, Wrap(Internal, nil))
+
+	// Goal: Other errors get the code of their message conventions.
+	for msg, code := range map[string]Code{
+		"input.collection is required":                              InvalidArgument,
+		"HTTP status 401: Unauthorized":                             SolrUnauthenticated,
+		"update: HTTP status 403: Unauthorized request":             SolrPermissionDenied,
+		"HTTP status 404: Not Found":                                NotFound,
+		"HTTP status 503: Service Unavailable":                      SolrUnavailable,
+		"HTTP status 400: undefined field foo":                      SolrError,
+		"HTTP request error: dial tcp: connection refused":          SolrUnavailable,
+		"HTTP request error: Client.Timeout exceeded while reading": Timeout,
+		"query matches 50000 documents":                             Internal,
+	} {
+		assert.Equal(t, code, CodeOf(errors.New(msg)), msg)
+	}
+	assert.Equal(t, Canceled, CodeOf(fmt.Errorf("select: %w", context.Canceled)))
+	assert.Equal(t, Timeout, CodeOf(fmt.Errorf("select: %w", context.DeadlineExceeded)))
+}
+
+// TestDescribe tests localizing errors.
+func TestDescribe(t *testing.T) {
+	err := New(CollectionNotFound, "collection missing does not exist; available: [products]")
+
+	// Goal: The message and hint follow the language, the code and detail do not.
+	en := Describe(err, English)
+	ja := Describe(err, Japanese)
+	assert.Equal(t, CollectionNotFound, ja.Code)
+	assert.Equal(t, en.Detail, ja.Detail)
+	assert.Equal(t, "Collection not found", en.Message)
+	assert.Equal(t, "コレクションが見つかりません", ja.Message)
+	assert.Equal(t, DocsURL+"#unknown-collections", ja.Docs)
+	assert.Equal(t, "コレクションが見つかりません: collection missing does not exist; available: [products]\n対処: "+ja.Hint+"\ncode: collection_not_found ("+ja.Docs+")", ja.Text(Japanese))
+
+	// Goal: Every code has a message and a hint in every language.
+	for _, e := range Entries() {
+		for _, lang := range Langs {
+			d := Lookup(e.Code, lang)
+			assert.NotEmpty(t, e.Message[lang], "%s %s", e.Code, lang)
+			assert.NotEmpty(t, e.Hint[lang], "%s %s", e.Code, lang)
+			assert.Equal(t, e.Code, d.Code)
+		}
+	}
+}
+
+// TestParseLang tests parsing languages.
+func TestParseLang(t *testing.T) {
+	for s, want := range map[string]Lang{"": English, "en": English, "ja": Japanese, "ja-JP": Japanese, "en_US": English, " JA ": Japanese} {
+		lang, err := ParseLang(s)
+		require.NoError(t, err, s)
+		assert.Equal(t, want, lang, s)
+	}
+	_, err := ParseLang("fr")
+	assert.ErrorContains(t, err, `unsupported language "fr"`)
+}
@@ -0,0 +1,253 @@
+// Package experiment routes a share of query executions to alternate query profiles and compares the outcomes of the variants.
+package experiment
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"net/url"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/config"
+)
+
+// Variant names. A is the unchanged request, B the request with the parameters of the experiment.
+const (
+	VariantA = "A"
+	VariantB = "B"
+)
+
+// Assignment is the variant a query execution runs as. It is returned in results so that feedback can refer to it.
+type Assignment struct {
+	Experiment string `json:"name"`
+	Variant    string `json:"variant"`
+
+	params map[string]string
+}
+
+// Params returns the parameters set by variant B, or nil for variant A. Empty values remove the parameter.
+func (a *Assignment) Params() map[string]string {
+	if a.Variant != VariantB {
+		return nil
+	}
+	return a.params
+}
+
+// Apply sets the parameters of variant B on params. Variant A is left unchanged.
+func (a *Assignment) Apply(params url.Values) {
+	if a.Variant != VariantB {
+		return
+	}
+	for k, v := range a.params {
+		if v == "" {
+			params.Del(k)
+		} else {
+			params.Set(k, v)
+		}
+	}
+}
+
+// VariantStats are the outcome metrics of one variant.
+type VariantStats struct {
+	Variant        string  `json:"variant"`
+	Executions     int64   `json:"executions"`
+	ZeroResults    int64   `json:"zeroResults"`
+	Clicks         int64   `json:"clicks"`
+	DurationMs     int64   `json:"durationMs"` // total duration of all executions
+	ZeroResultRate float64 `json:"zeroResultRate"`
+	ClickRate      float64 `json:"clickRate"` // clicks per execution
+	AvgDurationMs  float64 `json:"avgDurationMs"`
+}
+
+// Report compares the variants of a configured experiment.
+type Report struct {
+	Name        string            `json:"name"`
+	Description string            `json:"description,omitempty"`
+	Collections []string          `json:"collections,omitempty"`
+	Fraction    float64           `json:"fraction"`
+	Params      map[string]string `json:"params"`
+	Since       time.Time         `json:"since"`
+	Variants    []VariantStats    `json:"variants"`
+}
+
+// Manager assigns query executions to experiments and accumulates their outcomes. It is safe for concurrent use.
+type Manager struct {
+	mu          sync.Mutex
+	experiments []config.ExperimentConfig
+	stats       map[string]map[string]*VariantStats // experiment -> variant
+	since       map[string]time.Time
+	random      func() float64
+}
+
+// NewManager creates a Manager for the given experiments.
+func NewManager(experiments []config.ExperimentConfig) *Manager {
+	return &Manager{
+		experiments: experiments,
+		stats:       make(map[string]map[string]*VariantStats),
+		since:       make(map[string]time.Time),
+		random:      rand.Float64,
+	}
+}
+
+// SetConfig replaces the experiments. Metrics are kept by experiment name; rename an experiment to start over.
+func (m *Manager) SetConfig(experiments []config.ExperimentConfig) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.experiments = experiments
+}
+
+// Assign picks the variant of a query execution on collection, using the first experiment covering it.
+// It returns nil when no experiment applies.
+func (m *Manager) Assign(collection string) *Assignment {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, ec := range m.experiments {
+		if len(ec.Collections) > 0 && !slices.Contains(ec.Collections, collection) {
+			continue
+		}
+		a := &Assignment{Experiment: ec.Name, Variant: VariantA, params: ec.Params}
+		if m.random() < ec.Fraction {
+			a.Variant = VariantB
+		}
+		return a
+	}
+	return nil
+}
+
+// RecordExecution adds the outcome of a query execution.
+func (m *Manager) RecordExecution(a *Assignment, numFound int64, d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s := m.variant(a.Experiment, a.Variant)
+	s.Executions++
+	s.DurationMs += d.Milliseconds()
+	if numFound == 0 {
+		s.ZeroResults++
+	}
+}
+
+// RecordClick adds a click on a result of a variant. It reports false for unknown experiments or variants.
+func (m *Manager) RecordClick(experiment, variant string) bool {
+	if variant != VariantA && variant != VariantB {
+		return false
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !slices.ContainsFunc(m.experiments, func(ec config.ExperimentConfig) bool { return ec.Name == experiment }) {
+		return false
+	}
+	m.variant(experiment, variant).Clicks++
+	return true
+}
+
+func (m *Manager) variant(experiment, variant string) *VariantStats {
+	variants, ok := m.stats[experiment]
+	if !ok {
+		variants = make(map[string]*VariantStats)
+		m.stats[experiment] = variants
+		m.since[experiment] = time.Now()
+	}
+	s, ok := variants[variant]
+	if !ok {
+		s = &VariantStats{Variant: variant}
+		variants[variant] = s
+	}
+	return s
+}
+
+// Reports returns the metrics of the configured experiments, with both variants in order.
+func (m *Manager) Reports() []Report {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	reports := make([]Report, 0, len(m.experiments))
+	for _, ec := range m.experiments {
+		r := Report{
+			Name:        ec.Name,
+			Description: ec.Description,
+			Collections: ec.Collections,
+			Fraction:    ec.Fraction,
+			Params:      ec.Params,
+			Since:       m.since[ec.Name],
+		}
+		for _, v := range []string{VariantA, VariantB} {
+			s := VariantStats{Variant: v}
+			if p, ok := m.stats[ec.Name][v]; ok {
+				s = *p
+			}
+			if s.Executions > 0 {
+				s.ZeroResultRate = float64(s.ZeroResults) / float64(s.Executions)
+				s.ClickRate = float64(s.Clicks) / float64(s.Executions)
+				s.AvgDurationMs = float64(s.DurationMs) / float64(s.Executions)
+			}
+			r.Variants = append(r.Variants, s)
+		}
+		reports = append(reports, r)
+	}
+	return reports
+}
+
+// persisted is the file format of Save and Load.
+type persisted struct {
+	Since map[string]time.Time               `json:"since"`
+	Stats map[string]map[string]VariantStats `json:"stats"`
+}
+
+// Save writes the metrics to path, replacing the file atomically.
+func (m *Manager) Save(path string) error {
+	m.mu.Lock()
+	p := persisted{Since: m.since, Stats: make(map[string]map[string]VariantStats, len(m.stats))}
+	for name, variants := range m.stats {
+		p.Stats[name] = make(map[string]VariantStats, len(variants))
+		for v, s := range variants {
+			p.Stats[name][v] = *s
+		}
+	}
+	data, err := json.Marshal(p)
+	m.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("encode experiment metrics: %v", err)
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("write experiment metrics: %v", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("write experiment metrics: %v", err)
+	}
+	return nil
+}
+
+// Load replaces the metrics with those saved at path. A missing file is not an error.
+func (m *Manager) Load(path string) error {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("read experiment metrics: %v", err)
+	}
+	var p persisted
+	if err := json.Unmarshal(data, &p); err != nil {
+		return fmt.Errorf("decode experiment metrics: %v", err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.since = make(map[string]time.Time, len(p.Since))
+	for name, t := range p.Since {
+		m.since[name] = t
+	}
+	m.stats = make(map[string]map[string]*VariantStats, len(p.Stats))
+	for name, variants := range p.Stats {
+		m.stats[name] = make(map[string]*VariantStats, len(variants))
+		for v, s := range variants {
+			s.Variant = v
+			m.stats[name][v] = &s
+		}
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package experiment
+
+import (
+	"net/url"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestAssign tests the routing of executions to variants.
+func TestAssign(t *testing.T) {
+	m := NewManager([]config.ExperimentConfig{
+		{Name: "boost", Collections: []string{"products"}, Fraction: 0.2, Params: map[string]string{"bq": "inStock:true", "rq": ""}},
+		{Name: "catchall", Fraction: 0.5, Params: map[string]string{"mm": "2"}},
+	})
+	draws := []float64{0.1, 0.9}
+	m.random = func() float64 {
+		d := draws[0]
+		draws = draws[1:]
+		return d
+	}
+
+	// Goal: Draws below the fraction run as variant B with the parameters of the experiment set or removed.
+	a := m.Assign("products")
+	require.NotNil(t, a)
+	assert.Equal(t, Assignment{Experiment: "boost", Variant: VariantB, params: a.params}, *a)
+	params := url.Values{"q": {"tv"}, "rq": {"{!ltr model=m}"}}
+	a.Apply(params)
+	assert.Equal(t, url.Values{"q": {"tv"}, "bq": {"inStock:true"}}, params)
+
+	// Goal: Other draws run unchanged as variant A.
+	a = m.Assign("products")
+	assert.Equal(t, VariantA, a.Variant)
+	params = url.Values{"q": {"tv"}}
+	a.Apply(params)
+	assert.Equal(t, url.Values{"q": {"tv"}}, params)
+
+	// Goal: A collection only gets the first experiment covering it.
+	m.random = func() float64 { return 0 }
+	assert.Equal(t, "catchall", m.Assign("logs").Experiment)
+	assert.Nil(t, NewManager(nil).Assign("logs"))
+}
+
+// TestReports tests the metrics of the variants and their persistence.
+func TestReports(t *testing.T) {
+	cfg := []config.ExperimentConfig{{Name: "boost", Fraction: 0.5, Params: map[string]string{"bq": "inStock:true"}}}
+	m := NewManager(cfg)
+	m.RecordExecution(&Assignment{Experiment: "boost", Variant: VariantA}, 0, 30*time.Millisecond)
+	m.RecordExecution(&Assignment{Experiment: "boost", Variant: VariantA}, 12, 10*time.Millisecond)
+	m.RecordExecution(&Assignment{Experiment: "boost", Variant: VariantB}, 3, 40*time.Millisecond)
+
+	// Goal: Clicks only count for configured experiments and known variants.
+	assert.True(t, m.RecordClick("boost", VariantB))
+	assert.False(t, m.RecordClick("boost", "C"))
+	assert.False(t, m.RecordClick("other", VariantA))
+
+	// Goal: Both variants are reported with their rates.
+	reports := m.Reports()
+	require.Len(t, reports, 1)
+	a, b := reports[0].Variants[0], reports[0].Variants[1]
+	assert.Equal(t, int64(2), a.Executions)
+	assert.Equal(t, 0.5, a.ZeroResultRate)
+	assert.Equal(t, 20.0, a.AvgDurationMs)
+	assert.Equal(t, 1.0, b.ClickRate)
+
+	// Goal: Saved metrics load into a new manager.
+	path := filepath.Join(t.TempDir(), "experiments.json")
+	require.NoError(t, m.Save(path))
+	loaded := NewManager(cfg)
+	require.NoError(t, loaded.Load(path))
+	assert.Equal(t, reports[0].Variants, loaded.Reports()[0].Variants)
+	assert.NoError(t, loaded.Load(filepath.Join(t.TempDir(), "missing.json")))
+}
@@ -0,0 +1,166 @@
+// Package failover switches between a primary and a warm standby Solr cluster.
+// Repeated unavailability of the primary fails over to the standby, and periodic probes fail back.
+package failover
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/config"
+)
+
+// ProbeFunc checks whether the Solr cluster at baseURL is available.
+type ProbeFunc func(ctx context.Context, baseURL string) error
+
+// Status describes which cluster is active.
+type Status struct {
+	Active     string    `json:"active"` // "primary" or "standby"
+	PrimaryURL string    `json:"primaryUrl"`
+	StandbyURL string    `json:"standbyUrl"`
+	Since      time.Time `json:"since,omitempty"` // when the server failed over
+	LastError  string    `json:"lastError,omitempty"`
+}
+
+// Monitor tracks the availability of the primary cluster. It is safe for concurrent use.
+type Monitor struct {
+	primary   string
+	standby   string
+	threshold int
+	probe     ProbeFunc
+
+	mu        sync.Mutex
+	failures  int
+	onStandby bool
+	since     time.Time
+	lastError string
+}
+
+// New creates a Monitor that fails over after threshold consecutive unavailability errors of the primary.
+func New(primary, standby string, threshold int, probe ProbeFunc) *Monitor {
+	if threshold < 1 {
+		threshold = 1
+	}
+	return &Monitor{primary: primary, standby: standby, threshold: threshold, probe: probe}
+}
+
+// BaseURL returns the base URL of the active cluster.
+func (m *Monitor) BaseURL() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.onStandby {
+		return m.standby
+	}
+	return m.primary
+}
+
+// OnStandby reports whether the server has failed over to the standby.
+func (m *Monitor) OnStandby() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.onStandby
+}
+
+// Status returns the current state. Passwords in the URLs are redacted.
+func (m *Monitor) Status() Status {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s := Status{Active: "primary", PrimaryURL: config.RedactURL(m.primary), StandbyURL: config.RedactURL(m.standby), LastError: m.lastError}
+	if m.onStandby {
+		s.Active = "standby"
+		s.Since = m.since
+	}
+	return s
+}
+
+// Report records the outcome of a request against the primary. Errors that do not indicate
+// unavailability reset the failure count like successes do. Reports while on the standby are ignored.
+func (m *Monitor) Report(err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.onStandby || Canceled(err) {
+		return
+	}
+	if !Unavailable(err) {
+		m.failures = 0
+		return
+	}
+	m.failures++
+	m.lastError = err.Error()
+	if m.failures >= m.threshold {
+		m.onStandby = true
+		m.since = time.Now()
+		slog.Warn("Primary Solr unavailable, failing over to the standby", "primary", m.primary, "standby", m.standby, "failures", m.failures, "error", err)
+	}
+}
+
+// ReportResponse records the outcome of a request if it went to the primary: a transport error or a 502, 503 or
+// 504 response as unavailability, other responses as successes. Requests to other hosts, such as the standby,
+// are ignored.
+func (m *Monitor) ReportResponse(req *http.Request, resp *http.Response, err error) {
+	if !strings.HasPrefix(req.URL.String(), m.primary+"/") {
+		return
+	}
+	switch {
+	case err != nil:
+		m.Report(fmt.Errorf("HTTP request error: %w", err))
+	case resp.StatusCode >= 500:
+		m.Report(fmt.Errorf("HTTP status %d: %s", resp.StatusCode, http.StatusText(resp.StatusCode)))
+	default:
+		m.Report(nil)
+	}
+}
+
+// Probe checks the primary once while on the standby and fails back when it is available again.
+func (m *Monitor) Probe(ctx context.Context) {
+	if !m.OnStandby() {
+		return
+	}
+	if err := m.probe(ctx, m.primary); err != nil {
+		slog.Debug("Primary Solr still unavailable", "primary", m.primary, "error", err)
+		m.mu.Lock()
+		m.lastError = err.Error()
+		m.mu.Unlock()
+		return
+	}
+	m.mu.Lock()
+	m.onStandby = false
+	m.failures = 0
+	m.mu.Unlock()
+	slog.Info("Primary Solr available again, failing back", "primary", m.primary)
+}
+
+// Run probes the primary on every interval until ctx is done.
+func (m *Monitor) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.Probe(ctx)
+		}
+	}
+}
+
+// unavailableRe matches transport errors and gateway or availability HTTP statuses of the Solr clients.
+var unavailableRe = regexp.MustCompile(`HTTP request error|HTTP status (502|503|504)\b`)
+
+// Unavailable reports whether err indicates that the cluster could not serve the request at all,
+// as opposed to a rejected request such as a syntax error. Requests the caller cancelled say nothing about the cluster.
+func Unavailable(err error) bool {
+	return err != nil && !Canceled(err) && unavailableRe.MatchString(err.Error())
+}
+
+// Canceled reports whether err is the result of the caller cancelling the request, e.g. an MCP client giving up
+// on a tool call. The Solr clients flatten errors into strings, so the message is checked as well.
+func Canceled(err error) bool {
+	return errors.Is(err, context.Canceled) || (err != nil && strings.Contains(err.Error(), context.Canceled.Error()))
+}
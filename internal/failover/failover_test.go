@@ -0,0 +1,81 @@
+package failover
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestMonitor tests failing over after repeated unavailability and failing back after a successful probe.
+func TestMonitor(t *testing.T) {
+	probeErr := errors.New("HTTP request error: connection refused")
+	m := New("http://primary:8983", "http://standby:8983", 2, func(_ context.Context, baseURL string) error {
+		assert.Equal(t, "http://primary:8983", baseURL)
+		return probeErr
+	})
+	unavailable := errors.New("cluster status request: HTTP request error: dial tcp: connection refused")
+
+	// Goal: Successes and request errors reset the count of consecutive failures.
+	m.Report(unavailable)
+	m.Report(errors.New("HTTP status 400: undefined field"))
+	m.Report(unavailable)
+	assert.False(t, m.OnStandby())
+	assert.Equal(t, "http://primary:8983", m.BaseURL())
+
+	// Goal: Consecutive unavailability errors fail over.
+	m.Report(errors.New("HTTP status 503: Service Unavailable"))
+	assert.True(t, m.OnStandby())
+	assert.Equal(t, "http://standby:8983", m.BaseURL())
+	st := m.Status()
+	assert.Equal(t, "standby", st.Active)
+	assert.False(t, st.Since.IsZero())
+
+	// Goal: Reports while on the standby do not fail back.
+	m.Report(nil)
+	assert.True(t, m.OnStandby())
+
+	// Goal: A failed probe stays on the standby and a successful probe fails back.
+	m.Probe(context.Background())
+	assert.True(t, m.OnStandby())
+	probeErr = nil
+	m.Probe(context.Background())
+	assert.False(t, m.OnStandby())
+	assert.Equal(t, "primary", m.Status().Active)
+}
+
+// TestUnavailable tests the classification of errors.
+func TestUnavailable(t *testing.T) {
+	assert.True(t, Unavailable(errors.New("HTTP request error: EOF")))
+	assert.True(t, Unavailable(errors.New("HTTP status 502: Bad Gateway")))
+	assert.False(t, Unavailable(errors.New("HTTP status 500: undefined field")))
+	assert.False(t, Unavailable(errors.New("HTTP status 5030")))
+	assert.False(t, Unavailable(nil))
+	assert.False(t, Unavailable(errors.New(`HTTP request error: Get "http://solr:8983/solr/products/select": context canceled`)))
+	assert.True(t, Unavailable(errors.New("HTTP request error: context deadline exceeded (Client.Timeout exceeded while awaiting headers)")))
+}
+
+// TestReportResponse tests reporting the outcome of HTTP requests.
+func TestReportResponse(t *testing.T) {
+	m := New("http://primary:8983", "http://standby:8983", 2, nil)
+	request := func(url string) *http.Request {
+		req, err := http.NewRequest(http.MethodGet, url, nil)
+		assert.NoError(t, err)
+		return req
+	}
+	unavailable := &http.Response{StatusCode: http.StatusServiceUnavailable}
+
+	// Goal: Only requests to the primary count, and an answer of Solr resets the count.
+	m.ReportResponse(request("http://primary:8983/solr/admin/collections"), unavailable, nil)
+	m.ReportResponse(request("http://standby:8983/solr/admin/collections"), &http.Response{StatusCode: http.StatusOK}, nil)
+	m.ReportResponse(request("http://primary:8983/solr/logs/select"), &http.Response{StatusCode: http.StatusInternalServerError}, nil)
+	m.ReportResponse(request("http://primary:8983/solr/logs/select"), unavailable, nil)
+	assert.False(t, m.OnStandby())
+
+	// Goal: Transport errors count as unavailability.
+	m.ReportResponse(request("http://primary:8983/solr/logs/select"), nil, errors.New("dial tcp: connection refused"))
+	assert.True(t, m.OnStandby())
+	assert.Equal(t, "HTTP request error: dial tcp: connection refused", m.Status().LastError)
+}
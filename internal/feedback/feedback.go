@@ -0,0 +1,269 @@
+// Package feedback records which query results users open and aggregates them into click-through rates per query.
+package feedback
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MaxQueries bounds the number of distinct queries tracked. Executions of further queries are not counted.
+const MaxQueries = 10000
+
+// maxTopDocs is the number of most clicked documents reported per query.
+const maxTopDocs = 5
+
+// Click is a result a user opened, as reported by the host.
+type Click struct {
+	Time       time.Time `json:"time"`
+	Collection string    `json:"collection"`
+	Query      string    `json:"query"`
+	DocID      string    `json:"docId"`
+	Position   int       `json:"position,omitempty"` // 1-based rank of the document in the results, if known
+	Experiment string    `json:"experiment,omitempty"`
+	Variant    string    `json:"variant,omitempty"`
+}
+
+// QueryStats are the aggregated clicks of one query.
+type QueryStats struct {
+	Collection  string           `json:"collection"`
+	Query       string           `json:"query"`
+	Executions  int64            `json:"executions"`
+	Clicks      int64            `json:"clicks"`
+	PositionSum int64            `json:"positionSum"` // sum of the known click positions
+	Positioned  int64            `json:"positioned"`  // clicks with a known position
+	Docs        map[string]int64 `json:"docs"`
+	LastClick   time.Time        `json:"lastClick,omitzero"`
+}
+
+// QueryReport is the click-through rate of a query.
+type QueryReport struct {
+	Collection  string   `json:"collection"`
+	Query       string   `json:"query"`
+	Executions  int64    `json:"executions"`
+	Clicks      int64    `json:"clicks"`
+	CTR         float64  `json:"ctr"` // clicks per execution
+	AvgPosition float64  `json:"avgPosition,omitempty"`
+	TopDocs     []DocCTR `json:"topDocs,omitempty"`
+}
+
+// DocCTR is how often a document was opened from the results of a query.
+type DocCTR struct {
+	DocID  string `json:"docId"`
+	Clicks int64  `json:"clicks"`
+}
+
+// NormalizeQuery maps query texts differing only in case and whitespace to the same key.
+func NormalizeQuery(q string) string {
+	q = strings.Join(strings.Fields(strings.ToLower(q)), " ")
+	if q == "" {
+		return "*:*"
+	}
+	return q
+}
+
+// Tracker aggregates executions and clicks per query and appends clicks to a log. It is safe for concurrent use.
+type Tracker struct {
+	mu      sync.Mutex
+	since   time.Time
+	queries map[string]*QueryStats
+	logPath string
+}
+
+// NewTracker creates an empty Tracker. With a logPath, every click is appended to it as a JSON line.
+func NewTracker(logPath string) *Tracker {
+	return &Tracker{since: time.Now(), queries: make(map[string]*QueryStats), logPath: logPath}
+}
+
+func key(collection, query string) string {
+	return collection + "\x00" + query
+}
+
+// RecordExecution counts an execution of query on collection.
+func (t *Tracker) RecordExecution(collection, query string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if s := t.stats(collection, NormalizeQuery(query)); s != nil {
+		s.Executions++
+	}
+}
+
+// RecordClick adds a click to the statistics of its query and to the log.
+func (t *Tracker) RecordClick(c Click) error {
+	c.Query = NormalizeQuery(c.Query)
+	if c.Time.IsZero() {
+		c.Time = time.Now()
+	}
+	t.mu.Lock()
+	if s := t.stats(c.Collection, c.Query); s != nil {
+		s.Clicks++
+		s.Docs[c.DocID]++
+		if c.Position > 0 {
+			s.PositionSum += int64(c.Position)
+			s.Positioned++
+		}
+		s.LastClick = c.Time
+	}
+	t.mu.Unlock()
+	return t.appendLog(c)
+}
+
+func (t *Tracker) stats(collection, query string) *QueryStats {
+	k := key(collection, query)
+	s, ok := t.queries[k]
+	if !ok {
+		if len(t.queries) >= MaxQueries {
+			return nil
+		}
+		s = &QueryStats{Collection: collection, Query: query, Docs: map[string]int64{}}
+		t.queries[k] = s
+	}
+	return s
+}
+
+func (t *Tracker) appendLog(c Click) error {
+	if t.logPath == "" {
+		return nil
+	}
+	line, err := json.Marshal(c)
+	if err != nil {
+		return fmt.Errorf("encode click: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(t.logPath), 0o755); err != nil {
+		return fmt.Errorf("create feedback directory: %v", err)
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	f, err := os.OpenFile(t.logPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("write click log: %v", err)
+	}
+	defer f.Close()
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("write click log: %v", err)
+	}
+	return nil
+}
+
+// Since returns the start of the statistics.
+func (t *Tracker) Since() time.Time {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.since
+}
+
+// Report returns the click-through rates of the queries on collection (all collections when empty),
+// sorted by "executions" (default), "clicks" or "ctr", descending. A query filters to that query text.
+func (t *Tracker) Report(collection, query, sortBy string, limit int) []QueryReport {
+	t.mu.Lock()
+	var out []QueryReport
+	for _, s := range t.queries {
+		if (collection != "" && s.Collection != collection) || (query != "" && s.Query != NormalizeQuery(query)) {
+			continue
+		}
+		r := QueryReport{Collection: s.Collection, Query: s.Query, Executions: s.Executions, Clicks: s.Clicks}
+		if s.Executions > 0 {
+			r.CTR = float64(s.Clicks) / float64(s.Executions)
+		}
+		if s.Positioned > 0 {
+			r.AvgPosition = float64(s.PositionSum) / float64(s.Positioned)
+		}
+		for id, n := range s.Docs {
+			r.TopDocs = append(r.TopDocs, DocCTR{DocID: id, Clicks: n})
+		}
+		sort.Slice(r.TopDocs, func(i, j int) bool {
+			if r.TopDocs[i].Clicks != r.TopDocs[j].Clicks {
+				return r.TopDocs[i].Clicks > r.TopDocs[j].Clicks
+			}
+			return r.TopDocs[i].DocID < r.TopDocs[j].DocID
+		})
+		if len(r.TopDocs) > maxTopDocs {
+			r.TopDocs = r.TopDocs[:maxTopDocs]
+		}
+		out = append(out, r)
+	}
+	t.mu.Unlock()
+
+	metric := func(r QueryReport) float64 {
+		switch sortBy {
+		case "clicks":
+			return float64(r.Clicks)
+		case "ctr":
+			return r.CTR
+		}
+		return float64(r.Executions)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if a, b := metric(out[i]), metric(out[j]); a != b {
+			return a > b
+		}
+		return key(out[i].Collection, out[i].Query) < key(out[j].Collection, out[j].Query)
+	})
+	if limit > 0 && len(out) > limit {
+		out = out[:limit]
+	}
+	return out
+}
+
+// persisted is the file format of Save and Load.
+type persisted struct {
+	Since   time.Time    `json:"since"`
+	Queries []QueryStats `json:"queries"`
+}
+
+// Save writes the statistics to path, replacing the file atomically.
+func (t *Tracker) Save(path string) error {
+	t.mu.Lock()
+	p := persisted{Since: t.since, Queries: make([]QueryStats, 0, len(t.queries))}
+	for _, s := range t.queries {
+		p.Queries = append(p.Queries, *s)
+	}
+	data, err := json.Marshal(p)
+	t.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("encode feedback stats: %v", err)
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("write feedback stats: %v", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("write feedback stats: %v", err)
+	}
+	return nil
+}
+
+// Load replaces the statistics with those saved at path. A missing file is not an error.
+func (t *Tracker) Load(path string) error {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("read feedback stats: %v", err)
+	}
+	var p persisted
+	if err := json.Unmarshal(data, &p); err != nil {
+		return fmt.Errorf("decode feedback stats: %v", err)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !p.Since.IsZero() {
+		t.since = p.Since
+	}
+	t.queries = make(map[string]*QueryStats, len(p.Queries))
+	for _, s := range p.Queries {
+		if s.Docs == nil {
+			s.Docs = map[string]int64{}
+		}
+		t.queries[key(s.Collection, s.Query)] = &s
+	}
+	return nil
+}
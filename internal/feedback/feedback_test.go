@@ -0,0 +1,57 @@
+package feedback
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestReport tests the click-through rates per query.
+func TestReport(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "feedback", "clicks.jsonl")
+	tr := NewTracker(logPath)
+	for range 4 {
+		tr.RecordExecution("products", "Laptop  Bag")
+	}
+	tr.RecordExecution("products", "tv")
+	tr.RecordExecution("logs", "")
+	require.NoError(t, tr.RecordClick(Click{Collection: "products", Query: "laptop bag", DocID: "7", Position: 1}))
+	require.NoError(t, tr.RecordClick(Click{Collection: "products", Query: "LAPTOP bag", DocID: "7", Position: 3}))
+	require.NoError(t, tr.RecordClick(Click{Collection: "products", Query: "laptop bag", DocID: "2"}))
+
+	// Goal: Query texts differing in case and whitespace are aggregated, sorted by executions.
+	reports := tr.Report("", "", "", 0)
+	require.Len(t, reports, 3)
+	assert.Equal(t, QueryReport{
+		Collection: "products", Query: "laptop bag", Executions: 4, Clicks: 3, CTR: 0.75, AvgPosition: 2,
+		TopDocs: []DocCTR{{DocID: "7", Clicks: 2}, {DocID: "2", Clicks: 1}},
+	}, reports[0])
+
+	// Goal: Reports can be filtered by collection and query, and limited.
+	assert.Equal(t, "*:*", tr.Report("logs", "", "", 0)[0].Query)
+	assert.Len(t, tr.Report("products", "TV", "", 0), 1)
+	assert.Len(t, tr.Report("", "", "ctr", 1), 1)
+
+	// Goal: Every click is appended to the log with its normalized query.
+	data, err := os.ReadFile(logPath)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, lines, 3)
+	var c Click
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &c))
+	assert.Equal(t, "laptop bag", c.Query)
+	assert.Equal(t, 3, c.Position)
+	assert.False(t, c.Time.IsZero())
+
+	// Goal: Saved statistics load into a new tracker.
+	statsPath := filepath.Join(t.TempDir(), "stats.json")
+	require.NoError(t, tr.Save(statsPath))
+	loaded := NewTracker("")
+	require.NoError(t, loaded.Load(statsPath))
+	assert.Equal(t, reports, loaded.Report("", "", "", 0))
+}
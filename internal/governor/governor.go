@@ -0,0 +1,232 @@
+// Package governor keeps a burst of large tool calls from exhausting the memory of the server. It tracks the bytes
+// of the Solr responses read by the calls in flight, and admits a new call only while those bytes and the number of
+// running calls are within the budget. Other calls wait for a running one to finish, and are rejected as busy when
+// none finishes in time.
+package governor
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/config"
+	"github.com/Sashimimochi/solr-mcp-go/internal/errcatalog"
+)
+
+// BusyError rejects a call that found no room in the budget.
+type BusyError struct {
+	Reason     string
+	RetryAfter time.Duration
+}
+
+func (e *BusyError) Error() string {
+	return fmt.Sprintf("server busy: %s, retry in %ds", e.Reason, int(e.RetryAfter/time.Second))
+}
+
+// ErrorCode implements errcatalog.Coder.
+func (e *BusyError) ErrorCode() errcatalog.Code { return errcatalog.ServerBusy }
+
+// Stats is the current use of the budget.
+type Stats struct {
+	HeldBytes     int64 `json:"heldBytes"`
+	Running       int   `json:"running"`
+	Waiting       int   `json:"waiting"`
+	Rejected      int64 `json:"rejected"`
+	MaxBytes      int64 `json:"maxBytes,omitempty"`
+	MaxConcurrent int   `json:"maxConcurrent,omitempty"`
+}
+
+// Governor admits tool calls within a memory and concurrency budget. It is safe for concurrent use.
+type Governor struct {
+	mu       sync.Mutex
+	cfg      config.MemoryBudgetConfig
+	held     int64
+	running  int
+	waiting  int
+	rejected int64
+	released chan struct{} // closed and replaced whenever room may have been made
+	avg      time.Duration // moving average of the duration of calls, the retry hint of rejections
+}
+
+// New creates a Governor.
+func New(cfg config.MemoryBudgetConfig) *Governor {
+	return &Governor{cfg: cfg, released: make(chan struct{})}
+}
+
+// SetConfig replaces the budget, e.g. after a config reload. Waiting calls are admitted if it grew.
+func (g *Governor) SetConfig(cfg config.MemoryBudgetConfig) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.cfg = cfg
+	g.wake()
+}
+
+// Stats returns the current use of the budget.
+func (g *Governor) Stats() Stats {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return Stats{
+		HeldBytes:     g.held,
+		Running:       g.running,
+		Waiting:       g.waiting,
+		Rejected:      g.rejected,
+		MaxBytes:      g.cfg.MaxBytes,
+		MaxConcurrent: g.cfg.MaxConcurrent,
+	}
+}
+
+// Acquire admits a call, waiting up to memoryBudget.maxWait for room. The call must release the returned Ticket
+// when it finishes. It returns a *BusyError when no room was made in time, or ctx.Err() when ctx is done first.
+func (g *Governor) Acquire(ctx context.Context) (*Ticket, error) {
+	g.mu.Lock()
+	var timeout <-chan time.Time
+	for {
+		reason := g.full()
+		if reason == "" {
+			g.running++
+			g.mu.Unlock()
+			return &Ticket{g: g, start: time.Now()}, nil
+		}
+		if timeout == nil {
+			wait := g.cfg.MaxWaitDuration()
+			if wait == 0 {
+				return nil, g.reject(reason)
+			}
+			timer := time.NewTimer(wait)
+			defer timer.Stop()
+			timeout = timer.C
+		}
+		released := g.released
+		g.waiting++
+		g.mu.Unlock()
+		select {
+		case <-released:
+			g.mu.Lock()
+			g.waiting--
+		case <-timeout:
+			g.mu.Lock()
+			g.waiting--
+			return nil, g.reject(g.full())
+		case <-ctx.Done():
+			g.mu.Lock()
+			g.waiting--
+			g.mu.Unlock()
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// full returns why the budget has no room for another call, or "" if it has. g.mu must be held.
+func (g *Governor) full() string {
+	switch {
+	case g.cfg.MaxConcurrent > 0 && g.running >= g.cfg.MaxConcurrent:
+		return fmt.Sprintf("%d tool calls are running (memoryBudget.maxConcurrent is %d)", g.running, g.cfg.MaxConcurrent)
+	case g.cfg.MaxBytes > 0 && g.held >= g.cfg.MaxBytes:
+		return fmt.Sprintf("running tool calls hold %d bytes of Solr responses (memoryBudget.maxBytes is %d)", g.held, g.cfg.MaxBytes)
+	}
+	return ""
+}
+
+// reject counts a rejection and unlocks g.mu, which must be held.
+func (g *Governor) reject(reason string) error {
+	if reason == "" {
+		// Room was made just as the wait ran out; the caller retries right away
+		reason = "the budget was full until now"
+	}
+	g.rejected++
+	retry := time.Duration(math.Ceil(g.avg.Seconds())) * time.Second
+	g.mu.Unlock()
+	return &BusyError{Reason: reason, RetryAfter: max(retry, time.Second)}
+}
+
+// wake admits the waiting calls to check the budget again. g.mu must be held.
+func (g *Governor) wake() {
+	close(g.released)
+	g.released = make(chan struct{})
+}
+
+// Ticket is an admitted call. The bytes of the Solr responses it reads count against the budget until it is released.
+type Ticket struct {
+	g     *Governor
+	start time.Time
+	held  int64 // guarded by g.mu
+	done  bool  // guarded by g.mu
+}
+
+// add counts n bytes read for the call. Bytes read after the release, e.g. by a background job the call started,
+// are not counted.
+func (t *Ticket) add(n int64) {
+	t.g.mu.Lock()
+	defer t.g.mu.Unlock()
+	if t.done {
+		return
+	}
+	t.held += n
+	t.g.held += n
+}
+
+// Release gives the room of the call back. Later calls do nothing.
+func (t *Ticket) Release() {
+	g := t.g
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if t.done {
+		return
+	}
+	t.done = true
+	g.held -= t.held
+	g.running--
+	if d := time.Since(t.start); g.avg == 0 {
+		g.avg = d
+	} else {
+		g.avg = (4*g.avg + d) / 5
+	}
+	g.wake()
+}
+
+type ticketKey struct{}
+
+// WithTicket returns a context whose Solr responses count against the budget of t.
+func WithTicket(ctx context.Context, t *Ticket) context.Context {
+	return context.WithValue(ctx, ticketKey{}, t)
+}
+
+// Transport is an http.RoundTripper counting the response bytes of requests whose context carries a Ticket.
+type Transport struct {
+	Base http.RoundTripper
+}
+
+// Wrap returns a copy of client counting the response bytes of admitted calls.
+func Wrap(client *http.Client) *http.Client {
+	base := client.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	wrapped := *client
+	wrapped.Transport = &Transport{Base: base}
+	return &wrapped
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.Base.RoundTrip(req)
+	if ticket, ok := req.Context().Value(ticketKey{}).(*Ticket); ok && err == nil {
+		resp.Body = &countingBody{ReadCloser: resp.Body, ticket: ticket}
+	}
+	return resp, err
+}
+
+type countingBody struct {
+	io.ReadCloser
+	ticket *Ticket
+}
+
+func (b *countingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.ticket.add(int64(n))
+	return n, err
+}
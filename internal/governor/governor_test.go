@@ -0,0 +1,82 @@
+package governor
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/config"
+	"github.com/Sashimimochi/solr-mcp-go/internal/errcatalog"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestGovernor tests admitting, queueing and rejecting calls by the bytes they hold.
+func TestGovernor(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(strings.Repeat("x", 1000)))
+	}))
+	defer server.Close()
+	client := Wrap(server.Client())
+	g := New(config.MemoryBudgetConfig{MaxBytes: 1000, MaxWait: "0s"})
+	ctx := context.Background()
+
+	read := func(ticket *Ticket) {
+		req, err := http.NewRequestWithContext(WithTicket(ctx, ticket), http.MethodGet, server.URL, nil)
+		require.NoError(t, err)
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		_, err = io.Copy(io.Discard, resp.Body)
+		require.NoError(t, err)
+	}
+
+	// Goal: The response bytes of an admitted call count against the budget.
+	first, err := g.Acquire(ctx)
+	require.NoError(t, err)
+	read(first)
+	assert.Equal(t, Stats{HeldBytes: 1000, Running: 1, MaxBytes: 1000}, g.Stats())
+
+	// Goal: Calls are rejected as busy while the budget is used up, with a retry hint.
+	_, err = g.Acquire(ctx)
+	var busy *BusyError
+	require.ErrorAs(t, err, &busy)
+	assert.Equal(t, errcatalog.ServerBusy, errcatalog.CodeOf(err))
+	assert.Equal(t, time.Second, busy.RetryAfter)
+	assert.Equal(t, "server busy: running tool calls hold 1000 bytes of Solr responses (memoryBudget.maxBytes is 1000), retry in 1s", err.Error())
+
+	// Goal: Waiting calls are admitted when a running call releases its bytes.
+	g.SetConfig(config.MemoryBudgetConfig{MaxBytes: 1000, MaxWait: "1m"})
+	admitted := make(chan *Ticket)
+	go func() {
+		ticket, err := g.Acquire(ctx)
+		assert.NoError(t, err)
+		admitted <- ticket
+	}()
+	require.Eventually(t, func() bool { return g.Stats().Waiting == 1 }, time.Second, time.Millisecond)
+	first.Release()
+	second := <-admitted
+	assert.Equal(t, Stats{Running: 1, Rejected: 1, MaxBytes: 1000}, g.Stats())
+
+	// Goal: Bytes read after the release do not count.
+	second.Release()
+	second.Release()
+	read(second)
+	assert.Equal(t, Stats{Rejected: 1, MaxBytes: 1000}, g.Stats())
+
+	// Goal: The number of running calls is limited too, and a waiting caller can give up.
+	g.SetConfig(config.MemoryBudgetConfig{MaxConcurrent: 1})
+	third, err := g.Acquire(ctx)
+	require.NoError(t, err)
+	defer third.Release()
+	canceled, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	_, err = g.Acquire(canceled)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Zero(t, g.Stats().Waiting)
+}
@@ -0,0 +1,411 @@
+// Package jobs runs exports, reindexes and imports in the background. Each job pages through a collection with
+// cursorMark, or through an object by byte offset, and checkpoints the cursor after every batch, so interrupted
+// jobs resume where they left off after a restart.
+package jobs
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/objectstore"
+)
+
+// Job kinds.
+const (
+	Export  = "export"
+	Reindex = "reindex"
+	Import  = "import"
+)
+
+// Job states.
+const (
+	Running   = "running"
+	Done      = "done"
+	Failed    = "failed"
+	Cancelled = "cancelled"
+)
+
+// DefaultBatchSize is the number of documents read per request.
+const DefaultBatchSize = 500
+
+// Throttle limits the load a job puts on Solr. Zero values do not limit.
+type Throttle struct {
+	MaxRequestsPerSecond float64 `json:"maxRequestsPerSecond,omitempty"`
+	MaxBytesPerSecond    int64   `json:"maxBytesPerSecond,omitempty"`
+}
+
+// delay is the minimum duration of a batch of size bytes.
+func (t Throttle) delay(bytes int64) time.Duration {
+	var d time.Duration
+	if t.MaxRequestsPerSecond > 0 {
+		d = time.Duration(float64(time.Second) / t.MaxRequestsPerSecond)
+	}
+	if t.MaxBytesPerSecond > 0 {
+		d = max(d, time.Duration(float64(bytes)/float64(t.MaxBytesPerSecond)*float64(time.Second)))
+	}
+	return d
+}
+
+// Job is an export, reindex or import and its checkpoint.
+type Job struct {
+	ID         string   `json:"id"`
+	Kind       string   `json:"kind"`
+	Collection string   `json:"collection"`
+	Query      string   `json:"query"`
+	Fields     []string `json:"fields,omitempty"`
+	UniqueKey  string   `json:"uniqueKey"`
+	Target     string   `json:"target"`           // file of an export, collection of a reindex
+	Source     string   `json:"source,omitempty"` // object URI an import reads; Collection is then written
+	BatchSize  int      `json:"batchSize"`
+	Throttle   Throttle `json:"throttle"`
+	// Destination is the object store an export is uploaded to when it is done, and Object the uploaded file.
+	Destination string              `json:"destination,omitempty"`
+	Object      *objectstore.Object `json:"object,omitempty"`
+
+	Status    string    `json:"status"`
+	Cursor    string    `json:"cursor"`
+	Matched   int64     `json:"matched"`   // documents matching the query when the job started
+	Processed int64     `json:"processed"` // documents written up to the checkpoint
+	Bytes     int64     `json:"bytes"`     // bytes written up to the checkpoint
+	Resumed   int       `json:"resumed,omitempty"`
+	Error     string    `json:"error,omitempty"`
+	Created   time.Time `json:"created"`
+	Updated   time.Time `json:"updated"`
+}
+
+// Page is a batch of documents read at a cursor.
+type Page struct {
+	Docs []map[string]any
+	Next string // cursor of the next page; equal to the current cursor at the end
+}
+
+// FetchFunc reads the page of job at cursor.
+type FetchFunc func(ctx context.Context, job Job, cursor string) (Page, error)
+
+// Sink writes the documents of a job kind.
+type Sink interface {
+	// Write stores a batch of documents after the checkpoint of job and returns the bytes written.
+	// It is called again with the same documents if the job is interrupted before the checkpoint is saved.
+	Write(ctx context.Context, job Job, docs []map[string]any) (int64, error)
+	// Finish is called once all documents have been written. It may record its results in job.
+	Finish(ctx context.Context, job *Job) error
+}
+
+// Manager starts jobs, stores their checkpoints in a directory and resumes them. It is safe for concurrent use.
+type Manager struct {
+	dir   string
+	fetch FetchFunc
+	sinks map[string]Sink
+
+	mu     sync.Mutex
+	ctx    context.Context
+	jobs   map[string]*Job
+	active map[string]*runner
+	wg     sync.WaitGroup
+}
+
+// runner is the goroutine of a running job.
+type runner struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewManager creates a Manager keeping checkpoints in dir.
+func NewManager(dir string, fetch FetchFunc, sinks map[string]Sink) *Manager {
+	return &Manager{
+		dir:    dir,
+		fetch:  fetch,
+		sinks:  sinks,
+		ctx:    context.Background(),
+		jobs:   make(map[string]*Job),
+		active: make(map[string]*runner),
+	}
+}
+
+// ResumeAll loads the stored jobs and resumes those that were running, e.g. when the server stopped.
+// Jobs started later run until ctx is done.
+func (m *Manager) ResumeAll(ctx context.Context) error {
+	m.mu.Lock()
+	m.ctx = ctx
+	m.mu.Unlock()
+	if err := m.load(); err != nil {
+		return err
+	}
+	for _, j := range m.List() {
+		if j.Status == Running {
+			slog.Info("Resuming background job", "id", j.ID, "kind", j.Kind, "collection", j.Collection, "processed", j.Processed)
+			if _, err := m.Resume(j.ID); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (m *Manager) load() error {
+	entries, err := os.ReadDir(m.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("read jobs: %v", err)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".json") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(m.dir, e.Name()))
+		if err != nil {
+			return fmt.Errorf("read job: %v", err)
+		}
+		var j Job
+		if err := json.Unmarshal(data, &j); err != nil {
+			return fmt.Errorf("decode job %s: %v", e.Name(), err)
+		}
+		if _, ok := m.jobs[j.ID]; !ok {
+			m.jobs[j.ID] = &j
+		}
+	}
+	return nil
+}
+
+// NewID returns a random job ID prefixed with its kind.
+func NewID(kind string) string {
+	id := make([]byte, 6)
+	_, _ = rand.Read(id)
+	return kind + "-" + hex.EncodeToString(id)
+}
+
+// Start stores a new job and runs it in the background. Jobs without an ID get one from NewID.
+func (m *Manager) Start(job Job) (Job, error) {
+	if _, ok := m.sinks[job.Kind]; !ok {
+		return Job{}, fmt.Errorf("unknown job kind %q", job.Kind)
+	}
+	if job.ID == "" {
+		job.ID = NewID(job.Kind)
+	}
+	if job.BatchSize <= 0 {
+		job.BatchSize = DefaultBatchSize
+	}
+	job.Status, job.Cursor = Running, "*"
+	job.Created, job.Updated = time.Now().UTC(), time.Now().UTC()
+	if err := m.save(job); err != nil {
+		return Job{}, err
+	}
+	m.mu.Lock()
+	m.jobs[job.ID] = &job
+	m.mu.Unlock()
+	m.run(job)
+	return job, nil
+}
+
+// Resume continues a running, failed or cancelled job from its checkpoint.
+func (m *Manager) Resume(id string) (Job, error) {
+	m.mu.Lock()
+	j, ok := m.jobs[id]
+	if !ok {
+		m.mu.Unlock()
+		return Job{}, fmt.Errorf("unknown job %s", id)
+	}
+	if _, running := m.active[id]; running {
+		m.mu.Unlock()
+		return Job{}, fmt.Errorf("job %s is already running", id)
+	}
+	if j.Status == Done {
+		m.mu.Unlock()
+		return Job{}, fmt.Errorf("job %s is done", id)
+	}
+	j.Status, j.Error = Running, ""
+	j.Resumed++
+	job := *j
+	m.mu.Unlock()
+	if err := m.save(job); err != nil {
+		return Job{}, err
+	}
+	m.run(job)
+	return job, nil
+}
+
+// Cancel stops a running job. Its checkpoint is kept, so it can be resumed.
+func (m *Manager) Cancel(id string) (Job, error) {
+	m.mu.Lock()
+	j, ok := m.jobs[id]
+	r, running := m.active[id]
+	status := ""
+	if ok {
+		status = j.Status
+	}
+	m.mu.Unlock()
+	if !ok {
+		return Job{}, fmt.Errorf("unknown job %s", id)
+	}
+	if !running {
+		return Job{}, fmt.Errorf("job %s is not running (status %s)", id, status)
+	}
+	r.cancel()
+	<-r.done
+	return m.Get(id)
+}
+
+// Wait blocks until all running jobs have stopped, e.g. after the context of ResumeAll is done.
+func (m *Manager) Wait() {
+	m.wg.Wait()
+}
+
+// Get returns a job.
+func (m *Manager) Get(id string) (Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	j, ok := m.jobs[id]
+	if !ok {
+		return Job{}, fmt.Errorf("unknown job %s", id)
+	}
+	return *j, nil
+}
+
+// List returns all jobs, newest first.
+func (m *Manager) List() []Job {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := make([]Job, 0, len(m.jobs))
+	for _, j := range m.jobs {
+		out = append(out, *j)
+	}
+	sort.Slice(out, func(i, k int) bool {
+		if !out[i].Created.Equal(out[k].Created) {
+			return out[i].Created.After(out[k].Created)
+		}
+		return out[i].ID < out[k].ID
+	})
+	return out
+}
+
+func (m *Manager) run(job Job) {
+	m.mu.Lock()
+	ctx, cancel := context.WithCancel(m.ctx)
+	r := &runner{cancel: cancel, done: make(chan struct{})}
+	m.active[job.ID] = r
+	m.mu.Unlock()
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		defer close(r.done)
+		defer func() {
+			m.mu.Lock()
+			delete(m.active, job.ID)
+			m.mu.Unlock()
+			cancel()
+		}()
+		err := m.runBatches(ctx, &job)
+		switch {
+		case err == nil:
+			job.Status = Done
+			slog.Info("Background job done", "id", job.ID, "kind", job.Kind, "processed", job.Processed)
+		case ctx.Err() != nil && m.stopping():
+			// The server is stopping: the job stays running and resumes on the next start
+			return
+		case ctx.Err() != nil:
+			job.Status = Cancelled
+			slog.Info("Background job cancelled", "id", job.ID, "kind", job.Kind, "processed", job.Processed)
+		default:
+			job.Status, job.Error = Failed, err.Error()
+			slog.Error("Background job failed", "id", job.ID, "kind", job.Kind, "processed", job.Processed, "error", err)
+		}
+		m.checkpoint(job)
+	}()
+}
+
+func (m *Manager) stopping() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.ctx.Err() != nil
+}
+
+// runBatches copies the pages of job from its cursor on, saving a checkpoint after each batch.
+func (m *Manager) runBatches(ctx context.Context, job *Job) error {
+	sink := m.sinks[job.Kind]
+	for {
+		start := time.Now()
+		page, err := m.fetch(ctx, *job, job.Cursor)
+		if err != nil {
+			return fmt.Errorf("read %s at cursor %s: %v", job.source(), job.Cursor, err)
+		}
+		var written int64
+		if len(page.Docs) > 0 {
+			if written, err = sink.Write(ctx, *job, page.Docs); err != nil {
+				return err
+			}
+			job.Processed += int64(len(page.Docs))
+			job.Bytes += written
+		}
+		end := page.Next == "" || page.Next == job.Cursor
+		if !end {
+			job.Cursor = page.Next
+		}
+		m.checkpoint(*job)
+		if end {
+			return sink.Finish(ctx, job)
+		}
+		if wait := job.Throttle.delay(written) - time.Since(start); wait > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(wait):
+			}
+		} else if err := ctx.Err(); err != nil {
+			return err
+		}
+	}
+}
+
+// source is what the job reads.
+func (j *Job) source() string {
+	if j.Source != "" {
+		return j.Source
+	}
+	return j.Collection
+}
+
+// checkpoint updates the job in memory and in its file. A failed save is logged: the job then repeats
+// some batches when it is resumed, which the sinks tolerate.
+func (m *Manager) checkpoint(job Job) {
+	job.Updated = time.Now().UTC()
+	m.mu.Lock()
+	m.jobs[job.ID] = &job
+	m.mu.Unlock()
+	if err := m.save(job); err != nil {
+		slog.Error("Failed to save job checkpoint", "id", job.ID, "error", err)
+	}
+}
+
+// save writes the job to its file, replacing it atomically.
+func (m *Manager) save(job Job) error {
+	if err := os.MkdirAll(m.dir, 0o755); err != nil {
+		return fmt.Errorf("create jobs directory: %v", err)
+	}
+	data, err := json.MarshalIndent(job, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode job: %v", err)
+	}
+	path := filepath.Join(m.dir, job.ID+".json")
+	if err := os.WriteFile(path+".tmp", data, 0o644); err != nil {
+		return fmt.Errorf("write job: %v", err)
+	}
+	if err := os.Rename(path+".tmp", path); err != nil {
+		return fmt.Errorf("write job: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,166 @@
+package jobs
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pagedFetch serves docs in pages of the batch size of a job, using the offset as cursor.
+// Once block is closed, fetches after the first page wait until their context is done.
+func pagedFetch(docs []map[string]any, block chan struct{}) FetchFunc {
+	return func(ctx context.Context, job Job, cursor string) (Page, error) {
+		start := 0
+		if cursor != "*" {
+			start, _ = strconv.Atoi(cursor)
+		}
+		if block != nil && start > 0 {
+			select {
+			case <-block:
+			case <-ctx.Done():
+				return Page{}, ctx.Err()
+			}
+		}
+		end := min(start+job.BatchSize, len(docs))
+		return Page{Docs: docs[start:end], Next: strconv.Itoa(end)}, nil
+	}
+}
+
+// memorySink records the written document IDs.
+type memorySink struct {
+	mu       sync.Mutex
+	ids      []any
+	finished bool
+}
+
+func (s *memorySink) Write(_ context.Context, _ Job, docs []map[string]any) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, d := range docs {
+		s.ids = append(s.ids, d["id"])
+	}
+	return int64(10 * len(docs)), nil
+}
+
+func (s *memorySink) Finish(context.Context, *Job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.finished = true
+	return nil
+}
+
+func testDocs(n int) []map[string]any {
+	docs := make([]map[string]any, n)
+	for i := range docs {
+		docs[i] = map[string]any{"id": strconv.Itoa(i)}
+	}
+	return docs
+}
+
+// waitStatus waits until the job has the status.
+func waitStatus(t *testing.T, m *Manager, id, status string) Job {
+	t.Helper()
+	var job Job
+	require.Eventually(t, func() bool {
+		job, _ = m.Get(id)
+		return job.Status == status
+	}, 5*time.Second, 5*time.Millisecond)
+	return job
+}
+
+// TestManagerRun tests that a job copies all pages and records its progress.
+func TestManagerRun(t *testing.T) {
+	sink := &memorySink{}
+	m := NewManager(t.TempDir(), pagedFetch(testDocs(5), nil), map[string]Sink{Export: sink})
+
+	job, err := m.Start(Job{Kind: Export, Collection: "products", BatchSize: 2})
+	require.NoError(t, err)
+	assert.Contains(t, job.ID, "export-")
+
+	// Goal: All documents are written once and the sink is finished.
+	job = waitStatus(t, m, job.ID, Done)
+	assert.Equal(t, int64(5), job.Processed)
+	assert.Equal(t, int64(50), job.Bytes)
+	assert.Len(t, sink.ids, 5)
+	assert.True(t, sink.finished)
+
+	// Goal: Done jobs cannot be resumed, and unknown kinds are rejected.
+	_, err = m.Resume(job.ID)
+	assert.ErrorContains(t, err, "is done")
+	_, err = m.Start(Job{Kind: "copy"})
+	assert.ErrorContains(t, err, "unknown job kind")
+}
+
+// TestManagerResumeAfterRestart tests that a job interrupted by a shutdown resumes from its checkpoint.
+func TestManagerResumeAfterRestart(t *testing.T) {
+	dir := t.TempDir()
+	docs := testDocs(6)
+	first := &memorySink{}
+	m := NewManager(dir, pagedFetch(docs, make(chan struct{})), map[string]Sink{Reindex: first})
+	ctx, stop := context.WithCancel(context.Background())
+	require.NoError(t, m.ResumeAll(ctx))
+	job, err := m.Start(Job{Kind: Reindex, Collection: "products", Target: "products_v2", BatchSize: 2})
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		j, _ := m.Get(job.ID)
+		return j.Processed == 2
+	}, 5*time.Second, 5*time.Millisecond)
+	stop()
+	m.Wait()
+
+	// Goal: A job stopped by the shutdown stays running with its checkpoint.
+	job, err = m.Get(job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, Running, job.Status)
+	assert.Equal(t, "2", job.Cursor)
+
+	// Goal: A new manager resumes it from the checkpoint without repeating written batches.
+	second := &memorySink{}
+	m = NewManager(dir, pagedFetch(docs, nil), map[string]Sink{Reindex: second})
+	require.NoError(t, m.ResumeAll(context.Background()))
+	job = waitStatus(t, m, job.ID, Done)
+	assert.Equal(t, int64(6), job.Processed)
+	assert.Equal(t, 1, job.Resumed)
+	assert.Equal(t, []any{"2", "3", "4", "5"}, second.ids)
+}
+
+// TestManagerCancel tests cancelling and resuming a job.
+func TestManagerCancel(t *testing.T) {
+	block := make(chan struct{})
+	sink := &memorySink{}
+	m := NewManager(t.TempDir(), pagedFetch(testDocs(4), block), map[string]Sink{Export: sink})
+	job, err := m.Start(Job{Kind: Export, Collection: "products", BatchSize: 2})
+	require.NoError(t, err)
+
+	// Goal: A cancelled job keeps its checkpoint.
+	require.Eventually(t, func() bool {
+		j, _ := m.Get(job.ID)
+		return j.Processed == 2
+	}, 5*time.Second, 5*time.Millisecond)
+	job, err = m.Cancel(job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, Cancelled, job.Status)
+	_, err = m.Cancel(job.ID)
+	assert.ErrorContains(t, err, "is not running")
+
+	// Goal: A resumed job continues from the checkpoint.
+	close(block)
+	_, err = m.Resume(job.ID)
+	require.NoError(t, err)
+	job = waitStatus(t, m, job.ID, Done)
+	assert.Equal(t, int64(4), job.Processed)
+	assert.Len(t, sink.ids, 4)
+}
+
+// TestThrottleDelay tests the minimum duration of a batch.
+func TestThrottleDelay(t *testing.T) {
+	assert.Equal(t, time.Duration(0), Throttle{}.delay(1000))
+	assert.Equal(t, 500*time.Millisecond, Throttle{MaxRequestsPerSecond: 2}.delay(1000))
+	// Goal: The stricter limit wins.
+	assert.Equal(t, 2*time.Second, Throttle{MaxRequestsPerSecond: 2, MaxBytesPerSecond: 500}.delay(1000))
+}
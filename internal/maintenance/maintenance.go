@@ -0,0 +1,364 @@
+// Package maintenance runs routine index hygiene in a daily window: merging away deleted documents, running the
+// retention policies and backing up collections, between health checks before and after, with a summary sent
+// to the notifier.
+package maintenance
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/config"
+	"github.com/Sashimimochi/solr-mcp-go/internal/retention"
+	"github.com/Sashimimochi/solr-mcp-go/internal/solr"
+)
+
+// Step kinds.
+const (
+	KindMerge  = "merge"
+	KindBackup = "backup"
+)
+
+// Health grades, the same as solr.healthcheck.full.
+const (
+	HealthGreen  = "GREEN"
+	HealthYellow = "YELLOW"
+	HealthRed    = "RED"
+)
+
+// maxHistory is the number of runs kept in memory.
+const maxHistory = 20
+
+// Backend performs the Solr operations of the maintenance job.
+type Backend interface {
+	ListCollections(ctx context.Context) ([]string, error)
+	IndexStats(ctx context.Context, collection string) (solr.IndexStats, error)
+	ExpungeDeletes(ctx context.Context, collection string) error
+	Optimize(ctx context.Context, collection string, maxSegments int) error
+	Backup(ctx context.Context, collection string, cfg config.MaintenanceBackupConfig) error
+}
+
+// Health is the outcome of a health check of the cluster.
+type Health struct {
+	Status   string   `json:"status"`
+	Findings []string `json:"findings,omitempty"`
+}
+
+// HealthCheck grades the cluster.
+type HealthCheck func(ctx context.Context) Health
+
+// Step is a merge or backup of one collection.
+type Step struct {
+	Kind         string   `json:"kind"`
+	Collection   string   `json:"collection"`
+	Action       string   `json:"action,omitempty"`       // merge mode, or why the collection was left alone
+	DeletedShare *float64 `json:"deletedShare,omitempty"` // share of deleted documents before a merge
+	Executed     bool     `json:"executed"`
+	DurationMs   int64    `json:"durationMs"`
+	Error        string   `json:"error,omitempty"`
+}
+
+// Run is the record of one maintenance run.
+type Run struct {
+	Started   time.Time      `json:"started"`
+	Finished  time.Time      `json:"finished"`
+	Trigger   string         `json:"trigger"`           // "schedule"
+	Skipped   string         `json:"skipped,omitempty"` // why the work was not done
+	Before    *Health        `json:"before,omitempty"`
+	After     *Health        `json:"after,omitempty"`
+	Steps     []Step         `json:"steps"`
+	Retention *retention.Run `json:"retention,omitempty"`
+	Errors    []string       `json:"errors,omitempty"` // failures outside the steps
+}
+
+// Failed returns the number of steps that failed.
+func (r Run) Failed() int {
+	n := 0
+	for _, s := range r.Steps {
+		if s.Error != "" {
+			n++
+		}
+	}
+	if r.Retention != nil {
+		for _, a := range r.Retention.Actions {
+			if a.Error != "" {
+				n++
+			}
+		}
+	}
+	return n + len(r.Errors)
+}
+
+// Summary describes the run in one line.
+func (r Run) Summary() string {
+	if r.Skipped != "" {
+		return fmt.Sprintf("Maintenance skipped: %s", r.Skipped)
+	}
+	counts := map[string]int{}
+	for _, s := range r.Steps {
+		if s.Executed {
+			counts[s.Kind]++
+		}
+	}
+	parts := []string{fmt.Sprintf("%d merges", counts[KindMerge]), fmt.Sprintf("%d backups", counts[KindBackup])}
+	if r.Retention != nil {
+		parts = append(parts, fmt.Sprintf("%d retention actions", len(r.Retention.Actions)))
+	}
+	summary := fmt.Sprintf("Maintenance finished in %s: %s", r.Finished.Sub(r.Started).Round(time.Second), strings.Join(parts, ", "))
+	if n := r.Failed(); n > 0 {
+		summary += fmt.Sprintf(", %d failed", n)
+	}
+	if r.Before != nil && r.After != nil {
+		summary += fmt.Sprintf("; health %s before, %s after", r.Before.Status, r.After.Status)
+	}
+	return summary
+}
+
+// Manager runs the maintenance job against a Backend.
+type Manager struct {
+	mu        sync.RWMutex
+	cfg       config.MaintenanceConfig
+	backend   Backend
+	health    HealthCheck
+	retention func(ctx context.Context) (retention.Run, error)
+	notify    func(ctx context.Context, r Run)
+	history   []Run
+	lastStart time.Time // start of the window occurrence of the last run
+	now       func() time.Time
+}
+
+// NewManager creates a Manager. retention executes the retention policies and may be nil; notify is called with
+// every finished run.
+func NewManager(cfg config.MaintenanceConfig, backend Backend, health HealthCheck, retention func(context.Context) (retention.Run, error), notify func(context.Context, Run)) *Manager {
+	return &Manager{cfg: cfg, backend: backend, health: health, retention: retention, notify: notify, now: time.Now}
+}
+
+// SetConfig replaces the configuration, e.g. after a config reload.
+func (m *Manager) SetConfig(cfg config.MaintenanceConfig) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.cfg = cfg
+}
+
+// Config returns the configuration.
+func (m *Manager) Config() config.MaintenanceConfig {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.cfg
+}
+
+// History returns the most recent runs, newest first.
+func (m *Manager) History() []Run {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	out := make([]Run, len(m.history))
+	for i, r := range m.history {
+		out[len(m.history)-1-i] = r
+	}
+	return out
+}
+
+// NextWindow returns the start of the next occurrence of the window, or the current one if now is inside it.
+// ok is false when no window is configured.
+func (m *Manager) NextWindow() (time.Time, bool) {
+	cfg := m.Config()
+	now := m.now()
+	if start, _, ok := Window(cfg, now); ok {
+		return start, true
+	}
+	for _, day := range []int{0, 1} {
+		start, _, ok := occurrence(cfg, now, day)
+		if ok && start.After(now) {
+			return start, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// Window returns the occurrence of the configured window that now falls in. ok is false outside the window or
+// when no window is configured.
+func Window(cfg config.MaintenanceConfig, now time.Time) (start, end time.Time, ok bool) {
+	// A window spanning midnight may have started the day before
+	for _, day := range []int{-1, 0} {
+		start, end, ok := occurrence(cfg, now, day)
+		if ok && !now.Before(start) && now.Before(end) {
+			return start, end, true
+		}
+	}
+	return time.Time{}, time.Time{}, false
+}
+
+// occurrence returns the occurrence of the window starting on the day the given number of days after now.
+func occurrence(cfg config.MaintenanceConfig, now time.Time, days int) (start, end time.Time, ok bool) {
+	from, to, err := config.ParseWindow(cfg.Window)
+	if err != nil {
+		return time.Time{}, time.Time{}, false
+	}
+	loc, err := cfg.Location()
+	if err != nil {
+		return time.Time{}, time.Time{}, false
+	}
+	local := now.In(loc)
+	at := func(days int, offset time.Duration) time.Time {
+		return time.Date(local.Year(), local.Month(), local.Day()+days, int(offset/time.Hour), int(offset%time.Hour/time.Minute), 0, 0, loc)
+	}
+	start = at(days, from)
+	if to > from {
+		return start, at(days, to), true
+	}
+	return start, at(days+1, to), true
+}
+
+// RunScheduled checks every minute whether the window has begun and runs the job once per occurrence of the
+// window, bounded by its end, until ctx is done. It returns immediately when no window is configured.
+func (m *Manager) RunScheduled(ctx context.Context) {
+	if m.Config().Window == "" {
+		return
+	}
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+	for {
+		m.tick(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// tick runs the job if now is inside a window occurrence that has not run yet.
+func (m *Manager) tick(ctx context.Context) bool {
+	cfg := m.Config()
+	start, end, ok := Window(cfg, m.now())
+	m.mu.Lock()
+	if !ok || !start.After(m.lastStart) {
+		m.mu.Unlock()
+		return false
+	}
+	m.lastStart = start
+	m.mu.Unlock()
+	runCtx, cancel := context.WithDeadline(ctx, end)
+	defer cancel()
+	m.Execute(runCtx, "schedule")
+	return true
+}
+
+// Execute runs the job once: a health check, the merges, the retention policies and the backups, then a second
+// health check. The work is skipped when the cluster is RED before it starts, and the remaining steps are
+// skipped once ctx is done, e.g. at the end of the window.
+func (m *Manager) Execute(ctx context.Context, trigger string) Run {
+	cfg := m.Config()
+	run := Run{Started: m.now(), Trigger: trigger, Steps: []Step{}}
+	slog.Info("Maintenance started", "trigger", trigger)
+
+	before := m.health(ctx)
+	run.Before = &before
+	if before.Status == HealthRed {
+		run.Skipped = "the cluster is RED: " + strings.Join(before.Findings, "; ")
+	} else {
+		m.work(ctx, cfg, &run)
+		after := m.health(context.WithoutCancel(ctx))
+		run.After = &after
+	}
+	run.Finished = m.now()
+
+	m.mu.Lock()
+	m.history = append(m.history, run)
+	if len(m.history) > maxHistory {
+		m.history = m.history[len(m.history)-maxHistory:]
+	}
+	m.mu.Unlock()
+	slog.Info("Maintenance finished", "trigger", trigger, "steps", len(run.Steps), "failed", run.Failed(), "skipped", run.Skipped)
+	if m.notify != nil {
+		m.notify(context.WithoutCancel(ctx), run)
+	}
+	return run
+}
+
+// work merges the collections over the threshold, runs the retention policies and backs up the collections.
+func (m *Manager) work(ctx context.Context, cfg config.MaintenanceConfig, run *Run) {
+	collections := cfg.Collections
+	if len(collections) == 0 {
+		names, err := m.backend.ListCollections(ctx)
+		if err != nil {
+			run.Errors = append(run.Errors, fmt.Sprintf("list collections: %v", err))
+		}
+		collections = slices.Sorted(slices.Values(names))
+	}
+
+	for _, c := range collections {
+		if ctx.Err() != nil {
+			break
+		}
+		run.Steps = append(run.Steps, m.merge(ctx, cfg, c))
+	}
+
+	// Retention may drop collections, so it runs before the backups
+	if cfg.Retention && m.retention != nil && ctx.Err() == nil {
+		r, err := m.retention(ctx)
+		if err != nil {
+			run.Errors = append(run.Errors, fmt.Sprintf("retention: %v", err))
+		} else {
+			run.Retention = &r
+		}
+	}
+
+	if cfg.Backup.Location != "" {
+		for _, c := range collections {
+			if ctx.Err() != nil {
+				break
+			}
+			if r := run.Retention; r != nil && slices.ContainsFunc(r.Actions, func(a retention.Action) bool {
+				return a.Kind == retention.KindDropCollection && a.Collection == c && a.Executed
+			}) {
+				continue
+			}
+			step := Step{Kind: KindBackup, Collection: c}
+			start := time.Now()
+			if err := m.backend.Backup(ctx, c, cfg.Backup); err != nil {
+				step.Error = err.Error()
+			} else {
+				step.Executed = true
+			}
+			step.DurationMs = time.Since(start).Milliseconds()
+			run.Steps = append(run.Steps, step)
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		run.Errors = append(run.Errors, fmt.Sprintf("stopped at the end of the window: %v", err))
+	}
+}
+
+// merge merges the collection if its share of deleted documents reaches the threshold.
+func (m *Manager) merge(ctx context.Context, cfg config.MaintenanceConfig, collection string) Step {
+	step := Step{Kind: KindMerge, Collection: collection}
+	stats, err := m.backend.IndexStats(ctx, collection)
+	if err != nil {
+		step.Error = err.Error()
+		return step
+	}
+	step.DeletedShare = &stats.DeletedShare
+	if stats.DeletedShare < cfg.Threshold() {
+		step.Action = "below threshold"
+		return step
+	}
+	step.Action = cfg.MergeMode()
+	start := time.Now()
+	if step.Action == config.MergeOptimize {
+		err = m.backend.Optimize(ctx, collection, cfg.MaxSegments)
+	} else {
+		err = m.backend.ExpungeDeletes(ctx, collection)
+	}
+	step.DurationMs = time.Since(start).Milliseconds()
+	if err != nil {
+		step.Error = err.Error()
+		return step
+	}
+	step.Executed = true
+	return step
+}
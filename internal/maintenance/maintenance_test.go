@@ -0,0 +1,131 @@
+package maintenance
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/config"
+	"github.com/Sashimimochi/solr-mcp-go/internal/retention"
+	"github.com/Sashimimochi/solr-mcp-go/internal/solr"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeBackend answers index stats from a fixed map and records merges and backups.
+type fakeBackend struct {
+	deleted   map[string]float64 // collection -> deleted share
+	merges    []string
+	backups   []string
+	backupErr error
+}
+
+func (b *fakeBackend) ListCollections(context.Context) ([]string, error) {
+	var names []string
+	for name := range b.deleted {
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+func (b *fakeBackend) IndexStats(_ context.Context, collection string) (solr.IndexStats, error) {
+	return solr.IndexStats{DeletedShare: b.deleted[collection]}, nil
+}
+
+func (b *fakeBackend) ExpungeDeletes(_ context.Context, collection string) error {
+	b.merges = append(b.merges, "expungeDeletes "+collection)
+	return nil
+}
+
+func (b *fakeBackend) Optimize(_ context.Context, collection string, _ int) error {
+	b.merges = append(b.merges, "optimize "+collection)
+	return nil
+}
+
+func (b *fakeBackend) Backup(_ context.Context, collection string, _ config.MaintenanceBackupConfig) error {
+	if b.backupErr != nil {
+		return b.backupErr
+	}
+	b.backups = append(b.backups, collection)
+	return nil
+}
+
+// TestExecute tests a maintenance run between two health checks.
+func TestExecute(t *testing.T) {
+	backend := &fakeBackend{deleted: map[string]float64{"logs": 0.3, "products": 0.05, "events_2024-01-01": 0.5}}
+	health := Health{Status: HealthGreen}
+	var notified []Run
+	m := NewManager(config.MaintenanceConfig{
+		Window:    "02:00-04:00",
+		Retention: true,
+		Backup:    config.MaintenanceBackupConfig{Location: "/backups"},
+	}, backend, func(context.Context) Health { return health }, func(context.Context) (retention.Run, error) {
+		return retention.Run{Actions: []retention.Action{{Kind: retention.KindDropCollection, Collection: "events_2024-01-01", Executed: true}}}, nil
+	}, func(_ context.Context, r Run) { notified = append(notified, r) })
+
+	run := m.Execute(context.Background(), "schedule")
+
+	// Goal: Collections over the threshold are merged, and dropped collections are not backed up.
+	assert.Equal(t, []string{"expungeDeletes events_2024-01-01", "expungeDeletes logs"}, backend.merges)
+	assert.Equal(t, []string{"logs", "products"}, backend.backups)
+	assert.Equal(t, "below threshold", run.Steps[2].Action)
+	require.NotNil(t, run.Retention)
+	assert.Equal(t, HealthGreen, run.After.Status)
+	assert.Contains(t, run.Summary(), "2 merges, 2 backups, 1 retention actions; health GREEN before, GREEN after")
+
+	// Goal: Every run is notified and kept in the history.
+	assert.Len(t, notified, 1)
+	assert.Len(t, m.History(), 1)
+
+	// Goal: Failed backups are counted, and optimize is used when configured.
+	backend.merges, backend.backupErr = nil, errors.New("no space left")
+	m.SetConfig(config.MaintenanceConfig{Collections: []string{"logs"}, Merge: config.MergeOptimize, Backup: config.MaintenanceBackupConfig{Location: "/backups"}})
+	run = m.Execute(context.Background(), "schedule")
+	assert.Equal(t, []string{"optimize logs"}, backend.merges)
+	assert.Equal(t, 1, run.Failed())
+	assert.Contains(t, run.Summary(), "1 failed")
+
+	// Goal: Nothing is done on a RED cluster.
+	backend.merges = nil
+	health = Health{Status: HealthRed, Findings: []string{"node1 has 2% of its disk free"}}
+	run = m.Execute(context.Background(), "schedule")
+	assert.Empty(t, backend.merges)
+	assert.Nil(t, run.After)
+	assert.Equal(t, "Maintenance skipped: the cluster is RED: node1 has 2% of its disk free", run.Summary())
+	assert.Len(t, notified, 3)
+}
+
+// TestWindow tests finding the occurrence of the window, including windows that span midnight.
+func TestWindow(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	require.NoError(t, err)
+	cfg := config.MaintenanceConfig{Window: "23:00-01:30", Timezone: "Asia/Tokyo"}
+	at := func(day, hour, minute int) time.Time { return time.Date(2025, 3, day, hour, minute, 0, 0, tokyo) }
+
+	// Goal: Times after midnight belong to the occurrence that started the day before.
+	start, end, ok := Window(cfg, at(11, 0, 45))
+	assert.True(t, ok)
+	assert.Equal(t, at(10, 23, 0), start)
+	assert.Equal(t, at(11, 1, 30), end)
+	_, _, ok = Window(cfg, at(11, 1, 30))
+	assert.False(t, ok)
+
+	// Goal: The job runs once per occurrence.
+	backend := &fakeBackend{}
+	m := NewManager(cfg, backend, func(context.Context) Health { return Health{Status: HealthGreen} }, nil, nil)
+	now := at(10, 22, 59)
+	m.now = func() time.Time { return now }
+	next, ok := m.NextWindow()
+	assert.True(t, ok)
+	assert.Equal(t, at(10, 23, 0), next)
+	assert.False(t, m.tick(context.Background()))
+	now = at(10, 23, 0)
+	assert.True(t, m.tick(context.Background()))
+	now = at(11, 0, 30)
+	assert.False(t, m.tick(context.Background()))
+	now = at(11, 23, 10)
+	assert.True(t, m.tick(context.Background()))
+	assert.Len(t, m.History(), 2)
+}
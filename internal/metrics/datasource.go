@@ -0,0 +1,211 @@
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/config"
+	"github.com/Sashimimochi/solr-mcp-go/internal/solr"
+)
+
+// SeriesFunc evaluates a saved query as a time series.
+type SeriesFunc func(ctx context.Context, q config.SavedQuery, from, to time.Time, gap time.Duration) ([]solr.SeriesPoint, error)
+
+// Datasource serves saved queries through the API expected by the Grafana JSON datasource plugin.
+//
+//	GET  /            connection test
+//	POST /metrics     list of saved queries ({label, value})
+//	POST /search      list of saved query names (legacy SimpleJSON API)
+//	POST /query       time series for the requested targets
+type Datasource struct {
+	mu       sync.RWMutex
+	queries  []config.SavedQuery
+	evaluate EvaluateFunc
+	series   SeriesFunc
+}
+
+// NewDatasource creates a Grafana JSON datasource for the given saved queries.
+func NewDatasource(queries []config.SavedQuery, evaluate EvaluateFunc, series SeriesFunc) *Datasource {
+	return &Datasource{
+		queries:  queries,
+		evaluate: evaluate,
+		series:   series,
+	}
+}
+
+// SetQueries replaces the saved queries, e.g. after a config reload.
+func (d *Datasource) SetQueries(queries []config.SavedQuery) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.queries = queries
+}
+
+func (d *Datasource) savedQueries() []config.SavedQuery {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.queries
+}
+
+// DatasourceQueryRequest is the body of a Grafana /query request.
+type DatasourceQueryRequest struct {
+	Range struct {
+		From time.Time `json:"from"`
+		To   time.Time `json:"to"`
+	} `json:"range"`
+	IntervalMs    int64              `json:"intervalMs"`
+	MaxDataPoints int                `json:"maxDataPoints"`
+	Targets       []DatasourceTarget `json:"targets"`
+}
+
+// DatasourceTarget is a single panel target of a Grafana /query request.
+type DatasourceTarget struct {
+	RefID  string `json:"refId"`
+	Target string `json:"target"`
+	Hide   bool   `json:"hide,omitempty"`
+}
+
+// DatasourceSeries is a single time series in a Grafana /query response.
+type DatasourceSeries struct {
+	Target     string       `json:"target"`
+	Datapoints [][2]float64 `json:"datapoints"`
+}
+
+// ServeHTTP routes requests relative to the datasource mount point.
+func (d *Datasource) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch strings.TrimSuffix(r.URL.Path, "/") {
+	case "":
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"status":"ok"}`))
+	case "/metrics":
+		d.handleMetrics(w, r)
+	case "/search":
+		d.handleSearch(w, r)
+	case "/query":
+		d.handleQuery(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (d *Datasource) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	queries := d.savedQueries()
+	out := make([]map[string]string, 0, len(queries))
+	for _, q := range queries {
+		out = append(out, map[string]string{"label": q.Name, "value": q.Name})
+	}
+	writeJSON(w, out)
+}
+
+func (d *Datasource) handleSearch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	queries := d.savedQueries()
+	names := make([]string, 0, len(queries))
+	for _, q := range queries {
+		names = append(names, q.Name)
+	}
+	writeJSON(w, names)
+}
+
+func (d *Datasource) handleQuery(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var req DatasourceQueryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, fmt.Sprintf("decode request: %v", err), http.StatusBadRequest)
+		return
+	}
+	if req.Range.To.IsZero() {
+		req.Range.To = time.Now()
+	}
+	if req.Range.From.IsZero() || !req.Range.From.Before(req.Range.To) {
+		req.Range.From = req.Range.To.Add(-time.Hour)
+	}
+
+	out := []DatasourceSeries{}
+	for _, t := range req.Targets {
+		if t.Hide || t.Target == "" {
+			continue
+		}
+		q, ok := d.lookup(t.Target)
+		if !ok {
+			http.Error(w, fmt.Sprintf("unknown saved query %q", t.Target), http.StatusBadRequest)
+			return
+		}
+		series, err := d.query(r.Context(), q, req)
+		if err != nil {
+			slog.Error("Datasource query failed", "target", t.Target, "error", err)
+			http.Error(w, fmt.Sprintf("query %s: %v", t.Target, err), http.StatusBadGateway)
+			return
+		}
+		out = append(out, series)
+	}
+	writeJSON(w, out)
+}
+
+func (d *Datasource) query(ctx context.Context, q config.SavedQuery, req DatasourceQueryRequest) (DatasourceSeries, error) {
+	series := DatasourceSeries{Target: q.Name, Datapoints: [][2]float64{}}
+
+	// Saved queries without a time field can only report their current value
+	if q.TimeField == "" {
+		v, err := d.evaluate(ctx, q)
+		if err != nil {
+			return series, err
+		}
+		series.Datapoints = append(series.Datapoints, [2]float64{v, float64(req.Range.To.UnixMilli())})
+		return series, nil
+	}
+
+	points, err := d.series(ctx, q, req.Range.From, req.Range.To, seriesGap(req))
+	if err != nil {
+		return series, err
+	}
+	for _, p := range points {
+		series.Datapoints = append(series.Datapoints, [2]float64{p.Value, float64(p.Time.UnixMilli())})
+	}
+	return series, nil
+}
+
+// seriesGap derives the bucket size from the panel interval, widened so that maxDataPoints is respected.
+func seriesGap(req DatasourceQueryRequest) time.Duration {
+	gap := time.Duration(req.IntervalMs) * time.Millisecond
+	if req.MaxDataPoints > 0 {
+		if minGap := req.Range.To.Sub(req.Range.From) / time.Duration(req.MaxDataPoints); gap < minGap {
+			gap = minGap
+		}
+	}
+	if gap < time.Second {
+		gap = time.Second
+	}
+	return gap.Round(time.Second)
+}
+
+func (d *Datasource) lookup(name string) (config.SavedQuery, bool) {
+	for _, q := range d.savedQueries() {
+		if q.Name == name {
+			return q, true
+		}
+	}
+	return config.SavedQuery{}, false
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		slog.Error("Failed to encode response", "error", err)
+	}
+}
@@ -0,0 +1,144 @@
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/config"
+	"github.com/Sashimimochi/solr-mcp-go/internal/solr"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestDatasource creates a Datasource with fixed evaluation results.
+func newTestDatasource() *Datasource {
+	queries := []config.SavedQuery{
+		{Name: "errors_now", Collection: "logs"},
+		{Name: "errors_series", Collection: "logs", TimeField: "timestamp"},
+		{Name: "broken", Collection: "logs"},
+	}
+	return NewDatasource(queries,
+		func(ctx context.Context, q config.SavedQuery) (float64, error) {
+			if q.Name == "broken" {
+				return 0, errors.New("boom")
+			}
+			return 5, nil
+		},
+		func(ctx context.Context, q config.SavedQuery, from, to time.Time, gap time.Duration) ([]solr.SeriesPoint, error) {
+			return []solr.SeriesPoint{
+				{Time: from, Value: 1},
+				{Time: from.Add(gap), Value: 2},
+			}, nil
+		})
+}
+
+// TestDatasourceConnectionTest tests that the root path answers Grafana's connection test.
+func TestDatasourceConnectionTest(t *testing.T) {
+	rr := httptest.NewRecorder()
+	newTestDatasource().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `{"status":"ok"}`, rr.Body.String())
+}
+
+// TestDatasourceMetricsAndSearch tests that saved queries are listed as selectable metrics.
+func TestDatasourceMetricsAndSearch(t *testing.T) {
+	ds := newTestDatasource()
+
+	rr := httptest.NewRecorder()
+	ds.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/metrics", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `[{"label":"errors_now","value":"errors_now"},{"label":"errors_series","value":"errors_series"},{"label":"broken","value":"broken"}]`, rr.Body.String())
+
+	rr = httptest.NewRecorder()
+	ds.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/search", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `["errors_now","errors_series","broken"]`, rr.Body.String())
+
+	rr = httptest.NewRecorder()
+	ds.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rr.Code)
+}
+
+// TestDatasourceQuery tests the /query endpoint.
+func TestDatasourceQuery(t *testing.T) {
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(time.Hour)
+	body := func(targets ...string) *bytes.Buffer {
+		req := map[string]any{
+			"range":      map[string]any{"from": from, "to": to},
+			"intervalMs": 60000,
+		}
+		var ts []map[string]any
+		for _, target := range targets {
+			ts = append(ts, map[string]any{"refId": "A", "target": target})
+		}
+		req["targets"] = ts
+		b, _ := json.Marshal(req)
+		return bytes.NewBuffer(b)
+	}
+
+	// Goal: Saved queries with a time field return bucketed datapoints,
+	// and those without return their current value at the end of the range.
+	t.Run("Success: series and single value", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		newTestDatasource().ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/query", body("errors_series", "errors_now")))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		var out []DatasourceSeries
+		assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &out))
+		assert.Len(t, out, 2)
+		assert.Equal(t, "errors_series", out[0].Target)
+		assert.Equal(t, [][2]float64{
+			{1, float64(from.UnixMilli())},
+			{2, float64(from.Add(time.Minute).UnixMilli())},
+		}, out[0].Datapoints)
+		assert.Equal(t, [][2]float64{{5, float64(to.UnixMilli())}}, out[1].Datapoints)
+	})
+
+	// Goal: Unknown targets are rejected.
+	t.Run("Error: unknown target", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		newTestDatasource().ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/query", body("nope")))
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	// Goal: Solr failures are reported as a bad gateway.
+	t.Run("Error: evaluation failure", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		newTestDatasource().ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/query", body("broken")))
+		assert.Equal(t, http.StatusBadGateway, rr.Code)
+	})
+
+	// Goal: Malformed bodies are rejected.
+	t.Run("Error: invalid body", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		newTestDatasource().ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/query", bytes.NewBufferString("{")))
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
+
+// TestSeriesGap tests bucket size calculation.
+func TestSeriesGap(t *testing.T) {
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	req := DatasourceQueryRequest{IntervalMs: 15000}
+	req.Range.From = from
+	req.Range.To = from.Add(time.Hour)
+
+	// Goal: The panel interval is used as is.
+	assert.Equal(t, 15*time.Second, seriesGap(req))
+
+	// Goal: The gap is widened to respect maxDataPoints.
+	req.MaxDataPoints = 60
+	assert.Equal(t, time.Minute, seriesGap(req))
+
+	// Goal: The gap is never smaller than one second.
+	req = DatasourceQueryRequest{IntervalMs: 10}
+	assert.Equal(t, time.Second, seriesGap(req))
+}
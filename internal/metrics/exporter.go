@@ -0,0 +1,168 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/config"
+)
+
+// EvaluateFunc evaluates a saved query and returns its current value.
+type EvaluateFunc func(ctx context.Context, q config.SavedQuery) (float64, error)
+
+type sample struct {
+	value float64
+	ok    bool
+	at    time.Time
+}
+
+// Exporter periodically evaluates saved queries and exposes them as Prometheus gauges.
+type Exporter struct {
+	mu       sync.RWMutex
+	queries  []config.SavedQuery
+	samples  map[string]sample
+	interval time.Duration
+	evaluate EvaluateFunc
+	sources  []func() string
+}
+
+// NewExporter creates an Exporter for the given saved queries.
+func NewExporter(queries []config.SavedQuery, interval time.Duration, evaluate EvaluateFunc) *Exporter {
+	return &Exporter{
+		queries:  queries,
+		samples:  make(map[string]sample),
+		interval: interval,
+		evaluate: evaluate,
+	}
+}
+
+// SetQueries replaces the saved queries, e.g. after a config reload.
+func (e *Exporter) SetQueries(queries []config.SavedQuery) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.queries = queries
+	names := map[string]bool{}
+	for _, q := range queries {
+		names[q.Name] = true
+	}
+	for name := range e.samples {
+		if !names[name] {
+			delete(e.samples, name)
+		}
+	}
+}
+
+// AddSource appends the output of render, which must be in the Prometheus text exposition format, to every scrape.
+func (e *Exporter) AddSource(render func() string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.sources = append(e.sources, render)
+}
+
+// Collect evaluates every saved query once and stores the results.
+func (e *Exporter) Collect(ctx context.Context) {
+	e.mu.RLock()
+	queries := e.queries
+	e.mu.RUnlock()
+
+	for _, q := range queries {
+		v, err := e.evaluate(ctx, q)
+		if err != nil {
+			slog.Warn("Failed to evaluate saved query", "query", q.Name, "error", err)
+		}
+		e.mu.Lock()
+		e.samples[q.Name] = sample{value: v, ok: err == nil, at: time.Now()}
+		e.mu.Unlock()
+	}
+}
+
+// Run collects immediately and then on every interval until ctx is done.
+func (e *Exporter) Run(ctx context.Context) {
+	e.Collect(ctx)
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			e.Collect(ctx)
+		}
+	}
+}
+
+// ServeHTTP writes the collected samples in the Prometheus text exposition format.
+func (e *Exporter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	fmt.Fprint(w, e.Render())
+}
+
+// Render returns the current samples in the Prometheus text exposition format.
+func (e *Exporter) Render() string {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	var b strings.Builder
+	b.WriteString("# HELP solr_mcp_saved_query_up Whether the last evaluation of the saved query succeeded.\n")
+	b.WriteString("# TYPE solr_mcp_saved_query_up gauge\n")
+	for _, q := range e.queries {
+		s, seen := e.samples[q.Name]
+		if !seen {
+			continue
+		}
+		up := 0
+		if s.ok {
+			up = 1
+		}
+		fmt.Fprintf(&b, "solr_mcp_saved_query_up{query=\"%s\"} %d\n", q.Name, up)
+	}
+
+	for _, q := range e.queries {
+		s, seen := e.samples[q.Name]
+		if !seen || !s.ok {
+			continue
+		}
+		help := q.Description
+		if help == "" {
+			help = fmt.Sprintf("Saved query %s on collection %s.", q.Name, q.Collection)
+		}
+		fmt.Fprintf(&b, "# HELP %s %s\n", q.Name, escapeHelp(help))
+		fmt.Fprintf(&b, "# TYPE %s gauge\n", q.Name)
+		fmt.Fprintf(&b, "%s%s %g %d\n", q.Name, formatLabels(q.Labels), s.value, s.at.UnixMilli())
+	}
+	for _, render := range e.sources {
+		b.WriteString(render())
+	}
+	return b.String()
+}
+
+func formatLabels(labels map[string]string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		parts = append(parts, fmt.Sprintf("%s=\"%s\"", k, escapeLabelValue(labels[k])))
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+func escapeLabelValue(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`).Replace(s)
+}
+
+func escapeHelp(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(s)
+}
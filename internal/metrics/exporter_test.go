@@ -0,0 +1,118 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/config"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestExporterRender tests that collected samples are rendered in the Prometheus text format.
+func TestExporterRender(t *testing.T) {
+	queries := []config.SavedQuery{
+		{Name: "solr_errors_last_5m", Collection: "logs", Labels: map[string]string{"service": "api", "env": "prod"}},
+		{Name: "solr_bytes_total", Collection: "logs", Description: "Total bytes"},
+		{Name: "solr_broken", Collection: "logs"},
+	}
+	values := map[string]float64{"solr_errors_last_5m": 42, "solr_bytes_total": 1.5e6}
+	e := NewExporter(queries, time.Minute, func(ctx context.Context, q config.SavedQuery) (float64, error) {
+		if v, ok := values[q.Name]; ok {
+			return v, nil
+		}
+		return 0, errors.New("boom")
+	})
+
+	e.Collect(context.Background())
+	out := e.Render()
+
+	// Labels are sorted and the value is exposed as a gauge
+	assert.Contains(t, out, "# TYPE solr_errors_last_5m gauge")
+	assert.Contains(t, out, `solr_errors_last_5m{env="prod",service="api"} 42 `)
+	// Description is used as HELP text
+	assert.Contains(t, out, "# HELP solr_bytes_total Total bytes")
+	assert.Contains(t, out, "solr_bytes_total 1.5e+06 ")
+	// Failed queries are reported via the up gauge and have no sample
+	assert.Contains(t, out, `solr_mcp_saved_query_up{query="solr_broken"} 0`)
+	assert.Contains(t, out, `solr_mcp_saved_query_up{query="solr_errors_last_5m"} 1`)
+	assert.NotContains(t, out, "# TYPE solr_broken gauge")
+}
+
+// TestExporterRenderBeforeCollect tests that nothing but the header is rendered before the first collection.
+func TestExporterRenderBeforeCollect(t *testing.T) {
+	e := NewExporter([]config.SavedQuery{{Name: "q", Collection: "c"}}, time.Minute, nil)
+
+	out := e.Render()
+
+	assert.NotContains(t, out, "q{")
+	assert.NotContains(t, out, `query="q"`)
+}
+
+// TestExporterServeHTTP tests the /metrics handler.
+func TestExporterServeHTTP(t *testing.T) {
+	e := NewExporter([]config.SavedQuery{{Name: "q", Collection: "c"}}, time.Minute,
+		func(ctx context.Context, q config.SavedQuery) (float64, error) { return 7, nil })
+	e.Collect(context.Background())
+
+	rr := httptest.NewRecorder()
+	e.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.True(t, strings.HasPrefix(rr.Header().Get("Content-Type"), "text/plain"))
+	assert.Contains(t, rr.Body.String(), "q 7 ")
+}
+
+// TestExporterRun tests that Run collects immediately and stops when the context is cancelled.
+func TestExporterRun(t *testing.T) {
+	calls := make(chan struct{}, 10)
+	e := NewExporter([]config.SavedQuery{{Name: "q", Collection: "c"}}, time.Hour,
+		func(ctx context.Context, q config.SavedQuery) (float64, error) {
+			calls <- struct{}{}
+			return 1, nil
+		})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		e.Run(ctx)
+		close(done)
+	}()
+
+	select {
+	case <-calls:
+	case <-time.After(time.Second):
+		t.Fatal("expected an immediate collection")
+	}
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Run did not stop after cancel")
+	}
+}
+
+// TestFormatLabels tests label escaping.
+func TestFormatLabels(t *testing.T) {
+	assert.Equal(t, "", formatLabels(nil))
+	assert.Equal(t, `{a="x\"y",b="line\nbreak"}`, formatLabels(map[string]string{"b": "line\nbreak", "a": `x"y`}))
+}
+
+// TestExporterSetQueries tests that replaced saved queries drop stale samples.
+func TestExporterSetQueries(t *testing.T) {
+	e := NewExporter([]config.SavedQuery{{Name: "old", Collection: "c"}}, time.Minute,
+		func(ctx context.Context, q config.SavedQuery) (float64, error) { return 1, nil })
+	e.Collect(context.Background())
+
+	e.SetQueries([]config.SavedQuery{{Name: "new", Collection: "c"}})
+	e.Collect(context.Background())
+	out := e.Render()
+
+	assert.Contains(t, out, "new 1 ")
+	assert.NotContains(t, out, "old")
+}
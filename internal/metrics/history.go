@@ -0,0 +1,500 @@
+package metrics
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/config"
+)
+
+// Sample is a value of a series read from the Prometheus text exposition format.
+type Sample struct {
+	Name   string
+	Labels map[string]string
+	Value  float64
+}
+
+// MetricInfo is the HELP and TYPE of a metric family.
+type MetricInfo struct {
+	Name   string `json:"name"`
+	Type   string `json:"type,omitempty"` // counter, gauge, histogram, summary or untyped
+	Help   string `json:"help,omitempty"`
+	Series int    `json:"series"`
+}
+
+// ParseText reads the Prometheus text exposition format, as served by the solr-exporter.
+// Timestamps of samples are ignored: the scrape time is used instead.
+func ParseText(r io.Reader) ([]Sample, map[string]MetricInfo, error) {
+	var samples []Sample
+	info := map[string]MetricInfo{}
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "#") {
+			fields := strings.SplitN(strings.TrimSpace(line[1:]), " ", 3)
+			if len(fields) < 3 || (fields[0] != "HELP" && fields[0] != "TYPE") {
+				continue
+			}
+			mi := info[fields[1]]
+			mi.Name = fields[1]
+			if fields[0] == "HELP" {
+				mi.Help = unescape(fields[2], false)
+			} else {
+				mi.Type = fields[2]
+			}
+			info[fields[1]] = mi
+			continue
+		}
+		s, err := parseSample(line)
+		if err != nil {
+			return nil, nil, fmt.Errorf("line %d: %v", n, err)
+		}
+		samples = append(samples, s)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, nil, err
+	}
+	return samples, info, nil
+}
+
+// parseSample parses `name{label="value",...} value [timestamp]`.
+func parseSample(line string) (Sample, error) {
+	end := strings.IndexAny(line, "{ \t")
+	if end <= 0 {
+		return Sample{}, fmt.Errorf("invalid sample %q", line)
+	}
+	s := Sample{Name: line[:end], Labels: map[string]string{}}
+	rest := line[end:]
+	if strings.HasPrefix(rest, "{") {
+		i := 1
+		for {
+			for i < len(rest) && (rest[i] == ' ' || rest[i] == ',') {
+				i++
+			}
+			if i >= len(rest) {
+				return Sample{}, fmt.Errorf("unterminated labels in %q", line)
+			}
+			if rest[i] == '}' {
+				i++
+				break
+			}
+			eq := strings.Index(rest[i:], "=\"")
+			if eq <= 0 {
+				return Sample{}, fmt.Errorf("invalid labels in %q", line)
+			}
+			name := strings.TrimSpace(rest[i : i+eq])
+			i += eq + 2
+			start := i
+			for ; i < len(rest) && rest[i] != '"'; i++ {
+				if rest[i] == '\\' {
+					i++ // skip the escaped character
+				}
+			}
+			if i >= len(rest) {
+				return Sample{}, fmt.Errorf("unterminated label value in %q", line)
+			}
+			s.Labels[name] = unescape(rest[start:i], true)
+			i++
+		}
+		rest = rest[i:]
+	}
+	fields := strings.Fields(rest)
+	if len(fields) == 0 || len(fields) > 2 {
+		return Sample{}, fmt.Errorf("invalid sample %q", line)
+	}
+	v, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return Sample{}, fmt.Errorf("invalid value in %q", line)
+	}
+	s.Value = v
+	return s, nil
+}
+
+// unescape resolves the escapes of HELP texts (\\ and \n) and label values (also \").
+func unescape(s string, quotes bool) string {
+	if !strings.Contains(s, "\\") {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			switch s[i+1] {
+			case '\\':
+				b.WriteByte('\\')
+				i++
+				continue
+			case 'n':
+				b.WriteByte('\n')
+				i++
+				continue
+			case '"':
+				if quotes {
+					b.WriteByte('"')
+					i++
+					continue
+				}
+			}
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// Scrape reads the samples of a Prometheus text endpoint.
+func Scrape(ctx context.Context, client *http.Client, url string) ([]Sample, map[string]MetricInfo, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	req.Header.Set("Accept", "text/plain; version=0.0.4")
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("scrape %s: %v", config.RedactURL(url), err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, nil, fmt.Errorf("scrape %s: HTTP status %d", config.RedactURL(url), res.StatusCode)
+	}
+	return ParseText(res.Body)
+}
+
+// Point is a value of a series at a scrape.
+type Point struct {
+	Time  time.Time `json:"t"`
+	Value float64   `json:"v"`
+}
+
+type series struct {
+	name   string
+	labels map[string]string
+	points []Point
+}
+
+// History keeps the scraped samples of each series for a retention period. It is safe for concurrent use.
+type History struct {
+	mu        sync.RWMutex
+	series    map[string]*series
+	info      map[string]MetricInfo
+	retention time.Duration
+	maxSeries int
+	keep      []*regexp.Regexp
+	dropped   int
+	scrapes   int
+	last      time.Time
+	lastErr   string
+}
+
+// NewHistory creates an empty History with the settings of sc.
+func NewHistory(sc config.SolrExporterConfig) *History {
+	h := &History{series: map[string]*series{}, info: map[string]MetricInfo{}}
+	h.SetConfig(sc)
+	return h
+}
+
+// SetConfig applies changed retention, series limit and metric filters. Series no longer matching are removed.
+func (h *History) SetConfig(sc config.SolrExporterConfig) {
+	keep := make([]*regexp.Regexp, 0, len(sc.Metrics))
+	for _, m := range sc.Metrics {
+		if re, err := regexp.Compile("^(?:" + m + ")$"); err == nil {
+			keep = append(keep, re)
+		}
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.retention, h.maxSeries, h.keep = sc.RetentionDuration(), sc.SeriesLimit(), keep
+	for key, s := range h.series {
+		if !h.kept(s.name) {
+			delete(h.series, key)
+		}
+	}
+}
+
+// kept reports whether the metric name passes the filters. The caller holds h.mu.
+func (h *History) kept(name string) bool {
+	if len(h.keep) == 0 {
+		return true
+	}
+	for _, re := range h.keep {
+		if re.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// Add records the finite samples of a scrape at time at and drops points older than the retention.
+func (h *History) Add(at time.Time, samples []Sample, info map[string]MetricInfo) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.scrapes++
+	h.last, h.lastErr = at, ""
+	for name, mi := range info {
+		if h.kept(name) {
+			h.info[name] = mi
+		}
+	}
+	for _, smp := range samples {
+		// NaN and infinite values cannot be returned as JSON, e.g. ratios of caches without lookups
+		if !h.kept(smp.Name) || math.IsNaN(smp.Value) || math.IsInf(smp.Value, 0) {
+			continue
+		}
+		key := seriesKey(smp.Name, smp.Labels)
+		s, ok := h.series[key]
+		if !ok {
+			if len(h.series) >= h.maxSeries {
+				h.dropped++
+				continue
+			}
+			s = &series{name: smp.Name, labels: smp.Labels}
+			h.series[key] = s
+		}
+		s.points = append(s.points, Point{Time: at, Value: smp.Value})
+	}
+	cutoff := at.Add(-h.retention)
+	for key, s := range h.series {
+		i := sort.Search(len(s.points), func(i int) bool { return !s.points[i].Time.Before(cutoff) })
+		if i == len(s.points) {
+			delete(h.series, key)
+			continue
+		}
+		s.points = s.points[i:]
+	}
+}
+
+// Failed records a failed scrape.
+func (h *History) Failed(err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.lastErr = err.Error()
+}
+
+// Status describes the scrapes of a History.
+type Status struct {
+	Scrapes        int       `json:"scrapes"`
+	LastScrape     time.Time `json:"lastScrape,omitzero"`
+	LastError      string    `json:"lastError,omitempty"`
+	Series         int       `json:"series"`
+	DroppedSamples int       `json:"droppedSamples,omitempty"` // samples of series over maxSeries
+	Retention      string    `json:"retention"`
+}
+
+// Status returns the scrape counters.
+func (h *History) Status() Status {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return Status{Scrapes: h.scrapes, LastScrape: h.last, LastError: h.lastErr, Series: len(h.series), DroppedSamples: h.dropped, Retention: h.retention.String()}
+}
+
+// Metrics lists the recorded metric families with their series counts, sorted by name.
+func (h *History) Metrics() []MetricInfo {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	counts := map[string]int{}
+	for _, s := range h.series {
+		counts[s.name]++
+	}
+	out := make([]MetricInfo, 0, len(counts))
+	for name, n := range counts {
+		mi := h.family(name)
+		mi.Name, mi.Series = name, n
+		out = append(out, mi)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// family returns the HELP and TYPE of a series name, which may carry a histogram or summary suffix.
+// The caller holds h.mu.
+func (h *History) family(name string) MetricInfo {
+	if mi, ok := h.info[name]; ok {
+		return mi
+	}
+	for _, suffix := range []string{"_bucket", "_sum", "_count"} {
+		if mi, ok := h.info[strings.TrimSuffix(name, suffix)]; ok && strings.HasSuffix(name, suffix) {
+			return mi
+		}
+	}
+	return MetricInfo{}
+}
+
+// Query selects series of a History.
+type Query struct {
+	Metric    *regexp.Regexp    // matches the whole metric name
+	Labels    map[string]string // exact label values
+	Since     time.Time
+	MaxPoints int // points returned per series at most, evenly spaced (0: all)
+}
+
+// Series is a recorded series with a summary of its points in the queried window.
+type Series struct {
+	Metric string            `json:"metric"`
+	Type   string            `json:"type,omitempty"`
+	Labels map[string]string `json:"labels,omitempty"`
+	Points []Point           `json:"points"`
+	Summary
+}
+
+// Summary describes the values of a series in a window.
+type Summary struct {
+	Samples int     `json:"samples"`
+	Min     float64 `json:"min"`
+	Max     float64 `json:"max"`
+	Avg     float64 `json:"avg"`
+	First   float64 `json:"first"`
+	Last    float64 `json:"last"`
+	Change  float64 `json:"change"`          // Last - First
+	Trend   string  `json:"trend,omitempty"` // rising, falling or flat
+	// RatePerSecond is the average increase of a counter, ignoring resets.
+	RatePerSecond *float64 `json:"ratePerSecond,omitempty"`
+}
+
+// flatChange is the relative change under which a series is flat.
+const flatChange = 0.01
+
+// Query returns the series matching q, sorted by metric and labels.
+func (h *History) Query(q Query) []Series {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	var out []Series
+	for _, s := range h.series {
+		if q.Metric != nil && !q.Metric.MatchString(s.name) {
+			continue
+		}
+		if !matchLabels(s.labels, q.Labels) {
+			continue
+		}
+		i := sort.Search(len(s.points), func(i int) bool { return !s.points[i].Time.Before(q.Since) })
+		points := s.points[i:]
+		if len(points) == 0 {
+			continue
+		}
+		typ := h.family(s.name).Type
+		out = append(out, Series{Metric: s.name, Type: typ, Labels: s.labels, Points: downsample(points, q.MaxPoints), Summary: summarize(points, typ == "counter")})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return seriesKey(out[i].Metric, out[i].Labels) < seriesKey(out[j].Metric, out[j].Labels)
+	})
+	return out
+}
+
+func matchLabels(labels, want map[string]string) bool {
+	for k, v := range want {
+		if labels[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// summarize computes the summary of points.
+func summarize(points []Point, counter bool) Summary {
+	var sm Summary
+	var sum, increase float64
+	var first, last *Point
+	for i := range points {
+		p := &points[i]
+		if first == nil {
+			first, sm.Min, sm.Max = p, p.Value, p.Value
+		} else {
+			sm.Min, sm.Max = min(sm.Min, p.Value), max(sm.Max, p.Value)
+			if d := p.Value - last.Value; d >= 0 {
+				increase += d
+			} else {
+				increase += p.Value // counter reset
+			}
+		}
+		last = p
+		sum += p.Value
+		sm.Samples++
+	}
+	if sm.Samples == 0 {
+		return sm
+	}
+	sm.Avg = sum / float64(sm.Samples)
+	sm.First, sm.Last = first.Value, last.Value
+	sm.Change = sm.Last - sm.First
+	if sm.Samples > 1 {
+		switch scale := max(math.Abs(sm.First), math.Abs(sm.Last)); {
+		case math.Abs(sm.Change) <= flatChange*scale || sm.Change == 0:
+			sm.Trend = "flat"
+		case sm.Change > 0:
+			sm.Trend = "rising"
+		default:
+			sm.Trend = "falling"
+		}
+		if secs := last.Time.Sub(first.Time).Seconds(); counter && secs > 0 {
+			rate := increase / secs
+			sm.RatePerSecond = &rate
+		}
+	}
+	return sm
+}
+
+// downsample returns at most n evenly spaced points, always keeping the first and the last.
+func downsample(points []Point, n int) []Point {
+	if n <= 0 || len(points) <= n {
+		return points
+	}
+	if n == 1 {
+		return points[len(points)-1:]
+	}
+	out := make([]Point, n)
+	for i := range out {
+		out[i] = points[i*(len(points)-1)/(n-1)]
+	}
+	return out
+}
+
+func seriesKey(name string, labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	b.WriteString(name)
+	for _, k := range keys {
+		fmt.Fprintf(&b, "\x00%s=%s", k, labels[k])
+	}
+	return b.String()
+}
+
+// RunScraper scrapes the endpoint of the config returned by cfg into h on every interval until ctx is done.
+// The URL and filters are read from cfg on each scrape, so config reloads apply.
+func RunScraper(ctx context.Context, client *http.Client, h *History, interval time.Duration, cfg func() config.SolrExporterConfig) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		sc := cfg()
+		if sc.URL != "" {
+			h.SetConfig(sc)
+			samples, info, err := Scrape(ctx, client, sc.URL)
+			if err != nil {
+				slog.Warn("Failed to scrape the solr-exporter", "error", err)
+				h.Failed(err)
+			} else {
+				h.Add(time.Now(), samples, info)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
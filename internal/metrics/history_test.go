@@ -0,0 +1,87 @@
+package metrics
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const exporterText = `# HELP solr_metrics_core_searcher_cache_ratio Cache hit ratio
+# TYPE solr_metrics_core_searcher_cache_ratio gauge
+solr_metrics_core_searcher_cache_ratio{core="products_shard1_replica_n1",cache="queryResultCache",item="hitratio",} 0.8
+solr_metrics_core_searcher_cache_ratio{core="products_shard1_replica_n1",cache="filterCache",item="hitratio",} NaN
+# TYPE solr_metrics_core_requests_total counter
+solr_metrics_core_requests_total{core="products_shard1_replica_n1",handler="/select"} 100 1760000000000
+solr_metrics_jvm_gc{label="quoted \"value\" \\ path"} 3
+`
+
+// TestParseText tests reading the Prometheus text format of the solr-exporter.
+func TestParseText(t *testing.T) {
+	samples, info, err := ParseText(strings.NewReader(exporterText))
+	require.NoError(t, err)
+	require.Len(t, samples, 4)
+	assert.Equal(t, Sample{
+		Name:   "solr_metrics_core_searcher_cache_ratio",
+		Labels: map[string]string{"core": "products_shard1_replica_n1", "cache": "queryResultCache", "item": "hitratio"},
+		Value:  0.8,
+	}, samples[0])
+	assert.Equal(t, 100.0, samples[2].Value)
+	assert.Equal(t, `quoted "value" \ path`, samples[3].Labels["label"])
+	assert.Equal(t, MetricInfo{Name: "solr_metrics_core_searcher_cache_ratio", Type: "gauge", Help: "Cache hit ratio"}, info["solr_metrics_core_searcher_cache_ratio"])
+	assert.Equal(t, "counter", info["solr_metrics_core_requests_total"].Type)
+
+	_, _, err = ParseText(strings.NewReader("solr_up{core=\"a\" 1\n"))
+	assert.ErrorContains(t, err, "line 1")
+}
+
+// TestHistory tests recording, filtering and summarizing scraped series.
+func TestHistory(t *testing.T) {
+	h := NewHistory(config.SolrExporterConfig{Retention: "1h", Metrics: []string{"solr_metrics_core_.*"}})
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	ratio := []float64{0.8, 0.7, 0.6, 0.5}
+	requests := []float64{100, 160, 20, 80} // restarted after the second scrape
+	for i := range ratio {
+		text := strings.NewReplacer("} 0.8", "} "+strconv.FormatFloat(ratio[i], 'g', -1, 64), "} 100 ", "} "+strconv.FormatFloat(requests[i], 'g', -1, 64)+" ").Replace(exporterText)
+		samples, info, err := ParseText(strings.NewReader(text))
+		require.NoError(t, err)
+		h.Add(start.Add(time.Duration(i)*10*time.Minute), samples, info)
+	}
+
+	// Goal: Only matching metrics with finite values are kept.
+	metrics := h.Metrics()
+	require.Len(t, metrics, 2)
+	assert.Equal(t, MetricInfo{Name: "solr_metrics_core_requests_total", Type: "counter", Series: 1}, metrics[0])
+	assert.Equal(t, 1, metrics[1].Series)
+
+	// Goal: Series are summarized with their trend, and counters with their rate across resets.
+	series := h.Query(Query{Metric: regexp.MustCompile("^solr_metrics_core_.*$"), Labels: map[string]string{"core": "products_shard1_replica_n1"}})
+	require.Len(t, series, 2)
+	assert.Equal(t, "solr_metrics_core_requests_total", series[0].Metric)
+	assert.InDelta(t, (60.0+20+60)/1800, *series[0].RatePerSecond, 1e-9)
+	cache := series[1]
+	assert.Equal(t, "falling", cache.Trend)
+	assert.InDelta(t, -0.3, cache.Change, 1e-9)
+	assert.InDelta(t, 0.65, cache.Avg, 1e-9)
+	assert.Equal(t, 0.5, cache.Min)
+	assert.Nil(t, cache.RatePerSecond)
+
+	// Goal: Windows, label filters and downsampling select points.
+	series = h.Query(Query{Labels: map[string]string{"cache": "queryResultCache"}, Since: start.Add(15 * time.Minute)})
+	require.Len(t, series, 1)
+	assert.Equal(t, 2, series[0].Samples)
+	series = h.Query(Query{Labels: map[string]string{"cache": "queryResultCache"}, MaxPoints: 2})
+	assert.Equal(t, []Point{{start, 0.8}, {start.Add(30 * time.Minute), 0.5}}, series[0].Points)
+
+	// Goal: Points older than the retention are dropped.
+	h.Add(start.Add(65*time.Minute), nil, nil)
+	series = h.Query(Query{Labels: map[string]string{"cache": "queryResultCache"}})
+	assert.Equal(t, 3, series[0].Samples)
+	assert.Equal(t, 5, h.Status().Scrapes)
+}
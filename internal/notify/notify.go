@@ -0,0 +1,72 @@
+// Package notify delivers alerts of background jobs, such as detected schema drift, to a webhook.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/config"
+)
+
+// Event is an alert sent as the JSON body of a webhook request.
+type Event struct {
+	Type    string    `json:"type"` // e.g. "schema_drift"
+	Time    time.Time `json:"time"`
+	Summary string    `json:"summary"`
+	// Text repeats Summary for chat webhooks (Slack, Mattermost) that display a "text" field.
+	Text    string `json:"text"`
+	Details any    `json:"details,omitempty"`
+}
+
+// Notifier sends events to the configured webhook. Events are always logged, so a Notifier
+// without a webhook URL only logs.
+type Notifier struct {
+	cfg    config.NotifierConfig
+	client *http.Client
+}
+
+// New creates a Notifier for cfg.
+func New(cfg config.NotifierConfig) *Notifier {
+	return &Notifier{cfg: cfg, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// Send logs ev and posts it to the webhook, if one is configured.
+func (n *Notifier) Send(ctx context.Context, ev Event) error {
+	if ev.Time.IsZero() {
+		ev.Time = time.Now().UTC()
+	}
+	ev.Text = ev.Summary
+	slog.Warn("Notification", "type", ev.Type, "summary", ev.Summary)
+	if n == nil || n.cfg.WebhookURL == "" {
+		return nil
+	}
+
+	body, err := json.Marshal(ev)
+	if err != nil {
+		return fmt.Errorf("encode notification: %v", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.cfg.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("create notification request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range n.cfg.Headers {
+		req.Header.Set(k, v)
+	}
+	res, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("send notification to %s: %v", config.RedactURL(n.cfg.WebhookURL), err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(res.Body, 512))
+		return fmt.Errorf("notification webhook returned HTTP status %d: %s", res.StatusCode, msg)
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSend tests delivering events to the webhook.
+func TestSend(t *testing.T) {
+	var got Event
+	var auth string
+	status := http.StatusOK
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(srv.Close)
+	n := New(config.NotifierConfig{WebhookURL: srv.URL, Headers: map[string]string{"Authorization": "Bearer x"}})
+	ctx := context.Background()
+
+	// Goal: The event is posted as JSON with the configured headers and the summary as text.
+	require.NoError(t, n.Send(ctx, Event{Type: "schema_drift", Summary: "changed"}))
+	assert.Equal(t, "Bearer x", auth)
+	assert.Equal(t, "schema_drift", got.Type)
+	assert.Equal(t, "changed", got.Text)
+	assert.False(t, got.Time.IsZero())
+
+	// Goal: A failing webhook is an error.
+	status = http.StatusInternalServerError
+	assert.ErrorContains(t, n.Send(ctx, Event{Type: "schema_drift"}), "HTTP status 500")
+
+	// Goal: Without a webhook, events are only logged.
+	assert.NoError(t, New(config.NotifierConfig{}).Send(ctx, Event{Type: "schema_drift"}))
+}
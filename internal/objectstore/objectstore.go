@@ -0,0 +1,228 @@
+// Package objectstore uploads files to object storage, so large exports and results are handed over as a URL
+// instead of passing through the MCP channel, and reads objects to import. It speaks the REST APIs of Amazon S3 (and S3-compatible stores),
+// Google Cloud Storage (XML API with HMAC keys, which accepts S3 signatures) and Azure Blob Storage directly.
+package objectstore
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/config"
+)
+
+// azureVersion is the Blob service API version sent to Azure. It allows block blobs of up to 5000 MiB in one request.
+const azureVersion = "2021-08-06"
+
+// emptySHA256 is the hex SHA-256 of an empty request body.
+const emptySHA256 = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+// ParseURI splits an s3://bucket/key or gs://bucket/key URI into the store type, bucket and key.
+func ParseURI(uri string) (storeType, bucket, key string, err error) {
+	scheme, rest, ok := strings.Cut(uri, "://")
+	switch {
+	case ok && scheme == "s3":
+		storeType = config.ObjectStoreS3
+	case ok && scheme == "gs":
+		storeType = config.ObjectStoreGCS
+	default:
+		return "", "", "", fmt.Errorf("%q is not an s3:// or gs:// URI", uri)
+	}
+	bucket, key, _ = strings.Cut(rest, "/")
+	if bucket == "" || key == "" {
+		return "", "", "", fmt.Errorf("%q has no bucket or object key", uri)
+	}
+	return storeType, bucket, key, nil
+}
+
+// Object describes an uploaded object.
+type Object struct {
+	Store  string `json:"store"`
+	URL    string `json:"url"`
+	Key    string `json:"key"`
+	Bytes  int64  `json:"bytes"`
+	SHA256 string `json:"sha256"` // hex checksum of the content
+}
+
+// Store uploads files to one configured destination.
+type Store struct {
+	Name   string
+	Config config.ObjectStoreConfig
+	Client *http.Client
+	// Secret reads a credential variable (default: config.GetSecret).
+	Secret func(ctx context.Context, key string) (string, error)
+}
+
+// Upload puts the file at path as the object Prefix + key with the content type, in a single request.
+func (s *Store) Upload(ctx context.Context, key, path, contentType string) (Object, error) {
+	size, sum, err := checksum(path)
+	if err != nil {
+		return Object{}, err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return Object{}, err
+	}
+	defer f.Close()
+
+	key = s.Config.Prefix + key
+	objectURL := s.objectURL(key)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, objectURL, f)
+	if err != nil {
+		return Object{}, fmt.Errorf("create upload request: %v", err)
+	}
+	req.ContentLength = size
+	req.Header.Set("Content-Type", contentType)
+	if s.Config.Type == config.ObjectStoreAzure {
+		req.Header.Set("X-Ms-Blob-Type", "BlockBlob")
+	}
+	if err := s.authorize(ctx, req, sum); err != nil {
+		return Object{}, err
+	}
+
+	slog.Info("PUT", "url", config.RedactURL(objectURL), "bytes", size)
+	res, err := s.client().Do(req)
+	if err != nil {
+		return Object{}, fmt.Errorf("upload to %s: %v", s.Name, err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(res.Body, 4096))
+		return Object{}, fmt.Errorf("upload to %s: HTTP status %d: %s", s.Name, res.StatusCode, strings.TrimSpace(string(body)))
+	}
+	_, _ = io.Copy(io.Discard, res.Body)
+	return Object{Store: s.Name, URL: objectURL, Key: key, Bytes: size, SHA256: sum}, nil
+}
+
+// Open reads the object key, as stored (without Prefix), from byte offset on. At or past the end of the object,
+// it returns an empty reader. The caller closes the reader.
+func (s *Store) Open(ctx context.Context, key string, offset int64) (io.ReadCloser, error) {
+	objectURL := s.objectURL(key)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, objectURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create download request: %v", err)
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+	if err := s.authorize(ctx, req, emptySHA256); err != nil {
+		return nil, err
+	}
+	slog.Debug("GET", "url", config.RedactURL(objectURL), "offset", offset)
+	res, err := s.client().Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("download from %s: %v", s.Name, err)
+	}
+	switch {
+	case res.StatusCode == http.StatusRequestedRangeNotSatisfiable:
+		res.Body.Close()
+		return io.NopCloser(strings.NewReader("")), nil
+	case res.StatusCode == http.StatusOK && offset > 0:
+		// The store ignored the range: skip to the offset
+		if _, err := io.CopyN(io.Discard, res.Body, offset); err != nil && err != io.EOF {
+			res.Body.Close()
+			return nil, fmt.Errorf("download from %s: %v", s.Name, err)
+		}
+	case res.StatusCode < 200 || res.StatusCode >= 300:
+		defer res.Body.Close()
+		body, _ := io.ReadAll(io.LimitReader(res.Body, 4096))
+		return nil, fmt.Errorf("download from %s: HTTP status %d: %s", s.Name, res.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return res.Body, nil
+}
+
+func (s *Store) client() *http.Client {
+	if s.Client == nil {
+		return http.DefaultClient
+	}
+	return s.Client
+}
+
+// objectURL returns the URL of key, without credentials.
+func (s *Store) objectURL(key string) string {
+	c := s.Config
+	escaped := (&url.URL{Path: key}).EscapedPath()
+	base := strings.TrimRight(c.Endpoint, "/")
+	switch c.Type {
+	case config.ObjectStoreAzure:
+		if base == "" {
+			base = "https://" + c.Account + ".blob.core.windows.net"
+		}
+	case config.ObjectStoreGCS:
+		if base == "" {
+			base = "https://storage.googleapis.com"
+		}
+	default:
+		if base == "" {
+			// virtual-hosted style, the only one new AWS buckets support
+			return "https://" + c.Bucket + ".s3." + c.Region + ".amazonaws.com/" + escaped
+		}
+	}
+	return base + "/" + url.PathEscape(c.Bucket) + "/" + escaped
+}
+
+// authorize adds the credentials of the store to req.
+func (s *Store) authorize(ctx context.Context, req *http.Request, sum string) error {
+	secret := s.Secret
+	if secret == nil {
+		secret = config.GetSecret
+	}
+	prefix := s.Config.CredentialsPrefix()
+	if s.Config.Type == config.ObjectStoreAzure {
+		sas, err := secret(ctx, prefix+"_SAS_TOKEN")
+		if err != nil {
+			return err
+		}
+		if sas == "" {
+			return fmt.Errorf("object store %s: %s_SAS_TOKEN is not set", s.Name, prefix)
+		}
+		req.URL.RawQuery = strings.TrimPrefix(sas, "?")
+		req.Header.Set("X-Ms-Version", azureVersion)
+		return nil
+	}
+
+	var creds [3]string
+	for i, name := range []string{"_ACCESS_KEY_ID", "_SECRET_ACCESS_KEY", "_SESSION_TOKEN"} {
+		v, err := secret(ctx, prefix+name)
+		if err != nil {
+			return err
+		}
+		creds[i] = v
+	}
+	if creds[0] == "" || creds[1] == "" {
+		return fmt.Errorf("object store %s: %s_ACCESS_KEY_ID and %s_SECRET_ACCESS_KEY are required", s.Name, prefix, prefix)
+	}
+	region := s.Config.Region
+	if region == "" {
+		region = "auto"
+	}
+	req.Header.Set("X-Amz-Content-Sha256", sum)
+	if creds[2] != "" {
+		req.Header.Set("X-Amz-Security-Token", creds[2])
+	}
+	config.SignAWSv4(req, sum, creds[0], creds[1], region, "s3", time.Now())
+	return nil
+}
+
+// checksum returns the size and hex SHA-256 of the file at path.
+func checksum(path string) (int64, string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	n, err := io.Copy(h, f)
+	if err != nil {
+		return 0, "", fmt.Errorf("read %s: %v", path, err)
+	}
+	return n, hex.EncodeToString(h.Sum(nil)), nil
+}
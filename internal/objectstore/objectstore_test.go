@@ -0,0 +1,208 @@
+package objectstore
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// secrets returns a Secret function reading from vars.
+func secrets(vars map[string]string) func(context.Context, string) (string, error) {
+	return func(_ context.Context, key string) (string, error) { return vars[key], nil }
+}
+
+// TestUpload tests uploading a file to S3-compatible and Azure stores.
+func TestUpload(t *testing.T) {
+	var got *http.Request
+	var body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		got, body = r, string(data)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+	path := filepath.Join(t.TempDir(), "export.jsonl")
+	content := []byte("{\"id\":\"1\"}\n")
+	sum := sha256.Sum256(content)
+	require.NoError(t, os.WriteFile(path, content, 0o644))
+	ctx := context.Background()
+
+	// Goal: S3 uploads are signed, and the object URL and checksum are returned.
+	s := &Store{
+		Name:   "backups",
+		Config: config.ObjectStoreConfig{Type: config.ObjectStoreS3, Bucket: "exports", Prefix: "solr/", Region: "eu-west-1", Endpoint: server.URL},
+		Secret: secrets(map[string]string{"AWS_ACCESS_KEY_ID": "AKID", "AWS_SECRET_ACCESS_KEY": "secret"}),
+	}
+	obj, err := s.Upload(ctx, "job 1.jsonl", path, "application/x-ndjson")
+	require.NoError(t, err)
+	assert.Equal(t, Object{
+		Store:  "backups",
+		URL:    server.URL + "/exports/solr/job%201.jsonl",
+		Key:    "solr/job 1.jsonl",
+		Bytes:  11,
+		SHA256: hex.EncodeToString(sum[:]),
+	}, obj)
+	assert.Equal(t, http.MethodPut, got.Method)
+	assert.Equal(t, "/exports/solr/job%201.jsonl", got.URL.EscapedPath())
+	assert.Equal(t, obj.SHA256, got.Header.Get("X-Amz-Content-Sha256"))
+	assert.True(t, strings.HasPrefix(got.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/"))
+	assert.Contains(t, got.Header.Get("Authorization"), "/eu-west-1/s3/aws4_request")
+	assert.Equal(t, "{\"id\":\"1\"}\n", body)
+
+	// Goal: Azure uploads carry the SAS token, which is not part of the returned URL.
+	s = &Store{
+		Name:   "blob",
+		Config: config.ObjectStoreConfig{Type: config.ObjectStoreAzure, Bucket: "exports", Endpoint: server.URL},
+		Secret: secrets(map[string]string{"AZURE_STORAGE_SAS_TOKEN": "?sv=2021&sig=abc"}),
+	}
+	obj, err = s.Upload(ctx, "a.json", path, "application/json")
+	require.NoError(t, err)
+	assert.Equal(t, server.URL+"/exports/a.json", obj.URL)
+	assert.Equal(t, "sv=2021&sig=abc", got.URL.RawQuery)
+	assert.Equal(t, "BlockBlob", got.Header.Get("X-Ms-Blob-Type"))
+
+	// Goal: Missing credentials are reported before uploading.
+	s.Secret = secrets(nil)
+	_, err = s.Upload(ctx, "a.json", path, "application/json")
+	assert.ErrorContains(t, err, "AZURE_STORAGE_SAS_TOKEN is not set")
+}
+
+// roundTripFunc captures the requests of a client without sending them.
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
+
+// TestRequestFormats tests the requests sent to the public endpoint of each provider.
+func TestRequestFormats(t *testing.T) {
+	var got *http.Request
+	client := &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		got = r
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader("")), Request: r}, nil
+	})}
+	path := filepath.Join(t.TempDir(), "export.jsonl")
+	require.NoError(t, os.WriteFile(path, []byte("{}\n"), 0o644))
+	ctx := context.Background()
+	signedHeaders := func(r *http.Request) string {
+		_, rest, _ := strings.Cut(r.Header.Get("Authorization"), "SignedHeaders=")
+		headers, _, _ := strings.Cut(rest, ",")
+		return headers
+	}
+
+	// Goal: S3 uses virtual-hosted URLs, signs the payload hash and content type for s3 in the bucket region,
+	// and sends keys with reserved characters percent-encoded as they were signed.
+	s := &Store{
+		Name:   "s3",
+		Config: config.ObjectStoreConfig{Type: config.ObjectStoreS3, Bucket: "exports", Region: "eu-west-1"},
+		Client: client,
+		Secret: secrets(map[string]string{"AWS_ACCESS_KEY_ID": "AKID", "AWS_SECRET_ACCESS_KEY": "secret", "AWS_SESSION_TOKEN": "token"}),
+	}
+	_, err := s.Upload(ctx, "a+b$c=d.jsonl", path, "application/x-ndjson")
+	require.NoError(t, err)
+	assert.Equal(t, "https://exports.s3.eu-west-1.amazonaws.com/a%2Bb%24c%3Dd.jsonl", got.URL.String())
+	assert.Equal(t, "content-type;host;x-amz-content-sha256;x-amz-date;x-amz-security-token", signedHeaders(got))
+	assert.Contains(t, got.Header.Get("Authorization"), "Credential=AKID/")
+	assert.Contains(t, got.Header.Get("Authorization"), "/eu-west-1/s3/aws4_request")
+	assert.Equal(t, "token", got.Header.Get("X-Amz-Security-Token"))
+	assert.Equal(t, int64(3), got.ContentLength)
+
+	// Goal: Ranged S3 reads sign the range and the hash of the empty body.
+	r, err := s.Open(ctx, "a.jsonl", 5)
+	require.NoError(t, err)
+	r.Close()
+	assert.Equal(t, "host;range;x-amz-content-sha256;x-amz-date;x-amz-security-token", signedHeaders(got))
+	assert.Equal(t, emptySHA256, got.Header.Get("X-Amz-Content-Sha256"))
+
+	// Goal: GCS uses path-style URLs of the XML API, signed with HMAC keys for s3 in the "auto" region.
+	s = &Store{
+		Name:   "gcs",
+		Config: config.ObjectStoreConfig{Type: config.ObjectStoreGCS, Bucket: "exports"},
+		Client: client,
+		Secret: secrets(map[string]string{"GCS_ACCESS_KEY_ID": "GOOG1", "GCS_SECRET_ACCESS_KEY": "secret"}),
+	}
+	_, err = s.Upload(ctx, "a.jsonl", path, "application/x-ndjson")
+	require.NoError(t, err)
+	assert.Equal(t, "https://storage.googleapis.com/exports/a.jsonl", got.URL.String())
+	assert.Contains(t, got.Header.Get("Authorization"), "Credential=GOOG1/")
+	assert.Contains(t, got.Header.Get("Authorization"), "/auto/s3/aws4_request")
+
+	// Goal: Azure authorizes with the SAS token in the query and the Blob service version, without a signature header.
+	s = &Store{
+		Name:   "azure",
+		Config: config.ObjectStoreConfig{Type: config.ObjectStoreAzure, Bucket: "exports", Account: "acct"},
+		Client: client,
+		Secret: secrets(map[string]string{"AZURE_STORAGE_SAS_TOKEN": "sv=2021-08-06&sp=cw&sig=abc"}),
+	}
+	_, err = s.Upload(ctx, "a.jsonl", path, "application/x-ndjson")
+	require.NoError(t, err)
+	assert.Equal(t, "https://acct.blob.core.windows.net/exports/a.jsonl?sv=2021-08-06&sp=cw&sig=abc", got.URL.String())
+	assert.Equal(t, azureVersion, got.Header.Get("X-Ms-Version"))
+	assert.Equal(t, "BlockBlob", got.Header.Get("X-Ms-Blob-Type"))
+	assert.Empty(t, got.Header.Get("Authorization"))
+}
+
+// TestObjectURL tests the public endpoints of the store types.
+func TestObjectURL(t *testing.T) {
+	s := &Store{Config: config.ObjectStoreConfig{Type: config.ObjectStoreS3, Bucket: "b", Region: "us-east-2"}}
+	assert.Equal(t, "https://b.s3.us-east-2.amazonaws.com/x/y.json", s.objectURL("x/y.json"))
+	s.Config = config.ObjectStoreConfig{Type: config.ObjectStoreGCS, Bucket: "b"}
+	assert.Equal(t, "https://storage.googleapis.com/b/x/y.json", s.objectURL("x/y.json"))
+	s.Config = config.ObjectStoreConfig{Type: config.ObjectStoreAzure, Bucket: "c", Account: "acct"}
+	assert.Equal(t, "https://acct.blob.core.windows.net/c/x/y.json", s.objectURL("x/y.json"))
+}
+
+// TestOpen tests reading objects from an offset.
+func TestOpen(t *testing.T) {
+	const content = "0123456789"
+	var ranges []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ranges = append(ranges, r.Header.Get("Range"))
+		http.ServeContent(w, r, "data", time.Time{}, strings.NewReader(content))
+	}))
+	defer server.Close()
+	s := &Store{
+		Name:   "src",
+		Config: config.ObjectStoreConfig{Type: config.ObjectStoreGCS, Bucket: "b", Prefix: "ignored/", Endpoint: server.URL},
+		Secret: secrets(map[string]string{"GCS_ACCESS_KEY_ID": "GOOG", "GCS_SECRET_ACCESS_KEY": "secret"}),
+	}
+	read := func(offset int64) string {
+		r, err := s.Open(context.Background(), "data.jsonl", offset)
+		require.NoError(t, err)
+		defer r.Close()
+		data, err := io.ReadAll(r)
+		require.NoError(t, err)
+		return string(data)
+	}
+
+	// Goal: Objects are read whole, from an offset with a range request, and as empty past their end.
+	assert.Equal(t, content, read(0))
+	assert.Equal(t, "6789", read(6))
+	assert.Equal(t, "", read(10))
+	assert.Equal(t, []string{"", "bytes=6-", "bytes=10-"}, ranges)
+}
+
+// TestParseURI tests splitting object URIs.
+func TestParseURI(t *testing.T) {
+	typ, bucket, key, err := ParseURI("gs://data/exports/a.jsonl")
+	require.NoError(t, err)
+	assert.Equal(t, []string{config.ObjectStoreGCS, "data", "exports/a.jsonl"}, []string{typ, bucket, key})
+	typ, _, _, err = ParseURI("s3://data/a.jsonl")
+	require.NoError(t, err)
+	assert.Equal(t, config.ObjectStoreS3, typ)
+	for _, uri := range []string{"https://data/a.jsonl", "s3://data", "s3:///a.jsonl"} {
+		_, _, _, err = ParseURI(uri)
+		assert.Error(t, err, uri)
+	}
+}
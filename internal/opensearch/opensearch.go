@@ -0,0 +1,375 @@
+// Package opensearch is an experimental backend.SearchBackend for OpenSearch and Elasticsearch.
+// It translates the Solr parameters used by the tools into the Query DSL and returns Solr-shaped responses,
+// so the core tools (query, schema, health, update and delete) work unchanged against either engine.
+package opensearch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/backend"
+	"github.com/Sashimimochi/solr-mcp-go/internal/config"
+	"github.com/Sashimimochi/solr-mcp-go/internal/types"
+)
+
+var _ backend.SearchBackend = (*Backend)(nil)
+
+// IDField is the document field mapped to the OpenSearch _id, playing the role of the Solr uniqueKey.
+const IDField = "id"
+
+// Backend accesses an OpenSearch or Elasticsearch cluster. Indices take the place of collections.
+type Backend struct {
+	HttpClient *http.Client
+	BaseURL    string
+	User       string
+	Pass       string
+}
+
+// New creates a Backend for the cluster at baseURL.
+func New(baseURL, user, pass string, httpClient *http.Client) *Backend {
+	return &Backend{HttpClient: httpClient, BaseURL: strings.TrimRight(baseURL, "/"), User: user, Pass: pass}
+}
+
+// Query translates q, fq, fl, sort, start and rows into a _search request.
+func (b *Backend) Query(ctx context.Context, index string, params url.Values) (map[string]any, error) {
+	body, start := SearchBody(params)
+	var resp struct {
+		Took int `json:"took"`
+		Hits struct {
+			Total struct {
+				Value int64 `json:"value"`
+			} `json:"total"`
+			Hits []struct {
+				ID     string         `json:"_id"`
+				Source map[string]any `json:"_source"`
+			} `json:"hits"`
+		} `json:"hits"`
+	}
+	if err := b.do(ctx, http.MethodPost, "/"+url.PathEscape(index)+"/_search", body, &resp); err != nil {
+		return nil, err
+	}
+	docs := make([]any, 0, len(resp.Hits.Hits))
+	for _, h := range resp.Hits.Hits {
+		doc := h.Source
+		if doc == nil {
+			doc = map[string]any{}
+		}
+		if _, ok := doc[IDField]; !ok {
+			doc[IDField] = h.ID
+		}
+		docs = append(docs, doc)
+	}
+	return map[string]any{
+		"responseHeader": map[string]any{"status": 0, "QTime": resp.Took},
+		"response": map[string]any{
+			"numFound": float64(resp.Hits.Total.Value),
+			"start":    start,
+			"docs":     docs,
+		},
+	}, nil
+}
+
+// SearchBody converts Solr /select parameters into a _search request body, and returns the start offset.
+func SearchBody(params url.Values) (map[string]any, int) {
+	body := map[string]any{"query": BoolQuery(params.Get("q"), params["fq"]), "track_total_hits": true}
+	start, _ := strconv.Atoi(params.Get("start"))
+	if start > 0 {
+		body["from"] = start
+	}
+	if rows := params.Get("rows"); rows != "" {
+		n, _ := strconv.Atoi(rows)
+		body["size"] = n
+	}
+	if fl := params.Get("fl"); fl != "" {
+		var fields []string
+		for _, f := range strings.FieldsFunc(fl, func(r rune) bool { return r == ',' || r == ' ' }) {
+			if f != "score" {
+				fields = append(fields, f)
+			}
+		}
+		body["_source"] = fields
+	}
+	if s := params.Get("sort"); s != "" {
+		var sorts []any
+		for _, clause := range strings.Split(s, ",") {
+			field, order, _ := strings.Cut(strings.TrimSpace(clause), " ")
+			if field == "score" {
+				field = "_score"
+			}
+			sorts = append(sorts, map[string]any{field: map[string]any{"order": sortOrder(order)}})
+		}
+		body["sort"] = sorts
+	}
+	return body, start
+}
+
+func sortOrder(order string) string {
+	if strings.EqualFold(strings.TrimSpace(order), "desc") {
+		return "desc"
+	}
+	return "asc"
+}
+
+// BoolQuery combines a main query and filter queries.
+func BoolQuery(q string, fq []string) map[string]any {
+	main := StringQuery(q)
+	if len(fq) == 0 {
+		return main
+	}
+	filters := make([]any, len(fq))
+	for i, f := range fq {
+		filters[i] = StringQuery(f)
+	}
+	return map[string]any{"bool": map[string]any{"must": main, "filter": filters}}
+}
+
+// StringQuery translates a Solr query string: *:* becomes match_all, {!terms f=...} becomes a terms (or ids)
+// query, and anything else is passed to query_string, which shares the Lucene syntax.
+func StringQuery(q string) map[string]any {
+	q = strings.TrimSpace(q)
+	if q == "" || q == "*:*" {
+		return map[string]any{"match_all": map[string]any{}}
+	}
+	if strings.HasPrefix(q, "{!terms ") {
+		if local, values, ok := strings.Cut(strings.TrimPrefix(q, "{!terms "), "}"); ok {
+			field, sep := "", ","
+			for _, kv := range strings.Fields(local) {
+				k, v, _ := strings.Cut(kv, "=")
+				switch k {
+				case "f":
+					field = v
+				case "separator":
+					sep = strings.Trim(v, `'"`)
+				}
+			}
+			terms := strings.Split(values, sep)
+			if field == IDField {
+				return map[string]any{"ids": map[string]any{"values": terms}}
+			}
+			return map[string]any{"terms": map[string]any{field: terms}}
+		}
+	}
+	return map[string]any{"query_string": map[string]any{"query": q}}
+}
+
+// Count uses the _count API.
+func (b *Backend) Count(ctx context.Context, index, query string) (int64, error) {
+	var resp struct {
+		Count int64 `json:"count"`
+	}
+	body := map[string]any{"query": StringQuery(query)}
+	if err := b.do(ctx, http.MethodPost, "/"+url.PathEscape(index)+"/_count", body, &resp); err != nil {
+		return 0, err
+	}
+	return resp.Count, nil
+}
+
+// Add indexes documents with the bulk API, using their id field as _id, and refreshes the index.
+func (b *Backend) Add(ctx context.Context, index string, docs []map[string]any) error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for i, doc := range docs {
+		action := map[string]any{}
+		if id, ok := doc[IDField]; ok {
+			action["_id"] = fmt.Sprint(id)
+		}
+		if err := enc.Encode(map[string]any{"index": action}); err != nil {
+			return fmt.Errorf("encode documents[%d]: %v", i, err)
+		}
+		if err := enc.Encode(doc); err != nil {
+			return fmt.Errorf("encode documents[%d]: %v", i, err)
+		}
+	}
+	return b.bulk(ctx, index, &buf)
+}
+
+// DeleteByQuery uses the _delete_by_query API and refreshes the index.
+func (b *Backend) DeleteByQuery(ctx context.Context, index, query string) error {
+	body := map[string]any{"query": StringQuery(query)}
+	return b.do(ctx, http.MethodPost, "/"+url.PathEscape(index)+"/_delete_by_query?refresh=true", body, nil)
+}
+
+// DeleteByIDs deletes documents by _id with the bulk API and refreshes the index.
+func (b *Backend) DeleteByIDs(ctx context.Context, index string, ids []string) error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, id := range ids {
+		if err := enc.Encode(map[string]any{"delete": map[string]any{"_id": id}}); err != nil {
+			return fmt.Errorf("encode delete: %v", err)
+		}
+	}
+	return b.bulk(ctx, index, &buf)
+}
+
+func (b *Backend) bulk(ctx context.Context, index string, body *bytes.Buffer) error {
+	var resp struct {
+		Errors bool             `json:"errors"`
+		Items  []map[string]any `json:"items"`
+	}
+	u := b.BaseURL + "/" + url.PathEscape(index) + "/_bulk?refresh=true"
+	if err := b.send(ctx, http.MethodPost, u, "application/x-ndjson", body, &resp); err != nil {
+		return err
+	}
+	if resp.Errors {
+		for _, item := range resp.Items {
+			for _, result := range item {
+				if r, _ := result.(map[string]any); r != nil && r["error"] != nil {
+					return fmt.Errorf("bulk request failed: %v", r["error"])
+				}
+			}
+		}
+		return fmt.Errorf("bulk request failed")
+	}
+	return nil
+}
+
+// Schema flattens the index mapping into fields. Object fields are reported with dotted names.
+func (b *Backend) Schema(ctx context.Context, index string) (*types.FieldCatalog, error) {
+	var resp map[string]struct {
+		Mappings struct {
+			Properties map[string]any `json:"properties"`
+		} `json:"mappings"`
+	}
+	if err := b.do(ctx, http.MethodGet, "/"+url.PathEscape(index)+"/_mapping", nil, &resp); err != nil {
+		return nil, fmt.Errorf("failed to get schema: %v", err)
+	}
+	fc := &types.FieldCatalog{UniqueKey: IDField}
+	for _, m := range resp {
+		fc.All = append(fc.All, mappingFields("", m.Mappings.Properties)...)
+	}
+	sort.Slice(fc.All, func(i, j int) bool { return fc.All[i].Name < fc.All[j].Name })
+	return fc, nil
+}
+
+func mappingFields(prefix string, properties map[string]any) []types.SolrField {
+	var fields []types.SolrField
+	for name, p := range properties {
+		prop, _ := p.(map[string]any)
+		if nested, ok := prop["properties"].(map[string]any); ok {
+			fields = append(fields, mappingFields(prefix+name+".", nested)...)
+			continue
+		}
+		typ, _ := prop["type"].(string)
+		indexed := true
+		if v, ok := prop["index"].(bool); ok {
+			indexed = v
+		}
+		fields = append(fields, types.SolrField{Name: prefix + name, Type: typ, Indexed: indexed, Stored: true})
+	}
+	return fields
+}
+
+// ClusterStatus maps _cluster/health and _cat/nodes onto the Solr CLUSTERSTATUS structure.
+func (b *Backend) ClusterStatus(ctx context.Context, index string) (*config.ClusterStatusResponse, error) {
+	var nodes []struct {
+		Name string `json:"name"`
+	}
+	if err := b.do(ctx, http.MethodGet, "/_cat/nodes?format=json&h=name", nil, &nodes); err != nil {
+		return nil, fmt.Errorf("cluster status request: %v", err)
+	}
+	var health struct {
+		Indices map[string]struct {
+			Status string `json:"status"`
+			Shards map[string]struct {
+				Status string `json:"status"`
+			} `json:"shards"`
+		} `json:"indices"`
+	}
+	path := "/_cluster/health?level=shards"
+	if index != "" {
+		path = "/_cluster/health/" + url.PathEscape(index) + "?level=shards"
+	}
+	if err := b.do(ctx, http.MethodGet, path, nil, &health); err != nil {
+		return nil, fmt.Errorf("cluster status request: %v", err)
+	}
+
+	status := &config.ClusterStatusResponse{}
+	for _, n := range nodes {
+		status.Cluster.LiveNodes = append(status.Cluster.LiveNodes, n.Name)
+	}
+	status.Cluster.Collections = make(map[string]config.CollectionStatus, len(health.Indices))
+	for name, idx := range health.Indices {
+		shards := make(map[string]config.ShardInfo, len(idx.Shards))
+		for n, s := range idx.Shards {
+			shards["shard"+n] = config.ShardInfo{State: "active", Health: strings.ToUpper(s.Status)}
+		}
+		status.Cluster.Collections[name] = config.CollectionStatus{Health: strings.ToUpper(idx.Status), Shards: shards}
+	}
+	return status, nil
+}
+
+// ListCollections returns the names of all indices except hidden ones.
+func (b *Backend) ListCollections(ctx context.Context) ([]string, error) {
+	var indices []struct {
+		Index string `json:"index"`
+	}
+	if err := b.do(ctx, http.MethodGet, "/_cat/indices?format=json&h=index", nil, &indices); err != nil {
+		return nil, fmt.Errorf("failed to list collections: %v", err)
+	}
+	var names []string
+	for _, i := range indices {
+		if !strings.HasPrefix(i.Index, ".") {
+			names = append(names, i.Index)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// DeleteCollection deletes an index.
+func (b *Backend) DeleteCollection(ctx context.Context, index string) error {
+	return b.do(ctx, http.MethodDelete, "/"+url.PathEscape(index), nil, nil)
+}
+
+// do sends a JSON request to path and decodes the JSON response into out, if not nil.
+func (b *Backend) do(ctx context.Context, method, path string, body any, out any) error {
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("marshal request: %v", err)
+		}
+		reader = bytes.NewReader(payload)
+	}
+	return b.send(ctx, method, b.BaseURL+path, "application/json", reader, out)
+}
+
+func (b *Backend) send(ctx context.Context, method, u, contentType string, body io.Reader, out any) error {
+	slog.Debug("OpenSearch request", "method", method, "url", u)
+	req, err := http.NewRequestWithContext(ctx, method, u, body)
+	if err != nil {
+		return fmt.Errorf("create request: %v", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if b.User != "" {
+		req.SetBasicAuth(b.User, b.Pass)
+	}
+	res, err := b.HttpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("HTTP request error: %v", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		bodyBytes, _ := io.ReadAll(res.Body)
+		return fmt.Errorf("HTTP status %d: %s", res.StatusCode, string(bodyBytes))
+	}
+	if out == nil {
+		_, _ = io.Copy(io.Discard, res.Body)
+		return nil
+	}
+	if err := json.NewDecoder(res.Body).Decode(out); err != nil {
+		return fmt.Errorf("JSON decode error: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package opensearch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSearchBody tests the translation of Solr query parameters into the Query DSL.
+func TestSearchBody(t *testing.T) {
+	params := url.Values{
+		"q":     {"*:*"},
+		"fq":    {"level:ERROR", "{!terms f=host}a,b"},
+		"fl":    {"id,message,score"},
+		"sort":  {"timestamp desc, score asc"},
+		"start": {"20"},
+		"rows":  {"5"},
+	}
+	body, start := SearchBody(params)
+	got, err := json.Marshal(body)
+	require.NoError(t, err)
+
+	// Goal: Filters become bool filters, fl becomes _source and sort, start and rows are mapped.
+	assert.Equal(t, 20, start)
+	assert.JSONEq(t, `{
+		"query": {"bool": {
+			"must": {"match_all": {}},
+			"filter": [{"query_string": {"query": "level:ERROR"}}, {"terms": {"host": ["a", "b"]}}]
+		}},
+		"_source": ["id", "message"],
+		"sort": [{"timestamp": {"order": "desc"}}, {"_score": {"order": "asc"}}],
+		"from": 20,
+		"size": 5,
+		"track_total_hits": true
+	}`, string(got))
+
+	// Goal: Terms queries on the id field become ids queries and honour the separator.
+	assert.Equal(t, map[string]any{"ids": map[string]any{"values": []string{"a,1", "b"}}},
+		StringQuery("{!terms f=id separator='|'}a,1|b"))
+	// Goal: A Lucene query string is passed through.
+	assert.Equal(t, map[string]any{"query_string": map[string]any{"query": "title:go"}}, StringQuery("title:go"))
+}
+
+// TestBackend tests the Backend against a mock OpenSearch cluster.
+func TestBackend(t *testing.T) {
+	var lastBody, lastPath string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		lastBody, lastPath = string(body), r.Method+" "+r.URL.Path
+		switch r.Method + " " + r.URL.Path {
+		case "POST /logs/_search":
+			fmt.Fprintln(w, `{"took":3,"hits":{"total":{"value":2},"hits":[
+				{"_id":"1","_source":{"message":"a"}},
+				{"_id":"2","_source":{"id":"2","message":"b"}}]}}`)
+		case "POST /logs/_count":
+			fmt.Fprintln(w, `{"count":7}`)
+		case "POST /logs/_bulk":
+			assert.Equal(t, "application/x-ndjson", r.Header.Get("Content-Type"))
+			assert.Equal(t, "true", r.URL.Query().Get("refresh"))
+			fmt.Fprintln(w, `{"errors":false,"items":[]}`)
+		case "POST /broken/_bulk":
+			fmt.Fprintln(w, `{"errors":true,"items":[{"index":{"error":{"type":"mapper_parsing_exception"}}}]}`)
+		case "POST /logs/_delete_by_query", "DELETE /logs":
+			fmt.Fprintln(w, `{}`)
+		case "GET /logs/_mapping":
+			fmt.Fprintln(w, `{"logs":{"mappings":{"properties":{
+				"message":{"type":"text"},
+				"host":{"properties":{"name":{"type":"keyword","index":false}}}}}}}`)
+		case "GET /_cat/nodes":
+			fmt.Fprintln(w, `[{"name":"node-1"},{"name":"node-2"}]`)
+		case "GET /_cluster/health/logs":
+			fmt.Fprintln(w, `{"indices":{"logs":{"status":"yellow","shards":{"0":{"status":"yellow"}}}}}`)
+		case "GET /_cat/indices":
+			fmt.Fprintln(w, `[{"index":"logs"},{"index":".kibana"},{"index":"audit"}]`)
+		default:
+			http.Error(w, `{"error":"no such index"}`, http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	b := New(server.URL, "", "", server.Client())
+	ctx := context.Background()
+
+	// Goal: Search hits are returned in the Solr response shape with the _id as id.
+	resp, err := b.Query(ctx, "logs", url.Values{"q": {"*:*"}})
+	require.NoError(t, err)
+	response := resp["response"].(map[string]any)
+	assert.Equal(t, float64(2), response["numFound"])
+	assert.Equal(t, []any{
+		map[string]any{"id": "1", "message": "a"},
+		map[string]any{"id": "2", "message": "b"},
+	}, response["docs"])
+
+	n, err := b.Count(ctx, "logs", "level:DEBUG")
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), n)
+
+	// Goal: Documents are indexed with the bulk API using their id as _id.
+	require.NoError(t, b.Add(ctx, "logs", []map[string]any{{"id": "1", "message": "a"}}))
+	assert.Equal(t, "{\"index\":{\"_id\":\"1\"}}\n{\"id\":\"1\",\"message\":\"a\"}\n", lastBody)
+	require.NoError(t, b.DeleteByIDs(ctx, "logs", []string{"1", "2"}))
+	assert.Equal(t, "{\"delete\":{\"_id\":\"1\"}}\n{\"delete\":{\"_id\":\"2\"}}\n", lastBody)
+	require.NoError(t, b.DeleteByQuery(ctx, "logs", "*:*"))
+	assert.JSONEq(t, `{"query":{"match_all":{}}}`, lastBody)
+
+	// Goal: Item errors of a bulk request are reported.
+	err = b.Add(ctx, "broken", []map[string]any{{"id": "1"}})
+	assert.ErrorContains(t, err, "mapper_parsing_exception")
+
+	// Goal: The mapping is flattened into fields with id as unique key.
+	fc, err := b.Schema(ctx, "logs")
+	require.NoError(t, err)
+	assert.Equal(t, "id", fc.UniqueKey)
+	require.Len(t, fc.All, 2)
+	assert.Equal(t, "host.name", fc.All[0].Name)
+	assert.False(t, fc.All[0].Indexed)
+	assert.Equal(t, "message", fc.All[1].Name)
+
+	// Goal: Cluster health is mapped onto the CLUSTERSTATUS structure.
+	status, err := b.ClusterStatus(ctx, "logs")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"node-1", "node-2"}, status.Cluster.LiveNodes)
+	assert.Equal(t, "YELLOW", status.Cluster.Collections["logs"].Health)
+	assert.Equal(t, "YELLOW", status.Cluster.Collections["logs"].Shards["shard0"].Health)
+
+	// Goal: Hidden indices are not listed.
+	names, err := b.ListCollections(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"audit", "logs"}, names)
+
+	require.NoError(t, b.DeleteCollection(ctx, "logs"))
+	assert.Equal(t, "DELETE /logs", lastPath)
+
+	// Goal: HTTP errors are propagated.
+	_, err = b.Schema(ctx, "missing")
+	assert.ErrorContains(t, err, "HTTP status 404")
+}
@@ -0,0 +1,259 @@
+// Package policy authorizes tool calls with rules written in the Common Expression Language (CEL) and, optionally,
+// with an Open Policy Agent (OPA) server, recording every decision in an audit trail.
+package policy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/config"
+
+	"github.com/google/cel-go/cel"
+)
+
+// Input is the context of a tool call that rules and OPA decide on. In CEL rules, its fields are variables,
+// e.g. `collection == "hr" && !("hr-reader" in scopes)`.
+type Input struct {
+	Tool       string         `json:"tool"`
+	User       string         `json:"user"`   // from the user header, empty when the caller is unknown
+	Scopes     []string       `json:"scopes"` // scopes of the bearer token, if any
+	Client     string         `json:"client"` // MCP client name
+	Collection string         `json:"collection"`
+	Fields     []string       `json:"fields"`
+	Rows       int64          `json:"rows"`
+	Args       map[string]any `json:"args"` // all arguments of the call
+}
+
+// Decision is the outcome of an authorization.
+type Decision struct {
+	Allow  bool   `json:"allow"`
+	Rule   string `json:"rule"` // name of the deciding rule, "default" or "opa"
+	Reason string `json:"reason,omitempty"`
+}
+
+// rule is a compiled PolicyRule.
+type rule struct {
+	config.PolicyRule
+	program cel.Program
+}
+
+// Engine evaluates the policy of the config file. It is safe for concurrent use.
+type Engine struct {
+	client *http.Client
+
+	mu    sync.RWMutex
+	cfg   config.PolicyConfig
+	rules []rule
+	err   error // compilation error of the rules; every call is denied while it is set
+
+	auditMu sync.Mutex
+}
+
+// New returns an Engine without a policy, which allows every call. OPA is queried with client.
+func New(client *http.Client) *Engine {
+	return &Engine{client: client}
+}
+
+// newEnv returns the CEL environment of rules, declaring the fields of Input.
+func newEnv() (*cel.Env, error) {
+	return cel.NewEnv(
+		cel.Variable("tool", cel.StringType),
+		cel.Variable("user", cel.StringType),
+		cel.Variable("scopes", cel.ListType(cel.StringType)),
+		cel.Variable("client", cel.StringType),
+		cel.Variable("collection", cel.StringType),
+		cel.Variable("fields", cel.ListType(cel.StringType)),
+		cel.Variable("rows", cel.IntType),
+		cel.Variable("args", cel.MapType(cel.StringType, cel.DynType)),
+	)
+}
+
+// Compile checks that every rule is a valid CEL expression returning a bool.
+func Compile(cfg config.PolicyConfig) error {
+	_, err := compile(cfg.Rules)
+	return err
+}
+
+func compile(rules []config.PolicyRule) ([]rule, error) {
+	env, err := newEnv()
+	if err != nil {
+		return nil, err
+	}
+	compiled := make([]rule, 0, len(rules))
+	for i, r := range rules {
+		ast, iss := env.Compile(r.When)
+		if iss.Err() != nil {
+			return nil, fmt.Errorf("policy.rules[%d] (%s): %v", i, r.Name, iss.Err())
+		}
+		if ast.OutputType() != cel.BoolType {
+			return nil, fmt.Errorf("policy.rules[%d] (%s): when must be a bool expression, not %s", i, r.Name, ast.OutputType())
+		}
+		prg, err := env.Program(ast)
+		if err != nil {
+			return nil, fmt.Errorf("policy.rules[%d] (%s): %v", i, r.Name, err)
+		}
+		compiled = append(compiled, rule{PolicyRule: r, program: prg})
+	}
+	return compiled, nil
+}
+
+// SetConfig replaces the policy. When a rule does not compile, the error is returned and every call is denied
+// until a valid policy is set, since authorization must not fail open.
+func (e *Engine) SetConfig(cfg config.PolicyConfig) error {
+	rules, err := compile(cfg.Rules)
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.cfg, e.rules, e.err = cfg, rules, err
+	return err
+}
+
+// Config returns the current policy.
+func (e *Engine) Config() config.PolicyConfig {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.cfg
+}
+
+// Decide authorizes a call: the first matching rule decides, or the default. Allowed calls are then
+// checked with OPA, if configured. Rules failing to evaluate deny the call.
+func (e *Engine) Decide(ctx context.Context, in Input) Decision {
+	e.mu.RLock()
+	cfg, rules, err := e.cfg, e.rules, e.err
+	e.mu.RUnlock()
+	if err != nil {
+		return Decision{Rule: "invalid", Reason: "the policy is invalid: " + err.Error()}
+	}
+
+	d := Decision{Allow: cfg.Default != config.PolicyDeny, Rule: "default"}
+	if !d.Allow {
+		d.Reason = "no policy rule allows this call"
+	}
+	vars := map[string]any{
+		"tool": in.Tool, "user": in.User, "scopes": orEmpty(in.Scopes), "client": in.Client,
+		"collection": in.Collection, "fields": orEmpty(in.Fields), "rows": in.Rows, "args": in.Args,
+	}
+	if vars["args"] == nil {
+		vars["args"] = map[string]any{}
+	}
+	for _, r := range rules {
+		out, _, err := r.program.ContextEval(ctx, vars)
+		if err != nil {
+			return Decision{Rule: r.Name, Reason: fmt.Sprintf("rule %s failed: %v", r.Name, err)}
+		}
+		if matched, _ := out.Value().(bool); matched {
+			d = Decision{Allow: r.Effect == config.PolicyAllow, Rule: r.Name, Reason: r.Reason}
+			break
+		}
+	}
+	if d.Allow && cfg.OPA.URL != "" {
+		d = e.queryOPA(ctx, cfg.OPA, in)
+	}
+	return d
+}
+
+func orEmpty(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
+
+// queryOPA asks OPA for a decision on in. The result is a bool, or an object with allow and reason.
+func (e *Engine) queryOPA(ctx context.Context, oc config.OPAConfig, in Input) Decision {
+	ctx, cancel := context.WithTimeout(ctx, oc.TimeoutDuration())
+	defer cancel()
+	fail := func(err error) Decision {
+		slog.Error("OPA decision failed", "url", config.RedactURL(oc.URL), "error", err)
+		if oc.FailOpen {
+			return Decision{Allow: true, Rule: "opa", Reason: "OPA unavailable, allowed by failOpen"}
+		}
+		return Decision{Rule: "opa", Reason: fmt.Sprintf("OPA unavailable: %v", err)}
+	}
+	body, _ := json.Marshal(map[string]any{"input": in})
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, oc.URL, bytes.NewReader(body))
+	if err != nil {
+		return fail(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return fail(err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return fail(err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fail(fmt.Errorf("HTTP %d: %s", resp.StatusCode, bytes.TrimSpace(data)))
+	}
+	var out struct {
+		Result json.RawMessage `json:"result"`
+	}
+	if err := json.Unmarshal(data, &out); err != nil {
+		return fail(fmt.Errorf("parse response: %v", err))
+	}
+	if len(out.Result) == 0 {
+		return Decision{Rule: "opa", Reason: "the OPA decision is undefined"}
+	}
+	var allow bool
+	if json.Unmarshal(out.Result, &allow) == nil {
+		return Decision{Allow: allow, Rule: "opa"}
+	}
+	var obj struct {
+		Allow  bool   `json:"allow"`
+		Reason string `json:"reason"`
+	}
+	if err := json.Unmarshal(out.Result, &obj); err != nil {
+		return fail(fmt.Errorf("decision must be a bool or {allow, reason}: %s", out.Result))
+	}
+	return Decision{Allow: obj.Allow, Rule: "opa", Reason: obj.Reason}
+}
+
+// maxAuditArgs is the largest JSON size of the arguments kept in audit records, so documents sent to
+// solr.update do not bloat the audit file.
+const maxAuditArgs = 4 << 10
+
+// Audit logs a decision and appends it to the audit file if one is configured. Denials are logged as warnings.
+func (e *Engine) Audit(in Input, d Decision) {
+	attrs := []any{"tool", in.Tool, "user", in.User, "client", in.Client, "collection", in.Collection,
+		"allow", d.Allow, "rule", d.Rule, "reason", d.Reason}
+	if d.Allow {
+		slog.Debug("Policy decision", attrs...)
+	} else {
+		slog.Warn("Policy decision", attrs...)
+	}
+	path := e.Config().AuditFile
+	if path == "" {
+		return
+	}
+	record := struct {
+		Time time.Time `json:"time"`
+		Input
+		Decision
+		ArgsOmitted bool `json:"argsOmitted,omitempty"`
+	}{Time: time.Now().UTC(), Input: in, Decision: d}
+	if args, _ := json.Marshal(in.Args); len(args) > maxAuditArgs {
+		record.Args, record.ArgsOmitted = nil, true
+	}
+	line, _ := json.Marshal(record)
+	e.auditMu.Lock()
+	defer e.auditMu.Unlock()
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		slog.Error("Failed to open policy audit file", "path", path, "error", err)
+		return
+	}
+	defer f.Close()
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		slog.Error("Failed to write policy audit file", "path", path, "error", err)
+	}
+}
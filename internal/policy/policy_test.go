@@ -0,0 +1,126 @@
+package policy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestDecide tests deciding tool calls with CEL rules.
+func TestDecide(t *testing.T) {
+	ctx := context.Background()
+	e := New(http.DefaultClient)
+
+	// Goal: Without a policy, every call is allowed.
+	assert.True(t, e.Decide(ctx, Input{Tool: "solr.delete"}).Allow)
+
+	require.NoError(t, e.SetConfig(config.PolicyConfig{
+		Default: config.PolicyDeny,
+		Rules: []config.PolicyRule{
+			{Name: "hr-readers", When: `collection == "hr" && !("hr:read" in scopes)`, Effect: config.PolicyDeny, Reason: "hr needs the hr:read scope"},
+			{Name: "salary", When: `"salary" in fields && user != "payroll"`, Effect: config.PolicyDeny},
+			{Name: "row-limit", When: `tool == "solr.query" && rows > 100`, Effect: config.PolicyDeny, Reason: "at most 100 rows"},
+			{Name: "queries", When: `tool == "solr.query"`, Effect: config.PolicyAllow},
+			{Name: "ops-writes", When: `user == "ops" && has(args.documents)`, Effect: config.PolicyAllow},
+		},
+	}))
+	for _, tc := range []struct {
+		name string
+		in   Input
+		want Decision
+	}{
+		{"first match denies", Input{Tool: "solr.query", Collection: "hr"}, Decision{Rule: "hr-readers", Reason: "hr needs the hr:read scope"}},
+		{"scope allows", Input{Tool: "solr.query", Collection: "hr", Scopes: []string{"hr:read"}}, Decision{Allow: true, Rule: "queries"}},
+		{"field", Input{Tool: "solr.query", Fields: []string{"id", "salary"}}, Decision{Rule: "salary"}},
+		{"rows", Input{Tool: "solr.query", Rows: 1000}, Decision{Rule: "row-limit", Reason: "at most 100 rows"}},
+		{"args", Input{Tool: "solr.update", User: "ops", Args: map[string]any{"documents": []any{}}}, Decision{Allow: true, Rule: "ops-writes"}},
+		{"default", Input{Tool: "solr.update", User: "alice"}, Decision{Rule: "default", Reason: "no policy rule allows this call"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, e.Decide(ctx, tc.in))
+		})
+	}
+
+	// Goal: Rules must compile to a bool, and an invalid policy denies everything.
+	err := e.SetConfig(config.PolicyConfig{Rules: []config.PolicyRule{{Name: "rows", When: "rows + 1", Effect: config.PolicyDeny}}})
+	assert.ErrorContains(t, err, "when must be a bool expression")
+	assert.False(t, e.Decide(ctx, Input{Tool: "solr.query"}).Allow)
+	assert.ErrorContains(t, Compile(config.PolicyConfig{Rules: []config.PolicyRule{{Name: "x", When: "unknown == 1"}}}), "undeclared reference")
+}
+
+// TestOPA tests consulting OPA after the rules allowed a call.
+func TestOPA(t *testing.T) {
+	result := `true`
+	opa := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Input Input `json:"input"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, "solr.query", body.Input.Tool)
+		if result == "" {
+			fmt.Fprint(w, `{}`)
+			return
+		}
+		fmt.Fprintf(w, `{"result":%s}`, result)
+	}))
+	ctx := context.Background()
+	e := New(opa.Client())
+	cfg := config.PolicyConfig{
+		Rules: []config.PolicyRule{{Name: "no-drop", When: `tool == "solr.collection.drop"`, Effect: config.PolicyDeny}},
+		OPA:   config.OPAConfig{URL: opa.URL + "/v1/data/solr/allow"},
+	}
+	require.NoError(t, e.SetConfig(cfg))
+	in := Input{Tool: "solr.query"}
+
+	// Goal: OPA decisions are booleans or objects with a reason.
+	assert.Equal(t, Decision{Allow: true, Rule: "opa"}, e.Decide(ctx, in))
+	result = `{"allow":false,"reason":"outside business hours"}`
+	assert.Equal(t, Decision{Rule: "opa", Reason: "outside business hours"}, e.Decide(ctx, in))
+	result = ""
+	assert.Equal(t, Decision{Rule: "opa", Reason: "the OPA decision is undefined"}, e.Decide(ctx, in))
+
+	// Goal: Calls denied by a rule do not reach OPA.
+	assert.Equal(t, "no-drop", e.Decide(ctx, Input{Tool: "solr.collection.drop"}).Rule)
+
+	// Goal: An unreachable OPA denies calls unless failOpen is set.
+	opa.Close()
+	d := e.Decide(ctx, in)
+	assert.False(t, d.Allow)
+	assert.Contains(t, d.Reason, "OPA unavailable")
+	cfg.OPA.FailOpen = true
+	require.NoError(t, e.SetConfig(cfg))
+	assert.True(t, e.Decide(ctx, in).Allow)
+}
+
+// TestAudit tests the audit file of decisions.
+func TestAudit(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	e := New(http.DefaultClient)
+	require.NoError(t, e.SetConfig(config.PolicyConfig{Default: config.PolicyDeny, AuditFile: path}))
+	e.Audit(Input{Tool: "solr.query", User: "alice", Args: map[string]any{"query": "*:*"}}, Decision{Rule: "default"})
+	e.Audit(Input{Tool: "solr.update", Args: map[string]any{"documents": strings.Repeat("x", maxAuditArgs)}}, Decision{Allow: true, Rule: "ops"})
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, lines, 2)
+	var first, second map[string]any
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &first))
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &second))
+	assert.Equal(t, "alice", first["user"])
+	assert.Equal(t, map[string]any{"query": "*:*"}, first["args"])
+	assert.Equal(t, false, first["allow"])
+	assert.Nil(t, second["args"], "large arguments are left out")
+	assert.Equal(t, true, second["argsOmitted"])
+}
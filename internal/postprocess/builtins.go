@@ -0,0 +1,192 @@
+package postprocess
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Defaults of the truncate processor.
+const (
+	defaultMaxStringLength = 2000
+	defaultMaxArrayLength  = 100
+)
+
+// redactedValue replaces the values of redacted fields.
+const redactedValue = "[REDACTED]"
+
+// redactProcessor replaces the values of the given keys, at any depth, with a placeholder,
+// and adds a guardrail naming the redacted keys.
+func redactProcessor(fields []string) Processor {
+	set := make(map[string]bool, len(fields))
+	for _, f := range fields {
+		set[strings.ToLower(f)] = true
+	}
+	return ProcessorFunc(func(_ context.Context, r *Result) error {
+		if len(set) == 0 {
+			return nil
+		}
+		redacted := map[string]bool{}
+		r.Output = redact(r.Output, set, redacted)
+		if len(redacted) > 0 {
+			keys := make([]string, 0, len(redacted))
+			for k := range redacted {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			AddGuardrail(r.Output, "redact_fields", strings.Join(keys, ","), "postProcessing.redactFields of the server config hides these values")
+		}
+		return nil
+	})
+}
+
+func redact(v any, fields, redacted map[string]bool) any {
+	switch t := v.(type) {
+	case map[string]any:
+		for k, child := range t {
+			if fields[strings.ToLower(k)] {
+				t[k] = redactedValue
+				redacted[k] = true
+			} else {
+				t[k] = redact(child, fields, redacted)
+			}
+		}
+	case []any:
+		for i, child := range t {
+			t[i] = redact(child, fields, redacted)
+		}
+	}
+	return v
+}
+
+// truncateProcessor shortens long strings and arrays, and marks truncated outputs with "truncated": true and a guardrail.
+func truncateProcessor(maxString, maxArray int) Processor {
+	if maxString <= 0 {
+		maxString = defaultMaxStringLength
+	}
+	if maxArray <= 0 {
+		maxArray = defaultMaxArrayLength
+	}
+	return ProcessorFunc(func(_ context.Context, r *Result) error {
+		// Guardrails of earlier steps are kept whole, they explain the result
+		m, ok := r.Output.(map[string]any)
+		guardrails, kept := m["guardrails"]
+		if kept {
+			delete(m, "guardrails")
+		}
+		truncated := false
+		r.Output = truncate(r.Output, maxString, maxArray, &truncated)
+		if kept {
+			m["guardrails"] = guardrails
+		}
+		if ok && truncated {
+			m["truncated"] = true
+			AddGuardrail(m, "truncate_output", "", fmt.Sprintf("postProcessing truncate cuts strings to %d characters and arrays to %d items", maxString, maxArray))
+		}
+		return nil
+	})
+}
+
+func truncate(v any, maxString, maxArray int, truncated *bool) any {
+	switch t := v.(type) {
+	case string:
+		if runes := []rune(t); len(runes) > maxString {
+			*truncated = true
+			return string(runes[:maxString]) + "…"
+		}
+	case map[string]any:
+		for k, child := range t {
+			t[k] = truncate(child, maxString, maxArray, truncated)
+		}
+	case []any:
+		if len(t) > maxArray {
+			*truncated = true
+			t = t[:maxArray]
+		}
+		for i, child := range t {
+			t[i] = truncate(child, maxString, maxArray, truncated)
+		}
+		return t
+	}
+	return v
+}
+
+// queryDocs returns the documents of a Solr query response, or false if the output is not one.
+func queryDocs(output any) (map[string]any, []any, bool) {
+	m, _ := output.(map[string]any)
+	resp, _ := m["response"].(map[string]any)
+	docs, ok := resp["docs"].([]any)
+	return resp, docs, ok
+}
+
+// summarize adds a "summary" of Solr query responses: numFound, the number of returned documents and their fields.
+func summarize(_ context.Context, r *Result) error {
+	resp, docs, ok := queryDocs(r.Output)
+	if !ok {
+		return nil
+	}
+	r.Output.(map[string]any)["summary"] = map[string]any{
+		"numFound": resp["numFound"],
+		"returned": len(docs),
+		"fields":   docFields(docs),
+	}
+	return nil
+}
+
+func docFields(docs []any) []string {
+	seen := map[string]bool{}
+	fields := []string{}
+	for _, d := range docs {
+		doc, _ := d.(map[string]any)
+		for k := range doc {
+			if !seen[k] {
+				seen[k] = true
+				fields = append(fields, k)
+			}
+		}
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// format renders the documents of Solr query responses as a Markdown table in the text content.
+func format(_ context.Context, r *Result) error {
+	resp, docs, ok := queryDocs(r.Output)
+	if !ok {
+		return nil
+	}
+	fields := docFields(docs)
+	var b strings.Builder
+	fmt.Fprintf(&b, "numFound: %v, returned: %d\n", resp["numFound"], len(docs))
+	if len(fields) == 0 {
+		r.Text = b.String()
+		return nil
+	}
+	b.WriteString("\n| " + strings.Join(fields, " | ") + " |\n")
+	b.WriteString("|" + strings.Repeat(" --- |", len(fields)) + "\n")
+	for _, d := range docs {
+		doc, _ := d.(map[string]any)
+		cells := make([]string, len(fields))
+		for i, f := range fields {
+			if v, ok := doc[f]; ok {
+				cells[i] = markdownCell(v)
+			}
+		}
+		b.WriteString("| " + strings.Join(cells, " | ") + " |\n")
+	}
+	r.Text = b.String()
+	return nil
+}
+
+func markdownCell(v any) string {
+	s := fmt.Sprint(v)
+	if list, ok := v.([]any); ok {
+		parts := make([]string, len(list))
+		for i, p := range list {
+			parts[i] = fmt.Sprint(p)
+		}
+		s = strings.Join(parts, ", ")
+	}
+	return strings.NewReplacer("|", `\|`, "\n", " ").Replace(s)
+}
@@ -0,0 +1,163 @@
+// Package postprocess implements a configurable chain of processors applied to tool results,
+// such as redaction, truncation, summarization and formatting.
+package postprocess
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/config"
+)
+
+// Names of the built-in processors.
+const (
+	Redact    = "redact"
+	Truncate  = "truncate"
+	Summarize = "summarize"
+	Format    = "format"
+)
+
+// Result is the result of a tool call flowing through a pipeline.
+type Result struct {
+	Tool string
+	// Output is the structured output decoded from JSON: maps, slices, strings, float64, bool or nil.
+	Output any
+	// Text replaces the text content sent to the client when set. The JSON of Output is sent otherwise.
+	Text string
+}
+
+// Processor transforms a tool result in place.
+type Processor interface {
+	Process(ctx context.Context, r *Result) error
+}
+
+// ProcessorFunc adapts a function to the Processor interface.
+type ProcessorFunc func(ctx context.Context, r *Result) error
+
+// Process calls f(ctx, r).
+func (f ProcessorFunc) Process(ctx context.Context, r *Result) error {
+	return f(ctx, r)
+}
+
+// AddGuardrail appends an entry to the "guardrails" of a map output, telling the caller that a processor
+// changed the result. Entries have the shape of types.Guardrail. Other outputs are left unchanged.
+func AddGuardrail(output any, action, target, reason string) {
+	m, ok := output.(map[string]any)
+	if !ok {
+		return
+	}
+	g := map[string]any{"action": action, "reason": reason}
+	if target != "" {
+		g["target"] = target
+	}
+	list, _ := m["guardrails"].([]any)
+	m["guardrails"] = append(list, g)
+}
+
+// Registry holds the named processors and the configured pipelines.
+// Processors registered with Register take precedence over built-ins of the same name.
+type Registry struct {
+	mu       sync.RWMutex
+	cfg      config.PostProcessingConfig
+	builtins map[string]Processor
+	custom   map[string]Processor
+}
+
+// NewRegistry creates a Registry with the built-in processors configured from cfg.
+func NewRegistry(cfg config.PostProcessingConfig) *Registry {
+	r := &Registry{custom: make(map[string]Processor)}
+	r.SetConfig(cfg)
+	return r
+}
+
+// SetConfig replaces the configuration, e.g. after a config reload.
+func (r *Registry) SetConfig(cfg config.PostProcessingConfig) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cfg = cfg
+	r.builtins = map[string]Processor{
+		Redact:    redactProcessor(cfg.RedactFields),
+		Truncate:  truncateProcessor(cfg.MaxStringLength, cfg.MaxArrayLength),
+		Summarize: ProcessorFunc(summarize),
+		Format:    ProcessorFunc(format),
+	}
+	for _, name := range r.referencedLocked() {
+		if r.lookupLocked(name) == nil {
+			slog.Warn("Unknown post-processor in config, it will be skipped until registered", "processor", name)
+		}
+	}
+}
+
+// Register adds a processor that pipelines can reference by name.
+func (r *Registry) Register(name string, p Processor) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.custom[name] = p
+}
+
+// Pipeline returns the processor names applied to the results of tool, in order.
+func (r *Registry) Pipeline(tool string) []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if names, ok := r.cfg.Tools[tool]; ok {
+		return names
+	}
+	return r.cfg.Pipeline
+}
+
+// Process runs the pipeline of tool over its output.
+// It returns nil when the tool has no pipeline, so outputs are passed through untouched by default.
+func (r *Registry) Process(ctx context.Context, tool string, out any) (*Result, error) {
+	names := r.Pipeline(tool)
+	if len(names) == 0 {
+		return nil, nil
+	}
+	output, err := normalize(out)
+	if err != nil {
+		return nil, err
+	}
+	res := &Result{Tool: tool, Output: output}
+	for _, name := range names {
+		r.mu.RLock()
+		p := r.lookupLocked(name)
+		r.mu.RUnlock()
+		if p == nil {
+			continue
+		}
+		if err := p.Process(ctx, res); err != nil {
+			return nil, fmt.Errorf("post-processor %s: %v", name, err)
+		}
+	}
+	return res, nil
+}
+
+func (r *Registry) lookupLocked(name string) Processor {
+	if p, ok := r.custom[name]; ok {
+		return p
+	}
+	return r.builtins[name]
+}
+
+func (r *Registry) referencedLocked() []string {
+	names := append([]string{}, r.cfg.Pipeline...)
+	for _, tool := range r.cfg.Tools {
+		names = append(names, tool...)
+	}
+	return names
+}
+
+// normalize converts a tool output into its generic JSON representation.
+func normalize(out any) (any, error) {
+	b, err := json.Marshal(out)
+	if err != nil {
+		return nil, fmt.Errorf("encode output: %v", err)
+	}
+	var v any
+	if err := json.Unmarshal(b, &v); err != nil {
+		return nil, fmt.Errorf("decode output: %v", err)
+	}
+	return v, nil
+}
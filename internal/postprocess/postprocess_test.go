@@ -0,0 +1,103 @@
+package postprocess
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func queryResponse() map[string]any {
+	return map[string]any{
+		"response": map[string]any{
+			"numFound": 3,
+			"docs": []map[string]any{
+				{"id": "1", "email": "a@example.com", "title": strings.Repeat("x", 20)},
+				{"id": "2", "tags": []string{"a", "b"}},
+				{"id": "3"},
+			},
+		},
+	}
+}
+
+// TestRegistryProcess tests the selection and order of pipelines.
+func TestRegistryProcess(t *testing.T) {
+	ctx := context.Background()
+
+	// Goal: Without a pipeline outputs are passed through untouched.
+	r := NewRegistry(config.PostProcessingConfig{})
+	res, err := r.Process(ctx, "solr.query", queryResponse())
+	assert.NoError(t, err)
+	assert.Nil(t, res)
+
+	// Goal: Per-tool pipelines replace the default pipeline and run in order.
+	var order []string
+	r = NewRegistry(config.PostProcessingConfig{
+		Pipeline: []string{"first"},
+		Tools:    map[string][]string{"solr.query": {"second", "first"}},
+	})
+	for _, name := range []string{"first", "second"} {
+		r.Register(name, ProcessorFunc(func(_ context.Context, res *Result) error {
+			order = append(order, name+":"+res.Tool)
+			return nil
+		}))
+	}
+	_, err = r.Process(ctx, "solr.query", queryResponse())
+	assert.NoError(t, err)
+	_, err = r.Process(ctx, "solr.ping", map[string]any{})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"second:solr.query", "first:solr.query", "first:solr.ping"}, order)
+
+	// Goal: Unknown processors are skipped.
+	r = NewRegistry(config.PostProcessingConfig{Pipeline: []string{"missing", Summarize}})
+	res, err = r.Process(ctx, "solr.query", queryResponse())
+	assert.NoError(t, err)
+	assert.Contains(t, res.Output, "summary")
+}
+
+// TestBuiltins tests the built-in processors.
+func TestBuiltins(t *testing.T) {
+	ctx := context.Background()
+	r := NewRegistry(config.PostProcessingConfig{
+		Pipeline:        []string{Redact, Truncate, Summarize, Format},
+		RedactFields:    []string{"Email"},
+		MaxStringLength: 10,
+		MaxArrayLength:  2,
+	})
+	res, err := r.Process(ctx, "solr.query", queryResponse())
+	assert.NoError(t, err)
+	out := res.Output.(map[string]any)
+	docs := out["response"].(map[string]any)["docs"].([]any)
+
+	// Goal: redact replaces the values of configured keys case-insensitively.
+	assert.Equal(t, redactedValue, docs[0].(map[string]any)["email"])
+
+	// Goal: truncate shortens strings and arrays and marks the output.
+	assert.Equal(t, "xxxxxxxxxx…", docs[0].(map[string]any)["title"])
+	assert.Len(t, docs, 2)
+	assert.Equal(t, true, out["truncated"])
+
+	// Goal: redact and truncate explain their changes in guardrails.
+	guardrails := out["guardrails"].([]any)
+	require.Len(t, guardrails, 2)
+	assert.Equal(t, map[string]any{"action": "redact_fields", "target": "email", "reason": "postProcessing.redactFields of the server config hides these values"}, guardrails[0])
+	assert.Equal(t, "truncate_output", guardrails[1].(map[string]any)["action"])
+
+	// Goal: summarize reports numFound, the returned documents and their fields.
+	assert.Equal(t, map[string]any{
+		"numFound": float64(3),
+		"returned": 2,
+		"fields":   []string{"email", "id", "tags", "title"},
+	}, out["summary"])
+
+	// Goal: format renders the documents as a Markdown table.
+	assert.Equal(t, "numFound: 3, returned: 2\n\n"+
+		"| email | id | tags | title |\n"+
+		"| --- | --- | --- | --- |\n"+
+		"| [REDACTED] | 1 |  | xxxxxxxxxx… |\n"+
+		"|  | 2 | a, b |  |\n", res.Text)
+}
@@ -0,0 +1,255 @@
+// Package replay records the Solr requests of the server into a fixture file and replays them, so that a reported
+// query or planner bug can be reproduced exactly without access to the cluster it happened on.
+package replay
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Version is the format version of fixture files.
+const Version = 1
+
+// Fixture is the content of a fixture file.
+type Fixture struct {
+	Version      int           `json:"version"`
+	RecordedAt   time.Time     `json:"recordedAt"`
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction is a request and the response it received. Hosts and request headers are not recorded, so fixtures
+// hold no credentials and replay against any base URL.
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Request is a recorded request. URL is the path with the query parameters sorted by name.
+type Request struct {
+	Method string `json:"method"`
+	URL    string `json:"url"`
+	Body   string `json:"body,omitempty"`
+}
+
+// Response is a recorded response. JSON bodies are kept as JSON to make fixtures readable and editable,
+// others as a string in Body.
+type Response struct {
+	Status int               `json:"status"`
+	Header map[string]string `json:"header,omitempty"`
+	JSON   json.RawMessage   `json:"json,omitempty"`
+	Body   string            `json:"body,omitempty"`
+}
+
+// key identifies requests that replay the same interaction.
+func (r Request) key() string {
+	return r.Method + " " + r.URL + "\n" + r.Body
+}
+
+// Load reads a fixture file.
+func Load(path string) (*Fixture, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read fixture: %v", err)
+	}
+	var f Fixture
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("parse fixture %s: %v", path, err)
+	}
+	if f.Version != Version {
+		return nil, fmt.Errorf("fixture %s has version %d, expected %d", path, f.Version, Version)
+	}
+	return &f, nil
+}
+
+// Save writes f to path, replacing it atomically.
+func (f *Fixture) Save(path string) error {
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode fixture: %v", err)
+	}
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("create fixture dir: %v", err)
+		}
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("write fixture: %v", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("write fixture: %v", err)
+	}
+	return nil
+}
+
+// readRequest returns the recorded form of req. The body of req is replaced, so it can still be sent.
+func readRequest(req *http.Request) (Request, error) {
+	r := Request{Method: req.Method, URL: req.URL.EscapedPath()}
+	if q := req.URL.Query(); len(q) > 0 {
+		r.URL += "?" + q.Encode()
+	}
+	if req.Body != nil && req.Body != http.NoBody {
+		body, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return Request{}, fmt.Errorf("read request body: %v", err)
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		r.Body = string(body)
+	}
+	return r, nil
+}
+
+// Recorder is an http.RoundTripper that sends requests with Base and appends every interaction to the fixture
+// file at Path. The file is rewritten after each interaction, so it is complete whenever the server stops.
+type Recorder struct {
+	Base    http.RoundTripper
+	Path    string
+	mu      sync.Mutex
+	fixture Fixture
+}
+
+// NewRecorder returns a Recorder writing to path, starting a new fixture.
+func NewRecorder(path string, base http.RoundTripper) *Recorder {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &Recorder{Base: base, Path: path, fixture: Fixture{Version: Version, RecordedAt: time.Now().UTC(), Interactions: []Interaction{}}}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	return r.record(r.Base, req)
+}
+
+// Transport returns an http.RoundTripper that sends requests with base and records them into the fixture of r, so
+// clients with different transports share one fixture file.
+func (r *Recorder) Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		return r.record(base, req)
+	})
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func (r *Recorder) record(base http.RoundTripper, req *http.Request) (*http.Response, error) {
+	recorded, err := readRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	out := Response{Status: resp.StatusCode}
+	if ct := resp.Header.Get("Content-Type"); ct != "" {
+		out.Header = map[string]string{"Content-Type": ct}
+	}
+	if len(bytes.TrimSpace(body)) > 0 && json.Valid(body) {
+		out.JSON = json.RawMessage(bytes.TrimSpace(body))
+	} else {
+		out.Body = string(body)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.fixture.Interactions = append(r.fixture.Interactions, Interaction{Request: recorded, Response: out})
+	if err := r.fixture.Save(r.Path); err != nil {
+		slog.Error("Failed to save fixture", "path", r.Path, "error", err)
+	}
+	return resp, nil
+}
+
+// Player is an http.RoundTripper that answers requests from a fixture without any network access. Identical
+// requests receive their recorded responses in order, and the last one again once they are used up.
+// Requests that were not recorded fail.
+type Player struct {
+	mu      sync.Mutex
+	pending map[string][]Response
+}
+
+// NewPlayer returns a Player replaying f.
+func NewPlayer(f *Fixture) *Player {
+	p := &Player{pending: map[string][]Response{}}
+	for _, it := range f.Interactions {
+		k := it.Request.key()
+		p.pending[k] = append(p.pending[k], it.Response)
+	}
+	return p
+}
+
+// RoundTrip implements http.RoundTripper.
+func (p *Player) RoundTrip(req *http.Request) (*http.Response, error) {
+	recorded, err := readRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	k := recorded.key()
+	p.mu.Lock()
+	responses := p.pending[k]
+	if len(responses) == 0 {
+		p.mu.Unlock()
+		return nil, fmt.Errorf("replay: no recorded interaction for %s %s", recorded.Method, recorded.URL)
+	}
+	r := responses[0]
+	if len(responses) > 1 {
+		p.pending[k] = responses[1:]
+	}
+	p.mu.Unlock()
+
+	body := r.Body
+	if len(r.JSON) > 0 {
+		body = string(r.JSON)
+	}
+	header := http.Header{}
+	for k, v := range r.Header {
+		header.Set(k, v)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", r.Status, http.StatusText(r.Status)),
+		StatusCode:    r.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+// RecordingClient returns a copy of client that records its interactions to the fixture file at path.
+func RecordingClient(client *http.Client, path string) *http.Client {
+	c := *client
+	c.Transport = NewRecorder(path, client.Transport)
+	return &c
+}
+
+// PlayingClient returns a copy of client that answers from f.
+func PlayingClient(client *http.Client, f *Fixture) *http.Client {
+	c := *client
+	c.Transport = NewPlayer(f)
+	return &c
+}
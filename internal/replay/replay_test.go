@@ -0,0 +1,91 @@
+package replay
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRecordReplay tests recording interactions into a fixture and replaying them without the server.
+func TestRecordReplay(t *testing.T) {
+	numFound := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/solr/products/select":
+			numFound++
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprintf(w, `{"response":{"numFound":%d,"docs":[]}}`, numFound)
+		case "/solr/products/update":
+			body, _ := io.ReadAll(r.Body)
+			fmt.Fprintf(w, "received %s", body)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	path := filepath.Join(t.TempDir(), "fixtures", "products.json")
+	do := func(client *http.Client, method, url, body string) (int, string) {
+		req, err := http.NewRequest(method, url, strings.NewReader(body))
+		require.NoError(t, err)
+		req.SetBasicAuth("solr", "secret")
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		data, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp.StatusCode, string(data)
+	}
+
+	// Goal: Interactions are recorded while the responses still reach the caller.
+	client := RecordingClient(server.Client(), path)
+	_, body := do(client, http.MethodGet, server.URL+"/solr/products/select?rows=0&q=*:*", "")
+	assert.JSONEq(t, `{"response":{"numFound":1,"docs":[]}}`, body)
+	do(client, http.MethodGet, server.URL+"/solr/products/select?q=*:*&rows=0", "")
+	_, body = do(client, http.MethodPost, server.URL+"/solr/products/update", `[{"id":"1"}]`)
+	assert.Equal(t, `received [{"id":"1"}]`, body)
+	do(client, http.MethodGet, server.URL+"/solr/missing/select", "")
+	server.Close()
+
+	f, err := Load(path)
+	require.NoError(t, err)
+	require.Len(t, f.Interactions, 4)
+	assert.Equal(t, Request{Method: http.MethodGet, URL: "/solr/products/select?q=%2A%3A%2A&rows=0"}, f.Interactions[0].Request)
+	assert.JSONEq(t, `{"response":{"numFound":1,"docs":[]}}`, string(f.Interactions[0].Response.JSON))
+	assert.Equal(t, `received [{"id":"1"}]`, f.Interactions[2].Response.Body)
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "127.0.0.1", "hosts are not recorded")
+	assert.NotContains(t, string(data), "Basic", "credentials are not recorded")
+
+	// Goal: Identical requests replay their responses in order, against any host, and the last one again.
+	client = PlayingClient(&http.Client{}, f)
+	for _, want := range []int{1, 2, 2} {
+		_, body = do(client, http.MethodGet, "http://other:8983/solr/products/select?rows=0&q=*:*", "")
+		assert.JSONEq(t, fmt.Sprintf(`{"response":{"numFound":%d,"docs":[]}}`, want), body)
+	}
+	status, _ := do(client, http.MethodGet, "http://other:8983/solr/missing/select", "")
+	assert.Equal(t, http.StatusNotFound, status)
+
+	// Goal: Requests with other parameters or bodies were not recorded and fail.
+	_, err = client.Get("http://other:8983/solr/products/select?q=ipod")
+	assert.ErrorContains(t, err, "no recorded interaction for GET /solr/products/select?q=ipod")
+	_, err = client.Post("http://other:8983/solr/products/update", "application/json", strings.NewReader(`[{"id":"2"}]`))
+	assert.ErrorContains(t, err, "no recorded interaction")
+}
+
+// TestLoad tests rejecting unreadable fixtures.
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fixture.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"version":2,"interactions":[]}`), 0o644))
+	_, err := Load(path)
+	assert.ErrorContains(t, err, "has version 2, expected 1")
+	_, err = Load(filepath.Join(t.TempDir(), "missing.json"))
+	assert.ErrorContains(t, err, "read fixture")
+}
@@ -0,0 +1,290 @@
+// Package retention implements index lifecycle management: retention policies that delete
+// old documents or drop old time-partitioned collections, with dry-run previews and an audit trail.
+package retention
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/config"
+)
+
+// Action kinds.
+const (
+	KindDeleteDocuments = "delete_documents"
+	KindDropCollection  = "drop_collection"
+)
+
+// maxHistory is the number of runs kept in memory.
+const maxHistory = 20
+
+// Backend performs the Solr operations needed by retention policies.
+type Backend interface {
+	Count(ctx context.Context, collection, query string) (int64, error)
+	DeleteByQuery(ctx context.Context, collection, query string) error
+	ListCollections(ctx context.Context) ([]string, error)
+	DeleteCollection(ctx context.Context, name string) error
+}
+
+// Action is a single deletion planned or executed by a policy.
+type Action struct {
+	Policy     string    `json:"policy"`
+	Kind       string    `json:"kind"`
+	Collection string    `json:"collection"`
+	Query      string    `json:"query,omitempty"`
+	Cutoff     time.Time `json:"cutoff"`
+	Matched    int64     `json:"matched"` // documents removed by the action
+	Executed   bool      `json:"executed"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// Run is the record of one evaluation of the retention policies.
+type Run struct {
+	Started time.Time `json:"started"`
+	Trigger string    `json:"trigger"` // "tool" or "schedule"
+	DryRun  bool      `json:"dryRun"`
+	Actions []Action  `json:"actions"`
+}
+
+// Manager evaluates retention policies against a Backend.
+type Manager struct {
+	mu      sync.RWMutex
+	cfg     config.RetentionConfig
+	backend Backend
+	history []Run
+	now     func() time.Time
+}
+
+// NewManager creates a Manager for the given configuration.
+func NewManager(cfg config.RetentionConfig, backend Backend) *Manager {
+	return &Manager{cfg: cfg, backend: backend, now: time.Now}
+}
+
+// SetConfig replaces the configuration, e.g. after a config reload.
+func (m *Manager) SetConfig(cfg config.RetentionConfig) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.cfg = cfg
+}
+
+// Policies returns the configured policies.
+func (m *Manager) Policies() []config.RetentionPolicy {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.cfg.Policies
+}
+
+// History returns the most recent runs, newest first.
+func (m *Manager) History() []Run {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	out := make([]Run, len(m.history))
+	for i, r := range m.history {
+		out[len(m.history)-1-i] = r
+	}
+	return out
+}
+
+// Preview plans the actions of the named policy, or of all policies when name is empty, without deleting anything.
+func (m *Manager) Preview(ctx context.Context, name string) (Run, error) {
+	return m.run(ctx, name, "tool", true)
+}
+
+// Execute plans and executes the actions of the named policy, or of all policies when name is empty.
+func (m *Manager) Execute(ctx context.Context, name, trigger string) (Run, error) {
+	return m.run(ctx, name, trigger, false)
+}
+
+// RunScheduled executes all policies on the configured interval until ctx is done.
+// It returns immediately when no interval is configured.
+func (m *Manager) RunScheduled(ctx context.Context) {
+	m.mu.RLock()
+	interval, err := time.ParseDuration(m.cfg.Interval)
+	m.mu.RUnlock()
+	if err != nil || interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := m.Execute(ctx, "", "schedule"); err != nil {
+				slog.Error("Scheduled retention run failed", "error", err)
+			}
+		}
+	}
+}
+
+func (m *Manager) run(ctx context.Context, name, trigger string, dryRun bool) (Run, error) {
+	m.mu.RLock()
+	cfg := m.cfg
+	m.mu.RUnlock()
+
+	var policies []config.RetentionPolicy
+	for _, p := range cfg.Policies {
+		if name == "" || p.Name == name {
+			policies = append(policies, p)
+		}
+	}
+	if len(policies) == 0 {
+		if name != "" {
+			return Run{}, fmt.Errorf("retention policy %q not found", name)
+		}
+		return Run{}, fmt.Errorf("no retention policies configured")
+	}
+
+	run := Run{Started: m.now(), Trigger: trigger, DryRun: dryRun, Actions: []Action{}}
+	for _, p := range policies {
+		actions, err := m.plan(ctx, p, run.Started)
+		if err != nil {
+			run.Actions = append(run.Actions, Action{Policy: p.Name, Error: err.Error()})
+			continue
+		}
+		for _, a := range actions {
+			if !dryRun && a.Error == "" {
+				m.execute(ctx, &a)
+			}
+			run.Actions = append(run.Actions, a)
+		}
+	}
+
+	m.audit(cfg.AuditFile, run)
+	m.mu.Lock()
+	m.history = append(m.history, run)
+	if len(m.history) > maxHistory {
+		m.history = m.history[len(m.history)-maxHistory:]
+	}
+	m.mu.Unlock()
+	return run, nil
+}
+
+// plan determines the actions of a policy at the given time.
+func (m *Manager) plan(ctx context.Context, p config.RetentionPolicy, now time.Time) ([]Action, error) {
+	age, err := config.ParseAge(p.MaxAge)
+	if err != nil {
+		return nil, err
+	}
+	cutoff := now.Add(-age).UTC().Truncate(time.Second)
+
+	if p.Collection != "" {
+		a := Action{
+			Policy:     p.Name,
+			Kind:       KindDeleteDocuments,
+			Collection: p.Collection,
+			Query:      fmt.Sprintf("%s:[* TO %s}", p.DateField, cutoff.Format(time.RFC3339)),
+			Cutoff:     cutoff,
+		}
+		n, err := m.backend.Count(ctx, a.Collection, a.Query)
+		if err != nil {
+			a.Error = err.Error()
+		}
+		a.Matched = n
+		if n == 0 && err == nil {
+			return nil, nil
+		}
+		return []Action{a}, nil
+	}
+
+	names, err := m.backend.ListCollections(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var actions []Action
+	for _, c := range ExpiredCollections(names, p.CollectionPrefix, p.Layout(), cutoff) {
+		a := Action{Policy: p.Name, Kind: KindDropCollection, Collection: c, Cutoff: cutoff}
+		n, err := m.backend.Count(ctx, c, "*:*")
+		if err != nil {
+			a.Error = err.Error()
+		}
+		a.Matched = n
+		actions = append(actions, a)
+	}
+	return actions, nil
+}
+
+func (m *Manager) execute(ctx context.Context, a *Action) {
+	var err error
+	switch a.Kind {
+	case KindDeleteDocuments:
+		err = m.backend.DeleteByQuery(ctx, a.Collection, a.Query)
+	case KindDropCollection:
+		err = m.backend.DeleteCollection(ctx, a.Collection)
+	}
+	if err != nil {
+		a.Error = err.Error()
+		return
+	}
+	a.Executed = true
+}
+
+// ExpiredCollections returns the collections named prefix followed by a date in layout whose data is
+// entirely older than cutoff. A collection is only expired once the next collection starts before
+// cutoff, and the newest collection is never expired.
+func ExpiredCollections(names []string, prefix, layout string, cutoff time.Time) []string {
+	type dated struct {
+		name  string
+		start time.Time
+	}
+	var cols []dated
+	for _, n := range names {
+		suffix, ok := strings.CutPrefix(n, prefix)
+		if !ok {
+			continue
+		}
+		start, err := time.Parse(layout, suffix)
+		if err != nil {
+			continue
+		}
+		cols = append(cols, dated{n, start})
+	}
+	sort.Slice(cols, func(i, j int) bool { return cols[i].start.Before(cols[j].start) })
+
+	var expired []string
+	for i := 0; i+1 < len(cols); i++ {
+		if !cols[i+1].start.After(cutoff) {
+			expired = append(expired, cols[i].name)
+		}
+	}
+	return expired
+}
+
+// audit logs every action of a run and appends it to the audit file if one is configured.
+func (m *Manager) audit(path string, run Run) {
+	var f *os.File
+	if path != "" {
+		var err error
+		f, err = os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+		if err != nil {
+			slog.Error("Failed to open retention audit file", "path", path, "error", err)
+		} else {
+			defer f.Close()
+		}
+	}
+	for _, a := range run.Actions {
+		slog.Info("Retention audit", "trigger", run.Trigger, "dry_run", run.DryRun, "policy", a.Policy, "kind", a.Kind,
+			"collection", a.Collection, "query", a.Query, "matched", a.Matched, "executed", a.Executed, "error", a.Error)
+		if f == nil {
+			continue
+		}
+		record := struct {
+			Time    time.Time `json:"time"`
+			Trigger string    `json:"trigger"`
+			DryRun  bool      `json:"dryRun"`
+			Action
+		}{run.Started, run.Trigger, run.DryRun, a}
+		line, _ := json.Marshal(record)
+		if _, err := f.Write(append(line, '\n')); err != nil {
+			slog.Error("Failed to write retention audit file", "path", path, "error", err)
+		}
+	}
+}
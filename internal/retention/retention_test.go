@@ -0,0 +1,133 @@
+package retention
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/config"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeBackend records deletions and answers counts from a fixed map.
+type fakeBackend struct {
+	counts      map[string]int64 // collection -> numFound
+	collections []string
+	deletedDocs []string
+	dropped     []string
+	dropErr     error
+}
+
+func (b *fakeBackend) Count(_ context.Context, collection, _ string) (int64, error) {
+	return b.counts[collection], nil
+}
+
+func (b *fakeBackend) DeleteByQuery(_ context.Context, collection, query string) error {
+	b.deletedDocs = append(b.deletedDocs, collection+" "+query)
+	return nil
+}
+
+func (b *fakeBackend) ListCollections(context.Context) ([]string, error) {
+	return b.collections, nil
+}
+
+func (b *fakeBackend) DeleteCollection(_ context.Context, name string) error {
+	if b.dropErr != nil {
+		return b.dropErr
+	}
+	b.dropped = append(b.dropped, name)
+	return nil
+}
+
+func newTestManager(backend *fakeBackend, auditFile string) *Manager {
+	m := NewManager(config.RetentionConfig{
+		AuditFile: auditFile,
+		Policies: []config.RetentionPolicy{
+			{Name: "app_logs", Collection: "logs", DateField: "timestamp", MaxAge: "30d"},
+			{Name: "daily", CollectionPrefix: "events_", MaxAge: "2d"},
+		},
+	}, backend)
+	m.now = func() time.Time { return time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC) }
+	return m
+}
+
+// TestPreview tests that a preview plans actions without deleting anything.
+func TestPreview(t *testing.T) {
+	backend := &fakeBackend{
+		counts:      map[string]int64{"logs": 120, "events_2024-03-06": 5, "events_2024-03-07": 6},
+		collections: []string{"logs", "events_2024-03-06", "events_2024-03-07", "events_2024-03-08", "events_2024-03-09", "events_2024-03-10"},
+	}
+	m := newTestManager(backend, "")
+
+	run, err := m.Preview(context.Background(), "")
+
+	// Goal: Documents older than 30 days and collections whose data is older than 2 days are planned.
+	assert.NoError(t, err)
+	assert.True(t, run.DryRun)
+	assert.Len(t, run.Actions, 3)
+	assert.Equal(t, Action{
+		Policy: "app_logs", Kind: KindDeleteDocuments, Collection: "logs",
+		Query:   "timestamp:[* TO 2024-02-09T12:00:00Z}",
+		Cutoff:  time.Date(2024, 2, 9, 12, 0, 0, 0, time.UTC),
+		Matched: 120,
+	}, run.Actions[0])
+	assert.Equal(t, "events_2024-03-06", run.Actions[1].Collection)
+	assert.Equal(t, "events_2024-03-07", run.Actions[2].Collection)
+
+	// Goal: Nothing is deleted and the run is recorded.
+	assert.Empty(t, backend.deletedDocs)
+	assert.Empty(t, backend.dropped)
+	assert.Len(t, m.History(), 1)
+}
+
+// TestExecute tests that executed runs delete data and write the audit file.
+func TestExecute(t *testing.T) {
+	backend := &fakeBackend{
+		counts:      map[string]int64{"logs": 3},
+		collections: []string{"events_2024-03-01", "events_2024-03-05", "events_2024-03-10"},
+		dropErr:     errors.New("collection is part of an alias"),
+	}
+	auditFile := filepath.Join(t.TempDir(), "audit.jsonl")
+	m := newTestManager(backend, auditFile)
+
+	run, err := m.Execute(context.Background(), "", "tool")
+
+	// Goal: Delete-by-query runs, and a failed drop is reported on its action.
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"logs timestamp:[* TO 2024-02-09T12:00:00Z}"}, backend.deletedDocs)
+	assert.True(t, run.Actions[0].Executed)
+	assert.False(t, run.Actions[1].Executed)
+	assert.Contains(t, run.Actions[1].Error, "part of an alias")
+
+	// Goal: Every action is appended to the audit file as a JSON line.
+	data, err := os.ReadFile(auditFile)
+	assert.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	assert.Len(t, lines, 2)
+	var record map[string]any
+	assert.NoError(t, json.Unmarshal([]byte(lines[0]), &record))
+	assert.Equal(t, "app_logs", record["policy"])
+	assert.Equal(t, false, record["dryRun"])
+
+	// Goal: Unknown policies are rejected.
+	_, err = m.Execute(context.Background(), "missing", "tool")
+	assert.Error(t, err)
+}
+
+// TestExpiredCollections tests selection of time-partitioned collections.
+func TestExpiredCollections(t *testing.T) {
+	names := []string{"logs__TRA__2024-03-09", "logs__TRA__2024-03-01", "logs__TRA__2024-03-05", "other", "logs__TRA__bad"}
+	cutoff := time.Date(2024, 3, 6, 0, 0, 0, 0, time.UTC)
+
+	// Goal: Only collections followed by one starting before the cutoff expire; the newest never does.
+	assert.Equal(t, []string{"logs__TRA__2024-03-01"}, ExpiredCollections(names, "logs__TRA__", "2006-01-02", cutoff))
+	assert.Empty(t, ExpiredCollections(names, "logs__TRA__", "2006-01-02", time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)))
+	assert.Equal(t, []string{"logs__TRA__2024-03-01", "logs__TRA__2024-03-05"},
+		ExpiredCollections(names, "logs__TRA__", "2006-01-02", time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)))
+}
@@ -0,0 +1,273 @@
+// Package routing spreads read traffic over the nodes of a Solr cluster. It tracks an exponentially weighted
+// moving average (EWMA) of the latency and error rate of each node and prefers the fastest healthy one.
+package routing
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"net/url"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/config"
+)
+
+const (
+	// decay is the weight of a new sample in the moving averages.
+	decay = 0.2
+	// unhealthyErrorRate is the error rate from which a node only receives traffic when no node is healthy.
+	unhealthyErrorRate = 0.5
+	// retryAfter is how long an unhealthy node is avoided before it gets a request again.
+	retryAfter = 30 * time.Second
+	// DefaultExplore is the share of requests sent to a random healthy node, so the latency of nodes
+	// that are not preferred is still measured.
+	DefaultExplore = 0.05
+)
+
+// NodeStats describes the observed performance of a node.
+type NodeStats struct {
+	URL       string    `json:"url"`
+	Requests  int64     `json:"requests"`
+	Errors    int64     `json:"errors"`
+	LatencyMs float64   `json:"latencyMs"` // EWMA of successful requests
+	ErrorRate float64   `json:"errorRate"` // EWMA of unavailability errors, from 0 to 1
+	Healthy   bool      `json:"healthy"`
+	Preferred bool      `json:"preferred"`
+	LastError string    `json:"lastError,omitempty"`
+	LastSeen  time.Time `json:"lastSeen,omitzero"`
+}
+
+type node struct {
+	url       string
+	requests  int64
+	errors    int64
+	latency   float64 // milliseconds, 0 until the first success
+	errorRate float64
+	lastError string
+	lastSeen  time.Time
+	failedAt  time.Time
+}
+
+func (n *node) healthy(now time.Time) bool {
+	return n.errorRate < unhealthyErrorRate || now.Sub(n.failedAt) >= retryAfter
+}
+
+// Router picks the node of each read request. It is safe for concurrent use.
+type Router struct {
+	// Explore is the share of requests sent to a random healthy node instead of the fastest one.
+	Explore float64
+
+	mu    sync.Mutex
+	nodes []*node
+}
+
+// New creates a Router over the base URLs of the nodes of one cluster.
+func New(urls []string) *Router {
+	r := &Router{Explore: DefaultExplore}
+	r.SetNodes(urls)
+	return r
+}
+
+// SetNodes replaces the nodes, e.g. after discovering live nodes. The statistics of kept nodes are preserved.
+func (r *Router) SetNodes(urls []string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	old := make(map[string]*node, len(r.nodes))
+	for _, n := range r.nodes {
+		old[n.url] = n
+	}
+	nodes := make([]*node, 0, len(urls))
+	for _, u := range urls {
+		u = strings.TrimRight(u, "/")
+		if u == "" || slices.ContainsFunc(nodes, func(n *node) bool { return n.url == u }) {
+			continue
+		}
+		if n, ok := old[u]; ok {
+			nodes = append(nodes, n)
+		} else {
+			nodes = append(nodes, &node{url: u})
+		}
+	}
+	r.nodes = nodes
+}
+
+// Pick returns the base URL of the node for the next request. Nodes without measurements are tried first, then
+// the healthy node with the lowest latency, penalized by its error rate, is preferred. When no node is healthy,
+// the one with the lowest error rate is returned. exclude lists nodes that already failed the request.
+func (r *Router) Pick(exclude ...string) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var candidates []*node
+	for _, n := range r.nodes {
+		if !slices.Contains(exclude, n.url) {
+			candidates = append(candidates, n)
+		}
+	}
+	if len(candidates) == 0 {
+		return ""
+	}
+	now := time.Now()
+	var healthy []*node
+	for _, n := range candidates {
+		if n.healthy(now) {
+			healthy = append(healthy, n)
+		}
+	}
+	if len(healthy) == 0 {
+		best := candidates[0]
+		for _, n := range candidates[1:] {
+			if n.errorRate < best.errorRate {
+				best = n
+			}
+		}
+		return best.url
+	}
+	if len(healthy) > 1 && r.Explore > 0 && rand.Float64() < r.Explore {
+		return healthy[rand.IntN(len(healthy))].url
+	}
+	return preferred(healthy).url
+}
+
+// score is the expected cost of a request to the node: its latency, penalized by its error rate.
+// Nodes that never answered come last.
+func (n *node) score() float64 {
+	if n.latency == 0 {
+		return math.Inf(1)
+	}
+	return n.latency / max(1-n.errorRate, 0.01)
+}
+
+// preferred returns the node without measurements or, if all are measured, the node with the lowest score.
+func preferred(nodes []*node) *node {
+	best := nodes[0]
+	for _, n := range nodes[1:] {
+		if best.requests == 0 {
+			break
+		}
+		if n.requests == 0 || n.score() < best.score() {
+			best = n
+		}
+	}
+	return best
+}
+
+// Report records the outcome of a request to the node at baseURL. err is nil for requests the node answered,
+// including rejected ones; only unavailability errors count against the node.
+func (r *Router) Report(baseURL string, d time.Duration, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	i := slices.IndexFunc(r.nodes, func(n *node) bool { return n.url == baseURL })
+	if i < 0 {
+		return
+	}
+	n := r.nodes[i]
+	n.requests++
+	n.lastSeen = time.Now()
+	if err != nil {
+		n.errors++
+		n.errorRate = n.errorRate*(1-decay) + decay
+		n.lastError = err.Error()
+		if n.errorRate >= unhealthyErrorRate {
+			n.failedAt = n.lastSeen
+		}
+		return
+	}
+	n.errorRate *= 1 - decay
+	ms := float64(d) / float64(time.Millisecond)
+	if n.latency == 0 {
+		n.latency = ms
+	} else {
+		n.latency = n.latency*(1-decay) + ms*decay
+	}
+}
+
+// Stats returns the statistics of the nodes in configuration order. URLs are redacted.
+func (r *Router) Stats() []NodeStats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	var best *node
+	var healthy []*node
+	for _, n := range r.nodes {
+		if n.healthy(now) {
+			healthy = append(healthy, n)
+		}
+	}
+	if len(healthy) > 0 {
+		best = preferred(healthy)
+	}
+	out := make([]NodeStats, len(r.nodes))
+	for i, n := range r.nodes {
+		out[i] = NodeStats{
+			URL:       config.RedactURL(n.url),
+			Requests:  n.requests,
+			Errors:    n.errors,
+			LatencyMs: n.latency,
+			ErrorRate: n.errorRate,
+			Healthy:   n.healthy(now),
+			Preferred: n == best,
+			LastError: n.lastError,
+			LastSeen:  n.lastSeen,
+		}
+	}
+	return out
+}
+
+// Render returns the node statistics in the Prometheus text exposition format.
+func (r *Router) Render() string {
+	stats := r.Stats()
+	var b strings.Builder
+	gauges := []struct {
+		name, help string
+		value      func(NodeStats) float64
+	}{
+		{"solr_mcp_node_latency_ms", "Moving average of the latency of read requests to the Solr node.", func(s NodeStats) float64 { return s.LatencyMs }},
+		{"solr_mcp_node_error_rate", "Moving average of the share of read requests the Solr node could not serve.", func(s NodeStats) float64 { return s.ErrorRate }},
+		{"solr_mcp_node_healthy", "Whether the Solr node receives read requests.", func(s NodeStats) float64 { return boolValue(s.Healthy) }},
+		{"solr_mcp_node_preferred", "Whether the Solr node is the preferred node for read requests.", func(s NodeStats) float64 { return boolValue(s.Preferred) }},
+	}
+	for _, g := range gauges {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s gauge\n", g.name, g.help, g.name)
+		for _, s := range stats {
+			fmt.Fprintf(&b, "%s{node=%q} %g\n", g.name, s.URL, g.value(s))
+		}
+	}
+	b.WriteString("# HELP solr_mcp_node_requests_total Read requests sent to the Solr node.\n# TYPE solr_mcp_node_requests_total counter\n")
+	for _, s := range stats {
+		fmt.Fprintf(&b, "solr_mcp_node_requests_total{node=%q} %d\n", s.URL, s.Requests)
+	}
+	b.WriteString("# HELP solr_mcp_node_errors_total Read requests the Solr node could not serve.\n# TYPE solr_mcp_node_errors_total counter\n")
+	for _, s := range stats {
+		fmt.Fprintf(&b, "solr_mcp_node_errors_total{node=%q} %d\n", s.URL, s.Errors)
+	}
+	return b.String()
+}
+
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// LiveNodeURLs converts the live_nodes of CLUSTERSTATUS, e.g. "10.0.0.1:8983_solr", to base URLs
+// with the scheme of baseURL. The result is sorted.
+func LiveNodeURLs(baseURL string, liveNodes []string) []string {
+	scheme := "http"
+	if u, err := url.Parse(baseURL); err == nil && u.Scheme != "" {
+		scheme = u.Scheme
+	}
+	urls := make([]string, 0, len(liveNodes))
+	for _, n := range liveNodes {
+		host, _, _ := strings.Cut(n, "_")
+		if host != "" {
+			urls = append(urls, scheme+"://"+host)
+		}
+	}
+	sort.Strings(urls)
+	return urls
+}
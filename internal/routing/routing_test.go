@@ -0,0 +1,50 @@
+package routing
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPick tests that the fastest healthy node is preferred.
+func TestPick(t *testing.T) {
+	r := New([]string{"http://a:8983", "http://b:8983/", "http://a:8983"})
+	r.Explore = 0
+	require.Len(t, r.Stats(), 2)
+
+	// Goal: Nodes without measurements are tried first.
+	assert.Equal(t, "http://a:8983", r.Pick())
+	r.Report("http://a:8983", 80*time.Millisecond, nil)
+	assert.Equal(t, "http://b:8983", r.Pick())
+	r.Report("http://b:8983", 20*time.Millisecond, nil)
+
+	// Goal: The node with the lower latency average is preferred.
+	assert.Equal(t, "http://b:8983", r.Pick())
+	assert.True(t, r.Stats()[1].Preferred)
+
+	// Goal: Repeated unavailability makes a node unhealthy until the others fail too.
+	for range 4 {
+		r.Report("http://b:8983", time.Second, errors.New("HTTP request error: connection refused"))
+	}
+	assert.Equal(t, "http://a:8983", r.Pick())
+	stats := r.Stats()
+	assert.False(t, stats[1].Healthy)
+	assert.Equal(t, int64(4), stats[1].Errors)
+	assert.Equal(t, 20.0, stats[1].LatencyMs)
+	assert.Equal(t, "http://b:8983", r.Pick("http://a:8983"))
+	assert.Equal(t, "", New(nil).Pick())
+
+	// Goal: Statistics of kept nodes survive a node update.
+	r.SetNodes([]string{"http://b:8983", "http://c:8983"})
+	assert.Equal(t, int64(5), r.Stats()[0].Requests)
+	assert.Contains(t, r.Render(), `solr_mcp_node_healthy{node="http://b:8983"} 0`)
+}
+
+// TestLiveNodeURLs tests the conversion of live node names to base URLs.
+func TestLiveNodeURLs(t *testing.T) {
+	assert.Equal(t, []string{"https://10.0.0.1:8983", "https://10.0.0.2:8983"},
+		LiveNodeURLs("https://solr:8983", []string{"10.0.0.2:8983_solr", "10.0.0.1:8983_solr"}))
+}
@@ -0,0 +1,143 @@
+package server
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/errcatalog"
+	"github.com/Sashimimochi/solr-mcp-go/internal/utils"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// SetReadOnly enables or disables read-only mode, in which write tools are refused.
+func (st *State) SetReadOnly(enabled bool) {
+	st.readOnly.Store(enabled)
+	slog.Info("Read-only mode changed", "enabled", enabled)
+}
+
+// SetDraining enables or disables draining, in which new MCP sessions are refused while existing ones continue.
+func (st *State) SetDraining(enabled bool) {
+	st.draining.Store(enabled)
+	slog.Info("Draining changed", "enabled", enabled)
+}
+
+// readOnlyError refuses write tools in read-only mode.
+func (st *State) readOnlyError(tool string) error {
+	if writeTools[tool] && st.readOnly.Load() {
+		return errcatalog.New(errcatalog.ReadOnly, "%s is disabled: the server is in read-only mode", tool)
+	}
+	return nil
+}
+
+// drainHandler refuses requests that would start a new MCP session while the server is draining.
+func (st *State) drainHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if st.draining.Load() && r.Header.Get("Mcp-Session-Id") == "" {
+			w.Header().Set("Retry-After", "5")
+			http.Error(w, "server is draining, retry on another instance", http.StatusServiceUnavailable)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// AdminHandler serves the operator control API. Every request needs "Authorization: Bearer <token>".
+//
+//	GET    /admin/status       draining, read-only mode, open MCP sessions and the use of the memory budget
+//	POST   /admin/cache/flush  empty the schema, field value and collection list caches
+//	PUT    /admin/read-only    {"enabled": bool} toggles read-only mode
+//	GET    /admin/sessions     open MCP sessions with client, age, activity and tool calls
+//	DELETE /admin/sessions/ID  terminate a session
+//	POST   /admin/drain        refuse new MCP sessions
+//	DELETE /admin/drain        accept new MCP sessions again
+func (st *State) AdminHandler(mcpServer *mcp.Server, token string) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /admin/status", func(w http.ResponseWriter, r *http.Request) {
+		writeAdminJSON(w, st.adminStatus(mcpServer))
+	})
+	mux.HandleFunc("GET /admin/sessions", func(w http.ResponseWriter, r *http.Request) {
+		writeAdminJSON(w, map[string]any{"sessions": st.SessionInfos()})
+	})
+	mux.HandleFunc("DELETE /admin/sessions/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if err := st.TerminateSession(r.PathValue("id")); err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		writeAdminJSON(w, map[string]any{"terminated": r.PathValue("id")})
+	})
+	mux.HandleFunc("POST /admin/cache/flush", func(w http.ResponseWriter, r *http.Request) {
+		n := st.SchemaCache.Flush() + st.flushTopValues() + st.invalidateCollections()
+		slog.Info("Caches flushed", "entries", n)
+		writeAdminJSON(w, map[string]any{"flushed": n})
+	})
+	mux.HandleFunc("PUT /admin/read-only", func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Enabled *bool `json:"enabled"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.Enabled == nil {
+			http.Error(w, `body must be {"enabled": true|false}`, http.StatusBadRequest)
+			return
+		}
+		st.SetReadOnly(*body.Enabled)
+		writeAdminJSON(w, st.adminStatus(mcpServer))
+	})
+	mux.HandleFunc("POST /admin/drain", func(w http.ResponseWriter, r *http.Request) {
+		st.SetDraining(true)
+		writeAdminJSON(w, st.adminStatus(mcpServer))
+	})
+	mux.HandleFunc("DELETE /admin/drain", func(w http.ResponseWriter, r *http.Request) {
+		st.SetDraining(false)
+		writeAdminJSON(w, st.adminStatus(mcpServer))
+	})
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="solr-mcp-go admin"`)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		mux.ServeHTTP(w, r)
+	})
+}
+
+// startAdmin serves the control API on AdminAddr in the background. It is not started without AdminToken.
+func (st *State) startAdmin(mcpServer *mcp.Server) {
+	if st.AdminAddr == "" {
+		return
+	}
+	if st.AdminToken == "" {
+		slog.Error("Admin API not started: SOLR_MCP_ADMIN_TOKEN is not set", "address", st.AdminAddr)
+		return
+	}
+	slog.Info("Admin API listening", "address", st.AdminAddr)
+	go func() {
+		if err := http.ListenAndServe(st.AdminAddr, utils.LoggingHandler(st.AdminHandler(mcpServer, st.AdminToken))); err != nil {
+			slog.Error("Error running admin API", "error", err)
+		}
+	}()
+}
+
+func (st *State) adminStatus(mcpServer *mcp.Server) map[string]any {
+	sessions := []string{}
+	for s := range mcpServer.Sessions() {
+		sessions = append(sessions, s.ID())
+	}
+	return map[string]any{
+		"draining": st.draining.Load(),
+		"readOnly": st.readOnly.Load(),
+		"sessions": sessions,
+		"memory":   st.memoryGovernor().Stats(),
+	}
+}
+
+func writeAdminJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		slog.Error("Failed to encode admin response", "error", err)
+	}
+}
@@ -0,0 +1,105 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/types"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestAdminHandler tests the operator control API.
+func TestAdminHandler(t *testing.T) {
+	st := NewServer(WithSolrURL("http://127.0.0.1:1"), WithHTTPClient(&http.Client{}))
+	mcpServer := mcp.NewServer(&mcp.Implementation{}, nil)
+	AddTools(mcpServer, st)
+	session, _, _ := connectTestClient(t, mcpServer)
+	admin := httptest.NewServer(st.AdminHandler(mcpServer, "s3cret"))
+	defer admin.Close()
+
+	call := func(method, path, token, body string) (int, map[string]any) {
+		req, err := http.NewRequest(method, admin.URL+path, strings.NewReader(body))
+		require.NoError(t, err)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		var out map[string]any
+		json.NewDecoder(resp.Body).Decode(&out)
+		return resp.StatusCode, out
+	}
+
+	// Goal: Requests without the admin token are rejected.
+	code, _ := call(http.MethodGet, "/admin/status", "", "")
+	assert.Equal(t, http.StatusUnauthorized, code)
+	code, _ = call(http.MethodGet, "/admin/status", "wrong", "")
+	assert.Equal(t, http.StatusUnauthorized, code)
+
+	// Goal: The status lists the open MCP sessions.
+	code, out := call(http.MethodGet, "/admin/status", "s3cret", "")
+	require.Equal(t, http.StatusOK, code)
+	assert.Len(t, out["sessions"], 1)
+	assert.Equal(t, false, out["readOnly"])
+
+	// Goal: Flushing empties the schema cache.
+	st.SchemaCache.Set("logs", &types.FieldCatalog{})
+	code, out = call(http.MethodPost, "/admin/cache/flush", "s3cret", "")
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, float64(1), out["flushed"])
+	_, ok := st.SchemaCache.Get("logs")
+	assert.False(t, ok)
+
+	// Goal: Read-only mode refuses write tools but not read tools.
+	code, out = call(http.MethodPut, "/admin/read-only", "s3cret", `{"enabled":true}`)
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, true, out["readOnly"])
+	res, err := session.CallTool(context.Background(), &mcp.CallToolParams{Name: "solr.delete", Arguments: map[string]any{"collection": "logs", "query": "*:*"}})
+	require.NoError(t, err)
+	require.True(t, res.IsError)
+	assert.Contains(t, res.Content[0].(*mcp.TextContent).Text, "read-only mode")
+	res, err = session.CallTool(context.Background(), &mcp.CallToolParams{Name: "solr.info", Arguments: map[string]any{}})
+	require.NoError(t, err)
+	assert.False(t, res.IsError)
+
+	code, _ = call(http.MethodPut, "/admin/read-only", "s3cret", `{}`)
+	assert.Equal(t, http.StatusBadRequest, code)
+	call(http.MethodPut, "/admin/read-only", "s3cret", `{"enabled":false}`)
+	assert.NoError(t, st.readOnlyError("solr.delete"))
+}
+
+// TestDrainHandler tests that draining refuses new MCP sessions but keeps serving existing ones.
+func TestDrainHandler(t *testing.T) {
+	st := NewServer()
+	h := st.drainHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	request := func(sessionID string) int {
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		if sessionID != "" {
+			req.Header.Set("Mcp-Session-Id", sessionID)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	// Goal: Requests pass through while not draining.
+	assert.Equal(t, http.StatusOK, request(""))
+
+	// Goal: New sessions get 503 while draining; existing sessions continue.
+	st.SetDraining(true)
+	assert.Equal(t, http.StatusServiceUnavailable, request(""))
+	assert.Equal(t, http.StatusOK, request("abc"))
+
+	st.SetDraining(false)
+	assert.Equal(t, http.StatusOK, request(""))
+}
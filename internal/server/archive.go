@@ -0,0 +1,130 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/solr"
+	"github.com/Sashimimochi/solr-mcp-go/internal/types"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// defaultArchiveMaxDocs limits how many documents a single archive or restore call moves.
+const defaultArchiveMaxDocs = 10000
+
+// requireUniqueKey returns the uniqueKey of a collection, or a CapabilityError if it has none.
+func (st *State) requireUniqueKey(ctx context.Context, collection string) (string, error) {
+	fc, err := st.backend().Schema(ctx, collection)
+	if err != nil {
+		return "", fmt.Errorf("failed to get schema: %v", err)
+	}
+	key, err := fc.RequireUniqueKey()
+	if err != nil {
+		return "", &CapabilityError{
+			Capability: "unique_key",
+			Reason:     fmt.Sprintf("collection %s has no uniqueKey", collection),
+			Hint:       "Documents can only be addressed by ID in collections whose schema defines a uniqueKey.",
+		}
+	}
+	return key, nil
+}
+
+func (st *State) toolArchive(ctx context.Context, _ *mcp.CallToolRequest, in types.ArchiveIn) (*mcp.CallToolResult, any, error) {
+	token := in.Confirm
+	in.Confirm = ""
+	if token == "" {
+		preview, err := st.archivePreview(ctx, in)
+		if err != nil {
+			return nil, nil, err
+		}
+		out, err := st.confirmationRequired("solr.archive", in, preview)
+		return nil, out, err
+	}
+	if err := st.confirmStore().consume(token, "solr.archive", in); err != nil {
+		return nil, nil, err
+	}
+	return st.moveDocuments(ctx, in, false)
+}
+
+func (st *State) toolArchiveRestore(ctx context.Context, _ *mcp.CallToolRequest, in types.ArchiveIn) (*mcp.CallToolResult, any, error) {
+	return st.moveDocuments(ctx, in, true)
+}
+
+// moveSides validates an archive or restore request and returns the source and target collections and the
+// maximum number of documents to move.
+func moveSides(in types.ArchiveIn, restore bool) (string, string, int64, error) {
+	if strings.TrimSpace(in.Collection) == "" {
+		return "", "", 0, errors.New("input.collection is required")
+	}
+	if strings.TrimSpace(in.Query) == "" {
+		return "", "", 0, errors.New("input.query is required")
+	}
+	archive := in.ArchiveCollection
+	if archive == "" {
+		archive = in.Collection + "_archive"
+	}
+	maxDocs := int64(defaultArchiveMaxDocs)
+	if in.MaxDocs != nil {
+		maxDocs = int64(*in.MaxDocs)
+	}
+	if restore {
+		return archive, in.Collection, maxDocs, nil
+	}
+	return in.Collection, archive, maxDocs, nil
+}
+
+// archivePreview counts and samples the documents an archive call would move.
+func (st *State) archivePreview(ctx context.Context, in types.ArchiveIn) (map[string]any, error) {
+	src, dst, maxDocs, err := moveSides(in, false)
+	if err != nil {
+		return nil, err
+	}
+	key, err := st.requireUniqueKey(ctx, src)
+	if err != nil {
+		return nil, err
+	}
+	matched, err := st.backend().Count(ctx, src, in.Query)
+	if err != nil {
+		return nil, err
+	}
+	sample, err := solr.SampleIDs(ctx, st.backend(), src, in.Query, key, previewSampleSize)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{
+		"collection":        src,
+		"archiveCollection": dst,
+		"query":             in.Query,
+		"matched":           matched,
+		"maxDocs":           maxDocs,
+		"sampleIds":         sample,
+	}, nil
+}
+
+// moveDocuments moves documents from the collection to its archive, or back when restore is set.
+func (st *State) moveDocuments(ctx context.Context, in types.ArchiveIn, restore bool) (*mcp.CallToolResult, any, error) {
+	src, dst, maxDocs, err := moveSides(in, restore)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	key, err := st.requireUniqueKey(ctx, src)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	res, err := solr.MoveDocuments(ctx, st.HttpClient, st.solrURL(), st.BasicUser, st.BasicPass, src, dst, in.Query, key, maxDocs)
+	if err != nil {
+		slog.Error("Moving documents failed", "source", src, "target", dst, "query", in.Query, "error", err)
+		if res != nil {
+			return nil, nil, fmt.Errorf("%v (matched %d, copied %d, deleted %d)", err, res.Matched, res.Copied, res.Deleted)
+		}
+		return nil, nil, err
+	}
+	slog.Info("Documents moved", "source", src, "target", dst, "query", in.Query, "copied", res.Copied, "deleted", res.Deleted)
+	return nil, res, nil
+}
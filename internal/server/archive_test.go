@@ -0,0 +1,98 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/solr"
+	"github.com/Sashimimochi/solr-mcp-go/internal/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestToolArchive tests the validation and error paths of the archive tools.
+func TestToolArchive(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/solr/nokey/schema/uniquekey":
+			http.Error(w, "NullPointerException", http.StatusInternalServerError)
+		case "/solr/nokey/schema/fields":
+			fmt.Fprintln(w, `{"fields":[]}`)
+		case "/solr/logs/schema/uniquekey":
+			fmt.Fprintln(w, `{"uniqueKey":"id"}`)
+		case "/solr/logs/schema/fields":
+			fmt.Fprintln(w, `{"fields":[]}`)
+		case "/solr/logs/select":
+			fmt.Fprintln(w, `{"response":{"numFound":50000,"docs":[]}}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	st := newTestState(t, server.URL)
+
+	// Goal: A query is required so that a whole collection is never archived by accident.
+	_, _, err := st.toolArchive(context.Background(), nil, types.ArchiveIn{Collection: "logs"})
+	assert.ErrorContains(t, err, "query is required")
+
+	// Goal: Collections without uniqueKey return a capability error.
+	_, _, err = st.toolArchive(context.Background(), nil, types.ArchiveIn{Collection: "nokey", Query: "*:*"})
+	var capErr *CapabilityError
+	assert.ErrorAs(t, err, &capErr)
+	assert.Equal(t, "unique_key", capErr.Capability)
+
+	// Goal: The first call only previews the documents to move and issues a confirmation token.
+	in := types.ArchiveIn{Collection: "logs", Query: "level:DEBUG"}
+	_, out, err := st.toolArchive(context.Background(), nil, in)
+	require.NoError(t, err)
+	res := out.(map[string]any)
+	preview := res["preview"].(map[string]any)
+	assert.Equal(t, "logs_archive", preview["archiveCollection"])
+	assert.Equal(t, int64(50000), preview["matched"])
+	assert.Equal(t, int64(10000), preview["maxDocs"])
+
+	// Goal: The token does not confirm a different query.
+	token := res["confirmationToken"].(string)
+	_, _, err = st.toolArchive(context.Background(), nil, types.ArchiveIn{Collection: "logs", Query: "*:*", Confirm: token})
+	assert.ErrorIs(t, err, errConfirmationInvalid)
+
+	// Goal: Moves over the default limit are refused with the observed counts.
+	_, out, _ = st.toolArchive(context.Background(), nil, in)
+	in.Confirm = out.(map[string]any)["confirmationToken"].(string)
+	_, _, err = st.toolArchive(context.Background(), nil, in)
+	assert.ErrorContains(t, err, "more than the limit of 10000")
+	assert.ErrorContains(t, err, "matched 50000, copied 0")
+}
+
+// TestToolArchiveRestore tests that restore moves documents from the archive back to the collection.
+func TestToolArchiveRestore(t *testing.T) {
+	var selected []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/solr/logs_archive/schema/uniquekey":
+			fmt.Fprintln(w, `{"uniqueKey":"id"}`)
+		case "/solr/logs_archive/schema/fields":
+			fmt.Fprintln(w, `{"fields":[]}`)
+		case "/solr/logs_archive/select", "/solr/logs/select":
+			selected = append(selected, r.URL.Path)
+			fmt.Fprintln(w, `{"response":{"numFound":0,"docs":[]}}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	st := newTestState(t, server.URL)
+
+	_, out, err := st.toolArchiveRestore(context.Background(), nil, types.ArchiveIn{Collection: "logs", Query: "id:1"})
+
+	// Goal: The archive is the source of a restore.
+	assert.NoError(t, err)
+	res := out.(*solr.MoveResult)
+	assert.Equal(t, "logs_archive", res.Source)
+	assert.Equal(t, "logs", res.Target)
+	assert.Equal(t, []string{"/solr/logs_archive/select"}, selected)
+}
@@ -0,0 +1,154 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/config"
+	"github.com/Sashimimochi/solr-mcp-go/internal/types"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeBackend is an in-memory backend.SearchBackend for tool tests. Documents are keyed by "id".
+// Queries support *:*, field:value and {!terms f=id}a,b.
+type fakeBackend struct {
+	docs    map[string][]map[string]any
+	dropped []string
+}
+
+func (b *fakeBackend) match(collection, query string) []map[string]any {
+	var out []map[string]any
+	for _, doc := range b.docs[collection] {
+		switch {
+		case query == "*:*":
+		case strings.HasPrefix(query, "{!terms f=id}"):
+			if !slices.Contains(strings.Split(strings.TrimPrefix(query, "{!terms f=id}"), ","), fmt.Sprint(doc["id"])) {
+				continue
+			}
+		default:
+			field, value, _ := strings.Cut(query, ":")
+			if fmt.Sprint(doc[field]) != value {
+				continue
+			}
+		}
+		out = append(out, doc)
+	}
+	return out
+}
+
+func (b *fakeBackend) Query(_ context.Context, collection string, params url.Values) (map[string]any, error) {
+	matched := b.match(collection, params.Get("q"))
+	docs := make([]any, len(matched))
+	for i, d := range matched {
+		docs[i] = d
+		if fl := params.Get("fl"); fl != "" && fl != "*" {
+			projected := map[string]any{}
+			for _, f := range strings.Split(fl, ",") {
+				if v, ok := d[f]; ok {
+					projected[f] = v
+				}
+			}
+			docs[i] = projected
+		}
+	}
+	return map[string]any{"response": map[string]any{"numFound": float64(len(docs)), "docs": docs}}, nil
+}
+
+func (b *fakeBackend) Count(_ context.Context, collection, query string) (int64, error) {
+	return int64(len(b.match(collection, query))), nil
+}
+
+func (b *fakeBackend) Add(_ context.Context, collection string, docs []map[string]any) error {
+	for _, d := range docs {
+		b.docs[collection] = slices.DeleteFunc(b.docs[collection], func(e map[string]any) bool { return e["id"] == d["id"] })
+		b.docs[collection] = append(b.docs[collection], d)
+	}
+	return nil
+}
+
+func (b *fakeBackend) DeleteByQuery(_ context.Context, collection, query string) error {
+	matched := b.match(collection, query)
+	b.docs[collection] = slices.DeleteFunc(b.docs[collection], func(d map[string]any) bool {
+		return slices.ContainsFunc(matched, func(m map[string]any) bool { return m["id"] == d["id"] })
+	})
+	return nil
+}
+
+func (b *fakeBackend) DeleteByIDs(ctx context.Context, collection string, ids []string) error {
+	return b.DeleteByQuery(ctx, collection, "{!terms f=id}"+strings.Join(ids, ","))
+}
+
+func (b *fakeBackend) Schema(_ context.Context, collection string) (*types.FieldCatalog, error) {
+	if _, ok := b.docs[collection]; !ok {
+		return nil, fmt.Errorf("collection %s not found", collection)
+	}
+	return &types.FieldCatalog{UniqueKey: "id", All: []types.SolrField{{Name: "id", Type: "string"}}}, nil
+}
+
+func (b *fakeBackend) ClusterStatus(_ context.Context, collection string) (*config.ClusterStatusResponse, error) {
+	status := &config.ClusterStatusResponse{}
+	status.Cluster.LiveNodes = []string{"node1:8983_solr"}
+	status.Cluster.Collections = map[string]config.CollectionStatus{}
+	for name := range b.docs {
+		if collection == "" || collection == name {
+			status.Cluster.Collections[name] = config.CollectionStatus{Health: "GREEN"}
+		}
+	}
+	return status, nil
+}
+
+func (b *fakeBackend) ListCollections(context.Context) ([]string, error) {
+	var names []string
+	for name := range b.docs {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names, nil
+}
+
+func (b *fakeBackend) DeleteCollection(_ context.Context, name string) error {
+	delete(b.docs, name)
+	b.dropped = append(b.dropped, name)
+	return nil
+}
+
+// TestToolsWithBackend tests tools against an in-memory backend instead of a mock Solr server.
+func TestToolsWithBackend(t *testing.T) {
+	b := &fakeBackend{docs: map[string][]map[string]any{
+		"logs": {{"id": "1", "level": "INFO"}, {"id": "2", "level": "DEBUG"}},
+	}}
+	st := NewServer(WithBackend(b))
+	ctx := context.Background()
+
+	// Goal: Queries, health and completions go through the backend.
+	_, out, err := st.toolQuery(ctx, nil, types.QueryIn{Collection: "logs", Query: "level:DEBUG"})
+	assert.NoError(t, err)
+	assert.Equal(t, float64(1), out.(map[string]any)["response"].(map[string]any)["numFound"])
+	_, out, err = st.toolCollectionHealth(ctx, nil, types.CollectionHealthIn{Collection: "logs"})
+	assert.NoError(t, err)
+	assert.Equal(t, "GREEN", out.(map[string]any)["health"])
+	_, out, err = st.toolPing(ctx, nil, types.PingIn{})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, out.(map[string]any)["num_nodes"])
+
+	// Goal: Updates report overwritten documents and modify the backend.
+	_, out, err = st.toolUpdate(ctx, nil, types.UpdateIn{Collection: "logs", Documents: []map[string]any{{"id": "2", "level": "WARN"}, {"id": "3"}}})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"2"}, out.(map[string]any)["overwritten"])
+	assert.Len(t, b.docs["logs"], 3)
+
+	// Goal: Deletes are confirmed and executed through the backend.
+	in := types.DeleteIn{Collection: "logs", IDs: []string{"1", "3"}}
+	_, out, err = st.toolDelete(ctx, nil, in)
+	assert.NoError(t, err)
+	in.Confirm = out.(map[string]any)["confirmationToken"].(string)
+	_, out, err = st.toolDelete(ctx, nil, in)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), out.(map[string]any)["deleted"])
+	assert.Equal(t, []map[string]any{{"id": "2", "level": "WARN"}}, b.docs["logs"])
+}
@@ -0,0 +1,110 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/types"
+)
+
+// defaultDisplayFields are kept by a narrowed result when the collection has no configured display fields
+// and the schema has them, most important first.
+var defaultDisplayFields = []string{"title", "name", "label", "headline", "subject", "summary", "url"}
+
+// queryAnnotations are the keys solr.query adds to a response before the response budget applies. A narrowed
+// response keeps them.
+var queryAnnotations = []string{"suggestions", "corrections", "slowQuery", "possibleCauses"}
+
+// resultSize returns the size in bytes of a solr.query result after post-processing.
+func (st *State) resultSize(ctx context.Context, resp map[string]any) int {
+	var out any = resp
+	if r, err := st.postProcessors().Process(ctx, "solr.query", resp); err == nil && r != nil {
+		out = r.Output
+	}
+	data, _ := json.Marshal(out)
+	return len(data)
+}
+
+// displayFields returns the uniqueKey and the display fields of a collection, configured or found in its schema.
+func (st *State) displayFields(ctx context.Context, collection string) ([]string, error) {
+	fc, err := st.backend().Schema(ctx, collection)
+	if err != nil {
+		return nil, err
+	}
+	var fields []string
+	if fc.UniqueKey != "" {
+		fields = append(fields, fc.UniqueKey)
+	}
+	display, configured := st.fileConfig().ResponseBudget.DisplayFields[collection]
+	if !configured {
+		for _, f := range defaultDisplayFields {
+			if fc.HasField(f) {
+				display = append(display, f)
+			}
+		}
+	}
+	for _, f := range display {
+		if !slices.Contains(fields, f) {
+			fields = append(fields, f)
+		}
+	}
+	return fields, nil
+}
+
+// fitResponseBudget runs a query again with only the uniqueKey and display fields when its result exceeds
+// responseBudget.maxBytes after post-processing. It returns the response to use and a guardrail describing
+// the narrowing, or resp and nil when the result fits or cannot be narrowed.
+func (st *State) fitResponseBudget(ctx context.Context, collection string, params url.Values, resp map[string]any) (map[string]any, *types.Guardrail, error) {
+	budget := st.fileConfig().ResponseBudget.MaxBytes
+	if budget <= 0 {
+		return resp, nil, nil
+	}
+	size := st.resultSize(ctx, resp)
+	if size <= budget {
+		return resp, nil, nil
+	}
+	fields, err := st.displayFields(ctx, collection)
+	if err != nil || len(fields) == 0 {
+		slog.Warn("Result exceeds the response budget and cannot be narrowed", "collection", collection, "bytes", size, "budget", budget, "error", err)
+		return resp, nil, nil
+	}
+	requested := params.Get("fl")
+	if requested == "" {
+		requested = "*"
+	}
+	// Keep the scores the caller asked for, they order the results
+	if slices.Contains(strings.Split(requested, ","), "score") {
+		fields = append(fields, "score")
+	}
+	narrowed := url.Values{}
+	for k, v := range params {
+		narrowed[k] = v
+	}
+	narrowed.Set("fl", strings.Join(fields, ","))
+	out, _, err := st.routedQuery(ctx, collection, narrowed)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, key := range queryAnnotations {
+		if v, ok := resp[key]; ok {
+			out[key] = v
+		}
+	}
+	reason := fmt.Sprintf("the result of %d bytes exceeds responseBudget.maxBytes of %d bytes, so only the uniqueKey and display fields are returned", size, budget)
+	if after := st.resultSize(ctx, out); after > budget {
+		reason += fmt.Sprintf("; the narrowed result of %d bytes is still over the budget, request fewer rows", after)
+	}
+	slog.Info("Narrowed the fields of a result over the response budget", "collection", collection, "bytes", size, "budget", budget, "fl", narrowed.Get("fl"))
+	return out, &types.Guardrail{
+		Action:    "narrow_fields",
+		Target:    "fl",
+		Requested: requested,
+		Applied:   narrowed.Get("fl"),
+		Reason:    reason,
+	}, nil
+}
@@ -0,0 +1,69 @@
+package server
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/config"
+	"github.com/Sashimimochi/solr-mcp-go/internal/solr"
+	"github.com/Sashimimochi/solr-mcp-go/internal/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestResponseBudget tests narrowing the fields of solr.query results over the response budget.
+func TestResponseBudget(t *testing.T) {
+	b := &fakeBackend{docs: map[string][]map[string]any{
+		"products": {
+			{"id": "1", "title": "tv", "body": strings.Repeat("x", 2000)},
+			{"id": "2", "title": "radio", "body": strings.Repeat("y", 2000)},
+		},
+	}}
+	fc := &config.FileConfig{ResponseBudget: config.ResponseBudgetConfig{
+		MaxBytes:      1000,
+		DisplayFields: map[string][]string{"products": {"title", "id"}},
+	}}
+	st := NewServer(WithBackend(b), WithConfig(fc, ""))
+	ctx := context.Background()
+
+	// Goal: A result over the budget is fetched again with the uniqueKey and display fields.
+	_, out, err := st.toolQuery(ctx, nil, types.QueryIn{Collection: "products", Query: "*:*"})
+	require.NoError(t, err)
+	resp := out.(map[string]any)
+	assert.Equal(t, []any{map[string]any{"id": "1", "title": "tv"}, map[string]any{"id": "2", "title": "radio"}}, resp["response"].(map[string]any)["docs"])
+	guardrails := resp["guardrails"].([]types.Guardrail)
+	require.Len(t, guardrails, 1)
+	assert.Equal(t, "narrow_fields", guardrails[0].Action)
+	assert.Equal(t, "*", guardrails[0].Requested)
+	assert.Equal(t, "id,title", guardrails[0].Applied)
+	assert.NotContains(t, guardrails[0].Reason, "still over")
+
+	// Goal: A narrowed result keeps the annotations of slow and zero-hit queries.
+	slow := map[string]any{"qTime": float64(2500)}
+	resp, _, err = st.fitResponseBudget(ctx, "products", url.Values{"q": {"*:*"}}, map[string]any{
+		"response":       map[string]any{"numFound": float64(2), "docs": []any{b.docs["products"][0], b.docs["products"][1]}},
+		"slowQuery":      slow,
+		"possibleCauses": []solr.SlowQueryCause{{Cause: "deep_paging"}},
+		"suggestions":    []solr.FilterSuggestion{{Filter: "color:rde", Suggestion: "color:red"}},
+	})
+	require.NoError(t, err)
+	assert.Len(t, resp["response"].(map[string]any)["docs"].([]any)[0], 2)
+	assert.Equal(t, slow, resp["slowQuery"])
+	assert.Len(t, resp["possibleCauses"], 1)
+	assert.Len(t, resp["suggestions"], 1)
+
+	// Goal: Results within the budget are left alone.
+	_, out, err = st.toolQuery(ctx, nil, types.QueryIn{Collection: "products", Query: "*:*", Fields: []string{"id"}})
+	require.NoError(t, err)
+	assert.NotContains(t, out.(map[string]any), "guardrails")
+
+	// Goal: Truncation by post-processing counts towards the budget.
+	fc.PostProcessing = config.PostProcessingConfig{Pipeline: []string{"truncate"}, MaxStringLength: 100}
+	st = NewServer(WithBackend(b), WithConfig(fc, ""))
+	_, out, err = st.toolQuery(ctx, nil, types.QueryIn{Collection: "products", Query: "*:*"})
+	require.NoError(t, err)
+	assert.NotContains(t, out.(map[string]any), "guardrails")
+}
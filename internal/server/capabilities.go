@@ -0,0 +1,217 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/errcatalog"
+)
+
+// Capability describes an optional subsystem and whether it is configured.
+type Capability struct {
+	Name    string `json:"name"`
+	Enabled bool   `json:"enabled"`
+	Reason  string `json:"reason,omitempty"` // why the capability is disabled
+	Hint    string `json:"hint,omitempty"`   // how to enable it
+}
+
+// CapabilityError is returned when a request needs a capability that is not configured.
+type CapabilityError struct {
+	Capability string `json:"capability"`
+	Reason     string `json:"reason"`
+	Hint       string `json:"hint"`
+}
+
+func (e *CapabilityError) Error() string {
+	return fmt.Sprintf("capability not configured: %s (%s). %s", e.Capability, e.Reason, e.Hint)
+}
+
+// MarshalJSON adds a stable error code to the structured error.
+func (e *CapabilityError) MarshalJSON() ([]byte, error) {
+	type alias CapabilityError
+	return json.Marshal(struct {
+		Error string `json:"error"`
+		*alias
+	}{Error: string(e.ErrorCode()), alias: (*alias)(e)})
+}
+
+// ErrorCode returns the catalog code of the error.
+func (e *CapabilityError) ErrorCode() errcatalog.Code { return errcatalog.CapabilityNotConfigured }
+
+// Capabilities reports which optional subsystems are enabled.
+func (st *State) Capabilities() []Capability {
+	fc := st.fileConfig()
+	caps := []Capability{
+		{
+			Name:    "config_file",
+			Enabled: st.ConfigPath != "",
+			Reason:  "SOLR_MCP_CONFIG_FILE is not set",
+			Hint:    "Set SOLR_MCP_CONFIG_FILE to the path of a JSON config file.",
+		},
+		{
+			Name:    "saved_queries",
+			Enabled: len(fc.SavedQueries) > 0,
+			Reason:  "no savedQueries in the config file",
+			Hint:    "Add entries to savedQueries in the config file.",
+		},
+		{
+			Name:    "prometheus_exporter",
+			Enabled: fc.Exporter.Enabled,
+			Reason:  "exporter.enabled is false",
+			Hint:    "Set exporter.enabled to true in the config file to expose saved queries on /metrics.",
+		},
+		{
+			Name:    "solr_exporter",
+			Enabled: fc.SolrExporter.URL != "",
+			Reason:  "solrExporter.url is not set",
+			Hint:    "Set solrExporter.url in the config file to the /metrics endpoint of a solr-exporter to keep a history of Solr metrics for solr.metrics.history.",
+		},
+		{
+			Name:    "grafana_datasource",
+			Enabled: fc.Datasource.Enabled,
+			Reason:  "datasource.enabled is false",
+			Hint:    "Set datasource.enabled to true in the config file to expose saved queries on /datasource.",
+		},
+		{
+			Name:    "retention",
+			Enabled: len(fc.Retention.Policies) > 0,
+			Reason:  "no retention.policies in the config file",
+			Hint:    "Add retention.policies to the config file; set retention.interval to run them automatically.",
+		},
+		{
+			Name:    "maintenance",
+			Enabled: fc.Maintenance.Window != "",
+			Reason:  "maintenance.window is not set",
+			Hint:    "Set maintenance.window in the config file, e.g. \"02:00-04:00\", to merge away deleted documents, run retention and take backups every night.",
+		},
+		{
+			Name:    "drift_detection",
+			Enabled: st.DataDir != "",
+			Reason:  "SOLR_MCP_DATA_DIR is not set",
+			Hint:    "Set SOLR_MCP_DATA_DIR to store schema and config snapshots; set drift.interval in the config file to take them automatically.",
+		},
+		{
+			Name:    "background_jobs",
+			Enabled: st.DataDir != "",
+			Reason:  "SOLR_MCP_DATA_DIR is not set",
+			Hint:    "Set SOLR_MCP_DATA_DIR to run exports, reindexes and imports as background jobs that resume after a restart.",
+		},
+		{
+			Name:    "notifier",
+			Enabled: fc.Notifier.WebhookURL != "",
+			Reason:  "notifier.webhookUrl is not set",
+			Hint:    "Set notifier.webhookUrl in the config file to receive alerts such as schema drift; they are only logged otherwise.",
+		},
+		{
+			Name:    "standby_failover",
+			Enabled: st.failoverMonitor() != nil,
+			Reason:  "SOLR_MCP_STANDBY_URL is not set",
+			Hint:    "Set SOLR_MCP_STANDBY_URL to a DR Solr cluster that read tools fail over to when the primary is unavailable.",
+		},
+		{
+			Name:    "node_routing",
+			Enabled: st.nodeRouter() != nil,
+			Reason:  "SOLR_MCP_SOLR_NODES is not set",
+			Hint:    "Set SOLR_MCP_SOLR_NODES to further nodes of the cluster, or SOLR_MCP_DISCOVER_NODES=true, to send each query to the fastest healthy node.",
+		},
+		{
+			Name:    "admin_api",
+			Enabled: st.AdminAddr != "" && st.AdminToken != "",
+			Reason:  "SOLR_MCP_ADMIN_ADDR or SOLR_MCP_ADMIN_TOKEN is not set",
+			Hint:    "Set SOLR_MCP_ADMIN_ADDR and SOLR_MCP_ADMIN_TOKEN to serve the operator API, e.g. for read-only mode and draining.",
+		},
+		{
+			Name:    "read_only",
+			Enabled: st.readOnly.Load(),
+			Reason:  "write tools are accepted",
+			Hint:    "PUT {\"enabled\":true} to /admin/read-only of the admin API to refuse write tools.",
+		},
+		{
+			Name:    "authorization_policy",
+			Enabled: fc.Policy.Enabled(),
+			Reason:  "no policy.rules, policy.opa.url or policy.default in the config file",
+			Hint:    "Add policy.rules or policy.opa.url to the config file to authorize tool calls per user.",
+		},
+		{
+			Name:    "object_stores",
+			Enabled: len(fc.ObjectStores) > 0,
+			Reason:  "no objectStores in the config file",
+			Hint:    "Add objectStores to the config file to upload exports and query results to S3, GCS or Azure Blob storage.",
+		},
+		{
+			Name:    "memory_budget",
+			Enabled: fc.MemoryBudget.Enabled(),
+			Reason:  "memoryBudget.maxBytes and memoryBudget.maxConcurrent are not set",
+			Hint:    "Set memoryBudget.maxBytes or memoryBudget.maxConcurrent in the config file to reject tool calls as busy under load.",
+		},
+		{
+			Name:    "chaos",
+			Enabled: st.Chaos.Rate > 0,
+			Reason:  "SOLR_MCP_CHAOS_RATE is 0",
+			Hint:    "Set SOLR_MCP_CHAOS_RATE, e.g. to 0.1, to fail a share of Solr requests on purpose when testing agents.",
+		},
+		{
+			Name:    "record_replay",
+			Enabled: st.RecordFile != "" || st.replay != nil,
+			Reason:  "SOLR_MCP_RECORD_FILE and SOLR_MCP_REPLAY_FILE are not set",
+			Hint:    "Set SOLR_MCP_RECORD_FILE to record the Solr traffic to a fixture, and SOLR_MCP_REPLAY_FILE to answer from it without a cluster.",
+		},
+		{
+			Name:    "opensearch_backend",
+			Enabled: st.openSearch != nil,
+			Reason:  "SOLR_MCP_BACKEND is not opensearch",
+			Hint:    "Set SOLR_MCP_BACKEND=opensearch to run the tools against an OpenSearch or Elasticsearch cluster (experimental).",
+		},
+		{
+			Name:    "solr_basic_auth",
+			Enabled: st.BasicUser != "",
+			Reason:  "SOLR_BASIC_USER is not set",
+			Hint:    "Set SOLR_BASIC_USER and SOLR_BASIC_PASS if Solr requires authentication.",
+		},
+	}
+	for i := range caps {
+		if caps[i].Enabled {
+			caps[i].Reason, caps[i].Hint = "", ""
+		}
+	}
+	return caps
+}
+
+// CapabilityError returns a structured error for the named capability, or nil if it is enabled.
+func (st *State) CapabilityError(name string) *CapabilityError {
+	for _, c := range st.Capabilities() {
+		if c.Name == name {
+			if c.Enabled {
+				return nil
+			}
+			return &CapabilityError{Capability: c.Name, Reason: c.Reason, Hint: c.Hint}
+		}
+	}
+	return nil
+}
+
+// LogCapabilities logs the disabled capabilities with setup hints.
+func (st *State) LogCapabilities() {
+	for _, c := range st.Capabilities() {
+		if c.Enabled {
+			slog.Info("Capability enabled", "capability", c.Name)
+		} else {
+			slog.Info("Capability disabled", "capability", c.Name, "reason", c.Reason, "hint", c.Hint)
+		}
+	}
+}
+
+// capabilityErrorHandler answers HTTP requests for a disabled capability.
+func (st *State) capabilityErrorHandler(name string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		e := st.CapabilityError(name)
+		if e == nil {
+			e = &CapabilityError{Capability: name, Reason: "enabled after startup", Hint: "Restart the server to enable this endpoint."}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(e)
+	})
+}
@@ -0,0 +1,122 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/config"
+	"github.com/Sashimimochi/solr-mcp-go/internal/types"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// capabilityByName is a helper that finds a capability in the report.
+func capabilityByName(caps []Capability, name string) Capability {
+	for _, c := range caps {
+		if c.Name == name {
+			return c
+		}
+	}
+	return Capability{}
+}
+
+// TestCapabilities tests the capability report.
+func TestCapabilities(t *testing.T) {
+	// Goal: Unconfigured subsystems are reported as disabled with a reason and a hint.
+	t.Run("Defaults", func(t *testing.T) {
+		st := newTestState(t, "http://localhost:8983")
+
+		caps := st.Capabilities()
+
+		exporter := capabilityByName(caps, "prometheus_exporter")
+		assert.False(t, exporter.Enabled)
+		assert.NotEmpty(t, exporter.Reason)
+		assert.NotEmpty(t, exporter.Hint)
+		assert.False(t, capabilityByName(caps, "config_file").Enabled)
+		assert.False(t, capabilityByName(caps, "solr_basic_auth").Enabled)
+	})
+
+	// Goal: Configured subsystems are reported as enabled without hints.
+	t.Run("Configured", func(t *testing.T) {
+		st := newTestState(t, "http://localhost:8983")
+		st.ConfigPath = "/etc/solr-mcp.json"
+		st.BasicUser = "user"
+		st.StandbyURL = "http://standby:8983"
+		st.Nodes = []string{"http://solr2:8983"}
+		st.DataDir = t.TempDir()
+		st.AdminAddr, st.AdminToken = "127.0.0.1:9090", "s3cret"
+		st.readOnly.Store(true)
+		st.Chaos.Rate = 0.1
+		st.RecordFile = filepath.Join(t.TempDir(), "fixture.json")
+		st.openSearch = &openSearchOptions{baseURL: "http://opensearch:9200"}
+		st.Config = &config.FileConfig{
+			SavedQueries: []config.SavedQuery{{Name: "q", Collection: "c"}},
+			Exporter:     config.ExporterConfig{Enabled: true},
+			Datasource:   config.DatasourceConfig{Enabled: true},
+			Retention: config.RetentionConfig{Policies: []config.RetentionPolicy{
+				{Name: "logs", Collection: "logs", DateField: "timestamp", MaxAge: "30d"},
+			}},
+			Notifier:     config.NotifierConfig{WebhookURL: "https://hooks.example.com/solr"},
+			SolrExporter: config.SolrExporterConfig{URL: "http://solr-exporter:9854/metrics"},
+			Maintenance:  config.MaintenanceConfig{Window: "02:00-04:00"},
+			Policy:       config.PolicyConfig{Rules: []config.PolicyRule{{Name: "hr", When: "collection == 'hr'", Effect: "deny"}}},
+			ObjectStores: map[string]config.ObjectStoreConfig{"exports": {Type: "s3", Bucket: "exports"}},
+			MemoryBudget: config.MemoryBudgetConfig{MaxBytes: 1 << 30},
+		}
+
+		for _, c := range st.Capabilities() {
+			assert.True(t, c.Enabled, c.Name)
+			assert.Empty(t, c.Hint, c.Name)
+		}
+		assert.Nil(t, st.CapabilityError("saved_queries"))
+	})
+}
+
+// TestCapabilityError tests the structured capability error.
+func TestCapabilityError(t *testing.T) {
+	st := newTestState(t, "http://localhost:8983")
+
+	e := st.CapabilityError("grafana_datasource")
+
+	assert.NotNil(t, e)
+	assert.Contains(t, e.Error(), "capability not configured: grafana_datasource")
+	data, err := json.Marshal(e)
+	assert.NoError(t, err)
+	var out map[string]string
+	assert.NoError(t, json.Unmarshal(data, &out))
+	assert.Equal(t, "capability_not_configured", out["error"])
+	assert.Equal(t, "grafana_datasource", out["capability"])
+	assert.NotEmpty(t, out["hint"])
+
+	// Goal: Unknown capabilities yield no error.
+	assert.Nil(t, st.CapabilityError("unknown"))
+}
+
+// TestCapabilityErrorHandler tests the HTTP handler mounted for disabled endpoints.
+func TestCapabilityErrorHandler(t *testing.T) {
+	st := newTestState(t, "http://localhost:8983")
+	rr := httptest.NewRecorder()
+
+	st.capabilityErrorHandler("prometheus_exporter").ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"capability":"prometheus_exporter"`)
+}
+
+// TestToolInfo tests the solr.info tool.
+func TestToolInfo(t *testing.T) {
+	st := newTestState(t, "http://localhost:8983")
+
+	_, resp, err := st.toolInfo(context.Background(), nil, types.InfoIn{})
+
+	assert.NoError(t, err)
+	out := resp.(map[string]any)
+	assert.Equal(t, config.Version, out["version"])
+	assert.Equal(t, "http://localhost:8983", out["solrUrl"])
+	assert.Equal(t, "test", out["defaultCollection"])
+	assert.NotEmpty(t, out["capabilities"])
+}
@@ -0,0 +1,62 @@
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+	"net/url"
+	"slices"
+)
+
+// servedQuery is the response of a query and the base URL of the node that answered it.
+type servedQuery struct {
+	resp map[string]any
+	node string
+}
+
+// sharedQuery runs identical concurrent solr.query requests once, e.g. when several agents watch the same dashboard
+// question, and gives every caller its own copy of the response, so truncation and the other per-caller steps
+// applied afterwards do not affect each other. It also returns the base URL of the node that answered.
+func (st *State) sharedQuery(ctx context.Context, collection string, params url.Values) (map[string]any, string, error) {
+	q, shared, err := st.queries.Do(ctx, queryKey(st.solrURL(), collection, params), func(ctx context.Context) (servedQuery, error) {
+		resp, node, err := st.routedQuery(ctx, collection, params)
+		return servedQuery{resp: resp, node: node}, err
+	})
+	if !shared || err != nil {
+		return q.resp, q.node, err
+	}
+	slog.Debug("Sharing the response of an identical concurrent query", "collection", collection)
+	return copyJSON(q.resp).(map[string]any), q.node, nil
+}
+
+// queryKey hashes the normalized request of a query: parameters sorted by name, and filter queries, whose order
+// does not change the result, sorted too.
+func queryKey(baseURL, collection string, params url.Values) string {
+	normalized := url.Values{}
+	for k, v := range params {
+		normalized[k] = slices.Clone(v)
+	}
+	slices.Sort(normalized["fq"])
+	sum := sha256.Sum256([]byte(baseURL + "\n" + collection + "\n" + normalized.Encode()))
+	return hex.EncodeToString(sum[:])
+}
+
+// copyJSON returns a deep copy of a decoded JSON value. Other values are immutable or not copied.
+func copyJSON(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		m := make(map[string]any, len(v))
+		for k, e := range v {
+			m[k] = copyJSON(e)
+		}
+		return m
+	case []any:
+		s := make([]any, len(v))
+		for i, e := range v {
+			s[i] = copyJSON(e)
+		}
+		return s
+	}
+	return v
+}
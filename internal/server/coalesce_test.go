@@ -0,0 +1,78 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSharedQuery tests coalescing identical concurrent solr.query calls into one Solr request.
+func TestSharedQuery(t *testing.T) {
+	var selects atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/solr/products/select" {
+			http.NotFound(w, r)
+			return
+		}
+		selects.Add(1)
+		time.Sleep(100 * time.Millisecond)
+		fmt.Fprintln(w, `{"response":{"numFound":1,"docs":[{"id":"1"}]}}`)
+	}))
+	defer server.Close()
+	st := NewServer(WithSolrURL(server.URL), WithHTTPClient(&http.Client{}), WithDataDir(t.TempDir()))
+
+	// Goal: Identical concurrent queries send one request, and each caller gets its own copy of the response.
+	in := types.QueryIn{Collection: "products", Query: "status:active", FilterQuery: []string{"a:1", "b:2"}}
+	outs := make([]map[string]any, 3)
+	var wg sync.WaitGroup
+	for i := range outs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, out, err := st.toolQuery(context.Background(), nil, in)
+			assert.NoError(t, err)
+			outs[i], _ = out.(map[string]any)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), selects.Load())
+	require.NotNil(t, outs[0])
+	assert.Equal(t, outs[0], outs[1])
+	outs[0]["response"].(map[string]any)["docs"].([]any)[0].(map[string]any)["id"] = "changed"
+	assert.Equal(t, "1", outs[1]["response"].(map[string]any)["docs"].([]any)[0].(map[string]any)["id"])
+
+	// Goal: Queries that differ run on their own.
+	for _, q := range []string{"status:active", "status:retired"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _, err := st.toolQuery(context.Background(), nil, types.QueryIn{Collection: "products", Query: q})
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(3), selects.Load())
+}
+
+// TestQueryKey tests normalizing the parameters of coalesced queries.
+func TestQueryKey(t *testing.T) {
+	a := url.Values{"q": {"*:*"}, "fq": {"a:1", "b:2"}, "rows": {"10"}}
+	b := url.Values{"rows": {"10"}, "fq": {"b:2", "a:1"}, "q": {"*:*"}}
+	assert.Equal(t, queryKey("http://solr", "products", a), queryKey("http://solr", "products", b))
+	assert.Equal(t, []string{"a:1", "b:2"}, a["fq"], "the params are not modified")
+	assert.NotEqual(t, queryKey("http://solr", "products", a), queryKey("http://solr", "orders", a))
+	assert.NotEqual(t, queryKey("http://solr", "products", a), queryKey("http://standby", "products", a))
+	b.Set("rows", "20")
+	assert.NotEqual(t, queryKey("http://solr", "products", a), queryKey("http://solr", "products", b))
+}
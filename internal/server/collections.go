@@ -0,0 +1,158 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/errcatalog"
+)
+
+const (
+	// collectionListTTL is how long the collection list of the active cluster is reused.
+	collectionListTTL = 30 * time.Second
+	// missingCollectionTTL is how long calls on a collection found missing fail without contacting Solr.
+	missingCollectionTTL = 30 * time.Second
+	// maxListedCollections is the number of available collections named in errors.
+	maxListedCollections = 20
+)
+
+// collectionCache holds the collection list of a cluster and the collections found missing on it.
+type collectionCache struct {
+	baseURL string
+	names   []string
+	fetched time.Time
+	missing map[string]time.Time
+}
+
+// collectionNames returns the collections of the active cluster, listed at most collectionListTTL ago.
+func (st *State) collectionNames(ctx context.Context) ([]string, error) {
+	st.collectionsMu.Lock()
+	c := st.activeCollections()
+	if c.names != nil && time.Since(c.fetched) < collectionListTTL {
+		names := c.names
+		st.collectionsMu.Unlock()
+		return names, nil
+	}
+	st.collectionsMu.Unlock()
+
+	names, err := st.backend().ListCollections(ctx)
+	if err != nil {
+		return nil, err
+	}
+	slices.Sort(names)
+	st.collectionsMu.Lock()
+	defer st.collectionsMu.Unlock()
+	c = st.activeCollections()
+	c.names, c.fetched = names, time.Now()
+	for name := range c.missing {
+		if slices.Contains(names, name) {
+			delete(c.missing, name)
+		}
+	}
+	return names, nil
+}
+
+// activeCollections returns the cache of the active cluster, starting over after a failover or fail-back.
+// collectionsMu must be held.
+func (st *State) activeCollections() *collectionCache {
+	if st.collections == nil || st.collections.baseURL != st.solrURL() {
+		st.collections = &collectionCache{baseURL: st.solrURL(), missing: map[string]time.Time{}}
+	}
+	return st.collections
+}
+
+// invalidateCollections forgets the collection list and the missing collections, e.g. after a collection was
+// created or dropped, and returns the number of entries dropped.
+func (st *State) invalidateCollections() int {
+	st.collectionsMu.Lock()
+	defer st.collectionsMu.Unlock()
+	if st.collections == nil {
+		return 0
+	}
+	n := len(st.collections.missing)
+	if st.collections.names != nil {
+		n++
+	}
+	st.collections = nil
+	return n
+}
+
+// missingCollection fails calls on a collection that was found missing less than missingCollectionTTL ago, so
+// repeated calls do not cost round trips to Solr.
+func (st *State) missingCollection(tool string, in any) error {
+	collection := inputCollection(in)
+	if collection == "" || localTools[tool] {
+		return nil
+	}
+	st.collectionsMu.Lock()
+	defer st.collectionsMu.Unlock()
+	c := st.activeCollections()
+	since, ok := c.missing[collection]
+	if !ok {
+		return nil
+	}
+	if time.Since(since) >= missingCollectionTTL {
+		delete(c.missing, collection)
+		return nil
+	}
+	return collectionNotFound(collection, c.names)
+}
+
+// explainMissingCollection replaces a not found error of a call on a collection that does not exist with one
+// naming the available collections, and remembers the collection as missing.
+func (st *State) explainMissingCollection(ctx context.Context, tool string, in any, err error) error {
+	collection := inputCollection(in)
+	if collection == "" || localTools[tool] || !notFoundError(err) {
+		return err
+	}
+	names, lerr := st.collectionNames(ctx)
+	if lerr != nil || slices.Contains(names, collection) {
+		return err
+	}
+	st.collectionsMu.Lock()
+	st.activeCollections().missing[collection] = time.Now()
+	st.collectionsMu.Unlock()
+	slog.Info("Collection does not exist", "tool", tool, "collection", collection)
+	return collectionNotFound(collection, names)
+}
+
+// notFoundError reports whether err is Solr or OpenSearch saying a collection or index was not found.
+func notFoundError(err error) bool {
+	msg := err.Error()
+	return strings.Contains(msg, "HTTP status 404") ||
+		(strings.Contains(msg, "HTTP status 400") && strings.Contains(msg, "not found"))
+}
+
+func collectionNotFound(collection string, names []string) error {
+	if len(names) == 0 {
+		return errcatalog.New(errcatalog.CollectionNotFound, "collection %s does not exist; the cluster has no collections", collection)
+	}
+	listed := names
+	more := ""
+	if len(listed) > maxListedCollections {
+		listed = listed[:maxListedCollections]
+		more = fmt.Sprintf(" and %d more", len(names)-maxListedCollections)
+	}
+	return errcatalog.New(errcatalog.CollectionNotFound, "collection %s does not exist; available: [%s]%s", collection, strings.Join(listed, ", "), more)
+}
+
+// inputCollection returns the collection field of the input of a tool, if it has one.
+func inputCollection(in any) string {
+	v := reflect.ValueOf(in)
+	if v.Kind() == reflect.Pointer {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return ""
+	}
+	f := v.FieldByName("Collection")
+	if !f.IsValid() || f.Kind() != reflect.String {
+		return ""
+	}
+	return strings.TrimSpace(f.String())
+}
@@ -0,0 +1,87 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/types"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMissingCollection tests failing fast on collections that do not exist.
+func TestMissingCollection(t *testing.T) {
+	var missingRequests, lists atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/solr/admin/collections" && r.URL.Query().Get("action") == "LIST":
+			lists.Add(1)
+			fmt.Fprintln(w, `{"collections":["products","orders"]}`)
+		case strings.HasPrefix(r.URL.Path, "/solr/missing/"):
+			missingRequests.Add(1)
+			http.Error(w, `{"error":{"msg":"Not Found","code":404}}`, http.StatusNotFound)
+		case r.URL.Path == "/solr/products/select":
+			fmt.Fprintln(w, `{"response":{"numFound":0,"docs":[]}}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	st := NewServer(WithSolrURL(server.URL), WithHTTPClient(&http.Client{}))
+	session, _, _ := connectTestClient(t, st.NewMCPServer())
+	ctx := context.Background()
+	call := func(tool string, args map[string]any) string {
+		res, err := session.CallTool(ctx, &mcp.CallToolParams{Name: tool, Arguments: args})
+		require.NoError(t, err)
+		if !res.IsError {
+			return ""
+		}
+		e := res.StructuredContent.(map[string]any)["error"].(map[string]any)
+		assert.Equal(t, "collection_not_found", e["code"])
+		return e["detail"].(string)
+	}
+	const notFound = "collection missing does not exist; available: [orders, products]"
+
+	// Goal: A not found error of an unknown collection names the available collections.
+	assert.Equal(t, notFound, call("solr.query", map[string]any{"collection": "missing"}))
+	assert.Equal(t, int32(1), missingRequests.Load())
+
+	// Goal: Later calls on the collection fail without contacting Solr, whatever the tool.
+	assert.Equal(t, notFound, call("solr.schema", map[string]any{"collection": "missing"}))
+	assert.Equal(t, notFound, call("solr.query", map[string]any{"collection": "missing"}))
+	assert.Equal(t, int32(1), missingRequests.Load())
+	assert.Empty(t, call("solr.query", map[string]any{"collection": "products"}))
+
+	// Goal: The repeated failures produce the recommendation of missing collections.
+	recs := st.usageTracker().Recommendations()
+	require.Len(t, recs, 1)
+	assert.Equal(t, "collection_not_found", recs[0].Pattern)
+
+	// Goal: The collection list is cached and shared with argument completion.
+	names, err := st.collectionNames(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"orders", "products"}, names)
+	assert.Equal(t, int32(1), lists.Load())
+
+	// Goal: Invalidating the cache, as dropping a collection does, checks Solr again.
+	assert.Equal(t, 2, st.invalidateCollections())
+	assert.Equal(t, notFound, call("solr.query", map[string]any{"collection": "missing"}))
+	assert.Equal(t, int32(2), missingRequests.Load())
+	assert.Equal(t, int32(2), lists.Load())
+}
+
+// TestInputCollection tests reading the collection of tool inputs.
+func TestInputCollection(t *testing.T) {
+	assert.Equal(t, "products", inputCollection(types.QueryIn{Collection: " products "}))
+	assert.Equal(t, "products", inputCollection(&types.SchemaIn{Collection: "products"}))
+	assert.Equal(t, "", inputCollection(types.RetentionIn{Policy: "logs"}))
+	assert.Equal(t, "", inputCollection((*types.SchemaIn)(nil)))
+	assert.Equal(t, "", inputCollection(map[string]any{"collection": "products"}))
+}
@@ -0,0 +1,89 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/errcatalog"
+	"github.com/Sashimimochi/solr-mcp-go/internal/solr"
+)
+
+// minSolrMajor is the oldest Solr major version the tools are written for.
+const minSolrMajor = 7
+
+// cloudTools use the Collections API, which only exists in SolrCloud mode.
+var cloudTools = map[string]bool{
+	"solr.ping":              true,
+	"solr.collection.health": true,
+	"solr.query.shards":      true,
+	"solr.consistency.check": true,
+	"solr.healthcheck.full":  true,
+	"solr.collection.drop":   true,
+	"solr.elevation.set":     true,
+}
+
+// versionRetryInterval is how long a failed version detection is not retried.
+const versionRetryInterval = time.Minute
+
+// localTools do not contact Solr and run regardless of its version.
+var localTools = map[string]bool{
+	"solr.info":               true,
+	"solr.server.stats":       true,
+	"solr.server.sessions":    true,
+	"solr.metrics.history":    true,
+	"solr.experiment.report":  true,
+	"solr.feedback.report":    true,
+	"solr.jobs":               true,
+	"solr.maintenance.status": true,
+}
+
+// solrVersion returns the version of the active Solr cluster, detecting it on first use.
+// Failed detections are retried after versionRetryInterval; ok is false while the version is unknown.
+func (st *State) solrVersion(ctx context.Context) (solr.Version, bool) {
+	st.versionMu.Lock()
+	defer st.versionMu.Unlock()
+	if st.version != nil {
+		return *st.version, true
+	}
+	if st.Backend != nil || time.Since(st.versionFailed) < versionRetryInterval {
+		return solr.Version{}, false
+	}
+	v, err := solr.GetVersion(ctx, st.HttpClient, st.solrURL(), st.BasicUser, st.BasicPass)
+	if err != nil {
+		slog.Warn("Failed to detect the Solr version", "error", err)
+		st.versionFailed = time.Now()
+		return solr.Version{}, false
+	}
+	st.version = &v
+	return v, true
+}
+
+// DetectSolrVersion detects and logs the Solr version at startup.
+func (st *State) DetectSolrVersion(ctx context.Context) {
+	if v, ok := st.solrVersion(ctx); ok {
+		slog.Info("Detected Solr version", "version", v.String(), "mode", v.Mode)
+		if v.Major < minSolrMajor {
+			slog.Warn("Solr version is older than supported, tools will refuse to run", "version", v.String(), "minimum", minSolrMajor)
+		}
+	}
+}
+
+// toolUnsupported returns an error if the tool cannot work with the detected Solr version or mode.
+// Tools run unchecked while the version is unknown.
+func (st *State) toolUnsupported(ctx context.Context, tool string) error {
+	if st.Backend != nil || localTools[tool] {
+		return nil
+	}
+	v, ok := st.solrVersion(ctx)
+	if !ok {
+		return nil
+	}
+	if v.Major < minSolrMajor {
+		return errcatalog.New(errcatalog.UnsupportedSolr, "%s is not supported on Solr %s: Solr %d or later is required", tool, v, minSolrMajor)
+	}
+	if cloudTools[tool] && v.Mode != "" && !v.Cloud() {
+		return errcatalog.New(errcatalog.UnsupportedSolr, "%s is not supported on Solr %s in standalone mode: it needs the Collections API of SolrCloud", tool, v)
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSolrVersionCompatibility tests that tools refuse to run on unsupported Solr versions and modes.
+func TestSolrVersionCompatibility(t *testing.T) {
+	systemInfo := `{"mode":"std","lucene":{"solr-spec-version":"8.0.0"}}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/solr/admin/info/system" {
+			fmt.Fprintln(w, systemInfo)
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+	call := func(st *State, name string) *mcp.CallToolResult {
+		mcpServer := mcp.NewServer(&mcp.Implementation{}, nil)
+		AddTools(mcpServer, st)
+		session, _, _ := connectTestClient(t, mcpServer)
+		res, err := session.CallTool(context.Background(), &mcp.CallToolParams{Name: name, Arguments: map[string]any{}})
+		require.NoError(t, err)
+		return res
+	}
+
+	// Goal: SolrCloud-only tools refuse to run on standalone Solr with a clear message.
+	res := call(newTestState(t, server.URL), "solr.ping")
+	assert.True(t, res.IsError)
+	assert.Contains(t, res.Content[0].(*mcp.TextContent).Text, "solr.ping is not supported on Solr 8.0.0 in standalone mode")
+
+	// Goal: The detected version is reported by solr.info.
+	res = call(newTestState(t, server.URL), "solr.info")
+	assert.False(t, res.IsError)
+	version := res.StructuredContent.(map[string]any)["solrVersion"].(map[string]any)
+	assert.Equal(t, "8.0.0", version["version"])
+	assert.Equal(t, "std", version["mode"])
+
+	// Goal: Solr versions older than supported are refused, except for tools that do not contact Solr.
+	systemInfo = `{"mode":"solrcloud","lucene":{"solr-spec-version":"6.6.6"}}`
+	st := newTestState(t, server.URL)
+	res = call(st, "solr.ping")
+	assert.True(t, res.IsError)
+	assert.Contains(t, res.Content[0].(*mcp.TextContent).Text, "solr.ping is not supported on Solr 6.6.6: Solr 7 or later is required")
+	assert.NoError(t, st.toolUnsupported(context.Background(), "solr.info"))
+}
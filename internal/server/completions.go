@@ -0,0 +1,117 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// maxCompletionValues is the maximum number of values allowed in a completion response by the MCP spec.
+const maxCompletionValues = 100
+
+// Complete handles completion/complete requests for prompt and resource template arguments.
+// Completion is best effort: lookup failures are logged and yield no suggestions.
+func (st *State) Complete(ctx context.Context, req *mcp.CompleteRequest) (*mcp.CompleteResult, error) {
+	arg := req.Params.Argument
+	var ctxArgs map[string]string
+	if req.Params.Context != nil {
+		ctxArgs = req.Params.Context.Arguments
+	}
+
+	var values []string
+	switch arg.Name {
+	case "collection":
+		names, err := st.collectionNames(ctx)
+		if err != nil {
+			slog.Warn("Failed to list collections for completion", "error", err)
+		}
+		values = matchPrefix(names, arg.Value)
+	case "fl":
+		values = completeList(arg.Value, st.completionFields(ctx, ctxArgs, false))
+	case "sort":
+		var options []string
+		for _, f := range st.completionFields(ctx, ctxArgs, true) {
+			options = append(options, f+" asc", f+" desc")
+		}
+		values = completeList(arg.Value, append(options, "score desc", "score asc"))
+	case "name":
+		var names []string
+		for _, q := range st.fileConfig().SavedQueries {
+			names = append(names, q.Name)
+		}
+		values = matchPrefix(names, arg.Value)
+	}
+
+	return completionResult(values), nil
+}
+
+// completionFields returns the field names of the collection given in the completion context.
+// Sortable fields exclude multi-valued fields.
+func (st *State) completionFields(ctx context.Context, ctxArgs map[string]string, sortable bool) []string {
+	collection := strings.TrimSpace(ctxArgs["collection"])
+	if collection == "" {
+		collection = st.DefaultCollection
+	}
+	fc, err := st.backend().Schema(ctx, collection)
+	if err != nil {
+		slog.Warn("Failed to get schema for completion", "collection", collection, "error", err)
+		return nil
+	}
+	var names []string
+	for _, f := range fc.All {
+		if sortable && f.MultiValued {
+			continue
+		}
+		names = append(names, f.Name)
+	}
+	return names
+}
+
+// completeList completes the last element of a comma-separated list, keeping the preceding elements.
+func completeList(value string, options []string) []string {
+	head, last := "", value
+	if i := strings.LastIndex(value, ","); i >= 0 {
+		head, last = value[:i+1], value[i+1:]
+	}
+	trimmed := strings.TrimLeft(last, " ")
+	head += last[:len(last)-len(trimmed)]
+
+	var out []string
+	for _, o := range matchPrefix(options, trimmed) {
+		out = append(out, head+o)
+	}
+	return out
+}
+
+// matchPrefix returns the sorted, de-duplicated candidates starting with prefix (case-insensitive).
+func matchPrefix(candidates []string, prefix string) []string {
+	lower := strings.ToLower(prefix)
+	seen := map[string]bool{}
+	var out []string
+	for _, c := range candidates {
+		if seen[c] || !strings.HasPrefix(strings.ToLower(c), lower) {
+			continue
+		}
+		seen[c] = true
+		out = append(out, c)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func completionResult(values []string) *mcp.CompleteResult {
+	total := len(values)
+	if values == nil {
+		values = []string{}
+	}
+	return &mcp.CompleteResult{
+		Completion: mcp.CompletionResultDetails{
+			Values:  values[:min(total, maxCompletionValues)],
+			Total:   total,
+			HasMore: total > maxCompletionValues,
+		},
+	}
+}
@@ -0,0 +1,119 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/config"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+)
+
+// newCompletionServer mocks the Solr APIs used for completion.
+func newCompletionServer(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/solr/admin/collections":
+			fmt.Fprintln(w, `{"collections":["logs","products","logs_archive"]}`)
+		case "/solr/products/schema/uniquekey":
+			fmt.Fprintln(w, `{"uniqueKey":"id"}`)
+		case "/solr/products/schema/fields":
+			json.NewEncoder(w).Encode(map[string]any{"fields": []map[string]any{
+				{"name": "id", "type": "string"},
+				{"name": "price", "type": "pfloat"},
+				{"name": "popularity", "type": "pint"},
+				{"name": "tags", "type": "string", "multiValued": true},
+			}})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+}
+
+// complete is a helper that issues a completion request for the given argument.
+func complete(t *testing.T, st *State, name, value string, ctxArgs map[string]string) mcp.CompletionResultDetails {
+	t.Helper()
+	params := &mcp.CompleteParams{
+		Ref:      &mcp.CompleteReference{Type: "ref/prompt", Name: "solr.search"},
+		Argument: mcp.CompleteParamsArgument{Name: name, Value: value},
+	}
+	if ctxArgs != nil {
+		params.Context = &mcp.CompleteContext{Arguments: ctxArgs}
+	}
+	res, err := st.Complete(context.Background(), &mcp.CompleteRequest{Params: params})
+	assert.NoError(t, err)
+	return res.Completion
+}
+
+// TestComplete tests the completion handler.
+func TestComplete(t *testing.T) {
+	server := newCompletionServer(t)
+	defer server.Close()
+	st := newTestState(t, server.URL)
+	st.Config = &config.FileConfig{SavedQueries: []config.SavedQuery{
+		{Name: "errors_5m", Collection: "logs"},
+		{Name: "bytes_1h", Collection: "logs"},
+	}}
+
+	// Goal: Collection names come from the Collections API LIST action.
+	t.Run("collection", func(t *testing.T) {
+		res := complete(t, st, "collection", "lo", nil)
+		assert.Equal(t, []string{"logs", "logs_archive"}, res.Values)
+		assert.Equal(t, 2, res.Total)
+	})
+
+	// Goal: Fields come from the schema of the collection in the context,
+	// and only the last element of a comma-separated list is completed.
+	t.Run("fl", func(t *testing.T) {
+		res := complete(t, st, "fl", "id, p", map[string]string{"collection": "products"})
+		assert.Equal(t, []string{"id, popularity", "id, price"}, res.Values)
+	})
+
+	// Goal: Sort suggestions exclude multi-valued fields and include directions.
+	t.Run("sort", func(t *testing.T) {
+		res := complete(t, st, "sort", "", map[string]string{"collection": "products"})
+		assert.Contains(t, res.Values, "price asc")
+		assert.Contains(t, res.Values, "price desc")
+		assert.Contains(t, res.Values, "score desc")
+		assert.NotContains(t, res.Values, "tags asc")
+	})
+
+	// Goal: Saved query names are completed for the name argument.
+	t.Run("name", func(t *testing.T) {
+		res := complete(t, st, "name", "E", nil)
+		assert.Equal(t, []string{"errors_5m"}, res.Values)
+	})
+
+	// Goal: Unknown arguments yield an empty (non-nil) list.
+	t.Run("unknown argument", func(t *testing.T) {
+		res := complete(t, st, "other", "", nil)
+		assert.NotNil(t, res.Values)
+		assert.Empty(t, res.Values)
+	})
+
+	// Goal: Lookup failures yield no suggestions instead of an error.
+	t.Run("schema lookup failure", func(t *testing.T) {
+		res := complete(t, st, "fl", "", map[string]string{"collection": "missing"})
+		assert.Empty(t, res.Values)
+	})
+}
+
+// TestCompletionResult tests that results are capped at the MCP maximum.
+func TestCompletionResult(t *testing.T) {
+	values := make([]string, 150)
+	for i := range values {
+		values[i] = fmt.Sprintf("v%03d", i)
+	}
+
+	res := completionResult(values)
+
+	assert.Len(t, res.Completion.Values, maxCompletionValues)
+	assert.Equal(t, 150, res.Completion.Total)
+	assert.True(t, res.Completion.HasMore)
+}
@@ -0,0 +1,107 @@
+package server
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/errcatalog"
+)
+
+// defaultConfirmationTTL is how long a confirmation token stays valid.
+const defaultConfirmationTTL = 5 * time.Minute
+
+// errConfirmationInvalid is returned when a confirmation token is unknown, expired or does not match the call.
+var errConfirmationInvalid = errcatalog.New(errcatalog.ConfirmationInvalid, "confirmation token is invalid, expired, already used or was issued for different arguments; call the tool again without confirm to get a new preview and token")
+
+// confirmation is a pending destructive call waiting for its second step.
+type confirmation struct {
+	tool    string
+	args    string
+	expires time.Time
+}
+
+// confirmations implements the two-call handshake of destructive tools: the first call returns a
+// preview and a one-time token, and only a second call with that token and the same arguments executes.
+type confirmations struct {
+	mu     sync.Mutex
+	ttl    time.Duration
+	tokens map[string]confirmation
+	now    func() time.Time
+}
+
+func newConfirmations(ttl time.Duration) *confirmations {
+	return &confirmations{ttl: ttl, tokens: make(map[string]confirmation), now: time.Now}
+}
+
+// issue returns a new token for a call of tool with args, and its expiry.
+func (c *confirmations) issue(tool string, args any) (string, time.Time, error) {
+	key, err := json.Marshal(args)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("encode arguments: %v", err)
+	}
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", time.Time{}, fmt.Errorf("generate token: %v", err)
+	}
+	token := hex.EncodeToString(b)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	for t, p := range c.tokens {
+		if now.After(p.expires) {
+			delete(c.tokens, t)
+		}
+	}
+	expires := now.Add(c.ttl)
+	c.tokens[token] = confirmation{tool: tool, args: string(key), expires: expires}
+	return token, expires, nil
+}
+
+// consume validates and invalidates a token. It fails unless the token was issued for the same tool and args.
+func (c *confirmations) consume(token, tool string, args any) error {
+	key, err := json.Marshal(args)
+	if err != nil {
+		return fmt.Errorf("encode arguments: %v", err)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	p, ok := c.tokens[token]
+	if !ok {
+		return errConfirmationInvalid
+	}
+	delete(c.tokens, token)
+	if p.tool != tool || p.args != string(key) || c.now().After(p.expires) {
+		return errConfirmationInvalid
+	}
+	return nil
+}
+
+// confirmationRequired builds the result of the first call of a destructive tool.
+func (st *State) confirmationRequired(tool string, args any, preview any) (any, error) {
+	token, expires, err := st.confirmStore().issue(tool, args)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{
+		"preview":           preview,
+		"confirmationToken": token,
+		"expiresAt":         expires.UTC().Format(time.RFC3339),
+		"message":           fmt.Sprintf("Nothing has been changed yet. Review the preview, then call %s again with the same arguments and confirm=%q to execute.", tool, token),
+	}, nil
+}
+
+// confirmStore returns the confirmation store, creating it on first use.
+func (st *State) confirmStore() *confirmations {
+	st.confirmOnce.Do(func() {
+		st.confirm = newConfirmations(st.ConfirmationTTL)
+		if st.confirm.ttl <= 0 {
+			st.confirm.ttl = defaultConfirmationTTL
+		}
+	})
+	return st.confirm
+}
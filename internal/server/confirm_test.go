@@ -0,0 +1,103 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/types"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestConfirmations tests issuing and consuming confirmation tokens.
+func TestConfirmations(t *testing.T) {
+	c := newConfirmations(time.Minute)
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	c.now = func() time.Time { return now }
+	args := types.DeleteIn{Collection: "logs", Query: "level:DEBUG"}
+
+	// Goal: A token is valid exactly once for the same tool and arguments.
+	token, expires, err := c.issue("solr.delete", args)
+	assert.NoError(t, err)
+	assert.Equal(t, now.Add(time.Minute), expires)
+	assert.NoError(t, c.consume(token, "solr.delete", args))
+	assert.ErrorIs(t, c.consume(token, "solr.delete", args), errConfirmationInvalid)
+
+	// Goal: A token cannot confirm different arguments or another tool.
+	token, _, _ = c.issue("solr.delete", args)
+	assert.ErrorIs(t, c.consume(token, "solr.delete", types.DeleteIn{Collection: "logs", Query: "*:*"}), errConfirmationInvalid)
+	token, _, _ = c.issue("solr.delete", args)
+	assert.ErrorIs(t, c.consume(token, "solr.collection.drop", args), errConfirmationInvalid)
+
+	// Goal: Expired tokens are rejected.
+	token, _, _ = c.issue("solr.delete", args)
+	now = now.Add(2 * time.Minute)
+	assert.ErrorIs(t, c.consume(token, "solr.delete", args), errConfirmationInvalid)
+
+	// Goal: Unknown tokens are rejected.
+	assert.ErrorIs(t, c.consume("unknown", "solr.delete", args), errConfirmationInvalid)
+}
+
+// TestToolDelete tests the two-call handshake of solr.delete.
+func TestToolDelete(t *testing.T) {
+	var updates []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/solr/logs/schema/uniquekey":
+			fmt.Fprintln(w, `{"uniqueKey":"id"}`)
+		case "/solr/logs/schema/fields":
+			fmt.Fprintln(w, `{"fields":[]}`)
+		case "/solr/logs/select":
+			fmt.Fprintln(w, `{"response":{"numFound":2,"docs":[{"id":"a"},{"id":"b"}]}}`)
+		case "/solr/logs/update":
+			body, _ := io.ReadAll(r.Body)
+			updates = append(updates, string(body))
+			fmt.Fprintln(w, `{"responseHeader":{"status":0}}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	st := newTestState(t, server.URL)
+
+	// Goal: Exactly one of query and ids is accepted.
+	_, _, err := st.toolDelete(context.Background(), nil, types.DeleteIn{Collection: "logs"})
+	assert.ErrorContains(t, err, "exactly one of")
+
+	// Goal: preview only reports the matching documents and issues no token.
+	_, out, err := st.toolDelete(context.Background(), nil, types.DeleteIn{Collection: "logs", IDs: []string{"a", "b"}, Preview: true})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), out.(map[string]any)["matched"])
+	assert.Equal(t, "{!terms f=id}a,b", out.(map[string]any)["query"])
+	assert.NotContains(t, out, "confirmationToken")
+	assert.Empty(t, updates)
+
+	// Goal: The first call previews the matching documents and deletes nothing.
+	in := types.DeleteIn{Collection: "logs", Query: "level:DEBUG"}
+	_, out, err = st.toolDelete(context.Background(), nil, in)
+	assert.NoError(t, err)
+	res := out.(map[string]any)
+	preview := res["preview"].(map[string]any)
+	assert.Equal(t, int64(2), preview["matched"])
+	assert.Equal(t, []string{"a", "b"}, preview["sampleIds"])
+	assert.Empty(t, updates)
+
+	// Goal: The token does not confirm a different query.
+	token := res["confirmationToken"].(string)
+	_, _, err = st.toolDelete(context.Background(), nil, types.DeleteIn{Collection: "logs", Query: "*:*", Confirm: token})
+	assert.ErrorIs(t, err, errConfirmationInvalid)
+	assert.Empty(t, updates)
+
+	// Goal: The second call with a fresh token executes the delete.
+	_, out, _ = st.toolDelete(context.Background(), nil, in)
+	in.Confirm = out.(map[string]any)["confirmationToken"].(string)
+	_, out, err = st.toolDelete(context.Background(), nil, in)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), out.(map[string]any)["deleted"])
+	assert.Equal(t, []string{`{"delete":{"query":"level:DEBUG"}}`}, updates)
+}
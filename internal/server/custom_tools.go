@@ -0,0 +1,72 @@
+package server
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// customTool is a tool registered with RegisterTool before AddTools ran.
+type customTool struct {
+	name     string
+	register func(*mcp.Server)
+}
+
+// RegisterTool adds a custom tool alongside the built-in ones. Custom tools go through the same
+// argument size limit, post-processing, disabledTools and tool filter as the built-in tools.
+// Tools registered before AddTools are added after the built-in tools; tools registered later are
+// added to the running server immediately, which notifies connected sessions.
+func RegisterTool[In, Out any](st *State, t *mcp.Tool, h mcp.ToolHandlerFor[In, Out]) error {
+	st.toolMu.Lock()
+	if slices.Contains(st.toolOrder, t.Name) || slices.ContainsFunc(st.customTools, func(c customTool) bool { return c.name == t.Name }) {
+		st.toolMu.Unlock()
+		return fmt.Errorf("tool %s is already registered", t.Name)
+	}
+	mcpServer := st.mcpServer
+	if mcpServer == nil {
+		st.customTools = append(st.customTools, customTool{
+			name:     t.Name,
+			register: func(s *mcp.Server) { addTool(s, st, t, h) },
+		})
+		st.toolMu.Unlock()
+		return nil
+	}
+	st.toolMu.Unlock()
+
+	addTool(mcpServer, st, t, h)
+	slog.Info("Custom tool registered", "tool", t.Name)
+	return nil
+}
+
+// WithTool registers a custom tool when the server is created. See RegisterTool.
+func WithTool[In, Out any](t *mcp.Tool, h mcp.ToolHandlerFor[In, Out]) Option {
+	return func(st *State) {
+		if err := RegisterTool(st, t, h); err != nil {
+			slog.Error("Failed to register custom tool", "tool", t.Name, "error", err)
+		}
+	}
+}
+
+// addCustomTools registers the pending custom tools on mcpServer, skipping those that clash with built-in tools,
+// and remembers mcpServer for tools registered later.
+func (st *State) addCustomTools(mcpServer *mcp.Server) []string {
+	st.toolMu.Lock()
+	pending := st.customTools
+	st.customTools = nil
+	st.mcpServer = mcpServer
+	builtins := slices.Clone(st.toolOrder)
+	st.toolMu.Unlock()
+
+	var names []string
+	for _, c := range pending {
+		if slices.Contains(builtins, c.name) {
+			slog.Error("Custom tool clashes with a built-in tool and is skipped", "tool", c.name)
+			continue
+		}
+		c.register(mcpServer)
+		names = append(names, c.name)
+	}
+	return names
+}
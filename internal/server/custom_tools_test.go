@@ -0,0 +1,56 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/config"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+)
+
+type greetIn struct {
+	Name string `json:"name"`
+}
+
+func greet(_ context.Context, _ *mcp.CallToolRequest, in greetIn) (*mcp.CallToolResult, any, error) {
+	return nil, map[string]any{"greeting": "hello " + in.Name}, nil
+}
+
+// TestRegisterTool tests registering custom tools before and after AddTools.
+func TestRegisterTool(t *testing.T) {
+	st := NewServer(
+		WithTool(&mcp.Tool{Name: "acme.greet", Description: "Greet"}, greet),
+		WithConfig(&config.FileConfig{PostProcessing: config.PostProcessingConfig{
+			Tools:        map[string][]string{"acme.greet": {"redact"}},
+			RedactFields: []string{"greeting"},
+		}}, ""),
+	)
+
+	// Goal: Names already registered are rejected.
+	assert.Error(t, RegisterTool(st, &mcp.Tool{Name: "acme.greet"}, greet))
+
+	mcpServer := mcp.NewServer(&mcp.Implementation{}, nil)
+	names := AddTools(mcpServer, st)
+
+	// Goal: Custom tools registered before AddTools follow the built-in tools.
+	assert.Equal(t, append(append([]string{}, allToolNames...), "acme.greet"), names)
+
+	// Goal: Custom tools clashing with a built-in tool are rejected.
+	assert.Error(t, RegisterTool(st, &mcp.Tool{Name: "solr.query"}, greet))
+
+	// Goal: Custom tools registered later are added to the running server.
+	session, toolsChanged, _ := connectTestClient(t, mcpServer)
+	assert.NoError(t, RegisterTool(st, &mcp.Tool{Name: "acme.greet2", Description: "Greet again"}, greet))
+	<-toolsChanged
+	assert.Contains(t, st.EnabledToolNames(), "acme.greet2")
+
+	// Goal: Custom tools share the post-processing of built-in tools.
+	res, err := session.CallTool(context.Background(), &mcp.CallToolParams{Name: "acme.greet", Arguments: map[string]any{"name": "solr"}})
+	assert.NoError(t, err)
+	assert.Equal(t, "[REDACTED]", res.StructuredContent.(map[string]any)["greeting"])
+	res, err = session.CallTool(context.Background(), &mcp.CallToolParams{Name: "acme.greet2", Arguments: map[string]any{"name": "solr"}})
+	assert.NoError(t, err)
+	assert.Equal(t, "hello solr", res.StructuredContent.(map[string]any)["greeting"])
+}
@@ -0,0 +1,89 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/solr"
+	"github.com/Sashimimochi/solr-mcp-go/internal/types"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	// defaultDedupeThreshold is the minimum estimated similarity of MinHash duplicates.
+	defaultDedupeThreshold = 0.8
+	// defaultDedupeMaxDocs limits how many documents a MinHash scan reads.
+	defaultDedupeMaxDocs = 10000
+)
+
+func (st *State) toolDedupeFind(ctx context.Context, _ *mcp.CallToolRequest, in types.DedupeIn) (*mcp.CallToolResult, any, error) {
+	if strings.TrimSpace(in.Collection) == "" {
+		return nil, nil, errors.New("input.collection is required")
+	}
+	if in.SignatureField == "" && len(in.Fields) == 0 {
+		return nil, nil, errors.New("input.signatureField or input.fields is required")
+	}
+	threshold := in.Threshold
+	if threshold == 0 {
+		threshold = defaultDedupeThreshold
+	}
+	if threshold <= 0 || threshold > 1 {
+		return nil, nil, fmt.Errorf("input.threshold must be between 0 and 1, got %g", in.Threshold)
+	}
+	maxDocs := in.MaxDocs
+	if maxDocs <= 0 {
+		maxDocs = defaultDedupeMaxDocs
+	}
+	key, err := st.requireUniqueKey(ctx, in.Collection)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	res, err := solr.FindDuplicates(ctx, st.backend(), in.Collection, solr.DedupeOptions{
+		Query:          in.Query,
+		SignatureField: in.SignatureField,
+		Fields:         in.Fields,
+		Threshold:      threshold,
+		MaxDocs:        maxDocs,
+		UniqueKey:      key,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	slog.Info("Duplicate scan finished", "collection", in.Collection, "method", res.Method, "scanned", res.Scanned, "clusters", len(res.Clusters), "duplicates", res.Duplicates)
+	if !in.DeletePreview || res.Duplicates == 0 {
+		return nil, res, nil
+	}
+
+	// Keep the first document of each cluster and prepare a confirmed solr.delete of the others
+	var keep, remove []string
+	for _, c := range res.Clusters {
+		keep = append(keep, c.IDs[0])
+		remove = append(remove, c.IDs[1:]...)
+	}
+	deleteIn := types.DeleteIn{Collection: in.Collection, IDs: remove}
+	confirmation, err := st.confirmationRequired("solr.delete", deleteIn, map[string]any{
+		"collection": in.Collection,
+		"keep":       keep,
+		"delete":     remove,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return nil, map[string]any{
+		"method":     res.Method,
+		"scanned":    res.Scanned,
+		"clusters":   res.Clusters,
+		"duplicates": res.Duplicates,
+		"truncated":  res.Truncated,
+		"deletePreview": map[string]any{
+			"tool":         "solr.delete",
+			"arguments":    deleteIn,
+			"confirmation": confirmation,
+		},
+	}, nil
+}
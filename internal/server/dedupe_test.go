@@ -0,0 +1,49 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/solr"
+	"github.com/Sashimimochi/solr-mcp-go/internal/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestToolDedupeFind tests duplicate detection and the prepared delete of the duplicates.
+func TestToolDedupeFind(t *testing.T) {
+	b := &fakeBackend{docs: map[string][]map[string]any{
+		"news": {
+			{"id": "1", "body": "Apache Solr 10 released with new vector search features"},
+			{"id": "2", "body": "Apache Solr 10 released with new vector search features"},
+			{"id": "3", "body": "Weather forecast for the weekend: sunny with light winds"},
+		},
+	}}
+	st := NewServer(WithBackend(b))
+	ctx := context.Background()
+
+	// Goal: Either a signature field or text fields are required.
+	_, _, err := st.toolDedupeFind(ctx, nil, types.DedupeIn{Collection: "news"})
+	assert.ErrorContains(t, err, "signatureField or input.fields is required")
+	_, _, err = st.toolDedupeFind(ctx, nil, types.DedupeIn{Collection: "news", Fields: []string{"body"}, Threshold: 1.5})
+	assert.ErrorContains(t, err, "threshold")
+
+	// Goal: Duplicates are reported as clusters without changing anything.
+	_, out, err := st.toolDedupeFind(ctx, nil, types.DedupeIn{Collection: "news", Fields: []string{"body"}})
+	require.NoError(t, err)
+	res := out.(*solr.DedupeResult)
+	require.Len(t, res.Clusters, 1)
+	assert.Equal(t, []string{"1", "2"}, res.Clusters[0].IDs)
+
+	// Goal: deletePreview prepares a solr.delete of all but the first document that runs with its token.
+	_, out, err = st.toolDedupeFind(ctx, nil, types.DedupeIn{Collection: "news", Fields: []string{"body"}, DeletePreview: true})
+	require.NoError(t, err)
+	preview := out.(map[string]any)["deletePreview"].(map[string]any)
+	deleteIn := preview["arguments"].(types.DeleteIn)
+	assert.Equal(t, []string{"2"}, deleteIn.IDs)
+	deleteIn.Confirm = preview["confirmation"].(map[string]any)["confirmationToken"].(string)
+	_, _, err = st.toolDelete(ctx, nil, deleteIn)
+	require.NoError(t, err)
+	assert.Len(t, b.docs["news"], 2)
+}
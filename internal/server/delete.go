@@ -0,0 +1,137 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/solr"
+	"github.com/Sashimimochi/solr-mcp-go/internal/types"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// previewSampleSize is the number of document IDs included in previews of destructive tools.
+const previewSampleSize = 10
+
+// deleteQuery validates a delete request and returns the query selecting the documents to delete.
+func (st *State) deleteQuery(ctx context.Context, in types.DeleteIn) (string, string, error) {
+	if strings.TrimSpace(in.Collection) == "" {
+		return "", "", errors.New("input.collection is required")
+	}
+	if (strings.TrimSpace(in.Query) == "") == (len(in.IDs) == 0) {
+		return "", "", errors.New("exactly one of input.query and input.ids is required")
+	}
+	key, err := st.requireUniqueKey(ctx, in.Collection)
+	if err != nil {
+		return "", "", err
+	}
+	if len(in.IDs) > 0 {
+		return solr.IDsQuery(key, in.IDs), key, nil
+	}
+	return in.Query, key, nil
+}
+
+// deletePreview counts and samples the documents a delete would remove.
+func (st *State) deletePreview(ctx context.Context, in types.DeleteIn) (map[string]any, error) {
+	query, key, err := st.deleteQuery(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	matched, err := st.backend().Count(ctx, in.Collection, query)
+	if err != nil {
+		return nil, err
+	}
+	sample, err := solr.SampleIDs(ctx, st.backend(), in.Collection, query, key, previewSampleSize)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{
+		"collection": in.Collection,
+		"query":      query,
+		"matched":    matched,
+		"sampleIds":  sample,
+	}, nil
+}
+
+func (st *State) toolDelete(ctx context.Context, _ *mcp.CallToolRequest, in types.DeleteIn) (*mcp.CallToolResult, any, error) {
+	if in.Preview {
+		preview, err := st.deletePreview(ctx, in)
+		if err != nil {
+			return nil, nil, err
+		}
+		return nil, preview, nil
+	}
+	token := in.Confirm
+	in.Confirm = ""
+	if token == "" {
+		preview, err := st.deletePreview(ctx, in)
+		if err != nil {
+			return nil, nil, err
+		}
+		out, err := st.confirmationRequired("solr.delete", in, preview)
+		return nil, out, err
+	}
+	if err := st.confirmStore().consume(token, "solr.delete", in); err != nil {
+		return nil, nil, err
+	}
+
+	query, _, err := st.deleteQuery(ctx, in)
+	if err != nil {
+		return nil, nil, err
+	}
+	before, err := st.backend().Count(ctx, in.Collection, query)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(in.IDs) > 0 {
+		err = st.backend().DeleteByIDs(ctx, in.Collection, in.IDs)
+	} else {
+		err = st.backend().DeleteByQuery(ctx, in.Collection, query)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	slog.Info("Documents deleted", "collection", in.Collection, "query", query, "matched", before)
+	return nil, map[string]any{
+		"collection": in.Collection,
+		"query":      query,
+		"deleted":    before,
+	}, nil
+}
+
+func (st *State) toolCollectionDrop(ctx context.Context, _ *mcp.CallToolRequest, in types.DropCollectionIn) (*mcp.CallToolResult, any, error) {
+	if strings.TrimSpace(in.Collection) == "" {
+		return nil, nil, errors.New("input.collection is required")
+	}
+	token := in.Confirm
+	in.Confirm = ""
+	if token == "" {
+		status, err := solr.GetClusterStatus(ctx, st.HttpClient, st.solrURL(), st.BasicUser, st.BasicPass, in.Collection)
+		if err != nil {
+			return nil, nil, err
+		}
+		numDocs, err := st.backend().Count(ctx, in.Collection, "*:*")
+		if err != nil {
+			return nil, nil, err
+		}
+		out, err := st.confirmationRequired("solr.collection.drop", in, map[string]any{
+			"collection": in.Collection,
+			"numDocs":    numDocs,
+			"shards":     len(status.Shards),
+			"configName": status.ConfigName,
+		})
+		return nil, out, err
+	}
+	if err := st.confirmStore().consume(token, "solr.collection.drop", in); err != nil {
+		return nil, nil, err
+	}
+
+	if err := st.backend().DeleteCollection(ctx, in.Collection); err != nil {
+		return nil, nil, err
+	}
+	st.invalidateCollections()
+	slog.Info("Collection dropped", "collection", in.Collection)
+	return nil, map[string]any{"collection": in.Collection, "dropped": true}, nil
+}
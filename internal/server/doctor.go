@@ -0,0 +1,155 @@
+package server
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/config"
+	"github.com/Sashimimochi/solr-mcp-go/internal/policy"
+	"github.com/Sashimimochi/solr-mcp-go/internal/solr"
+)
+
+// Check statuses of the doctor report.
+const (
+	CheckPass = "PASS"
+	CheckWarn = "WARN"
+	CheckFail = "FAIL"
+	CheckSkip = "SKIP"
+)
+
+// CheckResult is a single line of the doctor report.
+type CheckResult struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Detail string `json:"detail"`
+	Hint   string `json:"hint,omitempty"`
+}
+
+// Doctor validates the environment configuration and the connection to Solr, and returns one result per check.
+func Doctor(ctx context.Context) []CheckResult {
+	var results []CheckResult
+	add := func(name, status, detail, hint string) {
+		results = append(results, CheckResult{Name: name, Status: status, Detail: detail, Hint: hint})
+	}
+
+	configPath := config.GetEnv("SOLR_MCP_CONFIG_FILE", "")
+	if configPath == "" {
+		add("config file", CheckSkip, "SOLR_MCP_CONFIG_FILE is not set", "")
+	} else if fc, err := config.LoadFileConfig(configPath); err != nil {
+		add("config file", CheckFail, err.Error(), "Fix the config file; the server starts without it otherwise.")
+	} else {
+		add("config file", CheckPass, fmt.Sprintf("%s (%d saved queries, %d retention policies)",
+			configPath, len(fc.SavedQueries), len(fc.Retention.Policies)), "")
+		if err := policy.Compile(fc.Policy); err != nil {
+			add("policy", CheckFail, err.Error(), "Every tool call is denied until the rules compile.")
+		} else if fc.Policy.Enabled() {
+			add("policy", CheckPass, fmt.Sprintf("%d rules, default %s", len(fc.Policy.Rules), cmp.Or(fc.Policy.Default, "allow")), "")
+		}
+	}
+
+	for _, key := range []string{"SOLR_BASIC_USER", "SOLR_BASIC_PASS"} {
+		if _, err := config.GetSecret(ctx, key); err != nil {
+			add("credentials", CheckFail, err.Error(), "Check the "+key+" secret source.")
+		}
+	}
+	if _, err := config.NewHTTPClient(config.GetEnv("SOLR_MCP_PROXY_URL", "")); err != nil {
+		add("proxy", CheckFail, err.Error(), "Set SOLR_MCP_PROXY_URL to a URL such as http://proxy:3128.")
+	}
+	for _, key := range []string{"SOLR_MCP_CONFIRMATION_TTL", "SOLR_MCP_CONFIG_RELOAD_INTERVAL", "SOLR_MCP_FAILOVER_PROBE_INTERVAL"} {
+		if v := config.GetEnv(key, ""); v != "" {
+			if d, err := time.ParseDuration(v); err != nil || d <= 0 {
+				add("settings", CheckWarn, fmt.Sprintf("%s=%q is not a positive duration and is ignored", key, v), "Use a Go duration such as 30s or 5m.")
+			}
+		}
+	}
+
+	return append(results, NewServerState().doctor(ctx)...)
+}
+
+// doctor checks the connection to the search backend and the default collection.
+func (st *State) doctor(ctx context.Context) []CheckResult {
+	var results []CheckResult
+	add := func(name, status, detail, hint string) {
+		results = append(results, CheckResult{Name: name, Status: status, Detail: detail, Hint: hint})
+	}
+	url := config.RedactURL(st.BaseURL)
+
+	status, err := st.backend().ClusterStatus(ctx, "")
+	if err != nil {
+		switch {
+		case strings.Contains(err.Error(), "HTTP status 401"), strings.Contains(err.Error(), "HTTP status 403"):
+			add("connection", CheckPass, url+" is reachable", "")
+			add("authentication", CheckFail, err.Error(), "Check SOLR_BASIC_USER and SOLR_BASIC_PASS.")
+		default:
+			add("connection", CheckFail, fmt.Sprintf("%s: %v", url, err), "Check SOLR_MCP_SOLR_URL, the proxy settings and that Solr runs in SolrCloud mode.")
+		}
+		add("collections", CheckSkip, "the search backend is not available", "")
+		return append(results, st.doctorStandby(ctx)...)
+	}
+	add("connection", CheckPass, fmt.Sprintf("%s (%d live nodes)", url, len(status.Cluster.LiveNodes)), "")
+	if st.BasicUser != "" {
+		add("authentication", CheckPass, "authenticated as "+st.BasicUser, "")
+	} else {
+		add("authentication", CheckPass, "no credentials configured and none required", "")
+	}
+
+	names, err := st.backend().ListCollections(ctx)
+	switch {
+	case err != nil:
+		add("collections", CheckFail, err.Error(), "The Solr user needs permission to list collections.")
+	case len(names) == 0:
+		add("collections", CheckWarn, "no collections found", "Create a collection before using the tools.")
+	default:
+		shown := names
+		if len(shown) > 10 {
+			shown = append(slices.Clone(shown[:10]), "…")
+		}
+		add("collections", CheckPass, fmt.Sprintf("%d: %s", len(names), strings.Join(shown, ", ")), "")
+	}
+
+	if err == nil && !slices.Contains(names, st.DefaultCollection) {
+		add("default collection", CheckWarn, fmt.Sprintf("%q does not exist", st.DefaultCollection), "Set SOLR_MCP_DEFAULT_COLLECTION to one of the collections.")
+	} else if fc, err := st.backend().Schema(ctx, st.DefaultCollection); err != nil {
+		add("default collection", CheckFail, err.Error(), "")
+	} else {
+		add("default collection", CheckPass, fmt.Sprintf("%s (uniqueKey %s, %d fields)", st.DefaultCollection, fc.UniqueKey, len(fc.All)), "")
+	}
+	return append(results, st.doctorStandby(ctx)...)
+}
+
+// doctorStandby checks the standby cluster, if one is configured.
+func (st *State) doctorStandby(ctx context.Context) []CheckResult {
+	if st.failoverMonitor() == nil {
+		return nil
+	}
+	b := &solr.HTTPBackend{HttpClient: st.HttpClient, BaseURL: st.StandbyURL, User: st.BasicUser, Pass: st.BasicPass}
+	if _, err := b.ClusterStatus(ctx, ""); err != nil {
+		return []CheckResult{{Name: "standby", Status: CheckFail, Detail: fmt.Sprintf("%s: %v", config.RedactURL(st.StandbyURL), err), Hint: "Check SOLR_MCP_STANDBY_URL; failover would not work."}}
+	}
+	return []CheckResult{{Name: "standby", Status: CheckPass, Detail: config.RedactURL(st.StandbyURL) + " is reachable"}}
+}
+
+// WriteReport prints the doctor results and returns the number of failed checks.
+func WriteReport(w io.Writer, results []CheckResult) int {
+	failures := 0
+	for _, r := range results {
+		fmt.Fprintf(w, "[%s] %s: %s\n", r.Status, r.Name, r.Detail)
+		if r.Hint != "" {
+			fmt.Fprintf(w, "       %s\n", r.Hint)
+		}
+		if r.Status == CheckFail {
+			failures++
+		}
+	}
+	if failures == 0 {
+		fmt.Fprintln(w, "\nAll checks passed.")
+	} else {
+		fmt.Fprintf(w, "\n%d check(s) failed.\n", failures)
+	}
+	return failures
+}
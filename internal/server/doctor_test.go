@@ -0,0 +1,73 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestDoctor tests the connection checks of the doctor report.
+func TestDoctor(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, _, _ := r.BasicAuth(); user == "intruder" {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		switch {
+		case r.URL.Path == "/solr/admin/collections" && r.URL.Query().Get("action") == "LIST":
+			fmt.Fprintln(w, `{"collections":["logs","products"]}`)
+		case r.URL.Path == "/solr/admin/collections":
+			fmt.Fprintln(w, `{"cluster":{"live_nodes":["node1:8983_solr"],"collections":{}}}`)
+		case r.URL.Path == "/solr/logs/schema/uniquekey":
+			fmt.Fprintln(w, `{"uniqueKey":"id"}`)
+		case r.URL.Path == "/solr/logs/schema/fields":
+			fmt.Fprintln(w, `{"fields":[{"name":"id","type":"string"},{"name":"message","type":"text_general"}]}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	ctx := context.Background()
+	statuses := func(results []CheckResult) map[string]string {
+		m := map[string]string{}
+		for _, r := range results {
+			m[r.Name] = r.Status
+		}
+		return m
+	}
+
+	// Goal: A healthy setup passes all checks.
+	st := newTestState(t, server.URL)
+	st.DefaultCollection = "logs"
+	results := st.doctor(ctx)
+	assert.Equal(t, map[string]string{
+		"connection": CheckPass, "authentication": CheckPass, "collections": CheckPass, "default collection": CheckPass,
+	}, statuses(results))
+	var out bytes.Buffer
+	assert.Equal(t, 0, WriteReport(&out, results))
+	assert.Contains(t, out.String(), "[PASS] collections: 2: logs, products")
+	assert.Contains(t, out.String(), "All checks passed.")
+
+	// Goal: A missing default collection is a warning.
+	st.DefaultCollection = "missing"
+	assert.Equal(t, CheckWarn, statuses(st.doctor(ctx))["default collection"])
+
+	// Goal: Rejected credentials fail the authentication check and skip the rest.
+	st.BasicUser = "intruder"
+	results = st.doctor(ctx)
+	assert.Equal(t, map[string]string{
+		"connection": CheckPass, "authentication": CheckFail, "collections": CheckSkip,
+	}, statuses(results))
+	out.Reset()
+	assert.Equal(t, 1, WriteReport(&out, results))
+	assert.Contains(t, out.String(), "Check SOLR_BASIC_USER and SOLR_BASIC_PASS.")
+
+	// Goal: An unreachable Solr fails the connection check.
+	server.Close()
+	assert.Equal(t, CheckFail, statuses(st.doctor(ctx))["connection"])
+}
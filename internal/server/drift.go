@@ -0,0 +1,102 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/drift"
+	"github.com/Sashimimochi/solr-mcp-go/internal/notify"
+	"github.com/Sashimimochi/solr-mcp-go/internal/solr"
+	"github.com/Sashimimochi/solr-mcp-go/internal/types"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// driftManager returns the drift detection manager, creating it on first use.
+// Snapshots are always taken from the primary Solr, so a failover does not look like drift.
+func (st *State) driftManager() *drift.Manager {
+	st.driftOnce.Do(func() {
+		var store *drift.Store
+		if st.DataDir != "" {
+			store = drift.NewStore(filepath.Join(st.DataDir, "drift"))
+		}
+		fetch := func(ctx context.Context, collection string) (map[string]any, map[string]any, error) {
+			return solr.GetSchemaAndOverlay(ctx, solr.SchemaContext{HttpClient: st.HttpClient, BaseURL: st.BaseURL, User: st.BasicUser, Pass: st.BasicPass}, collection)
+		}
+		st.drift = drift.NewManager(st.fileConfig().Drift, st.DefaultCollection, store, fetch, st.notifyDrift)
+	})
+	return st.drift
+}
+
+// notifier returns a notifier for the current configuration.
+func (st *State) notifier() *notify.Notifier {
+	return notify.New(st.fileConfig().Notifier)
+}
+
+// notifyDrift alerts about schema or config changes found by a drift check.
+func (st *State) notifyDrift(ctx context.Context, r drift.Report) {
+	counts := map[string]int{}
+	var categories []string
+	for _, c := range r.Changes {
+		if counts[c.Category] == 0 {
+			categories = append(categories, c.Category)
+		}
+		counts[c.Category]++
+	}
+	parts := make([]string, len(categories))
+	for i, c := range categories {
+		parts[i] = fmt.Sprintf("%d %s", counts[c], c)
+	}
+	summary := fmt.Sprintf("Schema/config drift in collection %s: %d changes (%s) since the snapshot of %s",
+		r.Collection, len(r.Changes), strings.Join(parts, ", "), r.Previous.Format(time.RFC3339))
+	if err := st.notifier().Send(ctx, notify.Event{Type: "schema_drift", Summary: summary, Details: r}); err != nil {
+		slog.Error("Failed to send drift notification", "collection", r.Collection, "error", err)
+	}
+}
+
+func (st *State) toolDriftReport(ctx context.Context, _ *mcp.CallToolRequest, in types.DriftIn) (*mcp.CallToolResult, any, error) {
+	if e := st.CapabilityError("drift_detection"); e != nil {
+		return nil, nil, e
+	}
+	collection := in.Collection
+	if collection == "" {
+		collection = st.DefaultCollection
+	}
+	if strings.TrimSpace(collection) == "" {
+		return nil, nil, errors.New("input.collection is required")
+	}
+	var from, to time.Time
+	var err error
+	if in.From != "" {
+		if from, err = time.Parse(time.RFC3339, in.From); err != nil {
+			return nil, nil, fmt.Errorf("input.from: %v", err)
+		}
+	}
+	if in.To != "" {
+		if to, err = time.Parse(time.RFC3339, in.To); err != nil {
+			return nil, nil, fmt.Errorf("input.to: %v", err)
+		}
+	}
+
+	out := map[string]any{}
+	m := st.driftManager()
+	if in.Check {
+		report, err := m.Check(ctx, collection)
+		if err != nil {
+			return nil, nil, err
+		}
+		out["check"] = report
+	}
+	timeline, err := m.Timeline(collection, from, to)
+	if err != nil {
+		return nil, nil, err
+	}
+	out["timeline"] = timeline
+	out["interval"] = st.fileConfig().Drift.Interval
+	return nil, out, nil
+}
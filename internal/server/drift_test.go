@@ -0,0 +1,48 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/drift"
+	"github.com/Sashimimochi/solr-mcp-go/internal/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestToolDriftReport tests taking and listing drift snapshots.
+func TestToolDriftReport(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/solr/products/schema":
+			_, _ = w.Write([]byte(`{"schema":{"name":"products","uniqueKey":"id"}}`))
+		case "/solr/products/config/overlay":
+			_, _ = w.Write([]byte(`{"overlay":{"znodeVersion":0}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	ctx := context.Background()
+
+	// Goal: Without a data directory, the tool reports the missing capability.
+	st := newTestState(t, srv.URL)
+	_, _, err := st.toolDriftReport(ctx, nil, types.DriftIn{Collection: "products"})
+	assert.ErrorContains(t, err, "drift_detection")
+
+	// Goal: A check stores the first snapshot and lists it in the timeline.
+	st = newTestState(t, srv.URL)
+	st.DataDir = t.TempDir()
+	_, out, err := st.toolDriftReport(ctx, nil, types.DriftIn{Collection: "products", Check: true})
+	require.NoError(t, err)
+	res := out.(map[string]any)
+	assert.True(t, res["check"].(drift.Report).First)
+	assert.Len(t, res["timeline"].(drift.Timeline).Snapshots, 1)
+
+	// Goal: Invalid time bounds are rejected.
+	_, _, err = st.toolDriftReport(ctx, nil, types.DriftIn{Collection: "products", From: "yesterday"})
+	assert.ErrorContains(t, err, "input.from")
+}
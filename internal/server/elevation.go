@@ -0,0 +1,148 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/solr"
+	"github.com/Sashimimochi/solr-mcp-go/internal/types"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// elevationRules reads the elevation rules of collection from file (default: elevate.xml).
+func (st *State) elevationRules(ctx context.Context, collection, file string) ([]solr.ElevationRule, error) {
+	data, err := solr.GetConfigFile(ctx, st.HttpClient, st.solrURL(), st.BasicUser, st.BasicPass, collection, file)
+	if err != nil {
+		return nil, fmt.Errorf("%v; is QueryElevationComponent configured with config-file %s?", err, file)
+	}
+	return solr.ParseElevateXML(data)
+}
+
+func (st *State) toolElevationList(ctx context.Context, _ *mcp.CallToolRequest, in types.ElevationListIn) (*mcp.CallToolResult, any, error) {
+	if strings.TrimSpace(in.Collection) == "" {
+		return nil, nil, errors.New("input.collection is required")
+	}
+	file := in.File
+	if file == "" {
+		file = solr.DefaultElevateFile
+	}
+	rules, err := st.elevationRules(ctx, in.Collection, file)
+	if err != nil {
+		return nil, nil, err
+	}
+	if in.Query != "" {
+		rules = slices.DeleteFunc(rules, func(r solr.ElevationRule) bool { return !strings.EqualFold(r.Query, in.Query) })
+	}
+	return nil, map[string]any{
+		"collection": in.Collection,
+		"file":       file,
+		"rules":      rules,
+	}, nil
+}
+
+func (st *State) toolElevationSet(ctx context.Context, _ *mcp.CallToolRequest, in types.ElevationSetIn) (*mcp.CallToolResult, any, error) {
+	if strings.TrimSpace(in.Collection) == "" {
+		return nil, nil, errors.New("input.collection is required")
+	}
+	if strings.TrimSpace(in.Query) == "" {
+		return nil, nil, errors.New("input.query is required")
+	}
+	if in.Match != "" && in.Match != "exact" && in.Match != "subset" {
+		return nil, nil, errors.New("input.match must be exact or subset")
+	}
+	if !in.Remove && len(in.Elevate) == 0 && len(in.Exclude) == 0 {
+		return nil, nil, errors.New("input.elevate or input.exclude is required unless remove is set")
+	}
+	for _, id := range in.Elevate {
+		if slices.Contains(in.Exclude, id) {
+			return nil, nil, fmt.Errorf("document %s is both elevated and excluded", id)
+		}
+	}
+	file := in.File
+	if file == "" {
+		file = solr.DefaultElevateFile
+	}
+
+	rules, err := st.elevationRules(ctx, in.Collection, file)
+	if err != nil {
+		return nil, nil, err
+	}
+	idx := slices.IndexFunc(rules, func(r solr.ElevationRule) bool { return r.Query == in.Query })
+	out := map[string]any{"collection": in.Collection, "file": file, "query": in.Query}
+	if idx >= 0 {
+		out["before"] = rules[idx]
+	}
+	switch {
+	case in.Remove && idx < 0:
+		return nil, nil, fmt.Errorf("no elevation rule for query %q", in.Query)
+	case in.Remove:
+		rules = slices.Delete(rules, idx, idx+1)
+	default:
+		rule := solr.ElevationRule{Query: in.Query, Elevate: in.Elevate, Exclude: in.Exclude}
+		if in.Match == "subset" {
+			rule.Match = "subset"
+		}
+		if idx >= 0 {
+			rules[idx] = rule
+		} else {
+			rules = append(rules, rule)
+		}
+		out["after"] = rule
+		if missing, err := st.missingIDs(ctx, in.Collection, append(append([]string{}, in.Elevate...), in.Exclude...)); err == nil && len(missing) > 0 {
+			out["missing"] = missing
+		}
+	}
+	if in.Preview {
+		out["preview"] = true
+		out["xml"] = string(solr.RenderElevateXML(rules))
+		return nil, out, nil
+	}
+
+	// The file belongs to the configset, so every collection using it is reloaded to apply the change consistently
+	status, err := st.backend().ClusterStatus(ctx, "")
+	if err != nil {
+		return nil, nil, err
+	}
+	coll, ok := status.Cluster.Collections[in.Collection]
+	if !ok || coll.ConfigName == "" {
+		return nil, nil, fmt.Errorf("configset of collection %s not found in cluster status", in.Collection)
+	}
+	if err := solr.UploadConfigFile(ctx, st.HttpClient, st.solrURL(), st.BasicUser, st.BasicPass, coll.ConfigName, file, solr.RenderElevateXML(rules)); err != nil {
+		return nil, nil, err
+	}
+	var reloaded []string
+	for name, c := range status.Cluster.Collections {
+		if c.ConfigName == coll.ConfigName {
+			reloaded = append(reloaded, name)
+		}
+	}
+	sort.Strings(reloaded)
+	for _, name := range reloaded {
+		if err := solr.ReloadCollection(ctx, st.HttpClient, st.solrURL(), st.BasicUser, st.BasicPass, name); err != nil {
+			return nil, nil, fmt.Errorf("%s was uploaded to configset %s but not applied: %v", file, coll.ConfigName, err)
+		}
+	}
+	slog.Info("Elevation rules updated", "collection", in.Collection, "query", in.Query, "removed", in.Remove, "configset", coll.ConfigName, "reloaded", reloaded)
+	out["configSet"] = coll.ConfigName
+	out["reloaded"] = reloaded
+	return nil, out, nil
+}
+
+// missingIDs returns the ids that do not exist in collection.
+func (st *State) missingIDs(ctx context.Context, collection string, ids []string) ([]string, error) {
+	key, err := st.requireUniqueKey(ctx, collection)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := solr.ExistingIDs(ctx, st.backend(), collection, key, ids)
+	if err != nil {
+		return nil, err
+	}
+	return slices.DeleteFunc(ids, func(id string) bool { return slices.Contains(existing, id) }), nil
+}
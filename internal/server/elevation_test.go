@@ -0,0 +1,77 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/solr"
+	"github.com/Sashimimochi/solr-mcp-go/internal/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestToolElevation tests listing and editing elevation rules.
+func TestToolElevation(t *testing.T) {
+	var uploaded string
+	var reloaded []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/solr/products/admin/file":
+			fmt.Fprint(w, `<elevate><query text="ipod"><doc id="1"/></query></elevate>`)
+		case "/solr/products/schema/uniquekey":
+			fmt.Fprint(w, `{"uniqueKey":"id"}`)
+		case "/solr/products/schema/fields":
+			fmt.Fprint(w, `{"fields":[{"name":"id","type":"string"}]}`)
+		case "/solr/products/select":
+			fmt.Fprint(w, `{"response":{"numFound":1,"docs":[{"id":"2"}]}}`)
+		case "/solr/admin/collections":
+			switch r.URL.Query().Get("action") {
+			case "CLUSTERSTATUS":
+				fmt.Fprint(w, `{"cluster":{"collections":{"products":{"configName":"shop"},"products_v2":{"configName":"shop"},"logs":{"configName":"logs"}}}}`)
+			case "RELOAD":
+				reloaded = append(reloaded, r.URL.Query().Get("name"))
+				fmt.Fprint(w, `{}`)
+			}
+		case "/solr/admin/configs":
+			assert.Equal(t, "shop", r.URL.Query().Get("name"))
+			assert.Equal(t, "elevate.xml", r.URL.Query().Get("filePath"))
+			body, _ := io.ReadAll(r.Body)
+			uploaded = string(body)
+			fmt.Fprint(w, `{}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	st := newTestState(t, server.URL)
+	ctx := context.Background()
+
+	// Goal: The rules of elevate.xml are listed.
+	_, out, err := st.toolElevationList(ctx, nil, types.ElevationListIn{Collection: "products"})
+	require.NoError(t, err)
+	assert.Equal(t, []solr.ElevationRule{{Query: "ipod", Elevate: []string{"1"}}}, out.(map[string]any)["rules"])
+
+	// Goal: A preview shows the new file and unknown documents without uploading anything.
+	_, out, err = st.toolElevationSet(ctx, nil, types.ElevationSetIn{Collection: "products", Query: "ipod", Elevate: []string{"2", "3"}, Preview: true})
+	require.NoError(t, err)
+	res := out.(map[string]any)
+	assert.Equal(t, []string{"3"}, res["missing"])
+	assert.Contains(t, res["xml"], `<doc id="3"></doc>`)
+	assert.Empty(t, uploaded)
+
+	// Goal: Changes are uploaded to the configset and every collection using it is reloaded.
+	_, out, err = st.toolElevationSet(ctx, nil, types.ElevationSetIn{Collection: "products", Query: "nano", Exclude: []string{"2"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"products", "products_v2"}, out.(map[string]any)["reloaded"])
+	assert.Contains(t, uploaded, `<query text="ipod">`)
+	assert.Contains(t, uploaded, `<doc id="2" exclude="true"></doc>`)
+
+	// Goal: Removing a rule that does not exist is an error.
+	_, _, err = st.toolElevationSet(ctx, nil, types.ElevationSetIn{Collection: "products", Query: "zune", Remove: true})
+	assert.ErrorContains(t, err, `no elevation rule for query "zune"`)
+}
@@ -0,0 +1,43 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/errcatalog"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const errorsResourceURI = "solr://errors"
+
+// errorResult returns the tool error result of err: the message, detail and hint in the configured language as
+// text, and the catalog code with them as structured content, so clients can branch on the code.
+func (st *State) errorResult(tool string, err error) *mcp.CallToolResult {
+	lang := st.language()
+	d := errcatalog.Describe(err, lang)
+	slog.Debug("Tool failed", "tool", tool, "code", d.Code, "error", err)
+	return &mcp.CallToolResult{
+		IsError:           true,
+		Content:           []mcp.Content{&mcp.TextContent{Text: d.Text(lang)}},
+		StructuredContent: map[string]any{"error": d},
+	}
+}
+
+// language returns the language of tool errors, English unless configured.
+func (st *State) language() errcatalog.Lang {
+	if st.Language == "" {
+		return errcatalog.English
+	}
+	return st.Language
+}
+
+// readErrorsResource returns the error catalog in the configured language.
+func (st *State) readErrorsResource(_ context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	lang := st.language()
+	var entries []errcatalog.Described
+	for _, e := range errcatalog.Entries() {
+		entries = append(entries, errcatalog.Lookup(e.Code, lang))
+	}
+	return jsonResource(req.Params.URI, map[string]any{"language": lang, "errors": entries})
+}
@@ -0,0 +1,59 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/errcatalog"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestErrorResult tests returning tool errors with catalog codes and localized hints.
+func TestErrorResult(t *testing.T) {
+	st := NewServer(WithSolrURL("http://127.0.0.1:1"), WithHTTPClient(&http.Client{}), WithLanguage(errcatalog.Japanese))
+	mcpServer := st.NewMCPServer()
+	session, _, _ := connectTestClient(t, mcpServer)
+	ctx := context.Background()
+	st.readOnly.Store(true)
+
+	// Goal: The code and detail are structured, the message and hint are in the configured language.
+	res, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "solr.update", Arguments: map[string]any{"collection": "products", "documents": []any{map[string]any{"id": "1"}}}})
+	require.NoError(t, err)
+	require.True(t, res.IsError)
+	var out struct{ Error errcatalog.Described }
+	data, err := json.Marshal(res.StructuredContent)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(data, &out))
+	assert.Equal(t, errcatalog.ReadOnly, out.Error.Code)
+	assert.Equal(t, "solr.update is disabled: the server is in read-only mode", out.Error.Detail)
+	assert.Equal(t, "サーバーは読み取り専用モードです", out.Error.Message)
+	assert.Equal(t, errcatalog.DocsURL+"#admin-api", out.Error.Docs)
+	text := res.Content[0].(*mcp.TextContent).Text
+	assert.Contains(t, text, "サーバーは読み取り専用モードです: solr.update is disabled")
+	assert.Contains(t, text, "code: read_only")
+
+	// Goal: Failures still count in the usage statistics.
+	var failures int64
+	for _, s := range st.usageTracker().Snapshot().Tools {
+		if s.Name == "solr.update" {
+			failures = s.Failures
+		}
+	}
+	assert.Equal(t, int64(1), failures)
+
+	// Goal: The catalog is readable as a resource.
+	rr, err := session.ReadResource(ctx, &mcp.ReadResourceParams{URI: errorsResourceURI})
+	require.NoError(t, err)
+	var catalog struct {
+		Language errcatalog.Lang
+		Errors   []errcatalog.Described
+	}
+	require.NoError(t, json.Unmarshal([]byte(rr.Contents[0].Text), &catalog))
+	assert.Equal(t, errcatalog.Japanese, catalog.Language)
+	assert.Len(t, catalog.Errors, len(errcatalog.Entries()))
+}
@@ -0,0 +1,67 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"time"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/experiment"
+	"github.com/Sashimimochi/solr-mcp-go/internal/types"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// experimentsFile is the name of the experiment metrics file in DataDir.
+const experimentsFile = "experiments.json"
+
+// experiments returns the A/B experiment manager, loading the metrics saved in DataDir on first use.
+func (st *State) experiments() *experiment.Manager {
+	st.experimentsOnce.Do(func() {
+		st.experimentsMgr = experiment.NewManager(st.fileConfig().Experiments)
+		if st.DataDir != "" {
+			path := filepath.Join(st.DataDir, experimentsFile)
+			if err := st.experimentsMgr.Load(path); err != nil {
+				slog.Warn("Failed to load experiment metrics, starting empty", "path", path, "error", err)
+			}
+		}
+	})
+	return st.experimentsMgr
+}
+
+// saveExperiments writes the experiment metrics to DataDir, as long as experiments are configured.
+func (st *State) saveExperiments() {
+	if len(st.fileConfig().Experiments) == 0 {
+		return
+	}
+	path := filepath.Join(st.DataDir, experimentsFile)
+	if err := st.experiments().Save(path); err != nil {
+		slog.Error("Failed to save experiment metrics", "path", path, "error", err)
+	}
+}
+
+// recordExperiment adds the outcome of a solr.query execution to its experiment and tags the result with the variant.
+func (st *State) recordExperiment(a *experiment.Assignment, resp map[string]any, d time.Duration) {
+	var numFound int64
+	if r, ok := resp["response"].(map[string]any); ok {
+		if n, ok := r["numFound"].(float64); ok {
+			numFound = int64(n)
+		}
+	}
+	st.experiments().RecordExecution(a, numFound, d)
+	resp["experiment"] = a
+}
+
+func (st *State) toolExperimentReport(ctx context.Context, _ *mcp.CallToolRequest, in types.ExperimentReportIn) (*mcp.CallToolResult, any, error) {
+	reports := st.experiments().Reports()
+	if in.Name != "" {
+		for _, r := range reports {
+			if r.Name == in.Name {
+				return nil, r, nil
+			}
+		}
+		return nil, nil, fmt.Errorf("unknown experiment %s; see the experiments section of the config file", in.Name)
+	}
+	return nil, map[string]any{"experiments": reports}, nil
+}
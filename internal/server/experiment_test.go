@@ -0,0 +1,60 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/config"
+	"github.com/Sashimimochi/solr-mcp-go/internal/experiment"
+	"github.com/Sashimimochi/solr-mcp-go/internal/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestQueryExperiment tests A/B experiments on solr.query.
+func TestQueryExperiment(t *testing.T) {
+	var received url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.URL.Query()
+		fmt.Fprint(w, `{"response":{"numFound":0,"docs":[]}}`)
+	}))
+	defer server.Close()
+	st := newTestState(t, server.URL)
+	st.Config = &config.FileConfig{Experiments: []config.ExperimentConfig{
+		{Name: "no-rerank", Collections: []string{"products"}, Fraction: 1, Params: map[string]string{"rq": "", "bq": "inStock:true"}},
+	}}
+	ctx := context.Background()
+
+	// Goal: Variant B changes the request and the result is tagged with the variant.
+	_, out, err := st.toolQuery(ctx, nil, types.QueryIn{Collection: "products", Query: "tv", Params: map[string]any{"rq": "{!ltr model=m}"}})
+	require.NoError(t, err)
+	assert.Equal(t, "inStock:true", received.Get("bq"))
+	assert.NotContains(t, received, "rq")
+	tag := out.(map[string]any)["experiment"].(*experiment.Assignment)
+	assert.Equal(t, "no-rerank", tag.Experiment)
+	assert.Equal(t, experiment.VariantB, tag.Variant)
+
+	// Goal: Plans and other collections are not part of the experiment.
+	_, out, err = st.toolQuery(ctx, nil, types.QueryIn{Collection: "products", PlanOnly: true})
+	require.NoError(t, err)
+	assert.NotContains(t, out, "experiment")
+	_, out, err = st.toolQuery(ctx, nil, types.QueryIn{Collection: "logs"})
+	require.NoError(t, err)
+	assert.NotContains(t, out, "experiment")
+
+	// Goal: The report counts the execution of variant B.
+	_, out, err = st.toolExperimentReport(ctx, nil, types.ExperimentReportIn{Name: "no-rerank"})
+	require.NoError(t, err)
+	report := out.(experiment.Report)
+	assert.Equal(t, int64(0), report.Variants[0].Executions)
+	assert.Equal(t, int64(1), report.Variants[1].Executions)
+	assert.Equal(t, 1.0, report.Variants[1].ZeroResultRate)
+
+	_, _, err = st.toolExperimentReport(ctx, nil, types.ExperimentReportIn{Name: "other"})
+	assert.ErrorContains(t, err, "unknown experiment other")
+}
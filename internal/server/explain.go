@@ -0,0 +1,50 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/solr"
+	"github.com/Sashimimochi/solr-mcp-go/internal/types"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// toolExplainQuery describes raw /select parameters or a saved query without running it.
+// The description is rule-based; the collection schema, when available, is used to check the fields.
+func (st *State) toolExplainQuery(ctx context.Context, _ *mcp.CallToolRequest, in types.ExplainQueryIn) (*mcp.CallToolResult, any, error) {
+	collection := in.Collection
+	var params url.Values
+	switch {
+	case in.SavedQuery != "":
+		q, ok := st.fileConfig().SavedQuery(in.SavedQuery)
+		if !ok {
+			return nil, nil, fmt.Errorf("saved query %s not found", in.SavedQuery)
+		}
+		params = solr.QueryValues(solr.BuildSavedQuery(q))
+		params.Del("wt")
+		collection = q.Collection
+	case len(in.Params) > 0:
+		params = solr.ParamValues(in.Params)
+	default:
+		return nil, nil, errors.New("params or savedQuery is required")
+	}
+
+	var fc *types.FieldCatalog
+	var schemaErr error
+	if collection != "" {
+		fc, schemaErr = st.backend().Schema(ctx, collection)
+	}
+	explanation := solr.ExplainQuery(params, fc)
+	if schemaErr != nil {
+		explanation.Warnings = append(explanation.Warnings, fmt.Sprintf("Fields were not checked, the schema of %s is unavailable: %v", collection, schemaErr))
+	}
+
+	return nil, map[string]any{
+		"collection":  collection,
+		"params":      params,
+		"explanation": explanation,
+	}, nil
+}
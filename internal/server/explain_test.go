@@ -0,0 +1,48 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/config"
+	"github.com/Sashimimochi/solr-mcp-go/internal/solr"
+	"github.com/Sashimimochi/solr-mcp-go/internal/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestToolExplainQuery tests explaining raw parameters and saved queries.
+func TestToolExplainQuery(t *testing.T) {
+	st := NewServer(
+		WithBackend(&fakeBackend{docs: map[string][]map[string]any{"logs": nil}}),
+		WithConfig(&config.FileConfig{SavedQueries: []config.SavedQuery{{Name: "errors", Collection: "logs", Query: "level:ERROR"}}}, ""),
+	)
+	ctx := context.Background()
+
+	// Goal: Raw parameters are explained and fields are checked against the schema.
+	_, out, err := st.toolExplainQuery(ctx, nil, types.ExplainQueryIn{Collection: "logs", Params: map[string]any{"q": "id:42", "rows": float64(0)}})
+	require.NoError(t, err)
+	exp := out.(map[string]any)["explanation"].(solr.QueryExplanation)
+	assert.Equal(t, `Finds documents where id is "42".`, exp.Summary)
+	assert.Contains(t, exp.Details, "Returns only the number of matching documents (rows=0).")
+	assert.Empty(t, exp.Warnings)
+
+	// Goal: Saved queries are explained against their collection.
+	_, out, err = st.toolExplainQuery(ctx, nil, types.ExplainQueryIn{SavedQuery: "errors"})
+	require.NoError(t, err)
+	assert.Equal(t, "logs", out.(map[string]any)["collection"])
+	exp = out.(map[string]any)["explanation"].(solr.QueryExplanation)
+	assert.Contains(t, exp.Warnings, "Field level is not in the schema of the collection; clauses on it match nothing or fail.")
+
+	// Goal: An unavailable schema is reported but does not fail the explanation.
+	_, out, err = st.toolExplainQuery(ctx, nil, types.ExplainQueryIn{Collection: "missing", Params: map[string]any{"q": "a:b"}})
+	require.NoError(t, err)
+	assert.NotEmpty(t, out.(map[string]any)["explanation"].(solr.QueryExplanation).Warnings)
+
+	// Goal: Unknown saved queries and empty input are rejected.
+	_, _, err = st.toolExplainQuery(ctx, nil, types.ExplainQueryIn{SavedQuery: "nope"})
+	assert.ErrorContains(t, err, "not found")
+	_, _, err = st.toolExplainQuery(ctx, nil, types.ExplainQueryIn{})
+	assert.Error(t, err)
+}
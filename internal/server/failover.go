@@ -0,0 +1,101 @@
+package server
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/config"
+	"github.com/Sashimimochi/solr-mcp-go/internal/errcatalog"
+	"github.com/Sashimimochi/solr-mcp-go/internal/failover"
+	"github.com/Sashimimochi/solr-mcp-go/internal/solr"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// writeTools modify Solr and only run against the standby when StandbyWrites is set.
+var writeTools = map[string]bool{
+	"solr.delete":          true,
+	"solr.update":          true,
+	"solr.collection.drop": true,
+	"solr.retention.run":   true,
+	"solr.archive":         true,
+	"solr.archive.restore": true,
+	"solr.elevation.set":   true,
+	"solr.ltr.upload":      true,
+	"solr.params.set":      true,
+	"solr.reindex":         true,
+	"solr.import":          true,
+}
+
+// failoverMonitor returns the standby failover monitor, or nil when no standby is configured
+// or a custom backend replaces the Solr HTTP APIs.
+func (st *State) failoverMonitor() *failover.Monitor {
+	if st.StandbyURL == "" || st.Backend != nil {
+		return nil
+	}
+	st.failoverOnce.Do(func() {
+		st.failover = failover.New(st.BaseURL, st.StandbyURL, st.FailoverThreshold, func(ctx context.Context, baseURL string) error {
+			b := &solr.HTTPBackend{HttpClient: st.HttpClient, BaseURL: baseURL, User: st.BasicUser, Pass: st.BasicPass}
+			_, err := b.ClusterStatus(ctx, "")
+			return err
+		})
+	})
+	return st.failover
+}
+
+// failoverTransport reports the outcome of every Solr request to the failover monitor. Only the requests say
+// whether the primary is available: tool calls also fail for invalid arguments or never reach Solr.
+type failoverTransport struct {
+	st   *State
+	base http.RoundTripper
+}
+
+func (t *failoverTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	resp, err := base.RoundTrip(req)
+	if m := t.st.failoverMonitor(); m != nil {
+		m.ReportResponse(req, resp, err)
+	}
+	return resp, err
+}
+
+// solrURL returns the base URL of the active Solr cluster: the standby after a failover, BaseURL otherwise.
+func (st *State) solrURL() string {
+	if m := st.failoverMonitor(); m != nil {
+		return m.BaseURL()
+	}
+	return st.BaseURL
+}
+
+// standbyWriteError refuses write tools while the server runs against the standby, unless StandbyWrites is set.
+func (st *State) standbyWriteError(tool string) error {
+	m := st.failoverMonitor()
+	if m == nil || !writeTools[tool] || st.StandbyWrites || !m.OnStandby() {
+		return nil
+	}
+	return errcatalog.New(errcatalog.StandbyWrite, "%s is disabled while the primary Solr is unavailable and requests go to the standby %s; set SOLR_MCP_STANDBY_ALLOW_WRITES=true to allow writes on the standby", tool, config.RedactURL(st.StandbyURL))
+}
+
+// markFailover marks results served by the standby, in the result metadata and, for object outputs, in a
+// "failover" field. The failover monitor learns about the primary from the Solr requests of HttpClient.
+func markFailover[Out any](st *State, res *mcp.CallToolResult, out Out, err error) *mcp.CallToolResult {
+	m := st.failoverMonitor()
+	if m == nil || err != nil || !m.OnStandby() {
+		return res
+	}
+	status := m.Status()
+	if res == nil {
+		res = &mcp.CallToolResult{}
+	}
+	if res.Meta == nil {
+		res.Meta = mcp.Meta{}
+	}
+	res.Meta["failover"] = status
+	if obj, ok := any(out).(map[string]any); ok {
+		obj["failover"] = status
+	}
+	return res
+}
@@ -0,0 +1,79 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestStandbyFailover tests failing over read tools to the standby, refusing writes there and failing back.
+func TestStandbyFailover(t *testing.T) {
+	var primaryDown atomic.Bool
+	cluster := func(node string, down *atomic.Bool) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if down != nil && down.Load() {
+				http.Error(w, "Service Unavailable", http.StatusServiceUnavailable)
+				return
+			}
+			fmt.Fprintf(w, `{"responseHeader":{"status":0},"cluster":{"live_nodes":[%q],"collections":{}}}`, node)
+		}))
+	}
+	primary := cluster("primary:8983_solr", &primaryDown)
+	defer primary.Close()
+	standby := cluster("standby:8983_solr", nil)
+	defer standby.Close()
+
+	st := NewServer(WithSolrURL(primary.URL), WithHTTPClient(&http.Client{}), WithStandby(standby.URL, false))
+	mcpServer := mcp.NewServer(&mcp.Implementation{}, nil)
+	AddTools(mcpServer, st)
+	session, _, _ := connectTestClient(t, mcpServer)
+	ctx := context.Background()
+	ping := func() *mcp.CallToolResult {
+		res, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "solr.ping", Arguments: map[string]any{}})
+		require.NoError(t, err)
+		return res
+	}
+
+	// Goal: Consecutive unavailability of the primary fails over to the standby, also when calls that do not
+	// reach Solr, or fail before reaching it, come in between.
+	primaryDown.Store(true)
+	for pings := 0; !st.failoverMonitor().OnStandby(); pings++ {
+		require.Less(t, pings, st.FailoverThreshold)
+		assert.True(t, ping().IsError)
+		res, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "solr.info", Arguments: map[string]any{}})
+		require.NoError(t, err)
+		assert.False(t, res.IsError)
+		res, err = session.CallTool(ctx, &mcp.CallToolParams{Name: "solr.query", Arguments: map[string]any{"collection": ""}})
+		require.NoError(t, err)
+		assert.True(t, res.IsError)
+	}
+	res := ping()
+	require.False(t, res.IsError)
+	out := res.StructuredContent.(map[string]any)
+	assert.Equal(t, []any{"standby:8983_solr"}, out["live_nodes"])
+
+	// Goal: Results served by the standby are marked.
+	assert.Equal(t, "standby", out["failover"].(map[string]any)["active"])
+	assert.NotNil(t, res.Meta["failover"])
+
+	// Goal: Write tools refuse to run against the standby.
+	res, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "solr.delete", Arguments: map[string]any{"collection": "logs", "query": "*:*"}})
+	require.NoError(t, err)
+	assert.True(t, res.IsError)
+	assert.Contains(t, res.Content[0].(*mcp.TextContent).Text, "SOLR_MCP_STANDBY_ALLOW_WRITES")
+
+	// Goal: A successful probe of the primary fails back.
+	primaryDown.Store(false)
+	st.failoverMonitor().Probe(ctx)
+	res = ping()
+	out = res.StructuredContent.(map[string]any)
+	assert.Equal(t, []any{"primary:8983_solr"}, out["live_nodes"])
+	assert.Nil(t, out["failover"])
+}
@@ -0,0 +1,130 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/feedback"
+	"github.com/Sashimimochi/solr-mcp-go/internal/types"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// feedbackDir is the directory of the click log and statistics in DataDir.
+const feedbackDir = "feedback"
+
+// defaultFeedbackLimit is the number of queries reported by solr.feedback.report by default.
+const defaultFeedbackLimit = 20
+
+// feedbackTracker returns the click feedback tracker, loading the statistics saved in DataDir on first use.
+func (st *State) feedbackTracker() *feedback.Tracker {
+	st.feedbackOnce.Do(func() {
+		if st.DataDir == "" {
+			st.feedback = feedback.NewTracker("")
+			return
+		}
+		st.feedback = feedback.NewTracker(filepath.Join(st.DataDir, feedbackDir, "clicks.jsonl"))
+		path := filepath.Join(st.DataDir, feedbackDir, "stats.json")
+		if err := st.feedback.Load(path); err != nil {
+			slog.Warn("Failed to load feedback stats, starting empty", "path", path, "error", err)
+		}
+	})
+	return st.feedback
+}
+
+// saveFeedback writes the click statistics to DataDir.
+func (st *State) saveFeedback() {
+	path := filepath.Join(st.DataDir, feedbackDir, "stats.json")
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		slog.Error("Failed to create feedback directory", "path", path, "error", err)
+		return
+	}
+	if err := st.feedbackTracker().Save(path); err != nil {
+		slog.Error("Failed to save feedback stats", "path", path, "error", err)
+	}
+}
+
+func (st *State) toolFeedbackClick(ctx context.Context, _ *mcp.CallToolRequest, in types.FeedbackClickIn) (*mcp.CallToolResult, any, error) {
+	if strings.TrimSpace(in.Collection) == "" {
+		return nil, nil, errors.New("input.collection is required")
+	}
+	if strings.TrimSpace(in.DocID) == "" {
+		return nil, nil, errors.New("input.docId is required")
+	}
+	if in.Position < 0 {
+		return nil, nil, errors.New("input.position must not be negative")
+	}
+	if (in.Experiment == "") != (in.Variant == "") {
+		return nil, nil, errors.New("input.experiment and input.variant must be set together, as returned in the experiment field of solr.query")
+	}
+	click := feedback.Click{
+		Time:       time.Now().UTC(),
+		Collection: in.Collection,
+		Query:      in.Query,
+		DocID:      in.DocID,
+		Position:   in.Position,
+		Experiment: in.Experiment,
+		Variant:    in.Variant,
+	}
+	if err := st.feedbackTracker().RecordClick(click); err != nil {
+		return nil, nil, err
+	}
+	out := map[string]any{"recorded": true, "query": feedback.NormalizeQuery(in.Query)}
+	if in.Experiment != "" {
+		out["experimentRecorded"] = st.experiments().RecordClick(in.Experiment, in.Variant)
+	}
+	if signals := st.fileConfig().Feedback.SignalsCollection; signals != "" {
+		if err := st.indexSignal(ctx, signals, click); err != nil {
+			slog.Warn("Failed to index click signal", "collection", signals, "error", err)
+			out["signalError"] = err.Error()
+		} else {
+			out["signalCollection"] = signals
+		}
+	}
+	return nil, out, nil
+}
+
+// indexSignal adds a click document to the signals collection, using dynamic field suffixes of the default configset.
+func (st *State) indexSignal(ctx context.Context, collection string, c feedback.Click) error {
+	if st.readOnly.Load() {
+		return errors.New("not indexed: the server is in read-only mode")
+	}
+	doc := map[string]any{
+		"id":           fmt.Sprintf("click-%d-%s", c.Time.UnixNano(), c.DocID),
+		"type_s":       "click",
+		"collection_s": c.Collection,
+		"query_s":      feedback.NormalizeQuery(c.Query),
+		"doc_id_s":     c.DocID,
+		"timestamp_dt": c.Time.Format(time.RFC3339),
+	}
+	if c.Position > 0 {
+		doc["position_i"] = c.Position
+	}
+	if c.Experiment != "" {
+		doc["experiment_s"] = c.Experiment
+		doc["variant_s"] = c.Variant
+	}
+	return st.backend().Add(ctx, collection, []map[string]any{doc})
+}
+
+func (st *State) toolFeedbackReport(ctx context.Context, _ *mcp.CallToolRequest, in types.FeedbackReportIn) (*mcp.CallToolResult, any, error) {
+	switch in.Sort {
+	case "", "executions", "clicks", "ctr":
+	default:
+		return nil, nil, errors.New("input.sort must be executions, clicks or ctr")
+	}
+	limit := in.Limit
+	if limit <= 0 {
+		limit = defaultFeedbackLimit
+	}
+	return nil, map[string]any{
+		"since":   st.feedbackTracker().Since(),
+		"queries": st.feedbackTracker().Report(in.Collection, in.Query, in.Sort, limit),
+	}, nil
+}
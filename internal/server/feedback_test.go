@@ -0,0 +1,57 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/config"
+	"github.com/Sashimimochi/solr-mcp-go/internal/feedback"
+	"github.com/Sashimimochi/solr-mcp-go/internal/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestToolFeedback tests recording clicks and reporting click-through rates.
+func TestToolFeedback(t *testing.T) {
+	b := &fakeBackend{docs: map[string][]map[string]any{
+		"products": {{"id": "1", "name": "tv"}, {"id": "2", "name": "radio"}},
+	}}
+	st := NewServer(WithBackend(b), WithConfig(&config.FileConfig{
+		Experiments: []config.ExperimentConfig{{Name: "boost", Fraction: 0.5, Params: map[string]string{"bq": "inStock:true"}}},
+		Feedback:    config.FeedbackConfig{SignalsCollection: "signals"},
+	}, ""), WithDataDir(t.TempDir()))
+	ctx := context.Background()
+
+	// Goal: Executed queries count towards the click-through rate of their query text.
+	_, _, err := st.toolQuery(ctx, nil, types.QueryIn{Collection: "products", Query: "*:*"})
+	require.NoError(t, err)
+	_, _, err = st.toolQuery(ctx, nil, types.QueryIn{Collection: "products", Query: "*:*"})
+	require.NoError(t, err)
+
+	// Goal: A click is recorded, counted for its experiment variant and indexed as a signal.
+	_, out, err := st.toolFeedbackClick(ctx, nil, types.FeedbackClickIn{Collection: "products", Query: "*:*", DocID: "2", Position: 2, Experiment: "boost", Variant: "B"})
+	require.NoError(t, err)
+	res := out.(map[string]any)
+	assert.Equal(t, true, res["experimentRecorded"])
+	assert.Equal(t, "signals", res["signalCollection"])
+	require.Len(t, b.docs["signals"], 1)
+	assert.Equal(t, "2", b.docs["signals"][0]["doc_id_s"])
+	assert.Equal(t, "B", b.docs["signals"][0]["variant_s"])
+	assert.Equal(t, int64(1), st.experiments().Reports()[0].Variants[1].Clicks)
+
+	_, out, err = st.toolFeedbackReport(ctx, nil, types.FeedbackReportIn{Collection: "products"})
+	require.NoError(t, err)
+	queries := out.(map[string]any)["queries"].([]feedback.QueryReport)
+	require.Len(t, queries, 1)
+	assert.Equal(t, int64(2), queries[0].Executions)
+	assert.Equal(t, 0.5, queries[0].CTR)
+
+	// Goal: Invalid input is rejected.
+	_, _, err = st.toolFeedbackClick(ctx, nil, types.FeedbackClickIn{Collection: "products"})
+	assert.ErrorContains(t, err, "input.docId is required")
+	_, _, err = st.toolFeedbackClick(ctx, nil, types.FeedbackClickIn{Collection: "products", DocID: "1", Experiment: "boost"})
+	assert.ErrorContains(t, err, "must be set together")
+	_, _, err = st.toolFeedbackReport(ctx, nil, types.FeedbackReportIn{Sort: "recent"})
+	assert.ErrorContains(t, err, "input.sort")
+}
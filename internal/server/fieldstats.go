@@ -0,0 +1,72 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/solr"
+	"github.com/Sashimimochi/solr-mcp-go/internal/types"
+)
+
+// fieldStats returns the cached statistics of a field, computing them on first use or when they are older than
+// the field statistics TTL.
+func (st *State) fieldStats(ctx context.Context, collection, field string) (types.FieldStats, error) {
+	if fs, ok := st.SchemaCache.GetFieldStats(collection, field); ok {
+		return fs, nil
+	}
+	fc, err := st.backend().Schema(ctx, collection)
+	if err != nil {
+		return types.FieldStats{}, err
+	}
+	i := slices.IndexFunc(fc.All, func(f types.SolrField) bool { return f.Name == field })
+	if i < 0 {
+		return types.FieldStats{}, fmt.Errorf("field %s not found in collection %s", field, collection)
+	}
+	fs, err := solr.FetchFieldStats(ctx, st.backend(), collection, fc.All[i], time.Now())
+	if err != nil {
+		return types.FieldStats{}, err
+	}
+	st.SchemaCache.SetFieldStats(collection, fs)
+	return fs, nil
+}
+
+// planFieldStats returns the statistics of the sort and facet fields of params, so a plan shows how many
+// distinct values a facet returns and which range a sort covers. Fields without statistics are left out.
+func (st *State) planFieldStats(ctx context.Context, collection string, params url.Values) map[string]types.FieldStats {
+	var fields []string
+	for _, clause := range strings.Split(params.Get("sort"), ",") {
+		field, _, _ := strings.Cut(strings.TrimSpace(clause), " ")
+		if field != "" && field != "score" && !strings.Contains(field, "(") {
+			fields = append(fields, field)
+		}
+	}
+	for _, f := range append(params["facet.field"], params["facet.range"]...) {
+		// drop local params such as {!ex=tag}
+		if i := strings.LastIndex(f, "}"); i >= 0 {
+			f = f[i+1:]
+		}
+		fields = append(fields, strings.TrimSpace(f))
+	}
+
+	var out map[string]types.FieldStats
+	for _, field := range fields {
+		if _, ok := out[field]; ok || field == "" {
+			continue
+		}
+		fs, err := st.fieldStats(ctx, collection, field)
+		if err != nil {
+			slog.Debug("Field statistics unavailable", "collection", collection, "field", field, "error", err)
+			continue
+		}
+		if out == nil {
+			out = make(map[string]types.FieldStats)
+		}
+		out[field] = fs
+	}
+	return out
+}
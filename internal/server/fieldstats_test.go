@@ -0,0 +1,90 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestFieldStats tests computing field statistics lazily, caching them and invalidating them on schema refresh.
+func TestFieldStats(t *testing.T) {
+	var statsRequests atomic.Int32
+	var priceType atomic.Value
+	priceType.Store("pfloat")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/solr/products/schema/uniquekey":
+			fmt.Fprintln(w, `{"uniqueKey":"id"}`)
+		case "/solr/products/schema/fields":
+			fmt.Fprintf(w, `{"fields":[{"name":"id","type":"string"},{"name":"title","type":"text_general"},{"name":"price","type":"%s"},{"name":"brand","type":"string"}]}`, priceType.Load())
+		case "/solr/products/select":
+			if r.URL.Query().Get("stats") != "true" {
+				fmt.Fprintln(w, `{"response":{"numFound":0,"docs":[]}}`)
+				return
+			}
+			statsRequests.Add(1)
+			fmt.Fprintln(w, `{"stats":{"stats_fields":{
+				"price":{"count":90,"cardinality":40,"min":1.5,"max":99.0},
+				"brand":{"count":100,"cardinality":12}}}}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	ctx := context.Background()
+	st := newTestState(t, server.URL)
+
+	// Goal: The plan of a query lists the statistics of its sort and facet fields, but not of text fields.
+	_, out, err := st.toolQuery(ctx, nil, types.QueryIn{Collection: "products", Sort: "price asc, score desc", PlanOnly: true,
+		Params: map[string]any{"facet.field": []string{"{!ex=b}brand", "title"}}})
+	require.NoError(t, err)
+	stats := out.(map[string]any)["fieldStats"].(map[string]types.FieldStats)
+	require.Len(t, stats, 2)
+	assert.Equal(t, int64(40), stats["price"].Cardinality)
+	assert.Equal(t, 99.0, stats["price"].Max)
+	assert.Equal(t, int64(12), stats["brand"].Cardinality)
+	assert.Nil(t, stats["brand"].Min)
+	assert.Equal(t, int32(2), statsRequests.Load())
+
+	// Goal: Cached statistics are reused.
+	_, err = st.fieldStats(ctx, "products", "price")
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), statsRequests.Load())
+
+	// Goal: A schema refresh that changes the type of a field drops its statistics only.
+	priceType.Store("plong")
+	st.SchemaCache.TTL = 0
+	_, err = st.backend().Schema(ctx, "products")
+	require.NoError(t, err)
+	fs, err := st.fieldStats(ctx, "products", "price")
+	require.NoError(t, err)
+	assert.Equal(t, "plong", fs.Type)
+	assert.Equal(t, int32(3), statsRequests.Load())
+	_, ok := st.SchemaCache.GetFieldStats("products", "brand")
+	assert.True(t, ok)
+
+	// Goal: Expired statistics are computed again, and unknown fields are rejected.
+	st.SchemaCache.StatsTTL = time.Nanosecond
+	_, err = st.fieldStats(ctx, "products", "brand")
+	require.NoError(t, err)
+	assert.Equal(t, int32(4), statsRequests.Load())
+	_, err = st.fieldStats(ctx, "products", "missing")
+	assert.ErrorContains(t, err, "field missing not found")
+
+	// Goal: Flushing the schema cache drops the statistics.
+	st.SchemaCache.StatsTTL = time.Hour
+	_, err = st.fieldStats(ctx, "products", "brand")
+	require.NoError(t, err)
+	st.SchemaCache.Flush()
+	_, ok = st.SchemaCache.GetFieldStats("products", "brand")
+	assert.False(t, ok)
+}
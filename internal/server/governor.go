@@ -0,0 +1,28 @@
+package server
+
+import (
+	"context"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/governor"
+)
+
+// memoryGovernor returns the memory budget of tool calls, creating it on first use.
+func (st *State) memoryGovernor() *governor.Governor {
+	st.governorOnce.Do(func() {
+		st.governor = governor.New(st.fileConfig().MemoryBudget)
+	})
+	return st.governor
+}
+
+// admit waits for room in the memory budget before a tool call that contacts Solr. The returned context counts the
+// Solr responses of the call against the budget until release is called.
+func (st *State) admit(ctx context.Context, tool string) (context.Context, func(), error) {
+	if localTools[tool] {
+		return ctx, func() {}, nil
+	}
+	ticket, err := st.memoryGovernor().Acquire(ctx)
+	if err != nil {
+		return ctx, nil, err
+	}
+	return governor.WithTicket(ctx, ticket), ticket.Release, nil
+}
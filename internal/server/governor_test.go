@@ -0,0 +1,73 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/config"
+	"github.com/Sashimimochi/solr-mcp-go/internal/errcatalog"
+	"github.com/Sashimimochi/solr-mcp-go/internal/governor"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMemoryBudget tests rejecting tool calls while running calls hold the memory budget.
+func TestMemoryBudget(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/solr/admin/collections":
+			fmt.Fprint(w, `{"collections":["products"]}`)
+		case "/solr/products/select":
+			fmt.Fprintf(w, `{"response":{"numFound":1,"docs":[{"id":"1","body":%q}]}}`, strings.Repeat("x", 2000))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	st := NewServer(WithSolrURL(server.URL), WithHTTPClient(&http.Client{}),
+		WithConfig(&config.FileConfig{MemoryBudget: config.MemoryBudgetConfig{MaxBytes: 1000, MaxWait: "0s"}}, ""))
+	session, _, _ := connectTestClient(t, st.NewMCPServer())
+	ctx := context.Background()
+	query := &mcp.CallToolParams{Name: "solr.query", Arguments: map[string]any{"collection": "products", "query": "*:*"}}
+
+	// A call in flight that read a large response
+	ticket, err := st.memoryGovernor().Acquire(ctx)
+	require.NoError(t, err)
+	req, err := http.NewRequestWithContext(governor.WithTicket(ctx, ticket), http.MethodGet, server.URL+"/solr/products/select", nil)
+	require.NoError(t, err)
+	resp, err := st.HttpClient.Do(req)
+	require.NoError(t, err)
+	_, err = io.Copy(io.Discard, resp.Body)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	// Goal: Calls contacting Solr are rejected as busy with a retry hint, local tools still answer.
+	res, err := session.CallTool(ctx, query)
+	require.NoError(t, err)
+	require.True(t, res.IsError)
+	var out struct{ Error errcatalog.Described }
+	data, err := json.Marshal(res.StructuredContent)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(data, &out))
+	assert.Equal(t, errcatalog.ServerBusy, out.Error.Code)
+	assert.Contains(t, out.Error.Detail, "server busy: running tool calls hold")
+	assert.Contains(t, out.Error.Detail, "retry in 1s")
+	res, err = session.CallTool(ctx, &mcp.CallToolParams{Name: "solr.info", Arguments: map[string]any{}})
+	require.NoError(t, err)
+	assert.False(t, res.IsError)
+
+	// Goal: Calls run again once the bytes are released, and give their own back when they finish.
+	ticket.Release()
+	res, err = session.CallTool(ctx, query)
+	require.NoError(t, err)
+	require.False(t, res.IsError)
+	assert.Equal(t, governor.Stats{Rejected: 1, MaxBytes: 1000}, st.memoryGovernor().Stats())
+}
@@ -0,0 +1,80 @@
+package server
+
+import (
+	"fmt"
+	"maps"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/experiment"
+	"github.com/Sashimimochi/solr-mcp-go/internal/solr"
+	"github.com/Sashimimochi/solr-mcp-go/internal/types"
+)
+
+// clampRows lowers the rows of params to maxRows and describes the change, or returns nil if rows is within the limit.
+func clampRows(params url.Values, maxRows int) *types.Guardrail {
+	if maxRows <= 0 || !params.Has("rows") {
+		return nil
+	}
+	rows, err := strconv.Atoi(params.Get("rows"))
+	if err == nil && rows <= maxRows {
+		return nil
+	}
+	requested := params.Get("rows")
+	params.Set("rows", strconv.Itoa(maxRows))
+	return &types.Guardrail{
+		Action:    "clamp_rows",
+		Target:    "rows",
+		Requested: requested,
+		Applied:   maxRows,
+		Reason:    fmt.Sprintf("queryLimits.maxRows of the server config allows at most %d rows per request", maxRows),
+	}
+}
+
+// limitGuardrails describes the resource limits added to a request and those left out for the Solr version.
+func limitGuardrails(limits *solr.QueryLimits, version solr.Version) []types.Guardrail {
+	if limits == nil {
+		return nil
+	}
+	var out []types.Guardrail
+	names := make([]string, 0, len(limits.Applied))
+	for name := range limits.Applied {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		out = append(out, types.Guardrail{
+			Action:  "apply_limit",
+			Target:  name,
+			Applied: limits.Applied[name],
+			Reason:  fmt.Sprintf("queryLimits.%s of the server config is added to requests that do not set it", name),
+		})
+	}
+	for _, name := range limits.Omitted {
+		out = append(out, types.Guardrail{
+			Action: "omit_limit",
+			Target: name,
+			Reason: fmt.Sprintf("queryLimits.%s needs Solr 9.6, the cluster runs %s", name, version),
+		})
+	}
+	return out
+}
+
+// experimentGuardrail describes the parameters replaced by the B variant of an A/B experiment, or returns nil.
+func experimentGuardrail(a *experiment.Assignment) *types.Guardrail {
+	if a == nil {
+		return nil
+	}
+	params := a.Params()
+	if len(params) == 0 {
+		return nil
+	}
+	return &types.Guardrail{
+		Action:  "experiment_params",
+		Target:  strings.Join(slices.Sorted(maps.Keys(params)), ","),
+		Applied: params,
+		Reason:  fmt.Sprintf("the query runs as variant %s of the A/B experiment %s", a.Variant, a.Experiment),
+	}
+}
@@ -0,0 +1,51 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/config"
+	"github.com/Sashimimochi/solr-mcp-go/internal/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestQueryGuardrails tests that solr.query explains the changes the server made to the request.
+func TestQueryGuardrails(t *testing.T) {
+	ctx := context.Background()
+	rows := 500
+
+	// Goal: Rows over queryLimits.maxRows are clamped, and experiment parameters are reported.
+	b := &fakeBackend{docs: map[string][]map[string]any{"products": {{"id": "1"}}}}
+	st := NewServer(WithBackend(b), WithConfig(&config.FileConfig{
+		QueryLimits: config.QueryLimitsConfig{MaxRows: 100},
+		Experiments: []config.ExperimentConfig{{Name: "boost", Fraction: 1, Params: map[string]string{"bq": "inStock:true"}}},
+	}, ""))
+	_, out, err := st.toolQuery(ctx, nil, types.QueryIn{Collection: "products", Rows: &rows})
+	require.NoError(t, err)
+	guardrails := out.(map[string]any)["guardrails"].([]types.Guardrail)
+	require.Len(t, guardrails, 2)
+	assert.Equal(t, types.Guardrail{
+		Action:    "clamp_rows",
+		Target:    "rows",
+		Requested: "500",
+		Applied:   100,
+		Reason:    "queryLimits.maxRows of the server config allows at most 100 rows per request",
+	}, guardrails[0])
+	assert.Equal(t, "experiment_params", guardrails[1].Action)
+	assert.Equal(t, "bq", guardrails[1].Target)
+
+	// Goal: Plans list the added resource limits, and requests within the limits have no guardrails.
+	st = newTestState(t, "http://localhost:8983")
+	st.Config = &config.FileConfig{QueryLimits: config.QueryLimitsConfig{TimeAllowed: 5000, MaxRows: 100}}
+	rows = 10
+	_, out, err = st.toolQuery(ctx, nil, types.QueryIn{Collection: "products", Rows: &rows, PlanOnly: true})
+	require.NoError(t, err)
+	assert.Equal(t, []types.Guardrail{{
+		Action:  "apply_limit",
+		Target:  "timeAllowed",
+		Applied: "5000",
+		Reason:  "queryLimits.timeAllowed of the server config is added to requests that do not set it",
+	}}, out.(map[string]any)["guardrails"])
+}
@@ -0,0 +1,298 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/config"
+	"github.com/Sashimimochi/solr-mcp-go/internal/routing"
+	"github.com/Sashimimochi/solr-mcp-go/internal/solr"
+	"github.com/Sashimimochi/solr-mcp-go/internal/types"
+	"github.com/Sashimimochi/solr-mcp-go/internal/utils"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Grades of solr.healthcheck.full, the same as the health of CLUSTERSTATUS.
+const (
+	gradeGreen  = "GREEN"
+	gradeYellow = "YELLOW"
+	gradeRed    = "RED"
+)
+
+// Thresholds and defaults of solr.healthcheck.full.
+const (
+	heapWarning        = 0.85 // heap usage
+	heapCritical       = 0.95
+	diskWarning        = 0.15 // free share of the data file system
+	diskCritical       = 0.05
+	defaultErrorWindow = 15 * time.Minute
+	maxSpotChecks      = 5 // collections whose replicas are compared when none are named
+	maxErrorSamples    = 5 // distinct error messages shown per node
+)
+
+// healthFinding is a problem found by solr.healthcheck.full.
+type healthFinding struct {
+	Severity string `json:"severity"`
+	Check    string `json:"check"`
+	Message  string `json:"message"`
+}
+
+// nodeHealth is the state of a live node.
+type nodeHealth struct {
+	Node         string          `json:"node"`
+	HeapUsage    *float64        `json:"heapUsage,omitempty"`
+	Disk         *solr.DiskUsage `json:"disk,omitempty"`
+	Errors       *int            `json:"errors,omitempty"`
+	RecentErrors []errorSample   `json:"recentErrors,omitempty"`
+}
+
+// errorSample is a distinct error message of a node with the number of times it was logged.
+type errorSample struct {
+	Message string    `json:"message"`
+	Count   int       `json:"count"`
+	Last    time.Time `json:"last"`
+}
+
+// healthReport collects the findings of the checks, which run concurrently.
+type healthReport struct {
+	mu       sync.Mutex
+	findings []healthFinding
+}
+
+func (r *healthReport) add(severity, check, format string, args ...any) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.findings = append(r.findings, healthFinding{Severity: severity, Check: check, Message: fmt.Sprintf(format, args...)})
+}
+
+// grade returns the findings, most severe first, and the worst severity among them.
+func (r *healthReport) grade() ([]healthFinding, string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	rank := map[string]int{gradeRed: 0, gradeYellow: 1}
+	sort.SliceStable(r.findings, func(i, j int) bool {
+		a, b := r.findings[i], r.findings[j]
+		if a.Severity != b.Severity {
+			return rank[a.Severity] < rank[b.Severity]
+		}
+		return a.Check < b.Check
+	})
+	status := gradeGreen
+	if len(r.findings) > 0 {
+		status = r.findings[0].Severity
+	}
+	return r.findings, status
+}
+
+func (st *State) toolHealthcheckFull(ctx context.Context, _ *mcp.CallToolRequest, in types.HealthcheckFullIn) (*mcp.CallToolResult, any, error) {
+	window := defaultErrorWindow
+	if in.ErrorWindow != "" {
+		d, err := time.ParseDuration(in.ErrorWindow)
+		if err != nil || d <= 0 {
+			return nil, nil, fmt.Errorf("input.errorWindow: expected a positive duration such as 15m, got %q", in.ErrorWindow)
+		}
+		window = d
+	}
+	start := time.Now()
+	report := &healthReport{}
+	out := map[string]any{"checkedAt": start.UTC()}
+	finish := func() (*mcp.CallToolResult, any, error) {
+		findings, status := report.grade()
+		if findings == nil {
+			findings = []healthFinding{}
+		}
+		out["status"] = status
+		out["findings"] = findings
+		out["durationMs"] = time.Since(start).Milliseconds()
+		slog.Info("Health check finished", "status", status, "findings", len(findings))
+		return nil, out, nil
+	}
+
+	// The cluster status names the live nodes and collections the other checks look at
+	status, err := st.backend().ClusterStatus(ctx, "")
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, nil, err
+		}
+		report.add(gradeRed, "cluster", "the cluster status could not be read: %v", err)
+		return finish()
+	}
+	liveNodes := status.Cluster.LiveNodes
+	out["cluster"] = map[string]any{"liveNodes": liveNodes, "numNodes": len(liveNodes)}
+	if len(liveNodes) == 0 {
+		report.add(gradeRed, "cluster", "the cluster has no live nodes")
+	}
+	out["collections"] = collectionHealth(report, status.Cluster.Collections, liveNodes)
+
+	spot, skipped := spotCheckCollections(in.Collections, status.Cluster.Collections)
+	for _, name := range in.Collections {
+		if _, ok := status.Cluster.Collections[name]; !ok {
+			report.add(gradeYellow, "consistency", "collection %s does not exist", name)
+		}
+	}
+	nodes := routing.LiveNodeURLs(st.solrURL(), liveNodes)
+	nodeStates := make([]nodeHealth, len(nodes))
+	consistency := make([]map[string]any, len(spot))
+	var wg sync.WaitGroup
+	for i, node := range nodes {
+		nodeStates[i].Node = node
+		wg.Add(1)
+		go func(n *nodeHealth) {
+			defer wg.Done()
+			st.checkNode(ctx, report, n, start.Add(-window))
+		}(&nodeStates[i])
+	}
+	for i, name := range spot {
+		cs := status.Cluster.Collections[name]
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			shards, findings := solr.CheckConsistency(ctx, st.HttpClient, st.BasicUser, st.BasicPass, &cs, "")
+			consistent := true
+			for _, s := range shards {
+				consistent = consistent && s.Consistent
+			}
+			for _, f := range findings {
+				report.add(gradeYellow, "consistency", "%s: %s", name, f)
+			}
+			consistency[i] = map[string]any{"collection": name, "consistent": consistent, "findings": findings}
+		}()
+	}
+	wg.Wait()
+	if ctx.Err() != nil {
+		return nil, nil, ctx.Err()
+	}
+	out["nodes"] = nodeStates
+	out["consistency"] = map[string]any{"collections": consistency, "skipped": skipped}
+	out["errorWindow"] = window.String()
+	return finish()
+}
+
+// collectionHealth grades the collections by their health in CLUSTERSTATUS and names the replicas that are not
+// active on a live node.
+func collectionHealth(report *healthReport, collections map[string]config.CollectionStatus, liveNodes []string) map[string]string {
+	health := make(map[string]string, len(collections))
+	for name, cs := range collections {
+		health[name] = cs.Health
+		if cs.Health != gradeYellow && cs.Health != gradeRed {
+			continue
+		}
+		var down []string
+		for shardName, shard := range cs.Shards {
+			for replicaName, r := range shard.Replicas {
+				if r.State != "active" || !slices.Contains(liveNodes, r.NodeName) {
+					down = append(down, fmt.Sprintf("%s/%s on %s is %s", shardName, replicaName, r.NodeName, r.State))
+				}
+			}
+		}
+		sort.Strings(down)
+		msg := fmt.Sprintf("collection %s is %s", name, cs.Health)
+		if len(down) > 0 {
+			msg += ": " + strings.Join(down, ", ")
+		}
+		report.add(cs.Health, "collections", "%s", msg)
+	}
+	return health
+}
+
+// spotCheckCollections returns the collections whose replicas are compared: those named, or else the first
+// maxSpotChecks by name, with the number of collections left out.
+func spotCheckCollections(named []string, collections map[string]config.CollectionStatus) ([]string, int) {
+	if len(named) > 0 {
+		var spot []string
+		for _, name := range named {
+			if _, ok := collections[name]; ok && !slices.Contains(spot, name) {
+				spot = append(spot, name)
+			}
+		}
+		return spot, 0
+	}
+	names := make([]string, 0, len(collections))
+	for name := range collections {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	if len(names) > maxSpotChecks {
+		return names[:maxSpotChecks], len(names) - maxSpotChecks
+	}
+	return names, 0
+}
+
+// checkNode reads the heap and disk usage and the errors logged since the start of the window of a live node.
+// Metrics that cannot be read are reported, as they may hide a problem.
+func (st *State) checkNode(ctx context.Context, report *healthReport, n *nodeHealth, since time.Time) {
+	var wg sync.WaitGroup
+	wg.Add(3)
+	go func() {
+		defer wg.Done()
+		m, err := solr.FetchNodeMetrics(ctx, st.HttpClient, n.Node, st.BasicUser, st.BasicPass)
+		if err != nil {
+			report.add(gradeYellow, "heap", "%s: %v", n.Node, err)
+			return
+		}
+		n.HeapUsage = &m.HeapUsage
+		switch {
+		case m.HeapUsage >= heapCritical:
+			report.add(gradeRed, "heap", "%s uses %.0f%% of its heap", n.Node, m.HeapUsage*100)
+		case m.HeapUsage >= heapWarning:
+			report.add(gradeYellow, "heap", "%s uses %.0f%% of its heap", n.Node, m.HeapUsage*100)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		d, err := solr.FetchDiskUsage(ctx, st.HttpClient, n.Node, st.BasicUser, st.BasicPass)
+		if err != nil {
+			report.add(gradeYellow, "disk", "%s: %v", n.Node, err)
+			return
+		}
+		n.Disk = &d
+		switch {
+		case d.FreeShare < diskCritical:
+			report.add(gradeRed, "disk", "%s has %.1f%% of its disk free", n.Node, d.FreeShare*100)
+		case d.FreeShare < diskWarning:
+			report.add(gradeYellow, "disk", "%s has %.1f%% of its disk free", n.Node, d.FreeShare*100)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		events, err := solr.RecentErrors(ctx, st.HttpClient, n.Node, st.BasicUser, st.BasicPass, since)
+		if err != nil {
+			report.add(gradeYellow, "errorLog", "%s: %v", n.Node, err)
+			return
+		}
+		count := len(events)
+		n.Errors = &count
+		n.RecentErrors = errorSamples(events)
+		if count > 0 {
+			report.add(gradeYellow, "errorLog", "%s logged %d errors since %s, most recently: %s",
+				n.Node, count, since.UTC().Format(time.RFC3339), n.RecentErrors[0].Message)
+		}
+	}()
+	wg.Wait()
+}
+
+// errorSamples groups events by message, most recent first, keeping maxErrorSamples messages.
+func errorSamples(events []solr.LogEvent) []errorSample {
+	var samples []errorSample
+	index := map[string]int{}
+	for i := len(events) - 1; i >= 0; i-- {
+		msg, _, _ := strings.Cut(events[i].Message, "\n")
+		if r := []rune(msg); len(r) > 200 {
+			msg = string(r[:200]) + "..."
+		}
+		if j, ok := index[msg]; ok {
+			samples[j].Count++
+			continue
+		}
+		index[msg] = len(samples)
+		samples = append(samples, errorSample{Message: msg, Count: 1, Last: events[i].Time})
+	}
+	return utils.HeadN(samples, maxErrorSamples)
+}
@@ -0,0 +1,96 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestHealthcheckFull tests grading the combined health checks of a cluster.
+func TestHealthcheckFull(t *testing.T) {
+	heap := 0.5
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		node := strings.TrimPrefix(server.URL, "http://") + "_solr"
+		switch {
+		case r.URL.Path == "/solr/admin/collections":
+			fmt.Fprintf(w, `{"cluster":{"live_nodes":[%[1]q],"collections":{
+				"products":{"health":"GREEN","shards":{"shard1":{"replicas":{
+					"core_node1":{"core":"products_shard1_replica_n1","node_name":%[1]q,"state":"active","base_url":"%[2]s/solr","leader":"true"},
+					"core_node2":{"core":"products_shard1_replica_n2","node_name":%[1]q,"state":"active","base_url":"%[2]s/solr"}}}}},
+				"orders":{"health":"YELLOW","shards":{"shard1":{"replicas":{
+					"core_node3":{"core":"orders_shard1_replica_n3","node_name":%[1]q,"state":"active","base_url":"%[2]s/solr","leader":"true"},
+					"core_node4":{"core":"orders_shard1_replica_n4","node_name":"10.0.0.9:8983_solr","state":"down","base_url":"%[2]s/gone"}}}}}}}}`, node, server.URL)
+		case r.URL.Path == "/solr/admin/metrics" && r.URL.Query().Get("group") == "node":
+			fmt.Fprint(w, `{"metrics":{"solr.node":{"CONTAINER.fs.usableSpace":100,"CONTAINER.fs.totalSpace":1000}}}`)
+		case r.URL.Path == "/solr/admin/metrics":
+			fmt.Fprintf(w, `{"metrics":{"solr.jvm":{"memory.heap.usage":%v}}}`, heap)
+		case r.URL.Path == "/solr/admin/info/logging":
+			fmt.Fprintf(w, `{"history":{"docs":[{"time":%q,"level":"ERROR","message":"undefined field foo\n\tat org.apache.solr"},{"time":%q,"level":"ERROR","message":"undefined field foo"}]}}`,
+				time.Now().Add(-time.Minute).UTC().Format(time.RFC3339), time.Now().UTC().Format(time.RFC3339))
+		case strings.HasPrefix(r.URL.Path, "/solr/") && strings.HasSuffix(r.URL.Path, "/select"):
+			fmt.Fprint(w, `{"response":{"numFound":10,"docs":[{"_version_":1790000000000000005}]}}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	st := NewServer(WithSolrURL(server.URL), WithHTTPClient(&http.Client{}))
+	ctx := context.Background()
+	run := func(in types.HealthcheckFullIn) map[string]any {
+		_, out, err := st.toolHealthcheckFull(ctx, nil, in)
+		require.NoError(t, err)
+		return out.(map[string]any)
+	}
+	findings := func(out map[string]any) []string {
+		var fs []string
+		for _, f := range out["findings"].([]healthFinding) {
+			fs = append(fs, f.Severity+" "+f.Check+": "+f.Message)
+		}
+		return fs
+	}
+
+	// Goal: Every check contributes its findings and the worst decides the grade.
+	out := run(types.HealthcheckFullIn{})
+	assert.Equal(t, "YELLOW", out["status"])
+	assert.Equal(t, map[string]string{"products": "GREEN", "orders": "YELLOW"}, out["collections"])
+	fs := findings(out)
+	assert.Contains(t, fs, "YELLOW collections: collection orders is YELLOW: shard1/core_node4 on 10.0.0.9:8983_solr is down")
+	assert.Contains(t, fs, "YELLOW disk: "+server.URL+" has 10.0% of its disk free")
+	assert.Contains(t, fs, "YELLOW errorLog: "+server.URL+" logged 2 errors since "+out["checkedAt"].(time.Time).Add(-15*time.Minute).Format(time.RFC3339)+", most recently: undefined field foo")
+	assert.Contains(t, fs, "YELLOW consistency: orders: shard1/core_node4 could not be checked: HTTP status 404: 404 page not found\n")
+	node := out["nodes"].([]nodeHealth)[0]
+	assert.Equal(t, 0.5, *node.HeapUsage)
+	assert.Equal(t, 2, *node.Errors)
+	assert.Equal(t, []errorSample{{Message: "undefined field foo", Count: 2, Last: node.RecentErrors[0].Last}}, node.RecentErrors)
+
+	// Goal: A critical finding makes the report RED and comes first.
+	heap = 0.97
+	out = run(types.HealthcheckFullIn{Collections: []string{"products", "missing"}, ErrorWindow: "1h"})
+	assert.Equal(t, "RED", out["status"])
+	fs = findings(out)
+	assert.Equal(t, "RED heap: "+server.URL+" uses 97% of its heap", fs[0])
+	assert.Contains(t, fs, "YELLOW consistency: collection missing does not exist")
+	assert.Equal(t, "1h0m0s", out["errorWindow"])
+	consistency := out["consistency"].(map[string]any)["collections"].([]map[string]any)
+	require.Len(t, consistency, 1)
+	assert.Equal(t, true, consistency[0]["consistent"])
+
+	// Goal: An unreachable cluster is a RED report, not a tool error.
+	st = NewServer(WithSolrURL("http://127.0.0.1:1"), WithHTTPClient(&http.Client{}))
+	out = run(types.HealthcheckFullIn{})
+	assert.Equal(t, "RED", out["status"])
+	assert.Contains(t, findings(out)[0], "RED cluster: the cluster status could not be read")
+
+	_, _, err := st.toolHealthcheckFull(ctx, nil, types.HealthcheckFullIn{ErrorWindow: "soon"})
+	assert.ErrorContains(t, err, "input.errorWindow")
+}
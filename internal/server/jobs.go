@@ -0,0 +1,347 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/jobs"
+	"github.com/Sashimimochi/solr-mcp-go/internal/solr"
+	"github.com/Sashimimochi/solr-mcp-go/internal/types"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// jobManager returns the background job manager, creating it on first use. Jobs read from the active Solr cluster,
+// imports from their object store.
+func (st *State) jobManager() *jobs.Manager {
+	st.jobsOnce.Do(func() {
+		fetch := func(ctx context.Context, job jobs.Job, cursor string) (jobs.Page, error) {
+			if job.Kind == jobs.Import {
+				return st.importPage(ctx, job, cursor)
+			}
+			docs, next, err := solr.CursorPage(ctx, st.HttpClient, st.solrURL(), st.BasicUser, st.BasicPass, job.Collection, job.Query, job.Fields, job.UniqueKey, cursor, job.BatchSize)
+			return jobs.Page{Docs: docs, Next: next}, err
+		}
+		st.jobs = jobs.NewManager(filepath.Join(st.DataDir, "jobs"), fetch, map[string]jobs.Sink{
+			jobs.Export:  exportSink{st: st},
+			jobs.Reindex: &reindexSink{st: st},
+			jobs.Import:  &reindexSink{st: st},
+		})
+	})
+	return st.jobs
+}
+
+// importPage reads the next batch of JSON lines of an import from the object store. The cursor is the byte offset
+// of the batch ("*" for the start), so each batch is one ranged request and the object is never staged locally.
+func (st *State) importPage(ctx context.Context, job jobs.Job, cursor string) (jobs.Page, error) {
+	var offset int64
+	if cursor != "*" {
+		var err error
+		if offset, err = strconv.ParseInt(cursor, 10, 64); err != nil {
+			return jobs.Page{}, fmt.Errorf("invalid import cursor %q", cursor)
+		}
+	}
+	store, key, err := st.sourceStore(job.Source)
+	if err != nil {
+		return jobs.Page{}, err
+	}
+	r, err := store.Open(ctx, key, offset)
+	if err != nil {
+		return jobs.Page{}, err
+	}
+	defer r.Close()
+	br := bufio.NewReader(r)
+	var docs []map[string]any
+	next := offset
+	for len(docs) < job.BatchSize {
+		line, err := br.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return jobs.Page{}, fmt.Errorf("read %s at byte %d: %v", job.Source, next, err)
+		}
+		if trimmed := bytes.TrimSpace(line); len(trimmed) > 0 {
+			dec := json.NewDecoder(bytes.NewReader(trimmed))
+			dec.UseNumber() // keep long values exact
+			var doc map[string]any
+			if derr := dec.Decode(&doc); derr != nil || doc == nil {
+				return jobs.Page{}, fmt.Errorf("%s at byte %d: each line must be a JSON object", job.Source, next)
+			}
+			docs = append(docs, doc)
+		}
+		next += int64(len(line))
+		if err == io.EOF {
+			break
+		}
+	}
+	return jobs.Page{Docs: docs, Next: strconv.FormatInt(next, 10)}, nil
+}
+
+// resumeJobs resumes the jobs that were running when the server stopped. They run until ctx is done.
+func (st *State) resumeJobs(ctx context.Context) {
+	if err := st.jobManager().ResumeAll(ctx); err != nil {
+		slog.Error("Failed to resume background jobs", "error", err)
+	}
+}
+
+// exportSink appends documents as JSON lines to the target file of a job. With a destination, the file is
+// uploaded to that object store when the job is done and then removed.
+type exportSink struct {
+	st *State
+}
+
+func (exportSink) Write(_ context.Context, job jobs.Job, docs []map[string]any) (int64, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, d := range docs {
+		if err := enc.Encode(d); err != nil {
+			return 0, fmt.Errorf("encode document: %v", err)
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(job.Target), 0o755); err != nil {
+		return 0, fmt.Errorf("create export directory: %v", err)
+	}
+	f, err := os.OpenFile(job.Target, os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return 0, fmt.Errorf("open export file: %v", err)
+	}
+	defer f.Close()
+	// Lines written after the last checkpoint are dropped, so a resumed job does not duplicate them
+	if err := f.Truncate(job.Bytes); err != nil {
+		return 0, fmt.Errorf("truncate export file to the checkpoint: %v", err)
+	}
+	if _, err := f.WriteAt(buf.Bytes(), job.Bytes); err != nil {
+		return 0, fmt.Errorf("write export file: %v", err)
+	}
+	return int64(buf.Len()), f.Sync()
+}
+
+func (s exportSink) Finish(ctx context.Context, job *jobs.Job) error {
+	if job.Destination == "" {
+		return nil
+	}
+	store, err := s.st.objectStore(job.Destination)
+	if err != nil {
+		return err
+	}
+	if job.Processed == 0 {
+		// nothing was written, but the destination should still get an (empty) export
+		if err := os.MkdirAll(filepath.Dir(job.Target), 0o755); err != nil {
+			return fmt.Errorf("create export directory: %v", err)
+		}
+		if err := os.WriteFile(job.Target, nil, 0o644); err != nil {
+			return fmt.Errorf("write export file: %v", err)
+		}
+	}
+	obj, err := store.Upload(ctx, "exports/"+filepath.Base(job.Target), job.Target, "application/x-ndjson")
+	if err != nil {
+		return err
+	}
+	job.Object = &obj
+	slog.Info("Export uploaded", "id", job.ID, "store", obj.Store, "key", obj.Key, "bytes", obj.Bytes)
+	if err := os.Remove(job.Target); err != nil {
+		slog.Warn("Failed to remove uploaded export file", "id", job.ID, "error", err)
+	}
+	return nil
+}
+
+// reindexSink adds documents to the collection a reindex or import writes. Batches repeated after a resume
+// overwrite the same documents by uniqueKey.
+type reindexSink struct {
+	st   *State
+	mu   sync.Mutex
+	skip map[string][]string // job ID -> fields not copied
+}
+
+func (s *reindexSink) skipFields(ctx context.Context, job jobs.Job) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if skip, ok := s.skip[job.ID]; ok {
+		return skip, nil
+	}
+	skip, err := solr.ReindexSkipFields(ctx, s.st.HttpClient, s.st.solrURL(), s.st.BasicUser, s.st.BasicPass, writtenCollection(job))
+	if err != nil {
+		return nil, err
+	}
+	if s.skip == nil {
+		s.skip = make(map[string][]string)
+	}
+	s.skip[job.ID] = skip
+	return skip, nil
+}
+
+func (s *reindexSink) Write(ctx context.Context, job jobs.Job, docs []map[string]any) (int64, error) {
+	skip, err := s.skipFields(ctx, job)
+	if err != nil {
+		return 0, err
+	}
+	batch := make([]map[string]any, len(docs))
+	for i, d := range docs {
+		batch[i] = solr.WithoutFields(d, skip)
+	}
+	collection := writtenCollection(job)
+	if err := solr.IndexDocuments(ctx, s.st.HttpClient, s.st.solrURL(), s.st.BasicUser, s.st.BasicPass, collection, batch); err != nil {
+		return 0, fmt.Errorf("write %s: %v", collection, err)
+	}
+	data, _ := json.Marshal(batch)
+	return int64(len(data)), nil
+}
+
+func (s *reindexSink) Finish(ctx context.Context, job *jobs.Job) error {
+	s.mu.Lock()
+	delete(s.skip, job.ID)
+	s.mu.Unlock()
+	return solr.Commit(ctx, s.st.HttpClient, s.st.solrURL(), s.st.BasicUser, s.st.BasicPass, writtenCollection(*job))
+}
+
+// writtenCollection is the collection a reindex or import job writes.
+func writtenCollection(job jobs.Job) string {
+	if job.Kind == jobs.Import {
+		return job.Collection
+	}
+	return job.Target
+}
+
+// startJob fills in the uniqueKey, match count and throttling of job and starts it. Imports have no match count.
+func (st *State) startJob(ctx context.Context, job jobs.Job) (*mcp.CallToolResult, any, error) {
+	if e := st.CapabilityError("background_jobs"); e != nil {
+		return nil, nil, e
+	}
+	if job.Query == "" && job.Kind != jobs.Import {
+		job.Query = "*:*"
+	}
+	if job.BatchSize < 0 {
+		return nil, nil, errors.New("input.batchSize must not be negative")
+	}
+	if job.Throttle.MaxRequestsPerSecond < 0 || job.Throttle.MaxBytesPerSecond < 0 {
+		return nil, nil, errors.New("input.maxRequestsPerSecond and input.maxBytesPerSecond must not be negative")
+	}
+	defaults := st.fileConfig().Jobs
+	if job.Throttle.MaxRequestsPerSecond == 0 {
+		job.Throttle.MaxRequestsPerSecond = defaults.MaxRequestsPerSecond
+	}
+	if job.Throttle.MaxBytesPerSecond == 0 {
+		job.Throttle.MaxBytesPerSecond = defaults.MaxBytesPerSecond
+	}
+	key, err := st.requireUniqueKey(ctx, job.Collection)
+	if err != nil {
+		return nil, nil, err
+	}
+	job.UniqueKey = key
+	if job.Kind != jobs.Import {
+		if job.Matched, err = solr.CountDocuments(ctx, st.HttpClient, st.solrURL(), st.BasicUser, st.BasicPass, job.Collection, job.Query); err != nil {
+			return nil, nil, fmt.Errorf("count documents: %v", err)
+		}
+	}
+	started, err := st.jobManager().Start(job)
+	if err != nil {
+		return nil, nil, err
+	}
+	slog.Info("Background job started", "id", started.ID, "kind", started.Kind, "collection", started.Collection, "target", started.Target, "source", started.Source, "matched", started.Matched)
+	return nil, started, nil
+}
+
+func (st *State) toolExport(ctx context.Context, _ *mcp.CallToolRequest, in types.ExportIn) (*mcp.CallToolResult, any, error) {
+	if strings.TrimSpace(in.Collection) == "" {
+		return nil, nil, errors.New("input.collection is required")
+	}
+	job := jobs.Job{
+		Kind:       jobs.Export,
+		Collection: in.Collection,
+		Query:      in.Query,
+		Fields:     in.Fields,
+		BatchSize:  in.BatchSize,
+		Throttle:   jobs.Throttle{MaxRequestsPerSecond: in.MaxRequestsPerSecond, MaxBytesPerSecond: in.MaxBytesPerSecond},
+	}
+	if in.Destination != "" {
+		// fail before starting rather than after the whole export
+		if _, err := st.objectStore(in.Destination); err != nil {
+			return nil, nil, err
+		}
+		job.Destination = in.Destination
+	}
+	job.ID = jobs.NewID(jobs.Export)
+	job.Target = filepath.Join(st.DataDir, "exports", job.ID+".jsonl")
+	return st.startJob(ctx, job)
+}
+
+func (st *State) toolReindex(ctx context.Context, _ *mcp.CallToolRequest, in types.ReindexIn) (*mcp.CallToolResult, any, error) {
+	if strings.TrimSpace(in.Collection) == "" {
+		return nil, nil, errors.New("input.collection is required")
+	}
+	if strings.TrimSpace(in.Target) == "" {
+		return nil, nil, errors.New("input.target is required")
+	}
+	if in.Target == in.Collection {
+		return nil, nil, errors.New("input.target must differ from input.collection")
+	}
+	return st.startJob(ctx, jobs.Job{
+		Kind:       jobs.Reindex,
+		Collection: in.Collection,
+		Query:      in.Query,
+		Target:     in.Target,
+		BatchSize:  in.BatchSize,
+		Throttle:   jobs.Throttle{MaxRequestsPerSecond: in.MaxRequestsPerSecond, MaxBytesPerSecond: in.MaxBytesPerSecond},
+	})
+}
+
+func (st *State) toolImport(ctx context.Context, _ *mcp.CallToolRequest, in types.ImportIn) (*mcp.CallToolResult, any, error) {
+	if strings.TrimSpace(in.Collection) == "" {
+		return nil, nil, errors.New("input.collection is required")
+	}
+	if strings.TrimSpace(in.Source) == "" {
+		return nil, nil, errors.New("input.source is required")
+	}
+	// fail before starting when no store can read the source
+	if _, _, err := st.sourceStore(in.Source); err != nil {
+		return nil, nil, fmt.Errorf("input.source: %v", err)
+	}
+	return st.startJob(ctx, jobs.Job{
+		Kind:       jobs.Import,
+		Collection: in.Collection,
+		Source:     in.Source,
+		BatchSize:  in.BatchSize,
+		Throttle:   jobs.Throttle{MaxRequestsPerSecond: in.MaxRequestsPerSecond, MaxBytesPerSecond: in.MaxBytesPerSecond},
+	})
+}
+
+func (st *State) toolJobs(ctx context.Context, _ *mcp.CallToolRequest, in types.JobsIn) (*mcp.CallToolResult, any, error) {
+	if e := st.CapabilityError("background_jobs"); e != nil {
+		return nil, nil, e
+	}
+	m := st.jobManager()
+	switch in.Action {
+	case "":
+		if in.ID == "" {
+			return nil, map[string]any{"jobs": m.List()}, nil
+		}
+		job, err := m.Get(in.ID)
+		return nil, job, err
+	case "cancel", "resume":
+		if in.ID == "" {
+			return nil, nil, fmt.Errorf("input.id is required to %s a job", in.Action)
+		}
+		var job jobs.Job
+		var err error
+		if in.Action == "cancel" {
+			job, err = m.Cancel(in.ID)
+		} else {
+			job, err = m.Resume(in.ID)
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		slog.Info("Background job "+in.Action, "id", job.ID, "status", job.Status, "processed", job.Processed)
+		return nil, job, nil
+	}
+	return nil, nil, errors.New("input.action must be cancel or resume")
+}
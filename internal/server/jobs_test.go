@@ -0,0 +1,85 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/jobs"
+	"github.com/Sashimimochi/solr-mcp-go/internal/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestToolExport tests exporting a collection in the background and following the job.
+func TestToolExport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/solr/products/schema/uniquekey":
+			fmt.Fprintln(w, `{"uniqueKey":"id"}`)
+		case "/solr/products/schema/fields":
+			fmt.Fprintln(w, `{"fields":[]}`)
+		case "/solr/products/select":
+			switch r.URL.Query().Get("cursorMark") {
+			case "":
+				fmt.Fprintln(w, `{"response":{"numFound":3,"docs":[]}}`)
+			case "*":
+				fmt.Fprintln(w, `{"response":{"docs":[{"id":"1"},{"id":"2"}]},"nextCursorMark":"c2"}`)
+			case "c2":
+				fmt.Fprintln(w, `{"response":{"docs":[{"id":"3"}]},"nextCursorMark":"c3"}`)
+			default:
+				fmt.Fprintln(w, `{"response":{"docs":[]},"nextCursorMark":"c3"}`)
+			}
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	ctx := context.Background()
+
+	// Goal: Background jobs need a data directory for their checkpoints.
+	_, _, err := newTestState(t, server.URL).toolExport(ctx, nil, types.ExportIn{Collection: "products"})
+	var capErr *CapabilityError
+	require.ErrorAs(t, err, &capErr)
+	assert.Equal(t, "background_jobs", capErr.Capability)
+
+	st := NewServer(WithSolrURL(server.URL), WithHTTPClient(&http.Client{}), WithDataDir(t.TempDir()))
+
+	// Goal: The export writes all pages as JSON lines to a file named after the job.
+	_, out, err := st.toolExport(ctx, nil, types.ExportIn{Collection: "products", BatchSize: 2, MaxRequestsPerSecond: 100})
+	require.NoError(t, err)
+	job := out.(jobs.Job)
+	assert.Equal(t, int64(3), job.Matched)
+	assert.Equal(t, 100.0, job.Throttle.MaxRequestsPerSecond)
+	assert.True(t, strings.HasSuffix(job.Target, job.ID+".jsonl"))
+	require.Eventually(t, func() bool {
+		_, out, _ := st.toolJobs(ctx, nil, types.JobsIn{ID: job.ID})
+		return out.(jobs.Job).Status == jobs.Done
+	}, 5*time.Second, 10*time.Millisecond)
+	data, err := os.ReadFile(job.Target)
+	require.NoError(t, err)
+	assert.Equal(t, "{\"id\":\"1\"}\n{\"id\":\"2\"}\n{\"id\":\"3\"}\n", string(data))
+
+	// Goal: Jobs are listed, and done jobs cannot be resumed.
+	_, out, err = st.toolJobs(ctx, nil, types.JobsIn{})
+	require.NoError(t, err)
+	assert.Len(t, out.(map[string]any)["jobs"], 1)
+	_, _, err = st.toolJobs(ctx, nil, types.JobsIn{ID: job.ID, Action: "resume"})
+	assert.ErrorContains(t, err, "is done")
+
+	// Goal: Invalid input is rejected.
+	_, _, err = st.toolExport(ctx, nil, types.ExportIn{Collection: "products", MaxBytesPerSecond: -1})
+	assert.ErrorContains(t, err, "must not be negative")
+	_, _, err = st.toolReindex(ctx, nil, types.ReindexIn{Collection: "products", Target: "products"})
+	assert.ErrorContains(t, err, "must differ")
+	_, _, err = st.toolJobs(ctx, nil, types.JobsIn{Action: "cancel"})
+	assert.ErrorContains(t, err, "input.id is required")
+	_, _, err = st.toolJobs(ctx, nil, types.JobsIn{ID: job.ID, Action: "pause"})
+	assert.ErrorContains(t, err, "input.action")
+}
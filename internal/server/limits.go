@@ -0,0 +1,47 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/errcatalog"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// toolArgumentLimitMiddleware rejects tools/call requests whose arguments exceed the limit of the tool in
+// toolArgsLimits, or limit bytes for tools without one. The rejection is returned as a tool error so that the
+// agent sees why the call failed. A limit of zero or less disables the check.
+func (st *State) toolArgumentLimitMiddleware(limit int) mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			if method != "tools/call" {
+				return next(ctx, method, req)
+			}
+			call, ok := req.(*mcp.CallToolRequest)
+			if !ok {
+				return next(ctx, method, req)
+			}
+			allowed, perTool := st.fileConfig().ToolArgsLimit(call.Params.Name, limit)
+			if allowed <= 0 || len(call.Params.Arguments) <= allowed {
+				return next(ctx, method, req)
+			}
+			source := "SOLR_MCP_MAX_TOOL_ARGS_BYTES"
+			if perTool {
+				source = "toolArgsLimits of the config file"
+			}
+			slog.Warn("Tool arguments too large", "tool", call.Params.Name, "size", len(call.Params.Arguments), "limit", allowed)
+			return st.errorResult(call.Params.Name, errcatalog.New(errcatalog.InvalidArgument,
+				"arguments of %s are %d bytes, exceeding the limit of %d bytes (%s); %s",
+				call.Params.Name, len(call.Params.Arguments), allowed, source, oversizedArgumentsHint(call.Params.Name))), nil
+		}
+	}
+}
+
+// oversizedArgumentsHint tells the agent how to send the content of a rejected call instead.
+func oversizedArgumentsHint(tool string) string {
+	if tool == "solr.update" {
+		return "split the documents into smaller calls, or upload them as JSON lines to S3 or GCS and load them with solr.import"
+	}
+	return "split the request into smaller calls"
+}
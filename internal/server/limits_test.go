@@ -0,0 +1,56 @@
+package server
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/config"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestToolArgumentLimitMiddleware tests that oversized tool arguments are rejected before the tool runs.
+func TestToolArgumentLimitMiddleware(t *testing.T) {
+	st := newTestState(t, "http://localhost:8983")
+	mcpServer := mcp.NewServer(&mcp.Implementation{}, nil)
+	mcpServer.AddReceivingMiddleware(st.toolArgumentLimitMiddleware(64))
+	AddTools(mcpServer, st)
+	session, _, _ := connectTestClient(t, mcpServer)
+
+	// Goal: Arguments over the limit return a tool error naming the limit.
+	res, err := session.CallTool(context.Background(), &mcp.CallToolParams{
+		Name:      "solr.query",
+		Arguments: map[string]any{"query": strings.Repeat("x", 100)},
+	})
+	assert.NoError(t, err)
+	assert.True(t, res.IsError)
+	text := res.Content[0].(*mcp.TextContent).Text
+	assert.Contains(t, text, "exceeding the limit of 64 bytes")
+	assert.Contains(t, text, "solr.query")
+	assert.Equal(t, "invalid_argument", res.StructuredContent.(map[string]any)["error"].(map[string]any)["code"])
+
+	// Goal: Arguments within the limit reach the tool.
+	res, err = session.CallTool(context.Background(), &mcp.CallToolParams{Name: "solr.info", Arguments: map[string]any{}})
+	assert.NoError(t, err)
+	assert.False(t, res.IsError)
+
+	// Goal: toolArgsLimits overrides the limit per tool, and the error names it and suggests solr.import for updates.
+	st.Config = &config.FileConfig{ToolArgsLimits: map[string]int{"solr.query": 0, "solr.update": 16}}
+	res, err = session.CallTool(context.Background(), &mcp.CallToolParams{
+		Name:      "solr.query",
+		Arguments: map[string]any{"collection": "products", "query": strings.Repeat("x", 100)},
+	})
+	assert.NoError(t, err)
+	assert.NotContains(t, res.Content[0].(*mcp.TextContent).Text, "exceeding the limit")
+	res, err = session.CallTool(context.Background(), &mcp.CallToolParams{
+		Name:      "solr.update",
+		Arguments: map[string]any{"collection": "products", "documents": []any{map[string]any{"id": "1"}}},
+	})
+	assert.NoError(t, err)
+	assert.True(t, res.IsError)
+	text = res.Content[0].(*mcp.TextContent).Text
+	assert.Contains(t, text, "exceeding the limit of 16 bytes (toolArgsLimits of the config file)")
+	assert.Contains(t, text, "solr.import")
+}
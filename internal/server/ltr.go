@@ -0,0 +1,157 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/solr"
+	"github.com/Sashimimochi/solr-mcp-go/internal/types"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// defaultFeatureStore is the feature store of features and models that do not name one.
+const defaultFeatureStore = "_DEFAULT_"
+
+func (st *State) toolLTRList(ctx context.Context, _ *mcp.CallToolRequest, in types.LTRListIn) (*mcp.CallToolResult, any, error) {
+	if strings.TrimSpace(in.Collection) == "" {
+		return nil, nil, errors.New("input.collection is required")
+	}
+	if in.Store != "" {
+		features, err := solr.GetLTRFeatures(ctx, st.HttpClient, st.solrURL(), st.BasicUser, st.BasicPass, in.Collection, in.Store)
+		if err != nil {
+			return nil, nil, err
+		}
+		return nil, map[string]any{"collection": in.Collection, "store": in.Store, "features": features}, nil
+	}
+	stores, err := solr.ListLTR(ctx, st.HttpClient, st.solrURL(), st.BasicUser, st.BasicPass, in.Collection)
+	if err != nil {
+		return nil, nil, err
+	}
+	return nil, map[string]any{"collection": in.Collection, "featureStores": stores.FeatureStores, "models": stores.Models}, nil
+}
+
+func (st *State) toolLTRUpload(ctx context.Context, _ *mcp.CallToolRequest, in types.LTRUploadIn) (*mcp.CallToolResult, any, error) {
+	if strings.TrimSpace(in.Collection) == "" {
+		return nil, nil, errors.New("input.collection is required")
+	}
+	if len(in.Features) == 0 && in.Model == nil {
+		return nil, nil, errors.New("input.features or input.model is required")
+	}
+	featureStores, err := checkLTRFeatures(in.Features)
+	if err != nil {
+		return nil, nil, err
+	}
+	model := ""
+	if in.Model != nil {
+		if model, err = checkLTRModel(in.Model); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	out := map[string]any{"collection": in.Collection}
+	if in.Replace {
+		existing, err := solr.ListLTR(ctx, st.HttpClient, st.solrURL(), st.BasicUser, st.BasicPass, in.Collection)
+		if err != nil {
+			return nil, nil, err
+		}
+		// Models are deleted first: Solr refuses to delete a feature store a model still uses
+		if model != "" && slices.ContainsFunc(existing.Models, func(m map[string]any) bool { return m["name"] == model }) {
+			if err := solr.DeleteLTR(ctx, st.HttpClient, st.solrURL(), st.BasicUser, st.BasicPass, in.Collection, "model-store", model); err != nil {
+				return nil, nil, fmt.Errorf("delete model %s: %v", model, err)
+			}
+			out["replacedModel"] = model
+		}
+		var replaced []string
+		for _, store := range featureStores {
+			if !slices.Contains(existing.FeatureStores, store) {
+				continue
+			}
+			if err := solr.DeleteLTR(ctx, st.HttpClient, st.solrURL(), st.BasicUser, st.BasicPass, in.Collection, "feature-store", store); err != nil {
+				return nil, nil, fmt.Errorf("delete feature store %s: %v", store, err)
+			}
+			replaced = append(replaced, store)
+		}
+		if len(replaced) > 0 {
+			out["replacedFeatureStores"] = replaced
+		}
+	}
+	if len(in.Features) > 0 {
+		if err := solr.PutLTRFeatures(ctx, st.HttpClient, st.solrURL(), st.BasicUser, st.BasicPass, in.Collection, in.Features); err != nil {
+			return nil, nil, fmt.Errorf("upload features: %v", err)
+		}
+		out["features"] = len(in.Features)
+		out["featureStores"] = featureStores
+	}
+	if model != "" {
+		if err := solr.PutLTRModel(ctx, st.HttpClient, st.solrURL(), st.BasicUser, st.BasicPass, in.Collection, in.Model); err != nil {
+			return nil, nil, fmt.Errorf("upload model %s: %v", model, err)
+		}
+		out["model"] = model
+	}
+	slog.Info("LTR resources uploaded", "collection", in.Collection, "features", len(in.Features), "model", model, "replace", in.Replace)
+
+	// Managed resources are only picked up by queries after a reload
+	if in.NoReload {
+		out["reloaded"] = false
+		return nil, out, nil
+	}
+	if v, known := st.solrVersion(ctx); known && v.Mode != "" && !v.Cloud() {
+		err = solr.ReloadCore(ctx, st.HttpClient, st.solrURL(), st.BasicUser, st.BasicPass, in.Collection)
+	} else {
+		err = solr.ReloadCollection(ctx, st.HttpClient, st.solrURL(), st.BasicUser, st.BasicPass, in.Collection)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("uploaded but not applied: %v", err)
+	}
+	out["reloaded"] = true
+	return nil, out, nil
+}
+
+// checkLTRFeatures validates features and returns the feature stores they go to.
+func checkLTRFeatures(features []map[string]any) ([]string, error) {
+	seen := map[string]bool{}
+	var stores []string
+	for i, f := range features {
+		name, _ := f["name"].(string)
+		if name == "" {
+			return nil, fmt.Errorf("input.features[%d].name is required", i)
+		}
+		if class, _ := f["class"].(string); class == "" {
+			return nil, fmt.Errorf("input.features[%d].class is required (e.g. org.apache.solr.ltr.feature.SolrFeature)", i)
+		}
+		store, _ := f["store"].(string)
+		if store == "" {
+			store = defaultFeatureStore
+		}
+		if seen[store+"\x00"+name] {
+			return nil, fmt.Errorf("feature %s appears twice in store %s", name, store)
+		}
+		seen[store+"\x00"+name] = true
+		if !slices.Contains(stores, store) {
+			stores = append(stores, store)
+		}
+	}
+	sort.Strings(stores)
+	return stores, nil
+}
+
+// checkLTRModel validates a model and returns its name.
+func checkLTRModel(model map[string]any) (string, error) {
+	name, _ := model["name"].(string)
+	if name == "" {
+		return "", errors.New("input.model.name is required")
+	}
+	if class, _ := model["class"].(string); class == "" {
+		return "", errors.New("input.model.class is required (e.g. org.apache.solr.ltr.model.LinearModel)")
+	}
+	if features, _ := model["features"].([]any); len(features) == 0 {
+		return "", errors.New("input.model.features is required")
+	}
+	return name, nil
+}
@@ -0,0 +1,78 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestToolLTR tests listing and uploading Learning To Rank features and models.
+func TestToolLTR(t *testing.T) {
+	var calls []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/solr/products/schema/feature-store":
+			fmt.Fprint(w, `{"featureStores":["shop"]}`)
+		case r.Method == http.MethodGet && r.URL.Path == "/solr/products/schema/feature-store/shop":
+			fmt.Fprint(w, `{"features":[{"name":"title_match","class":"org.apache.solr.ltr.feature.SolrFeature","store":"shop"}]}`)
+		case r.Method == http.MethodGet && r.URL.Path == "/solr/products/schema/model-store":
+			fmt.Fprint(w, `{"models":[{"name":"linear","store":"shop"}]}`)
+		case r.URL.Path == "/solr/admin/info/system":
+			fmt.Fprint(w, `{"mode":"solrcloud","lucene":{"solr-spec-version":"9.4.0"}}`)
+		case r.URL.Path == "/solr/admin/collections":
+			calls = append(calls, r.URL.Query().Get("action")+" "+r.URL.Query().Get("name"))
+			fmt.Fprint(w, `{}`)
+		default:
+			calls = append(calls, r.Method+" "+r.URL.Path)
+			fmt.Fprint(w, `{}`)
+		}
+	}))
+	defer server.Close()
+	st := newTestState(t, server.URL)
+	ctx := context.Background()
+
+	// Goal: Feature stores and models are listed, and the features of a store on request.
+	_, out, err := st.toolLTRList(ctx, nil, types.LTRListIn{Collection: "products"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"shop"}, out.(map[string]any)["featureStores"])
+	assert.Len(t, out.(map[string]any)["models"], 1)
+	_, out, err = st.toolLTRList(ctx, nil, types.LTRListIn{Collection: "products", Store: "shop"})
+	require.NoError(t, err)
+	assert.Len(t, out.(map[string]any)["features"], 1)
+
+	// Goal: With replace, the existing model and store are deleted, features are uploaded before the model and the collection is reloaded.
+	features := []map[string]any{
+		{"name": "title_match", "class": "org.apache.solr.ltr.feature.SolrFeature", "store": "shop", "params": map[string]any{"q": "{!field f=title}${user_query}"}},
+		{"name": "popularity", "class": "org.apache.solr.ltr.feature.FieldValueFeature", "params": map[string]any{"field": "popularity"}},
+	}
+	model := map[string]any{"name": "linear", "class": "org.apache.solr.ltr.model.LinearModel", "store": "shop", "features": []any{map[string]any{"name": "title_match"}}, "params": map[string]any{}}
+	_, out, err = st.toolLTRUpload(ctx, nil, types.LTRUploadIn{Collection: "products", Features: features, Model: model, Replace: true})
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"DELETE /solr/products/schema/model-store/linear",
+		"DELETE /solr/products/schema/feature-store/shop",
+		"PUT /solr/products/schema/feature-store",
+		"PUT /solr/products/schema/model-store",
+		"RELOAD products",
+	}, calls)
+	res := out.(map[string]any)
+	assert.Equal(t, []string{"_DEFAULT_", "shop"}, res["featureStores"])
+	assert.Equal(t, true, res["reloaded"])
+
+	// Goal: Incomplete definitions are rejected before anything is sent.
+	calls = nil
+	_, _, err = st.toolLTRUpload(ctx, nil, types.LTRUploadIn{Collection: "products", Features: []map[string]any{{"name": "x"}}})
+	assert.ErrorContains(t, err, "input.features[0].class is required")
+	_, _, err = st.toolLTRUpload(ctx, nil, types.LTRUploadIn{Collection: "products", Model: map[string]any{"name": "m", "class": "c"}})
+	assert.ErrorContains(t, err, "input.model.features is required")
+	_, _, err = st.toolLTRUpload(ctx, nil, types.LTRUploadIn{Collection: "products"})
+	assert.ErrorContains(t, err, "input.features or input.model is required")
+	assert.Empty(t, calls)
+}
@@ -0,0 +1,98 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/config"
+	"github.com/Sashimimochi/solr-mcp-go/internal/maintenance"
+	"github.com/Sashimimochi/solr-mcp-go/internal/notify"
+	"github.com/Sashimimochi/solr-mcp-go/internal/retention"
+	"github.com/Sashimimochi/solr-mcp-go/internal/solr"
+	"github.com/Sashimimochi/solr-mcp-go/internal/types"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// maintenanceManager returns the maintenance manager, creating it on first use.
+func (st *State) maintenanceManager() *maintenance.Manager {
+	st.maintenanceOnce.Do(func() {
+		runRetention := func(ctx context.Context) (retention.Run, error) {
+			run, err := st.retentionManager().Execute(ctx, "", "maintenance")
+			st.invalidateCollections()
+			return run, err
+		}
+		st.maintenance = maintenance.NewManager(st.fileConfig().Maintenance, &maintenanceBackend{st: st}, st.maintenanceHealth, runRetention, st.notifyMaintenance)
+	})
+	return st.maintenance
+}
+
+// maintenanceBackend runs the maintenance job against the primary Solr. Merges and backups can take longer than
+// the timeout of the HTTP client, so they are only bounded by the end of the window.
+type maintenanceBackend struct {
+	st *State
+}
+
+func (b *maintenanceBackend) client() *http.Client {
+	hc := *b.st.HttpClient
+	hc.Timeout = 0
+	return &hc
+}
+
+func (b *maintenanceBackend) ListCollections(ctx context.Context) ([]string, error) {
+	return b.st.backend().ListCollections(ctx)
+}
+
+func (b *maintenanceBackend) IndexStats(ctx context.Context, collection string) (solr.IndexStats, error) {
+	return solr.GetIndexStats(ctx, b.st.HttpClient, b.st.BaseURL, b.st.BasicUser, b.st.BasicPass, collection)
+}
+
+func (b *maintenanceBackend) ExpungeDeletes(ctx context.Context, collection string) error {
+	return solr.ExpungeDeletes(ctx, b.client(), b.st.BaseURL, b.st.BasicUser, b.st.BasicPass, collection)
+}
+
+func (b *maintenanceBackend) Optimize(ctx context.Context, collection string, maxSegments int) error {
+	return solr.Optimize(ctx, b.client(), b.st.BaseURL, b.st.BasicUser, b.st.BasicPass, collection, maxSegments)
+}
+
+func (b *maintenanceBackend) Backup(ctx context.Context, collection string, cfg config.MaintenanceBackupConfig) error {
+	return solr.BackupCollection(ctx, b.st.HttpClient, b.st.BaseURL, b.st.BasicUser, b.st.BasicPass, collection, cfg.Location, cfg.Repository, cfg.Keep)
+}
+
+// maintenanceHealth grades the cluster with the checks of solr.healthcheck.full.
+func (st *State) maintenanceHealth(ctx context.Context) maintenance.Health {
+	_, out, err := st.toolHealthcheckFull(ctx, nil, types.HealthcheckFullIn{})
+	if err != nil {
+		return maintenance.Health{Status: gradeRed, Findings: []string{err.Error()}}
+	}
+	report := out.(map[string]any)
+	h := maintenance.Health{Status: report["status"].(string)}
+	for _, f := range report["findings"].([]healthFinding) {
+		h.Findings = append(h.Findings, fmt.Sprintf("%s: %s", f.Check, f.Message))
+	}
+	return h
+}
+
+// notifyMaintenance sends the summary of a maintenance run.
+func (st *State) notifyMaintenance(ctx context.Context, r maintenance.Run) {
+	if err := st.notifier().Send(ctx, notify.Event{Type: "maintenance", Summary: r.Summary(), Details: r}); err != nil {
+		slog.Error("Failed to send maintenance notification", "error", err)
+	}
+}
+
+func (st *State) toolMaintenanceStatus(ctx context.Context, _ *mcp.CallToolRequest, _ types.MaintenanceStatusIn) (*mcp.CallToolResult, any, error) {
+	if e := st.CapabilityError("maintenance"); e != nil {
+		return nil, nil, e
+	}
+	m := st.maintenanceManager()
+	out := map[string]any{
+		"config":  m.Config(),
+		"history": m.History(),
+	}
+	if next, ok := m.NextWindow(); ok {
+		out["nextWindow"] = next
+	}
+	return nil, out, nil
+}
@@ -0,0 +1,99 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/config"
+	"github.com/Sashimimochi/solr-mcp-go/internal/maintenance"
+	"github.com/Sashimimochi/solr-mcp-go/internal/notify"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMaintenance tests a maintenance run against a healthy cluster and its notification.
+func TestMaintenance(t *testing.T) {
+	var mu sync.Mutex
+	var updates, backups []string
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		node := strings.TrimPrefix(server.URL, "http://") + "_solr"
+		q := r.URL.Query()
+		switch {
+		case r.URL.Path == "/solr/admin/collections" && q.Get("action") == "BACKUP":
+			backups = append(backups, q.Get("collection")+" "+q.Get("location"))
+			fmt.Fprint(w, `{}`)
+		case r.URL.Path == "/solr/admin/collections" && q.Get("action") == "LIST":
+			fmt.Fprint(w, `{"collections":["logs"]}`)
+		case r.URL.Path == "/solr/admin/collections" && q.Get("action") == "REQUESTSTATUS":
+			fmt.Fprint(w, `{"status":{"state":"completed"}}`)
+		case r.URL.Path == "/solr/admin/collections":
+			fmt.Fprintf(w, `{"cluster":{"live_nodes":[%[1]q],"collections":{"logs":{"health":"GREEN","shards":{"shard1":{"replicas":{
+				"core_node1":{"core":"logs_shard1_replica_n1","node_name":%[1]q,"state":"active","base_url":"%[2]s/solr","leader":"true"}}}}}}}}`, node, server.URL)
+		case r.URL.Path == "/solr/admin/metrics" && q.Get("group") == "node":
+			fmt.Fprint(w, `{"metrics":{"solr.node":{"CONTAINER.fs.usableSpace":500,"CONTAINER.fs.totalSpace":1000}}}`)
+		case r.URL.Path == "/solr/admin/metrics":
+			fmt.Fprint(w, `{"metrics":{"solr.jvm":{"memory.heap.usage":0.5}}}`)
+		case r.URL.Path == "/solr/admin/info/logging":
+			fmt.Fprint(w, `{"history":{"docs":[]}}`)
+		case r.URL.Path == "/solr/logs_shard1_replica_n1/admin/luke":
+			fmt.Fprint(w, `{"index":{"numDocs":60,"maxDoc":100,"deletedDocs":40}}`)
+		case r.URL.Path == "/solr/logs_shard1_replica_n1/select":
+			fmt.Fprint(w, `{"response":{"numFound":60,"docs":[{"_version_":1790000000000000005}]}}`)
+		case r.URL.Path == "/solr/logs/update":
+			body, _ := io.ReadAll(r.Body)
+			updates = append(updates, string(body))
+			fmt.Fprint(w, `{}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	events := make(chan notify.Event, 1)
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var ev notify.Event
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&ev))
+		events <- ev
+	}))
+	defer webhook.Close()
+
+	st := NewServer(WithSolrURL(server.URL), WithHTTPClient(&http.Client{}))
+	st.Config = &config.FileConfig{
+		Notifier:    config.NotifierConfig{WebhookURL: webhook.URL},
+		Maintenance: config.MaintenanceConfig{Window: "02:00-04:00", Backup: config.MaintenanceBackupConfig{Location: "/backups"}},
+	}
+	ctx := context.Background()
+
+	// Goal: A collection over the threshold is merged and backed up between two GREEN health checks.
+	run := st.maintenanceManager().Execute(ctx, "schedule")
+	assert.Equal(t, []string{`{"commit":{"expungeDeletes":true}}`}, updates)
+	assert.Equal(t, []string{"logs /backups"}, backups)
+	assert.Equal(t, maintenance.HealthGreen, run.Before.Status)
+	assert.Equal(t, maintenance.HealthGreen, run.After.Status)
+	assert.Zero(t, run.Failed())
+
+	// Goal: The summary is pushed to the notifier.
+	ev := <-events
+	assert.Equal(t, "maintenance", ev.Type)
+	assert.Contains(t, ev.Summary, "1 merges, 1 backups; health GREEN before, GREEN after")
+
+	// Goal: solr.maintenance.status shows the next window and the recent runs.
+	session, _, _ := connectTestClient(t, st.NewMCPServer())
+	res, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "solr.maintenance.status", Arguments: map[string]any{}})
+	require.NoError(t, err)
+	require.False(t, res.IsError)
+	out := res.StructuredContent.(map[string]any)
+	assert.NotEmpty(t, out["nextWindow"])
+	assert.Len(t, out["history"], 1)
+}
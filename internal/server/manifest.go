@@ -0,0 +1,152 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/config"
+	"github.com/Sashimimochi/solr-mcp-go/internal/opensearch"
+)
+
+// manifestFile is the name of the manifest written to DataDir.
+const manifestFile = "manifest.json"
+
+// Manifest is a machine-readable description of a running server, for fleet management and client documentation.
+type Manifest struct {
+	Name         string            `json:"name"`
+	Version      string            `json:"version"`
+	GeneratedAt  time.Time         `json:"generatedAt"`
+	Backend      string            `json:"backend"`
+	SolrURL      string            `json:"solrUrl"`
+	Tools        []ManifestTool    `json:"tools"`
+	Capabilities []Capability      `json:"capabilities"`
+	Guardrails   ManifestGuardrail `json:"guardrails"`
+}
+
+// ManifestTool is a registered tool and its input schema.
+type ManifestTool struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	InputSchema any    `json:"inputSchema"`
+}
+
+// ManifestGuardrail lists the limits and safety switches in effect.
+type ManifestGuardrail struct {
+	MaxRequestBytes  int                      `json:"maxRequestBytes"`
+	MaxToolArgsBytes int                      `json:"maxToolArgsBytes"`
+	ConfirmationTTL  string                   `json:"confirmationTtl"`
+	SchemaCacheTTL   string                   `json:"schemaCacheTtl"`
+	ReadOnly         bool                     `json:"readOnly"`
+	StandbyWrites    bool                     `json:"standbyWrites"`
+	DisabledTools    []string                 `json:"disabledTools,omitempty"`
+	ToolArgsLimits   map[string]int           `json:"toolArgsLimits,omitempty"`
+	QueryLimits      config.QueryLimitsConfig `json:"queryLimits"`
+	ChaosRate        float64                  `json:"chaosRate,omitempty"`
+}
+
+// Manifest describes the enabled tools, capabilities and guardrails.
+func (st *State) Manifest() Manifest {
+	fc := st.fileConfig()
+	m := Manifest{
+		Name:         "solr-mcp-go",
+		Version:      config.Version,
+		GeneratedAt:  time.Now().UTC(),
+		Backend:      st.backendName(),
+		SolrURL:      config.RedactURL(st.BaseURL),
+		Tools:        []ManifestTool{},
+		Capabilities: st.Capabilities(),
+		Guardrails: ManifestGuardrail{
+			MaxRequestBytes:  st.MaxRequestBytes,
+			MaxToolArgsBytes: st.MaxToolArgsBytes,
+			ConfirmationTTL:  st.ConfirmationTTL.String(),
+			SchemaCacheTTL:   st.SchemaCache.TTL.String(),
+			ReadOnly:         st.readOnly.Load(),
+			StandbyWrites:    st.StandbyWrites,
+			DisabledTools:    fc.DisabledTools,
+			ToolArgsLimits:   fc.ToolArgsLimits,
+			QueryLimits:      fc.QueryLimits,
+			ChaosRate:        st.Chaos.Rate,
+		},
+	}
+
+	st.toolMu.Lock()
+	defer st.toolMu.Unlock()
+	for _, name := range st.toolOrder {
+		if t := st.toolDefs[name]; t != nil && st.enabledTools[name] {
+			m.Tools = append(m.Tools, ManifestTool{Name: t.Name, Description: t.Description, InputSchema: t.InputSchema})
+		}
+	}
+	return m
+}
+
+func (st *State) backendName() string {
+	switch st.Backend.(type) {
+	case nil:
+		return "solr"
+	case *opensearch.Backend:
+		return "opensearch"
+	default:
+		return "custom"
+	}
+}
+
+// WriteManifest writes the manifest to manifest.json in dir and returns its path.
+// The file is replaced atomically so readers never see a partial manifest.
+func (st *State) WriteManifest(dir string) (string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("create data dir: %v", err)
+	}
+	data, err := json.MarshalIndent(st.Manifest(), "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("encode manifest: %v", err)
+	}
+	path := filepath.Join(dir, manifestFile)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0o644); err != nil {
+		return "", fmt.Errorf("write manifest: %v", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return "", fmt.Errorf("write manifest: %v", err)
+	}
+	return path, nil
+}
+
+// saveManifest writes the manifest to DataDir, if set, logging failures.
+func (st *State) saveManifest() {
+	if st.DataDir == "" {
+		return
+	}
+	path, err := st.WriteManifest(st.DataDir)
+	if err != nil {
+		slog.Error("Failed to write manifest", "error", err)
+		return
+	}
+	slog.Debug("Manifest written", "path", path)
+}
+
+// LogBanner logs a one-line summary of the server configuration at startup.
+func (st *State) LogBanner(address string) {
+	m := st.Manifest()
+	var caps []string
+	for _, c := range m.Capabilities {
+		if c.Enabled {
+			caps = append(caps, c.Name)
+		}
+	}
+	slog.Info("solr-mcp-go started",
+		"version", m.Version,
+		"address", address,
+		"backend", m.Backend,
+		"solr", m.SolrURL,
+		"tools", len(m.Tools),
+		"capabilities", strings.Join(caps, ","),
+		"readOnly", m.Guardrails.ReadOnly,
+		"maxRequestBytes", m.Guardrails.MaxRequestBytes,
+		"maxToolArgsBytes", m.Guardrails.MaxToolArgsBytes,
+	)
+}
@@ -0,0 +1,22 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/solr"
+)
+
+// annotateMatches adds matchedOn to the documents of a solr.query response. The debug section
+// requested for it is removed unless the caller asked for debug output themselves.
+func (st *State) annotateMatches(ctx context.Context, collection string, resp map[string]any, removeDebug bool) {
+	if removeDebug {
+		defer delete(resp, "debug")
+	}
+	fc, err := st.backend().Schema(ctx, collection)
+	if err != nil || fc.UniqueKey == "" {
+		slog.Debug("Cannot annotate matches without a uniqueKey", "collection", collection, "error", err)
+		return
+	}
+	solr.AnnotateMatches(resp, fc.UniqueKey)
+}
@@ -0,0 +1,111 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/objectstore"
+)
+
+// objectStore returns the configured object store name. Stores are read from the current config, so stores
+// added by a reload are used right away.
+func (st *State) objectStore(name string) (*objectstore.Store, error) {
+	stores := st.fileConfig().ObjectStores
+	oc, ok := stores[name]
+	if !ok {
+		if len(stores) == 0 {
+			return nil, fmt.Errorf("input.destination: no object stores are configured (objectStores in the config file)")
+		}
+		names := make([]string, 0, len(stores))
+		for n := range stores {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("input.destination: unknown object store %s (configured: %s)", name, strings.Join(names, ", "))
+	}
+	return &objectstore.Store{Name: name, Config: oc, Client: st.HttpClient}, nil
+}
+
+// sourceStore returns the configured store of an s3:// or gs:// URI, the first by name of its type and bucket, and
+// the object key.
+func (st *State) sourceStore(uri string) (*objectstore.Store, string, error) {
+	storeType, bucket, key, err := objectstore.ParseURI(uri)
+	if err != nil {
+		return nil, "", err
+	}
+	stores := st.fileConfig().ObjectStores
+	names := make([]string, 0, len(stores))
+	for n := range stores {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if oc := stores[name]; oc.Type == storeType && oc.Bucket == bucket {
+			return &objectstore.Store{Name: name, Config: oc, Client: st.HttpClient}, key, nil
+		}
+	}
+	return nil, "", fmt.Errorf("no %s object store is configured for bucket %s (objectStores in the config file)", storeType, bucket)
+}
+
+// uploadResult writes v as JSON to a temporary file and uploads it to the object store name as key.
+func (st *State) uploadResult(ctx context.Context, name, key string, v any) (objectstore.Object, error) {
+	store, err := st.objectStore(name)
+	if err != nil {
+		return objectstore.Object{}, err
+	}
+	f, err := os.CreateTemp("", "solr-mcp-result-*.json")
+	if err != nil {
+		return objectstore.Object{}, fmt.Errorf("create result file: %v", err)
+	}
+	defer os.Remove(f.Name())
+	err = json.NewEncoder(f).Encode(v)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return objectstore.Object{}, fmt.Errorf("write result file: %v", err)
+	}
+	return store.Upload(ctx, key, f.Name(), "application/json")
+}
+
+// uploadQueryResult uploads a solr.query result after post-processing and returns a summary in its place:
+// the object, the match count and the parts of the result that explain it, such as guardrails and warnings.
+func (st *State) uploadQueryResult(ctx context.Context, collection, destination string, resp map[string]any) (map[string]any, error) {
+	var out any = resp
+	r, err := st.postProcessors().Process(ctx, "solr.query", resp)
+	if err != nil {
+		return nil, err
+	}
+	if r != nil {
+		out = r.Output
+	}
+	obj, err := st.uploadResult(ctx, destination, resultKey("query", collection, time.Now()), out)
+	if err != nil {
+		return nil, err
+	}
+	slog.Info("Query result uploaded", "collection", collection, "store", obj.Store, "key", obj.Key, "bytes", obj.Bytes)
+	summary := map[string]any{"object": obj}
+	if body, ok := resp["response"].(map[string]any); ok {
+		summary["numFound"] = body["numFound"]
+		if docs, ok := body["docs"].([]any); ok {
+			summary["docs"] = len(docs)
+		}
+	}
+	for _, k := range []string{"guardrails", "warnings", "limits", "expanded", "corrections", "slowQuery", "possibleCauses"} {
+		if v, ok := resp[k]; ok {
+			summary[k] = v
+		}
+	}
+	return summary, nil
+}
+
+// resultKey returns the object key of a result of collection, e.g. "query/products-20240102T150405.123Z.json".
+func resultKey(kind, collection string, now time.Time) string {
+	return kind + "/" + collection + "-" + now.UTC().Format("20060102T150405.000Z") + ".json"
+}
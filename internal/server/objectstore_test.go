@@ -0,0 +1,167 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/config"
+	"github.com/Sashimimochi/solr-mcp-go/internal/jobs"
+	"github.com/Sashimimochi/solr-mcp-go/internal/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestObjectStoreDestination tests uploading query results and exports to an object store.
+func TestObjectStoreDestination(t *testing.T) {
+	var mu sync.Mutex
+	uploads := map[string]string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPut && strings.HasPrefix(r.URL.Path, "/bucket/"):
+			if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 ") {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			body, _ := io.ReadAll(r.Body)
+			mu.Lock()
+			uploads[strings.TrimPrefix(r.URL.Path, "/bucket/")] = string(body)
+			mu.Unlock()
+		case r.URL.Path == "/solr/products/schema/uniquekey":
+			fmt.Fprintln(w, `{"uniqueKey":"id"}`)
+		case r.URL.Path == "/solr/products/schema/fields":
+			fmt.Fprintln(w, `{"fields":[]}`)
+		case r.URL.Path == "/solr/products/select":
+			switch r.URL.Query().Get("cursorMark") {
+			case "":
+				fmt.Fprintln(w, `{"response":{"numFound":2,"docs":[{"id":"1"},{"id":"2"}]}}`)
+			case "*":
+				fmt.Fprintln(w, `{"response":{"docs":[{"id":"1"},{"id":"2"}]},"nextCursorMark":"c2"}`)
+			default:
+				fmt.Fprintln(w, `{"response":{"docs":[]},"nextCursorMark":"c2"}`)
+			}
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKID")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	ctx := context.Background()
+	st := NewServer(WithSolrURL(server.URL), WithHTTPClient(&http.Client{}), WithDataDir(t.TempDir()))
+	st.Config = &config.FileConfig{ObjectStores: map[string]config.ObjectStoreConfig{
+		"reports": {Type: config.ObjectStoreS3, Bucket: "bucket", Prefix: "solr/", Endpoint: server.URL},
+	}}
+
+	// Goal: A query result is uploaded in full, and the tool returns the object instead of the documents.
+	_, out, err := st.toolQuery(ctx, nil, types.QueryIn{Collection: "products", Destination: "reports"})
+	require.NoError(t, err)
+	summary := out.(map[string]any)
+	assert.NotContains(t, summary, "response")
+	assert.Equal(t, float64(2), summary["numFound"])
+	assert.Equal(t, 2, summary["docs"])
+	data, _ := json.Marshal(summary["object"])
+	var obj map[string]any
+	require.NoError(t, json.Unmarshal(data, &obj))
+	assert.Equal(t, "reports", obj["store"])
+	assert.True(t, strings.HasPrefix(obj["key"].(string), "solr/query/products-"))
+	assert.Len(t, obj["sha256"], 64)
+	assert.Contains(t, uploads[obj["key"].(string)], `"docs":[{"id":"1"},{"id":"2"}]`)
+
+	// Goal: Exports are uploaded when the job is done, and the job reports the object.
+	_, out, err = st.toolExport(ctx, nil, types.ExportIn{Collection: "products", Destination: "reports"})
+	require.NoError(t, err)
+	job := out.(jobs.Job)
+	require.Eventually(t, func() bool {
+		_, out, _ := st.toolJobs(ctx, nil, types.JobsIn{ID: job.ID})
+		job = out.(jobs.Job)
+		return job.Status == jobs.Done
+	}, 5*time.Second, 10*time.Millisecond)
+	require.NotNil(t, job.Object)
+	assert.Equal(t, "solr/exports/"+job.ID+".jsonl", job.Object.Key)
+	mu.Lock()
+	assert.Equal(t, "{\"id\":\"1\"}\n{\"id\":\"2\"}\n", uploads[job.Object.Key])
+	mu.Unlock()
+	_, err = os.Stat(job.Target)
+	assert.True(t, os.IsNotExist(err), "the local export file is removed after the upload")
+
+	// Goal: Unknown destinations are rejected before running anything.
+	_, _, err = st.toolQuery(ctx, nil, types.QueryIn{Collection: "products", Destination: "missing"})
+	assert.ErrorContains(t, err, "unknown object store missing (configured: reports)")
+	_, _, err = st.toolExport(ctx, nil, types.ExportIn{Collection: "products", Destination: "missing"})
+	assert.ErrorContains(t, err, "unknown object store missing")
+}
+
+// TestToolImport tests importing JSON lines from an object store in batches.
+func TestToolImport(t *testing.T) {
+	const object = "{\"id\":\"1\",\"price\":9007199254740993}\n\n{\"id\":\"2\",\"_version_\":5}\n{\"id\":\"3\"}"
+	var mu sync.Mutex
+	var ranges, batches []string
+	commits := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case r.URL.Path == "/data/exports/products.jsonl":
+			ranges = append(ranges, r.Header.Get("Range"))
+			http.ServeContent(w, r, "products.jsonl", time.Time{}, strings.NewReader(object))
+		case r.URL.Path == "/solr/products/schema/uniquekey":
+			fmt.Fprintln(w, `{"uniqueKey":"id"}`)
+		case r.URL.Path == "/solr/products/schema/fields":
+			fmt.Fprintln(w, `{"fields":[{"name":"_version_","type":"plong"}]}`)
+		case r.URL.Path == "/solr/products/schema/copyfields":
+			fmt.Fprintln(w, `{"copyFields":[]}`)
+		case r.URL.Path == "/solr/products/update":
+			if r.URL.Query().Get("commit") == "true" {
+				commits++
+			} else {
+				body, _ := io.ReadAll(r.Body)
+				batches = append(batches, string(body))
+			}
+			fmt.Fprintln(w, `{"responseHeader":{"status":0}}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	t.Setenv("GCS_ACCESS_KEY_ID", "GOOG")
+	t.Setenv("GCS_SECRET_ACCESS_KEY", "secret")
+	ctx := context.Background()
+	st := NewServer(WithSolrURL(server.URL), WithHTTPClient(&http.Client{}), WithDataDir(t.TempDir()))
+	st.Config = &config.FileConfig{ObjectStores: map[string]config.ObjectStoreConfig{
+		"lake": {Type: config.ObjectStoreGCS, Bucket: "data", Endpoint: server.URL},
+	}}
+
+	// Goal: The object is read in batches by byte offset and indexed without _version_, keeping long values exact.
+	_, out, err := st.toolImport(ctx, nil, types.ImportIn{Collection: "products", Source: "gs://data/exports/products.jsonl", BatchSize: 2})
+	require.NoError(t, err)
+	job := out.(jobs.Job)
+	require.Eventually(t, func() bool {
+		_, out, _ := st.toolJobs(ctx, nil, types.JobsIn{ID: job.ID})
+		job = out.(jobs.Job)
+		return job.Status == jobs.Done
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, int64(3), job.Processed)
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, batches, 2)
+	assert.JSONEq(t, `[{"id":"1","price":9007199254740993},{"id":"2"}]`, batches[0])
+	assert.JSONEq(t, `[{"id":"3"}]`, batches[1])
+	assert.Equal(t, 1, commits)
+	assert.Equal(t, []string{"", "bytes=62-", "bytes=72-"}, ranges)
+
+	// Goal: Sources without a configured store are rejected before starting a job.
+	_, _, err = st.toolImport(ctx, nil, types.ImportIn{Collection: "products", Source: "s3://data/a.jsonl"})
+	assert.ErrorContains(t, err, "no s3 object store is configured for bucket data")
+	_, _, err = st.toolImport(ctx, nil, types.ImportIn{Collection: "products", Source: "/tmp/a.jsonl"})
+	assert.ErrorContains(t, err, "not an s3:// or gs:// URI")
+}
@@ -0,0 +1,321 @@
+package server
+
+import (
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/backend"
+	"github.com/Sashimimochi/solr-mcp-go/internal/chaos"
+	"github.com/Sashimimochi/solr-mcp-go/internal/config"
+	"github.com/Sashimimochi/solr-mcp-go/internal/errcatalog"
+	"github.com/Sashimimochi/solr-mcp-go/internal/governor"
+	"github.com/Sashimimochi/solr-mcp-go/internal/opensearch"
+	"github.com/Sashimimochi/solr-mcp-go/internal/replay"
+	"github.com/Sashimimochi/solr-mcp-go/internal/solr"
+	"github.com/Sashimimochi/solr-mcp-go/internal/types"
+)
+
+// Option configures a State created by NewServer.
+type Option func(*State)
+
+// WithSolrURL sets the Solr base URL (default: http://localhost:8983).
+func WithSolrURL(baseURL string) Option {
+	return func(st *State) {
+		st.BaseURL = strings.TrimRight(baseURL, "/")
+	}
+}
+
+// WithBasicAuth sets the credentials sent to Solr.
+func WithBasicAuth(user, pass string) Option {
+	return func(st *State) {
+		st.BasicUser = user
+		st.BasicPass = pass
+	}
+}
+
+// WithHTTPClient sets the HTTP client used for Solr requests (default: a client with a 30s timeout).
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(st *State) {
+		st.HttpClient = httpClient
+	}
+}
+
+// WithDefaultCollection sets the collection used when a prompt or resource does not name one (default: gettingstarted).
+func WithDefaultCollection(name string) Option {
+	return func(st *State) {
+		st.DefaultCollection = name
+	}
+}
+
+// WithSchemaCacheTTL sets how long collection schemas are cached (default: 10 minutes).
+func WithSchemaCacheTTL(ttl time.Duration) Option {
+	return func(st *State) {
+		st.SchemaCache.TTL = ttl
+	}
+}
+
+// WithFieldStatsTTL sets how long field statistics such as distinct value estimates are cached (default: 30 minutes).
+func WithFieldStatsTTL(ttl time.Duration) Option {
+	return func(st *State) {
+		st.SchemaCache.StatsTTL = ttl
+	}
+}
+
+// WithConfig sets the file configuration. path is watched for changes by Run when not empty.
+func WithConfig(fc *config.FileConfig, path string) Option {
+	return func(st *State) {
+		st.Config = fc
+		st.ConfigPath = path
+	}
+}
+
+// WithToolFilter only registers the tools for which allow returns true, in addition to disabledTools of the config file.
+func WithToolFilter(allow func(name string) bool) Option {
+	return func(st *State) {
+		st.addToolFilter(allow)
+	}
+}
+
+// WithRequestLimits sets the maximum size of HTTP request bodies and of the arguments of a single tool call.
+// A limit of 0 disables the check.
+func WithRequestLimits(maxRequestBytes, maxToolArgsBytes int) Option {
+	return func(st *State) {
+		st.MaxRequestBytes = maxRequestBytes
+		st.MaxToolArgsBytes = maxToolArgsBytes
+	}
+}
+
+// WithConfirmationTTL sets how long confirmation tokens of destructive tools stay valid (default: 5 minutes).
+func WithConfirmationTTL(ttl time.Duration) Option {
+	return func(st *State) {
+		st.ConfirmationTTL = ttl
+	}
+}
+
+// WithStatsFile persists tool usage statistics to path, so that failure patterns are reported at the next startup.
+func WithStatsFile(path string) Option {
+	return func(st *State) {
+		st.StatsFile = path
+	}
+}
+
+// WithDataDir sets the directory receiving the manifest of the server.
+func WithDataDir(dir string) Option {
+	return func(st *State) {
+		st.DataDir = dir
+	}
+}
+
+// WithAdmin serves the operator control API on addr. Requests must carry token as a bearer credential.
+func WithAdmin(addr, token string) Option {
+	return func(st *State) {
+		st.AdminAddr = addr
+		st.AdminToken = token
+	}
+}
+
+// WithStandby sets a warm standby Solr cluster that the tools fail over to when the primary is unavailable.
+// Write tools refuse to run against the standby unless allowWrites is set.
+func WithStandby(baseURL string, allowWrites bool) Option {
+	return func(st *State) {
+		st.StandbyURL = strings.TrimRight(baseURL, "/")
+		st.StandbyWrites = allowWrites
+	}
+}
+
+// WithNodes spreads solr.query requests over further nodes of the primary cluster besides its base URL,
+// preferring the fastest healthy node. With discover set, the live nodes of the cluster are added periodically.
+func WithNodes(urls []string, discover bool) Option {
+	return func(st *State) {
+		st.Nodes = nil
+		for _, u := range urls {
+			if u = strings.TrimRight(strings.TrimSpace(u), "/"); u != "" {
+				st.Nodes = append(st.Nodes, u)
+			}
+		}
+		st.DiscoverNodes = discover
+	}
+}
+
+// WithBackend replaces the Solr HTTP backend, e.g. with a mock in tests or an adapter for another engine.
+func WithBackend(b backend.SearchBackend) Option {
+	return func(st *State) {
+		st.Backend = b
+	}
+}
+
+// WithOpenSearch serves the tools from an OpenSearch or Elasticsearch cluster instead of Solr (experimental).
+// Tools relying on Solr-specific APIs are not registered, in addition to those of other tool filters. The backend
+// is created by NewServer, so recording, replay and chaos mode apply to httpClient as they do to Solr's.
+func WithOpenSearch(baseURL, user, pass string, httpClient *http.Client) Option {
+	return func(st *State) {
+		st.openSearch = &openSearchOptions{baseURL: baseURL, user: user, pass: pass, httpClient: httpClient}
+		st.addToolFilter(func(name string) bool { return !solrOnlyTools[name] })
+	}
+}
+
+// openSearchOptions are the connection settings of an OpenSearch backend.
+type openSearchOptions struct {
+	baseURL, user, pass string
+	httpClient          *http.Client
+}
+
+// addToolFilter adds allow to the tool filter, so a tool is only registered if every filter allows it.
+func (st *State) addToolFilter(allow func(name string) bool) {
+	prev := st.ToolFilter
+	if prev == nil {
+		st.ToolFilter = allow
+		return
+	}
+	st.ToolFilter = func(name string) bool { return prev(name) && allow(name) }
+}
+
+// solrOnlyTools are the tools that need SolrCloud APIs the OpenSearch backend cannot provide.
+var solrOnlyTools = map[string]bool{
+	"solr.query.shards":      true,
+	"solr.consistency.check": true,
+	"solr.healthcheck.full":  true,
+	"solr.dedupe.find":       true,
+	"solr.profile":           true,
+	"solr.drift.report":      true,
+	"solr.archive":           true,
+	"solr.archive.restore":   true,
+	"solr.elevation.list":    true,
+	"solr.elevation.set":     true,
+	"solr.ltr.list":          true,
+	"solr.ltr.upload":        true,
+	"solr.params.list":       true,
+	"solr.params.set":        true,
+	"solr.export":            true,
+	"solr.reindex":           true,
+	"solr.import":            true,
+	"solr.permissions":       true,
+}
+
+// WithRecording records the Solr requests of the server and their responses to the fixture file at path.
+func WithRecording(path string) Option {
+	return func(st *State) {
+		st.RecordFile = path
+	}
+}
+
+// WithReplay answers the Solr requests of the server from a recorded fixture instead of a cluster.
+func WithReplay(f *replay.Fixture) Option {
+	return func(st *State) {
+		st.replay = f
+	}
+}
+
+// WithChaos fails a random share of Solr requests with timeouts, 503 responses and malformed bodies, so
+// agents built on the server can test their retry and fallback behavior. Never use it against a production cluster.
+func WithChaos(c chaos.Config) Option {
+	return func(st *State) {
+		st.Chaos = c
+	}
+}
+
+// WithLanguage sets the language of the messages and hints of tool errors.
+func WithLanguage(lang errcatalog.Lang) Option {
+	return func(st *State) {
+		st.Language = lang
+	}
+}
+
+// WithLogger sets the logger. The server logs through the slog default logger, so this replaces it process-wide.
+func WithLogger(logger *slog.Logger) Option {
+	return func(*State) {
+		slog.SetDefault(logger)
+	}
+}
+
+// NewServer creates a State from explicit options, without reading environment variables.
+func NewServer(opts ...Option) *State {
+	st := &State{
+		BaseURL:           "http://localhost:8983",
+		DefaultCollection: "gettingstarted",
+		HttpClient:        &http.Client{Timeout: 30 * time.Second},
+		SchemaCache: types.SchemaCache{
+			LastFetch: make(map[string]time.Time),
+			TTL:       10 * time.Minute,
+			ByCol:     make(map[string]*types.FieldCatalog),
+			StatsTTL:  30 * time.Minute,
+		},
+		Config:                &config.FileConfig{},
+		ConfigReloadInterval:  10 * time.Second,
+		MaxRequestBytes:       10 << 20,
+		MaxToolArgsBytes:      1 << 20,
+		ConfirmationTTL:       defaultConfirmationTTL,
+		FailoverThreshold:     3,
+		FailoverProbeInterval: 30 * time.Second,
+	}
+	for _, opt := range opts {
+		opt(st)
+	}
+	var recorder *replay.Recorder
+	if st.replay != nil {
+		slog.Warn("Replay mode enabled, Solr requests are answered from a fixture", "interactions", len(st.replay.Interactions))
+	} else if st.RecordFile != "" {
+		recorder = replay.NewRecorder(st.RecordFile, st.HttpClient.Transport)
+		slog.Warn("Recording Solr requests", "path", st.RecordFile)
+	}
+	if st.Chaos.Rate > 0 {
+		slog.Warn("Chaos mode enabled, Solr requests fail at random", "rate", st.Chaos.Rate, "faults", st.Chaos.Faults)
+	}
+	st.HttpClient = st.wrapClient(st.HttpClient, recorder, false)
+	if o := st.openSearch; o != nil {
+		hc := o.httpClient
+		if hc == nil {
+			hc = &http.Client{Timeout: 30 * time.Second}
+		}
+		st.Backend = opensearch.New(o.baseURL, o.user, o.pass, st.wrapClient(hc, recorder, true))
+	}
+	if st.StandbyURL != "" && st.Backend == nil {
+		c := *st.HttpClient
+		c.Transport = &failoverTransport{st: st, base: c.Transport}
+		st.HttpClient = &c
+	}
+	return st
+}
+
+// wrapClient applies replay or recording, then chaos mode, to a copy of hc, and counts the response bytes of tool
+// calls when a memory budget is set. A client dedicated to a backend other than Solr has faults injected into all
+// of its requests.
+func (st *State) wrapClient(hc *http.Client, recorder *replay.Recorder, dedicated bool) *http.Client {
+	if st.replay != nil {
+		hc = replay.PlayingClient(hc, st.replay)
+	} else if recorder != nil {
+		c := *hc
+		c.Transport = recorder.Transport(hc.Transport)
+		hc = &c
+	}
+	if st.Chaos.Rate > 0 {
+		c := st.Chaos
+		c.AllRequests = dedicated
+		hc = chaos.Wrap(hc, c)
+	}
+	if st.fileConfig().MemoryBudget.MaxBytes > 0 {
+		hc = governor.Wrap(hc)
+	}
+	return hc
+}
+
+// backend returns Backend, or the Solr HTTP backend for the connection settings of st.
+func (st *State) backend() backend.SearchBackend {
+	if st.Backend != nil {
+		return st.Backend
+	}
+	return &solr.HTTPBackend{
+		HttpClient: st.HttpClient,
+		BaseURL:    st.solrURL(),
+		User:       st.BasicUser,
+		Pass:       st.BasicPass,
+		Cache:      &st.SchemaCache,
+	}
+}
+
+// toolDisabled reports whether a tool is disabled by the config file or the tool filter.
+func (st *State) toolDisabled(name string) bool {
+	return st.fileConfig().ToolDisabled(name) || (st.ToolFilter != nil && !st.ToolFilter(name))
+}
@@ -0,0 +1,88 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/solr"
+	"github.com/Sashimimochi/solr-mcp-go/internal/types"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func (st *State) toolParamsList(ctx context.Context, _ *mcp.CallToolRequest, in types.ParamsListIn) (*mcp.CallToolResult, any, error) {
+	if strings.TrimSpace(in.Collection) == "" {
+		return nil, nil, errors.New("input.collection is required")
+	}
+	sets, err := solr.ListParamSets(ctx, st.HttpClient, st.solrURL(), st.BasicUser, st.BasicPass, in.Collection)
+	if err != nil {
+		return nil, nil, err
+	}
+	if in.Name != "" {
+		for _, ps := range sets {
+			if ps.Name == in.Name {
+				return nil, map[string]any{"collection": in.Collection, "paramSet": ps}, nil
+			}
+		}
+		return nil, nil, fmt.Errorf("parameter set %s not found in collection %s", in.Name, in.Collection)
+	}
+	return nil, map[string]any{"collection": in.Collection, "paramSets": sets}, nil
+}
+
+func (st *State) toolParamsSet(ctx context.Context, _ *mcp.CallToolRequest, in types.ParamsSetIn) (*mcp.CallToolResult, any, error) {
+	if strings.TrimSpace(in.Collection) == "" {
+		return nil, nil, errors.New("input.collection is required")
+	}
+	// useParams takes a comma-separated list of names
+	if strings.TrimSpace(in.Name) == "" || strings.ContainsAny(in.Name, ", ") {
+		return nil, nil, errors.New("input.name is required and must not contain commas or spaces")
+	}
+	if in.Delete {
+		if err := solr.DeleteParamSet(ctx, st.HttpClient, st.solrURL(), st.BasicUser, st.BasicPass, in.Collection, in.Name); err != nil {
+			return nil, nil, fmt.Errorf("delete parameter set %s: %v", in.Name, err)
+		}
+		slog.Info("Parameter set deleted", "collection", in.Collection, "name", in.Name)
+		return nil, map[string]any{"collection": in.Collection, "name": in.Name, "deleted": true}, nil
+	}
+	if len(in.Params) == 0 {
+		return nil, nil, errors.New("input.params is required unless input.delete is set")
+	}
+	for k, v := range in.Params {
+		if err := checkParamValue(v); err != nil {
+			return nil, nil, fmt.Errorf("input.params.%s: %v", k, err)
+		}
+	}
+	if err := solr.SetParamSet(ctx, st.HttpClient, st.solrURL(), st.BasicUser, st.BasicPass, in.Collection, in.Name, in.Params, in.Merge); err != nil {
+		return nil, nil, fmt.Errorf("save parameter set %s: %v", in.Name, err)
+	}
+	slog.Info("Parameter set saved", "collection", in.Collection, "name", in.Name, "params", len(in.Params), "merge", in.Merge)
+	return nil, map[string]any{
+		"collection": in.Collection,
+		"name":       in.Name,
+		"params":     in.Params,
+		"merged":     in.Merge,
+		"usage":      fmt.Sprintf("pass useParams: [%q] to solr.query", in.Name),
+	}, nil
+}
+
+// checkParamValue accepts the values Solr stores in a parameter set: scalars and lists of scalars.
+func checkParamValue(v any) error {
+	switch v := v.(type) {
+	case string, float64, bool:
+		return nil
+	case []any:
+		for _, item := range v {
+			if _, ok := item.([]any); ok {
+				return errors.New("nested lists are not supported")
+			}
+			if err := checkParamValue(item); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("must be a string, number, boolean or a list of them, got %T", v)
+}
@@ -0,0 +1,77 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/solr"
+	"github.com/Sashimimochi/solr-mcp-go/internal/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestToolParams tests listing, saving and using request parameter sets.
+func TestToolParams(t *testing.T) {
+	var bodies []string
+	var useParams string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/solr/products/config/params":
+			fmt.Fprint(w, `{"response":{"znodeVersion":3,"params":{
+				"search":{"defType":"edismax","qf":"title^2 body","":{"v":2}},
+				"facets":{"facet":"true","facet.field":["brand","color"],"":{"v":0}}}}}`)
+		case r.Method == http.MethodPost && r.URL.Path == "/solr/products/config/params":
+			body, _ := io.ReadAll(r.Body)
+			bodies = append(bodies, string(body))
+			fmt.Fprint(w, `{"responseHeader":{"status":0}}`)
+		case r.URL.Path == "/solr/products/select":
+			useParams = r.URL.Query().Get("useParams")
+			fmt.Fprint(w, `{"response":{"numFound":0,"docs":[]}}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	st := newTestState(t, server.URL)
+	ctx := context.Background()
+
+	// Goal: Parameter sets are listed by name without their metadata, with their version.
+	_, out, err := st.toolParamsList(ctx, nil, types.ParamsListIn{Collection: "products"})
+	require.NoError(t, err)
+	sets := out.(map[string]any)["paramSets"].([]solr.ParamSet)
+	require.Len(t, sets, 2)
+	assert.Equal(t, "facets", sets[0].Name)
+	assert.Equal(t, solr.ParamSet{Name: "search", Params: map[string]any{"defType": "edismax", "qf": "title^2 body"}, Version: 2}, sets[1])
+	_, out, err = st.toolParamsList(ctx, nil, types.ParamsListIn{Collection: "products", Name: "search"})
+	require.NoError(t, err)
+	assert.Equal(t, "search", out.(map[string]any)["paramSet"].(solr.ParamSet).Name)
+	_, _, err = st.toolParamsList(ctx, nil, types.ParamsListIn{Collection: "products", Name: "missing"})
+	assert.ErrorContains(t, err, "parameter set missing not found")
+
+	// Goal: Sets are created, merged and deleted with the Request Parameters API commands.
+	_, _, err = st.toolParamsSet(ctx, nil, types.ParamsSetIn{Collection: "products", Name: "search", Params: map[string]any{"rows": float64(5)}})
+	require.NoError(t, err)
+	_, _, err = st.toolParamsSet(ctx, nil, types.ParamsSetIn{Collection: "products", Name: "search", Params: map[string]any{"fl": []any{"id", "title"}}, Merge: true})
+	require.NoError(t, err)
+	_, _, err = st.toolParamsSet(ctx, nil, types.ParamsSetIn{Collection: "products", Name: "facets", Delete: true})
+	require.NoError(t, err)
+	assert.Equal(t, []string{`{"set":{"search":{"rows":5}}}`, `{"update":{"search":{"fl":["id","title"]}}}`, `{"delete":"facets"}`}, bodies)
+
+	// Goal: Queries reference sets by name.
+	_, _, err = st.toolQuery(ctx, nil, types.QueryIn{Collection: "products", UseParams: []string{"search", "facets"}})
+	require.NoError(t, err)
+	assert.Equal(t, "search,facets", useParams)
+
+	// Goal: Invalid input is rejected.
+	_, _, err = st.toolParamsSet(ctx, nil, types.ParamsSetIn{Collection: "products", Name: "a,b", Params: map[string]any{"rows": "5"}})
+	assert.ErrorContains(t, err, "input.name")
+	_, _, err = st.toolParamsSet(ctx, nil, types.ParamsSetIn{Collection: "products", Name: "search"})
+	assert.ErrorContains(t, err, "input.params is required")
+	_, _, err = st.toolParamsSet(ctx, nil, types.ParamsSetIn{Collection: "products", Name: "search", Params: map[string]any{"qf": map[string]any{"a": 1}}})
+	assert.ErrorContains(t, err, "input.params.qf")
+}
@@ -0,0 +1,154 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/solr"
+	"github.com/Sashimimochi/solr-mcp-go/internal/types"
+	"github.com/Sashimimochi/solr-mcp-go/internal/utils"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// toolPermissions are the Solr permissions each tool needs on its collection or the cluster.
+var toolPermissions = map[string][]string{
+	"solr.query":             {"read"},
+	"solr.explain_query":     {"read"},
+	"solr.dedupe.find":       {"read"},
+	"solr.profile":           {"read"},
+	"solr.export":            {"read"},
+	"solr.archive":           {"read"},
+	"solr.query.shards":      {"collection-admin-read", "read"},
+	"solr.consistency.check": {"collection-admin-read", "read"},
+	"solr.ping":              {"collection-admin-read"},
+	"solr.healthcheck.full":  {"collection-admin-read", "metrics-read", "read"},
+	"solr.collection.health": {"collection-admin-read"},
+	"solr.collection.drop":   {"collection-admin-edit"},
+	"solr.schema":            {"schema-read"},
+	"solr.ltr.list":          {"schema-read"},
+	"solr.ltr.upload":        {"schema-edit"},
+	"solr.params.list":       {"config-read"},
+	"solr.params.set":        {"config-edit"},
+	"solr.elevation.list":    {"config-read"},
+	"solr.elevation.set":     {"config-edit", "collection-admin-edit"},
+	"solr.update":            {"update"},
+	"solr.delete":            {"update"},
+	"solr.import":            {"update"},
+	"solr.archive.restore":   {"update"},
+	"solr.reindex":           {"read", "update"},
+}
+
+// toolGrant is whether the Solr user may run a tool, and why not.
+type toolGrant struct {
+	Allowed bool     `json:"allowed"`
+	Needs   []string `json:"needs"`
+	Reason  string   `json:"reason,omitempty"`
+}
+
+func (st *State) toolPermissions(ctx context.Context, _ *mcp.CallToolRequest, in types.PermissionsIn) (*mcp.CallToolResult, any, error) {
+	baseURL := st.solrURL()
+	sec, err := solr.GetSecurityInfo(ctx, st.HttpClient, baseURL, st.BasicUser, st.BasicPass)
+	if err != nil {
+		return nil, nil, err
+	}
+	user := utils.Choose(in.User, utils.Choose(sec.User, st.BasicUser))
+	own := user == utils.Choose(sec.User, st.BasicUser)
+	out := map[string]any{
+		"user":           user,
+		"authenticated":  user != "",
+		"authentication": utils.Choose(sec.Authentication, "none"),
+	}
+	if in.Collection != "" {
+		out["collection"] = in.Collection
+	}
+
+	auth, authErr := solr.GetAuthorization(ctx, st.HttpClient, baseURL, st.BasicUser, st.BasicPass)
+	var grants []solr.Grant
+	switch {
+	case authErr == nil:
+		roles := []string(auth.UserRoles[user])
+		if own && sec.Roles != nil {
+			roles = sec.Roles
+		}
+		out["roles"] = roles
+		out["authorization"] = map[string]any{"enabled": auth.Enabled, "class": auth.Class, "rulesVisible": true}
+		if auth.Enabled {
+			out["rules"] = auth.Permissions
+		}
+		for _, action := range solr.Actions {
+			grants = append(grants, auth.Check(action, in.Collection, user, roles))
+		}
+	case !own:
+		return nil, nil, fmt.Errorf("input.user: reading the roles of other users needs the security-read permission: %v", authErr)
+	default:
+		// Without security-read, the node still tells the user its roles and the rules granting them
+		out["roles"] = sec.Roles
+		out["authorization"] = map[string]any{"enabled": sec.Authorization != "", "class": sec.Authorization, "rulesVisible": false}
+		out["note"] = fmt.Sprintf("The rules are not visible to %s (%v), so permissions are judged from the names of the rules granted to its roles. A rule limited to some collections or listed earlier may still decide otherwise.", user, authErr)
+		for _, action := range solr.Actions {
+			grants = append(grants, grantFromNames(action, sec))
+		}
+	}
+	out["permissions"] = grants
+	out["tools"] = st.toolGrants(grants)
+	return nil, out, nil
+}
+
+// grantFromNames judges an action from the names of the rules granted to the roles of the user, which is all
+// a user without security-read can see.
+func grantFromNames(action solr.Action, sec solr.SecurityInfo) solr.Grant {
+	g := solr.Grant{Action: action.Name}
+	switch {
+	case sec.Authorization == "":
+		g.Allowed, g.Reason = true, "Solr has no authorization plugin"
+	case slices.Contains(sec.Permissions, action.Name):
+		g.Allowed, g.Rule, g.Reason = true, action.Name, fmt.Sprintf("rule %s is granted to the roles of the user", action.Name)
+	case slices.Contains(sec.Permissions, "all"):
+		g.Allowed, g.Rule, g.Reason = true, "all", "rule all is granted to the roles of the user"
+	default:
+		g.Reason = fmt.Sprintf("no rule named %s or all is granted to the roles %s", action.Name, strings.Join(sec.Roles, ", "))
+	}
+	return g
+}
+
+// toolGrants judges the enabled tools of the server from the permissions they need.
+func (st *State) toolGrants(grants []solr.Grant) map[string]toolGrant {
+	byAction := map[string]solr.Grant{}
+	for _, g := range grants {
+		byAction[g.Action] = g
+	}
+	st.toolMu.Lock()
+	defer st.toolMu.Unlock()
+	tools := map[string]toolGrant{}
+	for name, needs := range toolPermissions {
+		if !st.enabledTools[name] {
+			continue
+		}
+		tg := toolGrant{Allowed: true, Needs: needs}
+		for _, need := range needs {
+			if g := byAction[need]; !g.Allowed {
+				tg.Allowed, tg.Reason = false, fmt.Sprintf("%s is denied: %s", need, g.Reason)
+				break
+			}
+		}
+		tools[name] = tg
+	}
+	return tools
+}
+
+// explainForbidden points errors of requests Solr refused with HTTP 403 to solr.permissions, so agents can tell
+// users which permission is missing instead of reporting a bare 403.
+func (st *State) explainForbidden(tool string, err error) error {
+	if err == nil || tool == "solr.permissions" || !strings.Contains(err.Error(), "HTTP status 403") {
+		return err
+	}
+	user := utils.Choose(st.BasicUser, "the anonymous user")
+	hint := "Solr refused the request of " + user
+	if needs := toolPermissions[tool]; len(needs) > 0 {
+		hint += fmt.Sprintf(" (%s needs the %s permission)", tool, strings.Join(needs, " and "))
+	}
+	return fmt.Errorf("%w. %s; call solr.permissions to see the roles of %s and the authorization rules", err, hint, user)
+}
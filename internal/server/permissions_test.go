@@ -0,0 +1,95 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/solr"
+	"github.com/Sashimimochi/solr-mcp-go/internal/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestToolPermissions tests explaining the Solr permissions of the server user.
+func TestToolPermissions(t *testing.T) {
+	rulesVisible := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, _, _ := r.BasicAuth()
+		switch r.URL.Path {
+		case "/solr/admin/info/system":
+			fmt.Fprintf(w, `{"security":{"authenticationPlugin":"org.apache.solr.security.BasicAuthPlugin","authorizationPlugin":"org.apache.solr.security.RuleBasedAuthorizationPlugin","username":%q,"roles":["reader"],"permissions":["read","schema-read"]}}`, user)
+		case "/solr/admin/authorization":
+			if !rulesVisible {
+				http.Error(w, `{"error":{"msg":"Unauthorized request"}}`, http.StatusForbidden)
+				return
+			}
+			fmt.Fprintln(w, `{"authorization.enabled":true,"authorization":{"class":"solr.RuleBasedAuthorizationPlugin",
+				"user-role":{"agent":"reader","indexer":["reader","writer"]},
+				"permissions":[{"name":"read","role":"*","index":1},{"name":"schema-read","role":"reader","index":2},{"name":"update","collection":"products","role":"writer","index":3},{"name":"all","role":"admin","index":4}]}}`)
+		case "/solr/products/schema/uniquekey":
+			fmt.Fprintln(w, `{"uniqueKey":"id"}`)
+		case "/solr/products/schema/fields":
+			fmt.Fprintln(w, `{"fields":[{"name":"id","type":"string"}]}`)
+		case "/solr/products/select":
+			fmt.Fprintln(w, `{"response":{"numFound":0,"docs":[]}}`)
+		case "/solr/products/update":
+			http.Error(w, `{"error":{"msg":"Unauthorized request, Response code: 403"}}`, http.StatusForbidden)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	st := NewServer(WithSolrURL(server.URL), WithBasicAuth("agent", "secret"), WithHTTPClient(&http.Client{}))
+	st.NewMCPServer()
+	ctx := context.Background()
+
+	// Goal: With the rules visible, each permission and tool is judged by the rule deciding it.
+	_, out, err := st.toolPermissions(ctx, nil, types.PermissionsIn{Collection: "products"})
+	require.NoError(t, err)
+	res := out.(map[string]any)
+	assert.Equal(t, "agent", res["user"])
+	assert.Equal(t, []string{"reader"}, res["roles"])
+	assert.Len(t, res["rules"], 4)
+	grants := map[string]solr.Grant{}
+	for _, g := range res["permissions"].([]solr.Grant) {
+		grants[g.Action] = g
+	}
+	assert.True(t, grants["read"].Allowed)
+	assert.False(t, grants["update"].Allowed)
+	assert.Equal(t, 3, grants["update"].Index)
+	tools := res["tools"].(map[string]toolGrant)
+	assert.True(t, tools["solr.query"].Allowed)
+	assert.False(t, tools["solr.update"].Allowed)
+	assert.Equal(t, "update is denied: rule update needs one of the roles writer, and agent has reader", tools["solr.update"].Reason)
+	assert.NotContains(t, tools, "solr.info", "tools without Solr permissions are not listed")
+
+	// Goal: Other users are judged by their roles in the authorization config.
+	_, out, err = st.toolPermissions(ctx, nil, types.PermissionsIn{Collection: "products", User: "indexer"})
+	require.NoError(t, err)
+	assert.True(t, out.(map[string]any)["tools"].(map[string]toolGrant)["solr.update"].Allowed)
+
+	// Goal: Without security-read, permissions are judged from the names of the granted rules.
+	rulesVisible = false
+	_, out, err = st.toolPermissions(ctx, nil, types.PermissionsIn{})
+	require.NoError(t, err)
+	res = out.(map[string]any)
+	assert.Equal(t, false, res["authorization"].(map[string]any)["rulesVisible"])
+	assert.Contains(t, res["note"], "not visible to agent")
+	tools = res["tools"].(map[string]toolGrant)
+	assert.True(t, tools["solr.schema"].Allowed)
+	assert.Equal(t, "update is denied: no rule named update or all is granted to the roles reader", tools["solr.update"].Reason)
+	_, _, err = st.toolPermissions(ctx, nil, types.PermissionsIn{User: "indexer"})
+	assert.ErrorContains(t, err, "input.user: reading the roles of other users needs the security-read permission")
+
+	// Goal: Errors of requests refused with HTTP 403 point to solr.permissions.
+	_, _, err = st.toolUpdate(ctx, nil, types.UpdateIn{Collection: "products", Documents: []map[string]any{{"id": "1"}}})
+	require.Error(t, err)
+	err = st.explainForbidden("solr.update", err)
+	assert.ErrorContains(t, err, "HTTP status 403")
+	assert.ErrorContains(t, err, "Solr refused the request of agent (solr.update needs the update permission); call solr.permissions")
+	assert.NoError(t, st.explainForbidden("solr.update", nil))
+}
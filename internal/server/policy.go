@@ -0,0 +1,231 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/errcatalog"
+	"github.com/Sashimimochi/solr-mcp-go/internal/policy"
+	"github.com/Sashimimochi/solr-mcp-go/internal/solr"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// policyEngine returns the authorization policy engine, created with the policy of the config file.
+func (st *State) policyEngine() *policy.Engine {
+	st.policyOnce.Do(func() {
+		// OPA is not Solr, so it is queried without the chaos and replay wrappers of HttpClient
+		st.policy = policy.New(&http.Client{})
+		if err := st.policy.SetConfig(st.fileConfig().Policy); err != nil {
+			slog.Error("Invalid policy, all tool calls are denied", "error", err)
+		}
+	})
+	return st.policy
+}
+
+// queryAuthorizedTools are authorized by their handlers once the final Solr parameters are known, so the fields
+// and rows requested through params, select or useParams are what the policy sees.
+var queryAuthorizedTools = map[string]bool{
+	"solr.query": true,
+}
+
+// solrDefaultRows is the number of rows Solr returns when a request does not set rows.
+const solrDefaultRows = 10
+
+// authorize decides whether the call of tool with the arguments in is allowed by the policy, recording the
+// decision in the audit trail. Tools of queryAuthorizedTools pass the final Solr parameters of the call as params;
+// they are allowed here when params is nil.
+func (st *State) authorize(ctx context.Context, req *mcp.CallToolRequest, tool string, in any, params url.Values) error {
+	e := st.policyEngine()
+	cfg := e.Config()
+	if !cfg.Enabled() || (queryAuthorizedTools[tool] && params == nil) {
+		return nil
+	}
+	input := policy.Input{Tool: tool}
+	if data, err := json.Marshal(in); err == nil {
+		_ = json.Unmarshal(data, &input.Args)
+	}
+	input.Collection, _ = input.Args["collection"].(string)
+	if params != nil {
+		var err error
+		if input.Fields, input.Rows, err = st.queryScope(ctx, input.Collection, params); err != nil {
+			return errcatalog.New(errcatalog.PolicyDenied, "denied by policy: %v", err)
+		}
+	} else {
+		if rows, ok := input.Args["rows"].(float64); ok {
+			input.Rows = int64(rows)
+		}
+		for _, key := range []string{"fl", "fields"} {
+			if fields, ok := input.Args[key].([]any); ok {
+				for _, f := range fields {
+					if s, ok := f.(string); ok {
+						input.Fields = append(input.Fields, s)
+					}
+				}
+			}
+		}
+	}
+	if req != nil {
+		if req.Extra != nil {
+			input.User = req.Extra.Header.Get(cfg.UserHeaderName())
+			if req.Extra.TokenInfo != nil {
+				input.Scopes = req.Extra.TokenInfo.Scopes
+			}
+		}
+		if req.Session != nil {
+			if p := req.Session.InitializeParams(); p != nil && p.ClientInfo != nil {
+				input.Client = p.ClientInfo.Name
+			}
+		}
+	}
+
+	d := e.Decide(ctx, input)
+	e.Audit(input, d)
+	if d.Allow {
+		return nil
+	}
+	reason := d.Reason
+	if reason == "" {
+		reason = "this call is not allowed"
+	}
+	return errcatalog.New(errcatalog.PolicyDenied, "denied by policy %s: %s", d.Rule, reason)
+}
+
+// queryScope returns the fields and the number of rows requested by the Solr parameters of a query, including
+// those added by its useParams parameter sets. Where a parameter set and the request disagree, the larger scope
+// is returned: all fields of both, and the most rows. Without fl, and for * and patterns such as salary_*, the
+// fields are those of the schema that Solr would return.
+func (st *State) queryScope(ctx context.Context, collection string, params url.Values) ([]string, int64, error) {
+	fields := splitFieldList(params["fl"])
+	rows, explicit := largestRows(params["rows"])
+	if names := params.Get("useParams"); names != "" && st.Backend == nil {
+		sets, err := solr.ListParamSets(ctx, st.HttpClient, st.solrURL(), st.BasicUser, st.BasicPass, collection)
+		if err != nil {
+			return nil, 0, fmt.Errorf("cannot read the parameter sets of useParams: %v", err)
+		}
+		for _, name := range strings.Split(names, ",") {
+			for _, set := range sets {
+				if set.Name != strings.TrimSpace(name) {
+					continue
+				}
+				fl, setRows, ok := paramSetScope(set.Params)
+				fields = append(fields, fl...)
+				if ok && (!explicit || setRows > rows) {
+					rows, explicit = setRows, true
+				}
+			}
+		}
+	}
+	if !explicit {
+		rows = solrDefaultRows
+	}
+	fields, err := st.expandFieldPatterns(ctx, collection, fields)
+	if err != nil {
+		return nil, 0, err
+	}
+	return fields, rows, nil
+}
+
+// expandFieldPatterns replaces the * and field name patterns of an fl field list with the schema fields they
+// select. An empty list selects all fields.
+func (st *State) expandFieldPatterns(ctx context.Context, collection string, fields []string) ([]string, error) {
+	if len(fields) == 0 {
+		fields = []string{"*"}
+	}
+	if !slices.ContainsFunc(fields, isFieldPattern) {
+		return fields, nil
+	}
+	fc, err := st.backend().Schema(ctx, collection)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read the schema to resolve the fields of fl: %v", err)
+	}
+	var expanded []string
+	for _, f := range fields {
+		if isFieldPattern(f) {
+			expanded = append(expanded, solr.MatchingFields(fc, f)...)
+		} else {
+			expanded = append(expanded, f)
+		}
+	}
+	return expanded, nil
+}
+
+// isFieldPattern reports whether an fl entry selects fields by a pattern. Transformers such as [child] are not
+// field names.
+func isFieldPattern(f string) bool {
+	return strings.Contains(f, "*") && !strings.HasPrefix(f, "[")
+}
+
+// paramSetScope returns the fields and the most rows of a parameter set, including its _invariants_ and
+// _appends_ sections.
+func paramSetScope(params map[string]any) (fields []string, rows int64, ok bool) {
+	for key, v := range params {
+		switch key {
+		case "fl":
+			fields = append(fields, splitFieldList(paramStrings(v))...)
+		case "rows":
+			if r, found := largestRows(paramStrings(v)); found && (!ok || r > rows) {
+				rows, ok = r, true
+			}
+		case "_invariants_", "_appends_":
+			if section, isMap := v.(map[string]any); isMap {
+				fl, r, found := paramSetScope(section)
+				fields = append(fields, fl...)
+				if found && (!ok || r > rows) {
+					rows, ok = r, true
+				}
+			}
+		}
+	}
+	return fields, rows, ok
+}
+
+// paramStrings returns a parameter value of a parameter set as strings.
+func paramStrings(v any) []string {
+	switch v := v.(type) {
+	case []any:
+		var out []string
+		for _, e := range v {
+			out = append(out, fmt.Sprint(e))
+		}
+		return out
+	case nil:
+		return nil
+	default:
+		return []string{fmt.Sprint(v)}
+	}
+}
+
+// splitFieldList splits fl values such as "id,title score" into field names.
+func splitFieldList(values []string) []string {
+	var fields []string
+	for _, v := range values {
+		fields = append(fields, strings.FieldsFunc(v, func(r rune) bool { return r == ',' || unicode.IsSpace(r) })...)
+	}
+	return fields
+}
+
+// largestRows returns the largest of the rows values. Values that are not numbers count as unlimited, so a policy
+// on rows cannot be sidestepped with them.
+func largestRows(values []string) (int64, bool) {
+	var rows int64
+	for i, v := range values {
+		r, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64)
+		if err != nil {
+			r = math.MaxInt64
+		}
+		if i == 0 || r > rows {
+			rows = r
+		}
+	}
+	return rows, len(values) > 0
+}
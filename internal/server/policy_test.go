@@ -0,0 +1,150 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/config"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// userTransport names the caller in every request, as an authenticating proxy does.
+type userTransport string
+
+func (u userTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	r = r.Clone(r.Context())
+	r.Header.Set("X-Forwarded-User", string(u))
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+// TestPolicy tests authorizing tool calls by the caller with CEL rules and auditing the decisions.
+func TestPolicy(t *testing.T) {
+	audit := filepath.Join(t.TempDir(), "policy.jsonl")
+	st := NewServer(WithSolrURL("http://127.0.0.1:1"), WithHTTPClient(&http.Client{}))
+	st.Config = &config.FileConfig{Policy: config.PolicyConfig{
+		Rules: []config.PolicyRule{{
+			Name:   "ops-only-sessions",
+			When:   `tool == "solr.server.sessions" && user != "ops"`,
+			Effect: config.PolicyDeny,
+			Reason: "only operators may list sessions",
+		}},
+		AuditFile: audit,
+	}}
+	mcpServer := st.NewMCPServer()
+	httpServer := httptest.NewServer(mcp.NewStreamableHTTPHandler(func(*http.Request) *mcp.Server { return mcpServer }, nil))
+	t.Cleanup(httpServer.Close)
+	ctx := context.Background()
+	connect := func(user string) *mcp.ClientSession {
+		client := mcp.NewClient(&mcp.Implementation{Name: "agent"}, nil)
+		transport := &mcp.StreamableClientTransport{Endpoint: httpServer.URL, HTTPClient: &http.Client{Transport: userTransport(user)}}
+		session, err := client.Connect(ctx, transport, nil)
+		require.NoError(t, err)
+		t.Cleanup(func() { session.Close() })
+		return session
+	}
+	call := func(session *mcp.ClientSession) *mcp.CallToolResult {
+		res, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "solr.server.sessions", Arguments: map[string]any{}})
+		require.NoError(t, err)
+		return res
+	}
+
+	// Goal: A matching deny rule rejects the call with its reason.
+	res := call(connect("alice"))
+	require.True(t, res.IsError)
+	assert.Contains(t, res.Content[0].(*mcp.TextContent).Text, "denied by policy ops-only-sessions: only operators may list sessions")
+
+	// Goal: Calls no rule matches are allowed by default.
+	assert.False(t, call(connect("ops")).IsError)
+
+	// Goal: Every decision is recorded with the caller.
+	data, err := os.ReadFile(audit)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, lines, 2)
+	assert.Contains(t, lines[0], `"user":"alice"`)
+	assert.Contains(t, lines[0], `"allow":false`)
+	assert.Contains(t, lines[1], `"user":"ops"`)
+	assert.Contains(t, lines[1], `"client":"agent"`)
+
+	// Goal: A rule that does not compile denies every call.
+	err = st.policyEngine().SetConfig(config.PolicyConfig{Rules: []config.PolicyRule{{Name: "broken", When: "rows >", Effect: config.PolicyDeny}}})
+	require.Error(t, err)
+	res = call(connect("ops"))
+	require.True(t, res.IsError)
+	assert.Contains(t, res.Content[0].(*mcp.TextContent).Text, "the policy is invalid")
+}
+
+// TestQueryPolicy tests that solr.query is authorized on its final Solr parameters, however the fields and rows were set.
+func TestQueryPolicy(t *testing.T) {
+	var queries int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/solr/admin/collections":
+			fmt.Fprint(w, `{"collections":["hr"]}`)
+		case "/solr/hr/schema/uniquekey":
+			fmt.Fprint(w, `{"uniqueKey":"id"}`)
+		case "/solr/hr/schema/fields":
+			fmt.Fprint(w, `{"fields":[{"name":"id","type":"string"},{"name":"name","type":"string"},{"name":"salary","type":"pint"}]}`)
+		case "/solr/hr/config/params":
+			fmt.Fprint(w, `{"response":{"params":{"bulk":{"_invariants_":{"rows":"5000"},"":{"v":1}},"names":{"fl":"id,name"}}}}`)
+		case "/solr/hr/select":
+			queries++
+			fmt.Fprint(w, `{"response":{"numFound":0,"docs":[]}}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	st := NewServer(WithSolrURL(server.URL), WithHTTPClient(&http.Client{}), WithConfig(&config.FileConfig{
+		QueryLimits: config.QueryLimitsConfig{MaxRows: 500},
+		Policy: config.PolicyConfig{Rules: []config.PolicyRule{
+			{Name: "no-salary", When: `"salary" in fields`, Effect: config.PolicyDeny},
+			{Name: "max-rows", When: `rows > 100`, Effect: config.PolicyDeny},
+		}},
+	}, ""))
+	session, _, _ := connectTestClient(t, st.NewMCPServer())
+	ctx := context.Background()
+	call := func(args map[string]any) *mcp.CallToolResult {
+		args["collection"] = "hr"
+		res, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "solr.query", Arguments: args})
+		require.NoError(t, err)
+		return res
+	}
+	denied := func(res *mcp.CallToolResult) string {
+		require.True(t, res.IsError)
+		return res.Content[0].(*mcp.TextContent).Text
+	}
+
+	// Goal: Rows and fields set through params are seen by the policy.
+	assert.Contains(t, denied(call(map[string]any{"params": map[string]any{"fl": "id", "rows": "1000"}})), "denied by policy max-rows")
+	assert.Contains(t, denied(call(map[string]any{"params": map[string]any{"fl": "id salary"}})), "denied by policy no-salary")
+
+	// Goal: Without fl, and with * or a pattern, the policy sees the schema fields Solr would return.
+	assert.Contains(t, denied(call(map[string]any{})), "denied by policy no-salary")
+	assert.Contains(t, denied(call(map[string]any{"fl": []any{"*"}})), "denied by policy no-salary")
+	assert.Contains(t, denied(call(map[string]any{"params": map[string]any{"fl": "id,sal*"}})), "denied by policy no-salary")
+
+	// Goal: Rows are checked after queryLimits.maxRows clamped them.
+	assert.Contains(t, denied(call(map[string]any{"fl": []any{"id"}, "rows": 200})), "denied by policy max-rows")
+	st.Config.QueryLimits.MaxRows = 50
+	assert.False(t, call(map[string]any{"fl": []any{"id"}, "rows": 200}).IsError)
+
+	// Goal: Rows set by a parameter set of useParams are seen by the policy.
+	assert.Contains(t, denied(call(map[string]any{"fl": []any{"id"}, "useParams": []any{"bulk"}})), "denied by policy max-rows")
+	assert.False(t, call(map[string]any{"useParams": []any{"names"}}).IsError)
+	assert.Equal(t, 2, queries)
+
+	// Goal: The parameters of an experiment variant are authorized with the request.
+	st.experiments().SetConfig([]config.ExperimentConfig{{Name: "pay", Fraction: 1, Params: map[string]string{"fl": "id,salary"}}})
+	assert.Contains(t, denied(call(map[string]any{"fl": []any{"id"}})), "denied by policy no-salary")
+	assert.Equal(t, 2, queries)
+}
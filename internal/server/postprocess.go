@@ -0,0 +1,46 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/postprocess"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// postProcessors returns the post-processor registry, creating it on first use.
+func (st *State) postProcessors() *postprocess.Registry {
+	st.processorsOnce.Do(func() {
+		st.processors = postprocess.NewRegistry(st.fileConfig().PostProcessing)
+	})
+	return st.processors
+}
+
+// RegisterPostProcessor adds a processor that the postProcessing config can reference by name.
+func (st *State) RegisterPostProcessor(name string, p postprocess.Processor) {
+	st.postProcessors().Register(name, p)
+}
+
+// postProcess runs the configured pipeline of tool over a successful tool result.
+func postProcess[Out any](ctx context.Context, st *State, tool string, res *mcp.CallToolResult, out Out) (*mcp.CallToolResult, Out, error) {
+	if any(out) == nil {
+		return res, out, nil
+	}
+	r, err := st.postProcessors().Process(ctx, tool, out)
+	if err != nil || r == nil {
+		return res, out, err
+	}
+	processed, ok := r.Output.(Out)
+	if !ok {
+		slog.Warn("Post-processed output does not match the tool output type, keeping the original", "tool", tool)
+		return res, out, nil
+	}
+	if r.Text != "" {
+		if res == nil {
+			res = &mcp.CallToolResult{}
+		}
+		res.Content = []mcp.Content{&mcp.TextContent{Text: r.Text}}
+	}
+	return res, processed, nil
+}
@@ -0,0 +1,40 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/config"
+	"github.com/Sashimimochi/solr-mcp-go/internal/postprocess"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestPostProcessing tests that configured and registered processors are applied to tool results.
+func TestPostProcessing(t *testing.T) {
+	st := newTestState(t, "http://localhost:8983")
+	st.Config = &config.FileConfig{PostProcessing: config.PostProcessingConfig{
+		Tools:        map[string][]string{"solr.info": {postprocess.Redact, "label"}},
+		RedactFields: []string{"solrUrl"},
+	}}
+	st.RegisterPostProcessor("label", postprocess.ProcessorFunc(func(_ context.Context, r *postprocess.Result) error {
+		r.Text = "processed " + r.Tool
+		return nil
+	}))
+	mcpServer := mcp.NewServer(&mcp.Implementation{}, nil)
+	AddTools(mcpServer, st)
+	session, _, _ := connectTestClient(t, mcpServer)
+
+	res, err := session.CallTool(context.Background(), &mcp.CallToolParams{Name: "solr.info", Arguments: map[string]any{}})
+	assert.NoError(t, err)
+
+	// Goal: The structured output is redacted and the text content is replaced.
+	assert.Equal(t, "processed solr.info", res.Content[0].(*mcp.TextContent).Text)
+	assert.Equal(t, "[REDACTED]", res.StructuredContent.(map[string]any)["solrUrl"])
+
+	// Goal: Tools without a pipeline are unchanged.
+	res, err = session.CallTool(context.Background(), &mcp.CallToolParams{Name: "solr.retention.list", Arguments: map[string]any{}})
+	assert.NoError(t, err)
+	assert.True(t, res.IsError)
+}
@@ -0,0 +1,65 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/solr"
+	"github.com/Sashimimochi/solr-mcp-go/internal/types"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// defaultProfileMaxFields limits how many fields a profile covers when no fields are given.
+const defaultProfileMaxFields = 100
+
+func (st *State) toolProfile(ctx context.Context, _ *mcp.CallToolRequest, in types.ProfileIn) (*mcp.CallToolResult, any, error) {
+	if strings.TrimSpace(in.Collection) == "" {
+		return nil, nil, errors.New("input.collection is required")
+	}
+	fc, err := st.backend().Schema(ctx, in.Collection)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get schema: %v", err)
+	}
+
+	var fields []types.SolrField
+	if len(in.Fields) > 0 {
+		for _, name := range in.Fields {
+			if !fc.HasField(name) {
+				return nil, nil, fmt.Errorf("field %s does not exist in collection %s", name, in.Collection)
+			}
+			field := types.SolrField{Name: name}
+			for _, f := range fc.All {
+				if f.Name == name {
+					field = f
+				}
+			}
+			fields = append(fields, field)
+		}
+	} else {
+		fields = solr.ProfileFields(fc)
+	}
+	maxFields := in.MaxFields
+	if maxFields <= 0 {
+		maxFields = defaultProfileMaxFields
+	}
+	var skipped []string
+	if len(fields) > maxFields {
+		for _, f := range fields[maxFields:] {
+			skipped = append(skipped, f.Name)
+		}
+		fields = fields[:maxFields]
+	}
+
+	profile, err := solr.ProfileCollection(ctx, st.backend(), in.Collection, in.Query, fields, time.Now())
+	if err != nil {
+		return nil, nil, err
+	}
+	profile.SkippedFields = skipped
+	slog.Info("Collection profiled", "collection", in.Collection, "fields", len(fields), "findings", len(profile.Findings))
+	return nil, profile, nil
+}
@@ -0,0 +1,30 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/solr"
+	"github.com/Sashimimochi/solr-mcp-go/internal/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestToolProfile tests the field selection of solr.profile.
+func TestToolProfile(t *testing.T) {
+	st := NewServer(WithBackend(&fakeBackend{docs: map[string][]map[string]any{"logs": {{"id": "1"}}}}))
+	ctx := context.Background()
+
+	// Goal: Unknown fields are rejected before Solr is queried.
+	_, _, err := st.toolProfile(ctx, nil, types.ProfileIn{Collection: "logs", Fields: []string{"nope"}})
+	assert.ErrorContains(t, err, "field nope does not exist")
+
+	// Goal: Without fields, the schema fields are profiled.
+	_, out, err := st.toolProfile(ctx, nil, types.ProfileIn{Collection: "logs"})
+	require.NoError(t, err)
+	profile := out.(*solr.CollectionProfile)
+	require.Len(t, profile.Fields, 1)
+	assert.Equal(t, "id", profile.Fields[0].Name)
+	assert.Equal(t, int64(1), profile.NumDocs)
+}
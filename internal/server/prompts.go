@@ -0,0 +1,96 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/utils"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// AddPrompts registers prompt templates whose arguments can be completed by the host.
+func AddPrompts(mcpServer *mcp.Server, st *State) []string {
+	var promptNames []string
+
+	// solr.search prompt
+	mcpServer.AddPrompt(&mcp.Prompt{
+		Name:        "solr.search",
+		Description: "Search a Solr collection with solr.query",
+		Arguments: []*mcp.PromptArgument{
+			{Name: "collection", Description: "Solr collection name", Required: true},
+			{Name: "query", Description: "Solr query string (default: *:*)"},
+			{Name: "fl", Description: "Comma-separated fields to return"},
+			{Name: "sort", Description: "Sort criteria (e.g., 'price asc')"},
+		},
+	}, st.promptSearch)
+	promptNames = append(promptNames, "solr.search")
+
+	// solr.saved_query prompt
+	mcpServer.AddPrompt(&mcp.Prompt{
+		Name:        "solr.saved_query",
+		Description: "Run a saved query from the server configuration with solr.query",
+		Arguments: []*mcp.PromptArgument{
+			{Name: "name", Description: "Saved query name", Required: true},
+		},
+	}, st.promptSavedQuery)
+	promptNames = append(promptNames, "solr.saved_query")
+
+	return promptNames
+}
+
+func (st *State) promptSearch(ctx context.Context, req *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	args := req.Params.Arguments
+	if strings.TrimSpace(args["collection"]) == "" {
+		return nil, fmt.Errorf("argument collection is required")
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Use the solr.query tool to search the Solr collection %q", args["collection"])
+	if q := strings.TrimSpace(args["query"]); q != "" {
+		fmt.Fprintf(&b, " with the query %q", q)
+	}
+	b.WriteString(".")
+	if fl := strings.TrimSpace(args["fl"]); fl != "" {
+		fmt.Fprintf(&b, " Return only the fields %s.", fl)
+	}
+	if sort := strings.TrimSpace(args["sort"]); sort != "" {
+		fmt.Fprintf(&b, " Sort the results by %s.", sort)
+	}
+
+	return &mcp.GetPromptResult{
+		Description: "Search a Solr collection",
+		Messages: []*mcp.PromptMessage{
+			{Role: "user", Content: &mcp.TextContent{Text: b.String()}},
+		},
+	}, nil
+}
+
+func (st *State) promptSavedQuery(ctx context.Context, req *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	if e := st.CapabilityError("saved_queries"); e != nil {
+		return nil, e
+	}
+	name := req.Params.Arguments["name"]
+	q, ok := st.fileConfig().SavedQuery(name)
+	if !ok {
+		return nil, fmt.Errorf("saved query %q not found", name)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Use the solr.query tool on the collection %q with the query %q", q.Collection, utils.Choose(q.Query, "*:*"))
+	if len(q.FilterQuery) > 0 {
+		fmt.Fprintf(&b, " and the filter queries %q", q.FilterQuery)
+	}
+	b.WriteString(".")
+	if q.Description != "" {
+		fmt.Fprintf(&b, " This query finds: %s.", strings.TrimSuffix(q.Description, "."))
+	}
+
+	return &mcp.GetPromptResult{
+		Description: q.Description,
+		Messages: []*mcp.PromptMessage{
+			{Role: "user", Content: &mcp.TextContent{Text: b.String()}},
+		},
+	}, nil
+}
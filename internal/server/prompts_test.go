@@ -0,0 +1,86 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/config"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+)
+
+// getPrompt is a helper that builds a GetPromptRequest with the given arguments.
+func getPrompt(args map[string]string) *mcp.GetPromptRequest {
+	return &mcp.GetPromptRequest{Params: &mcp.GetPromptParams{Arguments: args}}
+}
+
+// TestPromptSearch tests the solr.search prompt.
+func TestPromptSearch(t *testing.T) {
+	st := newTestState(t, "http://localhost:8983")
+
+	// Goal: All provided arguments are reflected in the instruction.
+	t.Run("Success", func(t *testing.T) {
+		res, err := st.promptSearch(context.Background(), getPrompt(map[string]string{
+			"collection": "products", "query": "ipod", "fl": "id,price", "sort": "price asc",
+		}))
+
+		assert.NoError(t, err)
+		text := res.Messages[0].Content.(*mcp.TextContent).Text
+		assert.Contains(t, text, `"products"`)
+		assert.Contains(t, text, `"ipod"`)
+		assert.Contains(t, text, "id,price")
+		assert.Contains(t, text, "price asc")
+	})
+
+	// Goal: The collection argument is required.
+	t.Run("Error: missing collection", func(t *testing.T) {
+		_, err := st.promptSearch(context.Background(), getPrompt(map[string]string{}))
+		assert.Error(t, err)
+	})
+}
+
+// TestPromptSavedQuery tests the solr.saved_query prompt.
+func TestPromptSavedQuery(t *testing.T) {
+	st := newTestState(t, "http://localhost:8983")
+	st.Config = &config.FileConfig{SavedQueries: []config.SavedQuery{
+		{Name: "errors", Collection: "logs", FilterQuery: []string{"level:ERROR"}, Description: "Error logs"},
+	}}
+
+	// Goal: The saved query definition is turned into an instruction.
+	t.Run("Success", func(t *testing.T) {
+		res, err := st.promptSavedQuery(context.Background(), getPrompt(map[string]string{"name": "errors"}))
+
+		assert.NoError(t, err)
+		text := res.Messages[0].Content.(*mcp.TextContent).Text
+		assert.Contains(t, text, `"logs"`)
+		assert.Contains(t, text, `"*:*"`)
+		assert.Contains(t, text, "level:ERROR")
+		assert.Contains(t, text, "Error logs")
+	})
+
+	// Goal: Without saved queries a capability error with a setup hint is returned.
+	t.Run("Error: no saved queries configured", func(t *testing.T) {
+		st := newTestState(t, "http://localhost:8983")
+		_, err := st.promptSavedQuery(context.Background(), getPrompt(map[string]string{"name": "errors"}))
+		var capErr *CapabilityError
+		assert.ErrorAs(t, err, &capErr)
+		assert.Equal(t, "saved_queries", capErr.Capability)
+	})
+
+	// Goal: Unknown saved queries are reported.
+	t.Run("Error: not found", func(t *testing.T) {
+		_, err := st.promptSavedQuery(context.Background(), getPrompt(map[string]string{"name": "nope"}))
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "not found")
+	})
+}
+
+// TestAddPrompts tests the AddPrompts function.
+func TestAddPrompts(t *testing.T) {
+	st := newTestState(t, "http://localhost:8983")
+
+	names := AddPrompts(mcp.NewServer(&mcp.Implementation{}, nil), st)
+
+	assert.Equal(t, []string{"solr.search", "solr.saved_query"}, names)
+}
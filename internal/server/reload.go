@@ -0,0 +1,112 @@
+package server
+
+import (
+	"log/slog"
+	"reflect"
+	"slices"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/config"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// fileConfig returns the current file configuration.
+func (st *State) fileConfig() *config.FileConfig {
+	st.configMu.RLock()
+	defer st.configMu.RUnlock()
+	return st.Config
+}
+
+// EnabledToolNames returns the names of the currently registered tools in registration order.
+func (st *State) EnabledToolNames() []string {
+	st.toolMu.Lock()
+	defer st.toolMu.Unlock()
+
+	var names []string
+	for _, name := range st.toolOrder {
+		if st.enabledTools[name] {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// ApplyConfig swaps in a reloaded file configuration and updates the tools and resources registered on mcpServer.
+// The MCP SDK sends tools/list_changed and resources/list_changed notifications to connected sessions
+// whenever a tool or resource is added or removed.
+func (st *State) ApplyConfig(mcpServer *mcp.Server, fc *config.FileConfig) {
+	st.configMu.Lock()
+	st.Config = fc
+	st.configMu.Unlock()
+
+	st.syncTools(mcpServer)
+	st.syncSavedQueryResources(mcpServer)
+
+	if st.exporter != nil {
+		st.exporter.SetQueries(fc.SavedQueries)
+	}
+	if st.datasource != nil {
+		st.datasource.SetQueries(fc.SavedQueries)
+	}
+	st.retentionManager().SetConfig(fc.Retention)
+	st.driftManager().SetConfig(fc.Drift)
+	st.maintenanceManager().SetConfig(fc.Maintenance)
+	st.postProcessors().SetConfig(fc.PostProcessing)
+	st.docTransformers().SetConfig(fc.Transformers)
+	st.experiments().SetConfig(fc.Experiments)
+	st.memoryGovernor().SetConfig(fc.MemoryBudget)
+	if err := st.policyEngine().SetConfig(fc.Policy); err != nil {
+		slog.Error("Invalid policy, all tool calls are denied", "error", err)
+	}
+}
+
+// syncTools registers or removes tools according to DisabledTools.
+func (st *State) syncTools(mcpServer *mcp.Server) {
+	st.toolMu.Lock()
+	defer st.toolMu.Unlock()
+
+	for _, name := range st.toolOrder {
+		disabled := st.toolDisabled(name)
+		switch {
+		case disabled && st.enabledTools[name]:
+			mcpServer.RemoveTools(name)
+			st.enabledTools[name] = false
+			slog.Info("Tool disabled", "tool", name)
+		case !disabled && !st.enabledTools[name]:
+			st.toolRegistry[name](mcpServer)
+			st.enabledTools[name] = true
+			slog.Info("Tool enabled", "tool", name)
+		}
+	}
+}
+
+// syncSavedQueryResources registers a resource for each new or changed saved query and removes resources of deleted ones.
+func (st *State) syncSavedQueryResources(mcpServer *mcp.Server) {
+	st.toolMu.Lock()
+	defer st.toolMu.Unlock()
+
+	if st.savedQueryRes == nil {
+		st.savedQueryRes = make(map[string]config.SavedQuery)
+	}
+	fc := st.fileConfig()
+	var current []string
+	for _, q := range fc.SavedQueries {
+		current = append(current, q.Name)
+		if prev, ok := st.savedQueryRes[q.Name]; ok && reflect.DeepEqual(prev, q) {
+			continue
+		}
+		mcpServer.AddResource(&mcp.Resource{
+			Name:        q.Name,
+			URI:         savedQueryResourceURI(q.Name),
+			Description: q.Description,
+			MIMEType:    "application/json",
+		}, st.readSavedQueryResource)
+		st.savedQueryRes[q.Name] = q
+	}
+	for name := range st.savedQueryRes {
+		if !slices.Contains(current, name) {
+			mcpServer.RemoveResources(savedQueryResourceURI(name))
+			delete(st.savedQueryRes, name)
+		}
+	}
+}
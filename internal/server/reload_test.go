@@ -0,0 +1,125 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/config"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+)
+
+// connectTestClient connects an in-memory MCP client to mcpServer and returns channels
+// that receive list_changed notifications.
+func connectTestClient(t *testing.T, mcpServer *mcp.Server) (*mcp.ClientSession, chan struct{}, chan struct{}) {
+	t.Helper()
+	toolsChanged := make(chan struct{}, 10)
+	resourcesChanged := make(chan struct{}, 10)
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client"}, &mcp.ClientOptions{
+		ToolListChangedHandler: func(context.Context, *mcp.ToolListChangedRequest) {
+			toolsChanged <- struct{}{}
+		},
+		ResourceListChangedHandler: func(context.Context, *mcp.ResourceListChangedRequest) {
+			resourcesChanged <- struct{}{}
+		},
+	})
+
+	ctx := context.Background()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	if _, err := mcpServer.Connect(ctx, serverTransport, nil); err != nil {
+		t.Fatalf("server connect: %v", err)
+	}
+	session, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatalf("client connect: %v", err)
+	}
+	t.Cleanup(func() { session.Close() })
+	return session, toolsChanged, resourcesChanged
+}
+
+// waitNotification waits for a notification on ch or fails the test.
+func waitNotification(t *testing.T, ch chan struct{}, what string) {
+	t.Helper()
+	select {
+	case <-ch:
+	case <-time.After(2 * time.Second):
+		t.Fatalf("expected %s notification", what)
+	}
+}
+
+// TestApplyConfigTools tests that disabling and re-enabling tools updates the tool list and notifies clients.
+func TestApplyConfigTools(t *testing.T) {
+	st := newTestState(t, "http://localhost:8983")
+	mcpServer := mcp.NewServer(&mcp.Implementation{}, nil)
+	AddTools(mcpServer, st)
+	session, toolsChanged, _ := connectTestClient(t, mcpServer)
+
+	// Goal: A disabled tool disappears from tools/list.
+	st.ApplyConfig(mcpServer, &config.FileConfig{DisabledTools: []string{"solr.ping"}})
+	waitNotification(t, toolsChanged, "tools/list_changed")
+
+	res, err := session.ListTools(context.Background(), nil)
+	assert.NoError(t, err)
+	var names []string
+	for _, tool := range res.Tools {
+		names = append(names, tool.Name)
+	}
+	assert.NotContains(t, names, "solr.ping")
+	assert.NotContains(t, st.EnabledToolNames(), "solr.ping")
+
+	// Goal: Re-enabling the tool registers it again.
+	st.ApplyConfig(mcpServer, &config.FileConfig{})
+	waitNotification(t, toolsChanged, "tools/list_changed")
+	assert.Equal(t, allToolNames, st.EnabledToolNames())
+}
+
+// TestApplyConfigSavedQueryResources tests that saved query resources follow the config file.
+func TestApplyConfigSavedQueryResources(t *testing.T) {
+	st := newTestState(t, "http://localhost:8983")
+	mcpServer := mcp.NewServer(&mcp.Implementation{}, &mcp.ServerOptions{HasResources: true})
+	AddResources(mcpServer, st)
+	session, _, resourcesChanged := connectTestClient(t, mcpServer)
+
+	listURIs := func() []string {
+		res, err := session.ListResources(context.Background(), nil)
+		assert.NoError(t, err)
+		var uris []string
+		for _, r := range res.Resources {
+			uris = append(uris, r.URI)
+		}
+		return uris
+	}
+
+	// Goal: Adding a saved query adds a resource and notifies clients.
+	st.ApplyConfig(mcpServer, &config.FileConfig{SavedQueries: []config.SavedQuery{{Name: "errors", Collection: "logs"}}})
+	waitNotification(t, resourcesChanged, "resources/list_changed")
+	assert.Equal(t, []string{errorsResourceURI, "solr://saved-queries/errors"}, listURIs())
+
+	// Goal: Removing the saved query removes the resource.
+	st.ApplyConfig(mcpServer, &config.FileConfig{})
+	waitNotification(t, resourcesChanged, "resources/list_changed")
+	assert.Equal(t, []string{errorsResourceURI}, listURIs())
+}
+
+// TestApplyConfigUnchanged tests that an unchanged configuration does not re-register anything.
+func TestApplyConfigUnchanged(t *testing.T) {
+	cfg := &config.FileConfig{SavedQueries: []config.SavedQuery{{Name: "errors", Collection: "logs"}}}
+	st := newTestState(t, "http://localhost:8983")
+	st.Config = cfg
+	mcpServer := mcp.NewServer(&mcp.Implementation{}, nil)
+	AddTools(mcpServer, st)
+	AddResources(mcpServer, st)
+	_, toolsChanged, resourcesChanged := connectTestClient(t, mcpServer)
+
+	st.ApplyConfig(mcpServer, &config.FileConfig{SavedQueries: []config.SavedQuery{{Name: "errors", Collection: "logs"}}})
+
+	select {
+	case <-toolsChanged:
+		t.Fatal("unexpected tools/list_changed notification")
+	case <-resourcesChanged:
+		t.Fatal("unexpected resources/list_changed notification")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
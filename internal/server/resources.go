@@ -0,0 +1,100 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/solr"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	schemaResourcePrefix     = "solr://collections/"
+	savedQueryResourcePrefix = "solr://saved-queries/"
+)
+
+// AddResources registers resources and resource templates.
+func AddResources(mcpServer *mcp.Server, st *State) []string {
+	var resourceNames []string
+
+	// Schema resource template
+	mcpServer.AddResourceTemplate(&mcp.ResourceTemplate{
+		Name:        "solr.schema",
+		Description: "Schema information (uniqueKey, fields, metadata) of a Solr collection",
+		MIMEType:    "application/json",
+		URITemplate: schemaResourcePrefix + "{collection}/schema",
+	}, st.readSchemaResource)
+	resourceNames = append(resourceNames, "solr.schema")
+
+	// Error catalog, so clients can look up the codes of tool errors up front
+	mcpServer.AddResource(&mcp.Resource{
+		Name:        "solr.errors",
+		Description: "Codes of tool errors with their messages, remediation hints and documentation links",
+		MIMEType:    "application/json",
+		URI:         errorsResourceURI,
+	}, st.readErrorsResource)
+	resourceNames = append(resourceNames, "solr.errors")
+
+	// One resource per saved query; kept in sync with the config file on reload
+	st.syncSavedQueryResources(mcpServer)
+	for _, q := range st.fileConfig().SavedQueries {
+		resourceNames = append(resourceNames, savedQueryResourceURI(q.Name))
+	}
+
+	return resourceNames
+}
+
+func (st *State) readSchemaResource(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	uri := req.Params.URI
+	collection, err := url.PathUnescape(strings.TrimSuffix(strings.TrimPrefix(uri, schemaResourcePrefix), "/schema"))
+	if err != nil || collection == "" || strings.Contains(collection, "/") {
+		return nil, mcp.ResourceNotFoundError(uri)
+	}
+
+	fc, err := st.backend().Schema(ctx, collection)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get schema: %v", err)
+	}
+	return jsonResource(uri, fc)
+}
+
+func savedQueryResourceURI(name string) string {
+	return savedQueryResourcePrefix + url.PathEscape(name)
+}
+
+// readSavedQueryResource returns the definition of a saved query together with its current value.
+func (st *State) readSavedQueryResource(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	uri := req.Params.URI
+	name, err := url.PathUnescape(strings.TrimPrefix(uri, savedQueryResourcePrefix))
+	if err != nil {
+		return nil, mcp.ResourceNotFoundError(uri)
+	}
+	q, ok := st.fileConfig().SavedQuery(name)
+	if !ok {
+		return nil, mcp.ResourceNotFoundError(uri)
+	}
+
+	out := map[string]any{"savedQuery": q}
+	if v, err := solr.EvaluateSavedQuery(ctx, st.HttpClient, st.solrURL(), st.BasicUser, st.BasicPass, q); err != nil {
+		out["error"] = err.Error()
+	} else {
+		out["value"] = v
+	}
+	return jsonResource(uri, out)
+}
+
+func jsonResource(uri string, v any) (*mcp.ReadResourceResult, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("encode resource: %v", err)
+	}
+	return &mcp.ReadResourceResult{
+		Contents: []*mcp.ResourceContents{
+			{URI: uri, MIMEType: "application/json", Text: string(data)},
+		},
+	}, nil
+}
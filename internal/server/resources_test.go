@@ -0,0 +1,64 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/types"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+)
+
+// readResource is a helper that builds a ReadResourceRequest for the given URI.
+func readResource(uri string) *mcp.ReadResourceRequest {
+	return &mcp.ReadResourceRequest{Params: &mcp.ReadResourceParams{URI: uri}}
+}
+
+// TestReadSchemaResource tests the schema resource template handler.
+func TestReadSchemaResource(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/schema/uniquekey"):
+			json.NewEncoder(w).Encode(map[string]any{"uniqueKey": "id"})
+		case strings.HasSuffix(r.URL.Path, "/schema/fields"):
+			json.NewEncoder(w).Encode(map[string]any{"fields": []map[string]any{{"name": "id", "type": "string"}}})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	st := newTestState(t, server.URL)
+
+	// Goal: The field catalog of the collection in the URI is returned as JSON.
+	t.Run("Success", func(t *testing.T) {
+		res, err := st.readSchemaResource(context.Background(), readResource("solr://collections/products/schema"))
+
+		assert.NoError(t, err)
+		assert.Len(t, res.Contents, 1)
+		assert.Equal(t, "application/json", res.Contents[0].MIMEType)
+		var fc types.FieldCatalog
+		assert.NoError(t, json.Unmarshal([]byte(res.Contents[0].Text), &fc))
+		assert.Equal(t, "id", fc.UniqueKey)
+	})
+
+	// Goal: Malformed URIs are reported as not found.
+	t.Run("Error: malformed URI", func(t *testing.T) {
+		_, err := st.readSchemaResource(context.Background(), readResource("solr://collections//schema"))
+		assert.Error(t, err)
+	})
+}
+
+// TestAddResources tests the AddResources function.
+func TestAddResources(t *testing.T) {
+	st := newTestState(t, "http://localhost:8983")
+
+	names := AddResources(mcp.NewServer(&mcp.Implementation{}, nil), st)
+
+	assert.Equal(t, []string{"solr.schema", "solr.errors"}, names)
+}
@@ -0,0 +1,67 @@
+package server
+
+import (
+	"context"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/retention"
+	"github.com/Sashimimochi/solr-mcp-go/internal/types"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// retentionManager returns the retention manager, creating it on first use.
+func (st *State) retentionManager() *retention.Manager {
+	st.retentionOnce.Do(func() {
+		st.retention = retention.NewManager(st.fileConfig().Retention, st.backend())
+	})
+	return st.retention
+}
+
+func (st *State) toolRetentionList(ctx context.Context, _ *mcp.CallToolRequest, in types.RetentionIn) (*mcp.CallToolResult, any, error) {
+	if e := st.CapabilityError("retention"); e != nil {
+		return nil, nil, e
+	}
+	m := st.retentionManager()
+	return nil, map[string]any{
+		"policies": m.Policies(),
+		"interval": st.fileConfig().Retention.Interval,
+		"history":  m.History(),
+	}, nil
+}
+
+func (st *State) toolRetentionPreview(ctx context.Context, _ *mcp.CallToolRequest, in types.RetentionIn) (*mcp.CallToolResult, any, error) {
+	if e := st.CapabilityError("retention"); e != nil {
+		return nil, nil, e
+	}
+	run, err := st.retentionManager().Preview(ctx, in.Policy)
+	if err != nil {
+		return nil, nil, err
+	}
+	return nil, run, nil
+}
+
+func (st *State) toolRetentionRun(ctx context.Context, _ *mcp.CallToolRequest, in types.RetentionIn) (*mcp.CallToolResult, any, error) {
+	if e := st.CapabilityError("retention"); e != nil {
+		return nil, nil, e
+	}
+	token := in.Confirm
+	in.Confirm = ""
+	if token == "" {
+		preview, err := st.retentionManager().Preview(ctx, in.Policy)
+		if err != nil {
+			return nil, nil, err
+		}
+		out, err := st.confirmationRequired("solr.retention.run", in, preview)
+		return nil, out, err
+	}
+	if err := st.confirmStore().consume(token, "solr.retention.run", in); err != nil {
+		return nil, nil, err
+	}
+
+	run, err := st.retentionManager().Execute(ctx, in.Policy, "tool")
+	st.invalidateCollections()
+	if err != nil {
+		return nil, nil, err
+	}
+	return nil, run, nil
+}
@@ -0,0 +1,70 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/config"
+	"github.com/Sashimimochi/solr-mcp-go/internal/retention"
+	"github.com/Sashimimochi/solr-mcp-go/internal/types"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestRetentionTools tests the solr.retention.* tools against a mock Solr.
+func TestRetentionTools(t *testing.T) {
+	var deletes []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/solr/logs/select":
+			fmt.Fprintln(w, `{"response":{"numFound":7,"docs":[]}}`)
+		case "/solr/logs/update":
+			body, _ := io.ReadAll(r.Body)
+			deletes = append(deletes, string(body))
+			fmt.Fprintln(w, `{"responseHeader":{"status":0}}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	// Goal: Without policies the tools return a capability error.
+	st := newTestState(t, server.URL)
+	_, _, err := st.toolRetentionPreview(context.Background(), nil, types.RetentionIn{})
+	var capErr *CapabilityError
+	assert.ErrorAs(t, err, &capErr)
+	assert.Equal(t, "retention", capErr.Capability)
+
+	st = newTestState(t, server.URL)
+	st.Config = &config.FileConfig{Retention: config.RetentionConfig{Policies: []config.RetentionPolicy{
+		{Name: "app_logs", Collection: "logs", DateField: "timestamp", MaxAge: "30d"},
+	}}}
+
+	// Goal: A preview reports the matching documents without deleting them.
+	_, out, err := st.toolRetentionPreview(context.Background(), nil, types.RetentionIn{Policy: "app_logs"})
+	assert.NoError(t, err)
+	run := out.(retention.Run)
+	assert.True(t, run.DryRun)
+	assert.Equal(t, int64(7), run.Actions[0].Matched)
+	assert.Empty(t, deletes)
+
+	// Goal: A run without confirm only returns a preview and a token.
+	_, out, err = st.toolRetentionRun(context.Background(), nil, types.RetentionIn{Policy: "app_logs"})
+	assert.NoError(t, err)
+	token := out.(map[string]any)["confirmationToken"].(string)
+	assert.Empty(t, deletes)
+
+	// Goal: A confirmed run deletes the documents and the previews and the run appear in the history.
+	_, out, err = st.toolRetentionRun(context.Background(), nil, types.RetentionIn{Policy: "app_logs", Confirm: token})
+	assert.NoError(t, err)
+	assert.True(t, out.(retention.Run).Actions[0].Executed)
+	assert.Len(t, deletes, 1)
+
+	_, out, err = st.toolRetentionList(context.Background(), nil, types.RetentionIn{})
+	assert.NoError(t, err)
+	assert.Len(t, out.(map[string]any)["history"], 3)
+}
@@ -0,0 +1,88 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+	"net/url"
+	"time"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/config"
+	"github.com/Sashimimochi/solr-mcp-go/internal/failover"
+	"github.com/Sashimimochi/solr-mcp-go/internal/routing"
+	"github.com/Sashimimochi/solr-mcp-go/internal/solr"
+)
+
+// nodeDiscoveryInterval is how often the live nodes of the cluster are read when DiscoverNodes is set.
+const nodeDiscoveryInterval = time.Minute
+
+// nodeRouter returns the router of solr.query requests, or nil when no further nodes are configured or discovered
+// or a custom backend replaces the Solr HTTP APIs.
+func (st *State) nodeRouter() *routing.Router {
+	if (len(st.Nodes) == 0 && !st.DiscoverNodes) || st.Backend != nil {
+		return nil
+	}
+	st.routerOnce.Do(func() {
+		st.router = routing.New(append([]string{st.BaseURL}, st.Nodes...))
+	})
+	return st.router
+}
+
+// discoverNodes adds the live nodes of the primary cluster to r on every interval until ctx is done.
+func (st *State) discoverNodes(ctx context.Context, r *routing.Router, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		b := &solr.HTTPBackend{HttpClient: st.HttpClient, BaseURL: st.BaseURL, User: st.BasicUser, Pass: st.BasicPass}
+		if status, err := b.ClusterStatus(ctx, ""); err != nil {
+			slog.Warn("Failed to discover Solr nodes", "error", err)
+		} else {
+			live := routing.LiveNodeURLs(st.BaseURL, status.Cluster.LiveNodes)
+			r.SetNodes(append(append([]string{st.BaseURL}, st.Nodes...), live...))
+			slog.Debug("Discovered Solr nodes", "live_nodes", len(live))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// routedQuery runs a read query on the node picked by the router and reports its latency. When the node is
+// unavailable, the query is retried once on the next best node. Without a router, or after failing over to
+// the standby, the query goes to the active cluster. It also returns the base URL of the node that answered.
+func (st *State) routedQuery(ctx context.Context, collection string, params url.Values) (map[string]any, string, error) {
+	r := st.nodeRouter()
+	if r == nil || st.solrURL() != st.BaseURL {
+		node := st.solrURL()
+		resp, err := st.backend().Query(ctx, collection, params)
+		return resp, node, err
+	}
+	var tried []string
+	var lastErr error
+	for len(tried) < 2 {
+		node := r.Pick(tried...)
+		if node == "" {
+			break
+		}
+		b := &solr.HTTPBackend{HttpClient: st.HttpClient, BaseURL: node, User: st.BasicUser, Pass: st.BasicPass, Cache: &st.SchemaCache}
+		start := time.Now()
+		resp, err := b.Query(ctx, collection, params)
+		if failover.Canceled(err) {
+			// The caller gave up, which says nothing about the latency or health of the node
+			return nil, node, err
+		}
+		if !failover.Unavailable(err) {
+			r.Report(node, time.Since(start), nil)
+			return resp, node, err
+		}
+		r.Report(node, time.Since(start), err)
+		slog.Warn("Solr node unavailable for a query", "node", config.RedactURL(node), "error", err)
+		tried = append(tried, node)
+		lastErr = err
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return nil, "", lastErr
+}
@@ -0,0 +1,64 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/routing"
+	"github.com/Sashimimochi/solr-mcp-go/internal/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRoutedQuery tests spreading solr.query over the nodes and avoiding unavailable ones.
+func TestRoutedQuery(t *testing.T) {
+	var downHits, upHits atomic.Int64
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		downHits.Add(1)
+		http.Error(w, "Service Unavailable", http.StatusServiceUnavailable)
+	}))
+	defer down.Close()
+	up := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/solr/products/select" {
+			upHits.Add(1)
+			fmt.Fprintln(w, `{"response":{"numFound":1,"docs":[{"id":"1"}]}}`)
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	defer up.Close()
+
+	st := NewServer(WithSolrURL(down.URL), WithHTTPClient(&http.Client{}), WithNodes([]string{up.URL, " "}, false))
+	st.nodeRouter().Explore = 0
+	ctx := context.Background()
+
+	// Goal: A query on an unavailable node is retried on the next node.
+	_, out, err := st.toolQuery(ctx, nil, types.QueryIn{Collection: "products", Query: "*:*"})
+	require.NoError(t, err)
+	assert.NotNil(t, out)
+	assert.Equal(t, int64(1), downHits.Load())
+
+	// Goal: Once measured, the healthy node is preferred and the unavailable one is not queried again.
+	for range 3 {
+		_, _, err = st.toolQuery(ctx, nil, types.QueryIn{Collection: "products", Query: "*:*"})
+		require.NoError(t, err)
+	}
+	assert.Equal(t, int64(1), downHits.Load())
+	assert.Equal(t, int64(4), upHits.Load())
+
+	// Goal: The node statistics are reported by solr.info.
+	_, info, err := st.toolInfo(ctx, nil, types.InfoIn{})
+	require.NoError(t, err)
+	nodes := info.(map[string]any)["nodes"].([]routing.NodeStats)
+	require.Len(t, nodes, 2)
+	assert.Equal(t, int64(1), nodes[0].Errors)
+	assert.True(t, nodes[1].Preferred)
+
+	// Goal: Without further nodes, queries are not routed.
+	assert.Nil(t, newTestState(t, up.URL).nodeRouter())
+}
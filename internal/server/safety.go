@@ -0,0 +1,91 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/config"
+	"github.com/Sashimimochi/solr-mcp-go/internal/solr"
+	"github.com/Sashimimochi/solr-mcp-go/internal/types"
+)
+
+// expensiveTermReasons explain why each kind of expensive term is a risk.
+var expensiveTermReasons = map[string]string{
+	solr.LeadingWildcard: "a leading wildcard compares every term of the field",
+	solr.UnboundedRegex:  "a regular expression starting with .* compares every term of the field",
+	solr.WideFuzzy:       fmt.Sprintf("an edit distance of 2 on a term of up to %d characters matches most terms of the field", solr.ShortFuzzyTerm),
+}
+
+// riskyTerms returns the expensive terms of query on fields with more distinct values than the limit, or whose
+// distinct values are unknown, e.g. text fields and the default field.
+func (st *State) riskyTerms(ctx context.Context, collection, query string, limit int64) []solr.ExpensiveTerm {
+	var risky []solr.ExpensiveTerm
+	for _, t := range solr.FindExpensiveTerms(query) {
+		if t.Field != "" {
+			if fs, err := st.fieldStats(ctx, collection, t.Field); err == nil {
+				if fs.Cardinality <= limit {
+					continue
+				}
+				t.Expansion = &fs.Cardinality
+			}
+		}
+		risky = append(risky, t)
+	}
+	return risky
+}
+
+// querySafety looks for expensive wildcard, regex and fuzzy terms in the q and fq of the final request parameters,
+// so that terms passed through params or set by an experiment variant are checked too. In rewrite mode, they are
+// replaced by bounded terms in place and reported as guardrails. Otherwise the terms are returned, and the caller
+// asks for confirmation before running the query.
+func (st *State) querySafety(ctx context.Context, collection string, params url.Values) ([]solr.ExpensiveTerm, []types.Guardrail) {
+	sc := st.fileConfig().QuerySafety
+	if sc.SafetyMode() == config.SafetyOff {
+		return nil, nil
+	}
+	limit := sc.ExpansionLimit()
+	var all []solr.ExpensiveTerm
+	var guardrails []types.Guardrail
+	check := func(target string, query *string) {
+		terms := st.riskyTerms(ctx, collection, *query, limit)
+		if len(terms) == 0 {
+			return
+		}
+		if sc.SafetyMode() != config.SafetyRewrite {
+			all = append(all, terms...)
+			return
+		}
+		*query = solr.RewriteExpensiveTerms(*query, terms)
+		for _, t := range terms {
+			reason := expensiveTermReasons[t.Kind]
+			if t.Expansion != nil {
+				reason += fmt.Sprintf(" (about %d distinct values)", *t.Expansion)
+			}
+			guardrails = append(guardrails, types.Guardrail{Action: "rewrite_query", Target: target, Requested: t.Term, Applied: t.Rewrite, Reason: reason})
+		}
+		slog.Info("Rewrote expensive query terms", "collection", collection, "target", target, "terms", len(terms))
+	}
+	for i := range params["q"] {
+		check("query", &params["q"][i])
+	}
+	for i := range params["fq"] {
+		check("fq", &params["fq"][i])
+	}
+	return all, guardrails
+}
+
+// expensiveQueryPreview describes the expensive terms of a query waiting for confirmation.
+func expensiveQueryPreview(in types.QueryIn, terms []solr.ExpensiveTerm) map[string]any {
+	reasons := make([]string, len(terms))
+	for i, t := range terms {
+		reasons[i] = t.Term + ": " + expensiveTermReasons[t.Kind]
+	}
+	return map[string]any{
+		"collection":     in.Collection,
+		"expensiveTerms": terms,
+		"reasons":        reasons,
+		"hint":           "Expensive terms routinely overload Solr. Prefer the rewrite of each term, or confirm to run the query as written.",
+	}
+}
@@ -0,0 +1,99 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/config"
+	"github.com/Sashimimochi/solr-mcp-go/internal/solr"
+	"github.com/Sashimimochi/solr-mcp-go/internal/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestQuerySafety tests confirming and rewriting solr.query requests with expensive terms.
+func TestQuerySafety(t *testing.T) {
+	var queries []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/solr/products/schema/uniquekey":
+			fmt.Fprintln(w, `{"uniqueKey":"id"}`)
+		case "/solr/products/schema/fields":
+			fmt.Fprintln(w, `{"fields":[{"name":"id","type":"string"},{"name":"title","type":"text_general"},{"name":"sku","type":"string"},{"name":"color","type":"string"}]}`)
+		case "/solr/products/select":
+			if r.URL.Query().Get("stats") == "true" {
+				fmt.Fprintln(w, `{"stats":{"stats_fields":{"sku":{"count":50000,"cardinality":50000},"color":{"count":50000,"cardinality":8}}}}`)
+				return
+			}
+			queries = append(queries, r.URL.Query().Get("q"))
+			fmt.Fprintln(w, `{"response":{"numFound":1,"docs":[{"id":"1"}]}}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	ctx := context.Background()
+	st := newTestState(t, server.URL)
+
+	// Goal: By default, queries run unchecked.
+	_, _, err := st.toolQuery(ctx, nil, types.QueryIn{Collection: "products", Query: "title:*phone"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"title:*phone"}, queries)
+
+	// Goal: In confirm mode, a query with expensive terms only runs with a confirmation token.
+	queries = nil
+	st.Config = &config.FileConfig{QuerySafety: config.QuerySafetyConfig{Mode: config.SafetyConfirm}}
+	in := types.QueryIn{Collection: "products", Query: "title:*phone AND sku:/.*42/"}
+	_, out, err := st.toolQuery(ctx, nil, in)
+	require.NoError(t, err)
+	confirmation := out.(map[string]any)
+	terms := confirmation["preview"].(map[string]any)["expensiveTerms"].([]solr.ExpensiveTerm)
+	require.Len(t, terms, 2)
+	assert.Nil(t, terms[0].Expansion)
+	assert.Equal(t, int64(50000), *terms[1].Expansion)
+	assert.Empty(t, queries)
+
+	in.Confirm = confirmation["confirmationToken"].(string)
+	_, _, err = st.toolQuery(ctx, nil, in)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"title:*phone AND sku:/.*42/"}, queries)
+	_, _, err = st.toolQuery(ctx, nil, in)
+	assert.ErrorIs(t, err, errConfirmationInvalid)
+
+	// Goal: Terms passed through params are checked as well.
+	_, out, err = st.toolQuery(ctx, nil, types.QueryIn{Collection: "products", Params: map[string]any{"q": "title:*phone", "fq": []string{"sku:/.*42/"}}})
+	require.NoError(t, err)
+	assert.Len(t, out.(map[string]any)["preview"].(map[string]any)["expensiveTerms"], 2)
+
+	// Goal: Expensive terms on fields with few distinct values run unchanged.
+	_, out, err = st.toolQuery(ctx, nil, types.QueryIn{Collection: "products", Query: "color:*ed"})
+	require.NoError(t, err)
+	assert.Contains(t, out.(map[string]any), "response")
+
+	// Goal: Plans list the expensive terms without asking for confirmation.
+	_, out, err = st.toolQuery(ctx, nil, types.QueryIn{Collection: "products", Query: "title:ab~2", PlanOnly: true})
+	require.NoError(t, err)
+	assert.Len(t, out.(map[string]any)["expensiveTerms"], 1)
+
+	// Goal: In rewrite mode, expensive terms of the query and filters are replaced and reported as guardrails.
+	queries = nil
+	st.Config = &config.FileConfig{QuerySafety: config.QuerySafetyConfig{Mode: config.SafetyRewrite}}
+	_, out, err = st.toolQuery(ctx, nil, types.QueryIn{Collection: "products", Query: "title:*phone*", FilterQuery: []string{"sku:/.*42/"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"title:phone*"}, queries)
+	guardrails := out.(map[string]any)["guardrails"].([]types.Guardrail)
+	require.Len(t, guardrails, 2)
+	assert.Equal(t, types.Guardrail{Action: "rewrite_query", Target: "fq", Requested: "sku:/.*42/", Applied: "sku:/42/",
+		Reason: "a regular expression starting with .* compares every term of the field (about 50000 distinct values)"}, guardrails[1])
+
+	// Goal: The check can be turned off.
+	queries = nil
+	st.Config = &config.FileConfig{QuerySafety: config.QuerySafetyConfig{Mode: config.SafetyOff}}
+	_, _, err = st.toolQuery(ctx, nil, types.QueryIn{Collection: "products", Query: "title:*phone"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"title:*phone"}, queries)
+}
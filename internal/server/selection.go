@@ -0,0 +1,29 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/solr"
+	"github.com/Sashimimochi/solr-mcp-go/internal/types"
+)
+
+// selectFields compiles the field selection of a solr.query input into fl values.
+func (st *State) selectFields(ctx context.Context, in types.QueryIn) ([]string, error) {
+	if len(in.Fields) > 0 {
+		return nil, errors.New("use either fl or select")
+	}
+	sel, err := solr.ParseSelection(in.Select)
+	if err != nil {
+		return nil, err
+	}
+	if sel.HasTransformers() && st.Backend != nil {
+		return nil, errors.New("document transformers need the Solr backend")
+	}
+	fc, err := st.backend().Schema(ctx, in.Collection)
+	if err != nil {
+		return nil, fmt.Errorf("schema needed to check the selected fields: %v", err)
+	}
+	return sel.FieldList(fc)
+}
@@ -1,51 +1,280 @@
 package server
 
 import (
+	"context"
 	"log/slog"
 	"net/http"
 	"os"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
-	"solr-mcp-go/internal/config"
-	"solr-mcp-go/internal/types"
-	"solr-mcp-go/internal/utils"
+	"github.com/Sashimimochi/solr-mcp-go/internal/backend"
+	"github.com/Sashimimochi/solr-mcp-go/internal/chaos"
+	"github.com/Sashimimochi/solr-mcp-go/internal/coalesce"
+	"github.com/Sashimimochi/solr-mcp-go/internal/config"
+	"github.com/Sashimimochi/solr-mcp-go/internal/drift"
+	"github.com/Sashimimochi/solr-mcp-go/internal/errcatalog"
+	"github.com/Sashimimochi/solr-mcp-go/internal/experiment"
+	"github.com/Sashimimochi/solr-mcp-go/internal/failover"
+	"github.com/Sashimimochi/solr-mcp-go/internal/feedback"
+	"github.com/Sashimimochi/solr-mcp-go/internal/governor"
+	"github.com/Sashimimochi/solr-mcp-go/internal/jobs"
+	"github.com/Sashimimochi/solr-mcp-go/internal/maintenance"
+	"github.com/Sashimimochi/solr-mcp-go/internal/metrics"
+	"github.com/Sashimimochi/solr-mcp-go/internal/policy"
+	"github.com/Sashimimochi/solr-mcp-go/internal/postprocess"
+	"github.com/Sashimimochi/solr-mcp-go/internal/replay"
+	"github.com/Sashimimochi/solr-mcp-go/internal/retention"
+	"github.com/Sashimimochi/solr-mcp-go/internal/routing"
+	"github.com/Sashimimochi/solr-mcp-go/internal/solr"
+	"github.com/Sashimimochi/solr-mcp-go/internal/transform"
+	"github.com/Sashimimochi/solr-mcp-go/internal/types"
+	"github.com/Sashimimochi/solr-mcp-go/internal/usage"
+	"github.com/Sashimimochi/solr-mcp-go/internal/utils"
+	"github.com/Sashimimochi/solr-mcp-go/internal/watchdog"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
-	solr "github.com/stevenferrer/solr-go"
 )
 
 type State struct {
-	SolrClient        *solr.JSONClient
+	// Backend performs the search engine operations of the tools. The Solr HTTP APIs are used when it is nil.
+	// Replica-level diagnostics, archiving and saved queries always use the Solr HTTP APIs.
+	Backend           backend.SearchBackend
 	BaseURL           string
 	DefaultCollection string
 	HttpClient        *http.Client
 	BasicUser         string
 	BasicPass         string
 	SchemaCache       types.SchemaCache
+	Config            *config.FileConfig
+	ConfigPath        string
+	// ConfigReloadInterval is how often ConfigPath is checked for changes.
+	ConfigReloadInterval time.Duration
+	MaxRequestBytes      int
+	MaxToolArgsBytes     int
+	ConfirmationTTL      time.Duration
+	// ToolFilter, if set, only registers the tools for which it returns true.
+	ToolFilter func(name string) bool
+	// StandbyURL is the base URL of a warm standby (DR) Solr cluster, used after FailoverThreshold
+	// consecutive tool calls found the primary unavailable. The primary is probed every FailoverProbeInterval to fail back.
+	StandbyURL            string
+	StandbyWrites         bool // allow write tools on the standby
+	FailoverThreshold     int
+	FailoverProbeInterval time.Duration
+	// Nodes are further base URLs of the primary cluster that serve solr.query requests, picked by their
+	// latency and error rate. DiscoverNodes adds the live nodes reported by the cluster.
+	Nodes         []string
+	DiscoverNodes bool
+	// StatsFile, if set, persists tool usage statistics across restarts.
+	StatsFile string
+	// AdminAddr, if set, is the listen address of the operator control API, protected by AdminToken.
+	AdminAddr  string
+	AdminToken string
+	// DataDir, if set, receives manifest.json describing the tools, capabilities and guardrails at startup and on config reload,
+	// the schema and config snapshots of drift detection, the metrics of A/B experiments, click feedback and the
+	// checkpoints and files of background export and reindex jobs.
+	DataDir string
+	// Chaos, with a positive rate, fails a random share of Solr requests to test the retry and fallback behavior of agents.
+	Chaos chaos.Config
+	// Language is the language of the messages and hints of tool errors. The codes are the same in every language.
+	Language errcatalog.Lang
+	// RecordFile, if set, receives a fixture of all Solr requests and responses, which SOLR_MCP_REPLAY_FILE replays.
+	RecordFile string
+	replay     *replay.Fixture
+	openSearch *openSearchOptions // set by WithOpenSearch; NewServer creates Backend from it
+
+	configMu sync.RWMutex
+
+	toolMu        sync.Mutex
+	toolRegistry  map[string]func(*mcp.Server)
+	toolDefs      map[string]*mcp.Tool
+	toolOrder     []string
+	enabledTools  map[string]bool
+	customTools   []customTool
+	mcpServer     *mcp.Server
+	savedQueryRes map[string]config.SavedQuery
+	exporter      *metrics.Exporter
+	datasource    *metrics.Datasource
+
+	retentionOnce sync.Once
+	retention     *retention.Manager
+
+	driftOnce sync.Once
+	drift     *drift.Manager
+
+	maintenanceOnce sync.Once
+	maintenance     *maintenance.Manager
+
+	confirmOnce sync.Once
+	confirm     *confirmations
+
+	processorsOnce sync.Once
+	processors     *postprocess.Registry
+
+	transformersOnce sync.Once
+	transformers     *transform.Registry
+
+	experimentsOnce sync.Once
+	experimentsMgr  *experiment.Manager
+
+	feedbackOnce sync.Once
+	feedback     *feedback.Tracker
+
+	jobsOnce sync.Once
+	jobs     *jobs.Manager
+
+	usageOnce sync.Once
+	usage     *usage.Tracker
+
+	failoverOnce sync.Once
+	failover     *failover.Monitor
+
+	governorOnce sync.Once
+	governor     *governor.Governor
+
+	routerOnce sync.Once
+	router     *routing.Router
+
+	metricsHistoryOnce sync.Once
+	metricsHistory     *metrics.History
+
+	policyOnce sync.Once
+	policy     *policy.Engine
+
+	slowMu          sync.Mutex
+	baseline        *nodeBaseline
+	baselineRunning bool
+
+	sessions sessionTracker
+
+	queries coalesce.Group[servedQuery]
+
+	collectionsMu sync.Mutex
+	collections   *collectionCache
+
+	topValuesMu sync.Mutex
+	topValues   map[string]topValuesEntry
+
+	readOnly atomic.Bool
+	draining atomic.Bool
+
+	versionMu     sync.Mutex
+	version       *solr.Version
+	versionFailed time.Time
 }
 
+// NewServerState creates a State configured from environment variables and the SOLR_MCP_CONFIG_FILE config file.
 func NewServerState() *State {
-	client, baseURL, user, pass, httpClient := config.NewSolrClient()
-
-	st := &State{
-		SolrClient:        client,
-		BaseURL:           baseURL,
-		DefaultCollection: config.GetEnv("SOLR_MCP_DEFAULT_COLLECTION", "gettingstarted"),
-		HttpClient:        httpClient,
-		BasicUser:         user,
-		BasicPass:         pass,
-		SchemaCache: types.SchemaCache{
-			LastFetch: make(map[string]time.Time),
-			TTL:       10 * time.Minute,
-			ByCol:     make(map[string]*types.FieldCatalog),
-		},
+	_, baseURL, user, pass, httpClient := config.NewSolrClient()
+	// Pending Solr requests are reported after soft deadlines, well before the hard timeout of the client
+	deadlines := watchdog.DefaultDeadlines
+	if v := config.GetEnv("SOLR_MCP_SOFT_DEADLINES", ""); v != "" {
+		if d, err := watchdog.ParseDeadlines(v); err != nil {
+			slog.Error("Ignoring SOLR_MCP_SOFT_DEADLINES", "error", err)
+		} else {
+			deadlines = d
+		}
+	}
+	httpClient = watchdog.Wrap(httpClient, deadlines)
+
+	configPath := config.GetEnv("SOLR_MCP_CONFIG_FILE", "")
+	fileConfig, err := config.LoadFileConfig(configPath)
+	if err != nil {
+		slog.Error("Failed to load config file, continuing without it", "error", err)
+		fileConfig = &config.FileConfig{}
 	}
 
-	slog.Info("Configured Solr client", "base_url", baseURL, "default_collection", st.DefaultCollection)
+	opts := []Option{
+		WithSolrURL(baseURL),
+		WithBasicAuth(user, pass),
+		WithHTTPClient(httpClient),
+		WithDefaultCollection(config.GetEnv("SOLR_MCP_DEFAULT_COLLECTION", "gettingstarted")),
+		WithConfig(fileConfig, configPath),
+		WithRequestLimits(
+			config.GetEnvInt("SOLR_MCP_MAX_REQUEST_BYTES", 10<<20),
+			config.GetEnvInt("SOLR_MCP_MAX_TOOL_ARGS_BYTES", 1<<20),
+		),
+		WithStatsFile(config.GetEnv("SOLR_MCP_STATS_FILE", "")),
+		WithDataDir(config.GetEnv("SOLR_MCP_DATA_DIR", "")),
+		WithStandby(config.GetEnv("SOLR_MCP_STANDBY_URL", ""), config.GetEnv("SOLR_MCP_STANDBY_ALLOW_WRITES", "false") == "true"),
+		WithNodes(strings.Split(config.GetEnv("SOLR_MCP_SOLR_NODES", ""), ","), config.GetEnv("SOLR_MCP_DISCOVER_NODES", "false") == "true"),
+	}
+	if path := config.GetEnv("SOLR_MCP_REPLAY_FILE", ""); path != "" {
+		f, err := replay.Load(path)
+		if err != nil {
+			// Falling back to the real cluster would defeat the purpose of a replay
+			slog.Error("Failed to load the replay fixture", "error", err)
+			os.Exit(1)
+		}
+		opts = append(opts, WithReplay(f))
+	} else if path := config.GetEnv("SOLR_MCP_RECORD_FILE", ""); path != "" {
+		opts = append(opts, WithRecording(path))
+	}
+	if rate, err := strconv.ParseFloat(config.GetEnv("SOLR_MCP_CHAOS_RATE", "0"), 64); err != nil || rate < 0 || rate > 1 {
+		slog.Error("Ignoring SOLR_MCP_CHAOS_RATE, expected a number from 0 to 1", "value", config.GetEnv("SOLR_MCP_CHAOS_RATE", ""))
+	} else if rate > 0 {
+		faults, err := chaos.ParseFaults(config.GetEnv("SOLR_MCP_CHAOS_FAULTS", ""))
+		if err != nil {
+			slog.Error("Ignoring SOLR_MCP_CHAOS_FAULTS", "error", err)
+			faults = chaos.Faults
+		}
+		timeout, err := time.ParseDuration(config.GetEnv("SOLR_MCP_CHAOS_TIMEOUT", chaos.DefaultTimeout.String()))
+		if err != nil {
+			timeout = chaos.DefaultTimeout
+		}
+		opts = append(opts, WithChaos(chaos.Config{Rate: rate, Faults: faults, Timeout: timeout}))
+	}
+	if addr := config.GetEnv("SOLR_MCP_ADMIN_ADDR", ""); addr != "" {
+		token, err := config.GetSecret(context.Background(), "SOLR_MCP_ADMIN_TOKEN")
+		if err != nil {
+			slog.Error("Failed to load SOLR_MCP_ADMIN_TOKEN", "error", err)
+		}
+		opts = append(opts, WithAdmin(addr, token))
+	}
+	if ttl, err := time.ParseDuration(config.GetEnv("SOLR_MCP_CONFIRMATION_TTL", "5m")); err == nil && ttl > 0 {
+		opts = append(opts, WithConfirmationTTL(ttl))
+	}
+	if lang, err := errcatalog.ParseLang(config.GetEnv("SOLR_MCP_LANGUAGE", "")); err != nil {
+		slog.Error("Ignoring SOLR_MCP_LANGUAGE", "error", err)
+	} else {
+		opts = append(opts, WithLanguage(lang))
+	}
+	if strings.EqualFold(config.GetEnv("SOLR_MCP_BACKEND", "solr"), "opensearch") {
+		opts = append(opts, WithOpenSearch(baseURL, user, pass, httpClient))
+	}
+	st := NewServer(opts...)
+	if interval, err := time.ParseDuration(config.GetEnv("SOLR_MCP_CONFIG_RELOAD_INTERVAL", "10s")); err == nil && interval > 0 {
+		st.ConfigReloadInterval = interval
+	}
+	st.FailoverThreshold = config.GetEnvInt("SOLR_MCP_FAILOVER_THRESHOLD", st.FailoverThreshold)
+	if interval, err := time.ParseDuration(config.GetEnv("SOLR_MCP_FAILOVER_PROBE_INTERVAL", "30s")); err == nil && interval > 0 {
+		st.FailoverProbeInterval = interval
+	}
+
+	slog.Info("Configured Solr client", "base_url", config.RedactURL(st.BaseURL), "default_collection", st.DefaultCollection, "backend", config.GetEnv("SOLR_MCP_BACKEND", "solr"))
 	return st
 }
 
+// NewExporter creates a Prometheus exporter evaluating the configured saved queries.
+// It returns nil when exporter mode is disabled. With node routing, the exporter also exposes the node statistics.
+func (st *State) NewExporter() *metrics.Exporter {
+	fc := st.fileConfig()
+	if fc == nil || !fc.Exporter.Enabled {
+		return nil
+	}
+	st.exporter = metrics.NewExporter(fc.SavedQueries, fc.Exporter.IntervalDuration(),
+		func(ctx context.Context, q config.SavedQuery) (float64, error) {
+			return solr.EvaluateSavedQuery(ctx, st.HttpClient, st.solrURL(), st.BasicUser, st.BasicPass, q)
+		})
+	if r := st.nodeRouter(); r != nil {
+		st.exporter.AddSource(r.Render)
+	}
+	return st.exporter
+}
+
 // AIAgentCompatibilityMiddleware wraps the MCP handler to handle AI agent-specific HTTP patterns
 type AIAgentCompatibilityMiddleware struct {
 	mcpHandler http.Handler
@@ -122,16 +351,46 @@ func (rw *responseWrapper) Write(data []byte) (int, error) {
 	return rw.ResponseWriter.Write(data)
 }
 
-func Run(url string) {
-	st := NewServerState()
+// NewDatasource creates a Grafana JSON datasource serving the configured saved queries.
+// It returns nil when the datasource endpoint is disabled.
+func (st *State) NewDatasource() *metrics.Datasource {
+	fc := st.fileConfig()
+	if fc == nil || !fc.Datasource.Enabled {
+		return nil
+	}
+	st.datasource = metrics.NewDatasource(fc.SavedQueries,
+		func(ctx context.Context, q config.SavedQuery) (float64, error) {
+			return solr.EvaluateSavedQuery(ctx, st.HttpClient, st.solrURL(), st.BasicUser, st.BasicPass, q)
+		},
+		func(ctx context.Context, q config.SavedQuery, from, to time.Time, gap time.Duration) ([]solr.SeriesPoint, error) {
+			return solr.EvaluateSavedQuerySeries(ctx, st.HttpClient, st.solrURL(), st.BasicUser, st.BasicPass, q, from, to, gap)
+		})
+	return st.datasource
+}
 
+// NewMCPServer creates the MCP server with all tools, prompts and resources registered.
+func (st *State) NewMCPServer() *mcp.Server {
 	mcpServer := mcp.NewServer(&mcp.Implementation{
 		Name:    "solr-mcp-go",
 		Version: config.Version,
-	}, nil)
+	}, &mcp.ServerOptions{
+		CompletionHandler: st.Complete,
+	})
 
-	toolNames := AddTools(mcpServer, st)
+	mcpServer.AddReceivingMiddleware(st.toolArgumentLimitMiddleware(st.MaxToolArgsBytes), st.sessionActivityMiddleware)
+	AddTools(mcpServer, st)
+	promptNames := AddPrompts(mcpServer, st)
+	resourceNames := AddResources(mcpServer, st)
+
+	slog.Info("Available tools", "tools", strings.Join(st.EnabledToolNames(), ", "))
+	slog.Info("Available prompts", "prompts", strings.Join(promptNames, ", "))
+	slog.Info("Available resources", "resources", strings.Join(resourceNames, ", "))
+	return mcpServer
+}
 
+// Handler returns the HTTP handler serving mcpServer on "/" and the optional /metrics and /datasource endpoints.
+// It starts the background jobs (exporter, scheduled retention and maintenance, config reload), which run until ctx is done.
+func (st *State) Handler(ctx context.Context, mcpServer *mcp.Server) http.Handler {
 	// Create MCP Streamable HTTP handler
 	mcpHandler := mcp.NewStreamableHTTPHandler(func(req *http.Request) *mcp.Server {
 		return mcpServer
@@ -142,14 +401,115 @@ func Run(url string) {
 		mcpHandler: mcpHandler,
 	}
 
+	mux := http.NewServeMux()
+	mux.Handle("/", st.drainHandler(utils.MaxBytesHandler(aiAgentCompatHandler, int64(st.MaxRequestBytes))))
+
+	// Optional Prometheus exporter mode
+	if exporter := st.NewExporter(); exporter != nil {
+		go exporter.Run(ctx)
+		mux.Handle("/metrics", exporter)
+		slog.Info("Prometheus exporter enabled", "path", "/metrics", "saved_queries", len(st.fileConfig().SavedQueries))
+	} else {
+		mux.Handle("/metrics", st.capabilityErrorHandler("prometheus_exporter"))
+	}
+
+	// Optional Grafana JSON datasource
+	if ds := st.NewDatasource(); ds != nil {
+		dsHandler := http.StripPrefix("/datasource", ds)
+		mux.Handle("/datasource", dsHandler)
+		mux.Handle("/datasource/", dsHandler)
+		slog.Info("Grafana JSON datasource enabled", "path", "/datasource")
+	} else {
+		mux.Handle("/datasource", st.capabilityErrorHandler("grafana_datasource"))
+		mux.Handle("/datasource/", st.capabilityErrorHandler("grafana_datasource"))
+	}
+
+	// Scrape the solr-exporter into the metric history of solr.metrics.history
+	if sc := st.fileConfig().SolrExporter; sc.URL != "" {
+		go metrics.RunScraper(ctx, st.HttpClient, st.solrMetricsHistory(), sc.IntervalDuration(), func() config.SolrExporterConfig {
+			return st.fileConfig().SolrExporter
+		})
+		slog.Info("solr-exporter scraping enabled", "url", config.RedactURL(sc.URL), "interval", sc.IntervalDuration(), "retention", sc.RetentionDuration())
+	}
+
+	// Scheduled retention runs
+	if st.fileConfig().Retention.Interval != "" {
+		go st.retentionManager().RunScheduled(ctx)
+		slog.Info("Scheduled retention enabled", "interval", st.fileConfig().Retention.Interval)
+	}
+
+	// Scheduled schema and config snapshots
+	if interval := st.fileConfig().Drift.Interval; interval != "" {
+		if st.DataDir == "" {
+			slog.Warn("Drift detection needs SOLR_MCP_DATA_DIR to store snapshots, scheduled snapshots are disabled")
+		} else {
+			go st.driftManager().RunScheduled(ctx)
+			slog.Info("Scheduled drift detection enabled", "interval", interval, "collections", st.driftManager().Collections())
+		}
+	}
+
+	// Maintenance in its nightly window
+	if window := st.fileConfig().Maintenance.Window; window != "" {
+		go st.maintenanceManager().RunScheduled(ctx)
+		slog.Info("Scheduled maintenance enabled", "window", window, "timezone", st.fileConfig().Maintenance.Timezone)
+	}
+
+	// Probe the primary Solr to fail back from the standby
+	if m := st.failoverMonitor(); m != nil {
+		go m.Run(ctx, st.FailoverProbeInterval)
+		slog.Info("Standby failover enabled", "standby", config.RedactURL(st.StandbyURL), "threshold", st.FailoverThreshold)
+	}
+
+	// Discover the live nodes that solr.query requests are routed to
+	if r := st.nodeRouter(); r != nil {
+		if st.DiscoverNodes {
+			go st.discoverNodes(ctx, r, nodeDiscoveryInterval)
+		}
+		slog.Info("Latency-aware node routing enabled", "nodes", len(r.Stats()), "discover", st.DiscoverNodes)
+	}
+
+	// Persist usage statistics
+	if st.StatsFile != "" {
+		go st.saveUsageStats(ctx, time.Minute)
+	}
+
+	// Persist A/B experiment metrics and click feedback
+	if st.DataDir != "" {
+		go saveEvery(ctx, time.Minute, st.saveExperiments, st.saveFeedback)
+	}
+
+	// Resume background jobs interrupted by the last shutdown
+	if st.DataDir != "" {
+		st.resumeJobs(ctx)
+	}
+
+	// Hot-reload the config file
+	if st.ConfigPath != "" {
+		go config.WatchFileConfig(ctx, st.ConfigPath, st.ConfigReloadInterval, func(fc *config.FileConfig) {
+			st.ApplyConfig(mcpServer, fc)
+			st.saveManifest()
+		})
+	}
+
 	// Add logging middleware
-	handlerWithLogging := utils.LoggingHandler(aiAgentCompatHandler)
+	return utils.LoggingHandler(mux)
+}
+
+func Run(url string) {
+	st := NewServerState()
+	mcpServer := st.NewMCPServer()
+	handler := st.Handler(context.Background(), mcpServer)
 
 	slog.Info("MCP server listening", "address", url)
-	slog.Info("Available tools", "tools", strings.Join(toolNames, ", "))
 	slog.Info("AI agent compatibility mode enabled")
+	st.LogCapabilities()
+	st.DetectSolrVersion(context.Background())
+	st.LogRecommendations()
+	st.startAdmin(mcpServer)
+	st.LogBanner(url)
+	st.saveManifest()
 
-	if err := http.ListenAndServe(url, handlerWithLogging); err != nil {
+	if err := http.ListenAndServe(url, handler); err != nil {
 		slog.Error("Error running MCP server", "error", err)
 		os.Exit(1)
 	}
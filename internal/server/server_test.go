@@ -1,7 +1,21 @@
 package server
 
 import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
 	"testing"
+	"time"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/chaos"
+	"github.com/Sashimimochi/solr-mcp-go/internal/opensearch"
+	"github.com/Sashimimochi/solr-mcp-go/internal/replay"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // TestNewServerState tests the NewServerState function.
@@ -27,3 +41,70 @@ func TestNewServerState(t *testing.T) {
 		}
 	})
 }
+
+// TestNewServer tests that NewServer applies defaults and options without reading the environment.
+func TestNewServer(t *testing.T) {
+	// Goal: Defaults match those of NewServerState.
+	st := NewServer()
+	assert.Equal(t, "http://localhost:8983", st.BaseURL)
+	assert.Equal(t, "gettingstarted", st.DefaultCollection)
+	assert.Equal(t, 10*time.Minute, st.SchemaCache.TTL)
+
+	// Goal: Options override the defaults.
+	httpClient := &http.Client{}
+	st = NewServer(
+		WithSolrURL("http://solr:8983/"),
+		WithBasicAuth("solr", "secret"),
+		WithHTTPClient(httpClient),
+		WithDefaultCollection("products"),
+		WithSchemaCacheTTL(time.Minute),
+		WithRequestLimits(1024, 0),
+	)
+	assert.Equal(t, "http://solr:8983", st.BaseURL)
+	assert.Equal(t, "solr", st.BasicUser)
+	assert.Equal(t, "secret", st.BasicPass)
+	assert.Same(t, httpClient, st.HttpClient)
+	assert.Equal(t, "products", st.DefaultCollection)
+	assert.Equal(t, time.Minute, st.SchemaCache.TTL)
+	assert.Equal(t, 1024, st.MaxRequestBytes)
+	assert.Equal(t, 0, st.MaxToolArgsBytes)
+
+	// Goal: The tool filter disables tools in addition to the config file.
+	st = NewServer(WithToolFilter(func(name string) bool { return name != "solr.query" }))
+	assert.True(t, st.toolDisabled("solr.query"))
+	assert.False(t, st.toolDisabled("solr.ping"))
+
+	// Goal: The OpenSearch backend replaces Solr and disables the Solr-only tools, keeping other tool filters.
+	st = NewServer(WithToolFilter(func(name string) bool { return name != "solr.ping" }), WithOpenSearch("http://opensearch:9200", "", "", httpClient))
+	assert.IsType(t, &opensearch.Backend{}, st.backend())
+	assert.True(t, st.toolDisabled("solr.query.shards"))
+	assert.True(t, st.toolDisabled("solr.ping"))
+	assert.False(t, st.toolDisabled("solr.query"))
+}
+
+// TestOpenSearchClient tests that recording and chaos mode apply to the HTTP client of the OpenSearch backend.
+func TestOpenSearchClient(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		fmt.Fprint(w, `{"count":3}`)
+	}))
+	defer server.Close()
+	ctx := context.Background()
+
+	// Goal: Requests to OpenSearch are recorded into the fixture shared with Solr.
+	path := filepath.Join(t.TempDir(), "fixture.json")
+	st := NewServer(WithRecording(path), WithOpenSearch(server.URL, "", "", &http.Client{}))
+	n, err := st.backend().Count(ctx, "logs", "*:*")
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), n)
+	f, err := replay.Load(path)
+	require.NoError(t, err)
+	assert.Len(t, f.Interactions, 1)
+
+	// Goal: Chaos mode fails requests to OpenSearch, whose paths are not those of Solr.
+	st = NewServer(WithChaos(chaos.Config{Rate: 1, Faults: []chaos.Fault{chaos.Unavailable}}), WithOpenSearch(server.URL, "", "", &http.Client{}))
+	_, err = st.backend().Count(ctx, "logs", "*:*")
+	assert.Error(t, err)
+	assert.Equal(t, int32(1), requests.Load())
+}
@@ -0,0 +1,152 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/types"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// sessionActivity is what the server observed of an MCP session.
+type sessionActivity struct {
+	started    time.Time
+	lastActive time.Time
+	toolCalls  map[string]int
+}
+
+// sessionTracker follows the open MCP sessions. Sessions are added on their first request and removed when they end.
+type sessionTracker struct {
+	mu       sync.Mutex
+	sessions map[*mcp.ServerSession]*sessionActivity
+}
+
+// SessionInfo describes an open MCP session.
+type SessionInfo struct {
+	ID              string         `json:"id"`
+	ClientName      string         `json:"clientName,omitempty"`
+	ClientVersion   string         `json:"clientVersion,omitempty"`
+	ProtocolVersion string         `json:"protocolVersion,omitempty"`
+	StartedAt       time.Time      `json:"startedAt"`
+	Age             string         `json:"age"`
+	LastActivity    time.Time      `json:"lastActivity"`
+	Idle            string         `json:"idle"`
+	ToolCalls       map[string]int `json:"toolCalls"`
+	TotalToolCalls  int            `json:"totalToolCalls"`
+}
+
+// touchSession records activity of ss, counting a call of tool unless it is empty.
+func (st *State) touchSession(ss *mcp.ServerSession, tool string) {
+	if ss == nil {
+		return
+	}
+	t := &st.sessions
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.sessions == nil {
+		t.sessions = make(map[*mcp.ServerSession]*sessionActivity)
+	}
+	now := time.Now()
+	a, ok := t.sessions[ss]
+	if !ok {
+		a = &sessionActivity{started: now, toolCalls: map[string]int{}}
+		t.sessions[ss] = a
+		go func() {
+			ss.Wait()
+			t.mu.Lock()
+			delete(t.sessions, ss)
+			t.mu.Unlock()
+		}()
+	}
+	a.lastActive = now
+	if tool != "" {
+		a.toolCalls[tool]++
+	}
+}
+
+// sessionActivityMiddleware records the activity of every request, including those that are not tool calls.
+func (st *State) sessionActivityMiddleware(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		if ss, ok := req.GetSession().(*mcp.ServerSession); ok {
+			st.touchSession(ss, "")
+		}
+		return next(ctx, method, req)
+	}
+}
+
+// SessionInfos returns the open MCP sessions, oldest first.
+func (st *State) SessionInfos() []SessionInfo {
+	t := &st.sessions
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	infos := make([]SessionInfo, 0, len(t.sessions))
+	for ss, a := range t.sessions {
+		info := SessionInfo{
+			ID:           ss.ID(),
+			StartedAt:    a.started,
+			Age:          now.Sub(a.started).Round(time.Second).String(),
+			LastActivity: a.lastActive,
+			Idle:         now.Sub(a.lastActive).Round(time.Second).String(),
+			ToolCalls:    make(map[string]int, len(a.toolCalls)),
+		}
+		for tool, n := range a.toolCalls {
+			info.ToolCalls[tool] = n
+			info.TotalToolCalls += n
+		}
+		if p := ss.InitializeParams(); p != nil {
+			info.ProtocolVersion = p.ProtocolVersion
+			if p.ClientInfo != nil {
+				info.ClientName = p.ClientInfo.Name
+				info.ClientVersion = p.ClientInfo.Version
+			}
+		}
+		infos = append(infos, info)
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].StartedAt.Before(infos[j].StartedAt) })
+	return infos
+}
+
+// TerminateSession closes the MCP session with the given ID.
+func (st *State) TerminateSession(id string) error {
+	if id == "" {
+		return fmt.Errorf("session id is required")
+	}
+	t := &st.sessions
+	t.mu.Lock()
+	var target *mcp.ServerSession
+	for ss := range t.sessions {
+		if ss.ID() == id {
+			target = ss
+			break
+		}
+	}
+	t.mu.Unlock()
+
+	if target == nil {
+		return fmt.Errorf("session %s not found", id)
+	}
+	slog.Warn("Terminating MCP session", "session", id)
+	return target.Close()
+}
+
+// toolServerSessions shows the calling session. Other sessions are only counted: their IDs would let any client
+// end them, so listing and terminating them is left to the token-gated admin API.
+func (st *State) toolServerSessions(ctx context.Context, req *mcp.CallToolRequest, _ types.SessionsIn) (*mcp.CallToolResult, any, error) {
+	out := map[string]any{"otherSessions": 0}
+	for _, info := range st.SessionInfos() {
+		if req != nil && req.Session != nil && info.ID == req.Session.ID() {
+			out["session"] = info
+		} else {
+			out["otherSessions"] = out["otherSessions"].(int) + 1
+		}
+	}
+	return nil, out, nil
+}
@@ -0,0 +1,68 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestServerSessions tests inspecting MCP sessions with the tool and terminating them with the admin API.
+func TestServerSessions(t *testing.T) {
+	st := NewServer(WithSolrURL("http://127.0.0.1:1"), WithHTTPClient(&http.Client{}))
+	mcpServer := st.NewMCPServer()
+	httpServer := httptest.NewServer(mcp.NewStreamableHTTPHandler(func(*http.Request) *mcp.Server { return mcpServer }, nil))
+	t.Cleanup(httpServer.Close)
+
+	ctx := context.Background()
+	connect := func(name string) *mcp.ClientSession {
+		client := mcp.NewClient(&mcp.Implementation{Name: name, Version: "1.2.3"}, nil)
+		session, err := client.Connect(ctx, &mcp.StreamableClientTransport{Endpoint: httpServer.URL}, nil)
+		require.NoError(t, err)
+		t.Cleanup(func() { session.Close() })
+		return session
+	}
+	operator := connect("operator")
+	runaway := connect("runaway")
+	_, err := runaway.CallTool(ctx, &mcp.CallToolParams{Name: "solr.info", Arguments: map[string]any{}})
+	require.NoError(t, err)
+
+	// Goal: Sessions are listed with client info and tool call counts.
+	infos := st.SessionInfos()
+	require.Len(t, infos, 2)
+	assert.Equal(t, "operator", infos[0].ClientName)
+	assert.Equal(t, "1.2.3", infos[0].ClientVersion)
+	assert.Equal(t, operator.ID(), infos[0].ID)
+	assert.Equal(t, "runaway", infos[1].ClientName)
+	assert.Equal(t, map[string]int{"solr.info": 1}, infos[1].ToolCalls)
+	assert.Equal(t, 1, infos[1].TotalToolCalls)
+
+	// Goal: The tool shows only the calling session, and counts the others without their IDs.
+	res, err := operator.CallTool(ctx, &mcp.CallToolParams{Name: "solr.server.sessions", Arguments: map[string]any{}})
+	require.NoError(t, err)
+	require.False(t, res.IsError)
+	out := res.StructuredContent.(map[string]any)
+	assert.Equal(t, operator.ID(), out["session"].(map[string]any)["id"])
+	assert.Equal(t, float64(1), out["otherSessions"])
+	assert.NotContains(t, res.Content[0].(*mcp.TextContent).Text, runaway.ID())
+
+	// Goal: The admin API terminates a session, which disappears from the list.
+	admin := httptest.NewServer(st.AdminHandler(mcpServer, "s3cret"))
+	defer admin.Close()
+	req, err := http.NewRequest(http.MethodDelete, admin.URL+"/admin/sessions/"+runaway.ID(), nil)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer s3cret")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Eventually(t, func() bool { return len(st.SessionInfos()) == 1 }, time.Second, 10*time.Millisecond)
+
+	// Goal: Unknown sessions are reported.
+	assert.ErrorContains(t, st.TerminateSession("nope"), "not found")
+}
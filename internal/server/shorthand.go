@@ -0,0 +1,29 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/solr"
+	"github.com/Sashimimochi/solr-mcp-go/internal/types"
+)
+
+// rangeFieldKinds returns the range shorthand kind of the fields of collection. The schema is read
+// on first use; when it cannot be read, no field takes shorthands and the query is sent unchanged.
+func (st *State) rangeFieldKinds(ctx context.Context, collection string) func(field string) string {
+	var fc *types.FieldCatalog
+	loaded := false
+	return func(field string) string {
+		if !loaded {
+			loaded = true
+			var err error
+			if fc, err = st.backend().Schema(ctx, collection); err != nil {
+				slog.Warn("Range shorthands not expanded: cannot read the schema", "collection", collection, "error", err)
+			}
+		}
+		if fc == nil {
+			return ""
+		}
+		return solr.RangeFieldKind(fc, field)
+	}
+}
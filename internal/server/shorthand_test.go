@@ -0,0 +1,57 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"testing"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// schemaBackend is a fakeBackend with a typed schema that records the parameters of queries.
+type schemaBackend struct {
+	fakeBackend
+	fields    []types.SolrField
+	schemaErr error
+	queries   []url.Values
+}
+
+func (b *schemaBackend) Query(ctx context.Context, collection string, params url.Values) (map[string]any, error) {
+	b.queries = append(b.queries, params)
+	return b.fakeBackend.Query(ctx, collection, params)
+}
+
+func (b *schemaBackend) Schema(context.Context, string) (*types.FieldCatalog, error) {
+	if b.schemaErr != nil {
+		return nil, b.schemaErr
+	}
+	return &types.FieldCatalog{UniqueKey: "id", All: b.fields}, nil
+}
+
+// TestQueryRangeShorthands tests that solr.query expands range shorthands on the numeric and date fields of the schema.
+func TestQueryRangeShorthands(t *testing.T) {
+	b := &schemaBackend{
+		fakeBackend: fakeBackend{docs: map[string][]map[string]any{"products": {}}},
+		fields:      []types.SolrField{{Name: "id", Type: "string"}, {Name: "price", Type: "pfloat"}, {Name: "version", Type: "string"}},
+	}
+	st := NewServer(WithBackend(b))
+	ctx := context.Background()
+
+	// Goal: Shorthands on numeric fields are expanded and reported; shorthand-like values of string fields are kept.
+	_, out, err := st.toolQuery(ctx, nil, types.QueryIn{Collection: "products", Query: "price:10..20", FilterQuery: []string{"version:1.2..1.3", "price:abc.."}})
+	require.NoError(t, err)
+	assert.Equal(t, "price:[10 TO 20]", b.queries[0].Get("q"))
+	assert.Equal(t, []string{"version:1.2..1.3", "price:abc.."}, b.queries[0]["fq"])
+	assert.Len(t, out.(map[string]any)["expanded"], 1)
+
+	// Goal: Without a schema, the query is sent unchanged instead of failing.
+	b.schemaErr = errors.New("schema unavailable")
+	n := len(b.queries)
+	_, _, err = st.toolQuery(ctx, nil, types.QueryIn{Collection: "products", Query: "price:10..20"})
+	require.NoError(t, err)
+	assert.Equal(t, "price:10..20", b.queries[n].Get("q"))
+}
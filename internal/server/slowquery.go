@@ -0,0 +1,93 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+	"net/url"
+	"time"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/config"
+	"github.com/Sashimimochi/solr-mcp-go/internal/solr"
+)
+
+// Baselines of node metrics for the slow query analysis.
+const (
+	// baselineInterval is how often fast queries refresh the baseline in the background.
+	baselineInterval = time.Minute
+	// maxBaselineAge is the oldest baseline GC is compared with; older ones would dilute a GC storm.
+	maxBaselineAge = 10 * time.Minute
+)
+
+// nodeBaseline is an earlier metrics snapshot of a node, so GC can be judged over a window.
+type nodeBaseline struct {
+	node    string
+	metrics solr.NodeMetrics
+}
+
+// analyzeSlowQuery adds a snapshot of the metrics of node, which answered the query, and the possible causes to
+// a solr.query result whose QTime exceeds slowQuery.threshold. Faster results refresh the metrics baseline instead.
+func (st *State) analyzeSlowQuery(ctx context.Context, node string, params url.Values, resp map[string]any) {
+	threshold := st.fileConfig().SlowQuery.ThresholdDuration()
+	if threshold == 0 || st.Backend != nil {
+		return
+	}
+	header, _ := resp["responseHeader"].(map[string]any)
+	qtime, ok := header["QTime"].(float64)
+	if !ok {
+		return
+	}
+	if time.Duration(qtime)*time.Millisecond < threshold {
+		st.refreshNodeBaseline(node)
+		return
+	}
+
+	slow := map[string]any{"qTime": qtime, "threshold": threshold.String(), "node": config.RedactURL(node)}
+	resp["slowQuery"] = slow
+	m, err := solr.FetchNodeMetrics(ctx, st.HttpClient, node, st.BasicUser, st.BasicPass)
+	if err != nil {
+		slog.Warn("Failed to read node metrics of a slow query", "error", err)
+		slow["error"] = err.Error()
+		return
+	}
+	st.slowMu.Lock()
+	var baseline *solr.NodeMetrics
+	if b := st.baseline; b != nil && b.node == node && m.Time.Sub(b.metrics.Time) <= maxBaselineAge {
+		baseline = &b.metrics
+		slow["window"] = m.Time.Sub(b.metrics.Time).Round(time.Second).String()
+	}
+	st.baseline = &nodeBaseline{node: node, metrics: m}
+	st.slowMu.Unlock()
+
+	causes := solr.SlowQueryCauses(m, baseline, params)
+	slow["metrics"] = m
+	if len(causes) == 0 {
+		causes = []solr.SlowQueryCause{}
+		slow["note"] = "no node-level cause found; check the query itself with solr.explain_query and debug=timing"
+	}
+	resp["possibleCauses"] = causes
+	slog.Warn("Slow query", "qtime", qtime, "threshold", threshold, "causes", len(causes))
+}
+
+// refreshNodeBaseline takes a new baseline snapshot of node in the background when the last one is older than
+// baselineInterval.
+func (st *State) refreshNodeBaseline(node string) {
+	st.slowMu.Lock()
+	defer st.slowMu.Unlock()
+	if st.baselineRunning || (st.baseline != nil && st.baseline.node == node && time.Since(st.baseline.metrics.Time) < baselineInterval) {
+		return
+	}
+	st.baselineRunning = true
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		m, err := solr.FetchNodeMetrics(ctx, st.HttpClient, node, st.BasicUser, st.BasicPass)
+		st.slowMu.Lock()
+		defer st.slowMu.Unlock()
+		st.baselineRunning = false
+		if err != nil {
+			slog.Debug("Failed to refresh the node metrics baseline", "error", err)
+			return
+		}
+		st.baseline = &nodeBaseline{node: node, metrics: m}
+	}()
+}
@@ -0,0 +1,112 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/config"
+	"github.com/Sashimimochi/solr-mcp-go/internal/solr"
+	"github.com/Sashimimochi/solr-mcp-go/internal/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSlowQueryAnalysis tests attaching node metrics and possible causes to slow solr.query results.
+func TestSlowQueryAnalysis(t *testing.T) {
+	var metricsRequests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/solr/products/select":
+			qtime := 20
+			if r.URL.Query().Get("q") == "slow" {
+				qtime = 2500
+			}
+			fmt.Fprintf(w, `{"responseHeader":{"status":0,"QTime":%d},"response":{"numFound":0,"docs":[]}}`, qtime)
+		case "/solr/admin/metrics":
+			metricsRequests.Add(1)
+			fmt.Fprintln(w, `{"metrics":{
+				"solr.jvm":{"memory.heap.used":1900000000,"memory.heap.max":2000000000,"gc.G1-Young-Generation.count":12,"gc.G1-Young-Generation.time":300},
+				"solr.jetty":{"org.eclipse.jetty.util.thread.QueuedThreadPool.qtp1.jobs":{"value":7}}}}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	ctx := context.Background()
+	st := newTestState(t, server.URL)
+
+	// Goal: Without a threshold, results are left alone and no metrics are read.
+	_, out, err := st.toolQuery(ctx, nil, types.QueryIn{Collection: "products", Query: "slow"})
+	require.NoError(t, err)
+	assert.NotContains(t, out.(map[string]any), "slowQuery")
+	assert.Zero(t, metricsRequests.Load())
+
+	// Goal: Fast queries refresh the baseline in the background.
+	st.Config = &config.FileConfig{SlowQuery: config.SlowQueryConfig{Threshold: "1s"}}
+	_, out, err = st.toolQuery(ctx, nil, types.QueryIn{Collection: "products", Query: "fast"})
+	require.NoError(t, err)
+	assert.NotContains(t, out.(map[string]any), "possibleCauses")
+	require.Eventually(t, func() bool {
+		st.slowMu.Lock()
+		defer st.slowMu.Unlock()
+		return st.baseline != nil
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// Goal: Slow queries report a snapshot of the node and the causes it shows.
+	rows := 20000
+	_, out, err = st.toolQuery(ctx, nil, types.QueryIn{Collection: "products", Query: "slow", Rows: &rows})
+	require.NoError(t, err)
+	resp := out.(map[string]any)
+	slow := resp["slowQuery"].(map[string]any)
+	assert.Equal(t, float64(2500), slow["qTime"])
+	assert.Equal(t, "1s", slow["threshold"])
+	assert.Contains(t, slow, "window")
+	assert.Equal(t, int64(7), slow["metrics"].(solr.NodeMetrics).QueuedRequests)
+	var causes []string
+	for _, c := range resp["possibleCauses"].([]solr.SlowQueryCause) {
+		causes = append(causes, c.Cause)
+	}
+	assert.Equal(t, []string{"heap_pressure", "request_queueing", "deep_paging"}, causes)
+	assert.Equal(t, int32(2), metricsRequests.Load())
+}
+
+// TestSlowQueryRoutedNode tests reading the metrics of the node that answered a routed query.
+func TestSlowQueryRoutedNode(t *testing.T) {
+	var primaryMetrics, nodeMetrics atomic.Int32
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/solr/admin/metrics" {
+			primaryMetrics.Add(1)
+		}
+		http.Error(w, "Service Unavailable", http.StatusServiceUnavailable)
+	}))
+	defer primary.Close()
+	node := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/solr/products/select":
+			fmt.Fprint(w, `{"responseHeader":{"status":0,"QTime":2500},"response":{"numFound":0,"docs":[]}}`)
+		case "/solr/admin/metrics":
+			nodeMetrics.Add(1)
+			fmt.Fprint(w, `{"metrics":{"solr.jvm":{"memory.heap.used":100,"memory.heap.max":1000}}}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer node.Close()
+	st := NewServer(WithSolrURL(primary.URL), WithHTTPClient(&http.Client{}), WithNodes([]string{node.URL}, false),
+		WithConfig(&config.FileConfig{SlowQuery: config.SlowQueryConfig{Threshold: "1s"}}, ""))
+
+	// Goal: The slow query is attributed to the node that answered it, not to the base URL.
+	_, out, err := st.toolQuery(context.Background(), nil, types.QueryIn{Collection: "products", Query: "*:*"})
+	require.NoError(t, err)
+	slow := out.(map[string]any)["slowQuery"].(map[string]any)
+	assert.Equal(t, node.URL, slow["node"])
+	assert.NotContains(t, slow, "error")
+	assert.Equal(t, int32(1), nodeMetrics.Load())
+	assert.Zero(t, primaryMetrics.Load())
+}
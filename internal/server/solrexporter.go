@@ -0,0 +1,70 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/metrics"
+	"github.com/Sashimimochi/solr-mcp-go/internal/types"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Defaults of solr.metrics.history.
+const (
+	defaultHistoryPoints = 60
+	defaultHistorySeries = 20
+)
+
+// solrMetricsHistory returns the history of solr-exporter samples, creating it on first use.
+func (st *State) solrMetricsHistory() *metrics.History {
+	st.metricsHistoryOnce.Do(func() {
+		st.metricsHistory = metrics.NewHistory(st.fileConfig().SolrExporter)
+	})
+	return st.metricsHistory
+}
+
+func (st *State) toolMetricsHistory(ctx context.Context, _ *mcp.CallToolRequest, in types.MetricsHistoryIn) (*mcp.CallToolResult, any, error) {
+	if e := st.CapabilityError("solr_exporter"); e != nil {
+		return nil, nil, e
+	}
+	if in.MaxPoints < 0 || in.MaxSeries < 0 {
+		return nil, nil, fmt.Errorf("input.maxPoints and input.maxSeries must not be negative")
+	}
+	h := st.solrMetricsHistory()
+	if in.Metric == "" {
+		return nil, map[string]any{"status": h.Status(), "metrics": h.Metrics()}, nil
+	}
+	re, err := regexp.Compile("^(?:" + in.Metric + ")$")
+	if err != nil {
+		return nil, nil, fmt.Errorf("input.metric: %v", err)
+	}
+	q := metrics.Query{Metric: re, Labels: in.Labels, MaxPoints: in.MaxPoints}
+	if q.MaxPoints == 0 {
+		q.MaxPoints = defaultHistoryPoints
+	}
+	if in.Since != "" {
+		d, err := time.ParseDuration(in.Since)
+		if err != nil || d <= 0 {
+			return nil, nil, fmt.Errorf("input.since: invalid duration %q", in.Since)
+		}
+		q.Since = time.Now().Add(-d)
+	}
+	series := h.Query(q)
+	out := map[string]any{"status": h.Status()}
+	limit := in.MaxSeries
+	if limit == 0 {
+		limit = defaultHistorySeries
+	}
+	if len(series) > limit {
+		out["truncated"] = fmt.Sprintf("%d of %d series returned; narrow them with labels or raise maxSeries", limit, len(series))
+		series = series[:limit]
+	}
+	if series == nil {
+		series = []metrics.Series{}
+	}
+	out["series"] = series
+	return nil, out, nil
+}
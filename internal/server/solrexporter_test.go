@@ -0,0 +1,78 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/config"
+	"github.com/Sashimimochi/solr-mcp-go/internal/metrics"
+	"github.com/Sashimimochi/solr-mcp-go/internal/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestToolMetricsHistory tests answering questions about scraped solr-exporter metrics.
+func TestToolMetricsHistory(t *testing.T) {
+	ratio := 0.9
+	exporter := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "# TYPE solr_metrics_core_searcher_cache_ratio gauge\n"+
+			"solr_metrics_core_searcher_cache_ratio{core=\"products\",cache=\"queryResultCache\",item=\"hitratio\"} %g\n"+
+			"solr_metrics_core_searcher_cache_ratio{core=\"products\",cache=\"filterCache\",item=\"hitratio\"} 0.5\n"+
+			"solr_metrics_jvm_threads_current 40\n", ratio)
+	}))
+	defer exporter.Close()
+	ctx := context.Background()
+
+	// Goal: The tool needs a configured solr-exporter.
+	st := newTestState(t, "http://localhost:8983")
+	_, _, err := st.toolMetricsHistory(ctx, nil, types.MetricsHistoryIn{})
+	var capErr *CapabilityError
+	require.ErrorAs(t, err, &capErr)
+	assert.Equal(t, "solr_exporter", capErr.Capability)
+
+	st.Config = &config.FileConfig{SolrExporter: config.SolrExporterConfig{URL: exporter.URL, Metrics: []string{"solr_metrics_core_.*"}}}
+	h := st.solrMetricsHistory()
+	for _, v := range []float64{0.9, 0.8, 0.6} {
+		ratio = v
+		samples, info, err := metrics.Scrape(ctx, exporter.Client(), exporter.URL)
+		require.NoError(t, err)
+		h.Add(time.Now(), samples, info)
+	}
+
+	// Goal: Without a metric, the recorded metrics are listed.
+	_, out, err := st.toolMetricsHistory(ctx, nil, types.MetricsHistoryIn{})
+	require.NoError(t, err)
+	list := out.(map[string]any)["metrics"].([]metrics.MetricInfo)
+	require.Len(t, list, 1)
+	assert.Equal(t, metrics.MetricInfo{Name: "solr_metrics_core_searcher_cache_ratio", Type: "gauge", Series: 2}, list[0])
+	assert.Equal(t, 3, out.(map[string]any)["status"].(metrics.Status).Scrapes)
+
+	// Goal: Series are selected by name pattern and labels, with their trend.
+	_, out, err = st.toolMetricsHistory(ctx, nil, types.MetricsHistoryIn{
+		Metric: "solr_metrics_core_searcher_cache_.*", Labels: map[string]string{"cache": "queryResultCache"}, Since: "1h",
+	})
+	require.NoError(t, err)
+	series := out.(map[string]any)["series"].([]metrics.Series)
+	require.Len(t, series, 1)
+	assert.Equal(t, "falling", series[0].Trend)
+	assert.InDelta(t, -0.3, series[0].Change, 1e-9)
+	assert.Len(t, series[0].Points, 3)
+
+	// Goal: Large answers are truncated with a note.
+	_, out, err = st.toolMetricsHistory(ctx, nil, types.MetricsHistoryIn{Metric: "solr_metrics_core_searcher_cache_ratio", MaxSeries: 1})
+	require.NoError(t, err)
+	assert.Len(t, out.(map[string]any)["series"], 1)
+	assert.True(t, strings.HasPrefix(out.(map[string]any)["truncated"].(string), "1 of 2 series"))
+
+	// Goal: Invalid input is rejected.
+	_, _, err = st.toolMetricsHistory(ctx, nil, types.MetricsHistoryIn{Metric: "solr_(", Since: "1h"})
+	assert.ErrorContains(t, err, "input.metric")
+	_, _, err = st.toolMetricsHistory(ctx, nil, types.MetricsHistoryIn{Metric: "solr_up", Since: "yesterday"})
+	assert.ErrorContains(t, err, "input.since")
+}
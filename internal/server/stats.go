@@ -0,0 +1,79 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"time"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/types"
+	"github.com/Sashimimochi/solr-mcp-go/internal/usage"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// usageTracker returns the tool usage tracker, loading StatsFile on first use.
+func (st *State) usageTracker() *usage.Tracker {
+	st.usageOnce.Do(func() {
+		st.usage = usage.NewTracker()
+		if st.StatsFile != "" {
+			if err := st.usage.Load(st.StatsFile); err != nil {
+				slog.Warn("Failed to load usage stats, starting empty", "path", st.StatsFile, "error", err)
+			}
+		}
+		st.usage.OnRecommendation = func(r usage.Recommendation) {
+			slog.Warn("Repeated tool failures", "pattern", r.Pattern, "count", r.Count, "recommendation", r.Message)
+		}
+	})
+	return st.usage
+}
+
+// recordUsage records a finished tool call. It is deferred by the tool handlers, so out and err are read on return.
+func recordUsage[Out any](st *State, tool string, start time.Time, out *Out, err *error) {
+	size := 0
+	if *err == nil && any(*out) != nil {
+		if data, e := json.Marshal(*out); e == nil {
+			size = len(data)
+		}
+	}
+	st.usageTracker().Record(tool, *err, size, time.Since(start))
+}
+
+// LogRecommendations logs the recommendations derived from the usage statistics, e.g. those loaded from StatsFile at startup.
+func (st *State) LogRecommendations() {
+	for _, r := range st.usageTracker().Recommendations() {
+		slog.Warn("Repeated tool failures", "pattern", r.Pattern, "count", r.Count, "recommendation", r.Message)
+	}
+}
+
+// saveUsageStats writes the usage statistics to StatsFile on every interval and when ctx is done.
+func (st *State) saveUsageStats(ctx context.Context, interval time.Duration) {
+	saveEvery(ctx, interval, func() {
+		if err := st.usageTracker().Save(st.StatsFile); err != nil {
+			slog.Error("Failed to save usage stats", "path", st.StatsFile, "error", err)
+		}
+	})
+}
+
+// saveEvery calls each save function on every interval and when ctx is done.
+func saveEvery(ctx context.Context, interval time.Duration, saves ...func()) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			for _, save := range saves {
+				save()
+			}
+			return
+		case <-ticker.C:
+			for _, save := range saves {
+				save()
+			}
+		}
+	}
+}
+
+func (st *State) toolServerStats(ctx context.Context, _ *mcp.CallToolRequest, in types.InfoIn) (*mcp.CallToolResult, any, error) {
+	return nil, st.usageTracker().Snapshot(), nil
+}
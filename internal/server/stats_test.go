@@ -0,0 +1,59 @@
+package server
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestServerStats tests that tool calls are tracked and reported by solr.server.stats.
+func TestServerStats(t *testing.T) {
+	st := newTestState(t, "http://localhost:8983")
+	st.StatsFile = filepath.Join(t.TempDir(), "stats.json")
+	mcpServer := mcp.NewServer(&mcp.Implementation{}, nil)
+	AddTools(mcpServer, st)
+	session, _, _ := connectTestClient(t, mcpServer)
+	ctx := context.Background()
+
+	_, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "solr.info", Arguments: map[string]any{}})
+	require.NoError(t, err)
+	for range 3 {
+		_, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "solr.schema", Arguments: map[string]any{"collection": ""}})
+		require.NoError(t, err)
+	}
+
+	res, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "solr.server.stats", Arguments: map[string]any{}})
+	require.NoError(t, err)
+	out := res.StructuredContent.(map[string]any)
+	tools := map[string]map[string]any{}
+	for _, tool := range out["tools"].([]any) {
+		m := tool.(map[string]any)
+		tools[m["name"].(string)] = m
+	}
+
+	// Goal: Calls, failures and result sizes are counted per tool.
+	assert.Equal(t, float64(1), tools["solr.info"]["calls"])
+	assert.Equal(t, float64(0), tools["solr.info"]["failureRate"])
+	assert.Greater(t, tools["solr.info"]["avgResultBytes"], float64(0))
+	assert.Equal(t, float64(3), tools["solr.schema"]["calls"])
+	assert.Equal(t, float64(1), tools["solr.schema"]["failureRate"])
+	assert.Contains(t, tools["solr.schema"]["lastError"], "collection is required")
+
+	// Goal: Repeated missing collections produce a recommendation.
+	recs := out["recommendations"].([]any)
+	require.Len(t, recs, 1)
+	assert.Contains(t, recs[0].(map[string]any)["message"], "SOLR_MCP_DEFAULT_COLLECTION")
+
+	// Goal: Statistics survive a restart through the stats file.
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	st.saveUsageStats(cancelled, time.Hour)
+	restarted := newTestState(t, "http://localhost:8983")
+	restarted.StatsFile = st.StatsFile
+	assert.Len(t, restarted.usageTracker().Recommendations(), 1)
+}
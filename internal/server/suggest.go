@@ -0,0 +1,85 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+	"net/url"
+	"time"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/solr"
+	"github.com/Sashimimochi/solr-mcp-go/internal/types"
+)
+
+// topValuesEntry caches the most frequent values of a field.
+type topValuesEntry struct {
+	values  []string
+	low     bool // the field has at most solr.TopValuesLimit distinct values
+	fetched time.Time
+}
+
+// fieldTopValues returns the cached top values of field, fetching them when missing or older than the schema cache TTL.
+func (st *State) fieldTopValues(ctx context.Context, collection, field string) ([]string, bool) {
+	key := collection + "\x00" + field
+	st.topValuesMu.Lock()
+	e, ok := st.topValues[key]
+	st.topValuesMu.Unlock()
+	if ok && time.Since(e.fetched) < st.SchemaCache.TTL {
+		return e.values, e.low
+	}
+
+	resp, err := st.backend().Query(ctx, collection, solr.TopValuesParams(field))
+	if err != nil {
+		slog.Debug("Failed to fetch top field values", "collection", collection, "field", field, "error", err)
+		return nil, false
+	}
+	values, low := solr.ParseTopValues(resp, field)
+
+	st.topValuesMu.Lock()
+	defer st.topValuesMu.Unlock()
+	if st.topValues == nil {
+		st.topValues = make(map[string]topValuesEntry)
+	}
+	st.topValues[key] = topValuesEntry{values: values, low: low, fetched: time.Now()}
+	return values, low
+}
+
+// flushTopValues empties the top values cache and returns how many entries were removed.
+func (st *State) flushTopValues() int {
+	st.topValuesMu.Lock()
+	defer st.topValuesMu.Unlock()
+	n := len(st.topValues)
+	st.topValues = nil
+	return n
+}
+
+// filterSuggestions looks for misspelled fields and values in the filters of a query that matched nothing.
+func (st *State) filterSuggestions(ctx context.Context, in types.QueryIn, resp map[string]any) []solr.FilterSuggestion {
+	respObj, _ := resp["response"].(map[string]any)
+	if numFound, ok := respObj["numFound"].(float64); !ok || numFound > 0 || len(in.FilterQuery) == 0 {
+		return nil
+	}
+	fc, err := st.backend().Schema(ctx, in.Collection)
+	if err != nil {
+		slog.Debug("Schema unavailable for filter suggestions", "collection", in.Collection, "error", err)
+		fc = nil
+	}
+	return solr.SuggestFilterCorrections(in.FilterQuery, fc, func(field string) ([]string, bool) {
+		return st.fieldTopValues(ctx, in.Collection, field)
+	})
+}
+
+// correctFilters replaces the filters of params that have a suggestion.
+func correctFilters(params url.Values, suggestions []solr.FilterSuggestion) url.Values {
+	corrected := url.Values{}
+	for k, v := range params {
+		corrected[k] = append([]string{}, v...)
+	}
+	for i, fq := range corrected["fq"] {
+		for _, s := range suggestions {
+			if fq == s.Filter {
+				corrected["fq"][i] = s.Suggestion
+			}
+		}
+	}
+	return corrected
+}
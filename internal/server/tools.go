@@ -2,16 +2,21 @@ package server
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"log/slog"
+	"maps"
 	"net/http"
+	"net/url"
 	"strings"
+	"time"
 
-	"solr-mcp-go/internal/config"
-	"solr-mcp-go/internal/solr"
-	"solr-mcp-go/internal/types"
+	"github.com/Sashimimochi/solr-mcp-go/internal/config"
+	"github.com/Sashimimochi/solr-mcp-go/internal/experiment"
+	"github.com/Sashimimochi/solr-mcp-go/internal/opensearch"
+	"github.com/Sashimimochi/solr-mcp-go/internal/solr"
+	"github.com/Sashimimochi/solr-mcp-go/internal/types"
+	"github.com/Sashimimochi/solr-mcp-go/internal/utils"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	solr_sdk "github.com/stevenferrer/solr-go"
@@ -21,9 +26,133 @@ func AddTools(mcpServer *mcp.Server, st *State) []string {
 	var toolNames []string
 
 	// solr.query tool
-	mcp.AddTool(mcpServer, &mcp.Tool{
+	addTool(mcpServer, st, &mcp.Tool{
 		Name:        "solr.query",
 		Description: "Search documents in Solr /select query",
+		InputSchema: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"collection": map[string]any{
+					"type":        "string",
+					"description": "Solr collection name",
+				},
+				"query": map[string]any{
+					"type":        "string",
+					"description": "Solr query string (default: *:*)",
+				},
+				"fq": map[string]any{
+					"type":        "array",
+					"items":       map[string]any{"type": "string"},
+					"description": "Filter queries. Range shorthands are expanded, e.g. price:100..200, price:>=100, timestamp:last7d, timestamp:today",
+				},
+				"fl": map[string]any{
+					"type":        "array",
+					"items":       map[string]any{"type": "string"},
+					"description": "Fields to return",
+				},
+				"select": map[string]any{
+					"type":        "string",
+					"description": "Field selection instead of fl, validated against the schema, e.g. 'id title @child(filter: \"type_s:review\", limit: 3) { id rating }'. Transformers: @child, @elevated, @excluded, @features(store, efi), @explain(style), @shard, @docid; 'alias: field' renames a field",
+				},
+				"sort": map[string]any{
+					"type":        "string",
+					"description": "Sort criteria (e.g., 'price asc')",
+				},
+				"start": map[string]any{
+					"type":        "integer",
+					"description": "Starting offset for pagination",
+				},
+				"rows": map[string]any{
+					"type":        "integer",
+					"description": "Number of rows to return",
+				},
+				"params": map[string]any{
+					"type":        "object",
+					"description": "Additional query parameters",
+				},
+				"echoParams": map[string]any{
+					"type":        "boolean",
+					"description": "Echo all parameters in response",
+				},
+				"planOnly": map[string]any{
+					"type":        "boolean",
+					"description": "Only return the request that would be sent (method, URL, parameters and body) without running the query",
+				},
+				"autoCorrect": map[string]any{
+					"type":        "boolean",
+					"description": "When field:value filters match nothing because of a likely typo, rerun the query with the corrected filters instead of only suggesting them",
+				},
+				"elevate": map[string]any{
+					"type":        "boolean",
+					"description": "Apply the collection's elevation rules even when sorting, and mark pinned documents with [elevated]",
+				},
+				"ltr": map[string]any{
+					"type":        "object",
+					"description": "Re-rank the top documents with a Learning To Rank model (rq={!ltr ...})",
+					"properties": map[string]any{
+						"model":       map[string]any{"type": "string", "description": "Model name (see solr.ltr.list)"},
+						"reRankDocs":  map[string]any{"type": "integer", "description": "Number of top documents to re-rank (Solr default: 200)"},
+						"efi":         map[string]any{"type": "object", "description": "External feature information passed to the features, e.g. {\"user_query\": \"laptop\"}"},
+						"logFeatures": map[string]any{"type": "boolean", "description": "Return the feature values of each document in [features]"},
+						"store":       map[string]any{"type": "string", "description": "Feature store to log (default: the store of the model)"},
+					},
+					"required": []string{"model"},
+				},
+				"matchedOn": map[string]any{
+					"type":        "boolean",
+					"description": "Annotate each document with a matchedOn array of the field:term clauses that matched it, highest score contribution first",
+				},
+				"useParams": map[string]any{
+					"type":        "array",
+					"items":       map[string]any{"type": "string"},
+					"description": "Names of request parameter sets stored in Solr to apply (see solr.params.list); explicit parameters take precedence",
+				},
+				"destination": map[string]any{
+					"type":        "string",
+					"description": "Name of a configured object store to upload the full result to. The tool then returns the object URL and checksum instead of the documents",
+				},
+				"confirm": map[string]any{
+					"type":        "string",
+					"description": "confirmationToken returned for a query with expensive wildcard, regex or fuzzy terms, to run it as written",
+				},
+			},
+			"required": []string{"collection"},
+		},
+	}, st.toolQuery)
+	toolNames = append(toolNames, "solr.query")
+
+	// solr.ping tool
+	addTool(mcpServer, st, &mcp.Tool{
+		Name:        "solr.ping",
+		Description: "Check Solr cluster health (live nodes)",
+		InputSchema: map[string]any{
+			"type":       "object",
+			"properties": map[string]any{},
+		},
+	}, st.toolPing)
+	toolNames = append(toolNames, "solr.ping")
+
+	// solr.collection.health tool
+	addTool(mcpServer, st, &mcp.Tool{
+		Name:        "solr.collection.health",
+		Description: "Check specific collection health status",
+		InputSchema: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"collection": map[string]any{
+					"type":        "string",
+					"description": "Solr collection name",
+				},
+			},
+			"required": []string{"collection"},
+		},
+	}, st.toolCollectionHealth)
+	toolNames = append(toolNames, "solr.collection.health")
+
+	// solr.query.shards tool
+	addTool(mcpServer, st, &mcp.Tool{
+		Name:        "solr.query.shards",
+		Description: "Diagnose skewed shards or broken replicas by sending the same query to every replica with distrib=false and comparing numFound and latency",
 		InputSchema: map[string]any{
 			"type": "object",
 			"properties": map[string]any{
@@ -40,67 +169,699 @@ func AddTools(mcpServer *mcp.Server, st *State) []string {
 					"items":       map[string]any{"type": "string"},
 					"description": "Filter queries",
 				},
-				"fl": map[string]any{
+			},
+			"required": []string{"collection"},
+		},
+	}, st.toolQueryShards)
+	toolNames = append(toolNames, "solr.query.shards")
+
+	// solr.consistency.check tool
+	addTool(mcpServer, st, &mcp.Tool{
+		Name:        "solr.consistency.check",
+		Description: "Compare document counts, max(_version_) and an optional canary query across the replicas of each shard and flag divergence. Replicas may briefly differ while indexing is in progress",
+		InputSchema: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"collection": map[string]any{
+					"type":        "string",
+					"description": "Solr collection name",
+				},
+				"canary": map[string]any{
+					"type":        "string",
+					"description": "Optional query whose numFound is compared across replicas",
+				},
+			},
+			"required": []string{"collection"},
+		},
+	}, st.toolConsistencyCheck)
+	toolNames = append(toolNames, "solr.consistency.check")
+
+	// solr.healthcheck.full tool
+	addTool(mcpServer, st, &mcp.Tool{
+		Name:        "solr.healthcheck.full",
+		Description: "One-call situational overview of the cluster for on-call engineers: checks the live nodes, the health of every collection, the heap and disk usage of each node, replica consistency of a few collections and the errors logged recently, concurrently, and grades the result GREEN, YELLOW or RED with findings, most severe first",
+		InputSchema: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"collections": map[string]any{
+					"type":        "array",
+					"items":       map[string]any{"type": "string"},
+					"description": "Collections whose replicas are compared (default: the first 5 collections by name)",
+				},
+				"errorWindow": map[string]any{
+					"type":        "string",
+					"description": "How far back errors in the Solr logs are counted, as a duration (default: 15m)",
+				},
+			},
+		},
+	}, st.toolHealthcheckFull)
+	toolNames = append(toolNames, "solr.healthcheck.full")
+
+	// solr.dedupe.find tool
+	addTool(mcpServer, st, &mcp.Tool{
+		Name:        "solr.dedupe.find",
+		Description: "Find probable duplicate documents, either by an existing signature field or by MinHash similarity over the text of selected fields. Returns clusters of duplicate IDs with similarity scores",
+		InputSchema: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"collection": map[string]any{
+					"type":        "string",
+					"description": "Solr collection name",
+				},
+				"query": map[string]any{
+					"type":        "string",
+					"description": "Query restricting the documents that are compared (default: *:*)",
+				},
+				"signatureField": map[string]any{
+					"type":        "string",
+					"description": "Field holding a content signature (e.g. from SignatureUpdateProcessorFactory); documents with equal values are duplicates",
+				},
+				"fields": map[string]any{
+					"type":        "array",
+					"items":       map[string]any{"type": "string"},
+					"description": "Stored fields whose text is compared with MinHash when no signatureField is given",
+				},
+				"threshold": map[string]any{
+					"type":        "number",
+					"description": "Minimum estimated Jaccard similarity of MinHash duplicates (default: 0.8)",
+				},
+				"maxDocs": map[string]any{
+					"type":        "integer",
+					"description": "Maximum number of documents read for MinHash, or signature groups (default: 10000)",
+				},
+				"deletePreview": map[string]any{
+					"type":        "boolean",
+					"description": "Also prepare a solr.delete of all but the first document of each cluster, with a confirmation token",
+				},
+			},
+			"required": []string{"collection"},
+		},
+	}, st.toolDedupeFind)
+	toolNames = append(toolNames, "solr.dedupe.find")
+
+	// solr.profile tool
+	addTool(mcpServer, st, &mcp.Tool{
+		Name:        "solr.profile",
+		Description: "Profile the data quality of a collection: per-field fill rates, approximate distinct counts and min/max of numeric and date fields, with flags for empty, sparse and constant fields and dates in the future",
+		InputSchema: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"collection": map[string]any{
+					"type":        "string",
+					"description": "Solr collection name",
+				},
+				"query": map[string]any{
+					"type":        "string",
+					"description": "Query restricting the profiled documents (default: *:*)",
+				},
+				"fields": map[string]any{
+					"type":        "array",
+					"items":       map[string]any{"type": "string"},
+					"description": "Fields to profile (default: all schema fields)",
+				},
+				"maxFields": map[string]any{
+					"type":        "integer",
+					"description": "Maximum number of fields profiled (default: 100)",
+				},
+			},
+			"required": []string{"collection"},
+		},
+	}, st.toolProfile)
+	toolNames = append(toolNames, "solr.profile")
+
+	// solr.drift.report tool
+	addTool(mcpServer, st, &mcp.Tool{
+		Name:        "solr.drift.report",
+		Description: "Report changes of a collection's schema and config overlay over time from stored snapshots, e.g. analyzers or request handlers changed outside change control. Optionally takes a snapshot now and compares it with the latest one",
+		InputSchema: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"collection": map[string]any{
+					"type":        "string",
+					"description": "Solr collection name (default: the default collection)",
+				},
+				"check": map[string]any{
+					"type":        "boolean",
+					"description": "Take a snapshot now and compare it with the latest stored snapshot",
+				},
+				"from": map[string]any{
+					"type":        "string",
+					"description": "Only list snapshots taken at or after this RFC 3339 time",
+				},
+				"to": map[string]any{
+					"type":        "string",
+					"description": "Only list snapshots taken at or before this RFC 3339 time",
+				},
+			},
+		},
+	}, st.toolDriftReport)
+	toolNames = append(toolNames, "solr.drift.report")
+
+	// solr.delete tool
+	addTool(mcpServer, st, &mcp.Tool{
+		Name:        "solr.delete",
+		Description: "Delete documents by query or by IDs. " + confirmDescription,
+		InputSchema: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"collection": map[string]any{
+					"type":        "string",
+					"description": "Solr collection name",
+				},
+				"query": map[string]any{
+					"type":        "string",
+					"description": "Query selecting the documents to delete",
+				},
+				"ids": map[string]any{
+					"type":        "array",
+					"items":       map[string]any{"type": "string"},
+					"description": "uniqueKey values of the documents to delete (instead of query)",
+				},
+				"preview": previewProperty,
+				"confirm": confirmProperty,
+			},
+			"required": []string{"collection"},
+		},
+	}, st.toolDelete)
+	toolNames = append(toolNames, "solr.delete")
+
+	// solr.update tool
+	addTool(mcpServer, st, &mcp.Tool{
+		Name:        "solr.update",
+		Description: "Add documents, overwriting existing documents with the same uniqueKey. Use preview to see which documents would be overwritten",
+		InputSchema: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"collection": map[string]any{
+					"type":        "string",
+					"description": "Solr collection name",
+				},
+				"documents": map[string]any{
+					"type":        "array",
+					"items":       map[string]any{"type": "object"},
+					"description": "Documents to add. Each document must contain the uniqueKey field",
+				},
+				"preview": previewProperty,
+			},
+			"required": []string{"collection", "documents"},
+		},
+	}, st.toolUpdate)
+	toolNames = append(toolNames, "solr.update")
+
+	// solr.collection.drop tool
+	addTool(mcpServer, st, &mcp.Tool{
+		Name:        "solr.collection.drop",
+		Description: "Delete a whole collection. " + confirmDescription,
+		InputSchema: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"collection": map[string]any{
+					"type":        "string",
+					"description": "Solr collection name",
+				},
+				"confirm": confirmProperty,
+			},
+			"required": []string{"collection"},
+		},
+	}, st.toolCollectionDrop)
+	toolNames = append(toolNames, "solr.collection.drop")
+
+	// solr.retention.* tools
+	retentionSchema := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"policy": map[string]any{
+				"type":        "string",
+				"description": "Retention policy name (default: all policies)",
+			},
+		},
+	}
+	addTool(mcpServer, st, &mcp.Tool{
+		Name:        "solr.retention.list",
+		Description: "List the configured retention policies and recent retention runs",
+		InputSchema: map[string]any{
+			"type":       "object",
+			"properties": map[string]any{},
+		},
+	}, st.toolRetentionList)
+	toolNames = append(toolNames, "solr.retention.list")
+
+	addTool(mcpServer, st, &mcp.Tool{
+		Name:        "solr.retention.preview",
+		Description: "Dry run of retention policies: show which documents and collections would be deleted without deleting anything",
+		InputSchema: retentionSchema,
+	}, st.toolRetentionPreview)
+	toolNames = append(toolNames, "solr.retention.preview")
+
+	addTool(mcpServer, st, &mcp.Tool{
+		Name:        "solr.retention.run",
+		Description: "Run retention policies, deleting old documents and dropping expired collections. " + confirmDescription,
+		InputSchema: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"policy": map[string]any{
+					"type":        "string",
+					"description": "Retention policy name (default: all policies)",
+				},
+				"confirm": confirmProperty,
+			},
+		},
+	}, st.toolRetentionRun)
+	toolNames = append(toolNames, "solr.retention.run")
+
+	addTool(mcpServer, st, &mcp.Tool{
+		Name:        "solr.maintenance.status",
+		Description: "Show the maintenance job settings, its next window and its recent runs with their health checks, merges, retention actions and backups",
+		InputSchema: map[string]any{
+			"type":       "object",
+			"properties": map[string]any{},
+		},
+	}, st.toolMaintenanceStatus)
+	toolNames = append(toolNames, "solr.maintenance.status")
+
+	// solr.archive tools
+	archiveSchema := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"collection": map[string]any{
+				"type":        "string",
+				"description": "Primary Solr collection name",
+			},
+			"query": map[string]any{
+				"type":        "string",
+				"description": "Query selecting the documents to move",
+			},
+			"archiveCollection": map[string]any{
+				"type":        "string",
+				"description": "Archive collection (default: <collection>_archive). It must already exist with a compatible schema",
+			},
+			"maxDocs": map[string]any{
+				"type":        "integer",
+				"description": "Refuse to move more documents than this (default: 10000)",
+			},
+		},
+		"required": []string{"collection", "query"},
+	}
+	archiveProperties := maps.Clone(archiveSchema["properties"].(map[string]any))
+	archiveProperties["confirm"] = confirmProperty
+	addTool(mcpServer, st, &mcp.Tool{
+		Name:        "solr.archive",
+		Description: "Archive documents: copy the documents matching a query to the archive collection, verify the copy, then delete them from the primary collection. Safer than a raw delete. " + confirmDescription,
+		InputSchema: map[string]any{
+			"type":       "object",
+			"properties": archiveProperties,
+			"required":   archiveSchema["required"],
+		},
+	}, st.toolArchive)
+	toolNames = append(toolNames, "solr.archive")
+
+	addTool(mcpServer, st, &mcp.Tool{
+		Name:        "solr.archive.restore",
+		Description: "Restore archived documents: copy the documents matching a query from the archive collection back to the primary collection, verify, then delete them from the archive",
+		InputSchema: archiveSchema,
+	}, st.toolArchiveRestore)
+	toolNames = append(toolNames, "solr.archive.restore")
+
+	// Background job tools
+	throttleProperties := map[string]any{
+		"batchSize": map[string]any{
+			"type":        "integer",
+			"description": "Documents read per request (default: 500)",
+		},
+		"maxRequestsPerSecond": map[string]any{
+			"type":        "number",
+			"description": "Maximum batches per second, so the job does not starve interactive queries (default: jobs.maxRequestsPerSecond of the config file, unlimited if unset)",
+		},
+		"maxBytesPerSecond": map[string]any{
+			"type":        "integer",
+			"description": "Maximum bytes written per second (default: jobs.maxBytesPerSecond of the config file, unlimited if unset)",
+		},
+	}
+	exportProperties := map[string]any{
+		"collection": map[string]any{
+			"type":        "string",
+			"description": "Solr collection name",
+		},
+		"query": map[string]any{
+			"type":        "string",
+			"description": "Query selecting the documents to export (default: *:*)",
+		},
+		"fields": map[string]any{
+			"type":        "array",
+			"items":       map[string]any{"type": "string"},
+			"description": "Fields to export (default: all stored fields)",
+		},
+		"destination": map[string]any{
+			"type":        "string",
+			"description": "Name of a configured object store to upload the export to when it is done; the job then reports the object URL and checksum",
+		},
+	}
+	maps.Copy(exportProperties, throttleProperties)
+	addTool(mcpServer, st, &mcp.Tool{
+		Name:        "solr.export",
+		Description: "Start a background job exporting the documents matching a query to a JSON lines file. The job resumes from its last checkpoint after a restart; follow it with solr.jobs",
+		InputSchema: map[string]any{
+			"type":       "object",
+			"properties": exportProperties,
+			"required":   []string{"collection"},
+		},
+	}, st.toolExport)
+	toolNames = append(toolNames, "solr.export")
+
+	reindexProperties := map[string]any{
+		"collection": map[string]any{
+			"type":        "string",
+			"description": "Source collection",
+		},
+		"target": map[string]any{
+			"type":        "string",
+			"description": "Target collection. It must already exist; copyField targets are populated by its schema",
+		},
+		"query": map[string]any{
+			"type":        "string",
+			"description": "Query selecting the documents to copy (default: *:*)",
+		},
+	}
+	maps.Copy(reindexProperties, throttleProperties)
+	addTool(mcpServer, st, &mcp.Tool{
+		Name:        "solr.reindex",
+		Description: "Start a background job copying the documents matching a query into another collection, e.g. after a schema change. The job resumes from its last checkpoint after a restart; follow it with solr.jobs",
+		InputSchema: map[string]any{
+			"type":       "object",
+			"properties": reindexProperties,
+			"required":   []string{"collection", "target"},
+		},
+	}, st.toolReindex)
+	toolNames = append(toolNames, "solr.reindex")
+
+	importProperties := map[string]any{
+		"collection": map[string]any{
+			"type":        "string",
+			"description": "Collection to add the documents to",
+		},
+		"source": map[string]any{
+			"type":        "string",
+			"description": "s3:// or gs:// URI of a JSON lines file, one document per line, in a bucket of a configured object store",
+		},
+	}
+	maps.Copy(importProperties, throttleProperties)
+	addTool(mcpServer, st, &mcp.Tool{
+		Name:        "solr.import",
+		Description: "Start a background job adding the documents of a JSON lines file in S3 or GCS to a collection, e.g. one written by solr.export. The file is streamed in batches and the job resumes from its last checkpoint after a restart; follow it with solr.jobs",
+		InputSchema: map[string]any{
+			"type":       "object",
+			"properties": importProperties,
+			"required":   []string{"collection", "source"},
+		},
+	}, st.toolImport)
+	toolNames = append(toolNames, "solr.import")
+
+	addTool(mcpServer, st, &mcp.Tool{
+		Name:        "solr.jobs",
+		Description: "List the background export, reindex and import jobs with their progress, or cancel or resume one of them",
+		InputSchema: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"id": map[string]any{
+					"type":        "string",
+					"description": "Job ID (default: list all jobs)",
+				},
+				"action": map[string]any{
+					"type":        "string",
+					"enum":        []string{"cancel", "resume"},
+					"description": "Cancel a running job, keeping its checkpoint, or resume a cancelled or failed job",
+				},
+			},
+		},
+	}, st.toolJobs)
+	toolNames = append(toolNames, "solr.jobs")
+
+	// solr.elevation.list tool
+	addTool(mcpServer, st, &mcp.Tool{
+		Name:        "solr.elevation.list",
+		Description: "List the editorial elevation rules (pinned and excluded documents per query text) of a collection's QueryElevationComponent",
+		InputSchema: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"collection": map[string]any{
+					"type":        "string",
+					"description": "Solr collection name",
+				},
+				"file": map[string]any{
+					"type":        "string",
+					"description": "config-file of the QueryElevationComponent (default: elevate.xml)",
+				},
+				"query": map[string]any{
+					"type":        "string",
+					"description": "Only show the rule for this query text",
+				},
+			},
+			"required": []string{"collection"},
+		},
+	}, st.toolElevationList)
+	toolNames = append(toolNames, "solr.elevation.list")
+
+	// solr.elevation.set tool
+	addTool(mcpServer, st, &mcp.Tool{
+		Name:        "solr.elevation.set",
+		Description: "Create, replace or remove the elevation rule of a query text: pin documents to the top in the given order and hide others. The elevation file is uploaded to the collection's configset and the collections using it are reloaded",
+		InputSchema: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"collection": map[string]any{
+					"type":        "string",
+					"description": "Solr collection name",
+				},
+				"file": map[string]any{
+					"type":        "string",
+					"description": "config-file of the QueryElevationComponent (default: elevate.xml)",
+				},
+				"query": map[string]any{
+					"type":        "string",
+					"description": "Query text the rule applies to",
+				},
+				"match": map[string]any{
+					"type":        "string",
+					"enum":        []string{"exact", "subset"},
+					"description": "exact (default) or subset: apply to queries containing all terms of the query text",
+				},
+				"elevate": map[string]any{
+					"type":        "array",
+					"items":       map[string]any{"type": "string"},
+					"description": "uniqueKey values of the documents to pin, in order",
+				},
+				"exclude": map[string]any{
+					"type":        "array",
+					"items":       map[string]any{"type": "string"},
+					"description": "uniqueKey values of the documents to hide",
+				},
+				"remove": map[string]any{
+					"type":        "boolean",
+					"description": "Remove the rule of the query text",
+				},
+				"preview": map[string]any{
+					"type":        "boolean",
+					"description": "Only return the resulting elevation file without uploading it",
+				},
+			},
+			"required": []string{"collection", "query"},
+		},
+	}, st.toolElevationSet)
+	toolNames = append(toolNames, "solr.elevation.set")
+
+	// solr.ltr.list tool
+	addTool(mcpServer, st, &mcp.Tool{
+		Name:        "solr.ltr.list",
+		Description: "List the Learning To Rank feature stores and models of a collection, or the features of one store",
+		InputSchema: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"collection": map[string]any{
+					"type":        "string",
+					"description": "Solr collection name",
+				},
+				"store": map[string]any{
+					"type":        "string",
+					"description": "List the features of this feature store instead",
+				},
+			},
+			"required": []string{"collection"},
+		},
+	}, st.toolLTRList)
+	toolNames = append(toolNames, "solr.ltr.list")
+
+	// solr.ltr.upload tool
+	addTool(mcpServer, st, &mcp.Tool{
+		Name:        "solr.ltr.upload",
+		Description: "Upload Learning To Rank features and/or a model to a collection, then reload it so that queries can use them. Features are uploaded before the model that uses them",
+		InputSchema: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"collection": map[string]any{
+					"type":        "string",
+					"description": "Solr collection name",
+				},
+				"features": map[string]any{
 					"type":        "array",
-					"items":       map[string]any{"type": "string"},
-					"description": "Fields to return",
+					"items":       map[string]any{"type": "object"},
+					"description": "Feature definitions with name, class, params and an optional store (default: _DEFAULT_)",
 				},
-				"sort": map[string]any{
+				"model": map[string]any{
+					"type":        "object",
+					"description": "Model definition with name, class, features, params and an optional store",
+				},
+				"replace": map[string]any{
+					"type":        "boolean",
+					"description": "Delete the model and the feature stores being uploaded first, instead of failing or merging with existing ones",
+				},
+				"noReload": map[string]any{
+					"type":        "boolean",
+					"description": "Do not reload the collection after the upload",
+				},
+			},
+			"required": []string{"collection"},
+		},
+	}, st.toolLTRUpload)
+	toolNames = append(toolNames, "solr.ltr.upload")
+
+	// solr.params.list tool
+	addTool(mcpServer, st, &mcp.Tool{
+		Name:        "solr.params.list",
+		Description: "List the request parameter sets of a collection (Request Parameters API), or one set by name. Queries apply a set with useParams",
+		InputSchema: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"collection": map[string]any{
 					"type":        "string",
-					"description": "Sort criteria (e.g., 'price asc')",
+					"description": "Solr collection name",
 				},
-				"start": map[string]any{
-					"type":        "integer",
-					"description": "Starting offset for pagination",
+				"name": map[string]any{
+					"type":        "string",
+					"description": "Only return this parameter set",
 				},
-				"rows": map[string]any{
-					"type":        "integer",
-					"description": "Number of rows to return",
+			},
+			"required": []string{"collection"},
+		},
+	}, st.toolParamsList)
+	toolNames = append(toolNames, "solr.params.list")
+
+	// solr.params.set tool
+	addTool(mcpServer, st, &mcp.Tool{
+		Name:        "solr.params.set",
+		Description: "Create, replace, extend or delete a named request parameter set stored in Solr, so queries can reference the bundle with useParams instead of repeating its parameters",
+		InputSchema: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"collection": map[string]any{
+					"type":        "string",
+					"description": "Solr collection name",
+				},
+				"name": map[string]any{
+					"type":        "string",
+					"description": "Parameter set name",
 				},
 				"params": map[string]any{
 					"type":        "object",
-					"description": "Additional query parameters",
+					"description": "Request parameters, e.g. {\"defType\": \"edismax\", \"qf\": \"title^2 body\"}. Values are strings, numbers, booleans or lists of them",
 				},
-				"echoParams": map[string]any{
+				"merge": map[string]any{
 					"type":        "boolean",
-					"description": "Echo all parameters in response",
+					"description": "Add params to the existing set instead of replacing it",
+				},
+				"delete": map[string]any{
+					"type":        "boolean",
+					"description": "Delete the parameter set",
 				},
 			},
-			"required": []string{"collection"},
+			"required": []string{"collection", "name"},
 		},
-	}, st.toolQuery)
-	toolNames = append(toolNames, "solr.query")
+	}, st.toolParamsSet)
+	toolNames = append(toolNames, "solr.params.set")
 
-	// solr.ping tool
-	mcp.AddTool(mcpServer, &mcp.Tool{
-		Name:        "solr.ping",
-		Description: "Check Solr cluster health (live nodes)",
+	// solr.experiment.report tool
+	addTool(mcpServer, st, &mcp.Tool{
+		Name:        "solr.experiment.report",
+		Description: "Compare the variants of the A/B experiments on solr.query: executions, zero-result rate, click rate and latency per variant",
 		InputSchema: map[string]any{
-			"type":       "object",
-			"properties": map[string]any{},
+			"type": "object",
+			"properties": map[string]any{
+				"name": map[string]any{
+					"type":        "string",
+					"description": "Only report this experiment",
+				},
+			},
 		},
-	}, st.toolPing)
-	toolNames = append(toolNames, "solr.ping")
+	}, st.toolExperimentReport)
+	toolNames = append(toolNames, "solr.experiment.report")
 
-	// solr.collection.health tool
-	mcp.AddTool(mcpServer, &mcp.Tool{
-		Name:        "solr.collection.health",
-		Description: "Check specific collection health status",
+	// solr.feedback.click tool
+	addTool(mcpServer, st, &mcp.Tool{
+		Name:        "solr.feedback.click",
+		Description: "Record that the user opened a document returned by solr.query, as a relevance signal for click-through rates and experiments",
 		InputSchema: map[string]any{
 			"type": "object",
 			"properties": map[string]any{
 				"collection": map[string]any{
 					"type":        "string",
-					"description": "Solr collection name",
+					"description": "Collection of the query",
+				},
+				"query": map[string]any{
+					"type":        "string",
+					"description": "The query text that returned the document",
+				},
+				"docId": map[string]any{
+					"type":        "string",
+					"description": "uniqueKey value of the opened document",
+				},
+				"position": map[string]any{
+					"type":        "integer",
+					"description": "1-based rank of the document in the results",
+				},
+				"experiment": map[string]any{
+					"type":        "string",
+					"description": "experiment.name of the solr.query result, if any",
+				},
+				"variant": map[string]any{
+					"type":        "string",
+					"description": "experiment.variant of the solr.query result, if any",
 				},
 			},
-			"required": []string{"collection"},
+			"required": []string{"collection", "docId"},
 		},
-	}, st.toolCollectionHealth)
-	toolNames = append(toolNames, "solr.collection.health")
+	}, st.toolFeedbackClick)
+	toolNames = append(toolNames, "solr.feedback.click")
+
+	// solr.feedback.report tool
+	addTool(mcpServer, st, &mcp.Tool{
+		Name:        "solr.feedback.report",
+		Description: "Click-through rate per query from the clicks recorded with solr.feedback.click, with the most opened documents",
+		InputSchema: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"collection": map[string]any{
+					"type":        "string",
+					"description": "Only report queries on this collection",
+				},
+				"query": map[string]any{
+					"type":        "string",
+					"description": "Only report this query text",
+				},
+				"sort": map[string]any{
+					"type":        "string",
+					"enum":        []string{"executions", "clicks", "ctr"},
+					"description": "Sort order, descending (default: executions)",
+				},
+				"limit": map[string]any{
+					"type":        "integer",
+					"description": "Maximum number of queries (default: 20)",
+				},
+			},
+		},
+	}, st.toolFeedbackReport)
+	toolNames = append(toolNames, "solr.feedback.report")
 
 	// solr.schema tool
-	mcp.AddTool(mcpServer, &mcp.Tool{
+	addTool(mcpServer, st, &mcp.Tool{
 		Name:        "solr.schema",
 		Description: "Get Solr schema information",
 		InputSchema: map[string]any{
@@ -116,87 +877,363 @@ func AddTools(mcpServer *mcp.Server, st *State) []string {
 	}, st.toolSchema)
 	toolNames = append(toolNames, "solr.schema")
 
-	return toolNames
+	// solr.explain_query tool
+	addTool(mcpServer, st, &mcp.Tool{
+		Name:        "solr.explain_query",
+		Description: "Explain in plain language what raw Solr /select parameters or a saved query match and return, checking the referenced fields against the collection schema. Does not run the query",
+		InputSchema: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"collection": map[string]any{
+					"type":        "string",
+					"description": "Solr collection whose schema is used to check fields (optional)",
+				},
+				"params": map[string]any{
+					"type":        "object",
+					"description": "Raw /select parameters, e.g. {\"q\": \"title:solr\", \"fq\": [\"status:active\"], \"sort\": \"price asc\"}",
+				},
+				"savedQuery": map[string]any{
+					"type":        "string",
+					"description": "Name of a saved query from the config file to explain instead of params",
+				},
+			},
+		},
+	}, st.toolExplainQuery)
+	toolNames = append(toolNames, "solr.explain_query")
+
+	// solr.info tool
+	addTool(mcpServer, st, &mcp.Tool{
+		Name:        "solr.info",
+		Description: "Show server configuration and which optional capabilities are enabled, with setup hints for disabled ones",
+		InputSchema: map[string]any{
+			"type":       "object",
+			"properties": map[string]any{},
+		},
+	}, st.toolInfo)
+	toolNames = append(toolNames, "solr.info")
+
+	// solr.server.stats tool
+	addTool(mcpServer, st, &mcp.Tool{
+		Name:        "solr.server.stats",
+		Description: "Show per-tool invocation counts, failure rates, average result sizes and durations, with recommendations derived from repeated failures",
+		InputSchema: map[string]any{
+			"type":       "object",
+			"properties": map[string]any{},
+		},
+	}, st.toolServerStats)
+	toolNames = append(toolNames, "solr.server.stats")
+
+	// solr.server.sessions tool
+	addTool(mcpServer, st, &mcp.Tool{
+		Name:        "solr.server.sessions",
+		Description: "Show the calling MCP session with client name and version, age, last activity and tool call counts, and how many other sessions are open",
+		InputSchema: map[string]any{
+			"type":       "object",
+			"properties": map[string]any{},
+		},
+	}, st.toolServerSessions)
+	toolNames = append(toolNames, "solr.server.sessions")
+
+	// solr.metrics.history tool
+	addTool(mcpServer, st, &mcp.Tool{
+		Name:        "solr.metrics.history",
+		Description: "Answer questions about recent Solr metric values, such as the trend of a cache hit ratio, from the solr-exporter samples this server scraped. Returns series with points and min/max/avg/first/last, change, trend and the rate of counters. Without a metric, lists the recorded metrics",
+		InputSchema: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"metric": map[string]any{
+					"type":        "string",
+					"description": "Metric name, or a regular expression matching whole names (e.g. solr_metrics_core_searcher_cache_.*)",
+				},
+				"labels": map[string]any{
+					"type":                 "object",
+					"additionalProperties": map[string]any{"type": "string"},
+					"description":          "Exact label values the series must have, e.g. {\"cache\": \"queryResultCache\"}",
+				},
+				"since": map[string]any{
+					"type":        "string",
+					"description": "Window as a Go duration back from now, e.g. 30m or 2h (default: the whole retained history)",
+				},
+				"maxPoints": map[string]any{
+					"type":        "integer",
+					"description": fmt.Sprintf("Points returned per series at most, evenly spaced; summaries use all points (default: %d)", defaultHistoryPoints),
+				},
+				"maxSeries": map[string]any{
+					"type":        "integer",
+					"description": fmt.Sprintf("Series returned at most (default: %d)", defaultHistorySeries),
+				},
+			},
+		},
+	}, st.toolMetricsHistory)
+	toolNames = append(toolNames, "solr.metrics.history")
+
+	// solr.permissions tool
+	addTool(mcpServer, st, &mcp.Tool{
+		Name:        "solr.permissions",
+		Description: "Explain what the Solr user of this server may do: its roles, the authorization rules of the cluster, whether each Solr permission (read, update, schema-edit, ...) is allowed and by which rule, and which tools of this server will be refused. Use it when a tool fails with HTTP 403, or before indexing, to tell the user which permission is missing",
+		InputSchema: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"collection": map[string]any{
+					"type":        "string",
+					"description": "Collection to judge collection permissions for (default: rules limited to named collections are ignored)",
+				},
+				"user": map[string]any{
+					"type":        "string",
+					"description": "Another Solr user to judge, by the roles of the authorization config (needs the security-read permission; default: the user of this server)",
+				},
+			},
+		},
+	}, st.toolPermissions)
+	toolNames = append(toolNames, "solr.permissions")
+
+	return append(toolNames, st.addCustomTools(mcpServer)...)
+}
+
+// confirmDescription and confirmProperty describe the two-call handshake of destructive tools.
+const confirmDescription = "Two steps: the first call only returns a preview and a confirmationToken; call again with the same arguments and confirm set to the token to execute"
+
+var confirmProperty = map[string]any{
+	"type":        "string",
+	"description": "confirmationToken returned by the previous call with the same arguments",
+}
+
+var previewProperty = map[string]any{
+	"type":        "boolean",
+	"description": "Only report the affected documents without modifying the index",
+}
+
+// addTool records the registration of a tool so it can be disabled and re-enabled at runtime,
+// and registers it unless it is disabled in the config file.
+func addTool[In, Out any](mcpServer *mcp.Server, st *State, t *mcp.Tool, h mcp.ToolHandlerFor[In, Out]) {
+	st.toolMu.Lock()
+	defer st.toolMu.Unlock()
+
+	if st.toolRegistry == nil {
+		st.toolRegistry = make(map[string]func(*mcp.Server))
+		st.toolDefs = make(map[string]*mcp.Tool)
+		st.enabledTools = make(map[string]bool)
+	}
+	call := func(ctx context.Context, req *mcp.CallToolRequest, in In) (res *mcp.CallToolResult, out Out, err error) {
+		st.touchSession(req.Session, t.Name)
+		ctx = st.watchCall(ctx, req, t.Name)
+		if err = st.readOnlyError(t.Name); err != nil {
+			return nil, out, err
+		}
+		if err = st.standbyWriteError(t.Name); err != nil {
+			return nil, out, err
+		}
+		if err = st.authorize(ctx, req, t.Name, in, nil); err != nil {
+			return nil, out, err
+		}
+		if err = st.toolUnsupported(ctx, t.Name); err != nil {
+			return nil, out, err
+		}
+		if err = st.missingCollection(t.Name, in); err != nil {
+			return nil, out, err
+		}
+		ctx, release, err := st.admit(ctx, t.Name)
+		if err != nil {
+			return nil, out, err
+		}
+		defer release()
+		res, out, err = h(ctx, req, in)
+		res = markFailover(st, res, out, err)
+		if err != nil {
+			err = st.explainMissingCollection(ctx, t.Name, in, err)
+			err = st.explainForbidden(t.Name, err)
+			return res, out, err
+		}
+		return postProcess(ctx, st, t.Name, res, out)
+	}
+	processed := func(ctx context.Context, req *mcp.CallToolRequest, in In) (*mcp.CallToolResult, Out, error) {
+		start := time.Now()
+		res, out, err := call(ctx, req, in)
+		recordUsage(st, t.Name, start, &out, &err)
+		if err != nil {
+			var zero Out
+			return st.errorResult(t.Name, err), zero, nil
+		}
+		return res, out, nil
+	}
+	register := func(s *mcp.Server) { mcp.AddTool(s, t, processed) }
+	st.toolRegistry[t.Name] = register
+	st.toolDefs[t.Name] = t
+	st.toolOrder = append(st.toolOrder, t.Name)
+	if st.toolDisabled(t.Name) {
+		slog.Info("Tool disabled by config", "tool", t.Name)
+		return
+	}
+	register(mcpServer)
+	st.enabledTools[t.Name] = true
 }
 
 // Basic Tools
-func (st *State) toolQuery(ctx context.Context, _ *mcp.CallToolRequest, in types.QueryIn) (*mcp.CallToolResult, any, error) {
+func (st *State) toolQuery(ctx context.Context, req *mcp.CallToolRequest, in types.QueryIn) (*mcp.CallToolResult, any, error) {
 	if strings.TrimSpace(in.Collection) == "" {
 		return nil, nil, errors.New("input.collection is required")
 	}
-	qString := in.Query
-	if qString == "" {
-		qString = "*:*"
+	if in.Select != "" {
+		fields, err := st.selectFields(ctx, in)
+		if err != nil {
+			return nil, nil, fmt.Errorf("input.select: %v", err)
+		}
+		in.Fields = fields
 	}
+	rewrites := solr.ExpandQueryShorthands(&in, st.rangeFieldKinds(ctx, in.Collection))
+	query := solr.BuildQuery(in)
+	slog.Debug("Executing Solr query", "collection", in.Collection, "query", query)
 
-	// Use simple query without parser wrapper to avoid {!lucene v=...} syntax issues
-	// This allows complex queries with parentheses and multiple operators to work correctly
-	query := solr_sdk.NewQuery(qString)
-	if len(in.Fields) > 0 {
-		query = query.Fields(in.Fields...)
+	params := solr.QueryValues(query)
+	var guardrails []types.Guardrail
+	lc := st.fileConfig().QueryLimits
+	if g := clampRows(params, lc.MaxRows); g != nil {
+		guardrails = append(guardrails, *g)
 	}
-	if len(in.FilterQuery) > 0 {
-		query = query.Filters(in.FilterQuery...)
+	var limits *solr.QueryLimits
+	if st.Backend == nil && lc != (config.QueryLimitsConfig{MaxRows: lc.MaxRows}) {
+		version, known := st.solrVersion(ctx)
+		limits = solr.ApplyQueryLimits(params, lc, version, known)
+		guardrails = append(guardrails, limitGuardrails(limits, version)...)
 	}
-	if in.Sort != "" {
-		query = query.Sort(in.Sort)
+	removeDebug := false
+	if in.MatchedOn && st.Backend == nil {
+		removeDebug = solr.MatchedOnParams(params)
 	}
-	if in.Start != nil {
-		query = query.Offset(*in.Start)
+	if in.Elevate && st.Backend == nil {
+		solr.ElevationParams(params)
 	}
-	if in.Rows != nil {
-		query = query.Limit(*in.Rows)
+	if len(in.UseParams) > 0 && st.Backend != nil {
+		return nil, nil, errors.New("input.useParams: parameter sets need Solr")
 	}
-
-	// Merge params with echoParams if needed
-	params := make(map[string]any)
-	for k, v := range in.Params {
-		params[k] = v
+	if in.Destination != "" {
+		if _, err := st.objectStore(in.Destination); err != nil {
+			return nil, nil, err
+		}
 	}
-	if in.EchoParams {
-		params["echoParams"] = "all"
+	if in.LTR != nil {
+		if st.Backend != nil {
+			return nil, nil, errors.New("input.ltr: Learning To Rank needs Solr")
+		}
+		if err := solr.LTRParams(params, *in.LTR); err != nil {
+			return nil, nil, fmt.Errorf("input.ltr: %v", err)
+		}
 	}
-	if len(params) > 0 {
-		query = query.Params(solr_sdk.M(params))
+	// Experiments only apply to executed queries, so plans show the request as called. The parameters of the
+	// variant are authorized with the rest of the request.
+	var assignment *experiment.Assignment
+	if !in.PlanOnly {
+		if assignment = st.experiments().Assign(in.Collection); assignment != nil {
+			assignment.Apply(params)
+			if g := experimentGuardrail(assignment); g != nil {
+				guardrails = append(guardrails, *g)
+			}
+		}
+	}
+	risky, safety := st.querySafety(ctx, in.Collection, params)
+	guardrails = append(guardrails, safety...)
+	if err := st.authorize(ctx, req, "solr.query", in, params); err != nil {
+		return nil, nil, err
+	}
+	if in.PlanOnly {
+		plan := st.queryPlan(in.Collection, params, limits)
+		if rewrites != nil {
+			plan["expanded"] = rewrites
+		}
+		if guardrails != nil {
+			plan["guardrails"] = guardrails
+		}
+		if stats := st.planFieldStats(ctx, in.Collection, params); stats != nil {
+			plan["fieldStats"] = stats
+		}
+		if risky != nil {
+			plan["expensiveTerms"] = risky
+		}
+		return nil, plan, nil
+	}
+	if len(risky) > 0 {
+		token := in.Confirm
+		in.Confirm = ""
+		if token == "" {
+			out, err := st.confirmationRequired("solr.query", in, expensiveQueryPreview(in, risky))
+			return nil, out, err
+		}
+		if err := st.confirmStore().consume(token, "solr.query", in); err != nil {
+			return nil, nil, err
+		}
 	}
 
-	slog.Debug("Executing Solr query", "collection", in.Collection, "query", query)
-
-	resp, err := solr.QueryWithRawResponse(ctx, st.HttpClient, st.BaseURL, st.BasicUser, st.BasicPass, in.Collection, query)
-
-	return nil, resp, err
-}
-
-func (st *State) toolPing(ctx context.Context, _ *mcp.CallToolRequest, in types.PingIn) (*mcp.CallToolResult, any, error) {
-	// Use CLUSTERSTATUS API without collection parameter to get cluster-wide status
-	// Following solr-go SDK pattern (similar to CreateCollection/DeleteCollection)
-	urlStr := fmt.Sprintf("%s/solr/admin/collections?action=CLUSTERSTATUS&wt=json", st.BaseURL)
-	slog.Debug("Checking cluster status", "url", urlStr)
-
-	// Create HTTP request
-	req, err := http.NewRequestWithContext(ctx, "GET", urlStr, nil)
+	start := time.Now()
+	resp, node, err := st.sharedQuery(ctx, in.Collection, params)
 	if err != nil {
-		return nil, nil, fmt.Errorf("create request: %v", err)
+		return nil, nil, err
+	}
+	if assignment != nil {
+		st.recordExperiment(assignment, resp, time.Since(start))
+	}
+	st.feedbackTracker().RecordExecution(in.Collection, in.Query)
+	if suggestions := st.filterSuggestions(ctx, in, resp); len(suggestions) > 0 {
+		if !in.AutoCorrect {
+			resp["suggestions"] = suggestions
+		} else {
+			slog.Info("Correcting misspelled filters", "collection", in.Collection, "corrections", suggestions)
+			params = correctFilters(params, suggestions)
+			if resp, node, err = st.sharedQuery(ctx, in.Collection, params); err != nil {
+				return nil, nil, err
+			}
+			resp["corrections"] = suggestions
+		}
+	}
+	st.analyzeSlowQuery(ctx, node, params, resp)
+	// Uploaded results are not sent through the MCP channel, so they keep all fields
+	if in.Destination == "" {
+		var narrowed *types.Guardrail
+		if resp, narrowed, err = st.fitResponseBudget(ctx, in.Collection, params, resp); err != nil {
+			return nil, nil, err
+		}
+		if narrowed != nil {
+			guardrails = append(guardrails, *narrowed)
+		}
+	}
+	if in.MatchedOn {
+		st.annotateMatches(ctx, in.Collection, resp, removeDebug)
+	}
+	if applied := st.docTransformers().Apply(in.Collection, resp); len(applied) > 0 {
+		resp["transformed"] = applied
+	}
+	if limits != nil {
+		limits.ObservePartialResults(resp)
+		if limits.PartialResults {
+			slog.Warn("Query stopped at a resource limit, results are partial", "collection", in.Collection, "limits", limits.Applied)
+		}
+		resp["limits"] = limits
+	}
+	if rewrites != nil {
+		resp["expanded"] = rewrites
+	}
+	if guardrails != nil {
+		resp["guardrails"] = guardrails
 	}
 
-	// Add basic auth if configured
-	if st.BasicUser != "" && st.BasicPass != "" {
-		req.SetBasicAuth(st.BasicUser, st.BasicPass)
+	// Surface Solr warnings at the top level so they are not buried in the response header
+	if warnings := solr.ExtractWarnings(resp); len(warnings) > 0 {
+		slog.Warn("Solr returned warnings", "collection", in.Collection, "warnings", warnings)
+		resp["warnings"] = warnings
 	}
 
-	// Send request
-	httpResp, err := st.HttpClient.Do(req)
-	if err != nil {
-		slog.Error("Cluster status request failed", "error", err)
-		return nil, nil, fmt.Errorf("cluster status request: %v", err)
+	if in.Destination != "" {
+		out, err := st.uploadQueryResult(ctx, in.Collection, in.Destination, resp)
+		return nil, out, err
 	}
-	defer httpResp.Body.Close()
+	return nil, resp, nil
+}
 
-	// Decode response
-	var clusterResp config.ClusterStatusResponse
-	if err := json.NewDecoder(httpResp.Body).Decode(&clusterResp); err != nil {
-		slog.Error("Failed to decode cluster status", "error", err)
-		return nil, nil, fmt.Errorf("decode response: %v", err)
+func (st *State) toolPing(ctx context.Context, _ *mcp.CallToolRequest, in types.PingIn) (*mcp.CallToolResult, any, error) {
+	// Use CLUSTERSTATUS without a collection to get cluster-wide status
+	clusterResp, err := st.backend().ClusterStatus(ctx, "")
+	if err != nil {
+		slog.Error("Cluster status request failed", "error", err)
+		return nil, nil, err
 	}
 
 	// Return cluster-wide health information
@@ -213,36 +1250,11 @@ func (st *State) toolCollectionHealth(ctx context.Context, _ *mcp.CallToolReques
 		return nil, nil, errors.New("input.collection is required")
 	}
 
-	// Use CLUSTERSTATUS API with collection parameter
-	// Following solr-go SDK pattern
-	urlStr := fmt.Sprintf("%s/solr/admin/collections?action=CLUSTERSTATUS&collection=%s&wt=json", st.BaseURL, in.Collection)
-	slog.Debug("Checking collection health", "collection", in.Collection, "url", urlStr)
-
-	// Create HTTP request
-	req, err := http.NewRequestWithContext(ctx, "GET", urlStr, nil)
-	if err != nil {
-		return nil, nil, fmt.Errorf("create request: %v", err)
-	}
-
-	// Add basic auth if configured
-	if st.BasicUser != "" && st.BasicPass != "" {
-		req.SetBasicAuth(st.BasicUser, st.BasicPass)
-	}
-
-	// Send request
-	httpResp, err := st.HttpClient.Do(req)
+	clusterResp, err := st.backend().ClusterStatus(ctx, in.Collection)
 	if err != nil {
 		slog.Error("Collection health check failed", "error", err)
 		return nil, nil, fmt.Errorf("collection health check: %v", err)
 	}
-	defer httpResp.Body.Close()
-
-	// Decode response
-	var clusterResp config.ClusterStatusResponse
-	if err := json.NewDecoder(httpResp.Body).Decode(&clusterResp); err != nil {
-		slog.Error("Failed to decode collection health", "error", err)
-		return nil, nil, fmt.Errorf("decode response: %v", err)
-	}
 
 	// Extract collection status
 	collStatus, ok := clusterResp.Cluster.Collections[in.Collection]
@@ -260,22 +1272,119 @@ func (st *State) toolCollectionHealth(ctx context.Context, _ *mcp.CallToolReques
 	}, nil
 }
 
+// queryPlan describes the request solr.query would send for params, for review before running it.
+func (st *State) queryPlan(collection string, params url.Values, limits *solr.QueryLimits) map[string]any {
+	plan := map[string]any{
+		"planOnly":     true,
+		"collection":   collection,
+		"selectParams": params,
+	}
+	if limits != nil {
+		plan["limits"] = limits
+	}
+	switch b := st.Backend.(type) {
+	case nil:
+		plan["method"] = http.MethodGet
+		plan["url"] = config.RedactURL(solr.SelectURL(st.solrURL(), collection) + "?" + params.Encode())
+	case *opensearch.Backend:
+		body, _ := opensearch.SearchBody(params)
+		plan["method"] = http.MethodPost
+		plan["url"] = config.RedactURL(b.BaseURL + "/" + url.PathEscape(collection) + "/_search")
+		plan["body"] = body
+	}
+	return plan
+}
+
+func (st *State) toolQueryShards(ctx context.Context, _ *mcp.CallToolRequest, in types.ShardQueryIn) (*mcp.CallToolResult, any, error) {
+	if strings.TrimSpace(in.Collection) == "" {
+		return nil, nil, errors.New("input.collection is required")
+	}
+
+	status, err := solr.GetClusterStatus(ctx, st.HttpClient, st.solrURL(), st.BasicUser, st.BasicPass, in.Collection)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	query := solr_sdk.NewQuery(utils.Choose(in.Query, "*:*"))
+	if len(in.FilterQuery) > 0 {
+		query = query.Filters(in.FilterQuery...)
+	}
+	// solr-go drops a zero limit, so request rows=0 explicitly
+	query = query.Params(solr_sdk.M{"rows": 0})
+	results := solr.QueryReplicas(ctx, st.HttpClient, st.BasicUser, st.BasicPass, status, query)
+	shards, findings := solr.SummarizeShards(results)
+	for _, f := range findings {
+		slog.Warn("Shard diagnostic finding", "collection", in.Collection, "finding", f)
+	}
+
+	var total int64
+	for _, s := range shards {
+		total += s.NumFound
+	}
+	return nil, map[string]any{
+		"collection": in.Collection,
+		"numFound":   total,
+		"shards":     shards,
+		"findings":   findings,
+	}, nil
+}
+
+func (st *State) toolConsistencyCheck(ctx context.Context, _ *mcp.CallToolRequest, in types.ConsistencyCheckIn) (*mcp.CallToolResult, any, error) {
+	if strings.TrimSpace(in.Collection) == "" {
+		return nil, nil, errors.New("input.collection is required")
+	}
+
+	status, err := solr.GetClusterStatus(ctx, st.HttpClient, st.solrURL(), st.BasicUser, st.BasicPass, in.Collection)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	shards, findings := solr.CheckConsistency(ctx, st.HttpClient, st.BasicUser, st.BasicPass, status, in.Canary)
+	consistent := true
+	for _, s := range shards {
+		consistent = consistent && s.Consistent
+	}
+	for _, f := range findings {
+		slog.Warn("Replica consistency finding", "collection", in.Collection, "finding", f)
+	}
+
+	return nil, map[string]any{
+		"collection": in.Collection,
+		"consistent": consistent,
+		"shards":     shards,
+		"findings":   findings,
+	}, nil
+}
+
 // Smart Search Tool
 func (st *State) toolSchema(ctx context.Context, _ *mcp.CallToolRequest, in types.SchemaIn) (*mcp.CallToolResult, any, error) {
 	if strings.TrimSpace(in.Collection) == "" {
 		return nil, nil, errors.New("input.collection is required")
 	}
 
-	sCtx := solr.SchemaContext{
-		HttpClient: st.HttpClient,
-		BaseURL:    st.BaseURL,
-		User:       st.BasicUser,
-		Pass:       st.BasicPass,
-		Cache:      &st.SchemaCache,
-	}
-	fc, err := solr.GetFieldCatalog(ctx, sCtx, in.Collection)
+	fc, err := st.backend().Schema(ctx, in.Collection)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to get schema: %v", err)
 	}
 	return nil, fc, nil
 }
+
+func (st *State) toolInfo(ctx context.Context, _ *mcp.CallToolRequest, in types.InfoIn) (*mcp.CallToolResult, any, error) {
+	info := map[string]any{
+		"version":           config.Version,
+		"solrUrl":           config.RedactURL(st.BaseURL),
+		"defaultCollection": st.DefaultCollection,
+		"tools":             st.EnabledToolNames(),
+		"capabilities":      st.Capabilities(),
+	}
+	if m := st.failoverMonitor(); m != nil {
+		info["failover"] = m.Status()
+	}
+	if r := st.nodeRouter(); r != nil {
+		info["nodes"] = r.Stats()
+	}
+	if v, ok := st.solrVersion(ctx); ok {
+		info["solrVersion"] = v
+	}
+	return nil, info, nil
+}
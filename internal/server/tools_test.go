@@ -3,32 +3,67 @@ package server
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
-	"solr-mcp-go/internal/types"
+	"net/url"
 	"strings"
 	"testing"
-	"time"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/config"
+	"github.com/Sashimimochi/solr-mcp-go/internal/solr"
+	"github.com/Sashimimochi/solr-mcp-go/internal/types"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
-	solr "github.com/stevenferrer/solr-go"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
+// allToolNames lists the built-in tools in registration order.
+var allToolNames = []string{
+	"solr.query",
+	"solr.ping",
+	"solr.collection.health",
+	"solr.query.shards",
+	"solr.consistency.check",
+	"solr.healthcheck.full",
+	"solr.dedupe.find",
+	"solr.profile",
+	"solr.drift.report",
+	"solr.delete",
+	"solr.update",
+	"solr.collection.drop",
+	"solr.retention.list",
+	"solr.retention.preview",
+	"solr.retention.run",
+	"solr.maintenance.status",
+	"solr.archive",
+	"solr.archive.restore",
+	"solr.export",
+	"solr.reindex",
+	"solr.import",
+	"solr.jobs",
+	"solr.elevation.list",
+	"solr.elevation.set",
+	"solr.ltr.list",
+	"solr.ltr.upload",
+	"solr.params.list",
+	"solr.params.set",
+	"solr.experiment.report",
+	"solr.feedback.click",
+	"solr.feedback.report",
+	"solr.schema",
+	"solr.explain_query",
+	"solr.info",
+	"solr.server.stats",
+	"solr.server.sessions",
+	"solr.metrics.history",
+	"solr.permissions",
+}
+
 // newTestState creates a test State and HTTP mock server client.
 func newTestState(t *testing.T, baseURL string) *State {
-	client := solr.NewJSONClient(baseURL)
-	return &State{
-		SolrClient:        client,
-		BaseURL:           baseURL,
-		DefaultCollection: "test",
-		HttpClient:        &http.Client{},
-		SchemaCache: types.SchemaCache{
-			LastFetch: make(map[string]time.Time),
-			TTL:       10 * time.Minute,
-			ByCol:     make(map[string]*types.FieldCatalog),
-		},
-	}
+	return NewServer(WithSolrURL(baseURL), WithDefaultCollection("test"), WithHTTPClient(&http.Client{}))
 }
 
 // TestToolQuery tests the toolQuery method.
@@ -221,6 +256,187 @@ func TestToolQuery(t *testing.T) {
 
 		assert.Error(t, err)
 	})
+
+	t.Run("Success: Solr warnings surfaced", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(map[string]any{
+				"responseHeader": map[string]any{"status": 0, "warning": "Parameter 'qt' is deprecated"},
+				"response":       map[string]any{"numFound": 0, "docs": []any{}},
+			})
+		}))
+		defer server.Close()
+
+		st := newTestState(t, server.URL)
+		_, resp, err := st.toolQuery(context.Background(), nil, types.QueryIn{Collection: "testcol"})
+
+		// Goal: Warnings from the response header appear as a top-level warnings array.
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"Parameter 'qt' is deprecated"}, resp.(map[string]any)["warnings"])
+	})
+
+	t.Run("Success: configured query limits are applied and reported", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			if r.URL.Path == "/solr/admin/info/system" {
+				w.Write([]byte(`{"mode":"solrcloud","lucene":{"solr-spec-version":"9.8.0"}}`))
+				return
+			}
+			q := r.URL.Query()
+			assert.Equal(t, "5000", q.Get("timeAllowed"))
+			assert.Equal(t, "1000", q.Get("cpuAllowed"))
+			json.NewEncoder(w).Encode(map[string]any{
+				"responseHeader": map[string]any{"status": 0, "partialResults": true},
+				"response":       map[string]any{"numFound": 3, "docs": []any{}},
+			})
+		}))
+		defer server.Close()
+
+		st := newTestState(t, server.URL)
+		st.Config = &config.FileConfig{QueryLimits: config.QueryLimitsConfig{TimeAllowed: 5000, CPUAllowed: 1000}}
+		_, resp, err := st.toolQuery(context.Background(), nil, types.QueryIn{Collection: "testcol"})
+
+		// Goal: The applied limits and partial results are surfaced in the output.
+		assert.NoError(t, err)
+		limits := resp.(map[string]any)["limits"].(*solr.QueryLimits)
+		assert.Equal(t, map[string]string{"timeAllowed": "5000", "cpuAllowed": "1000"}, limits.Applied)
+		assert.True(t, limits.PartialResults)
+	})
+
+	t.Run("Success: planOnly returns the request without running it", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			t.Errorf("Unexpected request: %s", r.URL)
+		}))
+		defer server.Close()
+
+		st := newTestState(t, server.URL)
+		rows := 5
+		_, resp, err := st.toolQuery(context.Background(), nil, types.QueryIn{Collection: "testcol", Query: "title:solr", Rows: &rows, PlanOnly: true})
+
+		// Goal: The method, URL and parameters of the /select request are returned and Solr is not called.
+		assert.NoError(t, err)
+		plan := resp.(map[string]any)
+		assert.Equal(t, http.MethodGet, plan["method"])
+		assert.Contains(t, plan["url"], server.URL+"/solr/testcol/select?")
+		assert.Contains(t, plan["url"], "q=title%3Asolr")
+		assert.Equal(t, "5", plan["selectParams"].(url.Values).Get("rows"))
+	})
+
+	t.Run("Success: misspelled filter values are suggested or corrected", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			q := r.URL.Query()
+			switch {
+			case r.URL.Path != "/solr/logs/select":
+				http.NotFound(w, r)
+			case q.Get("facet.field") == "level":
+				fmt.Fprint(w, `{"response":{"numFound":12,"docs":[]},"facet_counts":{"facet_fields":{"level":["ERROR",10,"INFO",2]}}}`)
+			case q.Get("fq") == "level:ERROR":
+				fmt.Fprint(w, `{"response":{"numFound":10,"docs":[]}}`)
+			default:
+				fmt.Fprint(w, `{"response":{"numFound":0,"docs":[]}}`)
+			}
+		}))
+		defer server.Close()
+
+		st := newTestState(t, server.URL)
+		in := types.QueryIn{Collection: "logs", FilterQuery: []string{"level:ERORR"}}
+
+		// Goal: A zero-hit filter with a near match on a low-cardinality field gets a suggestion.
+		_, resp, err := st.toolQuery(context.Background(), nil, in)
+		require.NoError(t, err)
+		suggestions := resp.(map[string]any)["suggestions"].([]solr.FilterSuggestion)
+		require.Len(t, suggestions, 1)
+		assert.Equal(t, "level:ERROR", suggestions[0].Suggestion)
+
+		// Goal: With autoCorrect, the query is rerun with the corrected filter and the correction is noted.
+		in.AutoCorrect = true
+		_, resp, err = st.toolQuery(context.Background(), nil, in)
+		require.NoError(t, err)
+		out := resp.(map[string]any)
+		assert.Equal(t, float64(10), out["response"].(map[string]any)["numFound"])
+		assert.Len(t, out["corrections"], 1)
+	})
+
+	t.Run("Success: matchedOn annotates documents", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			switch r.URL.Path {
+			case "/solr/testcol/schema/uniquekey":
+				fmt.Fprint(w, `{"uniqueKey":"id"}`)
+			case "/solr/testcol/schema/fields":
+				fmt.Fprint(w, `{"fields":[{"name":"id","type":"string"},{"name":"title","type":"text_general"}]}`)
+			case "/solr/testcol/select":
+				if r.URL.Query().Get("debug.explain.structured") != "true" {
+					t.Errorf("Expected a structured explanation request, got: %s", r.URL.RawQuery)
+				}
+				fmt.Fprint(w, `{"response":{"numFound":1,"docs":[{"id":"1"}]},
+					"debug":{"explain":{"1":{"match":true,"value":1.5,"description":"weight(title:solr in 0) [SchemaSimilarity], result of:"}}}}`)
+			default:
+				http.NotFound(w, r)
+			}
+		}))
+		defer server.Close()
+
+		st := newTestState(t, server.URL)
+		_, resp, err := st.toolQuery(context.Background(), nil, types.QueryIn{Collection: "testcol", Query: "title:solr", MatchedOn: true})
+
+		// Goal: Documents list the clauses that matched them and the debug section requested for it is removed.
+		require.NoError(t, err)
+		out := resp.(map[string]any)
+		doc := out["response"].(map[string]any)["docs"].([]any)[0].(map[string]any)
+		assert.Equal(t, []string{"title:solr"}, doc["matchedOn"])
+		assert.NotContains(t, out, "debug")
+	})
+
+	t.Run("Success: select is compiled into fl", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			switch r.URL.Path {
+			case "/solr/testcol/schema/uniquekey":
+				fmt.Fprint(w, `{"uniqueKey":"id"}`)
+			case "/solr/testcol/schema/fields":
+				fmt.Fprint(w, `{"fields":[{"name":"id","type":"string"},{"name":"rating","type":"pint"}]}`)
+			default:
+				http.NotFound(w, r)
+			}
+		}))
+		defer server.Close()
+
+		st := newTestState(t, server.URL)
+		ctx := context.Background()
+
+		// Goal: Fields and transformers of the selection are sent as fl.
+		_, resp, err := st.toolQuery(ctx, nil, types.QueryIn{Collection: "testcol", Select: "id @child(limit: 2) { id rating }", PlanOnly: true})
+		require.NoError(t, err)
+		params := resp.(map[string]any)["selectParams"].(url.Values)
+		assert.Equal(t, []string{"id", "[child limit='2' fl='id,rating']"}, params["fl"])
+
+		// Goal: Unknown fields are rejected before the query is sent.
+		_, _, err = st.toolQuery(ctx, nil, types.QueryIn{Collection: "testcol", Select: "id price"})
+		assert.ErrorContains(t, err, "input.select: offset 3: field price does not exist")
+
+		// Goal: fl and select cannot be combined.
+		_, _, err = st.toolQuery(ctx, nil, types.QueryIn{Collection: "testcol", Select: "id", Fields: []string{"id"}})
+		assert.ErrorContains(t, err, "use either fl or select")
+	})
+
+	t.Run("Success: configured transformers reshape documents", func(t *testing.T) {
+		st := NewServer(WithBackend(&fakeBackend{docs: map[string][]map[string]any{
+			"products": {{"id": "1", "prc_l": float64(1250)}},
+		}}))
+		st.Config = &config.FileConfig{Transformers: map[string][]config.TransformerConfig{
+			"products": {{Type: "convert", Field: "prc_l", To: "price", Factor: 0.01}},
+		}}
+		_, resp, err := st.toolQuery(context.Background(), nil, types.QueryIn{Collection: "products"})
+
+		// Goal: Documents are transformed with the steps of their collection and the applied steps are listed.
+		require.NoError(t, err)
+		out := resp.(map[string]any)
+		doc := out["response"].(map[string]any)["docs"].([]any)[0].(map[string]any)
+		assert.Equal(t, 12.5, doc["price"])
+		assert.Equal(t, []string{"convert"}, out["transformed"])
+	})
 }
 
 // TestToolPing tests the toolPing method.
@@ -295,8 +511,7 @@ func TestToolPing(t *testing.T) {
 		_, _, err := st.toolPing(context.Background(), nil, in)
 
 		assert.Error(t, err)
-		// JSON decode error is expected on HTTP error
-		assert.Contains(t, err.Error(), "decode response")
+		assert.Contains(t, err.Error(), "HTTP status 503")
 	})
 
 	t.Run("Error: invalid JSON", func(t *testing.T) {
@@ -313,7 +528,7 @@ func TestToolPing(t *testing.T) {
 		_, _, err := st.toolPing(context.Background(), nil, in)
 
 		assert.Error(t, err)
-		assert.Contains(t, err.Error(), "decode response")
+		assert.Contains(t, err.Error(), "JSON decode error")
 	})
 
 	t.Run("Error: network error", func(t *testing.T) {
@@ -480,7 +695,7 @@ func TestToolCollectionHealth(t *testing.T) {
 		_, _, err := st.toolCollectionHealth(context.Background(), nil, in)
 
 		assert.Error(t, err)
-		assert.Contains(t, err.Error(), "decode response")
+		assert.Contains(t, err.Error(), "JSON decode error")
 	})
 
 	t.Run("Error: network error", func(t *testing.T) {
@@ -588,32 +803,95 @@ func TestToolSchema(t *testing.T) {
 	})
 }
 
-// TestAddTools tests the AddTools function.
-func TestAddTools(t *testing.T) {
-	t.Run("Success: all tools are registered", func(t *testing.T) {
-		impl := &mcp.Implementation{}
-		mcpServer := mcp.NewServer(impl, nil)
+// TestToolQueryShards tests the toolQueryShards method.
+func TestToolQueryShards(t *testing.T) {
+	t.Run("Success: per-replica results and findings", func(t *testing.T) {
+		var server *httptest.Server
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			switch r.URL.Path {
+			case "/solr/admin/collections":
+				fmt.Fprintf(w, `{"cluster":{"collections":{"testcol":{"shards":{"shard1":{"replicas":{
+					"core_node1":{"core":"c1","base_url":%q,"leader":"true"},
+					"core_node2":{"core":"c2","base_url":%q}}}}}}}}`, server.URL+"/solr", server.URL+"/solr")
+			case "/solr/c1/select":
+				fmt.Fprintln(w, `{"response":{"numFound":5,"docs":[]}}`)
+			case "/solr/c2/select":
+				fmt.Fprintln(w, `{"response":{"numFound":4,"docs":[]}}`)
+			default:
+				http.NotFound(w, r)
+			}
+		}))
+		defer server.Close()
+
+		st := newTestState(t, server.URL)
+		_, resp, err := st.toolQueryShards(context.Background(), nil, types.ShardQueryIn{Collection: "testcol"})
+
+		// Goal: The collection total uses the leader count and replica divergence is reported.
+		assert.NoError(t, err)
+		out := resp.(map[string]any)
+		assert.Equal(t, int64(5), out["numFound"])
+		assert.Len(t, out["findings"], 1)
+	})
+
+	t.Run("Error: collection is required", func(t *testing.T) {
 		st := newTestState(t, "http://localhost:8983")
+		_, _, err := st.toolQueryShards(context.Background(), nil, types.ShardQueryIn{})
 
-		toolNames := AddTools(mcpServer, st)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "collection is required")
+	})
+}
+
+// TestToolConsistencyCheck tests the toolConsistencyCheck method.
+func TestToolConsistencyCheck(t *testing.T) {
+	t.Run("Success: divergent replica", func(t *testing.T) {
+		var server *httptest.Server
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			switch r.URL.Path {
+			case "/solr/admin/collections":
+				fmt.Fprintf(w, `{"cluster":{"collections":{"testcol":{"shards":{"shard1":{"replicas":{
+					"core_node1":{"core":"c1","base_url":%q,"leader":"true"},
+					"core_node2":{"core":"c2","base_url":%q}}}}}}}}`, server.URL+"/solr", server.URL+"/solr")
+			case "/solr/c1/select":
+				fmt.Fprintln(w, `{"response":{"numFound":5,"docs":[{"_version_":1790000000000000001}]}}`)
+			case "/solr/c2/select":
+				fmt.Fprintln(w, `{"response":{"numFound":5,"docs":[{"_version_":1790000000000000000}]}}`)
+			default:
+				http.NotFound(w, r)
+			}
+		}))
+		defer server.Close()
+
+		st := newTestState(t, server.URL)
+		_, resp, err := st.toolConsistencyCheck(context.Background(), nil, types.ConsistencyCheckIn{Collection: "testcol"})
 
-		assert.Len(t, toolNames, 4)
-		assert.Contains(t, toolNames, "solr.query")
-		assert.Contains(t, toolNames, "solr.ping")
-		assert.Contains(t, toolNames, "solr.collection.health")
-		assert.Contains(t, toolNames, "solr.schema")
+		// Goal: A _version_ difference beyond float64 precision is detected.
+		assert.NoError(t, err)
+		out := resp.(map[string]any)
+		assert.Equal(t, false, out["consistent"])
+		assert.Equal(t, []string{"shard1 replicas disagree on max(_version_) (core_node1=1790000000000000001, core_node2=1790000000000000000)"}, out["findings"])
 	})
 
-	t.Run("Success: tool order is correct", func(t *testing.T) {
+	t.Run("Error: collection is required", func(t *testing.T) {
+		st := newTestState(t, "http://localhost:8983")
+		_, _, err := st.toolConsistencyCheck(context.Background(), nil, types.ConsistencyCheckIn{})
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "collection is required")
+	})
+}
+
+// TestAddTools tests the AddTools function.
+func TestAddTools(t *testing.T) {
+	t.Run("Success: all tools are registered in order", func(t *testing.T) {
 		impl := &mcp.Implementation{}
 		mcpServer := mcp.NewServer(impl, nil)
 		st := newTestState(t, "http://localhost:8983")
 
 		toolNames := AddTools(mcpServer, st)
 
-		assert.Equal(t, "solr.query", toolNames[0])
-		assert.Equal(t, "solr.ping", toolNames[1])
-		assert.Equal(t, "solr.collection.health", toolNames[2])
-		assert.Equal(t, "solr.schema", toolNames[3])
+		assert.Equal(t, allToolNames, toolNames)
 	})
 }
@@ -0,0 +1,18 @@
+package server
+
+import (
+	"github.com/Sashimimochi/solr-mcp-go/internal/transform"
+)
+
+// docTransformers returns the document transformer registry, creating it on first use.
+func (st *State) docTransformers() *transform.Registry {
+	st.transformersOnce.Do(func() {
+		st.transformers = transform.NewRegistry(st.fileConfig().Transformers)
+	})
+	return st.transformers
+}
+
+// RegisterTransformer adds a document transformer type that the transformers config can reference.
+func (st *State) RegisterTransformer(name string, fn transform.Func) {
+	st.docTransformers().Register(name, fn)
+}
@@ -0,0 +1,69 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/solr"
+	"github.com/Sashimimochi/solr-mcp-go/internal/types"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// updateIDs validates an update request and returns the uniqueKey field and values of its documents.
+func (st *State) updateIDs(ctx context.Context, in types.UpdateIn) (string, []string, error) {
+	if strings.TrimSpace(in.Collection) == "" {
+		return "", nil, errors.New("input.collection is required")
+	}
+	if len(in.Documents) == 0 {
+		return "", nil, errors.New("input.documents is required")
+	}
+	key, err := st.requireUniqueKey(ctx, in.Collection)
+	if err != nil {
+		return "", nil, err
+	}
+	ids := make([]string, len(in.Documents))
+	seen := make(map[string]bool, len(in.Documents))
+	for i, doc := range in.Documents {
+		id, ok := solr.DocumentID(doc, key)
+		if !ok {
+			return "", nil, fmt.Errorf("documents[%d]: uniqueKey field %s is required", i, key)
+		}
+		if seen[id] {
+			return "", nil, fmt.Errorf("documents[%d]: duplicate %s %q", i, key, id)
+		}
+		seen[id] = true
+		ids[i] = id
+	}
+	return key, ids, nil
+}
+
+func (st *State) toolUpdate(ctx context.Context, _ *mcp.CallToolRequest, in types.UpdateIn) (*mcp.CallToolResult, any, error) {
+	key, ids, err := st.updateIDs(ctx, in)
+	if err != nil {
+		return nil, nil, err
+	}
+	existing, err := solr.ExistingIDs(ctx, st.backend(), in.Collection, key, ids)
+	if err != nil {
+		return nil, nil, err
+	}
+	out := map[string]any{
+		"collection":  in.Collection,
+		"documents":   len(ids),
+		"overwritten": existing,
+		"added":       len(ids) - len(existing),
+	}
+	if in.Preview {
+		out["preview"] = true
+		return nil, out, nil
+	}
+
+	if err := st.backend().Add(ctx, in.Collection, in.Documents); err != nil {
+		return nil, nil, err
+	}
+	slog.Info("Documents updated", "collection", in.Collection, "documents", len(ids), "overwritten", len(existing))
+	return nil, out, nil
+}
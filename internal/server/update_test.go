@@ -0,0 +1,56 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/types"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestToolUpdate tests the preview and execution of solr.update.
+func TestToolUpdate(t *testing.T) {
+	var updates []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/solr/logs/schema/uniquekey":
+			fmt.Fprintln(w, `{"uniqueKey":"id"}`)
+		case "/solr/logs/schema/fields":
+			fmt.Fprintln(w, `{"fields":[]}`)
+		case "/solr/logs/select":
+			fmt.Fprintln(w, `{"response":{"numFound":1,"docs":[{"id":"a"}]}}`)
+		case "/solr/logs/update":
+			body, _ := io.ReadAll(r.Body)
+			updates = append(updates, string(body))
+			fmt.Fprintln(w, `{"responseHeader":{"status":0}}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	st := newTestState(t, server.URL)
+	docs := []map[string]any{{"id": "a", "level": "INFO"}, {"id": "b", "level": "WARN"}}
+
+	// Goal: Documents without the uniqueKey are rejected.
+	_, _, err := st.toolUpdate(context.Background(), nil, types.UpdateIn{Collection: "logs", Documents: []map[string]any{{"level": "INFO"}}})
+	assert.ErrorContains(t, err, "uniqueKey field id is required")
+
+	// Goal: A preview reports the documents that would be overwritten without modifying the index.
+	_, out, err := st.toolUpdate(context.Background(), nil, types.UpdateIn{Collection: "logs", Documents: docs, Preview: true})
+	assert.NoError(t, err)
+	res := out.(map[string]any)
+	assert.Equal(t, []string{"a"}, res["overwritten"])
+	assert.Equal(t, 1, res["added"])
+	assert.Empty(t, updates)
+
+	// Goal: Without preview the documents are posted.
+	_, _, err = st.toolUpdate(context.Background(), nil, types.UpdateIn{Collection: "logs", Documents: docs})
+	assert.NoError(t, err)
+	assert.Len(t, updates, 1)
+	assert.JSONEq(t, `[{"id":"a","level":"INFO"},{"id":"b","level":"WARN"}]`, updates[0])
+}
@@ -0,0 +1,37 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/watchdog"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// watchCall tells the MCP client when a Solr request of a tool call passes a soft deadline: as a progress
+// notification when the call has a progress token, and as a log message otherwise, which clients only receive
+// after setting a log level.
+func (st *State) watchCall(ctx context.Context, req *mcp.CallToolRequest, tool string) context.Context {
+	if req == nil || req.Session == nil || req.Params == nil {
+		return ctx
+	}
+	token := req.Params.GetProgressToken()
+	var progress atomic.Int64
+	return watchdog.WithNotifier(ctx, func(ctx context.Context, message string) {
+		var err error
+		if token != nil {
+			err = req.Session.NotifyProgress(ctx, &mcp.ProgressNotificationParams{
+				ProgressToken: token,
+				Message:       tool + ": " + message,
+				Progress:      float64(progress.Add(1)),
+			})
+		} else {
+			err = req.Session.Log(ctx, &mcp.LoggingMessageParams{Level: "warning", Logger: "solr-mcp-go", Data: tool + ": " + message})
+		}
+		if err != nil {
+			slog.Debug("Failed to notify the client of a slow Solr request", "tool", tool, "error", err)
+		}
+	})
+}
@@ -0,0 +1,69 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/watchdog"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSlowCallProgress tests reporting slow Solr requests of a tool call as progress notifications.
+func TestSlowCallProgress(t *testing.T) {
+	solrServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/solr/products/select" {
+			time.Sleep(100 * time.Millisecond)
+		}
+		fmt.Fprintln(w, `{"response":{"numFound":0,"docs":[]}}`)
+	}))
+	defer solrServer.Close()
+	client := watchdog.Wrap(&http.Client{}, []time.Duration{20 * time.Millisecond})
+	st := NewServer(WithSolrURL(solrServer.URL), WithHTTPClient(client), WithDataDir(t.TempDir()))
+	mcpServer := st.NewMCPServer()
+	ctx := context.Background()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	_, err := mcpServer.Connect(ctx, serverTransport, nil)
+	require.NoError(t, err)
+
+	var mu sync.Mutex
+	var progress []*mcp.ProgressNotificationParams
+	mcpClient := mcp.NewClient(&mcp.Implementation{Name: "agent"}, &mcp.ClientOptions{
+		ProgressNotificationHandler: func(_ context.Context, req *mcp.ProgressNotificationClientRequest) {
+			mu.Lock()
+			defer mu.Unlock()
+			progress = append(progress, req.Params)
+		},
+	})
+	session, err := mcpClient.Connect(ctx, clientTransport, nil)
+	require.NoError(t, err)
+	defer session.Close()
+
+	// Goal: The client hears about the slow request while the call is still running.
+	params := &mcp.CallToolParams{
+		Meta:      mcp.Meta{"progressToken": "call-1"},
+		Name:      "solr.query",
+		Arguments: map[string]any{"collection": "products", "query": "*:*"},
+	}
+	res, err := session.CallTool(ctx, params)
+	require.NoError(t, err)
+	require.False(t, res.IsError)
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(progress) > 0
+	}, time.Second, 10*time.Millisecond)
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, "call-1", progress[0].ProgressToken)
+	assert.Equal(t, float64(1), progress[0].Progress)
+	assert.True(t, strings.HasPrefix(progress[0].Message, "solr.query: Solr has not answered GET /solr/products/select after 20ms"), progress[0].Message)
+}
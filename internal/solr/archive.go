@@ -0,0 +1,134 @@
+package solr
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// archiveBatchSize is the number of documents copied per request.
+const archiveBatchSize = 500
+
+// MoveResult reports the counts observed while moving documents between collections.
+type MoveResult struct {
+	Source          string `json:"source"`
+	Target          string `json:"target"`
+	Query           string `json:"query"`
+	Matched         int64  `json:"matched"`         // documents matching the query in the source before the move
+	Copied          int64  `json:"copied"`          // documents written to the target
+	TargetMatched   int64  `json:"targetMatched"`   // documents matching the query in the target after the copy
+	Deleted         int64  `json:"deleted"`         // documents deleted from the source by ID
+	SourceRemaining int64  `json:"sourceRemaining"` // documents still matching the query in the source (e.g. indexed meanwhile)
+}
+
+// MoveDocuments copies the stored fields of the documents matching query from src to dst and then deletes
+// exactly the copied documents from src. The delete only happens after the target count has been verified,
+// so a failure at any step leaves the documents in src. More than maxDocs matches is rejected.
+func MoveDocuments(ctx context.Context, httpClient *http.Client, baseURL, user, pass, src, dst, query, uniqueKey string, maxDocs int64) (*MoveResult, error) {
+	res := &MoveResult{Source: src, Target: dst, Query: query}
+	matched, err := CountDocuments(ctx, httpClient, baseURL, user, pass, src, query)
+	if err != nil {
+		return nil, fmt.Errorf("count source documents: %v", err)
+	}
+	res.Matched = matched
+	if matched > maxDocs {
+		return res, fmt.Errorf("query matches %d documents, more than the limit of %d", matched, maxDocs)
+	}
+	if matched == 0 {
+		return res, nil
+	}
+
+	skip, err := ReindexSkipFields(ctx, httpClient, baseURL, user, pass, dst)
+	if err != nil {
+		return res, err
+	}
+
+	var ids []any
+	cursor := "*"
+	for {
+		docs, next, err := CursorPage(ctx, httpClient, baseURL, user, pass, src, query, nil, uniqueKey, cursor, archiveBatchSize)
+		if err != nil {
+			return res, fmt.Errorf("read source documents: %v", err)
+		}
+		batch := make([]map[string]any, 0, len(docs))
+		for _, doc := range docs {
+			batch = append(batch, WithoutFields(doc, skip))
+			ids = append(ids, doc[uniqueKey])
+		}
+		if len(batch) > 0 {
+			if err := IndexDocuments(ctx, httpClient, baseURL, user, pass, dst, batch); err != nil {
+				return res, fmt.Errorf("write target documents: %v", err)
+			}
+			res.Copied += int64(len(batch))
+		}
+		if next == "" || next == cursor {
+			break
+		}
+		cursor = next
+	}
+	if err := Commit(ctx, httpClient, baseURL, user, pass, dst); err != nil {
+		return res, fmt.Errorf("commit target: %v", err)
+	}
+
+	targetMatched, err := CountDocuments(ctx, httpClient, baseURL, user, pass, dst, query)
+	if err != nil {
+		return res, fmt.Errorf("verify target: %v", err)
+	}
+	res.TargetMatched = targetMatched
+	if targetMatched < res.Copied {
+		return res, fmt.Errorf("verification failed: copied %d documents but the target only has %d matches; source left unchanged", res.Copied, targetMatched)
+	}
+
+	for start := 0; start < len(ids); start += archiveBatchSize {
+		end := min(start+archiveBatchSize, len(ids))
+		last := end == len(ids)
+		if err := postUpdate(ctx, httpClient, baseURL, user, pass, src, map[string]any{"delete": ids[start:end]}, last); err != nil {
+			return res, fmt.Errorf("delete source documents: %v", err)
+		}
+		res.Deleted += int64(end - start)
+	}
+
+	remaining, err := CountDocuments(ctx, httpClient, baseURL, user, pass, src, query)
+	if err != nil {
+		return res, fmt.Errorf("verify source: %v", err)
+	}
+	res.SourceRemaining = remaining
+	return res, nil
+}
+
+// copyFieldTargets returns the destination fields of the copyField rules of a collection.
+// Destinations may be dynamic field patterns such as "*_str".
+func copyFieldTargets(ctx context.Context, httpClient *http.Client, baseURL, user, pass, collection string) ([]string, error) {
+	u := fmt.Sprintf("%s/solr/%s/schema/copyfields?wt=json", baseURL, url.PathEscape(collection))
+	var out struct {
+		CopyFields []struct {
+			Dest string `json:"dest"`
+		} `json:"copyFields"`
+	}
+	if err := getJSON(ctx, httpClient, user, pass, u, &out, nil); err != nil {
+		return nil, fmt.Errorf("failed to get copy fields of %s: %v", collection, err)
+	}
+	var targets []string
+	for _, cf := range out.CopyFields {
+		targets = append(targets, cf.Dest)
+	}
+	return targets, nil
+}
+
+// matchesAnyField reports whether name equals one of the field names or matches one of the
+// dynamic field patterns, which have a single leading or trailing "*".
+func matchesAnyField(fields []string, name string) bool {
+	for _, f := range fields {
+		switch {
+		case f == name:
+			return true
+		case strings.HasPrefix(f, "*") && strings.HasSuffix(name, f[1:]):
+			return true
+		case strings.HasSuffix(f, "*") && strings.HasPrefix(name, f[:len(f)-1]):
+			return true
+		}
+	}
+	return false
+}
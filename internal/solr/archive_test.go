@@ -0,0 +1,107 @@
+package solr
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// memorySolr is a tiny in-memory Solr serving select (with cursorMark), update and copyfields requests.
+// Queries match every document of a collection.
+type memorySolr struct {
+	mu   sync.Mutex
+	docs map[string][]map[string]any
+}
+
+func (m *memorySolr) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	collection, handler := parts[1], strings.Join(parts[2:], "/")
+	switch handler {
+	case "schema/copyfields":
+		fmt.Fprintln(w, `{"copyFields":[{"source":"title","dest":"*_str"}]}`)
+	case "select":
+		docs := m.docs[collection]
+		if r.URL.Query().Get("rows") == "0" {
+			fmt.Fprintf(w, `{"response":{"numFound":%d,"docs":[]}}`, len(docs))
+			return
+		}
+		// Serve everything in one page, then return the same cursor to end the iteration
+		cursor := r.URL.Query().Get("cursorMark")
+		page := docs
+		if cursor != "*" {
+			page = nil
+		}
+		json.NewEncoder(w).Encode(map[string]any{
+			"response":       map[string]any{"numFound": len(docs), "docs": page},
+			"nextCursorMark": "AoE=",
+		})
+	case "update":
+		var body any
+		json.NewDecoder(r.Body).Decode(&body)
+		switch b := body.(type) {
+		case []any:
+			for _, d := range b {
+				m.docs[collection] = append(m.docs[collection], d.(map[string]any))
+			}
+		case map[string]any:
+			if ids, ok := b["delete"].([]any); ok {
+				var kept []map[string]any
+				for _, d := range m.docs[collection] {
+					deleted := false
+					for _, id := range ids {
+						deleted = deleted || d["id"] == id
+					}
+					if !deleted {
+						kept = append(kept, d)
+					}
+				}
+				m.docs[collection] = kept
+			}
+		}
+		fmt.Fprintln(w, `{"responseHeader":{"status":0}}`)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// TestMoveDocuments tests moving documents to an archive collection.
+func TestMoveDocuments(t *testing.T) {
+	mem := &memorySolr{docs: map[string][]map[string]any{
+		"logs": {
+			{"id": "1", "title": "a", "title_str": "a", "_version_": 1.0},
+			{"id": "2", "title": "b", "title_str": "b", "_version_": 2.0},
+		},
+	}}
+	server := httptest.NewServer(mem)
+	defer server.Close()
+
+	// Goal: Documents are copied without copy field targets and _version_, then deleted from the source.
+	res, err := MoveDocuments(context.Background(), server.Client(), server.URL, "", "", "logs", "logs_archive", "*:*", "id", 100)
+	assert.NoError(t, err)
+	assert.Equal(t, &MoveResult{Source: "logs", Target: "logs_archive", Query: "*:*", Matched: 2, Copied: 2, TargetMatched: 2, Deleted: 2}, res)
+	assert.Empty(t, mem.docs["logs"])
+	assert.Equal(t, []map[string]any{{"id": "1", "title": "a"}, {"id": "2", "title": "b"}}, mem.docs["logs_archive"])
+
+	// Goal: Moves over the limit are rejected before anything is copied.
+	_, err = MoveDocuments(context.Background(), server.Client(), server.URL, "", "", "logs_archive", "logs", "*:*", "id", 1)
+	assert.Error(t, err)
+	assert.Len(t, mem.docs["logs_archive"], 2)
+}
+
+// TestMatchesAnyField tests matching of field names and dynamic field patterns.
+func TestMatchesAnyField(t *testing.T) {
+	fields := []string{"_text_", "*_str", "attr_*"}
+	assert.True(t, matchesAnyField(fields, "_text_"))
+	assert.True(t, matchesAnyField(fields, "title_str"))
+	assert.True(t, matchesAnyField(fields, "attr_color"))
+	assert.False(t, matchesAnyField(fields, "title"))
+}
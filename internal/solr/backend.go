@@ -0,0 +1,76 @@
+package solr
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/backend"
+	"github.com/Sashimimochi/solr-mcp-go/internal/config"
+	"github.com/Sashimimochi/solr-mcp-go/internal/types"
+)
+
+var _ backend.SearchBackend = (*HTTPBackend)(nil)
+
+// HTTPBackend implements backend.SearchBackend with the Solr HTTP APIs.
+type HTTPBackend struct {
+	HttpClient *http.Client
+	BaseURL    string
+	User       string
+	Pass       string
+	Cache      *types.SchemaCache
+}
+
+// Query sends params to the /select handler of the collection.
+func (b *HTTPBackend) Query(ctx context.Context, collection string, params url.Values) (map[string]any, error) {
+	return getSelect(ctx, b.HttpClient, b.User, b.Pass, SelectURL(b.BaseURL, collection), params)
+}
+
+func (b *HTTPBackend) Count(ctx context.Context, collection, query string) (int64, error) {
+	return CountDocuments(ctx, b.HttpClient, b.BaseURL, b.User, b.Pass, collection, query)
+}
+
+func (b *HTTPBackend) Add(ctx context.Context, collection string, docs []map[string]any) error {
+	return AddDocuments(ctx, b.HttpClient, b.BaseURL, b.User, b.Pass, collection, docs)
+}
+
+func (b *HTTPBackend) DeleteByQuery(ctx context.Context, collection, query string) error {
+	return DeleteByQuery(ctx, b.HttpClient, b.BaseURL, b.User, b.Pass, collection, query)
+}
+
+func (b *HTTPBackend) DeleteByIDs(ctx context.Context, collection string, ids []string) error {
+	return DeleteByIDs(ctx, b.HttpClient, b.BaseURL, b.User, b.Pass, collection, ids)
+}
+
+func (b *HTTPBackend) Schema(ctx context.Context, collection string) (*types.FieldCatalog, error) {
+	return GetFieldCatalog(ctx, SchemaContext{
+		HttpClient: b.HttpClient,
+		BaseURL:    b.BaseURL,
+		User:       b.User,
+		Pass:       b.Pass,
+		Cache:      b.Cache,
+	}, collection)
+}
+
+// ClusterStatus calls the Collections API CLUSTERSTATUS action.
+func (b *HTTPBackend) ClusterStatus(ctx context.Context, collection string) (*config.ClusterStatusResponse, error) {
+	u := fmt.Sprintf("%s/solr/admin/collections?action=CLUSTERSTATUS&wt=json", b.BaseURL)
+	if collection != "" {
+		u += "&collection=" + url.QueryEscape(collection)
+	}
+	var status config.ClusterStatusResponse
+	if err := getJSON(ctx, b.HttpClient, b.User, b.Pass, u, &status, nil); err != nil {
+		return nil, fmt.Errorf("cluster status request: %v", err)
+	}
+	DeriveHealth(&status)
+	return &status, nil
+}
+
+func (b *HTTPBackend) ListCollections(ctx context.Context) ([]string, error) {
+	return ListCollections(ctx, b.HttpClient, b.BaseURL, b.User, b.Pass)
+}
+
+func (b *HTTPBackend) DeleteCollection(ctx context.Context, name string) error {
+	return DeleteCollection(ctx, b.HttpClient, b.BaseURL, b.User, b.Pass, name)
+}
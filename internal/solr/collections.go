@@ -0,0 +1,19 @@
+package solr
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// ListCollections returns the collection names reported by the Collections API LIST action.
+func ListCollections(ctx context.Context, httpClient *http.Client, baseURL, user, pass string) ([]string, error) {
+	u := fmt.Sprintf("%s/solr/admin/collections?action=LIST&wt=json", baseURL)
+	var out struct {
+		Collections []string `json:"collections"`
+	}
+	if err := getJSON(ctx, httpClient, user, pass, u, &out, nil); err != nil {
+		return nil, fmt.Errorf("failed to list collections: %v", err)
+	}
+	return out.Collections, nil
+}
@@ -0,0 +1,42 @@
+package solr
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestListCollections tests the ListCollections function.
+func TestListCollections(t *testing.T) {
+	// Goal: Collection names are returned from the LIST action.
+	t.Run("Success", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/solr/admin/collections", r.URL.Path)
+			assert.Equal(t, "LIST", r.URL.Query().Get("action"))
+			fmt.Fprintln(w, `{"responseHeader":{"status":0},"collections":["logs","products"]}`)
+		}))
+		defer server.Close()
+
+		names, err := ListCollections(context.Background(), server.Client(), server.URL, "", "")
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"logs", "products"}, names)
+	})
+
+	// Goal: HTTP errors are propagated.
+	t.Run("Error: HTTP error", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "nope", http.StatusUnauthorized)
+		}))
+		defer server.Close()
+
+		_, err := ListCollections(context.Background(), server.Client(), server.URL, "", "")
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to list collections")
+	})
+}
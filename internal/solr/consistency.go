@@ -0,0 +1,150 @@
+package solr
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/config"
+)
+
+// ReplicaConsistency holds the values compared across the replicas of a shard.
+type ReplicaConsistency struct {
+	Replica        string `json:"replica"`
+	Core           string `json:"core"`
+	Node           string `json:"node"`
+	State          string `json:"state"`
+	Leader         bool   `json:"leader,omitempty"`
+	NumFound       *int64 `json:"numFound,omitempty"`
+	MaxVersion     string `json:"maxVersion,omitempty"` // max(_version_), kept as a string since it exceeds float64 precision
+	CanaryNumFound *int64 `json:"canaryNumFound,omitempty"`
+	Error          string `json:"error,omitempty"`
+}
+
+// ShardConsistency is the consistency report of a single shard.
+type ShardConsistency struct {
+	Shard      string               `json:"shard"`
+	Consistent bool                 `json:"consistent"`
+	Replicas   []ReplicaConsistency `json:"replicas"`
+}
+
+// CheckConsistency compares the document count, the highest _version_ and optionally the numFound
+// of a canary query across the replicas of every shard. Replicas are queried with distrib=false.
+// The returned findings describe every divergence and every replica that could not be checked.
+func CheckConsistency(ctx context.Context, httpClient *http.Client, user, pass string, status *config.CollectionStatus, canary string) ([]ShardConsistency, []string) {
+	targets := replicaTargets(status)
+	replicas := make([]ReplicaConsistency, len(targets))
+	var wg sync.WaitGroup
+	for i := range targets {
+		replicas[i] = ReplicaConsistency{
+			Replica: targets[i].Replica,
+			Core:    targets[i].Core,
+			Node:    targets[i].Node,
+			State:   targets[i].State,
+			Leader:  targets[i].Leader,
+		}
+		wg.Add(1)
+		go func(r *ReplicaConsistency, coreURL string) {
+			defer wg.Done()
+			if err := checkReplica(ctx, httpClient, user, pass, coreURL, canary, r); err != nil {
+				r.Error = err.Error()
+			}
+		}(&replicas[i], replicaCoreURL(status, &targets[i]))
+	}
+	wg.Wait()
+
+	var shards []ShardConsistency
+	var findings []string
+	for i, t := range targets {
+		if len(shards) == 0 || shards[len(shards)-1].Shard != t.Shard {
+			shards = append(shards, ShardConsistency{Shard: t.Shard, Consistent: true})
+		}
+		shard := &shards[len(shards)-1]
+		shard.Replicas = append(shard.Replicas, replicas[i])
+	}
+	for i := range shards {
+		shard := &shards[i]
+		var ok []ReplicaConsistency
+		for _, r := range shard.Replicas {
+			if r.Error != "" {
+				shard.Consistent = false
+				findings = append(findings, fmt.Sprintf("%s/%s could not be checked: %s", shard.Shard, r.Replica, r.Error))
+				continue
+			}
+			ok = append(ok, r)
+		}
+		for _, d := range []struct {
+			name  string
+			value func(ReplicaConsistency) string
+		}{
+			{"numFound", func(r ReplicaConsistency) string { return formatCount(r.NumFound) }},
+			{"max(_version_)", func(r ReplicaConsistency) string { return r.MaxVersion }},
+			{"canary numFound", func(r ReplicaConsistency) string { return formatCount(r.CanaryNumFound) }},
+		} {
+			if diverges(ok, d.value) {
+				shard.Consistent = false
+				var parts []string
+				for _, r := range ok {
+					parts = append(parts, fmt.Sprintf("%s=%s", r.Replica, d.value(r)))
+				}
+				findings = append(findings, fmt.Sprintf("%s replicas disagree on %s (%s)", shard.Shard, d.name, strings.Join(parts, ", ")))
+			}
+		}
+	}
+	return shards, findings
+}
+
+func checkReplica(ctx context.Context, httpClient *http.Client, user, pass, coreURL, canary string, r *ReplicaConsistency) error {
+	// _version_ is decoded as json.Number since it does not fit into a float64
+	var latest struct {
+		Response struct {
+			NumFound int64 `json:"numFound"`
+			Docs     []struct {
+				Version json.Number `json:"_version_"`
+			} `json:"docs"`
+		} `json:"response"`
+	}
+	values := url.Values{"q": {"*:*"}, "rows": {"1"}, "fl": {"_version_"}, "sort": {"_version_ desc"}, "distrib": {"false"}, "wt": {"json"}}
+	if err := getJSON(ctx, httpClient, user, pass, coreURL+"/select?"+values.Encode(), &latest, nil); err != nil {
+		return err
+	}
+	r.NumFound = &latest.Response.NumFound
+	if len(latest.Response.Docs) > 0 {
+		r.MaxVersion = latest.Response.Docs[0].Version.String()
+	}
+
+	if canary == "" {
+		return nil
+	}
+	var canaryResp struct {
+		Response struct {
+			NumFound int64 `json:"numFound"`
+		} `json:"response"`
+	}
+	values = url.Values{"q": {canary}, "rows": {"0"}, "distrib": {"false"}, "wt": {"json"}}
+	if err := getJSON(ctx, httpClient, user, pass, coreURL+"/select?"+values.Encode(), &canaryResp, nil); err != nil {
+		return fmt.Errorf("canary query: %v", err)
+	}
+	r.CanaryNumFound = &canaryResp.Response.NumFound
+	return nil
+}
+
+func diverges(replicas []ReplicaConsistency, value func(ReplicaConsistency) string) bool {
+	for _, r := range replicas {
+		if value(r) != value(replicas[0]) {
+			return true
+		}
+	}
+	return false
+}
+
+func formatCount(n *int64) string {
+	if n == nil {
+		return "-"
+	}
+	return fmt.Sprint(*n)
+}
@@ -0,0 +1,54 @@
+package solr
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestCheckConsistency tests the comparison of counts, versions and canary results across replicas.
+func TestCheckConsistency(t *testing.T) {
+	server := newShardServer(t, nil)
+	defer server.Close()
+	status, err := GetClusterStatus(context.Background(), server.Client(), server.URL, "", "", "logs")
+	assert.NoError(t, err)
+
+	responses := map[string]string{
+		"logs_shard1_replica_n1": `{"response":{"numFound":10,"docs":[{"_version_":1790000000000000005}]}}`,
+		"logs_shard1_replica_n2": `{"response":{"numFound":10,"docs":[{"_version_":1790000000000000005}]}}`,
+		"logs_shard2_replica_n3": `{"response":{"numFound":12,"docs":[{"_version_":1790000000000000007}]}}`,
+		"logs_shard2_replica_n4": `{"response":{"numFound":11,"docs":[{"_version_":1790000000000000006}]}}`,
+	}
+	coreServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		core := r.URL.Path[len("/solr/") : len(r.URL.Path)-len("/select")]
+		assert.Equal(t, "false", r.URL.Query().Get("distrib"))
+		if r.URL.Query().Get("q") == "level:ERROR" {
+			fmt.Fprintln(w, `{"response":{"numFound":3}}`)
+			return
+		}
+		fmt.Fprintln(w, responses[core])
+	}))
+	defer coreServer.Close()
+	for _, shard := range status.Shards {
+		for name, replica := range shard.Replicas {
+			replica.BaseURL = coreServer.URL + "/solr"
+			shard.Replicas[name] = replica
+		}
+	}
+
+	// Goal: shard1 is consistent while shard2 diverges on both numFound and max(_version_).
+	shards, findings := CheckConsistency(context.Background(), coreServer.Client(), "", "", status, "level:ERROR")
+	assert.Len(t, shards, 2)
+	assert.True(t, shards[0].Consistent)
+	assert.Equal(t, "1790000000000000005", shards[0].Replicas[0].MaxVersion)
+	assert.Equal(t, int64(3), *shards[0].Replicas[0].CanaryNumFound)
+	assert.False(t, shards[1].Consistent)
+	assert.Equal(t, []string{
+		"shard2 replicas disagree on numFound (core_node3=12, core_node4=11)",
+		"shard2 replicas disagree on max(_version_) (core_node3=1790000000000000007, core_node4=1790000000000000006)",
+	}, findings)
+}
@@ -0,0 +1,340 @@
+package solr
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/backend"
+)
+
+const (
+	// dedupeBatchSize is the number of documents read per cursorMark request.
+	dedupeBatchSize = 500
+	// minHashSize is the number of MinHash values per document, split into minHashBands LSH bands.
+	minHashSize  = 128
+	minHashBands = 32
+	// shingleSize is the number of consecutive words hashed together.
+	shingleSize = 3
+)
+
+// DedupeOptions selects how duplicates are found. With SignatureField, documents with the same
+// signature value are duplicates (e.g. a field filled by SignatureUpdateProcessorFactory).
+// Otherwise MinHash signatures are computed from the text of Fields.
+type DedupeOptions struct {
+	Query          string
+	SignatureField string
+	Fields         []string
+	Threshold      float64 // minimum estimated Jaccard similarity of MinHash duplicates
+	MaxDocs        int64   // documents read for MinHash, or duplicate groups for signatures
+	UniqueKey      string
+}
+
+// DuplicateCluster is a group of probable duplicates. Similarity is the lowest estimated similarity
+// of the pairs joining the cluster (1 for identical signatures).
+type DuplicateCluster struct {
+	IDs        []string `json:"ids"`
+	Similarity float64  `json:"similarity"`
+	Signature  string   `json:"signature,omitempty"`
+}
+
+// DedupeResult lists the duplicate clusters of a collection.
+type DedupeResult struct {
+	Method     string             `json:"method"` // "signature" or "minhash"
+	Scanned    int64              `json:"scanned"`
+	Clusters   []DuplicateCluster `json:"clusters"`
+	Duplicates int                `json:"duplicates"` // documents beyond the first of each cluster
+	Truncated  bool               `json:"truncated,omitempty"`
+}
+
+// FindDuplicates finds probable duplicate documents of collection.
+func FindDuplicates(ctx context.Context, b backend.Querier, collection string, opts DedupeOptions) (*DedupeResult, error) {
+	if opts.Query == "" {
+		opts.Query = "*:*"
+	}
+	var res *DedupeResult
+	var err error
+	if opts.SignatureField != "" {
+		res, err = signatureDuplicates(ctx, b, collection, opts)
+	} else {
+		res, err = minHashDuplicates(ctx, b, collection, opts)
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range res.Clusters {
+		res.Duplicates += len(c.IDs) - 1
+	}
+	return res, nil
+}
+
+// signatureDuplicates groups documents by signature value with a facet on values occurring twice or more.
+func signatureDuplicates(ctx context.Context, b backend.Querier, collection string, opts DedupeOptions) (*DedupeResult, error) {
+	resp, err := b.Query(ctx, collection, url.Values{
+		"q":              {opts.Query},
+		"rows":           {"0"},
+		"facet":          {"true"},
+		"facet.field":    {opts.SignatureField},
+		"facet.mincount": {"2"},
+		"facet.limit":    {strconv.FormatInt(opts.MaxDocs+1, 10)},
+		"wt":             {"json"},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("count signatures: %v", err)
+	}
+	res := &DedupeResult{Method: "signature", Clusters: []DuplicateCluster{}}
+	if respObj, ok := resp["response"].(map[string]any); ok {
+		if n, ok := respObj["numFound"].(float64); ok {
+			res.Scanned = int64(n)
+		}
+	}
+	signatures, _ := ParseTopValues(resp, opts.SignatureField)
+	if int64(len(signatures)) > opts.MaxDocs {
+		signatures, res.Truncated = signatures[:opts.MaxDocs], true
+	}
+	for _, sig := range signatures {
+		resp, err := b.Query(ctx, collection, url.Values{
+			"q":    {opts.Query},
+			"fq":   {fmt.Sprintf("{!term f=%s}%s", opts.SignatureField, sig)},
+			"fl":   {opts.UniqueKey},
+			"sort": {opts.UniqueKey + " asc"},
+			"rows": {strconv.Itoa(dedupeBatchSize)},
+			"wt":   {"json"},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("read documents with signature %s: %v", sig, err)
+		}
+		if ids := ExtractIDs(resp, opts.UniqueKey); len(ids) > 1 {
+			res.Clusters = append(res.Clusters, DuplicateCluster{IDs: ids, Similarity: 1, Signature: sig})
+		}
+	}
+	return res, nil
+}
+
+// minHashDuplicates reads the documents with cursorMark paging, computes a MinHash signature of the
+// text of the fields and compares the candidate pairs found by locality-sensitive hashing.
+func minHashDuplicates(ctx context.Context, b backend.Querier, collection string, opts DedupeOptions) (*DedupeResult, error) {
+	if len(opts.Fields) == 0 {
+		return nil, fmt.Errorf("fields are required without a signature field")
+	}
+	res := &DedupeResult{Method: "minhash", Clusters: []DuplicateCluster{}}
+	var ids []string
+	var sigs [][]uint64
+	cursor := "*"
+	for {
+		resp, err := b.Query(ctx, collection, url.Values{
+			"q":          {opts.Query},
+			"fl":         {strings.Join(append([]string{opts.UniqueKey}, opts.Fields...), ",")},
+			"sort":       {opts.UniqueKey + " asc"},
+			"rows":       {strconv.Itoa(dedupeBatchSize)},
+			"cursorMark": {cursor},
+			"wt":         {"json"},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("read documents: %v", err)
+		}
+		respObj, _ := resp["response"].(map[string]any)
+		docs, _ := respObj["docs"].([]any)
+		for _, d := range docs {
+			if res.Scanned >= opts.MaxDocs {
+				res.Truncated = true
+				break
+			}
+			doc, _ := d.(map[string]any)
+			id, ok := DocumentID(doc, opts.UniqueKey)
+			if !ok {
+				continue
+			}
+			res.Scanned++
+			if sig := minHash(shingles(docText(doc, opts.Fields))); sig != nil {
+				ids = append(ids, id)
+				sigs = append(sigs, sig)
+			}
+		}
+		next, _ := resp["nextCursorMark"].(string)
+		if res.Truncated || next == "" || next == cursor {
+			break
+		}
+		cursor = next
+	}
+
+	// Identical signatures are joined directly so that large groups of copies are not compared pairwise
+	uf := newUnionFind(len(ids))
+	minSim := map[int]float64{}
+	exact := map[string]int{}
+	var distinct []int
+	for i, sig := range sigs {
+		key := fmt.Sprint(sig)
+		if j, ok := exact[key]; ok {
+			uf.union(i, j)
+			minSim[j] = 1
+			continue
+		}
+		exact[key] = i
+		distinct = append(distinct, i)
+	}
+
+	// Documents sharing all values of a band are candidates; pairs are verified against the threshold.
+	rows := minHashSize / minHashBands
+	checked := map[[2]int]bool{}
+	for band := 0; band < minHashBands; band++ {
+		buckets := map[string][]int{}
+		for _, i := range distinct {
+			key := fmt.Sprint(sigs[i][band*rows : (band+1)*rows])
+			buckets[key] = append(buckets[key], i)
+		}
+		for _, bucket := range buckets {
+			for x := 0; x < len(bucket); x++ {
+				for y := x + 1; y < len(bucket); y++ {
+					pair := [2]int{bucket[x], bucket[y]}
+					if checked[pair] {
+						continue
+					}
+					checked[pair] = true
+					sim := similarity(sigs[pair[0]], sigs[pair[1]])
+					if sim < opts.Threshold {
+						continue
+					}
+					rx, ry := uf.find(pair[0]), uf.find(pair[1])
+					if rx == ry {
+						continue
+					}
+					lowest := sim
+					for _, r := range []int{rx, ry} {
+						if s, ok := minSim[r]; ok && s < lowest {
+							lowest = s
+						}
+					}
+					uf.union(rx, ry)
+					minSim[uf.find(rx)] = lowest
+				}
+			}
+		}
+	}
+
+	groups := map[int][]string{}
+	var roots []int
+	for i, id := range ids {
+		r := uf.find(i)
+		if _, ok := groups[r]; !ok {
+			roots = append(roots, r)
+		}
+		groups[r] = append(groups[r], id)
+	}
+	for _, r := range roots {
+		if len(groups[r]) > 1 {
+			res.Clusters = append(res.Clusters, DuplicateCluster{IDs: groups[r], Similarity: round2(minSim[r])})
+		}
+	}
+	sort.SliceStable(res.Clusters, func(i, j int) bool { return len(res.Clusters[i].IDs) > len(res.Clusters[j].IDs) })
+	return res, nil
+}
+
+// docText joins the values of fields of doc, including multi-valued fields.
+func docText(doc map[string]any, fields []string) string {
+	var parts []string
+	for _, f := range fields {
+		switch v := doc[f].(type) {
+		case nil:
+		case []any:
+			for _, item := range v {
+				parts = append(parts, fmt.Sprint(item))
+			}
+		default:
+			parts = append(parts, fmt.Sprint(v))
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// shingles returns the lowercased word n-grams of text. Texts shorter than shingleSize words are one shingle.
+func shingles(text string) []string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+	if len(words) == 0 {
+		return nil
+	}
+	if len(words) < shingleSize {
+		return []string{strings.Join(words, " ")}
+	}
+	out := make([]string, 0, len(words)-shingleSize+1)
+	for i := 0; i+shingleSize <= len(words); i++ {
+		out = append(out, strings.Join(words[i:i+shingleSize], " "))
+	}
+	return out
+}
+
+// minHash returns the MinHash signature of a set of shingles, or nil for an empty set.
+func minHash(shingles []string) []uint64 {
+	if len(shingles) == 0 {
+		return nil
+	}
+	sig := make([]uint64, minHashSize)
+	for i := range sig {
+		sig[i] = ^uint64(0)
+	}
+	for _, s := range shingles {
+		h := fnv.New64a()
+		h.Write([]byte(s))
+		base := h.Sum64()
+		for i := range sig {
+			if v := mix64(base ^ uint64(i+1)*0x9e3779b97f4a7c15); v < sig[i] {
+				sig[i] = v
+			}
+		}
+	}
+	return sig
+}
+
+// mix64 is the splitmix64 finalizer, used to derive independent hash functions from one hash.
+func mix64(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
+
+// similarity estimates the Jaccard similarity of two documents from their MinHash signatures.
+func similarity(a, b []uint64) float64 {
+	same := 0
+	for i := range a {
+		if a[i] == b[i] {
+			same++
+		}
+	}
+	return float64(same) / float64(len(a))
+}
+
+func round2(f float64) float64 {
+	return float64(int(f*100+0.5)) / 100
+}
+
+type unionFind []int
+
+func newUnionFind(n int) unionFind {
+	uf := make(unionFind, n)
+	for i := range uf {
+		uf[i] = i
+	}
+	return uf
+}
+
+func (uf unionFind) find(i int) int {
+	for uf[i] != i {
+		uf[i] = uf[uf[i]]
+		i = uf[i]
+	}
+	return i
+}
+
+func (uf unionFind) union(a, b int) {
+	uf[uf.find(a)] = uf.find(b)
+}
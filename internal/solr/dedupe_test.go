@@ -0,0 +1,110 @@
+package solr
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pagedQuerier serves docs in pages of two with cursorMark, or the response of facet and fq requests.
+type pagedQuerier struct {
+	docs     []any
+	requests []url.Values
+	respond  func(params url.Values) map[string]any
+}
+
+func (q *pagedQuerier) Query(_ context.Context, _ string, params url.Values) (map[string]any, error) {
+	q.requests = append(q.requests, params)
+	if q.respond != nil {
+		return q.respond(params), nil
+	}
+	start := 0
+	if c := params.Get("cursorMark"); c != "*" {
+		fmt.Sscan(c, &start)
+	}
+	end := min(start+2, len(q.docs))
+	return map[string]any{
+		"response":       map[string]any{"numFound": float64(len(q.docs)), "docs": q.docs[start:end]},
+		"nextCursorMark": fmt.Sprint(end),
+	}, nil
+}
+
+func (q *pagedQuerier) Count(context.Context, string, string) (int64, error) {
+	return int64(len(q.docs)), nil
+}
+
+// TestFindDuplicatesMinHash tests MinHash clustering of near-duplicate texts.
+func TestFindDuplicatesMinHash(t *testing.T) {
+	q := &pagedQuerier{docs: []any{
+		map[string]any{"id": "a", "title": "Solr is a fast open source search platform built on Apache Lucene"},
+		map[string]any{"id": "b", "title": "Solr is a fast open source search platform built on Apache Lucene!"},
+		map[string]any{"id": "c", "title": "Completely different text about cooking pasta with tomatoes and basil"},
+		map[string]any{"id": "d", "title": []any{"Solr is a fast open source search platform", "built on Apache Lucene"}},
+		map[string]any{"id": "e"},
+	}}
+
+	// Goal: Near-identical texts, also split over multi-valued fields, form one cluster and all pages are read.
+	res, err := FindDuplicates(context.Background(), q, "docs", DedupeOptions{Fields: []string{"title"}, Threshold: 0.8, MaxDocs: 100, UniqueKey: "id"})
+	require.NoError(t, err)
+	assert.Equal(t, "minhash", res.Method)
+	assert.Equal(t, int64(5), res.Scanned)
+	require.Len(t, res.Clusters, 1)
+	assert.Equal(t, []string{"a", "b", "d"}, res.Clusters[0].IDs)
+	assert.Equal(t, 1.0, res.Clusters[0].Similarity)
+	assert.Equal(t, 2, res.Duplicates)
+	assert.Equal(t, "*:*", q.requests[0].Get("q"))
+	assert.Equal(t, "id,title", q.requests[0].Get("fl"))
+
+	// Goal: Scans stop at maxDocs and report truncation.
+	res, err = FindDuplicates(context.Background(), q, "docs", DedupeOptions{Fields: []string{"title"}, Threshold: 0.8, MaxDocs: 1, UniqueKey: "id"})
+	require.NoError(t, err)
+	assert.True(t, res.Truncated)
+	assert.Equal(t, int64(1), res.Scanned)
+	assert.Empty(t, res.Clusters)
+}
+
+// TestFindDuplicatesSignature tests grouping by an existing signature field.
+func TestFindDuplicatesSignature(t *testing.T) {
+	q := &pagedQuerier{respond: func(params url.Values) map[string]any {
+		if params.Get("facet.field") == "sig" {
+			return map[string]any{
+				"response":     map[string]any{"numFound": float64(40)},
+				"facet_counts": map[string]any{"facet_fields": map[string]any{"sig": []any{"abc", float64(3), "def", float64(2)}}},
+			}
+		}
+		ids := map[string][]any{
+			"{!term f=sig}abc": {map[string]any{"id": "1"}, map[string]any{"id": "2"}, map[string]any{"id": "3"}},
+			"{!term f=sig}def": {map[string]any{"id": "4"}, map[string]any{"id": "5"}},
+		}[params.Get("fq")]
+		return map[string]any{"response": map[string]any{"docs": ids}}
+	}}
+
+	// Goal: Each signature value occurring more than once is a cluster with similarity 1.
+	res, err := FindDuplicates(context.Background(), q, "docs", DedupeOptions{SignatureField: "sig", MaxDocs: 100, UniqueKey: "id"})
+	require.NoError(t, err)
+	assert.Equal(t, "signature", res.Method)
+	assert.Equal(t, int64(40), res.Scanned)
+	assert.Equal(t, []DuplicateCluster{
+		{IDs: []string{"1", "2", "3"}, Similarity: 1, Signature: "abc"},
+		{IDs: []string{"4", "5"}, Similarity: 1, Signature: "def"},
+	}, res.Clusters)
+	assert.Equal(t, 3, res.Duplicates)
+	assert.Equal(t, "2", q.requests[0].Get("facet.mincount"))
+}
+
+// TestMinHashSimilarity tests that the MinHash estimate follows the overlap of the texts.
+func TestMinHashSimilarity(t *testing.T) {
+	a := minHash(shingles("the quick brown fox jumps over the lazy dog near the river bank"))
+	b := minHash(shingles("the quick brown fox jumps over the lazy cat near the river bank"))
+	c := minHash(shingles("an entirely unrelated sentence about databases and indexes"))
+
+	// Goal: Similar texts score between unrelated and identical texts.
+	assert.Equal(t, 1.0, similarity(a, a))
+	assert.Greater(t, similarity(a, b), similarity(a, c))
+	assert.Less(t, similarity(a, b), 1.0)
+	assert.Nil(t, minHash(shingles("  ,. ")))
+}
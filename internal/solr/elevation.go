@@ -0,0 +1,152 @@
+package solr
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/config"
+)
+
+// DefaultElevateFile is the config-file of QueryElevationComponent in the default configsets.
+const DefaultElevateFile = "elevate.xml"
+
+// ElevationRule pins (Elevate, in this order) and hides (Exclude) documents for a query text.
+// Match is "subset" to apply the rule to queries containing all terms of Query, or empty for exact matches.
+type ElevationRule struct {
+	Query   string   `json:"query"`
+	Match   string   `json:"match,omitempty"`
+	Elevate []string `json:"elevate,omitempty"`
+	Exclude []string `json:"exclude,omitempty"`
+}
+
+type elevateXML struct {
+	XMLName xml.Name          `xml:"elevate"`
+	Queries []elevateXMLQuery `xml:"query"`
+}
+
+type elevateXMLQuery struct {
+	Text  string          `xml:"text,attr"`
+	Match string          `xml:"match,attr,omitempty"`
+	Docs  []elevateXMLDoc `xml:"doc"`
+}
+
+type elevateXMLDoc struct {
+	ID      string `xml:"id,attr"`
+	Exclude bool   `xml:"exclude,attr,omitempty"`
+}
+
+// ParseElevateXML reads the rules of an elevate.xml file.
+func ParseElevateXML(data []byte) ([]ElevationRule, error) {
+	var doc elevateXML
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("parse elevate.xml: %v", err)
+	}
+	rules := make([]ElevationRule, 0, len(doc.Queries))
+	for _, q := range doc.Queries {
+		r := ElevationRule{Query: q.Text, Match: q.Match}
+		for _, d := range q.Docs {
+			if d.Exclude {
+				r.Exclude = append(r.Exclude, d.ID)
+			} else {
+				r.Elevate = append(r.Elevate, d.ID)
+			}
+		}
+		rules = append(rules, r)
+	}
+	return rules, nil
+}
+
+// RenderElevateXML writes rules as an elevate.xml file.
+func RenderElevateXML(rules []ElevationRule) []byte {
+	doc := elevateXML{Queries: make([]elevateXMLQuery, 0, len(rules))}
+	for _, r := range rules {
+		q := elevateXMLQuery{Text: r.Query, Match: r.Match}
+		for _, id := range r.Elevate {
+			q.Docs = append(q.Docs, elevateXMLDoc{ID: id})
+		}
+		for _, id := range r.Exclude {
+			q.Docs = append(q.Docs, elevateXMLDoc{ID: id, Exclude: true})
+		}
+		doc.Queries = append(doc.Queries, q)
+	}
+	out, _ := xml.MarshalIndent(doc, "", "  ")
+	return append([]byte(xml.Header+"<!-- Managed by solr-mcp-go (solr.elevation.set) -->\n"), append(out, '\n')...)
+}
+
+// ElevationParams makes a query apply the elevation rules even when it is sorted, and marks elevated
+// documents with an [elevated] field.
+func ElevationParams(params url.Values) {
+	params.Set("enableElevation", "true")
+	params.Set("forceElevation", "true")
+	if params.Get("fl") == "" {
+		params.Set("fl", "*,score,[elevated]")
+		return
+	}
+	params.Add("fl", "[elevated]")
+}
+
+// GetConfigFile reads a file of the configset of collection with the ShowFileRequestHandler.
+func GetConfigFile(ctx context.Context, httpClient *http.Client, baseURL, user, pass, collection, file string) ([]byte, error) {
+	u := fmt.Sprintf("%s/solr/%s/admin/file?file=%s&contentType=%s", baseURL, url.PathEscape(collection), url.QueryEscape(file), url.QueryEscape("text/plain;charset=utf-8"))
+	slog.Info("GET", "url", config.RedactURL(u))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %v", err)
+	}
+	if user != "" {
+		req.SetBasicAuth(user, pass)
+	}
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("HTTP request error: %v", err)
+	}
+	defer res.Body.Close()
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %v", file, err)
+	}
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return nil, fmt.Errorf("read %s: HTTP status %d: %s", file, res.StatusCode, body)
+	}
+	return body, nil
+}
+
+// UploadConfigFile replaces a single file of a configset with the Configsets API (Solr 8.7 and later).
+// Collections using the configset see the change after they are reloaded.
+func UploadConfigFile(ctx context.Context, httpClient *http.Client, baseURL, user, pass, configSet, file string, data []byte) error {
+	u := fmt.Sprintf("%s/solr/admin/configs?action=UPLOAD&name=%s&filePath=%s&overwrite=true&wt=json", baseURL, url.QueryEscape(configSet), url.QueryEscape(file))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("create request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	if user != "" {
+		req.SetBasicAuth(user, pass)
+	}
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("HTTP request error: %v", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		bodyBytes, _ := io.ReadAll(res.Body)
+		return fmt.Errorf("upload %s to configset %s: HTTP status %d: %s", file, configSet, res.StatusCode, string(bodyBytes))
+	}
+	_, _ = io.Copy(io.Discard, res.Body)
+	return nil
+}
+
+// ReloadCollection reloads a collection with the Collections API RELOAD action.
+func ReloadCollection(ctx context.Context, httpClient *http.Client, baseURL, user, pass, collection string) error {
+	u := fmt.Sprintf("%s/solr/admin/collections?action=RELOAD&name=%s&wt=json", baseURL, url.QueryEscape(collection))
+	if err := getJSON(ctx, httpClient, user, pass, u, nil, nil); err != nil {
+		return fmt.Errorf("failed to reload collection %s: %v", collection, err)
+	}
+	return nil
+}
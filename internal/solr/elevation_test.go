@@ -0,0 +1,56 @@
+package solr
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestElevateXML tests reading and writing elevate.xml.
+func TestElevateXML(t *testing.T) {
+	data := []byte(`<?xml version="1.0" encoding="UTF-8" ?>
+<!-- editorial boosts -->
+<elevate>
+  <query text="ipod">
+    <doc id="MA147LL/A" />
+    <doc id="IW-02" exclude="true" />
+    <doc id="F8V7067-APL-KIT" />
+  </query>
+  <query text="memory card" match="subset">
+    <doc id="VS1GB400C3" />
+  </query>
+</elevate>`)
+
+	// Goal: Elevated documents keep their order and excluded documents are listed separately.
+	rules, err := ParseElevateXML(data)
+	require.NoError(t, err)
+	assert.Equal(t, []ElevationRule{
+		{Query: "ipod", Elevate: []string{"MA147LL/A", "F8V7067-APL-KIT"}, Exclude: []string{"IW-02"}},
+		{Query: "memory card", Match: "subset", Elevate: []string{"VS1GB400C3"}},
+	}, rules)
+
+	// Goal: Rendered rules read back unchanged.
+	rendered, err := ParseElevateXML(RenderElevateXML(rules))
+	require.NoError(t, err)
+	assert.Equal(t, rules, rendered)
+
+	// Goal: Invalid files are reported.
+	_, err = ParseElevateXML([]byte("<elevate><query"))
+	assert.ErrorContains(t, err, "parse elevate.xml")
+}
+
+// TestElevationParams tests the query parameters of elevate.
+func TestElevationParams(t *testing.T) {
+	// Goal: Without fl, all fields are returned along with the [elevated] marker.
+	params := url.Values{"q": {"ipod"}}
+	ElevationParams(params)
+	assert.Equal(t, "true", params.Get("forceElevation"))
+	assert.Equal(t, []string{"*,score,[elevated]"}, params["fl"])
+
+	// Goal: A requested field list is kept and extended.
+	params = url.Values{"q": {"ipod"}, "fl": {"id"}}
+	ElevationParams(params)
+	assert.Equal(t, []string{"id", "[elevated]"}, params["fl"])
+}
@@ -0,0 +1,809 @@
+package solr
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/types"
+)
+
+// QueryExplanation is a rule-based, plain-language description of a Solr /select request.
+type QueryExplanation struct {
+	Summary  string           `json:"summary"`
+	Details  []string         `json:"details"`
+	Fields   []ExplainedField `json:"fields,omitempty"`
+	Warnings []string         `json:"warnings,omitempty"`
+}
+
+// ExplainedField is a field referenced by the request. Known is false when the schema does not have it.
+type ExplainedField struct {
+	Name  string `json:"name"`
+	Type  string `json:"type,omitempty"`
+	Known bool   `json:"known"`
+}
+
+// ParamValues converts request parameters decoded from JSON into URL values. Arrays become repeated parameters.
+func ParamValues(params map[string]any) url.Values {
+	values := url.Values{}
+	for k, v := range params {
+		switch val := v.(type) {
+		case []any:
+			for _, item := range val {
+				values.Add(k, fmt.Sprintf("%v", item))
+			}
+		case []string:
+			for _, item := range val {
+				values.Add(k, item)
+			}
+		case float64:
+			values.Add(k, strconv.FormatFloat(val, 'f', -1, 64))
+		default:
+			values.Add(k, fmt.Sprintf("%v", val))
+		}
+	}
+	return values
+}
+
+// explainer collects the fields and warnings found while describing a request.
+type explainer struct {
+	fc       *types.FieldCatalog
+	params   url.Values
+	fields   map[string]*ExplainedField
+	order    []string
+	warnings []string
+}
+
+// ExplainQuery describes what a /select request matches and returns.
+// fc, if not nil, is used to check the referenced fields and to include the parameters of the /select handler.
+func ExplainQuery(params url.Values, fc *types.FieldCatalog) QueryExplanation {
+	e := &explainer{fc: fc, params: params, fields: map[string]*ExplainedField{}}
+	var details, filters []string
+
+	q := e.param("q")
+	if q == "" {
+		q = "*:*"
+	}
+	matches := e.describeQuery(q, e.defaultField())
+	details = append(details, "Query: matches documents where "+matches+".")
+	switch defType := e.param("defType"); defType {
+	case "edismax", "dismax":
+		details = append(details, fmt.Sprintf("The query is parsed by %s and searched in %s.", defType, e.defaultField()))
+		if mm := e.param("mm"); mm != "" {
+			details = append(details, fmt.Sprintf("At least %s of the optional query clauses must match.", mm))
+		}
+	case "", "lucene":
+	default:
+		details = append(details, fmt.Sprintf("The query is parsed by the %s query parser.", defType))
+	}
+
+	fqs := append([]string{}, e.params["fq"]...)
+	if fc != nil && fc.SelectHandler != nil {
+		fqs = append(fqs, handlerValues(fc.SelectHandler.Appends, "fq")...)
+		fqs = append(fqs, handlerValues(fc.SelectHandler.Invariants, "fq")...)
+	}
+	for _, fq := range fqs {
+		f := e.describeFilter(fq)
+		filters = append(filters, f)
+		details = append(details, "Filter: "+f+".")
+	}
+
+	details = append(details, e.describeSort(q == "*:*"))
+	details = append(details, e.describePaging()...)
+	details = append(details, e.describeFields())
+	details = append(details, e.describeFacets()...)
+	details = append(details, e.describeOther()...)
+
+	summary := "Finds documents where " + matches
+	if len(filters) > 0 {
+		summary += ", limited to " + strings.Join(filters, "; ")
+	}
+	summary += "."
+
+	out := QueryExplanation{Summary: summary, Details: details, Warnings: e.warnings}
+	for _, name := range e.order {
+		out.Fields = append(out.Fields, *e.fields[name])
+	}
+	return out
+}
+
+// param returns a request parameter, falling back to the defaults of the /select handler. Invariants win over both.
+func (e *explainer) param(key string) string {
+	if e.fc != nil && e.fc.SelectHandler != nil {
+		if v := handlerValues(e.fc.SelectHandler.Invariants, key); len(v) > 0 {
+			return v[0]
+		}
+	}
+	if v := e.params.Get(key); v != "" {
+		return v
+	}
+	if e.fc != nil && e.fc.SelectHandler != nil {
+		if v := handlerValues(e.fc.SelectHandler.Defaults, key); len(v) > 0 {
+			return v[0]
+		}
+	}
+	return ""
+}
+
+func handlerValues(m map[string]any, key string) []string {
+	switch v := m[key].(type) {
+	case nil:
+		return nil
+	case []any:
+		var out []string
+		for _, item := range v {
+			out = append(out, fmt.Sprintf("%v", item))
+		}
+		return out
+	default:
+		return []string{fmt.Sprintf("%v", v)}
+	}
+}
+
+// defaultField describes where clauses without a field are searched.
+func (e *explainer) defaultField() string {
+	switch e.param("defType") {
+	case "edismax", "dismax":
+		if qf := strings.Fields(e.param("qf")); len(qf) > 0 {
+			for i, f := range qf {
+				name, boost, _ := strings.Cut(f, "^")
+				e.field(name)
+				if boost != "" {
+					qf[i] = fmt.Sprintf("%s (boost %s)", name, boost)
+				}
+			}
+			return "any of " + strings.Join(qf, ", ")
+		}
+	}
+	if df := e.param("df"); df != "" {
+		e.field(df)
+		return df
+	}
+	return "the default field"
+}
+
+// field records a referenced field and returns its type, if known.
+func (e *explainer) field(name string) string {
+	if name == "" || strings.ContainsAny(name, "*()") || name == "score" || name == "_query_" {
+		return ""
+	}
+	f, ok := e.fields[name]
+	if !ok {
+		f = &ExplainedField{Name: name, Known: true}
+		if e.fc != nil {
+			f.Known = e.fc.HasField(name)
+			for _, sf := range e.fc.All {
+				if sf.Name == name {
+					f.Type = sf.Type
+				}
+			}
+			if !f.Known {
+				e.warn(fmt.Sprintf("Field %s is not in the schema of the collection; clauses on it match nothing or fail.", name))
+			}
+		}
+		e.fields[name] = f
+		e.order = append(e.order, name)
+	}
+	return f.Type
+}
+
+func (e *explainer) warn(msg string) {
+	for _, w := range e.warnings {
+		if w == msg {
+			return
+		}
+	}
+	e.warnings = append(e.warnings, msg)
+}
+
+var localParamsRe = regexp.MustCompile(`^\{!\s*([^}]*)\}(.*)$`)
+
+// describeFilter describes a filter query, handling the common local parameters.
+func (e *explainer) describeFilter(fq string) string {
+	if m := localParamsRe.FindStringSubmatch(strings.TrimSpace(fq)); m != nil {
+		lp := parseLocalParams(m[1])
+		switch lp["type"] {
+		case "collapse":
+			e.field(lp["field"])
+			return fmt.Sprintf("one document per value of %s (collapsed)", lp["field"])
+		case "", "lucene":
+			// tag, cache and cost only affect faceting and caching
+			return e.describeQuery(m[2], e.defaultField())
+		}
+	}
+	return e.describeQuery(fq, e.defaultField())
+}
+
+// parseLocalParams parses "type key=value ..." of local parameters. A bare first word is the parser type.
+func parseLocalParams(s string) map[string]string {
+	lp := map[string]string{}
+	for i, part := range strings.Fields(s) {
+		k, v, ok := strings.Cut(part, "=")
+		switch {
+		case ok:
+			lp[k] = strings.Trim(v, `"'`)
+		case i == 0:
+			lp["type"] = k
+		}
+	}
+	return lp
+}
+
+// qnode is a node of a parsed Lucene query.
+type qnode struct {
+	kind     string // "clause", "and", "or", "not", "must"
+	text     string
+	field    string // default field of the clause
+	children []*qnode
+}
+
+// describeQuery describes a query in the standard (lucene) syntax.
+func (e *explainer) describeQuery(q, defaultField string) string {
+	q = strings.TrimSpace(q)
+	if m := localParamsRe.FindStringSubmatch(q); m != nil {
+		lp := parseLocalParams(m[1])
+		if t := lp["type"]; t != "" && t != "lucene" {
+			return fmt.Sprintf("the %s query parser matches %q", t, strings.TrimSpace(m[2]))
+		}
+		q = strings.TrimSpace(m[2])
+	}
+	p := &qparser{tokens: lexQuery(q), defaultAnd: strings.EqualFold(e.param("q.op"), "AND"), field: defaultField}
+	node := p.parseOr()
+	if p.pos < len(p.tokens) || p.broken {
+		e.warn(fmt.Sprintf("Could not fully parse %q (unbalanced parentheses or quotes?); the explanation may be incomplete.", q))
+	}
+	if node == nil {
+		return "nothing (empty query)"
+	}
+	for _, t := range p.tokens {
+		if t == "and" || t == "or" {
+			e.warn(fmt.Sprintf("Lowercase %q in %q is searched as a word, not used as an operator.", t, q))
+		}
+	}
+	return e.describeNode(node, true)
+}
+
+func (e *explainer) describeNode(n *qnode, top bool) string {
+	switch n.kind {
+	case "clause":
+		return e.describeClause(n.text, n.field)
+	case "not":
+		return "not (" + e.describeNode(n.children[0], false) + ")"
+	case "must":
+		return e.describeNode(n.children[0], false)
+	case "and":
+		parts := make([]string, len(n.children))
+		for i, c := range n.children {
+			parts[i] = e.describeNode(c, false)
+		}
+		return wrap(strings.Join(parts, " and "), !top)
+	}
+
+	var must, should, not []string
+	for _, c := range n.children {
+		switch c.kind {
+		case "must":
+			must = append(must, e.describeNode(c.children[0], false))
+		case "not":
+			not = append(not, e.describeNode(c.children[0], false))
+		default:
+			should = append(should, e.describeNode(c, false))
+		}
+	}
+	if !top && len(must) == 0 && len(should) == 0 {
+		e.warn("A parenthesized group with only negative clauses matches nothing; add *:* to it, e.g. (*:* -field:value).")
+	}
+	var s string
+	switch {
+	case len(must) > 0:
+		s = strings.Join(must, " and ")
+		if len(should) > 0 {
+			s += ", ranking higher those where " + strings.Join(should, " or ")
+		}
+	case len(should) > 0:
+		s = strings.Join(should, " or ")
+	default:
+		s = "any document"
+	}
+	if len(not) > 0 {
+		s += ", excluding those where " + strings.Join(not, " or ")
+	}
+	return wrap(s, !top && len(n.children) > 1)
+}
+
+func wrap(s string, paren bool) string {
+	if paren {
+		return "(" + s + ")"
+	}
+	return s
+}
+
+var (
+	boostRe  = regexp.MustCompile(`\^([0-9.]+)$`)
+	fuzzyRe  = regexp.MustCompile(`~([0-9.]*)$`)
+	rangeRe  = regexp.MustCompile(`^([\[{])\s*(.*?)\s+TO\s+(.*?)\s*([\]}])$`)
+	dateOpRe = regexp.MustCompile(`([+-]\d+|/)([A-Z]+)`)
+)
+
+// describeClause describes a single field:value clause.
+func (e *explainer) describeClause(text, defaultField string) string {
+	suffix := ""
+	if m := boostRe.FindStringSubmatch(text); m != nil && !strings.HasSuffix(text, `\^`+m[1]) {
+		text = strings.TrimSuffix(text, m[0])
+		suffix = fmt.Sprintf(" (boost %s)", m[1])
+	}
+	if text == "*:*" || text == "*" {
+		return "any document" + suffix
+	}
+	if strings.HasPrefix(text, "{!") {
+		return e.describeQuery(text, defaultField) + suffix
+	}
+
+	field, value := defaultField, text
+	if i := fieldSeparator(text); i > 0 {
+		field, value = unescape(text[:i]), text[i+1:]
+		if field == "_query_" {
+			return "the nested query " + value + suffix
+		}
+	}
+	fieldType := "text"
+	if !strings.HasPrefix(field, "any of ") && field != "the default field" {
+		fieldType = e.field(field)
+	}
+
+	switch {
+	case value == "*":
+		return field + " has any value" + suffix
+	case rangeRe.MatchString(value):
+		return describeRange(field, rangeRe.FindStringSubmatch(value)) + suffix
+	case strings.HasPrefix(value, `"`):
+		phrase, slop, _ := strings.Cut(strings.TrimPrefix(value, `"`), `"~`)
+		phrase = strings.TrimSuffix(phrase, `"`)
+		if slop != "" {
+			return fmt.Sprintf("%s contains the words %q within %s positions of each other%s", field, phrase, slop, suffix)
+		}
+		return fmt.Sprintf("%s contains the phrase %q%s", field, phrase, suffix)
+	case strings.HasPrefix(value, "/") && strings.HasSuffix(value, "/") && len(value) > 1:
+		return fmt.Sprintf("%s matches the regular expression %s%s", field, value, suffix)
+	case strings.ContainsAny(value, "*?"):
+		return e.describeWildcard(field, value) + suffix
+	case fuzzyRe.MatchString(value) && !strings.HasSuffix(value, `\~`):
+		m := fuzzyRe.FindStringSubmatch(value)
+		distance := m[1]
+		if distance == "" {
+			distance = "2"
+		}
+		return fmt.Sprintf("%s is similar to %q (up to %s edits)%s", field, unescape(strings.TrimSuffix(value, m[0])), distance, suffix)
+	case strings.Contains(strings.ToLower(fieldType), "text"):
+		return fmt.Sprintf("%s contains %q%s", field, unescape(value), suffix)
+	case fieldType == "":
+		return fmt.Sprintf("%s matches %q%s", field, unescape(value), suffix)
+	case fieldType == "string":
+		return fmt.Sprintf("%s is %q%s", field, unescape(value), suffix)
+	default:
+		return fmt.Sprintf("%s is %s%s", field, describeValue(unescape(value)), suffix)
+	}
+}
+
+func (e *explainer) describeWildcard(field, value string) string {
+	core := strings.Trim(value, "*")
+	switch {
+	case strings.HasPrefix(value, "*") || strings.HasPrefix(value, "?"):
+		e.warn(fmt.Sprintf("The leading wildcard in %s:%s scans the whole term dictionary and can be slow; consider ReversedWildcardFilterFactory or an n-gram field.", field, value))
+		if strings.HasSuffix(value, "*") && !strings.ContainsAny(core, "*?") {
+			return fmt.Sprintf("%s contains the substring %q", field, unescape(core))
+		}
+		if !strings.ContainsAny(core, "*?") {
+			return fmt.Sprintf("%s ends with %q", field, unescape(core))
+		}
+	case strings.HasSuffix(value, "*") && !strings.ContainsAny(core, "*?"):
+		return fmt.Sprintf("%s starts with %q", field, unescape(core))
+	}
+	return fmt.Sprintf("%s matches the pattern %q", field, value)
+}
+
+func describeRange(field string, m []string) string {
+	lower, upper := describeValue(m[2]), describeValue(m[3])
+	lowerIncl, upperIncl := m[1] == "[", m[4] == "]"
+	switch {
+	case m[2] == "*" && m[3] == "*":
+		return field + " has any value"
+	case m[2] == "*":
+		if upperIncl {
+			return fmt.Sprintf("%s is at most %s", field, upper)
+		}
+		return fmt.Sprintf("%s is less than %s", field, upper)
+	case m[3] == "*":
+		if lowerIncl {
+			return fmt.Sprintf("%s is at least %s", field, lower)
+		}
+		return fmt.Sprintf("%s is greater than %s", field, lower)
+	case lowerIncl && upperIncl:
+		return fmt.Sprintf("%s is between %s and %s (inclusive)", field, lower, upper)
+	case !lowerIncl && !upperIncl:
+		return fmt.Sprintf("%s is between %s and %s (exclusive)", field, lower, upper)
+	case lowerIncl:
+		return fmt.Sprintf("%s is from %s up to but excluding %s", field, lower, upper)
+	default:
+		return fmt.Sprintf("%s is after %s up to and including %s", field, lower, upper)
+	}
+}
+
+// describeValue spells out date math such as NOW-7DAYS/DAY and quotes other values.
+func describeValue(v string) string {
+	v = strings.Trim(v, `"`)
+	if _, err := strconv.ParseFloat(v, 64); err == nil {
+		return v
+	}
+	if !strings.HasPrefix(v, "NOW") {
+		return strconv.Quote(v)
+	}
+	parts := []string{"now"}
+	for _, m := range dateOpRe.FindAllStringSubmatch(strings.TrimPrefix(v, "NOW"), -1) {
+		unit := strings.ToLower(strings.TrimSuffix(m[2], "S"))
+		switch {
+		case m[1] == "/":
+			parts = append(parts, "rounded down to the "+unit)
+		case strings.HasPrefix(m[1], "-"):
+			parts = append(parts, fmt.Sprintf("minus %s %s(s)", m[1][1:], unit))
+		default:
+			parts = append(parts, fmt.Sprintf("plus %s %s(s)", m[1][1:], unit))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// fieldSeparator returns the index of the colon separating the field name from the value, or -1.
+func fieldSeparator(s string) int {
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"', '[', '{', '(', '/':
+			return -1
+		case ':':
+			return i
+		}
+	}
+	return -1
+}
+
+func unescape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// lexQuery splits a Lucene query into parentheses, operators, modifiers and clauses.
+// A "field:(" prefix is returned as a single token so that the group applies to that field.
+func lexQuery(q string) []string {
+	var tokens []string
+	i := 0
+	for i < len(q) {
+		c := q[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n':
+			i++
+		case c == '(' || c == ')':
+			tokens = append(tokens, string(c))
+			i++
+		case (c == '+' || c == '-' || c == '!') && i+1 < len(q) && q[i+1] != ' ':
+			tokens = append(tokens, string(c))
+			i++
+		case strings.HasPrefix(q[i:], "&&") || strings.HasPrefix(q[i:], "||"):
+			tokens = append(tokens, q[i:i+2])
+			i += 2
+		default:
+			start := i
+			depth := 0 // inside quotes, ranges or local params
+			var closer byte
+			for i < len(q) {
+				ch := q[i]
+				if ch == '\\' {
+					i += 2
+					continue
+				}
+				if depth > 0 {
+					if ch == closer || (closer == ']' && ch == '}') || (closer == '}' && ch == ']' && q[start] != '{') {
+						depth = 0
+					}
+					i++
+					continue
+				}
+				if ch == '"' {
+					depth, closer = 1, '"'
+				} else if ch == '[' {
+					depth, closer = 1, ']'
+				} else if ch == '{' {
+					depth, closer = 1, '}'
+				} else if ch == ' ' || ch == '\t' || ch == '\n' || ch == ')' {
+					break
+				} else if ch == '(' {
+					if i > start && q[i-1] == ':' {
+						i++
+					}
+					break
+				}
+				i++
+			}
+			if i > len(q) {
+				i = len(q)
+			}
+			tokens = append(tokens, q[start:i])
+		}
+	}
+	return tokens
+}
+
+// qparser is a recursive descent parser over the tokens of lexQuery. Precedence: NOT, AND, OR.
+type qparser struct {
+	tokens     []string
+	pos        int
+	defaultAnd bool
+	field      string
+	broken     bool
+}
+
+func (p *qparser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *qparser) operand() bool {
+	switch t := p.peek(); t {
+	case "", ")", "AND", "OR", "&&", "||":
+		return false
+	}
+	return true
+}
+
+func (p *qparser) parseOr() *qnode {
+	n := &qnode{kind: "or"}
+	for {
+		if c := p.parseAnd(); c != nil {
+			n.children = append(n.children, c)
+		}
+		if t := p.peek(); t == "OR" || t == "||" {
+			p.pos++
+			continue
+		}
+		if !p.defaultAnd && p.operand() {
+			continue
+		}
+		break
+	}
+	return simplify(n)
+}
+
+func (p *qparser) parseAnd() *qnode {
+	n := &qnode{kind: "and"}
+	for {
+		c := p.parseUnary()
+		if c == nil {
+			break
+		}
+		n.children = append(n.children, c)
+		if t := p.peek(); t == "AND" || t == "&&" {
+			p.pos++
+			continue
+		}
+		if p.defaultAnd && p.operand() {
+			continue
+		}
+		break
+	}
+	return simplify(n)
+}
+
+func (p *qparser) parseUnary() *qnode {
+	switch t := p.peek(); t {
+	case "NOT", "!", "-":
+		p.pos++
+		if c := p.parseUnary(); c != nil {
+			return &qnode{kind: "not", children: []*qnode{c}}
+		}
+		return nil
+	case "+":
+		p.pos++
+		if c := p.parseUnary(); c != nil {
+			return &qnode{kind: "must", children: []*qnode{c}}
+		}
+		return nil
+	case "(":
+		p.pos++
+		return p.parseGroup(p.field)
+	case "", ")", "AND", "OR", "&&", "||":
+		return nil
+	default:
+		p.pos++
+		if strings.HasSuffix(t, ":(") {
+			return p.parseGroup(unescape(strings.TrimSuffix(t, ":(")))
+		}
+		return &qnode{kind: "clause", text: t, field: p.field}
+	}
+}
+
+// parseGroup parses up to the closing parenthesis, with clauses defaulting to field.
+func (p *qparser) parseGroup(field string) *qnode {
+	outer := p.field
+	p.field = field
+	n := p.parseOr()
+	p.field = outer
+	if p.peek() == ")" {
+		p.pos++
+	} else {
+		p.broken = true
+	}
+	return n
+}
+
+// simplify unwraps single-child nodes. An OR node of a single NOT or + clause is kept,
+// since it describes "all documents except" and pure negative groups.
+func simplify(n *qnode) *qnode {
+	switch len(n.children) {
+	case 0:
+		return nil
+	case 1:
+		if c := n.children[0]; n.kind == "and" || (c.kind != "not" && c.kind != "must") {
+			return c
+		}
+	}
+	return n
+}
+
+// describeSort describes the sort order. matchAll is true for *:* queries, whose scores are all equal.
+func (e *explainer) describeSort(matchAll bool) string {
+	s := e.param("sort")
+	if s == "" {
+		if matchAll {
+			return "Sorted by index order, since all documents score the same."
+		}
+		return "Sorted by relevance score, best matches first."
+	}
+	var parts []string
+	for _, clause := range strings.Split(s, ",") {
+		f := strings.Fields(clause)
+		if len(f) == 0 {
+			continue
+		}
+		dir := "ascending"
+		if len(f) > 1 && strings.EqualFold(f[len(f)-1], "desc") {
+			dir = "descending"
+		}
+		name := strings.Join(f[:max(1, len(f)-1)], " ")
+		if !strings.Contains(name, "(") {
+			e.field(name)
+		}
+		parts = append(parts, name+" "+dir)
+	}
+	return "Sorted by " + strings.Join(parts, ", then ") + "."
+}
+
+func (e *explainer) describePaging() []string {
+	rows, start := 10, 0
+	if v, err := strconv.Atoi(e.param("rows")); err == nil {
+		rows = v
+	}
+	if v, err := strconv.Atoi(e.param("start")); err == nil {
+		start = v
+	}
+	var out []string
+	switch {
+	case rows == 0:
+		out = append(out, "Returns only the number of matching documents (rows=0).")
+	case start > 0:
+		out = append(out, fmt.Sprintf("Returns up to %d documents, skipping the first %d.", rows, start))
+	default:
+		out = append(out, fmt.Sprintf("Returns up to %d documents.", rows))
+	}
+	if cursor := e.param("cursorMark"); cursor != "" {
+		out = append(out, "Pages with cursorMark; the sort must include the unique key.")
+		if e.fc != nil && e.fc.UniqueKey != "" && !strings.Contains(e.param("sort"), e.fc.UniqueKey) {
+			e.warn(fmt.Sprintf("cursorMark needs a sort that includes the unique key %s.", e.fc.UniqueKey))
+		}
+	}
+	if start >= 10000 {
+		e.warn(fmt.Sprintf("Deep paging (start=%d) is expensive on every shard; use cursorMark instead.", start))
+	}
+	if rows > 1000 {
+		e.warn(fmt.Sprintf("rows=%d returns a very large page; consider paging or the export handler.", rows))
+	}
+	return out
+}
+
+func (e *explainer) describeFields() string {
+	fl := e.param("fl")
+	if fl == "" || fl == "*" {
+		return "Returns all stored fields of each document."
+	}
+	var names []string
+	for _, f := range strings.FieldsFunc(fl, func(r rune) bool { return r == ',' || r == ' ' }) {
+		names = append(names, f)
+		if !strings.ContainsAny(f, "*[(:") {
+			e.field(f)
+		}
+	}
+	return "Returns the fields " + strings.Join(names, ", ") + "."
+}
+
+func (e *explainer) describeFacets() []string {
+	var out []string
+	if e.param("facet") == "true" || e.param("facet") == "on" {
+		for _, f := range e.params["facet.field"] {
+			e.field(f)
+			out = append(out, fmt.Sprintf("Counts the matching documents per value of %s.", f))
+		}
+		for _, fq := range e.params["facet.query"] {
+			out = append(out, fmt.Sprintf("Counts the matching documents where %s.", e.describeQuery(fq, e.defaultField())))
+		}
+		for _, f := range e.params["facet.range"] {
+			e.field(f)
+			out = append(out, fmt.Sprintf("Counts the matching documents per %s of %s from %s to %s.",
+				e.facetParam(f, "gap"), f, describeValue(e.facetParam(f, "start")), describeValue(e.facetParam(f, "end"))))
+		}
+	}
+	if jf := e.param("json.facet"); jf != "" {
+		out = append(out, "Computes JSON facets: "+jf+".")
+	}
+	if e.param("group") == "true" {
+		if f := e.param("group.field"); f != "" {
+			e.field(f)
+			out = append(out, fmt.Sprintf("Groups the results by %s.", f))
+		}
+	}
+	if e.param("hl") == "true" || e.param("hl") == "on" {
+		out = append(out, fmt.Sprintf("Highlights the matches in %s.", orDefault(e.param("hl.fl"), "the default highlight fields")))
+	}
+	return out
+}
+
+// facetParam returns a range facet parameter, preferring the per-field form f.<field>.facet.range.<name>.
+func (e *explainer) facetParam(field, name string) string {
+	if v := e.param("f." + field + ".facet.range." + name); v != "" {
+		return v
+	}
+	return e.param("facet.range." + name)
+}
+
+func (e *explainer) describeOther() []string {
+	var out []string
+	if v := e.param("timeAllowed"); v != "" {
+		out = append(out, fmt.Sprintf("Stops searching after %s ms and may return partial results.", v))
+	}
+	if v := e.param("cpuAllowed"); v != "" {
+		out = append(out, fmt.Sprintf("Stops after %s ms of CPU time and may return partial results.", v))
+	}
+	if v := e.param("memAllowed"); v != "" {
+		out = append(out, fmt.Sprintf("Stops after allocating %s MB and may return partial results.", v))
+	}
+	if e.fc != nil && e.fc.SelectHandler != nil {
+		var keys []string
+		for k := range e.fc.SelectHandler.Invariants {
+			if k != "fq" {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			out = append(out, fmt.Sprintf("The /select handler always sets %s=%s; request values are ignored.", k, strings.Join(handlerValues(e.fc.SelectHandler.Invariants, k), ",")))
+		}
+	}
+	return out
+}
+
+func orDefault(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
@@ -0,0 +1,86 @@
+package solr
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/types"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestExplainQueryClauses tests the description of the query syntax.
+func TestExplainQueryClauses(t *testing.T) {
+	fc := &types.FieldCatalog{
+		UniqueKey: "id",
+		All: []types.SolrField{
+			{Name: "id", Type: "string"},
+			{Name: "title", Type: "text_general"},
+			{Name: "status", Type: "string"},
+			{Name: "price", Type: "pfloat"},
+			{Name: "created", Type: "pdate"},
+		},
+	}
+	tests := []struct {
+		q    string
+		want string
+	}{
+		{"*:*", "any document"},
+		{`title:solr`, `title contains "solr"`},
+		{`status:active AND price:[10 TO 100]`, `status is "active" and price is between 10 and 100 (inclusive)`},
+		{`title:"apache solr"~3`, `title contains the words "apache solr" within 3 positions of each other`},
+		{`title:sol*`, `title starts with "sol"`},
+		{`created:[NOW-7DAYS/DAY TO *]`, `created is at least now, minus 7 day(s), rounded down to the day`},
+		{`price:{* TO 5}`, `price is less than 5`},
+		{`+title:solr -status:deleted`, `title contains "solr", excluding those where status is "deleted"`},
+		{`title:solr title:lucene^2`, `title contains "solr" or title contains "lucene" (boost 2)`},
+		{`status:(active OR pending)`, `status is "active" or status is "pending"`},
+		{`-status:deleted`, `any document, excluding those where status is "deleted"`},
+	}
+	for _, tt := range tests {
+		// Goal: Each clause type is described in plain language.
+		got := ExplainQuery(url.Values{"q": {tt.q}}, fc)
+		assert.Equal(t, "Query: matches documents where "+tt.want+".", got.Details[0], tt.q)
+	}
+}
+
+// TestExplainQueryRequest tests the description of filters, sorting, paging and the warnings.
+func TestExplainQueryRequest(t *testing.T) {
+	fc := &types.FieldCatalog{
+		UniqueKey: "id",
+		All:       []types.SolrField{{Name: "id", Type: "string"}, {Name: "title", Type: "text_general"}, {Name: "status", Type: "string"}},
+		SelectHandler: &types.HandlerParams{
+			Defaults:   map[string]any{"df": "title"},
+			Invariants: map[string]any{"fq": "status:published"},
+		},
+	}
+	params := url.Values{
+		"q":     {"solr"},
+		"fq":    {"{!tag=s}status:active", "colour:red"},
+		"sort":  {"id desc"},
+		"start": {"20000"},
+		"rows":  {"5"},
+		"fl":    {"id,title"},
+	}
+	got := ExplainQuery(params, fc)
+
+	// Goal: The summary combines the query with the request and handler filters.
+	assert.Equal(t, `Finds documents where title contains "solr", limited to status is "active"; colour matches "red"; status is "published".`, got.Summary)
+
+	// Goal: Sorting, paging and returned fields are described.
+	assert.Contains(t, got.Details, "Sorted by id descending.")
+	assert.Contains(t, got.Details, "Returns up to 5 documents, skipping the first 20000.")
+	assert.Contains(t, got.Details, "Returns the fields id, title.")
+
+	// Goal: Unknown fields and deep paging are warned about.
+	assert.Contains(t, got.Warnings, "Field colour is not in the schema of the collection; clauses on it match nothing or fail.")
+	assert.Contains(t, got.Warnings, "Deep paging (start=20000) is expensive on every shard; use cursorMark instead.")
+	assert.Contains(t, got.Fields, ExplainedField{Name: "colour", Known: false})
+
+	// Goal: Leading wildcards, pure negative groups and lowercase operators are warned about.
+	got = ExplainQuery(url.Values{"q": {"title:*sol and (-status:x)"}}, nil)
+	assert.Len(t, got.Warnings, 3)
+
+	// Goal: Parameters decoded from JSON become repeated URL parameters.
+	assert.Equal(t, url.Values{"fq": {"a:1", "b:2"}, "rows": {"0"}}, ParamValues(map[string]any{"fq": []any{"a:1", "b:2"}, "rows": float64(0)}))
+}
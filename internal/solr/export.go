@@ -0,0 +1,71 @@
+package solr
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// CursorPage reads the documents of query in collection after cursor ("*" for the first page), sorted by uniqueKey.
+// It returns the cursor of the next page, which equals cursor after the last page.
+func CursorPage(ctx context.Context, httpClient *http.Client, baseURL, user, pass, collection, query string, fields []string, uniqueKey, cursor string, rows int) ([]map[string]any, string, error) {
+	fl := "*"
+	if len(fields) > 0 {
+		fl = strings.Join(fields, ",")
+	}
+	values := url.Values{
+		"q":          {query},
+		"fl":         {fl},
+		"sort":       {uniqueKey + " asc"},
+		"rows":       {strconv.Itoa(rows)},
+		"cursorMark": {cursor},
+		"wt":         {"json"},
+	}
+	resp, err := getSelect(ctx, httpClient, user, pass, SelectURL(baseURL, collection), values)
+	if err != nil {
+		return nil, "", err
+	}
+	respObj, _ := resp["response"].(map[string]any)
+	list, _ := respObj["docs"].([]any)
+	docs := make([]map[string]any, 0, len(list))
+	for _, d := range list {
+		if doc, ok := d.(map[string]any); ok {
+			docs = append(docs, doc)
+		}
+	}
+	next, _ := resp["nextCursorMark"].(string)
+	return docs, next, nil
+}
+
+// ReindexSkipFields returns the fields that are not copied into collection: the targets of its copyField rules,
+// which the schema populates again, _version_, which would trigger optimistic concurrency, and score.
+func ReindexSkipFields(ctx context.Context, httpClient *http.Client, baseURL, user, pass, collection string) ([]string, error) {
+	skip, err := copyFieldTargets(ctx, httpClient, baseURL, user, pass, collection)
+	if err != nil {
+		return nil, err
+	}
+	return append(skip, "_version_", "score"), nil
+}
+
+// WithoutFields returns a copy of doc without the fields matching skip, which may contain dynamic field patterns.
+func WithoutFields(doc map[string]any, skip []string) map[string]any {
+	out := make(map[string]any, len(doc))
+	for k, v := range doc {
+		if !matchesAnyField(skip, k) {
+			out[k] = v
+		}
+	}
+	return out
+}
+
+// IndexDocuments adds docs to collection without committing them.
+func IndexDocuments(ctx context.Context, httpClient *http.Client, baseURL, user, pass, collection string, docs []map[string]any) error {
+	return postUpdate(ctx, httpClient, baseURL, user, pass, collection, docs, false)
+}
+
+// Commit makes the documents added to collection visible.
+func Commit(ctx context.Context, httpClient *http.Client, baseURL, user, pass, collection string) error {
+	return postUpdate(ctx, httpClient, baseURL, user, pass, collection, map[string]any{"commit": map[string]any{}}, true)
+}
@@ -0,0 +1,84 @@
+package solr
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// DiskUsage is the space of the file system holding the data directory of a Solr node.
+type DiskUsage struct {
+	Usable    int64   `json:"usable"`
+	Total     int64   `json:"total"`
+	FreeShare float64 `json:"freeShare"`
+}
+
+// FetchDiskUsage reads the file system metrics of the node at baseURL.
+func FetchDiskUsage(ctx context.Context, httpClient *http.Client, baseURL, user, pass string) (DiskUsage, error) {
+	q := url.Values{"group": {"node"}, "prefix": {"CONTAINER.fs."}, "wt": {"json"}}
+	var resp struct {
+		Metrics map[string]map[string]any `json:"metrics"`
+	}
+	if err := getJSON(ctx, httpClient, user, pass, baseURL+"/solr/admin/metrics?"+q.Encode(), &resp, nil); err != nil {
+		return DiskUsage{}, fmt.Errorf("disk metrics: %v", err)
+	}
+	var d DiskUsage
+	if f, ok := metricValue(resp.Metrics["solr.node"]["CONTAINER.fs.usableSpace"]); ok {
+		d.Usable = int64(f)
+	}
+	if f, ok := metricValue(resp.Metrics["solr.node"]["CONTAINER.fs.totalSpace"]); ok {
+		d.Total = int64(f)
+	}
+	if d.Total <= 0 {
+		return DiskUsage{}, fmt.Errorf("disk metrics: the node reports no CONTAINER.fs.totalSpace")
+	}
+	d.FreeShare = float64(d.Usable) / float64(d.Total)
+	return d, nil
+}
+
+// LogEvent is an entry of the log history of a Solr node.
+type LogEvent struct {
+	Time    time.Time `json:"time"`
+	Level   string    `json:"level"`
+	Logger  string    `json:"logger,omitempty"`
+	Core    string    `json:"core,omitempty"`
+	Message string    `json:"message"`
+}
+
+// RecentErrors returns the ERROR and SEVERE events the node at baseURL logged after since, oldest first. The log
+// watcher of Solr keeps the last events at WARN and above, 50 by default, so older events may be missing. The
+// watcher threshold is left as it is, since setting it changes it for every client.
+func RecentErrors(ctx context.Context, httpClient *http.Client, baseURL, user, pass string, since time.Time) ([]LogEvent, error) {
+	q := url.Values{"since": {strconv.FormatInt(since.UnixMilli(), 10)}, "wt": {"json"}}
+	var resp struct {
+		History struct {
+			Docs []struct {
+				Time    string `json:"time"`
+				Level   string `json:"level"`
+				Logger  string `json:"logger"`
+				Core    string `json:"core"`
+				Message string `json:"message"`
+			} `json:"docs"`
+		} `json:"history"`
+	}
+	if err := getJSON(ctx, httpClient, user, pass, baseURL+"/solr/admin/info/logging?"+q.Encode(), &resp, nil); err != nil {
+		return nil, fmt.Errorf("log history: %v", err)
+	}
+	var events []LogEvent
+	for _, d := range resp.History.Docs {
+		if d.Level != "ERROR" && d.Level != "SEVERE" {
+			continue
+		}
+		at, _ := time.Parse(time.RFC3339Nano, d.Time)
+		if !at.IsZero() && at.Before(since) {
+			continue
+		}
+		events = append(events, LogEvent{Time: at, Level: d.Level, Logger: d.Logger, Core: d.Core, Message: d.Message})
+	}
+	sort.SliceStable(events, func(i, j int) bool { return events[i].Time.Before(events[j].Time) })
+	return events, nil
+}
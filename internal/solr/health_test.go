@@ -0,0 +1,47 @@
+package solr
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestNodeHealth tests reading the disk usage and the recent errors of a node.
+func TestNodeHealth(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/solr/admin/metrics":
+			assert.Equal(t, "node", r.URL.Query().Get("group"))
+			fmt.Fprint(w, `{"metrics":{"solr.node":{"CONTAINER.fs.usableSpace":{"value":250},"CONTAINER.fs.totalSpace":1000}}}`)
+		case "/solr/admin/info/logging":
+			assert.Empty(t, r.URL.Query().Get("threshold"), "the watcher threshold is shared by all clients")
+			fmt.Fprint(w, `{"history":{"numFound":4,"docs":[
+				{"time":"2025-01-01T12:10:00Z","level":"ERROR","logger":"o.a.s.h.RequestHandlerBase","core":"products_shard1_replica_n1","message":"undefined field foo"},
+				{"time":"2025-01-01T12:05:00Z","level":"WARN","message":"slow query"},
+				{"time":"2025-01-01T12:01:00Z","level":"SEVERE","message":"Index locked"},
+				{"time":"2025-01-01T11:00:00Z","level":"ERROR","message":"before the window"}]}}`)
+		}
+	}))
+	defer server.Close()
+	ctx := context.Background()
+
+	// Goal: The free share of the disk is derived from the file system metrics.
+	d, err := FetchDiskUsage(ctx, server.Client(), server.URL, "", "")
+	require.NoError(t, err)
+	assert.Equal(t, DiskUsage{Usable: 250, Total: 1000, FreeShare: 0.25}, d)
+
+	// Goal: Only errors after since are returned, oldest first.
+	since := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	events, err := RecentErrors(ctx, server.Client(), server.URL, "", "", since)
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	assert.Equal(t, "Index locked", events[0].Message)
+	assert.Equal(t, "products_shard1_replica_n1", events[1].Core)
+	assert.Equal(t, since.Add(10*time.Minute), events[1].Time)
+}
@@ -0,0 +1,66 @@
+package solr
+
+import (
+	"net/url"
+	"strconv"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/config"
+)
+
+// QueryLimits reports the resource limits added to a query and whether Solr stopped early.
+type QueryLimits struct {
+	Applied        map[string]string `json:"applied,omitempty"`
+	Omitted        []string          `json:"omitted,omitempty"` // configured limits the Solr version does not support
+	PartialResults bool              `json:"partialResults"`
+	Details        any               `json:"partialResultsDetails,omitempty"`
+}
+
+// ApplyQueryLimits adds the configured limits to params unless the request already sets them.
+// Limits that need Solr 9.6 are left out when version is known to be older.
+func ApplyQueryLimits(params url.Values, limits config.QueryLimitsConfig, version Version, known bool) *QueryLimits {
+	type limit struct {
+		name    string
+		value   string
+		needs96 bool
+	}
+	var configured []limit
+	if limits.TimeAllowed > 0 {
+		configured = append(configured, limit{"timeAllowed", strconv.Itoa(limits.TimeAllowed), false})
+	}
+	if limits.CPUAllowed > 0 {
+		configured = append(configured, limit{"cpuAllowed", strconv.Itoa(limits.CPUAllowed), true})
+	}
+	if limits.MemAllowed > 0 {
+		configured = append(configured, limit{"memAllowed", strconv.FormatFloat(limits.MemAllowed, 'f', -1, 64), true})
+	}
+	if limits.MultiThreaded {
+		configured = append(configured, limit{"multiThreaded", "true", true})
+	}
+	if limits.PartialResults != nil {
+		configured = append(configured, limit{"partialResults", strconv.FormatBool(*limits.PartialResults), false})
+	}
+	if len(configured) == 0 {
+		return nil
+	}
+
+	ql := &QueryLimits{Applied: map[string]string{}}
+	for _, l := range configured {
+		switch {
+		case params.Has(l.name):
+			continue
+		case l.needs96 && known && !version.AtLeast(9, 6):
+			ql.Omitted = append(ql.Omitted, l.name)
+		default:
+			params.Set(l.name, l.value)
+			ql.Applied[l.name] = l.value
+		}
+	}
+	return ql
+}
+
+// ObservePartialResults records whether the response was cut short by a limit.
+func (ql *QueryLimits) ObservePartialResults(resp map[string]any) {
+	header, _ := resp["responseHeader"].(map[string]any)
+	ql.PartialResults, _ = header["partialResults"].(bool)
+	ql.Details = header["partialResultsDetails"]
+}
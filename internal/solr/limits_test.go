@@ -0,0 +1,44 @@
+package solr
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/config"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestApplyQueryLimits tests that configured limits are added depending on the request and the Solr version.
+func TestApplyQueryLimits(t *testing.T) {
+	partial := false
+	limits := config.QueryLimitsConfig{TimeAllowed: 5000, CPUAllowed: 2000, MemAllowed: 64.5, MultiThreaded: true, PartialResults: &partial}
+
+	// Goal: Nothing is reported without configured limits.
+	assert.Nil(t, ApplyQueryLimits(url.Values{}, config.QueryLimitsConfig{}, Version{}, false))
+
+	// Goal: All limits are sent to Solr 9.6 and later, and request values take precedence.
+	params := url.Values{"timeAllowed": {"100"}}
+	ql := ApplyQueryLimits(params, limits, Version{Major: 9, Minor: 7}, true)
+	assert.Equal(t, map[string]string{"cpuAllowed": "2000", "memAllowed": "64.5", "multiThreaded": "true", "partialResults": "false"}, ql.Applied)
+	assert.Equal(t, "100", params.Get("timeAllowed"))
+	assert.Equal(t, "2000", params.Get("cpuAllowed"))
+	assert.Empty(t, ql.Omitted)
+
+	// Goal: Limits needing Solr 9.6 are left out for older versions.
+	params = url.Values{}
+	ql = ApplyQueryLimits(params, limits, Version{Major: 9, Minor: 4}, true)
+	assert.Equal(t, []string{"cpuAllowed", "memAllowed", "multiThreaded"}, ql.Omitted)
+	assert.Equal(t, "5000", params.Get("timeAllowed"))
+	assert.False(t, params.Has("cpuAllowed"))
+
+	// Goal: Limits are sent when the version is unknown.
+	params = url.Values{}
+	ApplyQueryLimits(params, limits, Version{}, false)
+	assert.Equal(t, "true", params.Get("multiThreaded"))
+
+	// Goal: Partial results are read from the response header.
+	ql.ObservePartialResults(map[string]any{"responseHeader": map[string]any{"partialResults": true, "partialResultsDetails": "Limits exceeded! (cpuAllowed)"}})
+	assert.True(t, ql.PartialResults)
+	assert.Equal(t, "Limits exceeded! (cpuAllowed)", ql.Details)
+}
@@ -0,0 +1,181 @@
+package solr
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/config"
+	"github.com/Sashimimochi/solr-mcp-go/internal/types"
+)
+
+// LTRParams adds the rq re-rank query of q to params and, with LogFeatures, the [features] transformer.
+func LTRParams(params url.Values, q types.LTRQuery) error {
+	if strings.TrimSpace(q.Model) == "" {
+		return fmt.Errorf("model is required")
+	}
+	if q.ReRankDocs < 0 {
+		return fmt.Errorf("reRankDocs must not be negative")
+	}
+	efi, err := efiParams(q.EFI)
+	if err != nil {
+		return err
+	}
+	rq := []string{"ltr", "model=" + quoteLocalParam(q.Model)}
+	if q.ReRankDocs > 0 {
+		rq = append(rq, "reRankDocs="+strconv.Itoa(q.ReRankDocs))
+	}
+	params.Set("rq", "{!"+strings.Join(append(rq, efi...), " ")+"}")
+	if !q.LogFeatures {
+		return nil
+	}
+	features := []string{"features"}
+	if q.FeatureStore != "" {
+		features = append(features, "store="+quoteLocalParam(q.FeatureStore))
+	}
+	transformer := "[" + strings.Join(append(features, efi...), " ") + "]"
+	if params.Get("fl") == "" {
+		params.Set("fl", "*,score,"+transformer)
+	} else {
+		params.Add("fl", transformer)
+	}
+	return nil
+}
+
+func efiParams(efi map[string]any) ([]string, error) {
+	keys := make([]string, 0, len(efi))
+	for k := range efi {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	out := make([]string, 0, len(keys))
+	for _, k := range keys {
+		if k == "" || strings.ContainsAny(k, " =}'\"") {
+			return nil, fmt.Errorf("invalid efi name %q", k)
+		}
+		v, err := scalarArg(efi[k])
+		if err != nil {
+			return nil, fmt.Errorf("efi.%s: %v", k, err)
+		}
+		out = append(out, "efi."+k+"="+quoteLocalParam(v))
+	}
+	return out, nil
+}
+
+// LTRStores lists the feature stores and models of a collection.
+type LTRStores struct {
+	FeatureStores []string         `json:"featureStores"`
+	Models        []map[string]any `json:"models"`
+}
+
+// ListLTR reads the feature store names and the models of collection.
+func ListLTR(ctx context.Context, httpClient *http.Client, baseURL, user, pass, collection string) (*LTRStores, error) {
+	out := &LTRStores{FeatureStores: []string{}, Models: []map[string]any{}}
+	var stores struct {
+		FeatureStores []string `json:"featureStores"`
+	}
+	if err := getJSON(ctx, httpClient, user, pass, ltrURL(baseURL, collection, "feature-store", ""), &stores, nil); err != nil {
+		return nil, fmt.Errorf("list feature stores (is the LTR module enabled?): %v", err)
+	}
+	var models struct {
+		Models []map[string]any `json:"models"`
+	}
+	if err := getJSON(ctx, httpClient, user, pass, ltrURL(baseURL, collection, "model-store", ""), &models, nil); err != nil {
+		return nil, fmt.Errorf("list models: %v", err)
+	}
+	if stores.FeatureStores != nil {
+		out.FeatureStores = stores.FeatureStores
+	}
+	if models.Models != nil {
+		out.Models = models.Models
+	}
+	return out, nil
+}
+
+// GetLTRFeatures reads the features of a feature store.
+func GetLTRFeatures(ctx context.Context, httpClient *http.Client, baseURL, user, pass, collection, store string) ([]map[string]any, error) {
+	var resp struct {
+		Features []map[string]any `json:"features"`
+	}
+	if err := getJSON(ctx, httpClient, user, pass, ltrURL(baseURL, collection, "feature-store", store), &resp, nil); err != nil {
+		return nil, fmt.Errorf("read feature store %s: %v", store, err)
+	}
+	if resp.Features == nil {
+		return []map[string]any{}, nil
+	}
+	return resp.Features, nil
+}
+
+// PutLTRFeatures uploads features. Each feature names its store, or goes to _DEFAULT_.
+func PutLTRFeatures(ctx context.Context, httpClient *http.Client, baseURL, user, pass, collection string, features []map[string]any) error {
+	return sendJSON(ctx, httpClient, http.MethodPut, user, pass, ltrURL(baseURL, collection, "feature-store", ""), features)
+}
+
+// PutLTRModel uploads a model. Its features must already exist in its store.
+func PutLTRModel(ctx context.Context, httpClient *http.Client, baseURL, user, pass, collection string, model map[string]any) error {
+	return sendJSON(ctx, httpClient, http.MethodPut, user, pass, ltrURL(baseURL, collection, "model-store", ""), model)
+}
+
+// DeleteLTR deletes a feature store (resource "feature-store") or a model (resource "model-store").
+func DeleteLTR(ctx context.Context, httpClient *http.Client, baseURL, user, pass, collection, resource, name string) error {
+	return sendJSON(ctx, httpClient, http.MethodDelete, user, pass, ltrURL(baseURL, collection, resource, name), nil)
+}
+
+func ltrURL(baseURL, collection, resource, name string) string {
+	u := fmt.Sprintf("%s/solr/%s/schema/%s", baseURL, url.PathEscape(collection), resource)
+	if name != "" {
+		u += "/" + url.PathEscape(name)
+	}
+	return u + "?wt=json"
+}
+
+// ReloadCore reloads a core of a standalone Solr with the CoreAdmin RELOAD action.
+func ReloadCore(ctx context.Context, httpClient *http.Client, baseURL, user, pass, core string) error {
+	u := fmt.Sprintf("%s/solr/admin/cores?action=RELOAD&core=%s&wt=json", baseURL, url.QueryEscape(core))
+	if err := getJSON(ctx, httpClient, user, pass, u, nil, nil); err != nil {
+		return fmt.Errorf("failed to reload core %s: %v", core, err)
+	}
+	return nil
+}
+
+// sendJSON sends body as JSON with method and fails on non-2xx responses.
+func sendJSON(ctx context.Context, httpClient *http.Client, method, user, pass, u string, body any) error {
+	slog.Info(method, "url", config.RedactURL(u))
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("marshal request: %v", err)
+		}
+		reader = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, reader)
+	if err != nil {
+		return fmt.Errorf("create request: %v", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if user != "" {
+		req.SetBasicAuth(user, pass)
+	}
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("HTTP request error: %v", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		bodyBytes, _ := io.ReadAll(res.Body)
+		return fmt.Errorf("HTTP status %d: %s", res.StatusCode, string(bodyBytes))
+	}
+	_, _ = io.Copy(io.Discard, res.Body)
+	return nil
+}
@@ -0,0 +1,35 @@
+package solr
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestLTRParams tests the rq and [features] parameters of a re-ranked query.
+func TestLTRParams(t *testing.T) {
+	// Goal: The model, the number of re-ranked documents and sorted efi values are passed as local params.
+	params := url.Values{}
+	require.NoError(t, LTRParams(params, types.LTRQuery{Model: "linear", ReRankDocs: 50, EFI: map[string]any{"user_query": "it's a laptop", "boost": float64(2)}}))
+	assert.Equal(t, `{!ltr model='linear' reRankDocs=50 efi.boost='2' efi.user_query='it\'s a laptop'}`, params.Get("rq"))
+	assert.Empty(t, params.Get("fl"))
+
+	// Goal: Feature logging adds the [features] transformer with the same efi values.
+	params = url.Values{"fl": {"id"}}
+	require.NoError(t, LTRParams(params, types.LTRQuery{Model: "linear", EFI: map[string]any{"q": "tv"}, LogFeatures: true, FeatureStore: "shop"}))
+	assert.Equal(t, `{!ltr model='linear' efi.q='tv'}`, params.Get("rq"))
+	assert.Equal(t, []string{"id", `[features store='shop' efi.q='tv']`}, params["fl"])
+
+	params = url.Values{}
+	require.NoError(t, LTRParams(params, types.LTRQuery{Model: "linear", LogFeatures: true}))
+	assert.Equal(t, "*,score,[features]", params.Get("fl"))
+
+	// Goal: Invalid input is rejected.
+	assert.ErrorContains(t, LTRParams(url.Values{}, types.LTRQuery{}), "model is required")
+	assert.ErrorContains(t, LTRParams(url.Values{}, types.LTRQuery{Model: "m", EFI: map[string]any{"a b": "x"}}), "invalid efi name")
+	assert.ErrorContains(t, LTRParams(url.Values{}, types.LTRQuery{Model: "m", EFI: map[string]any{"q": []any{"x"}}}), "efi.q")
+}
@@ -0,0 +1,116 @@
+package solr
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// IndexStats are the document counts of the shard leaders of a collection, summed.
+type IndexStats struct {
+	NumDocs      int64   `json:"numDocs"`
+	MaxDoc       int64   `json:"maxDoc"`
+	DeletedDocs  int64   `json:"deletedDocs"`
+	DeletedShare float64 `json:"deletedShare"` // deletedDocs / maxDoc
+}
+
+// GetIndexStats reads the index of every shard leader of the collection with the Luke handler. Leaders are read
+// because the merges of followers differ, and the leaders are what an expungeDeletes commit merges first.
+func GetIndexStats(ctx context.Context, httpClient *http.Client, baseURL, user, pass, collection string) (IndexStats, error) {
+	status, err := GetClusterStatus(ctx, httpClient, baseURL, user, pass, collection)
+	if err != nil {
+		return IndexStats{}, err
+	}
+	var stats IndexStats
+	for _, r := range replicaTargets(status) {
+		if !r.Leader {
+			continue
+		}
+		var resp struct {
+			Index struct {
+				NumDocs     int64 `json:"numDocs"`
+				MaxDoc      int64 `json:"maxDoc"`
+				DeletedDocs int64 `json:"deletedDocs"`
+			} `json:"index"`
+		}
+		u := replicaCoreURL(status, &r) + "/admin/luke?numTerms=0&show=index&wt=json"
+		if err := getJSON(ctx, httpClient, user, pass, u, &resp, nil); err != nil {
+			return IndexStats{}, fmt.Errorf("index stats of %s/%s: %v", collection, r.Shard, err)
+		}
+		stats.NumDocs += resp.Index.NumDocs
+		stats.MaxDoc += resp.Index.MaxDoc
+		stats.DeletedDocs += resp.Index.DeletedDocs
+	}
+	if stats.MaxDoc > 0 {
+		stats.DeletedShare = float64(stats.DeletedDocs) / float64(stats.MaxDoc)
+	}
+	return stats, nil
+}
+
+// ExpungeDeletes commits the collection with expungeDeletes, merging the segments with many deleted documents.
+func ExpungeDeletes(ctx context.Context, httpClient *http.Client, baseURL, user, pass, collection string) error {
+	return postUpdate(ctx, httpClient, baseURL, user, pass, collection, map[string]any{"commit": map[string]any{"expungeDeletes": true}}, false)
+}
+
+// Optimize merges the index of the collection down to maxSegments segments. It rewrites the whole index, so it
+// should only run when the collection is idle.
+func Optimize(ctx context.Context, httpClient *http.Client, baseURL, user, pass, collection string, maxSegments int) error {
+	if maxSegments <= 0 {
+		maxSegments = 1
+	}
+	return postUpdate(ctx, httpClient, baseURL, user, pass, collection, map[string]any{"optimize": map[string]any{"maxSegments": maxSegments}}, false)
+}
+
+// asyncPollInterval is how often the status of an async Collections API request is read.
+var asyncPollInterval = 5 * time.Second
+
+// BackupCollection backs up the collection with the Collections API BACKUP action under its own name at
+// location. The request runs asynchronously in Solr and is polled until it finishes or ctx is done. keep limits
+// the backup points of incremental backups, when positive.
+func BackupCollection(ctx context.Context, httpClient *http.Client, baseURL, user, pass, collection, location, repository string, keep int) error {
+	requestID := fmt.Sprintf("solr-mcp-backup-%s-%d", collection, time.Now().UnixNano())
+	q := url.Values{"action": {"BACKUP"}, "name": {collection}, "collection": {collection}, "location": {location}, "async": {requestID}, "wt": {"json"}}
+	if repository != "" {
+		q.Set("repository", repository)
+	}
+	if keep > 0 {
+		q.Set("maxNumBackupPoints", strconv.Itoa(keep))
+	}
+	if err := getJSON(ctx, httpClient, user, pass, baseURL+"/solr/admin/collections?"+q.Encode(), nil, nil); err != nil {
+		return fmt.Errorf("backup of %s: %v", collection, err)
+	}
+	defer func() {
+		// The request status is kept by Solr until it is deleted
+		cleanup, cancel := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Second)
+		defer cancel()
+		q := url.Values{"action": {"DELETESTATUS"}, "requestid": {requestID}, "wt": {"json"}}
+		_ = getJSON(cleanup, httpClient, user, pass, baseURL+"/solr/admin/collections?"+q.Encode(), nil, nil)
+	}()
+
+	status := url.Values{"action": {"REQUESTSTATUS"}, "requestid": {requestID}, "wt": {"json"}}
+	for {
+		var resp struct {
+			Status struct {
+				State string `json:"state"`
+				Msg   string `json:"msg"`
+			} `json:"status"`
+		}
+		if err := getJSON(ctx, httpClient, user, pass, baseURL+"/solr/admin/collections?"+status.Encode(), &resp, nil); err != nil {
+			return fmt.Errorf("backup of %s: status: %v", collection, err)
+		}
+		switch resp.Status.State {
+		case "completed":
+			return nil
+		case "failed", "notfound":
+			return fmt.Errorf("backup of %s %s: %s", collection, resp.Status.State, resp.Status.Msg)
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("backup of %s: %v", collection, ctx.Err())
+		case <-time.After(asyncPollInterval):
+		}
+	}
+}
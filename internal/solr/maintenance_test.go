@@ -0,0 +1,89 @@
+package solr
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMaintenance tests reading the index stats of a collection, merging it and backing it up.
+func TestMaintenance(t *testing.T) {
+	asyncPollInterval = time.Millisecond
+	defer func() { asyncPollInterval = 5 * time.Second }()
+	var updates []map[string]any
+	var polls atomic.Int32
+	var deleted string
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		switch r.URL.Path {
+		case "/solr/admin/collections":
+			switch q.Get("action") {
+			case "CLUSTERSTATUS":
+				base := server.URL + "/solr"
+				fmt.Fprintf(w, `{"cluster":{"collections":{"logs":{"shards":{
+					"shard1":{"replicas":{"core_node1":{"core":"logs_shard1_replica_n1","base_url":%q,"state":"active","leader":"true"},
+					                      "core_node2":{"core":"logs_shard1_replica_n2","base_url":%q,"state":"active"}}},
+					"shard2":{"replicas":{"core_node3":{"core":"logs_shard2_replica_n3","base_url":%q,"state":"active","leader":"true"}}}}}}}}`,
+					base, base, base)
+			case "BACKUP":
+				assert.Equal(t, "logs", q.Get("name"))
+				assert.Equal(t, "/backups", q.Get("location"))
+				assert.Equal(t, "s3", q.Get("repository"))
+				assert.Equal(t, "7", q.Get("maxNumBackupPoints"))
+				assert.NotEmpty(t, q.Get("async"))
+				fmt.Fprint(w, `{}`)
+			case "REQUESTSTATUS":
+				state := "running"
+				if polls.Add(1) > 1 {
+					state = "completed"
+				}
+				fmt.Fprintf(w, `{"status":{"state":%q}}`, state)
+			case "DELETESTATUS":
+				deleted = q.Get("requestid")
+				fmt.Fprint(w, `{}`)
+			}
+		case "/solr/logs_shard1_replica_n1/admin/luke":
+			fmt.Fprint(w, `{"index":{"numDocs":70,"maxDoc":100,"deletedDocs":30}}`)
+		case "/solr/logs_shard2_replica_n3/admin/luke":
+			fmt.Fprint(w, `{"index":{"numDocs":90,"maxDoc":100,"deletedDocs":10}}`)
+		case "/solr/logs/update":
+			var body map[string]any
+			data, _ := io.ReadAll(r.Body)
+			require.NoError(t, json.Unmarshal(data, &body))
+			updates = append(updates, body)
+			fmt.Fprint(w, `{}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	ctx := context.Background()
+
+	// Goal: The deleted documents of the shard leaders are summed, followers are not read.
+	stats, err := GetIndexStats(ctx, server.Client(), server.URL, "", "", "logs")
+	require.NoError(t, err)
+	assert.Equal(t, IndexStats{NumDocs: 160, MaxDoc: 200, DeletedDocs: 40, DeletedShare: 0.2}, stats)
+
+	// Goal: Merges are sent as JSON update commands.
+	require.NoError(t, ExpungeDeletes(ctx, server.Client(), server.URL, "", "", "logs"))
+	require.NoError(t, Optimize(ctx, server.Client(), server.URL, "", "", "logs", 0))
+	assert.Equal(t, []map[string]any{
+		{"commit": map[string]any{"expungeDeletes": true}},
+		{"optimize": map[string]any{"maxSegments": float64(1)}},
+	}, updates)
+
+	// Goal: A backup is polled until it completes, then its request status is deleted.
+	require.NoError(t, BackupCollection(ctx, server.Client(), server.URL, "", "", "logs", "/backups", "s3", 7))
+	assert.Equal(t, int32(2), polls.Load())
+	assert.Contains(t, deleted, "solr-mcp-backup-logs-")
+}
@@ -0,0 +1,143 @@
+package solr
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// MatchedOnParams asks Solr for a structured per-document score explanation. It returns false when
+// the request already asked for debug output, which is then left in the response.
+func MatchedOnParams(params url.Values) (added bool) {
+	if params.Get("debugQuery") == "true" || params.Has("debug") {
+		params.Set("debug.explain.structured", "true")
+		return false
+	}
+	params.Set("debug", "results")
+	params.Set("debug.explain.structured", "true")
+	return true
+}
+
+var (
+	// weightRe matches the description of a scored term or phrase, e.g. "weight(title:solr in 12) [SchemaSimilarity], result of:".
+	weightRe = regexp.MustCompile(`^weight\((.+) in \d+\)`)
+	// constantScoreRe matches unscored clauses, e.g. "ConstantScore(inStock:true)".
+	constantScoreRe = regexp.MustCompile(`^ConstantScore\((.+)\)`)
+	emRe            = regexp.MustCompile(`<em>(.*?)</em>`)
+)
+
+// AnnotateMatches adds a matchedOn array of "field:term" clauses to every document of resp, ordered
+// by score contribution. It reads the structured explanation of each document (see MatchedOnParams)
+// and falls back to the highlighting section. Documents are looked up by their uniqueKey value.
+// It returns the number of annotated documents.
+func AnnotateMatches(resp map[string]any, uniqueKey string) int {
+	respObj, _ := resp["response"].(map[string]any)
+	docs, _ := respObj["docs"].([]any)
+	debug, _ := resp["debug"].(map[string]any)
+	explain, _ := debug["explain"].(map[string]any)
+	highlighting, _ := resp["highlighting"].(map[string]any)
+
+	annotated := 0
+	for _, d := range docs {
+		doc, ok := d.(map[string]any)
+		if !ok || doc[uniqueKey] == nil {
+			continue
+		}
+		id := fmt.Sprintf("%v", doc[uniqueKey])
+		var matched []string
+		if node, ok := explain[id].(map[string]any); ok {
+			matched = explainMatches(node)
+		}
+		if len(matched) == 0 {
+			if hl, ok := highlighting[id].(map[string]any); ok {
+				matched = highlightMatches(hl)
+			}
+		}
+		if len(matched) > 0 {
+			doc["matchedOn"] = matched
+			annotated++
+		}
+	}
+	return annotated
+}
+
+// explainMatches collects the matching clauses of a structured explanation.
+func explainMatches(node map[string]any) []string {
+	contribution := map[string]float64{}
+	var walk func(n map[string]any)
+	walk = func(n map[string]any) {
+		if match, ok := n["match"].(bool); ok && !match {
+			return
+		}
+		desc, _ := n["description"].(string)
+		value, _ := n["value"].(float64)
+		if m := weightRe.FindStringSubmatch(desc); m != nil {
+			for _, clause := range splitClause(m[1]) {
+				contribution[clause] += value
+			}
+			return
+		}
+		if m := constantScoreRe.FindStringSubmatch(desc); m != nil {
+			for _, clause := range splitClause(m[1]) {
+				contribution[clause] += value
+			}
+			return
+		}
+		details, _ := n["details"].([]any)
+		for _, c := range details {
+			if child, ok := c.(map[string]any); ok {
+				walk(child)
+			}
+		}
+	}
+	walk(node)
+	return byContribution(contribution)
+}
+
+// splitClause splits a Lucene query description into field:term clauses, dropping match-all clauses.
+// Synonym(title:tv title:television) becomes two clauses.
+func splitClause(s string) []string {
+	if inner, ok := strings.CutPrefix(s, "Synonym("); ok {
+		var out []string
+		for _, part := range strings.Fields(strings.TrimSuffix(inner, ")")) {
+			out = append(out, splitClause(part)...)
+		}
+		return out
+	}
+	field, _, ok := strings.Cut(s, ":")
+	if !ok || field == "*" || strings.ContainsAny(field, " ()") {
+		return nil
+	}
+	return []string{s}
+}
+
+// highlightMatches returns field:term clauses from the <em> tags of a document's highlighting.
+func highlightMatches(hl map[string]any) []string {
+	contribution := map[string]float64{}
+	for field, snippets := range hl {
+		list, _ := snippets.([]any)
+		for _, s := range list {
+			text, _ := s.(string)
+			for _, m := range emRe.FindAllStringSubmatch(text, -1) {
+				contribution[field+":"+strings.ToLower(m[1])]++
+			}
+		}
+	}
+	return byContribution(contribution)
+}
+
+func byContribution(contribution map[string]float64) []string {
+	out := make([]string, 0, len(contribution))
+	for clause := range contribution {
+		out = append(out, clause)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if contribution[out[i]] != contribution[out[j]] {
+			return contribution[out[i]] > contribution[out[j]]
+		}
+		return out[i] < out[j]
+	})
+	return out
+}
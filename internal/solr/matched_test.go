@@ -0,0 +1,50 @@
+package solr
+
+import (
+	"encoding/json"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestAnnotateMatches tests matchedOn annotations from structured explanations and highlighting.
+func TestAnnotateMatches(t *testing.T) {
+	var resp map[string]any
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"response": {"docs": [{"id": "1"}, {"id": 2}, {"id": "3"}, {"title": "no id"}]},
+		"debug": {"explain": {
+			"1": {"match": true, "value": 3.5, "description": "sum of:", "details": [
+				{"match": true, "value": 2.5, "description": "weight(title:solr in 0) [SchemaSimilarity], result of:", "details": []},
+				{"match": true, "value": 1.0, "description": "weight(Synonym(body:tv body:television) in 0) [SchemaSimilarity], result of:"},
+				{"match": false, "value": 0.0, "description": "no match on required clause (tags:search)"}
+			]},
+			"2": {"match": true, "value": 1.0, "description": "ConstantScore(*:*)"}
+		}},
+		"highlighting": {"2": {"body": ["a <em>Fast</em> engine, <em>fast</em> indeed"]}, "3": {}}
+	}`), &resp))
+
+	// Goal: Matching clauses are listed by score contribution; match-all and unmatched clauses are left out.
+	assert.Equal(t, 2, AnnotateMatches(resp, "id"))
+	docs := resp["response"].(map[string]any)["docs"].([]any)
+	assert.Equal(t, []string{"title:solr", "body:television", "body:tv"}, docs[0].(map[string]any)["matchedOn"])
+
+	// Goal: Highlighting is used when the explanation names no clauses.
+	assert.Equal(t, []string{"body:fast"}, docs[1].(map[string]any)["matchedOn"])
+	assert.NotContains(t, docs[2].(map[string]any), "matchedOn")
+}
+
+// TestMatchedOnParams tests the debug parameters requested for matchedOn.
+func TestMatchedOnParams(t *testing.T) {
+	// Goal: Only the results explanation is requested and must be removed afterwards.
+	params := url.Values{"q": {"title:solr"}}
+	assert.True(t, MatchedOnParams(params))
+	assert.Equal(t, "results", params.Get("debug"))
+	assert.Equal(t, "true", params.Get("debug.explain.structured"))
+
+	// Goal: Debug output requested by the caller is kept.
+	params = url.Values{"debugQuery": {"true"}}
+	assert.False(t, MatchedOnParams(params))
+	assert.False(t, params.Has("debug"))
+}
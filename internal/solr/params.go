@@ -0,0 +1,67 @@
+package solr
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+)
+
+// ParamSet is a named bundle of request parameters stored in Solr with the Request Parameters API.
+// Queries apply it with useParams=name.
+type ParamSet struct {
+	Name    string         `json:"name"`
+	Params  map[string]any `json:"params"`
+	Version int            `json:"version"`
+}
+
+// ListParamSets reads the parameter sets of collection, sorted by name.
+func ListParamSets(ctx context.Context, httpClient *http.Client, baseURL, user, pass, collection string) ([]ParamSet, error) {
+	var resp struct {
+		Response struct {
+			Params map[string]map[string]any `json:"params"`
+		} `json:"response"`
+	}
+	if err := getJSON(ctx, httpClient, user, pass, paramsURL(baseURL, collection), &resp, nil); err != nil {
+		return nil, fmt.Errorf("list parameter sets: %v", err)
+	}
+	sets := make([]ParamSet, 0, len(resp.Response.Params))
+	for name, params := range resp.Response.Params {
+		ps := ParamSet{Name: name, Params: map[string]any{}}
+		for k, v := range params {
+			// the empty key holds the metadata of the set, e.g. {"v": 2}
+			if k == "" {
+				if meta, ok := v.(map[string]any); ok {
+					if version, ok := meta["v"].(float64); ok {
+						ps.Version = int(version)
+					}
+				}
+				continue
+			}
+			ps.Params[k] = v
+		}
+		sets = append(sets, ps)
+	}
+	sort.Slice(sets, func(i, j int) bool { return sets[i].Name < sets[j].Name })
+	return sets, nil
+}
+
+// SetParamSet creates or replaces a parameter set. With merge, params are added to an existing set instead,
+// keeping its other parameters.
+func SetParamSet(ctx context.Context, httpClient *http.Client, baseURL, user, pass, collection, name string, params map[string]any, merge bool) error {
+	op := "set"
+	if merge {
+		op = "update"
+	}
+	return sendJSON(ctx, httpClient, http.MethodPost, user, pass, paramsURL(baseURL, collection), map[string]any{op: map[string]any{name: params}})
+}
+
+// DeleteParamSet deletes a parameter set.
+func DeleteParamSet(ctx context.Context, httpClient *http.Client, baseURL, user, pass, collection, name string) error {
+	return sendJSON(ctx, httpClient, http.MethodPost, user, pass, paramsURL(baseURL, collection), map[string]any{"delete": name})
+}
+
+func paramsURL(baseURL, collection string) string {
+	return fmt.Sprintf("%s/solr/%s/config/params?wt=json", baseURL, url.PathEscape(collection))
+}
@@ -0,0 +1,252 @@
+package solr
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// StringList is a Solr security.json value that may be a single string or a list of strings.
+type StringList []string
+
+func (l *StringList) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*l = nil
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*l = StringList{s}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return fmt.Errorf("expected a string or a list of strings: %s", data)
+	}
+	*l = list
+	return nil
+}
+
+// Permission is a rule of the rule-based authorization plugin. Rules are matched in order of Index; the first
+// matching rule decides.
+type Permission struct {
+	Name  string `json:"name,omitempty"`
+	Index int    `json:"index"`
+	// Roles may run requests matching the rule; nil means anyone, even unauthenticated, and "*" any user.
+	Roles  StringList `json:"role"`
+	Path   StringList `json:"path,omitempty"`
+	Method StringList `json:"method,omitempty"`
+	// Collections limits the rule to some collections. A custom rule with an explicit null collection is
+	// AdminOnly: it only applies to admin requests, which have no collection.
+	Collections StringList `json:"collection,omitempty"`
+	AdminOnly   bool       `json:"adminOnly,omitempty"`
+}
+
+func (p *Permission) UnmarshalJSON(data []byte) error {
+	type plain Permission
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, (*plain)(p)); err != nil {
+		return err
+	}
+	c, ok := raw["collection"]
+	p.AdminOnly = ok && string(c) == "null"
+	return nil
+}
+
+// Authorization is the configuration of the authorization plugin of a cluster.
+type Authorization struct {
+	Enabled     bool                  `json:"enabled"`
+	Class       string                `json:"class,omitempty"`
+	Permissions []Permission          `json:"permissions,omitempty"`
+	UserRoles   map[string]StringList `json:"userRoles,omitempty"`
+}
+
+// GetAuthorization reads the authorization configuration from the Authorization API, which needs the
+// security-read permission. Clusters without an authorization plugin return a disabled configuration.
+func GetAuthorization(ctx context.Context, httpClient *http.Client, baseURL, user, pass string) (Authorization, error) {
+	var resp struct {
+		Enabled       *bool    `json:"authorization.enabled"`
+		ErrorMessages []string `json:"errorMessages"`
+		Authorization struct {
+			Class       string                `json:"class"`
+			Permissions []Permission          `json:"permissions"`
+			UserRole    map[string]StringList `json:"user-role"`
+		} `json:"authorization"`
+	}
+	err := getJSON(ctx, httpClient, user, pass, baseURL+"/solr/admin/authorization?wt=json", &resp, nil)
+	if err != nil {
+		if strings.Contains(err.Error(), "HTTP status 404") {
+			return Authorization{}, nil
+		}
+		return Authorization{}, fmt.Errorf("authorization request: %v", err)
+	}
+	a := Authorization{
+		Enabled:     resp.Authorization.Class != "" && (resp.Enabled == nil || *resp.Enabled),
+		Class:       resp.Authorization.Class,
+		Permissions: resp.Authorization.Permissions,
+		UserRoles:   resp.Authorization.UserRole,
+	}
+	for i := range a.Permissions {
+		if a.Permissions[i].Index == 0 {
+			a.Permissions[i].Index = i + 1
+		}
+	}
+	sort.SliceStable(a.Permissions, func(i, j int) bool { return a.Permissions[i].Index < a.Permissions[j].Index })
+	return a, nil
+}
+
+// SecurityInfo is what a node reports about the security of the current request in its system info: the
+// plugins, the user and, with the rule-based plugin, the user's roles and the names of the rules granting them.
+type SecurityInfo struct {
+	Authentication string   `json:"authentication,omitempty"`
+	Authorization  string   `json:"authorization,omitempty"`
+	User           string   `json:"user,omitempty"`
+	Roles          []string `json:"roles,omitempty"`
+	Permissions    []string `json:"permissions,omitempty"`
+}
+
+// GetSecurityInfo reads the security section of the system info handler, which any user may read.
+func GetSecurityInfo(ctx context.Context, httpClient *http.Client, baseURL, user, pass string) (SecurityInfo, error) {
+	var info struct {
+		Security struct {
+			AuthenticationPlugin string     `json:"authenticationPlugin"`
+			AuthorizationPlugin  string     `json:"authorizationPlugin"`
+			Username             string     `json:"username"`
+			Roles                StringList `json:"roles"`
+			Permissions          StringList `json:"permissions"`
+		} `json:"security"`
+	}
+	if err := getJSON(ctx, httpClient, user, pass, baseURL+"/solr/admin/info/system?wt=json", &info, nil); err != nil {
+		return SecurityInfo{}, fmt.Errorf("system info request: %v", err)
+	}
+	s := info.Security
+	return SecurityInfo{
+		Authentication: s.AuthenticationPlugin,
+		Authorization:  s.AuthorizationPlugin,
+		User:           s.Username,
+		Roles:          s.Roles,
+		Permissions:    s.Permissions,
+	}, nil
+}
+
+// Action is a kind of request checked against the rules: the predefined permission covering it and a
+// representative path and method for custom rules.
+type Action struct {
+	Name   string
+	Path   string
+	Method string
+	// Admin actions are not made on a collection.
+	Admin bool
+}
+
+// Actions are the predefined permissions the tools of this server need.
+var Actions = []Action{
+	{Name: "read", Path: "/select", Method: http.MethodGet},
+	{Name: "update", Path: "/update", Method: http.MethodPost},
+	{Name: "schema-read", Path: "/schema", Method: http.MethodGet},
+	{Name: "schema-edit", Path: "/schema", Method: http.MethodPost},
+	{Name: "config-read", Path: "/config", Method: http.MethodGet},
+	{Name: "config-edit", Path: "/config", Method: http.MethodPost},
+	{Name: "collection-admin-read", Path: "/admin/collections", Method: http.MethodGet, Admin: true},
+	{Name: "collection-admin-edit", Path: "/admin/collections", Method: http.MethodPost, Admin: true},
+	{Name: "core-admin-read", Path: "/admin/cores", Method: http.MethodGet, Admin: true},
+	{Name: "core-admin-edit", Path: "/admin/cores", Method: http.MethodPost, Admin: true},
+	{Name: "metrics-read", Path: "/admin/metrics", Method: http.MethodGet, Admin: true},
+	{Name: "security-read", Path: "/admin/authorization", Method: http.MethodGet, Admin: true},
+	{Name: "security-edit", Path: "/admin/authorization", Method: http.MethodPost, Admin: true},
+}
+
+// predefined are the permission names with a built-in meaning; other names are custom rules matched by path.
+var predefined = map[string]bool{
+	"all": true, "read": true, "update": true, "schema-read": true, "schema-edit": true, "config-read": true,
+	"config-edit": true, "collection-admin-read": true, "collection-admin-edit": true, "core-admin-read": true,
+	"core-admin-edit": true, "metrics-read": true, "security-read": true, "security-edit": true, "health": true,
+	"zk-read": true, "filestore-read": true, "filestore-write": true, "package-read": true, "package-edit": true,
+}
+
+// Grant is whether a user may run an action, and the rule deciding it.
+type Grant struct {
+	Action  string     `json:"action"`
+	Allowed bool       `json:"allowed"`
+	Rule    string     `json:"rule,omitempty"`
+	Index   int        `json:"ruleIndex,omitempty"`
+	Roles   StringList `json:"roles,omitempty"`
+	Reason  string     `json:"reason"`
+}
+
+// Check decides action on collection for a user with roles, the way the rule-based plugin does. Without a
+// collection, rules limited to named collections are skipped.
+func (a Authorization) Check(action Action, collection, user string, roles []string) Grant {
+	g := Grant{Action: action.Name}
+	if !a.Enabled {
+		g.Allowed, g.Reason = true, "Solr has no authorization plugin"
+		return g
+	}
+	for _, p := range a.Permissions {
+		if !p.matches(action, collection) {
+			continue
+		}
+		g.Rule, g.Index, g.Roles = p.label(), p.Index, p.Roles
+		switch {
+		case p.Roles == nil:
+			g.Allowed, g.Reason = true, fmt.Sprintf("rule %s allows anyone", g.Rule)
+		case slices.Contains(p.Roles, "*") && user != "":
+			g.Allowed, g.Reason = true, fmt.Sprintf("rule %s allows any authenticated user", g.Rule)
+		case slices.ContainsFunc(roles, func(r string) bool { return slices.Contains(p.Roles, r) }):
+			g.Allowed, g.Reason = true, fmt.Sprintf("rule %s allows the roles %s", g.Rule, strings.Join(p.Roles, ", "))
+		case user == "":
+			g.Reason = fmt.Sprintf("rule %s needs an authenticated user with one of the roles %s", g.Rule, strings.Join(p.Roles, ", "))
+		default:
+			g.Reason = fmt.Sprintf("rule %s needs one of the roles %s, and %s has %s", g.Rule, strings.Join(p.Roles, ", "), user, describeRoles(roles))
+		}
+		return g
+	}
+	g.Allowed, g.Reason = true, "no rule matches, and Solr allows requests without a matching rule"
+	return g
+}
+
+func describeRoles(roles []string) string {
+	if len(roles) == 0 {
+		return "no roles"
+	}
+	return strings.Join(roles, ", ")
+}
+
+func (p Permission) label() string {
+	if p.Name != "" {
+		return p.Name
+	}
+	return fmt.Sprintf("#%d", p.Index)
+}
+
+func (p Permission) matches(action Action, collection string) bool {
+	switch {
+	case p.Name == "all":
+		return true
+	case predefined[p.Name]:
+		return p.Name == action.Name && (action.Admin || p.inCollection(collection))
+	case p.AdminOnly != action.Admin:
+		return false
+	case !action.Admin && !p.inCollection(collection):
+		return false
+	case len(p.Method) > 0 && !slices.Contains(p.Method, action.Method):
+		return false
+	}
+	return len(p.Path) == 0 || slices.ContainsFunc(p.Path, func(pattern string) bool {
+		ok, _ := path.Match(pattern, action.Path)
+		return ok || pattern == "*" || pattern == "/*"
+	})
+}
+
+// inCollection reports whether the rule applies to requests on collection; rules without collections apply to all.
+func (p Permission) inCollection(collection string) bool {
+	return len(p.Collections) == 0 || slices.Contains(p.Collections, "*") || slices.Contains(p.Collections, collection)
+}
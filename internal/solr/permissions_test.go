@@ -0,0 +1,83 @@
+package solr
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const securityJSON = `{
+  "responseHeader": {"status": 0},
+  "authorization.enabled": true,
+  "authorization": {
+    "class": "solr.RuleBasedAuthorizationPlugin",
+    "user-role": {"solr": "admin", "indexer": ["reader", "writer"], "agent": ["reader"]},
+    "permissions": [
+      {"name": "security-edit", "role": "admin", "index": 1},
+      {"name": "health", "role": null, "index": 2},
+      {"name": "update", "collection": "products", "role": "writer", "index": 3},
+      {"name": "update", "role": "admin", "index": 4},
+      {"name": "read", "role": "*", "index": 5},
+      {"name": "custom-export", "path": "/export", "role": "reader", "index": 6},
+      {"name": "nodes", "collection": null, "path": "/admin/collections", "method": "GET", "role": "ops", "index": 7},
+      {"name": "all", "role": "admin", "index": 8}
+    ]
+  }
+}`
+
+// TestCheck tests judging actions with the rules of the rule-based authorization plugin.
+func TestCheck(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, securityJSON)
+	}))
+	defer server.Close()
+	auth, err := GetAuthorization(context.Background(), &http.Client{}, server.URL, "", "")
+	require.NoError(t, err)
+	require.True(t, auth.Enabled)
+	require.Len(t, auth.Permissions, 8)
+	assert.Nil(t, auth.Permissions[1].Roles)
+	assert.True(t, auth.Permissions[6].AdminOnly)
+	assert.Equal(t, StringList{"reader", "writer"}, auth.UserRoles["indexer"])
+	action := func(name string) Action {
+		for _, a := range Actions {
+			if a.Name == name {
+				return a
+			}
+		}
+		t.Fatalf("unknown action %s", name)
+		return Action{}
+	}
+
+	// Goal: The first matching rule decides, including rules limited to a collection.
+	g := auth.Check(action("update"), "products", "indexer", []string{"reader", "writer"})
+	assert.True(t, g.Allowed)
+	assert.Equal(t, 3, g.Index)
+	g = auth.Check(action("update"), "orders", "indexer", []string{"reader", "writer"})
+	assert.False(t, g.Allowed)
+	assert.Equal(t, "update", g.Rule)
+	assert.Equal(t, 4, g.Index)
+	assert.Equal(t, "rule update needs one of the roles admin, and indexer has reader, writer", g.Reason)
+
+	// Goal: Without a collection, collection-limited rules are skipped.
+	assert.Equal(t, 4, auth.Check(action("update"), "", "indexer", []string{"writer"}).Index)
+
+	// Goal: "*" allows authenticated users only.
+	assert.True(t, auth.Check(action("read"), "products", "agent", []string{"reader"}).Allowed)
+	assert.False(t, auth.Check(action("read"), "products", "", nil).Allowed)
+
+	// Goal: Admin-only custom rules match admin requests, and "all" catches the rest.
+	g = auth.Check(action("collection-admin-read"), "", "agent", []string{"reader"})
+	assert.Equal(t, "nodes", g.Rule)
+	assert.False(t, g.Allowed)
+	g = auth.Check(action("schema-edit"), "products", "solr", []string{"admin"})
+	assert.Equal(t, "all", g.Rule)
+	assert.True(t, g.Allowed)
+
+	// Goal: Without an authorization plugin, everything is allowed.
+	assert.True(t, Authorization{}.Check(action("security-edit"), "", "", nil).Allowed)
+}
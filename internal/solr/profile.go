@@ -0,0 +1,268 @@
+package solr
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/backend"
+	"github.com/Sashimimochi/solr-mcp-go/internal/types"
+)
+
+// FieldProfile describes how a field is filled across the profiled documents.
+type FieldProfile struct {
+	Name     string   `json:"name"`
+	Type     string   `json:"type"`
+	Present  int64    `json:"present"`            // documents with a value
+	FillRate float64  `json:"fillRate"`           // present / numDocs
+	Distinct *int64   `json:"distinct,omitempty"` // approximate distinct values (HyperLogLog), not computed for text fields
+	Min      any      `json:"min,omitempty"`      // numeric and date fields
+	Max      any      `json:"max,omitempty"`
+	Flags    []string `json:"flags,omitempty"`
+	Error    string   `json:"error,omitempty"`
+}
+
+// CollectionProfile is the data quality profile of the documents matching a query.
+type CollectionProfile struct {
+	Query   string         `json:"query"`
+	NumDocs int64          `json:"numDocs"`
+	Fields  []FieldProfile `json:"fields"`
+	// Findings lists the flagged fields in plain language.
+	Findings []string `json:"findings"`
+	// SkippedFields were not profiled because of the field limit.
+	SkippedFields []string `json:"skippedFields,omitempty"`
+}
+
+// Profile flags.
+const (
+	FlagEmpty      = "empty"       // no document has a value
+	FlagSparse     = "sparse"      // fewer than SparseFillRate of the documents have a value
+	FlagConstant   = "constant"    // every document with a value has the same value
+	FlagFutureDate = "future_date" // the latest date is in the future
+)
+
+// SparseFillRate is the fill rate below which a field is flagged as sparse.
+const SparseFillRate = 0.05
+
+var (
+	numericTypeRe = regexp.MustCompile(`^[pt]?(int|long|float|double)s?$`)
+	dateTypeRe    = regexp.MustCompile(`^[pt]?dates?$`)
+	keywordTypeRe = regexp.MustCompile(`^(strings?|booleans?)$`)
+)
+
+// internalFields are maintained by Solr and are not profiled.
+var internalFields = map[string]bool{"_version_": true, "_root_": true, "_nest_path_": true, "_nest_parent_": true}
+
+// ProfileFields returns the fields of fc worth profiling, in schema order.
+func ProfileFields(fc *types.FieldCatalog) []types.SolrField {
+	var out []types.SolrField
+	for _, f := range fc.All {
+		if !internalFields[f.Name] {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// ProfileCollection counts the documents with a value for each field with facet queries, and the
+// approximate distinct values and min/max of non-text fields with the stats component.
+// Failures of single fields are reported in their Error instead of failing the profile.
+func ProfileCollection(ctx context.Context, b backend.Querier, collection, query string, fields []types.SolrField, now time.Time) (*CollectionProfile, error) {
+	if query == "" {
+		query = "*:*"
+	}
+	p := &CollectionProfile{Query: query, Fields: make([]FieldProfile, len(fields)), Findings: []string{}}
+	for i, f := range fields {
+		p.Fields[i] = FieldProfile{Name: f.Name, Type: f.Type}
+	}
+
+	all := make([]int, len(fields))
+	for i := range fields {
+		all[i] = i
+	}
+	resp, err := b.Query(ctx, collection, fillRateParams(query, fields, all))
+	if err != nil {
+		// one field that cannot be range-queried fails the whole request, so fall back to one request per field
+		for i := range fields {
+			resp, err := b.Query(ctx, collection, fillRateParams(query, fields, []int{i}))
+			if err != nil {
+				p.Fields[i].Error = err.Error()
+				continue
+			}
+			p.NumDocs = numFound(resp)
+			readFillRates(resp, p, []int{i})
+		}
+	} else {
+		p.NumDocs = numFound(resp)
+		readFillRates(resp, p, all)
+	}
+
+	var statsFields []int
+	for i, f := range fields {
+		if p.Fields[i].Error == "" && p.Fields[i].Present > 0 && fieldKind(f.Type) != "text" {
+			statsFields = append(statsFields, i)
+		}
+	}
+	if len(statsFields) > 0 {
+		resp, err := b.Query(ctx, collection, statsParams(query, fields, statsFields))
+		if err != nil {
+			for _, i := range statsFields {
+				resp, err := b.Query(ctx, collection, statsParams(query, fields, []int{i}))
+				if err != nil {
+					p.Fields[i].Error = err.Error()
+					continue
+				}
+				readStats(resp, p, fields)
+			}
+		} else {
+			readStats(resp, p, fields)
+		}
+	}
+
+	for i := range p.Fields {
+		flagField(&p.Fields[i], p.NumDocs, now)
+		for _, flag := range p.Fields[i].Flags {
+			p.Findings = append(p.Findings, finding(p.Fields[i], flag))
+		}
+	}
+	return p, nil
+}
+
+// FetchFieldStats computes the number of documents with a value, the approximate distinct values and, for
+// numeric and date fields, the min/max of one field over the whole collection with the stats component.
+// Text fields have no statistics.
+func FetchFieldStats(ctx context.Context, b backend.Querier, collection string, f types.SolrField, now time.Time) (types.FieldStats, error) {
+	if fieldKind(f.Type) == "text" {
+		return types.FieldStats{}, fmt.Errorf("field %s: no statistics for text fields", f.Name)
+	}
+	local := "count=true cardinality=true"
+	if kind := fieldKind(f.Type); kind == "numeric" || kind == "date" {
+		local += " min=true max=true"
+	}
+	params := url.Values{"q": {"*:*"}, "rows": {"0"}, "stats": {"true"}, "wt": {"json"}}
+	params.Set("stats.field", fmt.Sprintf("{!%s}%s", local, f.Name))
+	resp, err := b.Query(ctx, collection, params)
+	if err != nil {
+		return types.FieldStats{}, err
+	}
+	stats, _ := resp["stats"].(map[string]any)
+	statsFields, _ := stats["stats_fields"].(map[string]any)
+	s, ok := statsFields[f.Name].(map[string]any)
+	if !ok {
+		return types.FieldStats{}, fmt.Errorf("field %s: no statistics in the response", f.Name)
+	}
+	fs := types.FieldStats{Field: f.Name, Type: f.Type, Min: s["min"], Max: s["max"], Fetched: now}
+	if n, ok := s["count"].(float64); ok {
+		fs.Count = int64(n)
+	}
+	if n, ok := s["cardinality"].(float64); ok {
+		fs.Cardinality = int64(n)
+	}
+	return fs, nil
+}
+
+// fieldKind classifies a field type name as "numeric", "date", "keyword" or "text".
+func fieldKind(typeName string) string {
+	switch {
+	case numericTypeRe.MatchString(typeName):
+		return "numeric"
+	case dateTypeRe.MatchString(typeName):
+		return "date"
+	case keywordTypeRe.MatchString(typeName):
+		return "keyword"
+	}
+	return "text"
+}
+
+func fillRateParams(query string, fields []types.SolrField, idx []int) url.Values {
+	params := url.Values{"q": {query}, "rows": {"0"}, "facet": {"true"}, "wt": {"json"}}
+	for _, i := range idx {
+		params.Add("facet.query", fmt.Sprintf("{!key=f%d}%s:[* TO *]", i, fields[i].Name))
+	}
+	return params
+}
+
+func readFillRates(resp map[string]any, p *CollectionProfile, idx []int) {
+	counts, _ := resp["facet_counts"].(map[string]any)
+	queries, _ := counts["facet_queries"].(map[string]any)
+	for _, i := range idx {
+		if n, ok := queries["f"+strconv.Itoa(i)].(float64); ok {
+			p.Fields[i].Present = int64(n)
+		}
+		if p.NumDocs > 0 {
+			p.Fields[i].FillRate = float64(int(float64(p.Fields[i].Present)/float64(p.NumDocs)*10000+0.5)) / 10000
+		}
+	}
+}
+
+func statsParams(query string, fields []types.SolrField, idx []int) url.Values {
+	params := url.Values{"q": {query}, "rows": {"0"}, "stats": {"true"}, "wt": {"json"}}
+	for _, i := range idx {
+		local := "cardinality=true"
+		if kind := fieldKind(fields[i].Type); kind == "numeric" || kind == "date" {
+			local = "min=true max=true cardinality=true"
+		}
+		params.Add("stats.field", fmt.Sprintf("{!%s}%s", local, fields[i].Name))
+	}
+	return params
+}
+
+func readStats(resp map[string]any, p *CollectionProfile, fields []types.SolrField) {
+	stats, _ := resp["stats"].(map[string]any)
+	statsFields, _ := stats["stats_fields"].(map[string]any)
+	for i, f := range fields {
+		s, ok := statsFields[f.Name].(map[string]any)
+		if !ok {
+			continue
+		}
+		if n, ok := s["cardinality"].(float64); ok {
+			distinct := int64(n)
+			p.Fields[i].Distinct = &distinct
+		}
+		p.Fields[i].Min, p.Fields[i].Max = s["min"], s["max"]
+	}
+}
+
+func numFound(resp map[string]any) int64 {
+	respObj, _ := resp["response"].(map[string]any)
+	n, _ := respObj["numFound"].(float64)
+	return int64(n)
+}
+
+// flagField adds the flags of suspicious fill patterns to f.
+func flagField(f *FieldProfile, numDocs int64, now time.Time) {
+	if f.Error != "" || numDocs == 0 {
+		return
+	}
+	switch {
+	case f.Present == 0:
+		f.Flags = append(f.Flags, FlagEmpty)
+	case f.FillRate < SparseFillRate:
+		f.Flags = append(f.Flags, FlagSparse)
+	}
+	if f.Present > 1 && f.Distinct != nil && *f.Distinct == 1 {
+		f.Flags = append(f.Flags, FlagConstant)
+	}
+	if maxDate, ok := f.Max.(string); ok && fieldKind(f.Type) == "date" {
+		if t, err := time.Parse(time.RFC3339, maxDate); err == nil && t.After(now.Add(24*time.Hour)) {
+			f.Flags = append(f.Flags, FlagFutureDate)
+		}
+	}
+}
+
+func finding(f FieldProfile, flag string) string {
+	switch flag {
+	case FlagEmpty:
+		return fmt.Sprintf("%s: no document has a value; the field may be unused", f.Name)
+	case FlagSparse:
+		return fmt.Sprintf("%s: only %.1f%% of the documents have a value", f.Name, f.FillRate*100)
+	case FlagConstant:
+		return fmt.Sprintf("%s: all %d documents with a value have the same value", f.Name, f.Present)
+	case FlagFutureDate:
+		return fmt.Sprintf("%s: the latest date %v is in the future", f.Name, f.Max)
+	}
+	return f.Name + ": " + flag
+}
@@ -0,0 +1,113 @@
+package solr
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type querierFunc func(params url.Values) (map[string]any, error)
+
+func (f querierFunc) Query(_ context.Context, _ string, params url.Values) (map[string]any, error) {
+	return f(params)
+}
+
+func (f querierFunc) Count(context.Context, string, string) (int64, error) { return 0, nil }
+
+// TestProfileCollection tests fill rates, stats and the flags of suspicious fields.
+func TestProfileCollection(t *testing.T) {
+	fields := []types.SolrField{
+		{Name: "id", Type: "string"},
+		{Name: "title", Type: "text_general"},
+		{Name: "legacy", Type: "string"},
+		{Name: "price", Type: "pfloat"},
+		{Name: "published", Type: "pdate"},
+		{Name: "region", Type: "string"},
+		{Name: "geo", Type: "location"},
+	}
+	q := querierFunc(func(params url.Values) (map[string]any, error) {
+		if fq := params["facet.query"]; len(fq) > 0 {
+			// the location field cannot be range-queried and fails the combined request
+			if strings.Contains(strings.Join(fq, " "), "geo:") {
+				return nil, errors.New("HTTP status 400: Can't run range query on geo")
+			}
+			present := map[string]float64{"f0": 1000, "f1": 990, "f2": 0, "f3": 30, "f4": 1000, "f5": 1000}
+			key := strings.TrimPrefix(strings.SplitN(fq[0], "}", 2)[0], "{!key=")
+			return map[string]any{
+				"response":     map[string]any{"numFound": float64(1000)},
+				"facet_counts": map[string]any{"facet_queries": map[string]any{key: present[key]}},
+			}, nil
+		}
+		assert.Equal(t, []string{"{!cardinality=true}id", "{!min=true max=true cardinality=true}price", "{!min=true max=true cardinality=true}published", "{!cardinality=true}region"}, params["stats.field"])
+		return map[string]any{"stats": map[string]any{"stats_fields": map[string]any{
+			"id":        map[string]any{"cardinality": float64(1000)},
+			"price":     map[string]any{"min": 1.5, "max": 99.0, "cardinality": float64(25)},
+			"published": map[string]any{"min": "2020-01-01T00:00:00Z", "max": "2031-01-01T00:00:00Z", "cardinality": float64(400)},
+			"region":    map[string]any{"cardinality": float64(1)},
+		}}}, nil
+	})
+
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	p, err := ProfileCollection(context.Background(), q, "products", "", fields, now)
+	require.NoError(t, err)
+
+	// Goal: A failing field is reported on its own and the others are profiled one by one.
+	assert.Equal(t, int64(1000), p.NumDocs)
+	assert.Equal(t, "*:*", p.Query)
+	assert.Contains(t, p.Fields[6].Error, "range query")
+	assert.Equal(t, 0.99, p.Fields[1].FillRate)
+	assert.Nil(t, p.Fields[1].Distinct)
+	assert.Equal(t, 1.5, p.Fields[3].Min)
+	assert.Equal(t, int64(25), *p.Fields[3].Distinct)
+
+	// Goal: Empty, sparse and constant fields and future dates are flagged with a finding each.
+	assert.Equal(t, []string{FlagEmpty}, p.Fields[2].Flags)
+	assert.Equal(t, []string{FlagSparse}, p.Fields[3].Flags)
+	assert.Equal(t, []string{FlagFutureDate}, p.Fields[4].Flags)
+	assert.Equal(t, []string{FlagConstant}, p.Fields[5].Flags)
+	assert.Empty(t, p.Fields[0].Flags)
+	assert.Equal(t, []string{
+		"legacy: no document has a value; the field may be unused",
+		"price: only 3.0% of the documents have a value",
+		"published: the latest date 2031-01-01T00:00:00Z is in the future",
+		"region: all 1000 documents with a value have the same value",
+	}, p.Findings)
+}
+
+// TestProfileFields tests that Solr-maintained fields are not profiled.
+func TestProfileFields(t *testing.T) {
+	fc := &types.FieldCatalog{All: []types.SolrField{{Name: "id"}, {Name: "_version_"}, {Name: "_root_"}, {Name: "title"}}}
+
+	// Goal: Internal fields are left out and schema order is kept.
+	assert.Equal(t, []types.SolrField{{Name: "id"}, {Name: "title"}}, ProfileFields(fc))
+}
+
+// TestFetchFieldStats tests computing the statistics of one field.
+func TestFetchFieldStats(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	q := querierFunc(func(params url.Values) (map[string]any, error) {
+		assert.Equal(t, "{!count=true cardinality=true min=true max=true}published", params.Get("stats.field"))
+		return map[string]any{"stats": map[string]any{"stats_fields": map[string]any{
+			"published": map[string]any{"count": float64(900), "cardinality": float64(400), "min": "2020-01-01T00:00:00Z", "max": "2025-06-01T00:00:00Z"},
+		}}}, nil
+	})
+	fs, err := FetchFieldStats(context.Background(), q, "products", types.SolrField{Name: "published", Type: "pdate"}, now)
+	require.NoError(t, err)
+	assert.Equal(t, types.FieldStats{Field: "published", Type: "pdate", Count: 900, Cardinality: 400,
+		Min: "2020-01-01T00:00:00Z", Max: "2025-06-01T00:00:00Z", Fetched: now}, fs)
+
+	// Goal: Text fields have no statistics, and a missing stats section is an error.
+	_, err = FetchFieldStats(context.Background(), q, "products", types.SolrField{Name: "title", Type: "text_general"}, now)
+	assert.ErrorContains(t, err, "no statistics for text fields")
+	empty := querierFunc(func(url.Values) (map[string]any, error) { return map[string]any{}, nil })
+	_, err = FetchFieldStats(context.Background(), empty, "products", types.SolrField{Name: "region", Type: "string"}, now)
+	assert.ErrorContains(t, err, "no statistics in the response")
+}
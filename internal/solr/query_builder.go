@@ -10,8 +10,11 @@ import (
 	"net/http"
 	"net/url"
 	"os"
-	"solr-mcp-go/internal/utils"
 	"strconv"
+	"strings"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/types"
+	"github.com/Sashimimochi/solr-mcp-go/internal/utils"
 
 	solr_sdk "github.com/stevenferrer/solr-go"
 )
@@ -113,13 +116,57 @@ func AppendFilterQuery(params map[string]any, fq string) {
 	}
 }
 
+// BuildQuery converts the input of solr.query into a solr-go Query.
+func BuildQuery(in types.QueryIn) *solr_sdk.Query {
+	// Use simple query without parser wrapper to avoid {!lucene v=...} syntax issues
+	// This allows complex queries with parentheses and multiple operators to work correctly
+	query := solr_sdk.NewQuery(utils.Choose(in.Query, "*:*"))
+	if len(in.Fields) > 0 {
+		query = query.Fields(in.Fields...)
+	}
+	if len(in.FilterQuery) > 0 {
+		query = query.Filters(in.FilterQuery...)
+	}
+	if in.Sort != "" {
+		query = query.Sort(in.Sort)
+	}
+	if in.Start != nil {
+		query = query.Offset(*in.Start)
+	}
+	if in.Rows != nil {
+		query = query.Limit(*in.Rows)
+	}
+
+	// Merge params with echoParams if needed
+	params := make(map[string]any)
+	for k, v := range in.Params {
+		params[k] = v
+	}
+	if in.EchoParams {
+		params["echoParams"] = "all"
+	}
+	if len(in.UseParams) > 0 {
+		params["useParams"] = strings.Join(in.UseParams, ",")
+	}
+	if len(params) > 0 {
+		query = query.Params(solr_sdk.M(params))
+	}
+	return query
+}
+
 // QueryWithRawResponse executes a query and returns the raw JSON response as map[string]any
 // This preserves all fields from Solr response including params in responseHeader
 func QueryWithRawResponse(ctx context.Context, httpClient *http.Client, baseURL, user, pass, collection string, query *solr_sdk.Query) (map[string]any, error) {
-	// Build the query URL
-	queryURL := fmt.Sprintf("%s/solr/%s/select", baseURL, url.PathEscape(collection))
+	return getSelect(ctx, httpClient, user, pass, SelectURL(baseURL, collection), QueryValues(query))
+}
 
-	// Convert query to URL parameters
+// SelectURL returns the URL of the /select handler of collection.
+func SelectURL(baseURL, collection string) string {
+	return fmt.Sprintf("%s/solr/%s/select", baseURL, url.PathEscape(collection))
+}
+
+// QueryValues converts a solr-go Query into traditional /select URL parameters.
+func QueryValues(query *solr_sdk.Query) url.Values {
 	queryMap := query.BuildQuery()
 	values := url.Values{}
 	for k, v := range queryMap {
@@ -139,6 +186,11 @@ func QueryWithRawResponse(ctx context.Context, httpClient *http.Client, baseURL,
 			paramKey = "fq"
 		}
 
+		// Query.Params stores additional params as solr_sdk.M, which is a distinct named type
+		if m, ok := v.(solr_sdk.M); ok {
+			v = map[string]any(m)
+		}
+
 		switch val := v.(type) {
 		case string:
 			values.Add(paramKey, val)
@@ -178,7 +230,11 @@ func QueryWithRawResponse(ctx context.Context, httpClient *http.Client, baseURL,
 		}
 	}
 	values.Set("wt", "json")
+	return values
+}
 
+// getSelect sends a GET request with the given parameters to a /select URL and decodes the JSON response.
+func getSelect(ctx context.Context, httpClient *http.Client, user, pass, queryURL string, values url.Values) (map[string]any, error) {
 	fullURL := queryURL + "?" + values.Encode()
 	slog.Debug("Executing raw Solr query", "url", fullURL)
 
@@ -514,6 +514,29 @@ func TestQueryWithRawResponse(t *testing.T) {
 		assert.NoError(t, err)
 	})
 
+	t.Run("Success: Params() values are flattened into the URL", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			q := r.URL.Query()
+			assert.Equal(t, "true", q.Get("facet"))
+			assert.Equal(t, []string{"a", "b"}, q["facet.field"])
+			assert.Empty(t, q.Get("params"))
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			json.NewEncoder(w).Encode(map[string]any{"response": map[string]any{}})
+		}))
+		defer server.Close()
+
+		client := &http.Client{}
+		query := solr.NewQuery("*:*").Params(solr.M{
+			"facet":       "true",
+			"facet.field": []string{"a", "b"},
+		})
+
+		_, err := QueryWithRawResponse(context.Background(), client, server.URL, "", "", "testcollection", query)
+
+		assert.NoError(t, err)
+	})
+
 	t.Run("Success: Basic auth", func(t *testing.T) {
 		var receivedAuth string
 		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -0,0 +1,147 @@
+package solr
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Kinds of expensive terms.
+const (
+	LeadingWildcard = "leading_wildcard" // *phone: every term of the field is compared
+	UnboundedRegex  = "unbounded_regex"  // /.*phone/: the automaton cannot skip any term of the field
+	WideFuzzy       = "wide_fuzzy"       // ab~2: an edit distance of 2 on a short term matches most terms of the field
+)
+
+// ShortFuzzyTerm is the length up to which a fuzzy term with an edit distance of 2 is considered too wide.
+const ShortFuzzyTerm = 5
+
+// ExpensiveTerm is a term of a query that makes Solr scan the term dictionary of its field.
+type ExpensiveTerm struct {
+	Kind  string `json:"kind"`
+	Field string `json:"field,omitempty"` // empty for the default field
+	Term  string `json:"term"`            // as written, including the field
+	// Rewrite is a bounded replacement of Term: leading wildcards and .* are dropped, and fuzzy terms use an edit distance of 1
+	Rewrite string `json:"rewrite"`
+	// Expansion estimates the terms visited, from the distinct values of the field. Nil when unknown.
+	Expansion *int64 `json:"expansion,omitempty"`
+
+	start, end int
+}
+
+var (
+	phraseRe       = regexp.MustCompile(`"(?:\\.|[^"\\])*"`)
+	regexTermRe    = regexp.MustCompile(`([\w.]+:)?/((?:\\.|[^/\\])+)/`)
+	wildcardTermRe = regexp.MustCompile(`([\w.]+:)?([*?][^\s()"\[\]{}^~:/]*)`)
+	fuzzyTermRe    = regexp.MustCompile(`([\w.]+:)?([^\s()"\[\]{}^~:*?\\/+!-][^\s()"\[\]{}^~:*?\\/]*)~([0-9.]*)`)
+	unboundedRe    = regexp.MustCompile(`^(\.[*+]|\.\{\d*,\d*\})+`)
+)
+
+// FindExpensiveTerms returns the leading wildcards, unbounded regular expressions and wide fuzzy terms of a
+// query in the standard, dismax or edismax syntax, in order. Quoted phrases and escaped characters are ignored.
+// Queries of other parsers, e.g. {!terms}, have none.
+func FindExpensiveTerms(query string) []ExpensiveTerm {
+	masked := []byte(query)
+	if m := localParamsRe.FindStringSubmatch(query); m != nil {
+		switch parseLocalParams(m[1])["type"] {
+		case "", "lucene", "dismax", "edismax":
+			mask(masked, 0, len(query)-len(m[2]))
+		default:
+			return nil
+		}
+	}
+	for _, loc := range phraseRe.FindAllIndex(masked, -1) {
+		mask(masked, loc[0], loc[1])
+	}
+
+	var terms []ExpensiveTerm
+	for _, m := range regexTermRe.FindAllSubmatchIndex(masked, -1) {
+		if !atTermStart(masked, m[0]) {
+			continue
+		}
+		// regexes are masked so their content is not read as wildcards or fuzzy terms
+		mask(masked, m[0], m[1])
+		field, pattern := group(query, m, 1), group(query, m, 2)
+		if !unboundedRe.MatchString(pattern) {
+			continue
+		}
+		rewrite := "/" + unboundedRe.ReplaceAllString(pattern, "") + "/"
+		if rewrite == "//" {
+			rewrite = "*"
+		}
+		terms = append(terms, newTerm(UnboundedRegex, query, m, field, rewrite))
+	}
+
+	for _, m := range wildcardTermRe.FindAllSubmatchIndex(masked, -1) {
+		term := group(query, m, 2)
+		if !atTermStart(masked, m[0]) || strings.Trim(term, "*?") == "" {
+			continue
+		}
+		terms = append(terms, newTerm(LeadingWildcard, query, m, group(query, m, 1), strings.TrimLeft(term, "*?")))
+	}
+	for _, m := range fuzzyTermRe.FindAllSubmatchIndex(masked, -1) {
+		if !atTermStart(masked, m[0]) {
+			continue
+		}
+		term, distance := group(query, m, 2), group(query, m, 3)
+		d := 2.0
+		if distance != "" {
+			var err error
+			if d, err = strconv.ParseFloat(distance, 64); err != nil {
+				continue
+			}
+		}
+		if d < 2 || utf8.RuneCountInString(term) > ShortFuzzyTerm {
+			continue
+		}
+		terms = append(terms, newTerm(WideFuzzy, query, m, group(query, m, 1), term+"~1"))
+	}
+	sort.Slice(terms, func(i, j int) bool { return terms[i].start < terms[j].start })
+	return terms
+}
+
+// RewriteExpensiveTerms replaces terms found in query by FindExpensiveTerms with their Rewrite.
+func RewriteExpensiveTerms(query string, terms []ExpensiveTerm) string {
+	sorted := append([]ExpensiveTerm{}, terms...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].start > sorted[j].start })
+	for _, t := range sorted {
+		if t.end <= len(query) && query[t.start:t.end] == t.Term {
+			query = query[:t.start] + t.Rewrite + query[t.end:]
+		}
+	}
+	return query
+}
+
+func newTerm(kind, query string, m []int, field, rewrite string) ExpensiveTerm {
+	return ExpensiveTerm{
+		Kind:    kind,
+		Field:   strings.TrimSuffix(field, ":"),
+		Term:    query[m[0]:m[1]],
+		Rewrite: field + rewrite,
+		start:   m[0],
+		end:     m[1],
+	}
+}
+
+// group returns submatch i of m in s, or "" when it did not participate.
+func group(s string, m []int, i int) string {
+	if m[2*i] < 0 {
+		return ""
+	}
+	return s[m[2*i]:m[2*i+1]]
+}
+
+// atTermStart reports whether a term starts at offset i: at the start of the query, or after whitespace,
+// a parenthesis or an operator.
+func atTermStart(s []byte, i int) bool {
+	return i == 0 || strings.IndexByte(" \t\r\n(+-!", s[i-1]) >= 0
+}
+
+// mask replaces s[from:to] with spaces, so offsets stay valid while the content is ignored.
+func mask(s []byte, from, to int) {
+	for i := from; i < to; i++ {
+		s[i] = ' '
+	}
+}
@@ -0,0 +1,78 @@
+package solr
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestFindExpensiveTerms tests detecting and rewriting leading wildcards, unbounded regexes and wide fuzzy terms.
+func TestFindExpensiveTerms(t *testing.T) {
+	testCases := []struct {
+		name    string
+		query   string
+		want    []ExpensiveTerm
+		rewrite string
+	}{
+		{
+			name:  "leading wildcards",
+			query: "title:*phone* AND (*case OR brand:apple)",
+			want: []ExpensiveTerm{
+				{Kind: LeadingWildcard, Field: "title", Term: "title:*phone*", Rewrite: "title:phone*"},
+				{Kind: LeadingWildcard, Term: "*case", Rewrite: "case"},
+			},
+			rewrite: "title:phone* AND (case OR brand:apple)",
+		},
+		{
+			name:  "unbounded regex",
+			query: "+sku:/.*-X.{0,3}/ -sku:/AB.*/",
+			want: []ExpensiveTerm{
+				{Kind: UnboundedRegex, Field: "sku", Term: "sku:/.*-X.{0,3}/", Rewrite: "sku:/-X.{0,3}/"},
+			},
+			rewrite: "+sku:/-X.{0,3}/ -sku:/AB.*/",
+		},
+		{
+			name:  "wide fuzzy terms",
+			query: "name:ab~ OR name:cd~1 OR name:laptop~2 OR +ef~2",
+			want: []ExpensiveTerm{
+				{Kind: WideFuzzy, Field: "name", Term: "name:ab~", Rewrite: "name:ab~1"},
+				{Kind: WideFuzzy, Term: "ef~2", Rewrite: "ef~1"},
+			},
+			rewrite: "name:ab~1 OR name:cd~1 OR name:laptop~2 OR +ef~1",
+		},
+		{
+			name:    "safe queries",
+			query:   `*:* AND price:[* TO 100] AND brand:* AND title:phone* AND "*quoted"~3 AND path:\*etc AND title:/[a-z]+/`,
+			rewrite: `*:* AND price:[* TO 100] AND brand:* AND title:phone* AND "*quoted"~3 AND path:\*etc AND title:/[a-z]+/`,
+		},
+		{
+			name:    "other query parsers",
+			query:   "{!terms f=id}*a,b",
+			rewrite: "{!terms f=id}*a,b",
+		},
+		{
+			name:  "edismax local params",
+			query: "{!edismax qf=title}*phone",
+			want: []ExpensiveTerm{
+				{Kind: LeadingWildcard, Term: "*phone", Rewrite: "phone"},
+			},
+			rewrite: "{!edismax qf=title}phone",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			terms := FindExpensiveTerms(tc.query)
+			got := make([]ExpensiveTerm, len(terms))
+			for i, term := range terms {
+				got[i] = ExpensiveTerm{Kind: term.Kind, Field: term.Field, Term: term.Term, Rewrite: term.Rewrite}
+			}
+			if tc.want == nil {
+				assert.Empty(t, got)
+			} else {
+				assert.Equal(t, tc.want, got)
+			}
+			assert.Equal(t, tc.rewrite, RewriteExpensiveTerms(tc.query, terms))
+		})
+	}
+}
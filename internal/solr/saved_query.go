@@ -0,0 +1,132 @@
+package solr
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/config"
+	"github.com/Sashimimochi/solr-mcp-go/internal/utils"
+
+	solr_sdk "github.com/stevenferrer/solr-go"
+)
+
+// BuildSavedQuery converts a saved query into a solr-go Query that only returns counts/aggregates.
+func BuildSavedQuery(q config.SavedQuery) *solr_sdk.Query {
+	query := solr_sdk.NewQuery(utils.Choose(q.Query, "*:*"))
+	if len(q.FilterQuery) > 0 {
+		query = query.Filters(q.FilterQuery...)
+	}
+	params := solr_sdk.M{"rows": 0}
+	if q.Aggregate != "" {
+		facet, _ := json.Marshal(map[string]any{"value": q.Aggregate})
+		params["json.facet"] = string(facet)
+	}
+	return query.Params(params)
+}
+
+// EvaluateSavedQuery runs a saved query and returns its numFound, or the aggregate value if one is configured.
+func EvaluateSavedQuery(ctx context.Context, httpClient *http.Client, baseURL, user, pass string, q config.SavedQuery) (float64, error) {
+	resp, err := QueryWithRawResponse(ctx, httpClient, baseURL, user, pass, q.Collection, BuildSavedQuery(q))
+	if err != nil {
+		return 0, err
+	}
+	return SavedQueryValue(resp, q)
+}
+
+// SavedQueryValue extracts the value of a saved query from a raw Solr response.
+func SavedQueryValue(resp map[string]any, q config.SavedQuery) (float64, error) {
+	if q.Aggregate == "" {
+		respObj, _ := resp["response"].(map[string]any)
+		numFound, ok := respObj["numFound"].(float64)
+		if !ok {
+			return 0, fmt.Errorf("numFound not found in response")
+		}
+		return numFound, nil
+	}
+
+	facets, _ := resp["facets"].(map[string]any)
+	if facets == nil {
+		return 0, fmt.Errorf("facets not found in response")
+	}
+	switch v := facets["value"].(type) {
+	case float64:
+		return v, nil
+	case nil:
+		// Solr omits aggregations over an empty result set
+		return 0, nil
+	default:
+		return 0, fmt.Errorf("unexpected aggregate value type %T", v)
+	}
+}
+
+// SeriesPoint is a single bucket of a saved query time series.
+type SeriesPoint struct {
+	Time  time.Time
+	Value float64
+}
+
+// BuildSavedQuerySeries converts a saved query into a range facet query bucketing its TimeField between from and to.
+func BuildSavedQuerySeries(q config.SavedQuery, from, to time.Time, gap time.Duration) *solr_sdk.Query {
+	query := solr_sdk.NewQuery(utils.Choose(q.Query, "*:*"))
+	if len(q.FilterQuery) > 0 {
+		query = query.Filters(q.FilterQuery...)
+	}
+	seconds := int64(gap / time.Second)
+	if seconds < 1 {
+		seconds = 1
+	}
+	series := map[string]any{
+		"type":  "range",
+		"field": q.TimeField,
+		"start": from.UTC().Format(time.RFC3339),
+		"end":   to.UTC().Format(time.RFC3339),
+		"gap":   fmt.Sprintf("+%dSECONDS", seconds),
+	}
+	if q.Aggregate != "" {
+		series["facet"] = map[string]any{"value": q.Aggregate}
+	}
+	facet, _ := json.Marshal(map[string]any{"series": series})
+	return query.Params(solr_sdk.M{"rows": 0, "json.facet": string(facet)})
+}
+
+// EvaluateSavedQuerySeries runs a saved query as a time series over [from, to).
+// The saved query must have a TimeField.
+func EvaluateSavedQuerySeries(ctx context.Context, httpClient *http.Client, baseURL, user, pass string, q config.SavedQuery, from, to time.Time, gap time.Duration) ([]SeriesPoint, error) {
+	if q.TimeField == "" {
+		return nil, fmt.Errorf("saved query %s has no timeField", q.Name)
+	}
+	resp, err := QueryWithRawResponse(ctx, httpClient, baseURL, user, pass, q.Collection, BuildSavedQuerySeries(q, from, to, gap))
+	if err != nil {
+		return nil, err
+	}
+	return SavedQuerySeriesPoints(resp, q)
+}
+
+// SavedQuerySeriesPoints extracts the buckets of a range facet response.
+func SavedQuerySeriesPoints(resp map[string]any, q config.SavedQuery) ([]SeriesPoint, error) {
+	facets, _ := resp["facets"].(map[string]any)
+	series, _ := facets["series"].(map[string]any)
+	if series == nil {
+		return nil, fmt.Errorf("series facet not found in response")
+	}
+	buckets, _ := series["buckets"].([]any)
+	points := make([]SeriesPoint, 0, len(buckets))
+	for _, b := range buckets {
+		bucket, _ := b.(map[string]any)
+		valStr, _ := bucket["val"].(string)
+		ts, err := time.Parse(time.RFC3339, valStr)
+		if err != nil {
+			return nil, fmt.Errorf("unexpected bucket value %v: %v", bucket["val"], err)
+		}
+		key := "count"
+		if q.Aggregate != "" {
+			key = "value"
+		}
+		v, _ := bucket[key].(float64)
+		points = append(points, SeriesPoint{Time: ts, Value: v})
+	}
+	return points, nil
+}
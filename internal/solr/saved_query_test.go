@@ -0,0 +1,148 @@
+package solr
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/config"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestEvaluateSavedQuery tests the EvaluateSavedQuery function.
+func TestEvaluateSavedQuery(t *testing.T) {
+	// Goal: Without an aggregate the numFound of the query is returned.
+	t.Run("Success: numFound", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			q := r.URL.Query()
+			assert.Equal(t, "/solr/logs/select", r.URL.Path)
+			assert.Equal(t, "level:ERROR", q.Get("q"))
+			assert.Equal(t, []string{"service:api"}, q["fq"])
+			assert.Equal(t, "0", q.Get("rows"))
+			assert.Empty(t, q.Get("json.facet"))
+			json.NewEncoder(w).Encode(map[string]any{"response": map[string]any{"numFound": 12}})
+		}))
+		defer server.Close()
+
+		v, err := EvaluateSavedQuery(context.Background(), server.Client(), server.URL, "", "", config.SavedQuery{
+			Name: "errors", Collection: "logs", Query: "level:ERROR", FilterQuery: []string{"service:api"},
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, float64(12), v)
+	})
+
+	// Goal: With an aggregate the JSON Facet value is returned.
+	t.Run("Success: aggregate", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, `{"value":"sum(bytes)"}`, r.URL.Query().Get("json.facet"))
+			assert.Equal(t, "*:*", r.URL.Query().Get("q"))
+			json.NewEncoder(w).Encode(map[string]any{
+				"response": map[string]any{"numFound": 3},
+				"facets":   map[string]any{"count": 3, "value": 2048.0},
+			})
+		}))
+		defer server.Close()
+
+		v, err := EvaluateSavedQuery(context.Background(), server.Client(), server.URL, "", "", config.SavedQuery{
+			Name: "bytes", Collection: "logs", Aggregate: "sum(bytes)",
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 2048.0, v)
+	})
+
+	// Goal: Solr errors are propagated.
+	t.Run("Error: HTTP error", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "boom", http.StatusInternalServerError)
+		}))
+		defer server.Close()
+
+		_, err := EvaluateSavedQuery(context.Background(), server.Client(), server.URL, "", "", config.SavedQuery{Name: "q", Collection: "logs"})
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "HTTP status 500")
+	})
+}
+
+// TestSavedQueryValue tests value extraction from raw responses.
+func TestSavedQueryValue(t *testing.T) {
+	agg := config.SavedQuery{Aggregate: "max(price)"}
+
+	// Goal: An aggregate over an empty result set is reported as zero.
+	v, err := SavedQueryValue(map[string]any{"facets": map[string]any{"count": 0.0}}, agg)
+	assert.NoError(t, err)
+	assert.Equal(t, 0.0, v)
+
+	// Goal: Missing sections are reported as errors.
+	_, err = SavedQueryValue(map[string]any{}, agg)
+	assert.Error(t, err)
+	_, err = SavedQueryValue(map[string]any{}, config.SavedQuery{})
+	assert.Error(t, err)
+	_, err = SavedQueryValue(map[string]any{"facets": map[string]any{"value": "x"}}, agg)
+	assert.Error(t, err)
+}
+
+// TestEvaluateSavedQuerySeries tests the EvaluateSavedQuerySeries function.
+func TestEvaluateSavedQuerySeries(t *testing.T) {
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(2 * time.Minute)
+
+	// Goal: A range facet over the time field is issued and its buckets are returned.
+	t.Run("Success: count buckets", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var facet map[string]map[string]any
+			assert.NoError(t, json.Unmarshal([]byte(r.URL.Query().Get("json.facet")), &facet))
+			assert.Equal(t, "range", facet["series"]["type"])
+			assert.Equal(t, "timestamp", facet["series"]["field"])
+			assert.Equal(t, "2025-01-01T00:00:00Z", facet["series"]["start"])
+			assert.Equal(t, "2025-01-01T00:02:00Z", facet["series"]["end"])
+			assert.Equal(t, "+60SECONDS", facet["series"]["gap"])
+			json.NewEncoder(w).Encode(map[string]any{
+				"facets": map[string]any{"series": map[string]any{"buckets": []any{
+					map[string]any{"val": "2025-01-01T00:00:00Z", "count": 3},
+					map[string]any{"val": "2025-01-01T00:01:00Z", "count": 4},
+				}}},
+			})
+		}))
+		defer server.Close()
+
+		points, err := EvaluateSavedQuerySeries(context.Background(), server.Client(), server.URL, "", "",
+			config.SavedQuery{Name: "q", Collection: "logs", TimeField: "timestamp"}, from, to, time.Minute)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []SeriesPoint{{Time: from, Value: 3}, {Time: from.Add(time.Minute), Value: 4}}, points)
+	})
+
+	// Goal: A saved query without a time field cannot be evaluated as a series.
+	t.Run("Error: no time field", func(t *testing.T) {
+		_, err := EvaluateSavedQuerySeries(context.Background(), http.DefaultClient, "http://localhost", "", "",
+			config.SavedQuery{Name: "q", Collection: "logs"}, from, to, time.Minute)
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "no timeField")
+	})
+}
+
+// TestSavedQuerySeriesPoints tests bucket extraction with aggregates.
+func TestSavedQuerySeriesPoints(t *testing.T) {
+	q := config.SavedQuery{Aggregate: "sum(bytes)"}
+	resp := map[string]any{"facets": map[string]any{"series": map[string]any{"buckets": []any{
+		map[string]any{"val": "2025-01-01T00:00:00Z", "count": 2.0, "value": 10.0},
+		map[string]any{"val": "2025-01-01T00:01:00Z", "count": 0.0},
+	}}}}
+
+	points, err := SavedQuerySeriesPoints(resp, q)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 10.0, points[0].Value)
+	assert.Equal(t, 0.0, points[1].Value)
+
+	_, err = SavedQuerySeriesPoints(map[string]any{}, q)
+	assert.Error(t, err)
+}
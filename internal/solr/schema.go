@@ -8,8 +8,10 @@ import (
 	"log/slog"
 	"net/http"
 	"net/url"
+	"sort"
 
-	"solr-mcp-go/internal/types"
+	"github.com/Sashimimochi/solr-mcp-go/internal/config"
+	"github.com/Sashimimochi/solr-mcp-go/internal/types"
 )
 
 type SchemaContext struct {
@@ -27,6 +29,7 @@ func GetFieldCatalog(ctx context.Context, sCtx SchemaContext, collection string)
 	}
 
 	fc := &types.FieldCatalog{}
+	var ukErr error
 	ukURL := fmt.Sprintf("%s/solr/%s/schema/uniquekey?wt=json", sCtx.BaseURL, url.PathEscape(collection))
 	if err := getJSON(ctx, sCtx.HttpClient, sCtx.User, sCtx.Pass, ukURL, &struct {
 		UniqueKey string `json:"uniqueKey"`
@@ -36,7 +39,7 @@ func GetFieldCatalog(ctx context.Context, sCtx SchemaContext, collection string)
 		}).UniqueKey
 		fc.UniqueKey = uniquekey
 	}); err != nil {
-		return nil, fmt.Errorf("failed to get uniqueKey from Solr: %v", err)
+		ukErr = err
 	}
 
 	fieldsURL := fmt.Sprintf("%s/solr/%s/schema/fields?wt=json&includeDynamic=true", sCtx.BaseURL, url.PathEscape(collection))
@@ -44,10 +47,28 @@ func GetFieldCatalog(ctx context.Context, sCtx SchemaContext, collection string)
 		Fields []types.SolrField `json:"fields"`
 	}
 	if err := getJSON(ctx, sCtx.HttpClient, sCtx.User, sCtx.Pass, fieldsURL, &fld, nil); err != nil {
+		if ukErr != nil {
+			return nil, fmt.Errorf("failed to get uniqueKey from Solr: %v", ukErr)
+		}
 		return nil, fmt.Errorf("failed to get fields from Solr: %v", err)
 	}
 	fc.All = fld.Fields
 
+	// Solr fails the uniqueKey request for schemas without one. The schema itself is readable,
+	// so continue with an empty key and let ID-based operations report it.
+	if ukErr != nil {
+		slog.Warn("failed to get uniqueKey from Solr, assuming the collection has none", "collection", collection, "err", ukErr)
+	}
+	if fc.UniqueKey == "" {
+		slog.Info("collection has no uniqueKey", "collection", collection)
+	}
+
+	if router, err := getRouter(ctx, sCtx, collection); err == nil {
+		fc.Router = router
+	} else {
+		slog.Warn("failed to get router from Solr", "err", err)
+	}
+
 	metadataURL := fmt.Sprintf("%s/solr/%s/admin/file?file=field_metadata.json&wt=json", sCtx.BaseURL, url.PathEscape(collection))
 	var metadata map[string]types.FieldMetadata
 	if err := getJSON(ctx, sCtx.HttpClient, sCtx.User, sCtx.Pass, metadataURL, &metadata, nil); err == nil {
@@ -56,13 +77,82 @@ func GetFieldCatalog(ctx context.Context, sCtx SchemaContext, collection string)
 		slog.Warn("failed to get field metadata from Solr", "err", err)
 	}
 
+	if handler, err := getSelectHandlerParams(ctx, sCtx, collection); err == nil {
+		fc.SelectHandler = handler
+	} else {
+		slog.Warn("failed to get /select handler config from Solr", "err", err)
+	}
+
+	if dynamic, err := getDynamicFieldInstances(ctx, sCtx, collection); err == nil {
+		fc.DynamicFields = dynamic
+	} else {
+		slog.Warn("failed to get dynamic field instances from Solr", "err", err)
+	}
+
 	// Store in cache with thread-safe access
 	sCtx.Cache.Set(collection, fc)
 	return fc, nil
 }
 
+// getRouter reads the document router name of the collection from CLUSTERSTATUS.
+// It fails on standalone Solr, which has no Collections API.
+func getRouter(ctx context.Context, sCtx SchemaContext, collection string) (string, error) {
+	statusURL := fmt.Sprintf("%s/solr/admin/collections?action=CLUSTERSTATUS&collection=%s&wt=json", sCtx.BaseURL, url.QueryEscape(collection))
+	var status config.ClusterStatusResponse
+	if err := getJSON(ctx, sCtx.HttpClient, sCtx.User, sCtx.Pass, statusURL, &status, nil); err != nil {
+		return "", err
+	}
+	coll, ok := status.Cluster.Collections[collection]
+	if !ok {
+		return "", fmt.Errorf("collection %s not found in cluster status", collection)
+	}
+	return coll.Router["name"], nil
+}
+
+// getSelectHandlerParams reads the defaults, appends and invariants of the /select handler from the Config API.
+func getSelectHandlerParams(ctx context.Context, sCtx SchemaContext, collection string) (*types.HandlerParams, error) {
+	configURL := fmt.Sprintf("%s/solr/%s/config/requestHandler?componentName=/select&wt=json", sCtx.BaseURL, url.PathEscape(collection))
+	var cfg struct {
+		Config struct {
+			RequestHandler map[string]types.HandlerParams `json:"requestHandler"`
+		} `json:"config"`
+	}
+	if err := getJSON(ctx, sCtx.HttpClient, sCtx.User, sCtx.Pass, configURL, &cfg, nil); err != nil {
+		return nil, err
+	}
+	handler, ok := cfg.Config.RequestHandler["/select"]
+	if !ok {
+		return nil, fmt.Errorf("/select handler not found in config")
+	}
+	return &handler, nil
+}
+
+// getDynamicFieldInstances asks the Luke handler which fields exist in the index and groups
+// those created from dynamic field patterns by their pattern.
+func getDynamicFieldInstances(ctx context.Context, sCtx SchemaContext, collection string) (map[string][]string, error) {
+	lukeURL := fmt.Sprintf("%s/solr/%s/admin/luke?numTerms=0&wt=json", sCtx.BaseURL, url.PathEscape(collection))
+	var luke struct {
+		Fields map[string]struct {
+			DynamicBase string `json:"dynamicBase"`
+		} `json:"fields"`
+	}
+	if err := getJSON(ctx, sCtx.HttpClient, sCtx.User, sCtx.Pass, lukeURL, &luke, nil); err != nil {
+		return nil, err
+	}
+	dynamic := map[string][]string{}
+	for name, f := range luke.Fields {
+		if f.DynamicBase != "" {
+			dynamic[f.DynamicBase] = append(dynamic[f.DynamicBase], name)
+		}
+	}
+	for pattern := range dynamic {
+		sort.Strings(dynamic[pattern])
+	}
+	return dynamic, nil
+}
+
 func getJSON(ctx context.Context, httpClient *http.Client, user, pass, u string, into any, after func(any)) error {
-	slog.Info("GET", "url", u)
+	slog.Info("GET", "url", config.RedactURL(u))
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
 	if err != nil {
 		return fmt.Errorf("failed to create request: %v", err)
@@ -100,3 +190,22 @@ func getJSON(ctx context.Context, httpClient *http.Client, user, pass, u string,
 
 	return nil
 }
+
+// GetSchemaAndOverlay reads the full schema and the config overlay (changes made with the Config API) of a collection.
+func GetSchemaAndOverlay(ctx context.Context, sCtx SchemaContext, collection string) (schema, overlay map[string]any, err error) {
+	var schemaResp struct {
+		Schema map[string]any `json:"schema"`
+	}
+	schemaURL := fmt.Sprintf("%s/solr/%s/schema?wt=json", sCtx.BaseURL, url.PathEscape(collection))
+	if err := getJSON(ctx, sCtx.HttpClient, sCtx.User, sCtx.Pass, schemaURL, &schemaResp, nil); err != nil {
+		return nil, nil, fmt.Errorf("failed to get schema: %v", err)
+	}
+	var overlayResp struct {
+		Overlay map[string]any `json:"overlay"`
+	}
+	overlayURL := fmt.Sprintf("%s/solr/%s/config/overlay?wt=json", sCtx.BaseURL, url.PathEscape(collection))
+	if err := getJSON(ctx, sCtx.HttpClient, sCtx.User, sCtx.Pass, overlayURL, &overlayResp, nil); err != nil {
+		return nil, nil, fmt.Errorf("failed to get config overlay: %v", err)
+	}
+	return schemaResp.Schema, overlayResp.Overlay, nil
+}
@@ -3,13 +3,15 @@ package solr
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"reflect"
-	"solr-mcp-go/internal/types"
 	"testing"
 	"time"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/types"
 )
 
 // TestGetFieldCatalog tests the GetFieldCatalog function.
@@ -67,6 +69,12 @@ func TestGetFieldCatalog(t *testing.T) {
 				"price_i":      {Description: "価格"},
 			}
 			json.NewEncoder(w).Encode(metadata)
+		// Mock Config API for the /select request handler
+		case "/solr/testcollection/config/requestHandler":
+			fmt.Fprintln(w, `{"config":{"requestHandler":{"/select":{"name":"/select","class":"solr.SearchHandler","defaults":{"df":"_text_","rows":10},"invariants":{"fq":"visible:true"}}}}}`)
+		// Mock Luke API listing the fields present in the index
+		case "/solr/testcollection/admin/luke":
+			fmt.Fprintln(w, `{"fields":{"id":{"type":"string"},"title_txt_ja":{"type":"text_ja","dynamicBase":"*_txt_ja"},"body_txt_ja":{"type":"text_ja","dynamicBase":"*_txt_ja"},"price_i":{"type":"pint","dynamicBase":"*_i"}}}`)
 		default:
 			http.NotFound(w, r)
 		}
@@ -122,6 +130,25 @@ func TestGetFieldCatalog(t *testing.T) {
 		if !reflect.DeepEqual(fc.Metadata, expectedFC.Metadata) {
 			t.Errorf("Metadata mismatch. got=%v, want=%v", fc.Metadata, expectedFC.Metadata)
 		}
+		// Goal: /select handler defaults and invariants are read from the Config API.
+		expectedHandler := &types.HandlerParams{
+			Defaults:   map[string]any{"df": "_text_", "rows": float64(10)},
+			Invariants: map[string]any{"fq": "visible:true"},
+		}
+		if !reflect.DeepEqual(fc.SelectHandler, expectedHandler) {
+			t.Errorf("SelectHandler mismatch. got=%v, want=%v", fc.SelectHandler, expectedHandler)
+		}
+		// Goal: Dynamic field instances found by Luke are grouped by their pattern.
+		expectedDynamic := map[string][]string{
+			"*_txt_ja": {"body_txt_ja", "title_txt_ja"},
+			"*_i":      {"price_i"},
+		}
+		if !reflect.DeepEqual(fc.DynamicFields, expectedDynamic) {
+			t.Errorf("DynamicFields mismatch. got=%v, want=%v", fc.DynamicFields, expectedDynamic)
+		}
+		if !fc.HasField("body_txt_ja") || fc.HasField("message_txt_ja") {
+			t.Errorf("HasField should only accept existing dynamic field instances")
+		}
 	})
 
 	t.Run("Success: cache works within TTL", func(t *testing.T) {
@@ -284,6 +311,10 @@ func TestGetFieldCatalog(t *testing.T) {
 		if len(fc.Metadata) != 0 {
 			t.Errorf("Metadata should be empty. got=%v", fc.Metadata)
 		}
+		// Handler config is optional as well
+		if fc.SelectHandler != nil {
+			t.Errorf("SelectHandler should be nil. got=%v", fc.SelectHandler)
+		}
 		// Other information should still be available
 		if fc.UniqueKey != "id" {
 			t.Errorf("UniqueKey not obtained. got=%s", fc.UniqueKey)
@@ -362,6 +393,49 @@ func TestGetFieldCatalog(t *testing.T) {
 		}
 	})
 
+	t.Run("Success: collection without uniqueKey and implicit router", func(t *testing.T) {
+		// Goal: A failing uniqueKey request does not fail the catalog when the schema is readable,
+		// and the router is captured from CLUSTERSTATUS.
+		noKeyServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			switch r.URL.Path {
+			case "/solr/testcollection/schema/uniquekey":
+				http.Error(w, `{"error":{"msg":"java.lang.NullPointerException"}}`, http.StatusInternalServerError)
+			case "/solr/testcollection/schema/fields":
+				fmt.Fprintln(w, `{"fields":[{"name":"message","type":"text_general"}]}`)
+			case "/solr/admin/collections":
+				fmt.Fprintln(w, `{"cluster":{"collections":{"testcollection":{"router":{"name":"implicit"},"shards":{}}}}}`)
+			default:
+				http.NotFound(w, r)
+			}
+		}))
+		defer noKeyServer.Close()
+
+		sCtx := SchemaContext{
+			HttpClient: noKeyServer.Client(),
+			BaseURL:    noKeyServer.URL,
+			Cache: &types.SchemaCache{
+				ByCol:     make(map[string]*types.FieldCatalog),
+				LastFetch: make(map[string]time.Time),
+				TTL:       1 * time.Minute,
+			},
+		}
+
+		fc, err := GetFieldCatalog(context.Background(), sCtx, "testcollection")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if fc.UniqueKey != "" {
+			t.Errorf("UniqueKey should be empty. got=%s", fc.UniqueKey)
+		}
+		if fc.Router != "implicit" {
+			t.Errorf("Router mismatch. got=%s, want=implicit", fc.Router)
+		}
+		if _, err := fc.RequireUniqueKey(); !errors.Is(err, types.ErrNoUniqueKey) {
+			t.Errorf("RequireUniqueKey should return ErrNoUniqueKey. got=%v", err)
+		}
+	})
+
 	t.Run("Error: HTTP request fails", func(t *testing.T) {
 		// Goal: Verify appropriate errors are returned on network failures.
 		sCtx := SchemaContext{
@@ -0,0 +1,451 @@
+package solr
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/types"
+)
+
+// MaxChildLimit is the largest number of child documents @child may return per parent.
+const MaxChildLimit = 1000
+
+// SelectionItem is a field or a document transformer of a field selection.
+type SelectionItem struct {
+	Alias       string
+	Name        string // field name, "score", or a transformer name starting with "@"
+	Args        map[string]any
+	Fields      []SelectionItem // fields of child documents selected with @child { ... }
+	pos         int
+	transformer bool
+}
+
+// Selection is a parsed field selection of solr.query, such as
+//
+//	id, title, @child(filter: "type_s:review", limit: 3) { id, rating }, @features(store: "ltr", efi: {q: "laptop"})
+//
+// Plain names are fields (validated against the schema), "score" is the relevance score, "alias: field"
+// renames a field, and names starting with "@" are Solr document transformers from an allowlist.
+type Selection []SelectionItem
+
+// transformerSpec describes an allowed document transformer and its arguments.
+type transformerSpec struct {
+	solrName string
+	args     map[string]string // argument -> Solr local param
+	children bool              // accepts a { ... } field selection
+	alias    bool              // the result key can be renamed
+}
+
+var selectionTransformers = map[string]transformerSpec{
+	"@child":    {solrName: "child", args: map[string]string{"filter": "childFilter", "parentFilter": "parentFilter", "limit": "limit"}, children: true},
+	"@elevated": {solrName: "elevated", alias: true},
+	"@excluded": {solrName: "excluded", alias: true},
+	"@features": {solrName: "features", args: map[string]string{"store": "store", "efi": "efi", "format": "format"}, alias: true},
+	"@explain":  {solrName: "explain", args: map[string]string{"style": "style"}, alias: true},
+	"@shard":    {solrName: "shard", alias: true},
+	"@docid":    {solrName: "docid", alias: true},
+}
+
+// SelectionTransformerNames returns the allowed transformer names, sorted.
+func SelectionTransformerNames() []string {
+	names := make([]string, 0, len(selectionTransformers))
+	for n := range selectionTransformers {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ParseSelection parses a field selection. Items are separated by commas or whitespace.
+func ParseSelection(s string) (Selection, error) {
+	p := &selectionParser{src: s}
+	items, err := p.items(false)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return nil, fmt.Errorf("empty selection")
+	}
+	return items, nil
+}
+
+// HasTransformers reports whether the selection uses document transformers.
+func (sel Selection) HasTransformers() bool {
+	for _, it := range sel {
+		if it.transformer {
+			return true
+		}
+	}
+	return false
+}
+
+// FieldList validates the selection against the schema and returns the fl values that request it.
+// fc may be nil to skip the field checks.
+func (sel Selection) FieldList(fc *types.FieldCatalog) ([]string, error) {
+	out := make([]string, 0, len(sel))
+	for _, it := range sel {
+		fl, err := it.fieldList(fc)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, fl)
+	}
+	return out, nil
+}
+
+func (it SelectionItem) fieldList(fc *types.FieldCatalog) (string, error) {
+	prefix := ""
+	if it.Alias != "" {
+		prefix = it.Alias + ":"
+	}
+	if !it.transformer {
+		if len(it.Fields) > 0 {
+			return "", fmt.Errorf("offset %d: only @child takes a field selection, %s is a field", it.pos, it.Name)
+		}
+		if err := checkSelectedField(it.Name, fc); err != nil {
+			return "", fmt.Errorf("offset %d: %v", it.pos, err)
+		}
+		return prefix + it.Name, nil
+	}
+
+	spec, ok := selectionTransformers[it.Name]
+	if !ok {
+		return "", fmt.Errorf("offset %d: unknown transformer %s (allowed: %s)", it.pos, it.Name, strings.Join(SelectionTransformerNames(), ", "))
+	}
+	if it.Alias != "" && !spec.alias {
+		return "", fmt.Errorf("offset %d: %s cannot be renamed, child documents are returned under their relation", it.pos, it.Name)
+	}
+	if len(it.Fields) > 0 && !spec.children {
+		return "", fmt.Errorf("offset %d: %s does not take a field selection", it.pos, it.Name)
+	}
+
+	params := []string{spec.solrName}
+	argNames := make([]string, 0, len(it.Args))
+	for name := range it.Args {
+		argNames = append(argNames, name)
+	}
+	sort.Strings(argNames)
+	for _, name := range argNames {
+		local, ok := spec.args[name]
+		if !ok {
+			return "", fmt.Errorf("offset %d: %s has no argument %q", it.pos, it.Name, name)
+		}
+		value := it.Args[name]
+		switch local {
+		case "efi":
+			efi, ok := value.(map[string]any)
+			if !ok {
+				return "", fmt.Errorf("offset %d: efi must be an object such as {q: \"laptop\"}", it.pos)
+			}
+			keys := make([]string, 0, len(efi))
+			for k := range efi {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				v, err := scalarArg(efi[k])
+				if err != nil {
+					return "", fmt.Errorf("offset %d: efi.%s: %v", it.pos, k, err)
+				}
+				params = append(params, "efi."+k+"="+quoteLocalParam(v))
+			}
+			continue
+		case "limit":
+			n, ok := value.(float64)
+			if !ok || n != float64(int(n)) || n < 1 || n > MaxChildLimit {
+				return "", fmt.Errorf("offset %d: limit must be an integer from 1 to %d", it.pos, MaxChildLimit)
+			}
+		case "style":
+			if s, _ := value.(string); s != "text" && s != "nl" && s != "html" {
+				return "", fmt.Errorf("offset %d: style must be text, nl or html", it.pos)
+			}
+		}
+		v, err := scalarArg(value)
+		if err != nil {
+			return "", fmt.Errorf("offset %d: %s: %v", it.pos, name, err)
+		}
+		if (local == "childFilter" || local == "parentFilter") && (strings.Contains(v, "{!") || strings.Contains(v, "$")) {
+			return "", fmt.Errorf("offset %d: %s must not use local params or parameter references", it.pos, name)
+		}
+		params = append(params, local+"="+quoteLocalParam(v))
+	}
+	if len(it.Fields) > 0 {
+		fields := make([]string, 0, len(it.Fields))
+		for _, f := range it.Fields {
+			if f.transformer {
+				return "", fmt.Errorf("offset %d: only fields can be selected for child documents", f.pos)
+			}
+			fl, err := f.fieldList(fc)
+			if err != nil {
+				return "", err
+			}
+			fields = append(fields, fl)
+		}
+		params = append(params, "fl="+quoteLocalParam(strings.Join(fields, ",")))
+	}
+	return prefix + "[" + strings.Join(params, " ") + "]", nil
+}
+
+func checkSelectedField(name string, fc *types.FieldCatalog) error {
+	if name == "score" || fc == nil {
+		return nil
+	}
+	if strings.Contains(name, "*") {
+		if len(MatchingFields(fc, name)) == 0 {
+			return fmt.Errorf("no field matches %s", name)
+		}
+		return nil
+	}
+	if !fc.HasField(name) {
+		return fmt.Errorf("field %s does not exist", name)
+	}
+	return nil
+}
+
+// MatchingFields returns the fields of the schema, including the dynamic field instances in the index, that an fl
+// pattern such as "*" or "price_*" selects.
+func MatchingFields(fc *types.FieldCatalog, pattern string) []string {
+	var fields []string
+	for _, f := range fc.All {
+		if globMatch(pattern, f.Name) {
+			fields = append(fields, f.Name)
+		}
+	}
+	for _, instances := range fc.DynamicFields {
+		for _, inst := range instances {
+			if globMatch(pattern, inst) {
+				fields = append(fields, inst)
+			}
+		}
+	}
+	return fields
+}
+
+// globMatch matches name against a pattern where * stands for any characters.
+func globMatch(pattern, name string) bool {
+	parts := strings.Split(pattern, "*")
+	if !strings.HasPrefix(name, parts[0]) {
+		return false
+	}
+	name = name[len(parts[0]):]
+	for i, part := range parts[1:] {
+		if i == len(parts)-2 {
+			return strings.HasSuffix(name, part)
+		}
+		idx := strings.Index(name, part)
+		if idx < 0 {
+			return false
+		}
+		name = name[idx+len(part):]
+	}
+	return true
+}
+
+func scalarArg(v any) (string, error) {
+	switch t := v.(type) {
+	case string:
+		return t, nil
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64), nil
+	case bool:
+		return strconv.FormatBool(t), nil
+	}
+	return "", fmt.Errorf("must be a string, number or boolean")
+}
+
+// quoteLocalParam quotes a local param value so that spaces, brackets and quotes cannot end it early.
+func quoteLocalParam(v string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(v) + "'"
+}
+
+// selectionParser is a recursive descent parser of field selections.
+type selectionParser struct {
+	src string
+	pos int
+}
+
+func (p *selectionParser) errorf(format string, args ...any) error {
+	return fmt.Errorf("offset %d: %s", p.pos, fmt.Sprintf(format, args...))
+}
+
+// skip moves past whitespace and, if commas is set, item separating commas.
+func (p *selectionParser) skip(commas bool) {
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		if c == ' ' || c == '\t' || c == '\n' || c == '\r' || (commas && c == ',') {
+			p.pos++
+			continue
+		}
+		return
+	}
+}
+
+func (p *selectionParser) peek() byte {
+	if p.pos < len(p.src) {
+		return p.src[p.pos]
+	}
+	return 0
+}
+
+func (p *selectionParser) items(nested bool) ([]SelectionItem, error) {
+	var items []SelectionItem
+	for {
+		p.skip(true)
+		switch p.peek() {
+		case 0:
+			if nested {
+				return nil, p.errorf("missing }")
+			}
+			return items, nil
+		case '}':
+			if !nested {
+				return nil, p.errorf("unexpected }")
+			}
+			p.pos++
+			return items, nil
+		}
+		it, err := p.item(nested)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, it)
+	}
+}
+
+func (p *selectionParser) item(nested bool) (SelectionItem, error) {
+	it := SelectionItem{pos: p.pos}
+	name, err := p.name()
+	if err != nil {
+		return it, err
+	}
+	p.skip(false)
+	if p.peek() == ':' {
+		if strings.HasPrefix(name, "@") || strings.Contains(name, "*") {
+			return it, p.errorf("invalid alias %s", name)
+		}
+		p.pos++
+		p.skip(false)
+		it.Alias = name
+		if name, err = p.name(); err != nil {
+			return it, err
+		}
+		p.skip(false)
+	}
+	it.Name = name
+	it.transformer = strings.HasPrefix(name, "@")
+	if p.peek() == '(' {
+		if !it.transformer {
+			return it, p.errorf("only transformers take arguments, %s is a field", name)
+		}
+		p.pos++
+		if it.Args, err = p.args(')'); err != nil {
+			return it, err
+		}
+		p.skip(false)
+	}
+	if p.peek() == '{' {
+		if nested {
+			return it, p.errorf("child selections cannot be nested")
+		}
+		p.pos++
+		if it.Fields, err = p.items(true); err != nil {
+			return it, err
+		}
+	}
+	return it, nil
+}
+
+func isNameChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '.' || c == '-' || c == '*'
+}
+
+func (p *selectionParser) name() (string, error) {
+	start := p.pos
+	if p.peek() == '@' {
+		p.pos++
+	}
+	for p.pos < len(p.src) && isNameChar(p.src[p.pos]) {
+		p.pos++
+	}
+	name := p.src[start:p.pos]
+	if name == "" || name == "@" {
+		p.pos = start
+		return "", p.errorf("expected a field or @transformer, found %q", string(p.peek()))
+	}
+	return name, nil
+}
+
+// args parses "key: value" pairs up to the closing byte. Values are strings, numbers, booleans or objects.
+func (p *selectionParser) args(closing byte) (map[string]any, error) {
+	args := map[string]any{}
+	for {
+		p.skip(true)
+		if p.peek() == closing {
+			p.pos++
+			return args, nil
+		}
+		if p.peek() == 0 {
+			return nil, p.errorf("missing %c", closing)
+		}
+		key, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		p.skip(false)
+		if p.peek() != ':' {
+			return nil, p.errorf("expected : after %s", key)
+		}
+		p.pos++
+		p.skip(false)
+		if args[key], err = p.value(); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (p *selectionParser) value() (any, error) {
+	switch c := p.peek(); {
+	case c == '"' || c == '\'':
+		return p.str(c)
+	case c == '{':
+		p.pos++
+		return p.args('}')
+	}
+	start := p.pos
+	for p.pos < len(p.src) && isNameChar(p.src[p.pos]) {
+		p.pos++
+	}
+	word := p.src[start:p.pos]
+	switch word {
+	case "":
+		return nil, p.errorf("expected a value")
+	case "true", "false":
+		return word == "true", nil
+	}
+	if n, err := strconv.ParseFloat(word, 64); err == nil {
+		return n, nil
+	}
+	return word, nil
+}
+
+func (p *selectionParser) str(quote byte) (string, error) {
+	p.pos++
+	var b strings.Builder
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		switch {
+		case c == '\\' && p.pos+1 < len(p.src):
+			b.WriteByte(p.src[p.pos+1])
+			p.pos += 2
+		case c == quote:
+			p.pos++
+			return b.String(), nil
+		default:
+			b.WriteByte(c)
+			p.pos++
+		}
+	}
+	return "", p.errorf("unterminated string")
+}
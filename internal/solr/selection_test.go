@@ -0,0 +1,100 @@
+package solr
+
+import (
+	"testing"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func selectionCatalog() *types.FieldCatalog {
+	return &types.FieldCatalog{
+		UniqueKey: "id",
+		All: []types.SolrField{
+			{Name: "id", Type: "string"},
+			{Name: "title", Type: "text_general"},
+			{Name: "rating", Type: "pint"},
+			{Name: "type_s", Type: "string"},
+		},
+		DynamicFields: map[string][]string{"attr_*": {"attr_color", "attr_size"}},
+	}
+}
+
+// TestSelectionFieldList tests compiling selections into fl values.
+func TestSelectionFieldList(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    string
+		expected []string
+	}{
+		{
+			name:     "fields, score, aliases and globs",
+			input:    "id title, name: title score attr_*",
+			expected: []string{"id", "title", "name:title", "score", "attr_*"},
+		},
+		{
+			name:     "child documents with a filter, limit and fields",
+			input:    `id, @child(filter: "type_s:review", limit: 3) { id, rating }`,
+			expected: []string{"id", "[child childFilter='type_s:review' limit='3' fl='id,rating']"},
+		},
+		{
+			name:     "features with a store and external feature info",
+			input:    `id @features(store: ltr, efi: {q: "red shoes", user: 7})`,
+			expected: []string{"id", "[features efi.q='red shoes' efi.user='7' store='ltr']"},
+		},
+		{
+			name:     "renamed transformers",
+			input:    `id pinned: @elevated why: @explain(style: nl) @shard`,
+			expected: []string{"id", "pinned:[elevated]", "why:[explain style='nl']", "[shard]"},
+		},
+		{
+			name:     "quotes in values are escaped",
+			input:    `@child(filter: "title:\"it's\"")`,
+			expected: []string{`[child childFilter='title:"it\'s"']`},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Goal: The selection is turned into the equivalent fl values.
+			sel, err := ParseSelection(tc.input)
+			require.NoError(t, err)
+			fl, err := sel.FieldList(selectionCatalog())
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, fl)
+		})
+	}
+}
+
+// TestSelectionErrors tests that invalid selections are rejected with their position.
+func TestSelectionErrors(t *testing.T) {
+	testCases := []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{name: "unknown field", input: "id nope", wantErr: "offset 3: field nope does not exist"},
+		{name: "unknown transformer", input: "@value", wantErr: "unknown transformer @value (allowed: @child"},
+		{name: "unknown argument", input: "@child(depth: 2)", wantErr: `@child has no argument "depth"`},
+		{name: "limit out of range", input: "@child(limit: 5000)", wantErr: "limit must be an integer from 1 to 1000"},
+		{name: "local params in a filter", input: `@child(filter: "{!join from=id to=id}x")`, wantErr: "must not use local params"},
+		{name: "renamed children", input: "reviews: @child", wantErr: "@child cannot be renamed"},
+		{name: "nested transformer", input: "@child { id @shard }", wantErr: "only fields can be selected"},
+		{name: "arguments on a field", input: "title(x: 1)", wantErr: "only transformers take arguments"},
+		{name: "unterminated selection", input: "@child { id", wantErr: "missing }"},
+		{name: "empty", input: " , ", wantErr: "empty selection"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Goal: The error names the problem.
+			sel, err := ParseSelection(tc.input)
+			if err == nil {
+				_, err = sel.FieldList(selectionCatalog())
+			}
+			assert.ErrorContains(t, err, tc.wantErr)
+		})
+	}
+}
@@ -0,0 +1,212 @@
+package solr
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/config"
+
+	solr_sdk "github.com/stevenferrer/solr-go"
+)
+
+// ReplicaResult is the outcome of a query sent to a single replica core with distrib=false.
+type ReplicaResult struct {
+	Shard     string         `json:"shard"`
+	Replica   string         `json:"replica"`
+	Core      string         `json:"core"`
+	Node      string         `json:"node"`
+	State     string         `json:"state"`
+	Leader    bool           `json:"leader,omitempty"`
+	NumFound  *int64         `json:"numFound,omitempty"`
+	QTime     int            `json:"qtime"`
+	LatencyMs int64          `json:"latencyMs"`
+	Error     string         `json:"error,omitempty"`
+	Response  map[string]any `json:"-"`
+}
+
+// GetClusterStatus returns the CLUSTERSTATUS of a collection.
+func GetClusterStatus(ctx context.Context, httpClient *http.Client, baseURL, user, pass, collection string) (*config.CollectionStatus, error) {
+	u := fmt.Sprintf("%s/solr/admin/collections?action=CLUSTERSTATUS&collection=%s&wt=json", baseURL, url.QueryEscape(collection))
+	var status config.ClusterStatusResponse
+	if err := getJSON(ctx, httpClient, user, pass, u, &status, nil); err != nil {
+		return nil, fmt.Errorf("cluster status request: %v", err)
+	}
+	coll, ok := status.Cluster.Collections[collection]
+	if !ok {
+		return nil, fmt.Errorf("collection %s not found", collection)
+	}
+	return &coll, nil
+}
+
+// QueryReplicas sends the query to every replica of the collection concurrently with distrib=false,
+// so that each replica only answers from its own index. Results are sorted by shard and replica.
+func QueryReplicas(ctx context.Context, httpClient *http.Client, user, pass string, status *config.CollectionStatus, query *solr_sdk.Query) []ReplicaResult {
+	values := QueryValues(query)
+	values.Set("distrib", "false")
+
+	results := replicaTargets(status)
+	var wg sync.WaitGroup
+	for i := range results {
+		wg.Add(1)
+		go func(r *ReplicaResult) {
+			defer wg.Done()
+			start := time.Now()
+			resp, err := getSelect(ctx, httpClient, user, pass, replicaCoreURL(status, r)+"/select", values)
+			r.LatencyMs = time.Since(start).Milliseconds()
+			if err != nil {
+				r.Error = err.Error()
+				return
+			}
+			r.Response = resp
+			if header, ok := resp["responseHeader"].(map[string]any); ok {
+				if qtime, ok := header["QTime"].(float64); ok {
+					r.QTime = int(qtime)
+				}
+			}
+			if respObj, ok := resp["response"].(map[string]any); ok {
+				if numFound, ok := respObj["numFound"].(float64); ok {
+					n := int64(numFound)
+					r.NumFound = &n
+				}
+			}
+		}(&results[i])
+	}
+	wg.Wait()
+	return results
+}
+
+// replicaTargets lists the replicas of the collection sorted by shard and replica name.
+func replicaTargets(status *config.CollectionStatus) []ReplicaResult {
+	var results []ReplicaResult
+	for shardName, shard := range status.Shards {
+		for replicaName, replica := range shard.Replicas {
+			results = append(results, ReplicaResult{
+				Shard:   shardName,
+				Replica: replicaName,
+				Core:    replica.Core,
+				Node:    replica.NodeName,
+				State:   replica.State,
+				Leader:  replica.Leader == "true",
+			})
+		}
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Shard != results[j].Shard {
+			return results[i].Shard < results[j].Shard
+		}
+		return results[i].Replica < results[j].Replica
+	})
+	return results
+}
+
+// replicaCoreURL returns the URL of the core hosting the replica.
+func replicaCoreURL(status *config.CollectionStatus, r *ReplicaResult) string {
+	replica := status.Shards[r.Shard].Replicas[r.Replica]
+	return strings.TrimRight(replica.BaseURL, "/") + "/" + url.PathEscape(replica.Core)
+}
+
+// ShardSummary compares the replicas of a single shard.
+type ShardSummary struct {
+	Shard        string          `json:"shard"`
+	NumFound     int64           `json:"numFound"`         // numFound of the leader, or the largest when there is no leader result
+	Consistent   bool            `json:"consistent"`       // all replicas that answered agree on numFound
+	Failed       int             `json:"failed,omitempty"` // replicas that returned an error
+	MaxLatencyMs int64           `json:"maxLatencyMs"`
+	Replicas     []ReplicaResult `json:"replicas"`
+}
+
+// SummarizeShards groups replica results by shard and reports replicas that disagree or fail,
+// slow replicas and shards whose document count is far from the average.
+func SummarizeShards(results []ReplicaResult) ([]ShardSummary, []string) {
+	var summaries []ShardSummary
+	var findings []string
+	index := map[string]int{}
+	for _, r := range results {
+		i, ok := index[r.Shard]
+		if !ok {
+			i = len(summaries)
+			index[r.Shard] = i
+			summaries = append(summaries, ShardSummary{Shard: r.Shard, Consistent: true})
+		}
+		summaries[i].Replicas = append(summaries[i].Replicas, r)
+	}
+
+	var total int64
+	for i := range summaries {
+		s := &summaries[i]
+		var counts []int64
+		var latencies []int64
+		for _, r := range s.Replicas {
+			if r.Error != "" {
+				s.Failed++
+				findings = append(findings, fmt.Sprintf("%s/%s failed: %s", s.Shard, r.Replica, r.Error))
+				continue
+			}
+			latencies = append(latencies, r.LatencyMs)
+			if r.LatencyMs > s.MaxLatencyMs {
+				s.MaxLatencyMs = r.LatencyMs
+			}
+			if r.NumFound == nil {
+				continue
+			}
+			counts = append(counts, *r.NumFound)
+			if r.Leader || *r.NumFound > s.NumFound {
+				s.NumFound = *r.NumFound
+			}
+		}
+		for _, c := range counts {
+			if c != counts[0] {
+				s.Consistent = false
+			}
+		}
+		if !s.Consistent {
+			var parts []string
+			for _, r := range s.Replicas {
+				if r.NumFound != nil {
+					parts = append(parts, fmt.Sprintf("%s=%d", r.Replica, *r.NumFound))
+				}
+			}
+			findings = append(findings, fmt.Sprintf("%s replicas disagree on numFound (%s)", s.Shard, strings.Join(parts, ", ")))
+		}
+		if slow := slowReplica(s.Replicas, latencies); slow != "" {
+			findings = append(findings, fmt.Sprintf("%s/%s is much slower than the other replicas of the shard", s.Shard, slow))
+		}
+		total += s.NumFound
+	}
+
+	if len(summaries) > 1 && total > 0 {
+		mean := float64(total) / float64(len(summaries))
+		for _, s := range summaries {
+			if ratio := float64(s.NumFound) / mean; ratio > 2 || ratio < 0.5 {
+				findings = append(findings, fmt.Sprintf("%s holds %d matching documents, %.1fx the shard average of %.0f", s.Shard, s.NumFound, ratio, mean))
+			}
+		}
+	}
+	return summaries, findings
+}
+
+// slowReplica returns the replica whose latency is more than three times the median of its shard.
+func slowReplica(replicas []ReplicaResult, latencies []int64) string {
+	if len(latencies) < 2 {
+		return ""
+	}
+	sorted := append([]int64(nil), latencies...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	median := sorted[(len(sorted)-1)/2]
+	// Ignore differences that are within normal jitter
+	if median < 10 {
+		median = 10
+	}
+	for _, r := range replicas {
+		if r.Error == "" && r.LatencyMs > 3*median {
+			return r.Replica
+		}
+	}
+	return ""
+}
@@ -0,0 +1,103 @@
+package solr
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	solr_sdk "github.com/stevenferrer/solr-go"
+)
+
+// newShardServer mocks CLUSTERSTATUS for a collection with two shards of two replicas,
+// answering per-core queries with the given numFound values.
+func newShardServer(t *testing.T, numFound map[string]string) *httptest.Server {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/solr/admin/collections":
+			base := server.URL + "/solr"
+			fmt.Fprintf(w, `{"cluster":{"collections":{"logs":{"shards":{
+				"shard1":{"replicas":{"core_node1":{"core":"logs_shard1_replica_n1","base_url":%q,"node_name":"n1","state":"active","leader":"true"},
+				                      "core_node2":{"core":"logs_shard1_replica_n2","base_url":%q,"node_name":"n2","state":"active"}}},
+				"shard2":{"replicas":{"core_node3":{"core":"logs_shard2_replica_n3","base_url":%q,"node_name":"n1","state":"active","leader":"true"},
+				                      "core_node4":{"core":"logs_shard2_replica_n4","base_url":%q,"node_name":"n2","state":"active"}}}}}}}}`,
+				base, base, base, base)
+		default:
+			assert.Equal(t, "false", r.URL.Query().Get("distrib"))
+			core := r.URL.Path[len("/solr/") : len(r.URL.Path)-len("/select")]
+			n, ok := numFound[core]
+			if !ok {
+				http.Error(w, "core down", http.StatusServiceUnavailable)
+				return
+			}
+			fmt.Fprintf(w, `{"responseHeader":{"status":0,"QTime":3},"response":{"numFound":%s,"docs":[]}}`, n)
+		}
+	}))
+	return server
+}
+
+// TestQueryReplicas tests that every replica is queried with distrib=false.
+func TestQueryReplicas(t *testing.T) {
+	server := newShardServer(t, map[string]string{
+		"logs_shard1_replica_n1": "10",
+		"logs_shard1_replica_n2": "10",
+		"logs_shard2_replica_n3": "12",
+	})
+	defer server.Close()
+
+	status, err := GetClusterStatus(context.Background(), server.Client(), server.URL, "", "", "logs")
+	assert.NoError(t, err)
+
+	// Goal: Results are returned per replica, sorted, with numFound, QTime and errors.
+	results := QueryReplicas(context.Background(), server.Client(), "", "", status, solr_sdk.NewQuery("*:*"))
+	assert.Len(t, results, 4)
+	assert.Equal(t, "shard1", results[0].Shard)
+	assert.Equal(t, "core_node1", results[0].Replica)
+	assert.True(t, results[0].Leader)
+	assert.Equal(t, int64(10), *results[0].NumFound)
+	assert.Equal(t, 3, results[0].QTime)
+	assert.Equal(t, "core_node4", results[3].Replica)
+	assert.Nil(t, results[3].NumFound)
+	assert.Contains(t, results[3].Error, "503")
+
+	// Goal: An unknown collection is reported.
+	_, err = GetClusterStatus(context.Background(), server.Client(), server.URL, "", "", "missing")
+	assert.Error(t, err)
+}
+
+// TestSummarizeShards tests the detection of inconsistent replicas, failures and skewed shards.
+func TestSummarizeShards(t *testing.T) {
+	n := func(v int64) *int64 { return &v }
+
+	// Goal: Consistent, balanced shards produce no findings.
+	summaries, findings := SummarizeShards([]ReplicaResult{
+		{Shard: "shard1", Replica: "r1", Leader: true, NumFound: n(10)},
+		{Shard: "shard1", Replica: "r2", NumFound: n(10)},
+		{Shard: "shard2", Replica: "r3", Leader: true, NumFound: n(12)},
+	})
+	assert.Empty(t, findings)
+	assert.Len(t, summaries, 2)
+	assert.True(t, summaries[0].Consistent)
+	assert.Equal(t, int64(12), summaries[1].NumFound)
+
+	// Goal: Diverging replicas, failed replicas, slow replicas and skewed shards are reported.
+	summaries, findings = SummarizeShards([]ReplicaResult{
+		{Shard: "shard1", Replica: "r1", Leader: true, NumFound: n(10), LatencyMs: 20},
+		{Shard: "shard1", Replica: "r2", NumFound: n(7), LatencyMs: 500},
+		{Shard: "shard2", Replica: "r3", Leader: true, NumFound: n(100)},
+		{Shard: "shard2", Replica: "r4", Error: "HTTP status 503"},
+	})
+	assert.False(t, summaries[0].Consistent)
+	assert.Equal(t, int64(10), summaries[0].NumFound)
+	assert.Equal(t, 1, summaries[1].Failed)
+	assert.Len(t, findings, 4)
+	assert.Contains(t, findings[0], "shard1 replicas disagree on numFound (r1=10, r2=7)")
+	assert.Contains(t, findings[1], "shard1/r2 is much slower")
+	assert.Contains(t, findings[2], "shard2/r4 failed")
+	assert.Contains(t, findings[3], "shard1 holds 10 matching documents")
+}
@@ -0,0 +1,213 @@
+package solr
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/types"
+)
+
+// Rewrite is a range shorthand that was expanded into Solr range syntax.
+type Rewrite struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+var (
+	// shorthandClauseRe finds field:value clauses; the value is checked for a shorthand separately.
+	shorthandClauseRe = regexp.MustCompile(`(^|[\s(+\-!])([A-Za-z_][\w.]*):([^\s()\[\]{}"]+)`)
+	lastRe            = regexp.MustCompile(`^last(\d+)(s|m|h|d|w|y)$`)
+	comparisonRe      = regexp.MustCompile(`^(>=|<=|>|<)(.+)$`)
+)
+
+var lastUnits = map[string]string{"s": "SECONDS", "m": "MINUTES", "h": "HOURS", "d": "DAYS", "w": "DAYS", "y": "YEARS"}
+
+// ExpandRangeShorthands rewrites friendly range shorthands in a query into Solr range syntax:
+//
+//	price:100..200        price:[100 TO 200]
+//	price:100.. / ..200   price:[100 TO *] / price:[* TO 200]
+//	price:>100, >=, <, <= price:{100 TO *], price:[100 TO *], ...
+//	date:2024-01-01..2024-01-31  whole days, the end day included
+//	timestamp:last7d      timestamp:[NOW-7DAYS TO NOW] (units s, m, h, d, w, y)
+//	timestamp:today / yesterday
+//
+// kind returns the RangeFieldKind of a field. Shorthands are only expanded on numeric fields with
+// numeric bounds and on date fields with date bounds. Other clauses, including malformed shorthands
+// such as a start after the end, are left unchanged for Solr to interpret.
+func ExpandRangeShorthands(q string, kind func(field string) string) (string, []Rewrite) {
+	var b strings.Builder
+	var rewrites []Rewrite
+	last := 0
+	for _, m := range shorthandClauseRe.FindAllStringSubmatchIndex(q, -1) {
+		field, value := q[m[4]:m[5]], q[m[6]:m[7]]
+		if !isShorthand(value) {
+			continue
+		}
+		expanded, ok := expandShorthand(value, kind(field))
+		if !ok {
+			continue
+		}
+		b.WriteString(q[last:m[6]])
+		b.WriteString(expanded)
+		last = m[7]
+		rewrites = append(rewrites, Rewrite{From: field + ":" + value, To: field + ":" + expanded})
+	}
+	if rewrites == nil {
+		return q, nil
+	}
+	b.WriteString(q[last:])
+	return b.String(), rewrites
+}
+
+// RangeFieldKind returns "numeric" or "date" for the fields of fc that range shorthands apply to, or "" for other fields.
+func RangeFieldKind(fc *types.FieldCatalog, field string) string {
+	for _, f := range fc.All {
+		if f.Name != field {
+			continue
+		}
+		if k := fieldKind(f.Type); k == "numeric" || k == "date" {
+			return k
+		}
+		return ""
+	}
+	return ""
+}
+
+// isShorthand reports whether value looks like a shorthand, so that the schema is only consulted for candidates.
+func isShorthand(value string) bool {
+	return value == "today" || value == "yesterday" || lastRe.MatchString(value) ||
+		comparisonRe.MatchString(value) || strings.Contains(value, "..")
+}
+
+// expandShorthand returns the range for a shorthand value on a field of the given kind,
+// or ok=false if value is not a valid shorthand for that kind.
+func expandShorthand(value, kind string) (string, bool) {
+	if kind != "numeric" && kind != "date" {
+		return "", false
+	}
+	switch value {
+	case "today":
+		return "[NOW/DAY TO NOW/DAY+1DAY}", kind == "date"
+	case "yesterday":
+		return "[NOW/DAY-1DAY TO NOW/DAY}", kind == "date"
+	}
+	if m := lastRe.FindStringSubmatch(value); m != nil {
+		n, err := strconv.Atoi(m[1])
+		if err != nil || n <= 0 || kind != "date" {
+			return "", false
+		}
+		if m[2] == "w" {
+			n *= 7
+		}
+		return fmt.Sprintf("[NOW-%d%s TO NOW]", n, lastUnits[m[2]]), true
+	}
+	if m := comparisonRe.FindStringSubmatch(value); m != nil {
+		bound, _, ok := rangeBound(m[2], kind, false)
+		if !ok {
+			return "", false
+		}
+		// for a whole day, > and <= refer to the start of the next day
+		nextBound, wholeDay, _ := rangeBound(m[2], kind, true)
+		switch m[1] {
+		case ">":
+			if wholeDay {
+				return fmt.Sprintf("[%s TO *]", nextBound), true
+			}
+			return fmt.Sprintf("{%s TO *]", bound), true
+		case ">=":
+			return fmt.Sprintf("[%s TO *]", bound), true
+		case "<":
+			return fmt.Sprintf("[* TO %s}", bound), true
+		default:
+			return fmt.Sprintf("[* TO %s%s", nextBound, closing(wholeDay)), true
+		}
+	}
+
+	start, end, ok := strings.Cut(value, "..")
+	if !ok || (start == "" && end == "") {
+		return "", false
+	}
+	lower, upper, exclusive := "*", "*", false
+	if start != "" {
+		if lower, _, ok = rangeBound(start, kind, false); !ok {
+			return "", false
+		}
+	}
+	if end != "" {
+		if upper, exclusive, ok = rangeBound(end, kind, true); !ok {
+			return "", false
+		}
+	}
+	if start != "" && end != "" && !inOrder(start, end, kind) {
+		return "", false
+	}
+	return fmt.Sprintf("[%s TO %s%s", lower, upper, closing(exclusive)), true
+}
+
+func closing(exclusive bool) string {
+	if exclusive {
+		return "}"
+	}
+	return "]"
+}
+
+// rangeBound validates a number for numeric fields, and a date or date math for date fields.
+// A date without a time covers the whole day, so as an upper bound it becomes the exclusive start of the next day.
+func rangeBound(s, kind string, upper bool) (string, bool, bool) {
+	if kind == "numeric" {
+		_, err := strconv.ParseFloat(s, 64)
+		return s, false, err == nil
+	}
+	if strings.HasPrefix(s, "NOW") {
+		return s, false, true
+	}
+	if d, err := time.Parse("2006-01-02", s); err == nil {
+		if upper {
+			return d.AddDate(0, 0, 1).Format(time.RFC3339), true, true
+		}
+		return d.Format(time.RFC3339), false, true
+	}
+	if d, err := time.Parse(time.RFC3339, s); err == nil {
+		return d.UTC().Format(time.RFC3339Nano), false, true
+	}
+	return "", false, false
+}
+
+// inOrder reports whether the start of a numeric or date range is not after its end. Date math is not checked.
+func inOrder(start, end, kind string) bool {
+	if kind == "numeric" {
+		a, _ := strconv.ParseFloat(start, 64)
+		b, _ := strconv.ParseFloat(end, 64)
+		return a <= b
+	}
+	a, errA := parseDay(start)
+	b, errB := parseDay(end)
+	return errA != nil || errB != nil || !a.After(b)
+}
+
+func parseDay(s string) (time.Time, error) {
+	if d, err := time.Parse("2006-01-02", s); err == nil {
+		return d, nil
+	}
+	return time.Parse(time.RFC3339, s)
+}
+
+// ExpandQueryShorthands expands range shorthands in the query and filter queries of in.
+// kind is only called for clauses that look like shorthands.
+func ExpandQueryShorthands(in *types.QueryIn, kind func(field string) string) []Rewrite {
+	q, rewrites := ExpandRangeShorthands(in.Query, kind)
+	in.Query = q
+	if len(in.FilterQuery) > 0 {
+		in.FilterQuery = slices.Clone(in.FilterQuery)
+	}
+	for i, fq := range in.FilterQuery {
+		expanded, r := ExpandRangeShorthands(fq, kind)
+		in.FilterQuery[i] = expanded
+		rewrites = append(rewrites, r...)
+	}
+	return rewrites
+}
@@ -0,0 +1,105 @@
+package solr
+
+import (
+	"testing"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/types"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testFieldKinds classifies the fields of the shorthand tests like RangeFieldKind.
+func testFieldKinds(field string) string {
+	switch field {
+	case "price":
+		return "numeric"
+	case "date", "timestamp":
+		return "date"
+	}
+	return ""
+}
+
+// TestExpandRangeShorthands tests the expansion of range shorthands into Solr range syntax.
+func TestExpandRangeShorthands(t *testing.T) {
+	cases := map[string]string{
+		"price:100..200":              "price:[100 TO 200]",
+		"price:100..":                 "price:[100 TO *]",
+		"price:..2.5":                 "price:[* TO 2.5]",
+		"price:-10..10":               "price:[-10 TO 10]",
+		"price:>100":                  "price:{100 TO *]",
+		"price:>=100":                 "price:[100 TO *]",
+		"price:<100":                  "price:[* TO 100}",
+		"price:<=100":                 "price:[* TO 100]",
+		"timestamp:last7d":            "timestamp:[NOW-7DAYS TO NOW]",
+		"timestamp:last2w":            "timestamp:[NOW-14DAYS TO NOW]",
+		"timestamp:last30m":           "timestamp:[NOW-30MINUTES TO NOW]",
+		"timestamp:today":             "timestamp:[NOW/DAY TO NOW/DAY+1DAY}",
+		"timestamp:yesterday":         "timestamp:[NOW/DAY-1DAY TO NOW/DAY}",
+		"date:2024-01-01..2024-01-31": "date:[2024-01-01T00:00:00Z TO 2024-02-01T00:00:00Z}",
+		"date:>2024-01-31":            "date:[2024-02-01T00:00:00Z TO *]",
+		"date:<=2024-01-31":           "date:[* TO 2024-02-01T00:00:00Z}",
+		"date:NOW-1DAY..NOW":          "date:[NOW-1DAY TO NOW]",
+		"level:ERROR AND price:1..5":  "level:ERROR AND price:[1 TO 5]",
+		"(price:1..5 OR -price:>9)":   "(price:[1 TO 5] OR -price:{9 TO *])",
+	}
+	for in, want := range cases {
+		// Goal: Shorthands are expanded and other clauses are kept.
+		got, rewrites := ExpandRangeShorthands(in, testFieldKinds)
+		assert.Equal(t, want, got, in)
+		assert.NotEmpty(t, rewrites, in)
+	}
+
+	// Goal: Queries without shorthands are returned unchanged.
+	got, rewrites := ExpandRangeShorthands(`level:ERROR AND price:[1 TO 5] AND title:"a..b"`, testFieldKinds)
+	assert.Equal(t, `level:ERROR AND price:[1 TO 5] AND title:"a..b"`, got)
+	assert.Nil(t, rewrites)
+
+	// Goal: Shorthand-like values of text and unknown fields, e.g. versions and paths, are left to Solr.
+	for _, in := range []string{"version:1.2..1.3", "path:../etc", "title:>100", "status:today", "other:last7d"} {
+		got, rewrites := ExpandRangeShorthands(in, testFieldKinds)
+		assert.Equal(t, in, got, in)
+		assert.Nil(t, rewrites, in)
+	}
+
+	// Goal: Malformed shorthands and bounds of the wrong type are left unchanged instead of failing the query.
+	for _, in := range []string{"price:200..100", "price:..", "price:abc..def", "date:2024-02-01..2024-01-01", "price:1..2024-01-01", "date:1..5", "timestamp:last0d", "price:last7d", "price:>abc"} {
+		got, rewrites := ExpandRangeShorthands(in, testFieldKinds)
+		assert.Equal(t, in, got, in)
+		assert.Nil(t, rewrites, in)
+	}
+}
+
+// TestRangeFieldKind tests the classification of schema fields for range shorthands.
+func TestRangeFieldKind(t *testing.T) {
+	fc := &types.FieldCatalog{All: []types.SolrField{{Name: "price", Type: "pfloat"}, {Name: "timestamp", Type: "pdate"}, {Name: "title", Type: "text_general"}}}
+
+	// Goal: Numeric and date fields take shorthands; text and unknown fields do not.
+	assert.Equal(t, "numeric", RangeFieldKind(fc, "price"))
+	assert.Equal(t, "date", RangeFieldKind(fc, "timestamp"))
+	assert.Equal(t, "", RangeFieldKind(fc, "title"))
+	assert.Equal(t, "", RangeFieldKind(fc, "missing"))
+}
+
+// TestExpandQueryShorthands tests that the query and every filter query are expanded.
+func TestExpandQueryShorthands(t *testing.T) {
+	fq := []string{"price:1..5", "level:ERROR"}
+	in := types.QueryIn{Query: "timestamp:last1h", FilterQuery: fq}
+
+	// Goal: Rewrites are reported in order and the caller's filter slice is not modified.
+	rewrites := ExpandQueryShorthands(&in, testFieldKinds)
+	assert.Equal(t, "timestamp:[NOW-1HOURS TO NOW]", in.Query)
+	assert.Equal(t, []string{"price:[1 TO 5]", "level:ERROR"}, in.FilterQuery)
+	assert.Equal(t, []Rewrite{
+		{From: "timestamp:last1h", To: "timestamp:[NOW-1HOURS TO NOW]"},
+		{From: "price:1..5", To: "price:[1 TO 5]"},
+	}, rewrites)
+	assert.Equal(t, "price:1..5", fq[0])
+
+	// Goal: Field kinds are only looked up for clauses that look like shorthands.
+	var looked []string
+	ExpandQueryShorthands(&types.QueryIn{Query: "level:ERROR AND price:1..5"}, func(field string) string {
+		looked = append(looked, field)
+		return ""
+	})
+	assert.Equal(t, []string{"price"}, looked)
+}
@@ -0,0 +1,174 @@
+package solr
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// NodeMetrics is a snapshot of the JVM and Jetty metrics of a Solr node that explain slow requests.
+type NodeMetrics struct {
+	Time           time.Time `json:"time"`
+	HeapUsed       int64     `json:"heapUsed"`
+	HeapMax        int64     `json:"heapMax"`
+	HeapUsage      float64   `json:"heapUsage"`
+	GCCount        int64     `json:"gcCount"`  // collections since the JVM started, all collectors
+	GCTimeMs       int64     `json:"gcTimeMs"` // time spent in collections since the JVM started
+	Threads        int64     `json:"threads"`
+	ThreadsBlocked int64     `json:"threadsBlocked"`
+	ProcessCPULoad float64   `json:"processCpuLoad"`
+	SystemLoad     float64   `json:"systemLoadAverage"`
+	Processors     int64     `json:"processors"`
+	// QueuedRequests are requests waiting for a Jetty thread, PoolUtilization the busy share of its threads.
+	QueuedRequests  int64   `json:"queuedRequests"`
+	PoolUtilization float64 `json:"poolUtilization"`
+}
+
+// nodeMetricPrefixes are the metric names read by FetchNodeMetrics.
+var nodeMetricPrefixes = []string{
+	"memory.heap.", "gc.", "threads.count", "threads.blocked.count",
+	"os.processCpuLoad", "os.systemLoadAverage", "os.availableProcessors",
+	"org.eclipse.jetty.util.thread.QueuedThreadPool.",
+}
+
+// FetchNodeMetrics reads the heap, GC, thread and Jetty thread pool metrics of the node at baseURL.
+func FetchNodeMetrics(ctx context.Context, httpClient *http.Client, baseURL, user, pass string) (NodeMetrics, error) {
+	q := url.Values{"group": {"jvm,jetty"}, "prefix": {strings.Join(nodeMetricPrefixes, ",")}, "wt": {"json"}}
+	var resp struct {
+		Metrics map[string]map[string]any `json:"metrics"`
+	}
+	if err := getJSON(ctx, httpClient, user, pass, baseURL+"/solr/admin/metrics?"+q.Encode(), &resp, nil); err != nil {
+		return NodeMetrics{}, fmt.Errorf("node metrics: %v", err)
+	}
+	m := NodeMetrics{Time: time.Now()}
+	for k, v := range resp.Metrics["solr.jvm"] {
+		f, ok := metricValue(v)
+		if !ok {
+			continue
+		}
+		switch {
+		case k == "memory.heap.used":
+			m.HeapUsed = int64(f)
+		case k == "memory.heap.max":
+			m.HeapMax = int64(f)
+		case k == "memory.heap.usage":
+			m.HeapUsage = f
+		case strings.HasPrefix(k, "gc.") && strings.HasSuffix(k, ".count"):
+			m.GCCount += int64(f)
+		case strings.HasPrefix(k, "gc.") && strings.HasSuffix(k, ".time"):
+			m.GCTimeMs += int64(f)
+		case k == "threads.count":
+			m.Threads = int64(f)
+		case k == "threads.blocked.count":
+			m.ThreadsBlocked = int64(f)
+		case k == "os.processCpuLoad":
+			m.ProcessCPULoad = f
+		case k == "os.systemLoadAverage":
+			m.SystemLoad = f
+		case k == "os.availableProcessors":
+			m.Processors = int64(f)
+		}
+	}
+	for k, v := range resp.Metrics["solr.jetty"] {
+		f, ok := metricValue(v)
+		if !ok {
+			continue
+		}
+		switch {
+		case strings.HasSuffix(k, ".jobs"):
+			m.QueuedRequests += int64(f)
+		case strings.HasSuffix(k, ".utilization"):
+			m.PoolUtilization = max(m.PoolUtilization, f)
+		}
+	}
+	if m.HeapUsage == 0 && m.HeapMax > 0 {
+		m.HeapUsage = float64(m.HeapUsed) / float64(m.HeapMax)
+	}
+	return m, nil
+}
+
+// metricValue returns a gauge or counter value, which Solr returns as a number or as {"value": n}.
+func metricValue(v any) (float64, bool) {
+	switch v := v.(type) {
+	case float64:
+		return v, true
+	case map[string]any:
+		f, ok := v["value"].(float64)
+		return f, ok
+	}
+	return 0, false
+}
+
+// SlowQueryCause is a possible reason for a slow query, with the evidence found.
+type SlowQueryCause struct {
+	Cause    string `json:"cause"`
+	Evidence string `json:"evidence"`
+}
+
+// Thresholds of SlowQueryCauses.
+const (
+	heapPressure     = 0.85 // heap usage
+	gcShare          = 0.10 // share of wall time spent in GC between two snapshots
+	cpuSaturation    = 0.90 // process CPU load
+	loadPerProcessor = 1.5  // system load average per processor
+	poolSaturation   = 0.90 // Jetty thread pool utilization
+	blockedThreads   = 10
+	deepPaging       = 10000 // start + rows
+	manyRows         = 1000
+)
+
+// SlowQueryCauses analyzes a node snapshot taken after a slow query with the request params. GC is only judged
+// when a baseline snapshot of the same node, taken earlier, gives a time window.
+func SlowQueryCauses(now NodeMetrics, baseline *NodeMetrics, params url.Values) []SlowQueryCause {
+	var causes []SlowQueryCause
+	add := func(cause, format string, args ...any) {
+		causes = append(causes, SlowQueryCause{Cause: cause, Evidence: fmt.Sprintf(format, args...)})
+	}
+	if baseline != nil && now.Time.After(baseline.Time) && now.GCTimeMs >= baseline.GCTimeMs {
+		window := now.Time.Sub(baseline.Time)
+		gcTime := time.Duration(now.GCTimeMs-baseline.GCTimeMs) * time.Millisecond
+		if share := gcTime.Seconds() / window.Seconds(); share >= gcShare {
+			add("gc_pauses", "garbage collection took %.0f%% of the last %s (%s in %d collections)",
+				share*100, window.Round(time.Second), gcTime.Round(time.Millisecond), now.GCCount-baseline.GCCount)
+		}
+	}
+	if now.HeapUsage >= heapPressure {
+		add("heap_pressure", "the heap is %.0f%% full (%s of %s), which causes frequent collections",
+			now.HeapUsage*100, formatBytes(now.HeapUsed), formatBytes(now.HeapMax))
+	}
+	if now.QueuedRequests > 0 {
+		add("request_queueing", "%d requests were waiting for a Jetty thread", now.QueuedRequests)
+	}
+	if now.PoolUtilization >= poolSaturation {
+		add("thread_pool_saturated", "%.0f%% of the Jetty request threads were busy", now.PoolUtilization*100)
+	}
+	if now.ThreadsBlocked >= blockedThreads {
+		add("blocked_threads", "%d of %d JVM threads were blocked on locks", now.ThreadsBlocked, now.Threads)
+	}
+	if now.ProcessCPULoad >= cpuSaturation {
+		add("cpu_saturation", "the Solr process used %.0f%% of the CPU", now.ProcessCPULoad*100)
+	}
+	if now.Processors > 0 && now.SystemLoad/float64(now.Processors) >= loadPerProcessor {
+		add("high_system_load", "the load average was %.1f on %d processors", now.SystemLoad, now.Processors)
+	}
+	start, _ := strconv.Atoi(params.Get("start"))
+	rows, _ := strconv.Atoi(params.Get("rows"))
+	if start+rows >= deepPaging && params.Get("cursorMark") == "" {
+		add("deep_paging", "start=%d and rows=%d make every shard sort %d documents; use cursorMark to page deeply", start, rows, start+rows)
+	} else if rows >= manyRows {
+		add("many_rows", "rows=%d fetches and serializes many stored documents", rows)
+	}
+	return causes
+}
+
+func formatBytes(n int64) string {
+	const gib, mib = 1 << 30, 1 << 20
+	if n >= gib {
+		return fmt.Sprintf("%.1f GiB", float64(n)/gib)
+	}
+	return fmt.Sprintf("%.0f MiB", float64(n)/mib)
+}
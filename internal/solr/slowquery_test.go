@@ -0,0 +1,59 @@
+package solr
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestFetchNodeMetrics tests reading the JVM and Jetty metrics of a node.
+func TestFetchNodeMetrics(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/solr/admin/metrics", r.URL.Path)
+		assert.Equal(t, "jvm,jetty", r.URL.Query().Get("group"))
+		fmt.Fprint(w, `{"metrics":{
+			"solr.jvm":{"memory.heap.used":900,"memory.heap.max":1000,"gc.G1-Young-Generation.count":10,"gc.G1-Young-Generation.time":300,
+				"gc.G1-Old-Generation.count":1,"gc.G1-Old-Generation.time":200,"threads.count":80,"threads.blocked.count":2,
+				"os.processCpuLoad":0.4,"os.systemLoadAverage":3.5,"os.availableProcessors":4},
+			"solr.jetty":{"org.eclipse.jetty.util.thread.QueuedThreadPool.qtp1.jobs":{"value":3},"org.eclipse.jetty.util.thread.QueuedThreadPool.qtp1.utilization":{"value":0.95}}}}`)
+	}))
+	defer server.Close()
+
+	m, err := FetchNodeMetrics(context.Background(), server.Client(), server.URL, "", "")
+	require.NoError(t, err)
+	m.Time = time.Time{}
+	assert.Equal(t, NodeMetrics{
+		HeapUsed: 900, HeapMax: 1000, HeapUsage: 0.9, GCCount: 11, GCTimeMs: 500, Threads: 80, ThreadsBlocked: 2,
+		ProcessCPULoad: 0.4, SystemLoad: 3.5, Processors: 4, QueuedRequests: 3, PoolUtilization: 0.95,
+	}, m)
+}
+
+// TestSlowQueryCauses tests the analysis of node metrics and request parameters.
+func TestSlowQueryCauses(t *testing.T) {
+	at := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	baseline := NodeMetrics{Time: at, GCCount: 100, GCTimeMs: 1000, HeapUsage: 0.5}
+	now := NodeMetrics{Time: at.Add(30 * time.Second), GCCount: 112, GCTimeMs: 7000, HeapUsed: 7 << 30, HeapMax: 8 << 30, HeapUsage: 0.875, Processors: 4, SystemLoad: 2}
+
+	// Goal: GC over the window since the baseline and heap usage are reported.
+	causes := SlowQueryCauses(now, &baseline, url.Values{"rows": {"10"}})
+	assert.Equal(t, []SlowQueryCause{
+		{Cause: "gc_pauses", Evidence: "garbage collection took 20% of the last 30s (6s in 12 collections)"},
+		{Cause: "heap_pressure", Evidence: "the heap is 88% full (7.0 GiB of 8.0 GiB), which causes frequent collections"},
+	}, causes)
+
+	// Goal: Without a baseline GC is not judged, and request shapes are reported.
+	causes = SlowQueryCauses(NodeMetrics{Time: at, QueuedRequests: 4}, nil, url.Values{"start": {"20000"}, "rows": {"50"}})
+	require.Len(t, causes, 2)
+	assert.Equal(t, "request_queueing", causes[0].Cause)
+	assert.Equal(t, "deep_paging", causes[1].Cause)
+
+	// Goal: A healthy node and a small request have no causes.
+	assert.Empty(t, SlowQueryCauses(NodeMetrics{Time: at, Processors: 8, SystemLoad: 1}, nil, url.Values{}))
+}
@@ -0,0 +1,159 @@
+package solr
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/types"
+)
+
+// FilterSuggestion is a likely correction of a filter query that matched nothing.
+type FilterSuggestion struct {
+	Filter     string `json:"filter"`
+	Suggestion string `json:"suggestion"`
+	Reason     string `json:"reason"`
+}
+
+// TopValuesLimit is how many values of a field are fetched. Fields with more distinct values are
+// not low-cardinality and their values are not corrected.
+const TopValuesLimit = 200
+
+// TopValuesParams returns /select parameters counting the most frequent values of field.
+func TopValuesParams(field string) url.Values {
+	return url.Values{
+		"q":              {"*:*"},
+		"rows":           {"0"},
+		"facet":          {"true"},
+		"facet.field":    {field},
+		"facet.limit":    {strconv.Itoa(TopValuesLimit + 1)},
+		"facet.mincount": {"1"},
+		"wt":             {"json"},
+	}
+}
+
+// ParseTopValues reads the facet values of field from a response to TopValuesParams.
+// lowCardinality is false when the field has more than TopValuesLimit distinct values.
+func ParseTopValues(resp map[string]any, field string) (values []string, lowCardinality bool) {
+	counts, _ := resp["facet_counts"].(map[string]any)
+	fields, _ := counts["facet_fields"].(map[string]any)
+	pairs, _ := fields[field].([]any)
+	for i := 0; i+1 < len(pairs); i += 2 {
+		values = append(values, fmt.Sprintf("%v", pairs[i]))
+	}
+	return values, len(values) <= TopValuesLimit
+}
+
+// ParseFieldFilter splits a simple field:value filter. Filters with operators, ranges, wildcards,
+// local parameters or groups are not simple.
+func ParseFieldFilter(fq string) (field, value string, ok bool) {
+	fq = strings.TrimSpace(fq)
+	field, value, ok = strings.Cut(fq, ":")
+	if !ok || field == "" || value == "" || strings.ContainsAny(field, ` {}()+-!"\`) {
+		return "", "", false
+	}
+	if unquoted, err := strconv.Unquote(value); err == nil {
+		return field, unquoted, true
+	}
+	if strings.ContainsAny(value, ` *?[]{}()"\~^`) {
+		return "", "", false
+	}
+	return field, value, true
+}
+
+// SuggestFilterCorrections looks for misspelled field names and values in simple field:value filters.
+// Field names are checked against fc; values against the top values of low-cardinality fields.
+func SuggestFilterCorrections(filters []string, fc *types.FieldCatalog, topValues func(field string) ([]string, bool)) []FilterSuggestion {
+	var out []FilterSuggestion
+	for _, fq := range filters {
+		field, value, ok := ParseFieldFilter(fq)
+		if !ok {
+			continue
+		}
+		if fc != nil && !fc.HasField(field) {
+			names := make([]string, 0, len(fc.All))
+			for _, f := range fc.All {
+				names = append(names, f.Name)
+			}
+			if match, ok := closest(field, names); ok {
+				out = append(out, FilterSuggestion{
+					Filter:     fq,
+					Suggestion: match + ":" + quoteValue(value),
+					Reason:     fmt.Sprintf("field %s does not exist; did you mean %s?", field, match),
+				})
+			}
+			continue
+		}
+		values, low := topValues(field)
+		if !low || len(values) == 0 {
+			continue
+		}
+		for _, v := range values {
+			if v == value {
+				// the value exists, so the other filters cause the empty result
+				values = nil
+				break
+			}
+		}
+		if match, ok := closest(value, values); ok {
+			out = append(out, FilterSuggestion{
+				Filter:     fq,
+				Suggestion: field + ":" + quoteValue(match),
+				Reason:     fmt.Sprintf("%s has no value %q; did you mean %q?", field, value, match),
+			})
+		}
+	}
+	return out
+}
+
+func quoteValue(v string) string {
+	if strings.ContainsAny(v, ` :*?[]{}()"\~^+-!/&|`) {
+		return strconv.Quote(v)
+	}
+	return v
+}
+
+// closest returns the candidate nearest to s, ignoring case. Case-only differences always match;
+// otherwise at most one edit is allowed for short words and two for words of 6 or more characters.
+func closest(s string, candidates []string) (string, bool) {
+	maxDist := 1
+	if len(s) >= 6 {
+		maxDist = 2
+	}
+	best, bestDist := "", maxDist+1
+	lower := strings.ToLower(s)
+	for _, c := range candidates {
+		d := editDistance(lower, strings.ToLower(c))
+		if d < bestDist {
+			best, bestDist = c, d
+		}
+	}
+	return best, bestDist <= maxDist
+}
+
+// editDistance is the Damerau-Levenshtein distance (with adjacent transpositions) between a and b.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev2 := make([]int, len(rb)+1)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				cur[j] = min(cur[j], prev2[j-2]+1)
+			}
+		}
+		prev2, prev, cur = prev, cur, prev2
+	}
+	return prev[len(rb)]
+}
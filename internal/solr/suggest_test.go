@@ -0,0 +1,59 @@
+package solr
+
+import (
+	"testing"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/types"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestSuggestFilterCorrections tests suggestions for misspelled field names and values.
+func TestSuggestFilterCorrections(t *testing.T) {
+	fc := &types.FieldCatalog{All: []types.SolrField{{Name: "service"}, {Name: "level"}, {Name: "message"}}}
+	topValues := func(field string) ([]string, bool) {
+		switch field {
+		case "level":
+			return []string{"ERROR", "WARN", "INFO"}, true
+		case "message":
+			return []string{"timeout"}, false
+		}
+		return []string{"api", "web"}, true
+	}
+	filters := []string{"serivce:api", "level:ERORR", "level:error", `level:"WARN"`, "message:timeot", "level:[* TO *]", "level:DEBUG"}
+
+	// Goal: Field names are corrected against the schema and values against the top values of low-cardinality fields.
+	assert.Equal(t, []FilterSuggestion{
+		{Filter: "serivce:api", Suggestion: "service:api", Reason: "field serivce does not exist; did you mean service?"},
+		{Filter: "level:ERORR", Suggestion: "level:ERROR", Reason: `level has no value "ERORR"; did you mean "ERROR"?`},
+		{Filter: "level:error", Suggestion: "level:ERROR", Reason: `level has no value "error"; did you mean "ERROR"?`},
+	}, SuggestFilterCorrections(filters, fc, topValues))
+
+	// Goal: Field names are not checked without a schema.
+	assert.Empty(t, SuggestFilterCorrections([]string{"serivce:api"}, nil, func(string) ([]string, bool) { return nil, false }))
+}
+
+// TestParseFieldFilter tests which filters are simple enough to correct.
+func TestParseFieldFilter(t *testing.T) {
+	field, value, ok := ParseFieldFilter(`status:"in progress"`)
+	assert.True(t, ok)
+	assert.Equal(t, "status", field)
+	assert.Equal(t, "in progress", value)
+
+	for _, fq := range []string{"a:b AND c:d", "{!tag=x}a:b", "a:[1 TO 2]", "a:b*", "-a:b", "nofield"} {
+		_, _, ok := ParseFieldFilter(fq)
+		assert.False(t, ok, fq)
+	}
+
+	// Goal: Transpositions count as one edit.
+	assert.Equal(t, 1, editDistance("erorr", "error"))
+	assert.Equal(t, 3, editDistance("kitten", "sitting"))
+}
+
+// TestParseTopValues tests reading facet values and the low-cardinality check.
+func TestParseTopValues(t *testing.T) {
+	resp := map[string]any{"facet_counts": map[string]any{"facet_fields": map[string]any{"level": []any{"ERROR", 10.0, "INFO", 3.0}}}}
+	values, low := ParseTopValues(resp, "level")
+	assert.Equal(t, []string{"ERROR", "INFO"}, values)
+	assert.True(t, low)
+}
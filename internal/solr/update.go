@@ -0,0 +1,145 @@
+package solr
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/backend"
+
+	solr_sdk "github.com/stevenferrer/solr-go"
+)
+
+// CountDocuments returns the numFound of a query.
+func CountDocuments(ctx context.Context, httpClient *http.Client, baseURL, user, pass, collection, query string) (int64, error) {
+	resp, err := QueryWithRawResponse(ctx, httpClient, baseURL, user, pass, collection, solr_sdk.NewQuery(query).Params(solr_sdk.M{"rows": 0}))
+	if err != nil {
+		return 0, err
+	}
+	respObj, _ := resp["response"].(map[string]any)
+	numFound, ok := respObj["numFound"].(float64)
+	if !ok {
+		return 0, fmt.Errorf("numFound not found in response")
+	}
+	return int64(numFound), nil
+}
+
+// DeleteByQuery deletes the documents matching query and commits.
+func DeleteByQuery(ctx context.Context, httpClient *http.Client, baseURL, user, pass, collection, query string) error {
+	return postUpdate(ctx, httpClient, baseURL, user, pass, collection, map[string]any{"delete": map[string]any{"query": query}}, true)
+}
+
+// DeleteByIDs deletes the documents with the given uniqueKey values and commits.
+func DeleteByIDs(ctx context.Context, httpClient *http.Client, baseURL, user, pass, collection string, ids []string) error {
+	return postUpdate(ctx, httpClient, baseURL, user, pass, collection, map[string]any{"delete": ids}, true)
+}
+
+// IDsQuery returns a query matching the documents with the given uniqueKey values.
+// The terms parser has no escaping, so a separator that does not occur in any ID is chosen.
+func IDsQuery(uniqueKey string, ids []string) string {
+	joined := strings.Join(ids, "")
+	for _, sep := range []string{",", "|", ";", "~", "^"} {
+		if !strings.Contains(joined, sep) {
+			if sep == "," {
+				return fmt.Sprintf("{!terms f=%s}%s", uniqueKey, strings.Join(ids, sep))
+			}
+			return fmt.Sprintf("{!terms f=%s separator='%s'}%s", uniqueKey, sep, strings.Join(ids, sep))
+		}
+	}
+	// Fall back to a boolean query of quoted terms
+	clauses := make([]string, len(ids))
+	for i, id := range ids {
+		clauses[i] = fmt.Sprintf("%s:%q", uniqueKey, id)
+	}
+	return strings.Join(clauses, " OR ")
+}
+
+// SampleIDs returns up to n uniqueKey values of the documents matching query.
+func SampleIDs(ctx context.Context, b backend.Querier, collection, query, uniqueKey string, n int) ([]string, error) {
+	q := solr_sdk.NewQuery(query).Fields(uniqueKey).Params(solr_sdk.M{"rows": n})
+	resp, err := b.Query(ctx, collection, QueryValues(q))
+	if err != nil {
+		return nil, err
+	}
+	return ExtractIDs(resp, uniqueKey), nil
+}
+
+// existingIDsBatchSize is the number of IDs looked up per query by ExistingIDs.
+const existingIDsBatchSize = 500
+
+// ExistingIDs returns the subset of ids that already exist in the collection.
+func ExistingIDs(ctx context.Context, b backend.Querier, collection, uniqueKey string, ids []string) ([]string, error) {
+	existing := []string{}
+	for start := 0; start < len(ids); start += existingIDsBatchSize {
+		batch := ids[start:min(start+existingIDsBatchSize, len(ids))]
+		q := solr_sdk.NewQuery(IDsQuery(uniqueKey, batch)).Fields(uniqueKey).Params(solr_sdk.M{"rows": len(batch)})
+		resp, err := b.Query(ctx, collection, QueryValues(q))
+		if err != nil {
+			return nil, err
+		}
+		existing = append(existing, ExtractIDs(resp, uniqueKey)...)
+	}
+	return existing, nil
+}
+
+// DocumentID returns the uniqueKey value of a document as a string.
+func DocumentID(doc map[string]any, uniqueKey string) (string, bool) {
+	switch v := doc[uniqueKey].(type) {
+	case string:
+		return v, v != ""
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	case json.Number:
+		return v.String(), true
+	default:
+		return "", false
+	}
+}
+
+// AddDocuments adds or overwrites documents and commits.
+func AddDocuments(ctx context.Context, httpClient *http.Client, baseURL, user, pass, collection string, docs []map[string]any) error {
+	return postUpdate(ctx, httpClient, baseURL, user, pass, collection, docs, true)
+}
+
+// postUpdate sends a JSON update command to the collection, optionally committing it.
+func postUpdate(ctx context.Context, httpClient *http.Client, baseURL, user, pass, collection string, body any, commit bool) error {
+	u := fmt.Sprintf("%s/solr/%s/update?commit=%t&wt=json", baseURL, url.PathEscape(collection), commit)
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("marshal update: %v", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("create request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if user != "" {
+		req.SetBasicAuth(user, pass)
+	}
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("HTTP request error: %v", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		bodyBytes, _ := io.ReadAll(res.Body)
+		return fmt.Errorf("HTTP status %d: %s", res.StatusCode, string(bodyBytes))
+	}
+	_, _ = io.Copy(io.Discard, res.Body)
+	return nil
+}
+
+// DeleteCollection deletes a collection with the Collections API DELETE action.
+func DeleteCollection(ctx context.Context, httpClient *http.Client, baseURL, user, pass, name string) error {
+	u := fmt.Sprintf("%s/solr/admin/collections?action=DELETE&name=%s&wt=json", baseURL, url.QueryEscape(name))
+	if err := getJSON(ctx, httpClient, user, pass, u, nil, nil); err != nil {
+		return fmt.Errorf("failed to delete collection %s: %v", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package solr
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestUpdateHelpers tests CountDocuments, DeleteByQuery and DeleteCollection against a mock Solr.
+func TestUpdateHelpers(t *testing.T) {
+	var updateBody, deleted string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/solr/logs/select":
+			assert.Equal(t, "0", r.URL.Query().Get("rows"))
+			fmt.Fprintln(w, `{"response":{"numFound":42,"docs":[]}}`)
+		case "/solr/logs/update":
+			assert.Equal(t, "true", r.URL.Query().Get("commit"))
+			body, _ := io.ReadAll(r.Body)
+			updateBody = string(body)
+			fmt.Fprintln(w, `{"responseHeader":{"status":0}}`)
+		case "/solr/admin/collections":
+			deleted = r.URL.Query().Get("name")
+			fmt.Fprintln(w, `{"responseHeader":{"status":0}}`)
+		default:
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	ctx := context.Background()
+
+	// Goal: numFound of a query is returned.
+	n, err := CountDocuments(ctx, server.Client(), server.URL, "", "", "logs", "level:DEBUG")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(42), n)
+
+	// Goal: Delete-by-query posts a JSON delete command with commit.
+	assert.NoError(t, DeleteByQuery(ctx, server.Client(), server.URL, "", "", "logs", "level:DEBUG"))
+	assert.JSONEq(t, `{"delete":{"query":"level:DEBUG"}}`, updateBody)
+
+	// Goal: Delete-by-IDs posts the IDs as a JSON delete command.
+	assert.NoError(t, DeleteByIDs(ctx, server.Client(), server.URL, "", "", "logs", []string{"a", "b"}))
+	assert.JSONEq(t, `{"delete":["a","b"]}`, updateBody)
+
+	// Goal: Collections are deleted by name and errors are propagated.
+	assert.NoError(t, DeleteCollection(ctx, server.Client(), server.URL, "", "", "logs_2024-01-01"))
+	assert.Equal(t, "logs_2024-01-01", deleted)
+	assert.Error(t, DeleteByQuery(ctx, server.Client(), server.URL, "", "", "missing", "*:*"))
+}
+
+// TestIDsQuery tests that IDsQuery picks a separator that does not occur in the IDs.
+func TestIDsQuery(t *testing.T) {
+	// Goal: The default comma separator is used when possible.
+	assert.Equal(t, "{!terms f=id}a,b", IDsQuery("id", []string{"a", "b"}))
+
+	// Goal: Another separator is used when an ID contains a comma.
+	assert.Equal(t, "{!terms f=id separator='|'}a,1|b", IDsQuery("id", []string{"a,1", "b"}))
+
+	// Goal: Quoted clauses are used when every separator occurs in the IDs.
+	assert.Equal(t, `id:",|;~^" OR id:"b"`, IDsQuery("id", []string{",|;~^", "b"}))
+}
+
+// TestExistingIDs tests that ExistingIDs looks up the IDs with a terms query.
+func TestExistingIDs(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "{!terms f=id}a,b,c", r.URL.Query().Get("q"))
+		assert.Equal(t, "3", r.URL.Query().Get("rows"))
+		fmt.Fprintln(w, `{"response":{"numFound":1,"docs":[{"id":"b"}]}}`)
+	}))
+	defer server.Close()
+
+	// Goal: Only the IDs returned by Solr are reported as existing.
+	b := &HTTPBackend{HttpClient: server.Client(), BaseURL: server.URL}
+	ids, err := ExistingIDs(context.Background(), b, "logs", "id", []string{"a", "b", "c"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"b"}, ids)
+}
+
+// TestDocumentID tests the conversion of uniqueKey values to strings.
+func TestDocumentID(t *testing.T) {
+	// Goal: Strings and JSON numbers are supported; missing and empty values are not.
+	id, ok := DocumentID(map[string]any{"id": "a"}, "id")
+	assert.True(t, ok)
+	assert.Equal(t, "a", id)
+	id, ok = DocumentID(map[string]any{"id": float64(12)}, "id")
+	assert.True(t, ok)
+	assert.Equal(t, "12", id)
+	_, ok = DocumentID(map[string]any{"id": ""}, "id")
+	assert.False(t, ok)
+	_, ok = DocumentID(map[string]any{"name": "a"}, "id")
+	assert.False(t, ok)
+}
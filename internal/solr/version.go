@@ -0,0 +1,120 @@
+package solr
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/config"
+)
+
+// Version is a Solr release version and the mode the node runs in.
+type Version struct {
+	Major int    `json:"major"`
+	Minor int    `json:"minor"`
+	Patch int    `json:"patch"`
+	Raw   string `json:"version"`
+	Mode  string `json:"mode"` // "solrcloud" or "std"
+}
+
+// ParseVersion parses a solr-spec-version such as "9.4.1" or "8.11.2-SNAPSHOT".
+func ParseVersion(s string) (Version, error) {
+	v := Version{Raw: s}
+	core, _, _ := strings.Cut(strings.TrimSpace(s), "-")
+	parts := strings.Split(core, ".")
+	nums := []*int{&v.Major, &v.Minor, &v.Patch}
+	for i, p := range parts {
+		if i >= len(nums) {
+			break
+		}
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			return Version{}, fmt.Errorf("invalid Solr version %q", s)
+		}
+		*nums[i] = n
+	}
+	return v, nil
+}
+
+// AtLeast reports whether v is major.minor or later.
+func (v Version) AtLeast(major, minor int) bool {
+	return v.Major > major || (v.Major == major && v.Minor >= minor)
+}
+
+// Cloud reports whether the node runs in SolrCloud mode.
+func (v Version) Cloud() bool {
+	return v.Mode == "solrcloud"
+}
+
+func (v Version) String() string {
+	return v.Raw
+}
+
+// GetVersion reads the version and mode from the system info handler.
+func GetVersion(ctx context.Context, httpClient *http.Client, baseURL, user, pass string) (Version, error) {
+	var info struct {
+		Mode   string `json:"mode"`
+		Lucene struct {
+			SolrSpecVersion string `json:"solr-spec-version"`
+		} `json:"lucene"`
+	}
+	u := baseURL + "/solr/admin/info/system?wt=json"
+	if err := getJSON(ctx, httpClient, user, pass, u, &info, nil); err != nil {
+		return Version{}, fmt.Errorf("system info request: %v", err)
+	}
+	v, err := ParseVersion(info.Lucene.SolrSpecVersion)
+	if err != nil {
+		return Version{}, err
+	}
+	v.Mode = info.Mode
+	return v, nil
+}
+
+// DeriveHealth fills in the health of collections and shards where CLUSTERSTATUS does not report it
+// (before Solr 8.1): GREEN when all replicas are active, YELLOW when every shard has an active replica,
+// and RED otherwise.
+func DeriveHealth(status *config.ClusterStatusResponse) {
+	for name, coll := range status.Cluster.Collections {
+		if coll.Health != "" {
+			continue
+		}
+		collHealth := "GREEN"
+		for shardName, shard := range coll.Shards {
+			active := 0
+			for _, r := range shard.Replicas {
+				if r.State == "active" && liveNode(status.Cluster.LiveNodes, r.NodeName) {
+					active++
+				}
+			}
+			switch {
+			case len(shard.Replicas) > 0 && active == len(shard.Replicas):
+				shard.Health = "GREEN"
+			case active > 0:
+				shard.Health = "YELLOW"
+			default:
+				shard.Health = "RED"
+			}
+			coll.Shards[shardName] = shard
+			if shard.Health == "RED" || (shard.Health == "YELLOW" && collHealth == "GREEN") {
+				collHealth = shard.Health
+			}
+		}
+		coll.Health = collHealth
+		status.Cluster.Collections[name] = coll
+	}
+}
+
+// liveNode reports whether node is live. Replicas without a node name count as live.
+func liveNode(liveNodes []string, node string) bool {
+	if node == "" || liveNodes == nil {
+		return true
+	}
+	for _, n := range liveNodes {
+		if n == node {
+			return true
+		}
+	}
+	return false
+}
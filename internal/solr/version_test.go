@@ -0,0 +1,79 @@
+package solr
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestParseVersion tests parsing of solr-spec-version strings.
+func TestParseVersion(t *testing.T) {
+	v, err := ParseVersion("8.11.2-SNAPSHOT")
+	require.NoError(t, err)
+	assert.Equal(t, 8, v.Major)
+	assert.Equal(t, 11, v.Minor)
+	assert.Equal(t, 2, v.Patch)
+	assert.Equal(t, "8.11.2-SNAPSHOT", v.String())
+
+	// Goal: Versions compare by major and minor.
+	assert.True(t, v.AtLeast(8, 1))
+	assert.False(t, v.AtLeast(9, 0))
+	_, err = ParseVersion("nine")
+	assert.Error(t, err)
+}
+
+// TestGetVersion tests reading the version and mode from the system info handler.
+func TestGetVersion(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/solr/admin/info/system", r.URL.Path)
+		fmt.Fprintln(w, `{"mode":"std","lucene":{"solr-spec-version":"7.7.3","lucene-spec-version":"7.7.3"}}`)
+	}))
+	defer server.Close()
+
+	v, err := GetVersion(context.Background(), server.Client(), server.URL, "", "")
+	require.NoError(t, err)
+	assert.Equal(t, 7, v.Major)
+	assert.False(t, v.Cloud())
+}
+
+// TestDeriveHealth tests the health derived for CLUSTERSTATUS responses of Solr before 8.1.
+func TestDeriveHealth(t *testing.T) {
+	replica := func(state, node string) config.ReplicaInfo {
+		return config.ReplicaInfo{State: state, NodeName: node}
+	}
+	status := &config.ClusterStatusResponse{Cluster: config.ClusterInfo{
+		LiveNodes: []string{"n1", "n2"},
+		Collections: map[string]config.CollectionStatus{
+			"green": {Shards: map[string]config.ShardInfo{
+				"shard1": {Replicas: map[string]config.ReplicaInfo{"r1": replica("active", "n1"), "r2": replica("active", "n2")}},
+			}},
+			"yellow": {Shards: map[string]config.ShardInfo{
+				"shard1": {Replicas: map[string]config.ReplicaInfo{"r1": replica("active", "n1"), "r2": replica("down", "n2")}},
+				"shard2": {Replicas: map[string]config.ReplicaInfo{"r1": replica("active", "n1")}},
+			}},
+			"red": {Shards: map[string]config.ShardInfo{
+				"shard1": {Replicas: map[string]config.ReplicaInfo{"r1": replica("active", "n3")}},
+				"shard2": {Replicas: map[string]config.ReplicaInfo{"r1": replica("active", "n1"), "r2": replica("recovering", "n2")}},
+			}},
+			"reported": {Health: "ORANGE"},
+		},
+	}}
+
+	DeriveHealth(status)
+
+	// Goal: Health is derived from replica states and live nodes; reported health is kept.
+	colls := status.Cluster.Collections
+	assert.Equal(t, "GREEN", colls["green"].Health)
+	assert.Equal(t, "YELLOW", colls["yellow"].Health)
+	assert.Equal(t, "YELLOW", colls["yellow"].Shards["shard1"].Health)
+	assert.Equal(t, "GREEN", colls["yellow"].Shards["shard2"].Health)
+	assert.Equal(t, "RED", colls["red"].Health)
+	assert.Equal(t, "ORANGE", colls["reported"].Health)
+}
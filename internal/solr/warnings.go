@@ -0,0 +1,64 @@
+package solr
+
+import (
+	"fmt"
+	"sort"
+)
+
+// ExtractWarnings collects the warnings Solr reports in a raw response, e.g. for deprecated
+// parameters or partial results. Both the responseHeader and the top level are checked,
+// since Solr versions and handlers differ in where they put them.
+func ExtractWarnings(resp map[string]any) []string {
+	var warnings []string
+	seen := map[string]bool{}
+	add := func(v any) {
+		for _, w := range warningStrings(v) {
+			if w != "" && !seen[w] {
+				seen[w] = true
+				warnings = append(warnings, w)
+			}
+		}
+	}
+
+	header, _ := resp["responseHeader"].(map[string]any)
+	for _, src := range []map[string]any{header, resp} {
+		if src == nil {
+			continue
+		}
+		add(src["warning"])
+		add(src["warnings"])
+	}
+	if partial, _ := header["partialResults"].(bool); partial {
+		add("partialResults: results are incomplete (e.g. timeAllowed exceeded or a shard failed)")
+	}
+	return warnings
+}
+
+func warningStrings(v any) []string {
+	switch w := v.(type) {
+	case nil:
+		return nil
+	case string:
+		return []string{w}
+	case []any:
+		var out []string
+		for _, item := range w {
+			out = append(out, warningStrings(item)...)
+		}
+		return out
+	case map[string]any:
+		// e.g. {"deprecatedParam": "message"}; sort keys for a stable order
+		keys := make([]string, 0, len(w))
+		for k := range w {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		out := make([]string, 0, len(keys))
+		for _, k := range keys {
+			out = append(out, fmt.Sprintf("%s: %v", k, w[k]))
+		}
+		return out
+	default:
+		return []string{fmt.Sprint(w)}
+	}
+}
@@ -0,0 +1,31 @@
+package solr
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestExtractWarnings tests that warnings are collected from the response header and top level.
+func TestExtractWarnings(t *testing.T) {
+	// Goal: A response without warnings yields nil.
+	assert.Nil(t, ExtractWarnings(map[string]any{"responseHeader": map[string]any{"status": 0.0}}))
+
+	// Goal: String, array and object warnings are flattened, de-duplicated and partial results are reported.
+	resp := map[string]any{
+		"responseHeader": map[string]any{
+			"warning":        "Parameter 'qt' is deprecated",
+			"warnings":       []any{"Parameter 'qt' is deprecated", map[string]any{"b": "second", "a": "first"}},
+			"partialResults": true,
+		},
+		"warning": "top-level warning",
+	}
+	warnings := ExtractWarnings(resp)
+	assert.Equal(t, []string{
+		"Parameter 'qt' is deprecated",
+		"a: first",
+		"b: second",
+		"top-level warning",
+		"partialResults: results are incomplete (e.g. timeAllowed exceeded or a shard failed)",
+	}, warnings)
+}
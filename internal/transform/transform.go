@@ -0,0 +1,259 @@
+// Package transform reshapes the documents of search results per collection, such as renaming
+// fields, converting units, localizing timestamps and building display fields, without reindexing.
+package transform
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	_ "time/tzdata" // the Docker image has no zoneinfo for localize
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/config"
+)
+
+// Names of the built-in transformer types.
+const (
+	Rename   = "rename"
+	Convert  = "convert"
+	Localize = "localize"
+	Concat   = "concat"
+)
+
+// Func transforms a document in place. rule is the configured step, so registered transformers
+// can read their settings from it, e.g. from Options.
+type Func func(doc map[string]any, rule config.TransformerConfig) error
+
+// Registry holds the transformer types and the configured steps of each collection.
+// Types registered with Register take precedence over built-ins of the same name.
+type Registry struct {
+	mu     sync.RWMutex
+	cfg    map[string][]config.TransformerConfig
+	custom map[string]Func
+}
+
+var builtins = map[string]Func{
+	Rename:   rename,
+	Convert:  convert,
+	Localize: localize,
+	Concat:   concat,
+}
+
+// NewRegistry creates a Registry with the steps of cfg.
+func NewRegistry(cfg map[string][]config.TransformerConfig) *Registry {
+	r := &Registry{custom: make(map[string]Func)}
+	r.SetConfig(cfg)
+	return r
+}
+
+// SetConfig replaces the configured steps, e.g. after a config reload.
+func (r *Registry) SetConfig(cfg map[string][]config.TransformerConfig) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cfg = cfg
+	for collection, steps := range cfg {
+		for _, s := range steps {
+			if r.lookupLocked(s.Type) == nil {
+				slog.Warn("Unknown transformer in config, it will be skipped until registered", "collection", collection, "transformer", s.Type)
+			}
+		}
+	}
+}
+
+// Register adds a transformer type that the transformers config can reference.
+func (r *Registry) Register(name string, fn Func) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.custom[name] = fn
+}
+
+// Steps returns the configured steps of collection, in order.
+func (r *Registry) Steps(collection string) []config.TransformerConfig {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cfg[collection]
+}
+
+// Apply runs the steps of collection over the documents of a query response and returns the types
+// of the applied steps. A step failing on a document leaves that document as the step found it.
+func (r *Registry) Apply(collection string, resp map[string]any) []string {
+	steps := r.Steps(collection)
+	if len(steps) == 0 {
+		return nil
+	}
+	respObj, _ := resp["response"].(map[string]any)
+	docs, _ := respObj["docs"].([]any)
+	var applied []string
+	for _, step := range steps {
+		r.mu.RLock()
+		fn := r.lookupLocked(step.Type)
+		r.mu.RUnlock()
+		if fn == nil {
+			continue
+		}
+		applied = append(applied, step.Type)
+		for _, d := range docs {
+			doc, ok := d.(map[string]any)
+			if !ok {
+				continue
+			}
+			if err := fn(doc, step); err != nil {
+				slog.Debug("Transformer skipped a document", "collection", collection, "transformer", step.Type, "error", err)
+			}
+		}
+	}
+	return applied
+}
+
+func (r *Registry) lookupLocked(name string) Func {
+	if fn, ok := r.custom[name]; ok {
+		return fn
+	}
+	return builtins[name]
+}
+
+// rename moves the value of Field to To.
+func rename(doc map[string]any, rule config.TransformerConfig) error {
+	v, ok := doc[rule.Field]
+	if !ok {
+		return nil
+	}
+	delete(doc, rule.Field)
+	doc[rule.To] = v
+	return nil
+}
+
+// convert computes value * Factor + Offset, rounded to Decimals, with Unit appended if set.
+// Multi-valued fields are converted value by value.
+func convert(doc map[string]any, rule config.TransformerConfig) error {
+	v, ok := doc[rule.Field]
+	if !ok {
+		return nil
+	}
+	factor := rule.Factor
+	if factor == 0 {
+		factor = 1
+	}
+	one := func(v any) (any, error) {
+		n, err := number(v)
+		if err != nil {
+			return nil, err
+		}
+		n = n*factor + rule.Offset
+		if rule.Decimals != nil {
+			p := math.Pow10(*rule.Decimals)
+			n = math.Round(n*p) / p
+		}
+		if rule.Unit == "" {
+			return n, nil
+		}
+		prec := -1
+		if rule.Decimals != nil {
+			prec = *rule.Decimals
+		}
+		return strconv.FormatFloat(n, 'f', prec, 64) + " " + rule.Unit, nil
+	}
+	out, err := mapValues(v, one)
+	if err != nil {
+		return fmt.Errorf("convert %s: %v", rule.Field, err)
+	}
+	doc[target(rule)] = out
+	return nil
+}
+
+// localize formats a timestamp in Timezone with Layout.
+func localize(doc map[string]any, rule config.TransformerConfig) error {
+	v, ok := doc[rule.Field]
+	if !ok {
+		return nil
+	}
+	loc, err := time.LoadLocation(rule.Timezone)
+	if err != nil {
+		return err
+	}
+	layout := rule.Layout
+	if layout == "" {
+		layout = time.RFC3339
+	}
+	out, err := mapValues(v, func(v any) (any, error) {
+		s, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("not a timestamp: %v", v)
+		}
+		t, err := time.Parse(time.RFC3339Nano, s)
+		if err != nil {
+			return nil, err
+		}
+		return t.In(loc).Format(layout), nil
+	})
+	if err != nil {
+		return fmt.Errorf("localize %s: %v", rule.Field, err)
+	}
+	doc[target(rule)] = out
+	return nil
+}
+
+// concat joins the values of Fields into To. Missing fields are left out.
+func concat(doc map[string]any, rule config.TransformerConfig) error {
+	sep := rule.Separator
+	if sep == "" {
+		sep = " "
+	}
+	var parts []string
+	for _, f := range rule.Fields {
+		switch v := doc[f].(type) {
+		case nil:
+		case []any:
+			for _, item := range v {
+				parts = append(parts, fmt.Sprint(item))
+			}
+		default:
+			parts = append(parts, fmt.Sprint(v))
+		}
+	}
+	if len(parts) > 0 {
+		doc[rule.To] = strings.Join(parts, sep)
+	}
+	return nil
+}
+
+func target(rule config.TransformerConfig) string {
+	if rule.To != "" {
+		return rule.To
+	}
+	return rule.Field
+}
+
+// mapValues applies fn to a value or to each value of a multi-valued field.
+func mapValues(v any, fn func(any) (any, error)) (any, error) {
+	list, ok := v.([]any)
+	if !ok {
+		return fn(v)
+	}
+	out := make([]any, len(list))
+	for i, item := range list {
+		n, err := fn(item)
+		if err != nil {
+			return nil, err
+		}
+		out[i] = n
+	}
+	return out, nil
+}
+
+func number(v any) (float64, error) {
+	switch n := v.(type) {
+	case float64:
+		return n, nil
+	case int:
+		return float64(n), nil
+	case int64:
+		return float64(n), nil
+	case string:
+		return strconv.ParseFloat(n, 64)
+	}
+	return 0, fmt.Errorf("not a number: %v", v)
+}
@@ -0,0 +1,77 @@
+package transform
+
+import (
+	"testing"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/config"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func queryResponse(docs ...map[string]any) map[string]any {
+	list := make([]any, len(docs))
+	for i, d := range docs {
+		list[i] = d
+	}
+	return map[string]any{"response": map[string]any{"numFound": float64(len(docs)), "docs": list}}
+}
+
+// TestBuiltins tests the built-in transformer types.
+func TestBuiltins(t *testing.T) {
+	two := 2
+	r := NewRegistry(map[string][]config.TransformerConfig{
+		"products": {
+			{Type: Rename, Field: "prc_l", To: "price_cents"},
+			{Type: Convert, Field: "price_cents", To: "price", Factor: 0.01, Decimals: &two, Unit: "EUR"},
+			{Type: Convert, Field: "size_b", Factor: 1.0 / 1024},
+			{Type: Localize, Field: "updated_dt", Timezone: "Asia/Tokyo", Layout: "2006-01-02 15:04 MST"},
+			{Type: Concat, Fields: []string{"brand_s", "name_s", "missing"}, To: "display", Separator: " - "},
+		},
+	})
+	doc := map[string]any{
+		"id":         "1",
+		"prc_l":      float64(1250),
+		"size_b":     []any{float64(2048), "1024"},
+		"updated_dt": "2024-03-10T15:30:00Z",
+		"brand_s":    "Acme",
+		"name_s":     "Anvil",
+	}
+	other := map[string]any{"id": "2", "prc_l": "n/a"}
+
+	// Goal: Steps run in order on every document and are listed in the result.
+	applied := r.Apply("products", queryResponse(doc, other))
+	assert.Equal(t, []string{Rename, Convert, Convert, Localize, Concat}, applied)
+	assert.NotContains(t, doc, "prc_l")
+	assert.Equal(t, float64(1250), doc["price_cents"])
+	assert.Equal(t, "12.50 EUR", doc["price"])
+	assert.Equal(t, []any{float64(2), float64(1)}, doc["size_b"])
+	assert.Equal(t, "2024-03-11 00:30 JST", doc["updated_dt"])
+	assert.Equal(t, "Acme - Anvil", doc["display"])
+
+	// Goal: A value that cannot be converted is left unchanged.
+	assert.Equal(t, "n/a", other["price_cents"])
+	assert.NotContains(t, other, "price")
+
+	// Goal: Collections without steps are not touched.
+	assert.Nil(t, r.Apply("logs", queryResponse(map[string]any{"id": "1"})))
+}
+
+// TestRegister tests registered transformer types.
+func TestRegister(t *testing.T) {
+	r := NewRegistry(map[string][]config.TransformerConfig{
+		"products": {{Type: "upper", Field: "name_s"}, {Type: "unknown"}},
+	})
+	doc := map[string]any{"name_s": "anvil"}
+
+	// Goal: Unknown types are skipped until they are registered.
+	assert.Empty(t, r.Apply("products", queryResponse(doc)))
+	assert.Equal(t, "anvil", doc["name_s"])
+
+	// Goal: Registered types receive their configured step.
+	r.Register("upper", func(doc map[string]any, rule config.TransformerConfig) error {
+		doc[rule.Field] = "ANVIL"
+		return nil
+	})
+	assert.Equal(t, []string{"upper"}, r.Apply("products", queryResponse(doc)))
+	assert.Equal(t, "ANVIL", doc["name_s"])
+}
@@ -1,6 +1,8 @@
 package types
 
 import (
+	"errors"
+	"slices"
 	"sync"
 	"time"
 )
@@ -11,6 +13,10 @@ type SchemaCache struct {
 	LastFetch map[string]time.Time
 	TTL       time.Duration
 	ByCol     map[string]*FieldCatalog
+	// StatsTTL is how long field statistics are cached. Statistics of a field are also dropped
+	// when a refreshed schema changes or removes the field.
+	StatsTTL time.Duration
+	stats    map[string]map[string]FieldStats
 }
 
 // Get retrieves a cached FieldCatalog if it exists and is still valid
@@ -40,14 +46,99 @@ func (sc *SchemaCache) Set(collection string, fc *FieldCatalog) {
 	sc.mu.Lock()
 	defer sc.mu.Unlock()
 
+	for name, fs := range sc.stats[collection] {
+		if !slices.ContainsFunc(fc.All, func(f SolrField) bool { return f.Name == name && f.Type == fs.Type }) {
+			delete(sc.stats[collection], name)
+		}
+	}
 	sc.ByCol[collection] = fc
 	sc.LastFetch[collection] = time.Now()
 }
 
+// GetFieldStats retrieves the cached statistics of a field if they exist and are still valid
+func (sc *SchemaCache) GetFieldStats(collection, field string) (FieldStats, bool) {
+	sc.mu.RLock()
+	defer sc.mu.RUnlock()
+
+	fs, ok := sc.stats[collection][field]
+	if !ok || time.Since(fs.Fetched) >= sc.StatsTTL {
+		return FieldStats{}, false
+	}
+	return fs, true
+}
+
+// SetFieldStats stores the statistics of a field in the cache
+func (sc *SchemaCache) SetFieldStats(collection string, fs FieldStats) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
+	if sc.stats == nil {
+		sc.stats = make(map[string]map[string]FieldStats)
+	}
+	if sc.stats[collection] == nil {
+		sc.stats[collection] = make(map[string]FieldStats)
+	}
+	sc.stats[collection][fs.Field] = fs
+}
+
+// Flush removes all cached schemas and returns how many were removed.
+func (sc *SchemaCache) Flush() int {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
+	n := len(sc.ByCol)
+	sc.ByCol = make(map[string]*FieldCatalog)
+	sc.LastFetch = make(map[string]time.Time)
+	sc.stats = nil
+	return n
+}
+
 type FieldCatalog struct {
-	UniqueKey string
-	All       []SolrField
-	Metadata  map[string]FieldMetadata `json:"metadata,omitempty"`
+	UniqueKey     string
+	All           []SolrField
+	Metadata      map[string]FieldMetadata `json:"metadata,omitempty"`
+	SelectHandler *HandlerParams           `json:"selectHandler,omitempty"`
+	// Router is the document router of the collection from CLUSTERSTATUS (e.g. "compositeId" or "implicit")
+	Router string `json:"router,omitempty"`
+	// DynamicFields maps dynamic field patterns (e.g. "*_txt_ja") to the concrete fields that exist in the index
+	DynamicFields map[string][]string `json:"dynamicFields,omitempty"`
+}
+
+// ErrNoUniqueKey is returned for operations that need a uniqueKey on collections without one.
+var ErrNoUniqueKey = errors.New("collection has no uniqueKey")
+
+// RequireUniqueKey returns the uniqueKey field, or ErrNoUniqueKey if the collection has none.
+// Operations addressing documents by ID (real-time get, atomic updates) should check it first.
+func (fc *FieldCatalog) RequireUniqueKey() (string, error) {
+	if fc.UniqueKey == "" {
+		return "", ErrNoUniqueKey
+	}
+	return fc.UniqueKey, nil
+}
+
+// HasField reports whether name is a static field or a dynamic field instance that exists in the index.
+func (fc *FieldCatalog) HasField(name string) bool {
+	for _, f := range fc.All {
+		if f.Name == name {
+			return true
+		}
+	}
+	for _, instances := range fc.DynamicFields {
+		for _, n := range instances {
+			if n == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// HandlerParams holds the parameters a request handler applies to every request,
+// as reported by the Config API.
+type HandlerParams struct {
+	Defaults   map[string]any `json:"defaults,omitempty"`   // used unless the request overrides them
+	Appends    map[string]any `json:"appends,omitempty"`    // added to the request parameters (e.g. extra fq)
+	Invariants map[string]any `json:"invariants,omitempty"` // always applied; request values are ignored
 }
 
 type SolrField struct {
@@ -58,21 +149,62 @@ type SolrField struct {
 	MultiValued bool   `json:"multiValued,omitempty"`
 }
 
+// FieldStats holds estimates of the values of a field, for planning queries without asking Solr every time.
+type FieldStats struct {
+	Field string `json:"field"`
+	Type  string `json:"type"`
+	// Count is the number of documents with a value
+	Count int64 `json:"count"`
+	// Cardinality is an estimate of the number of distinct values
+	Cardinality int64 `json:"cardinality"`
+	// Min and Max are only set for numeric and date fields
+	Min     any       `json:"min,omitempty"`
+	Max     any       `json:"max,omitempty"`
+	Fetched time.Time `json:"fetched"`
+}
+
 type FieldMetadata struct {
 	Description string `json:"description"`
 }
 
+// Guardrail describes a change the server made to a request or its result, and why.
+type Guardrail struct {
+	Action    string `json:"action"`           // e.g. "clamp_rows", "apply_limit", "truncate_output"
+	Target    string `json:"target,omitempty"` // parameter or output part affected
+	Requested any    `json:"requested,omitempty"`
+	Applied   any    `json:"applied,omitempty"`
+	Reason    string `json:"reason"`
+}
+
 // Basic tool types
 type QueryIn struct {
 	Collection  string         `json:"collection,omitempty"`
 	Query       string         `json:"query,omitempty"`
 	FilterQuery []string       `json:"fq,omitempty"`
 	Fields      []string       `json:"fl,omitempty"`
+	Select      string         `json:"select,omitempty"`
 	Sort        string         `json:"sort,omitempty"`
 	Start       *int           `json:"start,omitempty"`
 	Rows        *int           `json:"rows,omitempty"`
 	Params      map[string]any `json:"params,omitempty"`
 	EchoParams  bool           `json:"echoParams,omitempty"`
+	PlanOnly    bool           `json:"planOnly,omitempty"`
+	AutoCorrect bool           `json:"autoCorrect,omitempty"`
+	MatchedOn   bool           `json:"matchedOn,omitempty"`
+	Elevate     bool           `json:"elevate,omitempty"`
+	LTR         *LTRQuery      `json:"ltr,omitempty"`
+	UseParams   []string       `json:"useParams,omitempty"`
+	Destination string         `json:"destination,omitempty"`
+	Confirm     string         `json:"confirm,omitempty"`
+}
+
+// LTRQuery re-ranks the top documents of a query with a Learning To Rank model.
+type LTRQuery struct {
+	Model        string         `json:"model"`
+	ReRankDocs   int            `json:"reRankDocs,omitempty"`  // documents re-ranked (Solr default: 200)
+	EFI          map[string]any `json:"efi,omitempty"`         // external feature information, e.g. {"user_query": "laptop"}
+	LogFeatures  bool           `json:"logFeatures,omitempty"` // return the feature values of each document in [features]
+	FeatureStore string         `json:"store,omitempty"`       // feature store logged (default: the store of the model)
 }
 
 type CommitIn struct {
@@ -83,15 +215,209 @@ type PingIn struct {
 	// No fields needed - cluster-wide ping
 }
 
+type InfoIn struct {
+	// No fields needed
+}
+
+type MetricsHistoryIn struct {
+	Metric    string            `json:"metric,omitempty"`
+	Labels    map[string]string `json:"labels,omitempty"`
+	Since     string            `json:"since,omitempty"`
+	MaxPoints int               `json:"maxPoints,omitempty"`
+	MaxSeries int               `json:"maxSeries,omitempty"`
+}
+
+type PermissionsIn struct {
+	Collection string `json:"collection,omitempty"`
+	User       string `json:"user,omitempty"`
+}
+
+type SessionsIn struct{}
+
 type CollectionHealthIn struct {
 	Collection string `json:"collection,omitempty"`
 }
 
+type ShardQueryIn struct {
+	Collection  string   `json:"collection,omitempty"`
+	Query       string   `json:"query,omitempty"`
+	FilterQuery []string `json:"fq,omitempty"`
+}
+
+type ConsistencyCheckIn struct {
+	Collection string `json:"collection,omitempty"`
+	Canary     string `json:"canary,omitempty"`
+}
+
+type HealthcheckFullIn struct {
+	Collections []string `json:"collections,omitempty"`
+	ErrorWindow string   `json:"errorWindow,omitempty"`
+}
+
+type RetentionIn struct {
+	Policy  string `json:"policy,omitempty"`
+	Confirm string `json:"confirm,omitempty"`
+}
+
+type MaintenanceStatusIn struct{}
+
+type DedupeIn struct {
+	Collection     string   `json:"collection,omitempty"`
+	Query          string   `json:"query,omitempty"`
+	SignatureField string   `json:"signatureField,omitempty"`
+	Fields         []string `json:"fields,omitempty"`
+	Threshold      float64  `json:"threshold,omitempty"`
+	MaxDocs        int64    `json:"maxDocs,omitempty"`
+	DeletePreview  bool     `json:"deletePreview,omitempty"`
+}
+
+type ProfileIn struct {
+	Collection string   `json:"collection,omitempty"`
+	Query      string   `json:"query,omitempty"`
+	Fields     []string `json:"fields,omitempty"`
+	MaxFields  int      `json:"maxFields,omitempty"`
+}
+
+type DriftIn struct {
+	Collection string `json:"collection,omitempty"`
+	Check      bool   `json:"check,omitempty"`
+	From       string `json:"from,omitempty"`
+	To         string `json:"to,omitempty"`
+}
+
+type ElevationListIn struct {
+	Collection string `json:"collection,omitempty"`
+	File       string `json:"file,omitempty"`
+	Query      string `json:"query,omitempty"`
+}
+
+type ElevationSetIn struct {
+	Collection string   `json:"collection,omitempty"`
+	File       string   `json:"file,omitempty"`
+	Query      string   `json:"query,omitempty"`
+	Match      string   `json:"match,omitempty"`
+	Elevate    []string `json:"elevate,omitempty"`
+	Exclude    []string `json:"exclude,omitempty"`
+	Remove     bool     `json:"remove,omitempty"`
+	Preview    bool     `json:"preview,omitempty"`
+}
+
+type LTRListIn struct {
+	Collection string `json:"collection,omitempty"`
+	Store      string `json:"store,omitempty"`
+}
+
+type LTRUploadIn struct {
+	Collection string           `json:"collection,omitempty"`
+	Features   []map[string]any `json:"features,omitempty"`
+	Model      map[string]any   `json:"model,omitempty"`
+	Replace    bool             `json:"replace,omitempty"`
+	NoReload   bool             `json:"noReload,omitempty"`
+}
+
+type ParamsListIn struct {
+	Collection string `json:"collection,omitempty"`
+	Name       string `json:"name,omitempty"`
+}
+
+type ParamsSetIn struct {
+	Collection string         `json:"collection,omitempty"`
+	Name       string         `json:"name,omitempty"`
+	Params     map[string]any `json:"params,omitempty"`
+	Merge      bool           `json:"merge,omitempty"`
+	Delete     bool           `json:"delete,omitempty"`
+}
+
+type ExperimentReportIn struct {
+	Name string `json:"name,omitempty"`
+}
+
+type FeedbackClickIn struct {
+	Collection string `json:"collection,omitempty"`
+	Query      string `json:"query,omitempty"`
+	DocID      string `json:"docId,omitempty"`
+	Position   int    `json:"position,omitempty"`
+	Experiment string `json:"experiment,omitempty"`
+	Variant    string `json:"variant,omitempty"`
+}
+
+type FeedbackReportIn struct {
+	Collection string `json:"collection,omitempty"`
+	Query      string `json:"query,omitempty"`
+	Sort       string `json:"sort,omitempty"`
+	Limit      int    `json:"limit,omitempty"`
+}
+
+type ExportIn struct {
+	Collection           string   `json:"collection,omitempty"`
+	Query                string   `json:"query,omitempty"`
+	Fields               []string `json:"fields,omitempty"`
+	BatchSize            int      `json:"batchSize,omitempty"`
+	MaxRequestsPerSecond float64  `json:"maxRequestsPerSecond,omitempty"`
+	MaxBytesPerSecond    int64    `json:"maxBytesPerSecond,omitempty"`
+	Destination          string   `json:"destination,omitempty"`
+}
+
+type ReindexIn struct {
+	Collection           string  `json:"collection,omitempty"`
+	Target               string  `json:"target,omitempty"`
+	Query                string  `json:"query,omitempty"`
+	BatchSize            int     `json:"batchSize,omitempty"`
+	MaxRequestsPerSecond float64 `json:"maxRequestsPerSecond,omitempty"`
+	MaxBytesPerSecond    int64   `json:"maxBytesPerSecond,omitempty"`
+}
+
+type ImportIn struct {
+	Collection           string  `json:"collection,omitempty"`
+	Source               string  `json:"source,omitempty"`
+	BatchSize            int     `json:"batchSize,omitempty"`
+	MaxRequestsPerSecond float64 `json:"maxRequestsPerSecond,omitempty"`
+	MaxBytesPerSecond    int64   `json:"maxBytesPerSecond,omitempty"`
+}
+
+type JobsIn struct {
+	ID     string `json:"id,omitempty"`
+	Action string `json:"action,omitempty"`
+}
+
+type DeleteIn struct {
+	Collection string   `json:"collection,omitempty"`
+	Query      string   `json:"query,omitempty"`
+	IDs        []string `json:"ids,omitempty"`
+	Preview    bool     `json:"preview,omitempty"`
+	Confirm    string   `json:"confirm,omitempty"`
+}
+
+type UpdateIn struct {
+	Collection string           `json:"collection,omitempty"`
+	Documents  []map[string]any `json:"documents,omitempty"`
+	Preview    bool             `json:"preview,omitempty"`
+}
+
+type DropCollectionIn struct {
+	Collection string `json:"collection,omitempty"`
+	Confirm    string `json:"confirm,omitempty"`
+}
+
+type ArchiveIn struct {
+	Collection        string `json:"collection,omitempty"`
+	Query             string `json:"query,omitempty"`
+	ArchiveCollection string `json:"archiveCollection,omitempty"`
+	MaxDocs           *int   `json:"maxDocs,omitempty"`
+	Confirm           string `json:"confirm,omitempty"` // solr.archive only
+}
+
 // Smart search tool types
 type SchemaIn struct {
 	Collection string `json:"collection,omitempty"`
 }
 
+type ExplainQueryIn struct {
+	Collection string         `json:"collection,omitempty"`
+	Params     map[string]any `json:"params,omitempty"`
+	SavedQuery string         `json:"savedQuery,omitempty"`
+}
+
 type SchemaOut struct {
 	SelectParams   map[string]any `json:"selectParams,omitempty"`   // Parameters used for the executed /select request
 	JSONRequest    any            `json:"jsonRequest,omitempty"`    // Executed JSON request body
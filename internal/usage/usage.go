@@ -0,0 +1,234 @@
+// Package usage tracks per-tool invocation statistics and derives configuration recommendations from failure patterns.
+package usage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/errcatalog"
+)
+
+// ToolStats are the accumulated statistics of a single tool.
+type ToolStats struct {
+	Name           string    `json:"name"`
+	Calls          int64     `json:"calls"`
+	Failures       int64     `json:"failures"`
+	ResultBytes    int64     `json:"resultBytes"` // total size of successful results
+	DurationMs     int64     `json:"durationMs"`  // total duration of all calls
+	LastError      string    `json:"lastError,omitempty"`
+	LastCall       time.Time `json:"lastCall"`
+	FailureRate    float64   `json:"failureRate"`
+	AvgResultBytes float64   `json:"avgResultBytes"`
+	AvgDurationMs  float64   `json:"avgDurationMs"`
+}
+
+// Recommendation is an actionable hint derived from repeated failures.
+type Recommendation struct {
+	Pattern string `json:"pattern"`
+	Count   int64  `json:"count"`
+	Message string `json:"message"`
+}
+
+// Snapshot is the state of a Tracker at a point in time.
+type Snapshot struct {
+	Since           time.Time        `json:"since"`
+	Tools           []ToolStats      `json:"tools"`
+	Recommendations []Recommendation `json:"recommendations"`
+}
+
+// rule maps a code of the error catalog to a recommendation. A rule with a detail only matches errors whose
+// message contains it.
+type rule struct {
+	code    errcatalog.Code
+	detail  string
+	message string
+}
+
+// pattern names the failures the rule matches in recommendations and the stats file.
+func (r rule) pattern() string {
+	if r.detail == "" {
+		return string(r.code)
+	}
+	return string(r.code) + ": " + r.detail
+}
+
+// rules are checked in order; an error counts towards the first rule it matches.
+var rules = []rule{
+	{errcatalog.InvalidArgument, "collection is required", "Tools are often called without a collection: set SOLR_MCP_DEFAULT_COLLECTION to the main collection and name it in your client instructions."},
+	{errcatalog.CollectionNotFound, "", "Requests target collections that do not exist: check the collection names or SOLR_MCP_DEFAULT_COLLECTION."},
+	{errcatalog.SolrUnauthenticated, "", "Solr rejects the credentials: check SOLR_BASIC_USER and SOLR_BASIC_PASS."},
+	{errcatalog.SolrPermissionDenied, "", "Solr denies access: grant the SOLR_BASIC_USER account the permissions the tools need."},
+	{errcatalog.SolrUnavailable, "", "Solr is unreachable: check SOLR_MCP_SOLR_URL and the network."},
+	{errcatalog.ConfirmationInvalid, "", "Confirmation tokens expire or do not match: raise SOLR_MCP_CONFIRMATION_TTL or repeat the exact arguments of the preview call."},
+	{errcatalog.CapabilityNotConfigured, "", "Tools need optional capabilities that are disabled: see solr.info for setup hints."},
+	{errcatalog.ServerBusy, "", "Tool calls are rejected as busy: raise memoryBudget.maxBytes, memoryBudget.maxConcurrent or memoryBudget.maxWait in the config file."},
+}
+
+// MinFailures is how often a failure pattern must occur before it is recommended.
+const MinFailures = 3
+
+// Tracker accumulates tool statistics. It is safe for concurrent use.
+type Tracker struct {
+	mu       sync.Mutex
+	since    time.Time
+	tools    map[string]*ToolStats
+	patterns map[string]int64
+	warned   map[string]bool
+	// OnRecommendation, if set, is called once per pattern when it first reaches MinFailures.
+	OnRecommendation func(Recommendation)
+}
+
+// NewTracker creates an empty Tracker.
+func NewTracker() *Tracker {
+	return &Tracker{
+		since:    time.Now(),
+		tools:    make(map[string]*ToolStats),
+		patterns: make(map[string]int64),
+		warned:   make(map[string]bool),
+	}
+}
+
+// Record adds a tool call. resultBytes is only counted for successful calls.
+func (t *Tracker) Record(tool string, err error, resultBytes int, d time.Duration) {
+	t.mu.Lock()
+	s, ok := t.tools[tool]
+	if !ok {
+		s = &ToolStats{Name: tool}
+		t.tools[tool] = s
+	}
+	s.Calls++
+	s.DurationMs += d.Milliseconds()
+	s.LastCall = time.Now()
+	var notify *Recommendation
+	if err != nil {
+		s.Failures++
+		s.LastError = err.Error()
+		code := errcatalog.CodeOf(err)
+		for _, r := range rules {
+			if r.code == code && strings.Contains(s.LastError, r.detail) {
+				p := r.pattern()
+				t.patterns[p]++
+				if t.patterns[p] >= MinFailures && !t.warned[p] {
+					t.warned[p] = true
+					notify = &Recommendation{Pattern: p, Count: t.patterns[p], Message: r.message}
+				}
+				break
+			}
+		}
+	} else {
+		s.ResultBytes += int64(resultBytes)
+	}
+	onRecommendation := t.OnRecommendation
+	t.mu.Unlock()
+
+	if notify != nil && onRecommendation != nil {
+		onRecommendation(*notify)
+	}
+}
+
+// Snapshot returns the statistics of all tools sorted by name, with derived rates and averages.
+func (t *Tracker) Snapshot() Snapshot {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	snap := Snapshot{Since: t.since, Tools: make([]ToolStats, 0, len(t.tools)), Recommendations: t.recommendations()}
+	for _, s := range t.tools {
+		c := *s
+		if c.Calls > 0 {
+			c.FailureRate = float64(c.Failures) / float64(c.Calls)
+			c.AvgDurationMs = float64(c.DurationMs) / float64(c.Calls)
+		}
+		if ok := c.Calls - c.Failures; ok > 0 {
+			c.AvgResultBytes = float64(c.ResultBytes) / float64(ok)
+		}
+		snap.Tools = append(snap.Tools, c)
+	}
+	sort.Slice(snap.Tools, func(i, j int) bool { return snap.Tools[i].Name < snap.Tools[j].Name })
+	return snap
+}
+
+// Recommendations returns the hints for all failure patterns that occurred at least MinFailures times.
+func (t *Tracker) Recommendations() []Recommendation {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.recommendations()
+}
+
+func (t *Tracker) recommendations() []Recommendation {
+	recs := []Recommendation{}
+	for _, r := range rules {
+		if n := t.patterns[r.pattern()]; n >= MinFailures {
+			recs = append(recs, Recommendation{Pattern: r.pattern(), Count: n, Message: r.message})
+		}
+	}
+	return recs
+}
+
+// persisted is the file format of Save and Load.
+type persisted struct {
+	Since    time.Time            `json:"since"`
+	Tools    map[string]ToolStats `json:"tools"`
+	Patterns map[string]int64     `json:"patterns"`
+}
+
+// Save writes the statistics to path, replacing the file atomically.
+func (t *Tracker) Save(path string) error {
+	t.mu.Lock()
+	p := persisted{Since: t.since, Tools: make(map[string]ToolStats, len(t.tools)), Patterns: t.patterns}
+	for name, s := range t.tools {
+		p.Tools[name] = *s
+	}
+	data, err := json.Marshal(p)
+	t.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("encode usage stats: %v", err)
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("write usage stats: %v", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("write usage stats: %v", err)
+	}
+	return nil
+}
+
+// Load replaces the statistics with those saved at path. A missing file is not an error.
+// Patterns loaded from the file are not reported again through OnRecommendation.
+func (t *Tracker) Load(path string) error {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("read usage stats: %v", err)
+	}
+	var p persisted
+	if err := json.Unmarshal(data, &p); err != nil {
+		return fmt.Errorf("decode usage stats: %v", err)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !p.Since.IsZero() {
+		t.since = p.Since
+	}
+	t.tools = make(map[string]*ToolStats, len(p.Tools))
+	for name, s := range p.Tools {
+		s.Name = name
+		t.tools[name] = &s
+	}
+	t.patterns = make(map[string]int64, len(p.Patterns))
+	t.warned = make(map[string]bool, len(p.Patterns))
+	for pattern, n := range p.Patterns {
+		t.patterns[pattern] = n
+		t.warned[pattern] = n >= MinFailures
+	}
+	return nil
+}
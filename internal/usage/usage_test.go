@@ -0,0 +1,96 @@
+package usage
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/errcatalog"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestTracker tests the accumulated statistics and derived values.
+func TestTracker(t *testing.T) {
+	tr := NewTracker()
+	tr.Record("solr.query", nil, 100, 10*time.Millisecond)
+	tr.Record("solr.query", nil, 300, 30*time.Millisecond)
+	tr.Record("solr.query", errors.New("boom"), 0, 20*time.Millisecond)
+	tr.Record("solr.ping", nil, 10, 0)
+
+	snap := tr.Snapshot()
+	require.Len(t, snap.Tools, 2)
+
+	// Goal: Tools are sorted by name and averages only count successful results.
+	assert.Equal(t, "solr.ping", snap.Tools[0].Name)
+	q := snap.Tools[1]
+	assert.Equal(t, int64(3), q.Calls)
+	assert.Equal(t, int64(1), q.Failures)
+	assert.InDelta(t, 1.0/3, q.FailureRate, 1e-9)
+	assert.Equal(t, float64(200), q.AvgResultBytes)
+	assert.Equal(t, float64(20), q.AvgDurationMs)
+	assert.Equal(t, "boom", q.LastError)
+	assert.Empty(t, snap.Recommendations)
+}
+
+// TestRecommendations tests that failure patterns are recommended once they repeat.
+func TestRecommendations(t *testing.T) {
+	tr := NewTracker()
+	var notified []Recommendation
+	tr.OnRecommendation = func(r Recommendation) { notified = append(notified, r) }
+
+	for range MinFailures - 1 {
+		tr.Record("solr.query", errors.New("input.collection is required"), 0, 0)
+	}
+	// Goal: Patterns below the threshold are not recommended.
+	assert.Empty(t, tr.Recommendations())
+
+	// Goal: Patterns are counted across tools and notified once.
+	tr.Record("solr.schema", errors.New("input.collection is required"), 0, 0)
+	tr.Record("solr.schema", errors.New("input.collection is required"), 0, 0)
+	require.Len(t, notified, 1)
+	assert.Equal(t, "invalid_argument: collection is required", notified[0].Pattern)
+	recs := tr.Recommendations()
+	require.Len(t, recs, 1)
+	assert.Equal(t, int64(MinFailures+1), recs[0].Count)
+	assert.Contains(t, recs[0].Message, "SOLR_MCP_DEFAULT_COLLECTION")
+
+	// Goal: Failures are matched by their code rather than their text, e.g. missing collections.
+	for range MinFailures {
+		tr.Record("solr.query", errcatalog.New(errcatalog.CollectionNotFound, "collection prodcts does not exist; available: [products]"), 0, 0)
+		tr.Record("solr.query", errcatalog.New(errcatalog.SolrError, "collection is required by the HTTP status 404 handler"), 0, 0)
+	}
+	require.Len(t, notified, 2)
+	assert.Equal(t, "collection_not_found", notified[1].Pattern)
+	assert.Contains(t, notified[1].Message, "collections that do not exist")
+	assert.Len(t, tr.Recommendations(), 2)
+}
+
+// TestSaveLoad tests that statistics are persisted and loaded patterns are not notified again.
+func TestSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "stats.json")
+	tr := NewTracker()
+	for range MinFailures {
+		tr.Record("solr.query", errors.New("HTTP status 401: unauthorized"), 0, 0)
+	}
+	tr.Record("solr.query", nil, 50, 0)
+	require.NoError(t, tr.Save(path))
+
+	loaded := NewTracker()
+	// Goal: A missing file is not an error.
+	require.NoError(t, loaded.Load(filepath.Join(t.TempDir(), "missing.json")))
+	require.NoError(t, loaded.Load(path))
+	loaded.OnRecommendation = func(Recommendation) { t.Error("loaded pattern notified again") }
+	loaded.Record("solr.query", errors.New("HTTP status 401: unauthorized"), 0, 0)
+
+	// Goal: Counts and recommendations are restored.
+	snap := loaded.Snapshot()
+	require.Len(t, snap.Tools, 1)
+	assert.Equal(t, int64(MinFailures+2), snap.Tools[0].Calls)
+	assert.Equal(t, float64(50), snap.Tools[0].AvgResultBytes)
+	require.Len(t, snap.Recommendations, 1)
+	assert.Contains(t, snap.Recommendations[0].Message, "SOLR_BASIC_USER")
+	assert.True(t, snap.Since.Equal(tr.Snapshot().Since))
+}
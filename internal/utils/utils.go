@@ -1,6 +1,9 @@
 package utils
 
 import (
+	"errors"
+	"fmt"
+	"io"
 	"log/slog"
 	"net/http"
 	"strconv"
@@ -18,6 +21,99 @@ func LoggingHandler(next http.Handler) http.Handler {
 	})
 }
 
+// MaxBytesHandler is a middleware that rejects request bodies larger than limit bytes
+// with 413 Request Entity Too Large and a JSON-RPC error body.
+// The body is streamed to next through http.MaxBytesReader rather than buffered, so no more than what next
+// reads is held in memory. Once next has read past the limit, its response is replaced by the 413 response.
+// A limit of zero or less disables the check.
+func MaxBytesHandler(next http.Handler, limit int64) http.Handler {
+	if limit <= 0 {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ContentLength > limit {
+			writeTooLarge(w, r, limit)
+			return
+		}
+		// Content-Length may be absent (chunked encoding), so enforce the limit while reading
+		body := &limitedBody{ReadCloser: http.MaxBytesReader(w, r.Body, limit)}
+		r.Body = body
+		tw := &tooLargeWriter{ResponseWriter: w, body: body, r: r, limit: limit}
+		next.ServeHTTP(tw, r)
+		if body.exceeded && !tw.wroteHeader {
+			writeTooLarge(w, r, limit)
+		}
+	})
+}
+
+// limitedBody records whether a read went past the limit of its http.MaxBytesReader.
+type limitedBody struct {
+	io.ReadCloser
+	exceeded bool
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	var maxErr *http.MaxBytesError
+	if errors.As(err, &maxErr) {
+		b.exceeded = true
+	}
+	return n, err
+}
+
+// tooLargeWriter replaces the response of a handler that read past the body limit with the 413 response,
+// whatever status the handler chose for the read error.
+type tooLargeWriter struct {
+	http.ResponseWriter
+	body        *limitedBody
+	r           *http.Request
+	limit       int64
+	wroteHeader bool
+	replaced    bool
+}
+
+func (w *tooLargeWriter) WriteHeader(code int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	if w.body.exceeded {
+		w.replaced = true
+		writeTooLarge(w.ResponseWriter, w.r, w.limit)
+		return
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *tooLargeWriter) Write(p []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.replaced {
+		return len(p), nil
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+// Flush keeps streamed (SSE) responses working through the middleware.
+func (w *tooLargeWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok && !w.replaced {
+		f.Flush()
+	}
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (w *tooLargeWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func writeTooLarge(w http.ResponseWriter, r *http.Request, limit int64) {
+	slog.Warn("Request body too large", "path", r.URL.Path, "limit", limit, "content_length", r.ContentLength)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusRequestEntityTooLarge)
+	fmt.Fprintf(w, `{"jsonrpc":"2.0","id":null,"error":{"code":-32600,"message":"request body exceeds the limit of %d bytes"}}`, limit)
+}
+
 func Choose(s, fallback string) string {
 	if strings.TrimSpace(s) != "" {
 		return s
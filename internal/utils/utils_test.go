@@ -2,6 +2,7 @@ package utils
 
 import (
 	"bytes"
+	"io"
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
@@ -259,3 +260,71 @@ func TestTwoDigitString(t *testing.T) {
 		}
 	})
 }
+
+// TestMaxBytesHandler tests the MaxBytesHandler middleware.
+func TestMaxBytesHandler(t *testing.T) {
+	echo := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Write(body)
+	})
+	handler := MaxBytesHandler(echo, 8)
+
+	// Goal: Bodies within the limit are passed through unchanged.
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest("POST", "/", strings.NewReader("12345678")))
+	if rr.Code != http.StatusOK || rr.Body.String() != "12345678" {
+		t.Errorf("Expected 200 with echoed body, Actual %d %q", rr.Code, rr.Body.String())
+	}
+
+	// Goal: Bodies over the limit are rejected with 413 and a JSON-RPC error.
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest("POST", "/", strings.NewReader("123456789")))
+	if rr.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("Expected 413, Actual %d", rr.Code)
+	}
+	if !strings.Contains(rr.Body.String(), "exceeds the limit of 8 bytes") {
+		t.Errorf("Expected limit message, Actual %q", rr.Body.String())
+	}
+
+	// Goal: Bodies without Content-Length are still limited while reading.
+	rr = httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/", io.NopCloser(strings.NewReader("123456789")))
+	req.ContentLength = -1
+	handler.ServeHTTP(rr, req)
+	if rr.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("Expected 413 for unknown length, Actual %d", rr.Code)
+	}
+
+	// Goal: The body is streamed, so a handler that stops reading within the limit answers as usual.
+	rr = httptest.NewRecorder()
+	req = httptest.NewRequest("POST", "/", io.NopCloser(strings.NewReader("123456789")))
+	req.ContentLength = -1
+	MaxBytesHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		head := make([]byte, 4)
+		io.ReadFull(r.Body, head)
+		w.Write(head)
+	}), 8).ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK || rr.Body.String() != "1234" {
+		t.Errorf("Expected 200 with the first bytes, Actual %d %q", rr.Code, rr.Body.String())
+	}
+
+	// Goal: The error response of a handler that read past the limit becomes a 413.
+	rr = httptest.NewRecorder()
+	req = httptest.NewRequest("POST", "/", io.NopCloser(strings.NewReader("123456789")))
+	req.ContentLength = -1
+	MaxBytesHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := io.ReadAll(r.Body); err != nil {
+			http.Error(w, "bad request", http.StatusBadRequest)
+		}
+	}), 8).ServeHTTP(rr, req)
+	if rr.Code != http.StatusRequestEntityTooLarge || strings.Contains(rr.Body.String(), "bad request") {
+		t.Errorf("Expected 413 instead of the handler error, Actual %d %q", rr.Code, rr.Body.String())
+	}
+
+	// Goal: A non-positive limit disables the check.
+	rr = httptest.NewRecorder()
+	MaxBytesHandler(echo, 0).ServeHTTP(rr, httptest.NewRequest("POST", "/", strings.NewReader("123456789")))
+	if rr.Code != http.StatusOK {
+		t.Errorf("Expected 200 when disabled, Actual %d", rr.Code)
+	}
+}
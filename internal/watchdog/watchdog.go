@@ -0,0 +1,106 @@
+// Package watchdog warns about Solr requests that take longer than soft deadlines. Slow requests are logged, and
+// the tool call waiting for them is told through a notifier, so users get feedback long before the hard timeout.
+package watchdog
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/config"
+)
+
+// DefaultDeadlines are the soft deadlines of Solr requests unless configured otherwise.
+var DefaultDeadlines = []time.Duration{5 * time.Second, 15 * time.Second}
+
+// ParseDeadlines parses a comma-separated list of durations such as "5s,15s", sorted ascending.
+// "off" disables the warnings.
+func ParseDeadlines(s string) ([]time.Duration, error) {
+	if strings.TrimSpace(s) == "off" {
+		return nil, nil
+	}
+	var deadlines []time.Duration
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part == "" {
+			continue
+		}
+		d, err := time.ParseDuration(part)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("invalid soft deadline %q", part)
+		}
+		deadlines = append(deadlines, d)
+	}
+	slices.Sort(deadlines)
+	return slices.Compact(deadlines), nil
+}
+
+// Notifier is told that a request of the current call passed a soft deadline.
+type Notifier func(ctx context.Context, message string)
+
+type notifierKey struct{}
+
+// WithNotifier returns a context whose slow requests are reported to n, e.g. as MCP progress notifications.
+func WithNotifier(ctx context.Context, n Notifier) context.Context {
+	return context.WithValue(ctx, notifierKey{}, n)
+}
+
+// Transport is an http.RoundTripper logging requests that have not been answered after each of Deadlines.
+// Timeout is the hard timeout of the client, mentioned in the warnings.
+type Transport struct {
+	Base      http.RoundTripper
+	Deadlines []time.Duration
+	Timeout   time.Duration
+}
+
+// Wrap returns a copy of client that warns about requests passing deadlines. Without deadlines, client is returned.
+func Wrap(client *http.Client, deadlines []time.Duration) *http.Client {
+	if len(deadlines) == 0 {
+		return client
+	}
+	base := client.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	wrapped := *client
+	wrapped.Transport = &Transport{Base: base, Deadlines: deadlines, Timeout: client.Timeout}
+	return &wrapped
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	done := make(chan struct{})
+	defer close(done)
+	go t.watch(req, time.Now(), done)
+	return t.Base.RoundTrip(req)
+}
+
+// watch warns at each deadline passed before done is closed or the request is cancelled.
+func (t *Transport) watch(req *http.Request, start time.Time, done <-chan struct{}) {
+	ctx := req.Context()
+	notify, _ := ctx.Value(notifierKey{}).(Notifier)
+	target := req.Method + " " + req.URL.Path
+	for _, d := range t.Deadlines {
+		timer := time.NewTimer(time.Until(start.Add(d)))
+		select {
+		case <-done:
+			timer.Stop()
+			return
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		slog.Warn("Slow Solr request", "method", req.Method, "url", config.RedactURL(req.URL.String()), "elapsed", d)
+		if notify != nil {
+			msg := fmt.Sprintf("Solr has not answered %s after %s, still waiting", target, d)
+			if t.Timeout > 0 {
+				msg += fmt.Sprintf(" (the request fails after %s)", t.Timeout)
+			}
+			notify(ctx, msg)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package watchdog
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestTransport tests reporting requests that pass soft deadlines.
+func TestTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/solr/slow/select" {
+			time.Sleep(150 * time.Millisecond)
+		}
+	}))
+	defer server.Close()
+	client := Wrap(&http.Client{Timeout: 30 * time.Second}, []time.Duration{20 * time.Millisecond, 60 * time.Millisecond, time.Hour})
+	var mu sync.Mutex
+	var messages []string
+	ctx := WithNotifier(context.Background(), func(_ context.Context, message string) {
+		mu.Lock()
+		defer mu.Unlock()
+		messages = append(messages, message)
+	})
+	get := func(path string) {
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+path, nil)
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+
+	// Goal: Fast requests are not reported.
+	get("/solr/fast/select")
+	time.Sleep(50 * time.Millisecond)
+	mu.Lock()
+	assert.Empty(t, messages)
+	mu.Unlock()
+
+	// Goal: A slow request is reported once per deadline it passes, until it is answered.
+	get("/solr/slow/select?q=*:*")
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []string{
+		"Solr has not answered GET /solr/slow/select after 20ms, still waiting (the request fails after 30s)",
+		"Solr has not answered GET /solr/slow/select after 60ms, still waiting (the request fails after 30s)",
+	}, messages)
+}
+
+// TestParseDeadlines tests parsing soft deadlines.
+func TestParseDeadlines(t *testing.T) {
+	deadlines, err := ParseDeadlines("15s, 5s,5s")
+	require.NoError(t, err)
+	assert.Equal(t, []time.Duration{5 * time.Second, 15 * time.Second}, deadlines)
+	deadlines, err = ParseDeadlines("off")
+	require.NoError(t, err)
+	assert.Empty(t, deadlines)
+	_, err = ParseDeadlines("5s,-1s")
+	assert.ErrorContains(t, err, `invalid soft deadline "-1s"`)
+
+	// Goal: Without deadlines, the client is used as is.
+	client := &http.Client{}
+	assert.Same(t, client, Wrap(client, nil))
+}
@@ -0,0 +1,98 @@
+package solrmcp_test
+
+import (
+	"context"
+	"net/url"
+	"testing"
+
+	"github.com/Sashimimochi/solr-mcp-go/pkg/solrmcp"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryBackend implements SearchBackend with the exported types only, as a program using the library would.
+type memoryBackend struct {
+	docs []map[string]any
+}
+
+func (b *memoryBackend) Query(ctx context.Context, collection string, params url.Values) (map[string]any, error) {
+	docs := make([]any, 0, len(b.docs))
+	for _, d := range b.docs {
+		docs = append(docs, d)
+	}
+	return map[string]any{"response": map[string]any{"numFound": len(docs), "start": 0, "docs": docs}}, nil
+}
+
+func (b *memoryBackend) Count(ctx context.Context, collection, query string) (int64, error) {
+	return int64(len(b.docs)), nil
+}
+
+func (b *memoryBackend) Add(ctx context.Context, collection string, docs []map[string]any) error {
+	b.docs = append(b.docs, docs...)
+	return nil
+}
+
+func (b *memoryBackend) DeleteByQuery(ctx context.Context, collection, query string) error {
+	b.docs = nil
+	return nil
+}
+
+func (b *memoryBackend) DeleteByIDs(ctx context.Context, collection string, ids []string) error {
+	return nil
+}
+
+func (b *memoryBackend) Schema(ctx context.Context, collection string) (*solrmcp.FieldCatalog, error) {
+	return &solrmcp.FieldCatalog{
+		UniqueKey: "id",
+		All:       []solrmcp.SolrField{{Name: "id", Type: "string"}, {Name: "name", Type: "text_general"}},
+		Metadata:  map[string]solrmcp.FieldMetadata{"name": {Description: "product name"}},
+	}, nil
+}
+
+func (b *memoryBackend) ClusterStatus(ctx context.Context, collection string) (*solrmcp.ClusterStatusResponse, error) {
+	return &solrmcp.ClusterStatusResponse{Cluster: solrmcp.ClusterInfo{
+		LiveNodes: []string{"node1:8983_solr"},
+		Collections: map[string]solrmcp.CollectionStatus{"products": {
+			Health: "GREEN",
+			Shards: map[string]solrmcp.ShardInfo{"shard1": {
+				State:    "active",
+				Replicas: map[string]solrmcp.ReplicaInfo{"core_node1": {Core: "products_shard1_replica_n1", State: "active", Leader: "true"}},
+			}},
+		}},
+	}}, nil
+}
+
+func (b *memoryBackend) ListCollections(ctx context.Context) ([]string, error) {
+	return []string{"products"}, nil
+}
+
+func (b *memoryBackend) DeleteCollection(ctx context.Context, name string) error {
+	return nil
+}
+
+// TestExternalBackend tests that a backend implemented outside the module can replace Solr.
+func TestExternalBackend(t *testing.T) {
+	var _ solrmcp.SearchBackend = (*memoryBackend)(nil)
+
+	b := &memoryBackend{docs: []map[string]any{{"id": "1", "name": "iPod"}}}
+	s := solrmcp.NewServer(solrmcp.NewClient("http://localhost:8983"), solrmcp.WithBackend(b), solrmcp.WithToolFilter(func(name string) bool {
+		return name == "solr.query"
+	}))
+
+	ctx := context.Background()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	_, err := s.MCPServer().Connect(ctx, serverTransport, nil)
+	require.NoError(t, err)
+	session, err := mcp.NewClient(&mcp.Implementation{Name: "test-client"}, nil).Connect(ctx, clientTransport, nil)
+	require.NoError(t, err)
+	defer session.Close()
+
+	// Goal: Tools read documents from the external backend.
+	res, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "solr.query", Arguments: map[string]any{"collection": "products", "query": "*:*"}})
+	require.NoError(t, err)
+	require.False(t, res.IsError, "%v", res.Content)
+	out := res.StructuredContent.(map[string]any)
+	assert.EqualValues(t, 1, out["response"].(map[string]any)["numFound"])
+}
@@ -0,0 +1,157 @@
+package solrmcp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/replay"
+	"github.com/Sashimimochi/solr-mcp-go/internal/solr"
+	"github.com/Sashimimochi/solr-mcp-go/internal/types"
+)
+
+// defaultSchemaCacheTTL is how long schemas are cached unless WithSchemaCacheTTL is used.
+const defaultSchemaCacheTTL = 10 * time.Minute
+
+// Client accesses a Solr server. It is safe for concurrent use.
+type Client struct {
+	baseURL     string
+	user        string
+	pass        string
+	httpClient  *http.Client
+	schemaCache types.SchemaCache
+	recordPath  string
+	fixture     *replay.Fixture
+}
+
+// ClientOption configures a Client.
+type ClientOption func(*Client)
+
+// WithBasicAuth sets the credentials sent with every request.
+func WithBasicAuth(user, pass string) ClientOption {
+	return func(c *Client) {
+		c.user = user
+		c.pass = pass
+	}
+}
+
+// WithHTTPClient sets the HTTP client used for all requests (default: a client with a 30s timeout).
+func WithHTTPClient(httpClient *http.Client) ClientOption {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithRecording records every request of the client and its response to the fixture file at path, for
+// replaying them later with WithReplay. Fixtures hold no hosts or request headers, so no credentials.
+func WithRecording(path string) ClientOption {
+	return func(c *Client) {
+		c.recordPath = path
+	}
+}
+
+// WithReplay answers the requests of the client from a fixture file written by WithRecording, without network
+// access. Requests that were not recorded fail.
+func WithReplay(path string) (ClientOption, error) {
+	f, err := replay.Load(path)
+	if err != nil {
+		return nil, err
+	}
+	return func(c *Client) {
+		c.fixture = f
+	}, nil
+}
+
+// WithSchemaCacheTTL sets how long schemas returned by Schema are cached (default: 10 minutes).
+func WithSchemaCacheTTL(ttl time.Duration) ClientOption {
+	return func(c *Client) {
+		c.schemaCache.TTL = ttl
+	}
+}
+
+// NewClient creates a Client for the Solr server at baseURL, e.g. "http://localhost:8983".
+func NewClient(baseURL string, opts ...ClientOption) *Client {
+	c := &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		schemaCache: types.SchemaCache{
+			LastFetch: make(map[string]time.Time),
+			TTL:       defaultSchemaCacheTTL,
+			ByCol:     make(map[string]*types.FieldCatalog),
+		},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.fixture != nil {
+		c.httpClient = replay.PlayingClient(c.httpClient, c.fixture)
+	} else if c.recordPath != "" {
+		c.httpClient = replay.RecordingClient(c.httpClient, c.recordPath)
+	}
+	return c
+}
+
+// BaseURL returns the Solr base URL of the client.
+func (c *Client) BaseURL() string {
+	return c.baseURL
+}
+
+// QueryValues returns the /select parameters of a query request.
+func QueryValues(req QueryRequest) url.Values {
+	return solr.QueryValues(solr.BuildQuery(req.queryIn()))
+}
+
+// Query runs a /select request and returns the raw Solr response.
+// Warnings reported by Solr are copied to a top-level "warnings" key.
+func (c *Client) Query(ctx context.Context, req QueryRequest) (map[string]any, error) {
+	if strings.TrimSpace(req.Collection) == "" {
+		return nil, errors.New("collection is required")
+	}
+	resp, err := solr.QueryWithRawResponse(ctx, c.httpClient, c.baseURL, c.user, c.pass, req.Collection, solr.BuildQuery(req.queryIn()))
+	if err != nil {
+		return nil, err
+	}
+	if warnings := solr.ExtractWarnings(resp); len(warnings) > 0 {
+		resp["warnings"] = warnings
+	}
+	return resp, nil
+}
+
+// Count returns the number of documents matching query.
+func (c *Client) Count(ctx context.Context, collection, query string) (int64, error) {
+	return solr.CountDocuments(ctx, c.httpClient, c.baseURL, c.user, c.pass, collection, query)
+}
+
+// Schema returns the fields, uniqueKey and /select handler parameters of a collection.
+func (c *Client) Schema(ctx context.Context, collection string) (*FieldCatalog, error) {
+	return solr.GetFieldCatalog(ctx, solr.SchemaContext{
+		HttpClient: c.httpClient,
+		BaseURL:    c.baseURL,
+		User:       c.user,
+		Pass:       c.pass,
+		Cache:      &c.schemaCache,
+	}, collection)
+}
+
+// Collections returns the names of all collections.
+func (c *Client) Collections(ctx context.Context) ([]string, error) {
+	return solr.ListCollections(ctx, c.httpClient, c.baseURL, c.user, c.pass)
+}
+
+// Add adds or overwrites documents and commits.
+func (c *Client) Add(ctx context.Context, collection string, docs []map[string]any) error {
+	return solr.AddDocuments(ctx, c.httpClient, c.baseURL, c.user, c.pass, collection, docs)
+}
+
+// DeleteByQuery deletes the documents matching query and commits.
+func (c *Client) DeleteByQuery(ctx context.Context, collection, query string) error {
+	return solr.DeleteByQuery(ctx, c.httpClient, c.baseURL, c.user, c.pass, collection, query)
+}
+
+// DeleteByIDs deletes the documents with the given uniqueKey values and commits.
+func (c *Client) DeleteByIDs(ctx context.Context, collection string, ids []string) error {
+	return solr.DeleteByIDs(ctx, c.httpClient, c.baseURL, c.user, c.pass, collection, ids)
+}
@@ -0,0 +1,101 @@
+package solrmcp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestClient tests the library client against a mock Solr.
+func TestClient(t *testing.T) {
+	var schemaRequests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, _ := r.BasicAuth()
+		assert.Equal(t, "solr", user)
+		assert.Equal(t, "secret", pass)
+		switch r.URL.Path {
+		case "/solr/products/select":
+			assert.Equal(t, "name:ipod", r.URL.Query().Get("q"))
+			assert.Equal(t, "id", r.URL.Query().Get("fl"))
+			fmt.Fprintln(w, `{"responseHeader":{"warnings":["slow"]},"response":{"numFound":1,"docs":[{"id":"1"}]}}`)
+		case "/solr/products/schema/uniquekey":
+			schemaRequests++
+			fmt.Fprintln(w, `{"uniqueKey":"id"}`)
+		case "/solr/products/schema/fields":
+			fmt.Fprintln(w, `{"fields":[{"name":"id","type":"string"}]}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	c := NewClient(server.URL+"/", WithBasicAuth("solr", "secret"), WithHTTPClient(server.Client()), WithSchemaCacheTTL(time.Hour))
+	ctx := context.Background()
+
+	// Goal: The trailing slash of the base URL is removed.
+	assert.Equal(t, server.URL, c.BaseURL())
+
+	// Goal: Queries return the raw response with warnings surfaced.
+	resp, err := c.Query(ctx, QueryRequest{Collection: "products", Query: "name:ipod", Fields: []string{"id"}})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"slow"}, resp["warnings"])
+
+	// Goal: A collection is required.
+	_, err = c.Query(ctx, QueryRequest{})
+	assert.Error(t, err)
+
+	// Goal: Schemas are cached for the configured TTL.
+	fc, err := c.Schema(ctx, "products")
+	assert.NoError(t, err)
+	assert.Equal(t, "id", fc.UniqueKey)
+	_, err = c.Schema(ctx, "products")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, schemaRequests)
+}
+
+// TestQueryValues tests the conversion of query requests to /select parameters.
+func TestQueryValues(t *testing.T) {
+	rows := 5
+
+	// Goal: Empty queries match all documents and fields map to their /select names.
+	v := QueryValues(QueryRequest{FilterQuery: []string{"inStock:true"}, Rows: &rows, Params: map[string]any{"defType": "edismax"}})
+	assert.Equal(t, "*:*", v.Get("q"))
+	assert.Equal(t, "inStock:true", v.Get("fq"))
+	assert.Equal(t, "5", v.Get("rows"))
+	assert.Equal(t, "edismax", v.Get("defType"))
+	assert.Equal(t, "json", v.Get("wt"))
+
+	// Goal: Paramsets and echoParams are passed on.
+	v = QueryValues(QueryRequest{UseParams: []string{"a", "b"}, EchoParams: true})
+	assert.Equal(t, "a,b", v.Get("useParams"))
+	assert.Equal(t, "all", v.Get("echoParams"))
+}
+
+// TestClientReplay tests reproducing recorded client requests without Solr.
+func TestClientReplay(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, `{"response":{"numFound":1,"docs":[{"id":"1"}]}}`)
+	}))
+	path := filepath.Join(t.TempDir(), "fixture.json")
+	ctx := context.Background()
+	req := QueryRequest{Collection: "products", Query: "name:ipod"}
+	recorded, err := NewClient(server.URL, WithRecording(path)).Query(ctx, req)
+	require.NoError(t, err)
+	server.Close()
+
+	// Goal: The recorded response is returned without the server.
+	opt, err := WithReplay(path)
+	require.NoError(t, err)
+	replayed, err := NewClient("http://localhost:8983", opt).Query(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, recorded, replayed)
+
+	_, err = WithReplay(filepath.Join(t.TempDir(), "missing.json"))
+	assert.Error(t, err)
+}
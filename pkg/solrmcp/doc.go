@@ -0,0 +1,11 @@
+// Package solrmcp exposes the Solr tooling of solr-mcp-go as a Go library, so that other programs
+// can query collections, inspect schemas and modify documents without running the MCP server.
+//
+//	c := solrmcp.NewClient("http://localhost:8983", solrmcp.WithBasicAuth("user", "pass"))
+//	resp, err := c.Query(ctx, solrmcp.QueryRequest{Collection: "techproducts", Query: "name:ipod"})
+//
+// Client, Server, their options and QueryRequest are the stable API. The other types are aliases
+// of the types the tools use, exported so that post-processors, transformers and backends can be
+// written outside this module; they may gain fields along with the tools. Everything under
+// internal/ may change without notice.
+package solrmcp
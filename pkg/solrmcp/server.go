@@ -0,0 +1,124 @@
+package solrmcp
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/Sashimimochi/solr-mcp-go/internal/config"
+	"github.com/Sashimimochi/solr-mcp-go/internal/errcatalog"
+	"github.com/Sashimimochi/solr-mcp-go/internal/server"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Server is an embeddable MCP server exposing the Solr tools, prompts and resources.
+type Server struct {
+	st  *server.State
+	mcp *mcp.Server
+}
+
+// ServerOption configures a Server.
+type ServerOption func(*serverOptions)
+
+// serverOptions collects the options of the server implementation, which is not part of the API.
+type serverOptions struct {
+	opts []server.Option
+}
+
+func wrapOption(opt server.Option) ServerOption {
+	return func(o *serverOptions) {
+		o.opts = append(o.opts, opt)
+	}
+}
+
+// WithDefaultCollection sets the collection used when a prompt or resource does not name one.
+func WithDefaultCollection(name string) ServerOption {
+	return wrapOption(server.WithDefaultCollection(name))
+}
+
+// WithToolFilter only registers the tools for which allow returns true.
+func WithToolFilter(allow func(name string) bool) ServerOption {
+	return wrapOption(server.WithToolFilter(allow))
+}
+
+// WithConfigFile loads the JSON config file at path, which Handler then watches for changes.
+func WithConfigFile(path string) (ServerOption, error) {
+	fc, err := config.LoadFileConfig(path)
+	if err != nil {
+		return nil, err
+	}
+	return wrapOption(server.WithConfig(fc, path)), nil
+}
+
+// WithConfirmationTTL sets how long confirmation tokens of destructive tools stay valid.
+func WithConfirmationTTL(ttl time.Duration) ServerOption {
+	return wrapOption(server.WithConfirmationTTL(ttl))
+}
+
+// WithLanguage sets the language of the messages and hints of tool errors, "en" (default) or "ja".
+func WithLanguage(lang string) (ServerOption, error) {
+	l, err := errcatalog.ParseLang(lang)
+	if err != nil {
+		return nil, err
+	}
+	return wrapOption(server.WithLanguage(l)), nil
+}
+
+// WithBackend makes the tools use b instead of the Solr HTTP APIs of the client, e.g. a mock in tests.
+// Replica-level diagnostics, archiving and saved queries still use the client.
+func WithBackend(b SearchBackend) ServerOption {
+	return wrapOption(server.WithBackend(b))
+}
+
+// WithLogger replaces the slog default logger, through which the server logs.
+func WithLogger(logger *slog.Logger) ServerOption {
+	return wrapOption(server.WithLogger(logger))
+}
+
+// WithTool adds a custom tool alongside the built-in ones. It shares the argument size limit,
+// post-processing, disabledTools and tool filter of the built-in tools.
+func WithTool[In, Out any](t *mcp.Tool, h mcp.ToolHandlerFor[In, Out]) ServerOption {
+	return wrapOption(server.WithTool(t, h))
+}
+
+// RegisterTool adds a custom tool to a running server. Connected sessions are notified of the new tool.
+func RegisterTool[In, Out any](s *Server, t *mcp.Tool, h mcp.ToolHandlerFor[In, Out]) error {
+	return server.RegisterTool(s.st, t, h)
+}
+
+// NewServer creates an MCP server that accesses Solr through c.
+func NewServer(c *Client, opts ...ServerOption) *Server {
+	o := &serverOptions{opts: []server.Option{
+		server.WithSolrURL(c.baseURL),
+		server.WithBasicAuth(c.user, c.pass),
+		server.WithHTTPClient(c.httpClient),
+		server.WithSchemaCacheTTL(c.schemaCache.TTL),
+	}}
+	for _, opt := range opts {
+		opt(o)
+	}
+	st := server.NewServer(o.opts...)
+	return &Server{st: st, mcp: st.NewMCPServer()}
+}
+
+// MCPServer returns the underlying MCP server, e.g. to connect it to a stdio or in-memory transport.
+func (s *Server) MCPServer() *mcp.Server {
+	return s.mcp
+}
+
+// Handler returns the Streamable HTTP handler of the server. Background jobs run until ctx is done.
+func (s *Server) Handler(ctx context.Context) http.Handler {
+	return s.st.Handler(ctx, s.mcp)
+}
+
+// RegisterPostProcessor adds a tool result processor that the postProcessing config can reference by name.
+func (s *Server) RegisterPostProcessor(name string, p PostProcessor) {
+	s.st.RegisterPostProcessor(name, p)
+}
+
+// RegisterTransformer adds a document transformer type that the transformers config can reference.
+func (s *Server) RegisterTransformer(name string, fn DocTransformer) {
+	s.st.RegisterTransformer(name, fn)
+}
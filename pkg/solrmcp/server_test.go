@@ -0,0 +1,38 @@
+package solrmcp
+
+import (
+	"context"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestServer tests that an embedded server exposes the filtered tools over an in-memory transport.
+func TestServer(t *testing.T) {
+	c := NewClient("http://localhost:8983")
+	s := NewServer(c, WithDefaultCollection("products"), WithToolFilter(func(name string) bool {
+		return name == "solr.info"
+	}))
+
+	ctx := context.Background()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	_, err := s.MCPServer().Connect(ctx, serverTransport, nil)
+	assert.NoError(t, err)
+	session, err := mcp.NewClient(&mcp.Implementation{Name: "test-client"}, nil).Connect(ctx, clientTransport, nil)
+	assert.NoError(t, err)
+	defer session.Close()
+
+	// Goal: Only the tools accepted by the filter are registered.
+	tools, err := session.ListTools(ctx, nil)
+	assert.NoError(t, err)
+	assert.Len(t, tools.Tools, 1)
+	assert.Equal(t, "solr.info", tools.Tools[0].Name)
+
+	// Goal: The connection settings of the client and the server options are applied.
+	res, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "solr.info", Arguments: map[string]any{}})
+	assert.NoError(t, err)
+	info := res.StructuredContent.(map[string]any)
+	assert.Equal(t, "http://localhost:8983", info["solrUrl"])
+	assert.Equal(t, "products", info["defaultCollection"])
+}
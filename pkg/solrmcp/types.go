@@ -0,0 +1,87 @@
+package solrmcp
+
+import (
+	"github.com/Sashimimochi/solr-mcp-go/internal/backend"
+	"github.com/Sashimimochi/solr-mcp-go/internal/config"
+	"github.com/Sashimimochi/solr-mcp-go/internal/postprocess"
+	"github.com/Sashimimochi/solr-mcp-go/internal/transform"
+	"github.com/Sashimimochi/solr-mcp-go/internal/types"
+)
+
+// QueryRequest is a /select request run by Client.Query.
+type QueryRequest struct {
+	Collection  string
+	Query       string         // q; matches all documents when empty
+	FilterQuery []string       // fq
+	Fields      []string       // fl
+	Sort        string         // sort
+	Start       *int           // start
+	Rows        *int           // rows
+	Params      map[string]any // other /select parameters, e.g. {"defType": "edismax"}
+	EchoParams  bool           // echoParams=all
+	UseParams   []string       // useParams, the paramsets applied to the request
+}
+
+// queryIn converts req to the input of the solr.query tool, from which the /select parameters are built.
+func (req QueryRequest) queryIn() types.QueryIn {
+	return types.QueryIn{
+		Collection:  req.Collection,
+		Query:       req.Query,
+		FilterQuery: req.FilterQuery,
+		Fields:      req.Fields,
+		Sort:        req.Sort,
+		Start:       req.Start,
+		Rows:        req.Rows,
+		Params:      req.Params,
+		EchoParams:  req.EchoParams,
+		UseParams:   req.UseParams,
+	}
+}
+
+// FieldCatalog describes the schema of a collection.
+type FieldCatalog = types.FieldCatalog
+
+// SolrField is a single field of a FieldCatalog.
+type SolrField = types.SolrField
+
+// FieldMetadata holds the description of a field of a FieldCatalog.
+type FieldMetadata = types.FieldMetadata
+
+// HandlerParams holds the defaults, appends and invariants of a request handler.
+type HandlerParams = types.HandlerParams
+
+// ErrNoUniqueKey is returned for operations that need a uniqueKey on collections without one.
+var ErrNoUniqueKey = types.ErrNoUniqueKey
+
+// PostProcessor transforms tool results. Register it with Server.RegisterPostProcessor.
+type PostProcessor = postprocess.Processor
+
+// PostProcessorFunc adapts a function to the PostProcessor interface.
+type PostProcessorFunc = postprocess.ProcessorFunc
+
+// ToolResult is the result of a tool call passed to a PostProcessor.
+type ToolResult = postprocess.Result
+
+// DocTransformer reshapes a document of solr.query results in place. Register it with Server.RegisterTransformer.
+type DocTransformer = transform.Func
+
+// TransformerConfig is a configured transformer step, passed to a DocTransformer.
+type TransformerConfig = config.TransformerConfig
+
+// SearchBackend is everything the tools need from a search engine. See WithBackend.
+type SearchBackend = backend.SearchBackend
+
+// ClusterStatusResponse is the cluster state returned by SearchBackend.ClusterStatus.
+type ClusterStatusResponse = config.ClusterStatusResponse
+
+// ClusterInfo lists the collections and live nodes of a ClusterStatusResponse.
+type ClusterInfo = config.ClusterInfo
+
+// CollectionStatus describes a collection of a ClusterInfo.
+type CollectionStatus = config.CollectionStatus
+
+// ShardInfo describes a shard of a CollectionStatus.
+type ShardInfo = config.ShardInfo
+
+// ReplicaInfo describes a replica of a ShardInfo.
+type ReplicaInfo = config.ReplicaInfo